@@ -0,0 +1,28 @@
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCall(t *testing.T) {
+	c := newTestStub(t, weatherStub)
+
+	result, err := c.Call("tools/call", map[string]interface{}{
+		"name":      "forecast",
+		"arguments": map[string]interface{}{"city": "Oslo"},
+	})
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if text, _ := resultText(t, result); text != "Sunny in Oslo" {
+		t.Errorf("Expected the tool's result, got %q", text)
+	}
+
+	if _, err := c.Call("x-myorg/missing", nil); err == nil || !strings.Contains(err.Error(), "-32601") {
+		t.Errorf("Expected a method not found error, got %v", err)
+	}
+	if _, err := c.Call("", nil); err == nil {
+		t.Error("Expected an empty method to be rejected")
+	}
+}
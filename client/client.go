@@ -71,6 +71,59 @@ func WithRequestTimeoutOption(d time.Duration) TimeoutOption {
 	return TimeoutOption{Duration: d}
 }
 
+// RequestIDOption creates a request option that reports the JSON-RPC ID of the request.
+type RequestIDOption struct {
+	Callback func(id int64)
+}
+
+func (r RequestIDOption) apply() {}
+
+// WithRequestIDCallback creates a RequestIDOption for CallTool, GetResource and GetPrompt.
+//
+// The callback is invoked with the request's ID before it is sent, so that a
+// long-running request can be cancelled from another goroutine.
+//
+// Example:
+//
+//	ids := make(chan int64, 1)
+//	go func() {
+//	    time.Sleep(time.Second)
+//	    client.CancelRequest(<-ids)
+//	}()
+//	_, err := client.CallTool("long-task", args,
+//	    client.WithRequestIDCallback(func(id int64) { ids <- id }))
+//	if errors.Is(err, client.ErrRequestCancelled) {
+//	    log.Println("cancelled")
+//	}
+func WithRequestIDCallback(callback func(id int64)) RequestIDOption {
+	return RequestIDOption{Callback: callback}
+}
+
+// ProgressOption creates a request option that receives the request's progress notifications.
+type ProgressOption struct {
+	Handler ProgressHandler
+}
+
+func (p ProgressOption) apply() {}
+
+// WithProgress creates a ProgressOption for CallTool, GetResource and GetPrompt.
+//
+// The request is sent with a progress token in its _meta, and the handler receives
+// the notifications the server sends for that token, and only those. Progress also
+// keeps the request from timing out. The handler runs on the goroutine reading the
+// connection, so it should not block. Transports that deliver notifications apart
+// from responses, such as SSE, may call it shortly after the request returns.
+//
+// Example:
+//
+//	result, err := client.CallTool("long-task", args,
+//	    client.WithProgress(func(p client.ProgressNotification) {
+//	        fmt.Printf("%.0f/%.0f %s\n", p.Progress, p.Total, p.Message)
+//	    }))
+func WithProgress(handler ProgressHandler) ProgressOption {
+	return ProgressOption{Handler: handler}
+}
+
 // ResourceParamsOption creates a request option that adds parameters to a resource request.
 type ResourceParamsOption struct {
 	Params map[string]interface{}
@@ -78,6 +131,25 @@ type ResourceParamsOption struct {
 
 func (r ResourceParamsOption) apply() {}
 
+// TagFilterOption creates a request option that restricts list results to entries carrying all given tags.
+type TagFilterOption struct {
+	Tags []string
+}
+
+func (t TagFilterOption) apply() {}
+
+// WithTagFilter creates a TagFilterOption for ListResources and ListPrompts.
+//
+// The tags are sent to the server as a list filter and also applied locally,
+// so the result is correct against servers that ignore the filter.
+//
+// Example:
+//
+//	prompts, err := client.ListPrompts(client.WithTagFilter("code"))
+func WithTagFilter(tags ...string) TagFilterOption {
+	return TagFilterOption{Tags: tags}
+}
+
 // WithResourceParams creates a ResourceParamsOption for adding parameters to GetResource requests.
 //
 // This allows passing additional parameters alongside the URI in resources/read requests
@@ -96,10 +168,8 @@ func WithResourceParams(params map[string]interface{}) ResourceParamsOption {
 	return ResourceParamsOption{Params: params}
 }
 
-// Client represents an MCP client for communicating with MCP servers.
-// It provides methods for all MCP operations including tool calls, resource access,
-// prompt rendering, root management, and sampling functionality.
-type Client interface {
+// ToolCaller lists and calls the tools of an MCP server.
+type ToolCaller interface {
 	// CallTool invokes a tool on the connected MCP server.
 	//
 	// The name parameter specifies the tool to call. The args parameter contains
@@ -119,6 +189,43 @@ type Client interface {
 	//  }, client.WithRequestTimeoutOption(10*time.Second))
 	CallTool(name string, args map[string]interface{}, opts ...RequestOption) (interface{}, error)
 
+	// ListTools retrieves the list of available tools from the server.
+	//
+	// This method calls the tools/list endpoint as specified in the MCP protocol.
+	// It automatically handles pagination internally and returns all available tools.
+	// The returned slice contains all available tools with their names, descriptions,
+	// and input schemas, which can be used for tool discovery and proxy patterns.
+	//
+	// Example:
+	//  tools, err := client.ListTools()
+	//  for _, tool := range tools {
+	//      fmt.Printf("Tool: %s - %s\n", tool.Name, tool.Description)
+	//      fmt.Printf("Schema: %+v\n", tool.InputSchema)
+	//  }
+	ListTools() ([]Tool, error)
+
+	// SearchTools retrieves the tools matching a query.
+	//
+	// The name prefix and tags of the query are sent to the server as a tools/list
+	// filter so large tool sets are narrowed before they are transferred; they are
+	// also applied locally so the result is correct against servers that ignore the
+	// filter. The free-text part is matched case-insensitively against tool names
+	// and descriptions.
+	//
+	// Example:
+	//  tools, err := client.SearchTools(client.ToolQuery{Text: "read", Tags: []string{"files"}})
+	SearchTools(query ToolQuery) ([]Tool, error)
+
+	// ClearToolCache discards all results cached by WithToolResultCache.
+	//
+	// The cache is cleared automatically when the server announces a tools/list_changed
+	// notification; call this when tool results are known to be stale for other reasons.
+	// It is a no-op when caching is not enabled.
+	ClearToolCache()
+}
+
+// ResourceReader lists and reads the resources of an MCP server.
+type ResourceReader interface {
 	// GetResource retrieves a resource from the server.
 	//
 	// The path parameter specifies the resource URI to retrieve.
@@ -147,6 +254,54 @@ type Client interface {
 	//      client.WithRequestTimeoutOption(5*time.Second))
 	GetResource(path string, opts ...RequestOption) (*ResourceResponse, error)
 
+	// GetRoot retrieves the root resource from the server.
+	//
+	// This is a convenience method equivalent to calling GetResource("/").
+	//
+	// Example:
+	//  root, err := client.GetRoot()
+	//  // Access content based on protocol version:
+	//  if len(root.Content) > 0 {
+	//      // 2024-11-05 format
+	//      fmt.Println("Root text:", root.Content[0].Text)
+	//  } else if len(root.Contents) > 0 {
+	//      // 2025-03-26 format
+	//      fmt.Println("Root text:", root.Contents[0].Content[0].Text)
+	//  }
+	GetRoot() (*ResourceResponse, error)
+
+	// ListResources retrieves the list of available resources from the server.
+	//
+	// This method calls the resources/list endpoint as specified in the MCP protocol.
+	// It automatically handles pagination internally and returns all available resources.
+	// The returned slice contains all available resources with their URIs, names,
+	// descriptions, and MIME types, which can be used for resource discovery.
+	//
+	// Example:
+	//  resources, err := client.ListResources()
+	//  for _, resource := range resources {
+	//      fmt.Printf("Resource: %s - %s\n", resource.Name, resource.Description)
+	//      fmt.Printf("URI: %s, MIME Type: %s\n", resource.URI, resource.MimeType)
+	//  }
+	ListResources(opts ...RequestOption) ([]Resource, error)
+
+	// ListResourceTemplates retrieves the list of templated resources from the server.
+	//
+	// This method calls the resources/templates/list endpoint, handling pagination
+	// internally. Templated resources take parameters in their URI, such as
+	// /users/{id}, and are not included in ListResources.
+	//
+	// Example:
+	//  templates, err := client.ListResourceTemplates()
+	//  for _, template := range templates {
+	//      fmt.Printf("Template: %s - %s\n", template.URITemplate, template.Description)
+	//  }
+	ListResourceTemplates(opts ...RequestOption) ([]ResourceTemplate, error)
+}
+
+// PromptReader lists and renders the prompts of an MCP server, and completes
+// their arguments.
+type PromptReader interface {
 	// GetPrompt retrieves a prompt from the server.
 	//
 	// The name parameter specifies the prompt to retrieve. The variables parameter
@@ -163,22 +318,78 @@ type Client interface {
 	//  }, client.WithRequestTimeoutOption(3*time.Second))
 	GetPrompt(name string, variables map[string]interface{}, opts ...RequestOption) (*PromptResponse, error)
 
-	// GetRoot retrieves the root resource from the server.
+	// ListPrompts retrieves the list of available prompts from the server.
 	//
-	// This is a convenience method equivalent to calling GetResource("/").
+	// This method calls the prompts/list endpoint as specified in the MCP protocol.
+	// It automatically handles pagination internally and returns all available prompts.
+	// The returned slice contains all available prompts with their names, descriptions,
+	// and argument specifications, which can be used for prompt discovery.
 	//
 	// Example:
-	//  root, err := client.GetRoot()
-	//  // Access content based on protocol version:
-	//  if len(root.Content) > 0 {
-	//      // 2024-11-05 format
-	//      fmt.Println("Root text:", root.Content[0].Text)
-	//  } else if len(root.Contents) > 0 {
-	//      // 2025-03-26 format
-	//      fmt.Println("Root text:", root.Contents[0].Content[0].Text)
+	//  prompts, err := client.ListPrompts()
+	//  for _, prompt := range prompts {
+	//      fmt.Printf("Prompt: %s - %s\n", prompt.Name, prompt.Description)
+	//      fmt.Printf("Arguments: %d\n", len(prompt.Arguments))
 	//  }
-	GetRoot() (*ResourceResponse, error)
+	ListPrompts(opts ...RequestOption) ([]Prompt, error)
+
+	// Complete requests completion suggestions for an argument of a prompt or resource.
+	//
+	// This method calls the completion/complete endpoint as specified in the MCP protocol.
+	// The ref parameter identifies the prompt or resource, and argument holds the
+	// argument's name and the value typed so far. Arguments the server has no
+	// completions for return an empty list.
+	//
+	// Example:
+	//  result, err := client.Complete(client.PromptRef("review"),
+	//      client.CompletionArgument{Name: "language", Value: "py"})
+	//  for _, value := range result.Values {
+	//      fmt.Println(value)
+	//  }
+	Complete(ref CompletionRef, argument CompletionArgument) (*CompletionResult, error)
+}
+
+// Sampler handles sampling requests from an MCP server and sends sampling requests.
+type Sampler interface {
+	// WithSamplingHandler registers a handler for sampling requests.
+	//
+	// The handler will be called when the server requests sampling (e.g., for LLM interactions).
+	// Returns the client instance for method chaining.
+	//
+	// Example:
+	//  client = client.WithSamplingHandler(func(params SamplingCreateMessageParams) (SamplingResponse, error) {
+	//      // Process sampling request
+	//      return SamplingResponse{...}, nil
+	//  })
+	WithSamplingHandler(handler SamplingHandler) Client
+
+	// GetSamplingHandler returns the currently registered sampling handler.
+	GetSamplingHandler() SamplingHandler
+
+	// RequestSampling initiates a sampling request to the server.
+	//
+	// This is the unified method for all sampling operations, supporting both
+	// regular and streaming modes through options configuration.
+	//
+	// Example:
+	//  opts := client.NewSamplingOptions(messages, prefs).
+	//      WithSystemPrompt("You are a helpful assistant").
+	//      WithMaxTokens(1000)
+	//  response, err := client.RequestSampling(opts)
+	//
+	// For streaming (protocol version 2025-03-26 only):
+	//  opts := client.NewSamplingOptions(messages, prefs).
+	//      WithStreaming(func(chunk *SamplingResponse) error {
+	//          fmt.Printf("Received chunk: %s\n", chunk.Content.Text)
+	//          return nil
+	//      })
+	//  response, err := client.RequestSampling(opts)
+	RequestSampling(opts *SamplingOptions) (*SamplingResponse, error)
+}
 
+// Admin manages a client's connection to an MCP server: its roots, log level,
+// in-flight requests and lifetime.
+type Admin interface {
 	// Close closes the client connection to the server and releases all resources.
 	//
 	// After calling Close, the client cannot be used for further operations.
@@ -192,6 +403,26 @@ type Client interface {
 	//  defer client.Close()
 	Close() error
 
+	// SetLogLevel asks the server to send log messages at or above the given level.
+	//
+	// This method calls the logging/setLevel endpoint as specified in the MCP protocol.
+	// Receive the messages by registering a handler with OnLogMessage.
+	//
+	// Example:
+	//  err := client.SetLogLevel(mcp.LogLevelDebug)
+	SetLogLevel(level mcp.LogLevel) error
+
+	// CancelRequest cancels an in-flight request by its JSON-RPC ID.
+	//
+	// The pending call returns an error wrapping ErrRequestCancelled, and the
+	// server is sent a notifications/cancelled so that its handler can stop.
+	// Use WithRequestIDCallback to learn a request's ID. Requests that time out
+	// are cancelled on the server automatically.
+	//
+	// Example:
+	//  err := client.CancelRequest(id)
+	CancelRequest(id int64) error
+
 	// AddRoot registers a new root endpoint with the server.
 	//
 	// The uri parameter specifies the path of the root. The name parameter
@@ -220,50 +451,93 @@ type Client interface {
 	//  }
 	GetRoots() ([]Root, error)
 
-	// ListTools retrieves the list of available tools from the server.
+	// Ping sends a ping request to the server to verify connection health.
+	Ping() error
+}
+
+// Client represents an MCP client for communicating with MCP servers.
+// It provides methods for all MCP operations including tool calls, resource access,
+// prompt rendering, root management, and sampling functionality.
+//
+// Client is the union of ToolCaller, ResourceReader, PromptReader, Sampler and
+// Admin. Libraries should accept the smallest of these interfaces they need, so
+// that callers can pass any client and mocks stay small.
+type Client interface {
+	ToolCaller
+	ResourceReader
+	PromptReader
+	Sampler
+	Admin
+
+	// Call sends a request for any JSON-RPC method and returns its result, as an
+	// escape hatch for vendor extensions the client has no method for, such as the
+	// custom methods a server registers with server.Method and advertises in its
+	// experimental capability. The params are sent as they marshal to JSON; a
+	// JSON-RPC error response is returned as a *ServerError.
 	//
-	// This method calls the tools/list endpoint as specified in the MCP protocol.
-	// It automatically handles pagination internally and returns all available tools.
-	// The returned slice contains all available tools with their names, descriptions,
-	// and input schemas, which can be used for tool discovery and proxy patterns.
+	// Example:
+	//  result, err := client.Call("x-myorg/reindex", map[string]interface{}{
+	//      "collection": "docs",
+	//  })
+	Call(method string, params interface{}, opts ...RequestOption) (interface{}, error)
+
+	// OnLogMessage adds a handler for log messages the server sends with
+	// notifications/message.
 	//
 	// Example:
-	//  tools, err := client.ListTools()
-	//  for _, tool := range tools {
-	//      fmt.Printf("Tool: %s - %s\n", tool.Name, tool.Description)
-	//      fmt.Printf("Schema: %+v\n", tool.InputSchema)
-	//  }
-	ListTools() ([]Tool, error)
+	//  client.OnLogMessage(func(msg client.LogMessage) {
+	//      log.Printf("[server %s] %v", msg.Level, msg.Data)
+	//  })
+	OnLogMessage(handler LogMessageHandler)
 
-	// ListResources retrieves the list of available resources from the server.
+	// OnNotification adds a handler for server notifications with the given method.
 	//
-	// This method calls the resources/list endpoint as specified in the MCP protocol.
-	// It automatically handles pagination internally and returns all available resources.
-	// The returned slice contains all available resources with their URIs, names,
-	// descriptions, and MIME types, which can be used for resource discovery.
+	// Handlers run on a per-method queue: notifications of one method are delivered in
+	// order, and a slow handler never blocks the connection or other methods. The
+	// typed OnProgress, OnResourceUpdated, OnListChanged and OnLogMessage methods are
+	// built on the same queues.
 	//
 	// Example:
-	//  resources, err := client.ListResources()
-	//  for _, resource := range resources {
-	//      fmt.Printf("Resource: %s - %s\n", resource.Name, resource.Description)
-	//      fmt.Printf("URI: %s, MIME Type: %s\n", resource.URI, resource.MimeType)
-	//  }
-	ListResources(opts ...RequestOption) ([]Resource, error)
+	//  client.OnNotification("notifications/vendor/status", func(params json.RawMessage) error {
+	//      log.Printf("status: %s", params)
+	//      return nil
+	//  })
+	OnNotification(method string, handler NotificationHandler)
 
-	// ListPrompts retrieves the list of available prompts from the server.
+	// OnProgress adds a handler for notifications/progress.
 	//
-	// This method calls the prompts/list endpoint as specified in the MCP protocol.
-	// It automatically handles pagination internally and returns all available prompts.
-	// The returned slice contains all available prompts with their names, descriptions,
-	// and argument specifications, which can be used for prompt discovery.
+	// Example:
+	//  client.OnProgress(func(p client.ProgressNotification) {
+	//      fmt.Printf("%v: %.0f/%.0f %s\n", p.ProgressToken, p.Progress, p.Total, p.Message)
+	//  })
+	OnProgress(handler ProgressHandler)
+
+	// OnResourceUpdated adds a handler for notifications/resources/updated, which the
+	// server sends for resources the client subscribed to.
 	//
 	// Example:
-	//  prompts, err := client.ListPrompts()
-	//  for _, prompt := range prompts {
-	//      fmt.Printf("Prompt: %s - %s\n", prompt.Name, prompt.Description)
-	//      fmt.Printf("Arguments: %d\n", len(prompt.Arguments))
-	//  }
-	ListPrompts(opts ...RequestOption) ([]Prompt, error)
+	//  client.OnResourceUpdated(func(uri string) {
+	//      refresh(uri)
+	//  })
+	OnResourceUpdated(handler ResourceUpdatedHandler)
+
+	// OnListChanged adds a handler for tools, resources and prompts list_changed
+	// notifications. The handler receives "tools", "resources" or "prompts".
+	//
+	// Example:
+	//  client.OnListChanged(func(kind string) {
+	//      if kind == "tools" {
+	//          tools, _ = client.ListTools()
+	//      }
+	//  })
+	OnListChanged(handler ListChangedHandler)
+
+	// UnknownMethodCounts returns how many notifications of each unknown method were received.
+	//
+	// Unknown notifications are those neither the client nor a handler registered
+	// with WithNotificationHandler handles. Each is also published as an
+	// UnknownMethodEvent.
+	UnknownMethodCounts() map[string]int64
 
 	// Version returns the negotiated protocol version with the server.
 	//
@@ -291,21 +565,6 @@ type Client interface {
 	//  }
 	IsConnected() bool
 
-	// WithSamplingHandler registers a handler for sampling requests.
-	//
-	// The handler will be called when the server requests sampling (e.g., for LLM interactions).
-	// Returns the client instance for method chaining.
-	//
-	// Example:
-	//  client = client.WithSamplingHandler(func(params SamplingCreateMessageParams) (SamplingResponse, error) {
-	//      // Process sampling request
-	//      return SamplingResponse{...}, nil
-	//  })
-	WithSamplingHandler(handler SamplingHandler) Client
-
-	// GetSamplingHandler returns the currently registered sampling handler.
-	GetSamplingHandler() SamplingHandler
-
 	// Events returns the events subject for subscribing to client events.
 	//
 	// This provides access to the event system for monitoring client lifecycle,
@@ -319,26 +578,6 @@ type Client interface {
 	//  })
 	Events() *events.Subject
 
-	// RequestSampling initiates a sampling request to the server.
-	//
-	// This is the unified method for all sampling operations, supporting both
-	// regular and streaming modes through options configuration.
-	//
-	// Example:
-	//  opts := client.NewSamplingOptions(messages, prefs).
-	//      WithSystemPrompt("You are a helpful assistant").
-	//      WithMaxTokens(1000)
-	//  response, err := client.RequestSampling(opts)
-	//
-	// For streaming (protocol version 2025-03-26 only):
-	//  opts := client.NewSamplingOptions(messages, prefs).
-	//      WithStreaming(func(chunk *SamplingResponse) error {
-	//          fmt.Printf("Received chunk: %s\n", chunk.Content.Text)
-	//          return nil
-	//      })
-	//  response, err := client.RequestSampling(opts)
-	RequestSampling(opts *SamplingOptions) (*SamplingResponse, error)
-
 	// SendBatch sends multiple requests to the server in a single batch operation.
 	//
 	// This method implements JSON-RPC 2.0 batch requests, allowing multiple operations
@@ -470,22 +709,30 @@ type Client interface {
 	//  }
 	SupportsListChangedNotifications(resourceType string) bool
 
-	// Ping sends a ping request to the server to verify connection health.
-	Ping() error
-
 	// WaitForReady waits for the client to be fully connected and ready to handle requests.
 	//
-	// This method blocks until the client is connected, initialized, and can successfully
-	// ping the server, or until the timeout is reached. It's useful for ensuring the
-	// server is fully ready before making API calls.
+	// This method blocks until the client is connected, initialized, can successfully
+	// ping the server and meets the given conditions, or until the timeout is reached.
+	// Without conditions, those of WithReadyConditions apply. It's useful for ensuring
+	// the server is fully ready before making API calls.
 	//
 	// Example:
-	//  if err := client.WaitForReady(5 * time.Second); err != nil {
+	//  if err := client.WaitForReady(5*time.Second, client.ToolsAvailable("search")); err != nil {
 	//      log.Fatal("Server not ready:", err)
 	//  }
 	//  // Now safe to make API calls
 	//  tools, err := client.ListTools()
-	WaitForReady(timeout time.Duration) error
+	WaitForReady(timeout time.Duration, conditions ...ReadyCondition) error
+
+	// OnReady adds a handler run once the client is ready: connected, initialized and
+	// meeting the conditions of WithReadyConditions. A handler added once the client
+	// is ready runs at once. The client also publishes an events.ClientReadyEvent.
+	//
+	// Example:
+	//  client.OnReady(func() {
+	//      router.Enable("search")
+	//  })
+	OnReady(handler func())
 }
 
 // clientImpl is the concrete implementation of the Client interface.
@@ -507,6 +754,9 @@ type clientImpl struct {
 	capabilities      ClientCapabilities
 	samplingHandler   SamplingHandler
 
+	// Answers elicitation requests from the server (see WithElicitationHandler)
+	elicitationHandler ElicitationHandler
+
 	// Server capabilities and info (received during initialization)
 	// Set once during initialization, protected by c.mu, never change after
 	serverCapabilities *ServerCapabilities
@@ -517,8 +767,46 @@ type clientImpl struct {
 	serverRegistry *ServerRegistry
 	serverName     string
 
+	// optionErr is an error of an option, returned by NewClient
+	optionErr error
+
+	// Initialize retries of slow-starting servers (see WithInitializeRetry)
+	initializeAttempts   int
+	initializeRetryDelay time.Duration
+
+	// Transports probed by Connect (see WithTransportFallback)
+	transportFallback []TransportKind
+
 	// Events
 	events *events.Subject
+
+	// Optional tool result cache (see WithToolResultCache)
+	toolCache *toolResultCache
+
+	// Input schemas of the server's tools, for validating CallToolTyped requests
+	toolSchemas toolSchemaCache
+
+	// Cancel functions of in-flight requests, keyed by request ID
+	inFlight sync.Map
+
+	// Progress handlers of in-flight requests, keyed by progress token
+	progressHandlers sync.Map
+
+	// Streams of in-flight streaming sampling requests, keyed by request ID
+	samplingStreams sync.Map
+
+	// Dispatches inbound notifications to registered handlers
+	notifications *notificationRouter
+
+	// Counts of notifications nobody handles, by method
+	notificationMu sync.Mutex
+	unknownMethods map[string]int64
+
+	// Readiness beyond initialization (see WithReadyConditions and OnReady)
+	readyConditions []ReadyCondition
+	readyMu         sync.Mutex
+	ready           bool
+	readyHandlers   []func()
 }
 
 // NewClient creates a new MCP client with the given URL and options.
@@ -530,6 +818,8 @@ type clientImpl struct {
 //   - "ws://host:port/path": Uses WebSocket protocol
 //   - "http://host:port/path": Uses HTTP protocol
 //   - "sse://host:port/path": Uses Server-Sent Events protocol
+//   - "auto://host:port/path": Probes streamable HTTP, SSE and WebSocket in turn
+//     (see WithTransportFallback)
 //   - Custom schemes can be handled with a custom Transport implementation
 //
 // Errors returned by NewClient may include:
@@ -567,8 +857,10 @@ func NewClient(url string, options ...Option) (Client, error) {
 				ListChanged: true,
 			},
 		},
-		events: events.NewSubject(),
+		events:         events.NewSubject(),
+		unknownMethods: make(map[string]int64),
 	}
+	c.notifications = newNotificationRouter(c.logger, DefaultNotificationQueueSize, ctx.Done())
 
 	// Initialize the roots manager with the actor pattern
 	c.rootsManager = newRootsManager(c)
@@ -577,6 +869,17 @@ func NewClient(url string, options ...Option) (Client, error) {
 	for _, option := range options {
 		option(c)
 	}
+	c.notifications.logger = c.logger
+
+	// Fail with the error of an option, such as a server that could not be started,
+	// rather than with a less specific connection error
+	if c.optionErr != nil {
+		cancel()
+		if c.serverRegistry != nil {
+			c.serverRegistry.Close()
+		}
+		return nil, c.optionErr
+	}
 
 	// Emit client initializing event
 	go func() {
@@ -612,6 +915,7 @@ func NewClient(url string, options ...Option) (Client, error) {
 			c.logger.Warn("failed to publish client initialized event", "error", err)
 		}
 	}()
+	go c.watchReady()
 
 	return c, nil
 }
@@ -644,16 +948,47 @@ func (c *clientImpl) IsConnected() bool {
 
 // CallTool calls a tool on the server.
 func (c *clientImpl) CallTool(name string, args map[string]interface{}, opts ...RequestOption) (interface{}, error) {
-	timeout := c.extractTimeout(opts...)
-	return c.sendRequestWithTimeout("tools/call", map[string]interface{}{
+	requestOpts := c.extractRequestOptions(opts...)
+
+	// Serve from the tool result cache when enabled
+	var cacheKey string
+	cacheable := false
+	if c.toolCache != nil {
+		cacheKey, cacheable = toolCacheKey(name, args)
+		if cacheable && !hasNoCacheOption(opts...) {
+			if result, hit := c.toolCache.get(cacheKey); hit {
+				c.logger.Debug("tool result served from cache", "tool", name)
+				return result, nil
+			}
+		}
+	}
+
+	result, err := c.sendRequestWithOptions("tools/call", map[string]interface{}{
 		"name":      name,
 		"arguments": args,
-	}, timeout)
+	}, requestOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheable && !isToolErrorResult(result) {
+		c.toolCache.put(cacheKey, result)
+	}
+
+	return result, nil
+}
+
+// Call sends a request for any method to the server.
+func (c *clientImpl) Call(method string, params interface{}, opts ...RequestOption) (interface{}, error) {
+	if method == "" {
+		return nil, fmt.Errorf("method cannot be empty")
+	}
+	return c.sendRequestWithOptions(method, params, c.extractRequestOptions(opts...))
 }
 
 // GetResource retrieves a resource from the server.
 func (c *clientImpl) GetResource(uri string, opts ...RequestOption) (*ResourceResponse, error) {
-	timeout := c.extractTimeout(opts...)
+	requestOpts := c.extractRequestOptions(opts...)
 	resourceParams := c.extractResourceParams(opts...)
 
 	// Build request parameters starting with the URI
@@ -666,7 +1001,7 @@ func (c *clientImpl) GetResource(uri string, opts ...RequestOption) (*ResourceRe
 		params[key] = value
 	}
 
-	result, err := c.sendRequestWithTimeout("resources/read", params, timeout)
+	result, err := c.sendRequestWithOptions("resources/read", params, requestOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get resource: %w", err)
 	}
@@ -757,7 +1092,7 @@ func (c *clientImpl) GetResource(uri string, opts ...RequestOption) (*ResourceRe
 
 // GetPrompt retrieves a prompt from the server.
 func (c *clientImpl) GetPrompt(name string, variables map[string]interface{}, opts ...RequestOption) (*PromptResponse, error) {
-	timeout := c.extractTimeout(opts...)
+	requestOpts := c.extractRequestOptions(opts...)
 
 	params := map[string]interface{}{
 		"name": name,
@@ -767,7 +1102,7 @@ func (c *clientImpl) GetPrompt(name string, variables map[string]interface{}, op
 		params["arguments"] = variables
 	}
 
-	result, err := c.sendRequestWithTimeout("prompts/get", params, timeout)
+	result, err := c.sendRequestWithOptions("prompts/get", params, requestOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get prompt: %w", err)
 	}
@@ -794,8 +1129,18 @@ func (c *clientImpl) GetPrompt(name string, variables map[string]interface{}, op
 				// Parse content
 				if contentData, ok := messageMap["content"].(map[string]interface{}); ok {
 					message.Content = PromptContent{
-						Type: getString(contentData, "type"),
-						Text: getString(contentData, "text"),
+						Type:     mcp.ContentType(getString(contentData, "type")),
+						Text:     getString(contentData, "text"),
+						Data:     getString(contentData, "data"),
+						MimeType: getString(contentData, "mimeType"),
+					}
+					if resourceData, ok := contentData["resource"].(map[string]interface{}); ok {
+						message.Content.Resource = &mcp.EmbeddedResource{
+							URI:      getString(resourceData, "uri"),
+							MimeType: getString(resourceData, "mimeType"),
+							Text:     getString(resourceData, "text"),
+							Blob:     getString(resourceData, "blob"),
+						}
 					}
 				}
 
@@ -819,6 +1164,40 @@ func (c *clientImpl) extractTimeout(opts ...RequestOption) time.Duration {
 	return c.requestTimeout
 }
 
+// extractRequestOptions builds the per-request options from request options.
+func (c *clientImpl) extractRequestOptions(opts ...RequestOption) *RequestOptions {
+	requestOpts := DefaultRequestOptions().WithTimeout(c.extractTimeout(opts...))
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case RequestIDOption:
+			requestOpts.OnRequestID = opt.Callback
+		case ProgressOption:
+			requestOpts.OnProgress = opt.Handler
+		}
+	}
+	return requestOpts
+}
+
+// hasNoCacheOption reports whether the request options ask to bypass the tool result cache.
+func hasNoCacheOption(opts ...RequestOption) bool {
+	for _, opt := range opts {
+		if _, ok := opt.(NoCacheOption); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// extractTagFilter extracts the tags of a TagFilterOption from request options.
+func extractTagFilter(opts ...RequestOption) []string {
+	for _, opt := range opts {
+		if filter, ok := opt.(TagFilterOption); ok {
+			return filter.Tags
+		}
+	}
+	return nil
+}
+
 // extractResourceParams extracts resource parameters from request options.
 func (c *clientImpl) extractResourceParams(opts ...RequestOption) map[string]interface{} {
 	if len(opts) > 0 {
@@ -838,6 +1217,11 @@ func (c *clientImpl) GetRoot() (*ResourceResponse, error) {
 
 // ListTools retrieves the list of available tools from the server.
 func (c *clientImpl) ListTools() ([]Tool, error) {
+	return c.listTools(nil)
+}
+
+// listTools pages through tools/list, passing the optional filter extension on every page.
+func (c *clientImpl) listTools(filter map[string]interface{}) ([]Tool, error) {
 	var allTools []Tool
 	cursor := ""
 
@@ -847,6 +1231,9 @@ func (c *clientImpl) ListTools() ([]Tool, error) {
 		if cursor != "" {
 			params["cursor"] = cursor
 		}
+		if len(filter) > 0 {
+			params["filter"] = filter
+		}
 
 		// Send the tools/list request
 		result, err := c.sendRequest("tools/list", params)
@@ -907,6 +1294,7 @@ func (c *clientImpl) ListTools() ([]Tool, error) {
 func (c *clientImpl) ListResources(opts ...RequestOption) ([]Resource, error) {
 	var allResources []Resource
 	cursor := ""
+	tags := extractTagFilter(opts...)
 
 	for {
 		// Prepare parameters for the request
@@ -914,6 +1302,9 @@ func (c *clientImpl) ListResources(opts ...RequestOption) ([]Resource, error) {
 		if cursor != "" {
 			params["cursor"] = cursor
 		}
+		if len(tags) > 0 {
+			params["filter"] = map[string]interface{}{"tags": tags}
+		}
 
 		// Send the resources/list request
 		result, err := c.sendRequest("resources/list", params)
@@ -948,6 +1339,11 @@ func (c *clientImpl) ListResources(opts ...RequestOption) ([]Resource, error) {
 				Annotations: getMap(resourceMap, "annotations"),
 			}
 
+			// Servers without the filter extension return everything
+			if !mcp.HasAllTags(resource.Annotations, tags) {
+				continue
+			}
+
 			allResources = append(allResources, resource)
 		}
 
@@ -962,6 +1358,54 @@ func (c *clientImpl) ListResources(opts ...RequestOption) ([]Resource, error) {
 	return allResources, nil
 }
 
+// ListResourceTemplates retrieves the list of templated resources from the server.
+func (c *clientImpl) ListResourceTemplates(opts ...RequestOption) ([]ResourceTemplate, error) {
+	var allTemplates []ResourceTemplate
+	cursor := ""
+	tags := extractTagFilter(opts...)
+
+	for {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		if len(tags) > 0 {
+			params["filter"] = map[string]interface{}{"tags": tags}
+		}
+
+		result, err := c.sendRequest("resources/templates/list", params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resource templates: %w", err)
+		}
+
+		responseBytes, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to re-marshal response for parsing: %w", err)
+		}
+		var apiData struct {
+			ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+			NextCursor        string             `json:"nextCursor,omitempty"`
+		}
+		if err := json.Unmarshal(responseBytes, &apiData); err != nil {
+			return nil, fmt.Errorf("failed to parse resources/templates/list response: %w", err)
+		}
+
+		// Servers without the filter extension return everything
+		for _, template := range apiData.ResourceTemplates {
+			if mcp.HasAllTags(template.Annotations, tags) {
+				allTemplates = append(allTemplates, template)
+			}
+		}
+
+		if apiData.NextCursor == "" {
+			break
+		}
+		cursor = apiData.NextCursor
+	}
+
+	return allTemplates, nil
+}
+
 // ListPrompts retrieves the list of available prompts from the server.
 //
 // This method calls the prompts/list endpoint as specified in the MCP protocol.
@@ -979,6 +1423,7 @@ func (c *clientImpl) ListResources(opts ...RequestOption) ([]Resource, error) {
 func (c *clientImpl) ListPrompts(opts ...RequestOption) ([]Prompt, error) {
 	var allPrompts []Prompt
 	cursor := ""
+	tags := extractTagFilter(opts...)
 
 	for {
 		// Prepare parameters for the request
@@ -986,6 +1431,9 @@ func (c *clientImpl) ListPrompts(opts ...RequestOption) ([]Prompt, error) {
 		if cursor != "" {
 			params["cursor"] = cursor
 		}
+		if len(tags) > 0 {
+			params["filter"] = map[string]interface{}{"tags": tags}
+		}
 
 		// Send the prompts/list request
 		result, err := c.sendRequest("prompts/list", params)
@@ -1028,7 +1476,12 @@ func (c *clientImpl) ListPrompts(opts ...RequestOption) ([]Prompt, error) {
 		}
 
 		// Add the prompts to our collection
-		allPrompts = append(allPrompts, apiData.Prompts...)
+		// Servers without the filter extension return everything
+		for _, prompt := range apiData.Prompts {
+			if mcp.HasAllTags(prompt.Annotations, tags) {
+				allPrompts = append(allPrompts, prompt)
+			}
+		}
 
 		// Check if there are more pages
 		if apiData.NextCursor == "" {
@@ -1205,6 +1658,8 @@ func (c *clientImpl) HasCapability(capability string) bool {
 		return c.serverCapabilities.Resources != nil
 	case "tools":
 		return c.serverCapabilities.Tools != nil
+	case "completions":
+		return c.serverCapabilities.Completions != nil
 	case "experimental":
 		return c.serverCapabilities.Experimental != nil
 	default:
@@ -1239,41 +1694,43 @@ func (c *clientImpl) SupportsListChangedNotifications(resourceType string) bool
 
 // WaitForReady waits for the client to be fully connected and ready to handle requests.
 //
-// This method blocks until the client is connected, initialized, and can successfully
-// ping the server, or until the timeout is reached. It's useful for ensuring the
-// server is fully ready before making API calls.
+// This method blocks until the client is connected, initialized, can successfully
+// ping the server and meets the given conditions, or until the timeout is reached.
+// Without conditions, those of WithReadyConditions apply. It's useful for ensuring
+// the server is fully ready before making API calls.
 //
 // Example:
 //
-//	if err := client.WaitForReady(5 * time.Second); err != nil {
+//	if err := client.WaitForReady(5*time.Second, client.ToolsAvailable("search")); err != nil {
 //	    log.Fatal("Server not ready:", err)
 //	}
 //	// Now safe to make API calls
 //	tools, err := client.ListTools()
-func (c *clientImpl) WaitForReady(timeout time.Duration) error {
+func (c *clientImpl) WaitForReady(timeout time.Duration, conditions ...ReadyCondition) error {
 	ctx, cancel := context.WithTimeout(c.ctx, timeout)
 	defer cancel()
 
+	if len(conditions) == 0 {
+		conditions = c.readyConditions
+	}
+
 	// First check if already ready
-	if c.IsConnected() && c.IsInitialized() {
-		if err := c.Ping(); err == nil {
-			return nil
-		}
+	notReady := c.checkReady(conditions)
+	if notReady == nil {
+		return nil
 	}
 
 	// Wait for readiness with polling
-	ticker := time.NewTicker(50 * time.Millisecond)
+	ticker := time.NewTicker(readyPollInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			return fmt.Errorf("client not ready within timeout: %w", ctx.Err())
+			return fmt.Errorf("client not ready within timeout (%v): %w", notReady, ctx.Err())
 		case <-ticker.C:
-			if c.IsConnected() && c.IsInitialized() {
-				if err := c.Ping(); err == nil {
-					return nil
-				}
+			if notReady = c.checkReady(conditions); notReady == nil {
+				return nil
 			}
 		}
 	}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CompletionRef identifies the prompt or resource whose argument is being completed.
+type CompletionRef struct {
+	// Type is "ref/prompt" or "ref/resource"
+	Type string `json:"type"`
+
+	// Name is the prompt name for prompt references
+	Name string `json:"name,omitempty"`
+
+	// URI is the resource URI or URI template for resource references
+	URI string `json:"uri,omitempty"`
+}
+
+// PromptRef returns a reference to a prompt for Complete.
+func PromptRef(name string) CompletionRef {
+	return CompletionRef{Type: "ref/prompt", Name: name}
+}
+
+// ResourceRef returns a reference to a resource for Complete.
+func ResourceRef(uri string) CompletionRef {
+	return CompletionRef{Type: "ref/resource", URI: uri}
+}
+
+// CompletionArgument is the argument being completed and its partial value.
+type CompletionArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CompletionResult holds the suggestions returned by Complete.
+type CompletionResult struct {
+	// Values are the suggestions, at most 100 per response
+	Values []string `json:"values"`
+
+	// Total is the number of suggestions available, if the server reports it
+	Total int `json:"total,omitempty"`
+
+	// HasMore indicates that more suggestions exist than were returned
+	HasMore bool `json:"hasMore,omitempty"`
+}
+
+// Complete requests completion suggestions for an argument of a prompt or resource.
+func (c *clientImpl) Complete(ref CompletionRef, argument CompletionArgument) (*CompletionResult, error) {
+	result, err := c.sendRequest("completion/complete", map[string]interface{}{
+		"ref":      ref,
+		"argument": argument,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete argument: %w", err)
+	}
+
+	// Round-trip the generic result into the typed response
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response format from completion/complete: %w", err)
+	}
+
+	var response struct {
+		Completion CompletionResult `json:"completion"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("invalid response format from completion/complete: %w", err)
+	}
+	if response.Completion.Values == nil {
+		response.Completion.Values = []string{}
+	}
+
+	return &response.Completion, nil
+}
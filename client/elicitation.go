@@ -0,0 +1,101 @@
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/localrivet/gomcp/util/schema"
+)
+
+// ElicitationCreateParams is an alias to the shared mcp.ElicitationCreateParams type.
+type ElicitationCreateParams = mcp.ElicitationCreateParams
+
+// ElicitationResult is an alias to the shared mcp.ElicitationResult type.
+type ElicitationResult = mcp.ElicitationResult
+
+// ElicitationAction is an alias to the shared mcp.ElicitationAction type.
+type ElicitationAction = mcp.ElicitationAction
+
+// Actions an elicitation handler answers with
+const (
+	ElicitationAccept  = mcp.ElicitationAccept
+	ElicitationDecline = mcp.ElicitationDecline
+	ElicitationCancel  = mcp.ElicitationCancel
+)
+
+// ElicitationHandler collects the input a server asks the user for with an
+// elicitation/create request. It shows params.Message to the user, and returns
+// the input with ElicitationAccept, or ElicitationDecline or ElicitationCancel if
+// the user does not give it. Accepted content must match params.RequestedSchema.
+type ElicitationHandler func(params ElicitationCreateParams) (ElicitationResult, error)
+
+// WithElicitationHandler makes the client answer elicitation requests from servers
+// with handler, and declares the elicitation capability when the client
+// initializes. Servers only send elicitation requests to clients that negotiated
+// the draft protocol version.
+//
+// Handlers may take as long as the user does: they run apart from the
+// connection's message loop, so the client keeps receiving messages meanwhile.
+//
+// Example:
+//
+//	c, err := client.NewClient("ws://localhost:8080/mcp",
+//	    client.WithProtocolVersion("draft"),
+//	    client.WithElicitationHandler(func(params client.ElicitationCreateParams) (client.ElicitationResult, error) {
+//	        answers, ok := promptUser(params.Message, params.RequestedSchema)
+//	        if !ok {
+//	            return client.ElicitationResult{Action: client.ElicitationDecline}, nil
+//	        }
+//	        return client.ElicitationResult{Action: client.ElicitationAccept, Content: answers}, nil
+//	    }),
+//	)
+func WithElicitationHandler(handler ElicitationHandler) Option {
+	return func(c *clientImpl) {
+		c.elicitationHandler = handler
+		if handler != nil {
+			c.capabilities.Elicitation = &mcp.ElicitationCapability{}
+		} else {
+			c.capabilities.Elicitation = nil
+		}
+	}
+}
+
+// handleElicitationCreate answers an elicitation request from the server with the
+// client's elicitation handler, checking that accepted content matches the
+// requested schema
+func (c *clientImpl) handleElicitationCreate(id int64, paramsJSON []byte) error {
+	var params ElicitationCreateParams
+	if err := json.Unmarshal(paramsJSON, &params); err != nil {
+		return c.sendJsonRpcErrorResponse(id, -32700, "Parse error", err.Error())
+	}
+	if err := mcp.ValidateElicitationSchema(params.RequestedSchema); err != nil {
+		return c.sendJsonRpcErrorResponse(id, -32602, "Invalid params", err.Error())
+	}
+
+	handler := c.elicitationHandler
+	if handler == nil {
+		return c.sendJsonRpcErrorResponse(id, -32601, "Method not found", "No elicitation handler registered")
+	}
+
+	result, err := handler(params)
+	if err != nil {
+		return c.sendJsonRpcErrorResponse(id, -32603, "Elicitation error", err.Error())
+	}
+
+	// Only accepted input is sent, and it must be what the server asked for
+	if err := mcp.ValidateElicitationAction(result.Action); err != nil {
+		return c.sendJsonRpcErrorResponse(id, -32603, "Invalid elicitation response", err.Error())
+	}
+	if result.Action != ElicitationAccept {
+		result.Content = nil
+	} else {
+		if result.Content == nil {
+			result.Content = map[string]interface{}{}
+		}
+		if err := schema.ValidateArgs(params.RequestedSchema, result.Content); err != nil {
+			return c.sendJsonRpcErrorResponse(id, -32603, "Invalid elicitation response", err.Error())
+		}
+	}
+
+	return c.sendJsonRpcSuccessResponse(id, result)
+}
@@ -166,8 +166,14 @@ func (t *EmbeddedTransport) handleMessage(message []byte) ([]byte, error) {
 		t.mu.RUnlock()
 
 		if handler != nil {
-			// Pass the full message, not just params
-			go handler(method, message)
+			// Pass the full message, not just params. Notifications are passed in the
+			// order they arrive, so that the latest progress is the last one handled;
+			// requests are handled on their own, since answering them may take a while.
+			if _, isRequest := jsonMsg["id"]; isRequest {
+				go handler(method, message)
+			} else {
+				handler(method, message)
+			}
 		}
 	}
 
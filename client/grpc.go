@@ -2,7 +2,9 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/localrivet/gomcp/transport/grpc"
@@ -14,14 +16,50 @@ type GRPCTransport struct {
 	notifyHandler func(method string, params []byte)
 	reqTimeout    time.Duration
 	connTimeout   time.Duration
+	mu            sync.RWMutex
 }
 
 // Connect establishes a connection to the server
 func (t *GRPCTransport) Connect() error {
+	if t.connTimeout > 0 {
+		grpc.WithConnectionTimeout(t.connTimeout)(t.transport)
+	}
 	if err := t.transport.Initialize(); err != nil {
 		return fmt.Errorf("failed to initialize gRPC transport: %w", err)
 	}
-	return t.transport.Start()
+	if err := t.transport.Start(); err != nil {
+		return err
+	}
+
+	go t.forwardNotifications()
+	return nil
+}
+
+// forwardNotifications passes server-initiated messages to the notification handler
+// until the transport stops
+func (t *GRPCTransport) forwardNotifications() {
+	for {
+		message, err := t.transport.Receive()
+		if err != nil || message == nil {
+			// The transport stopped or the stream closed
+			return
+		}
+
+		var envelope struct {
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal(message, &envelope); err != nil || envelope.Method == "" {
+			continue
+		}
+
+		t.mu.RLock()
+		handler := t.notifyHandler
+		t.mu.RUnlock()
+		if handler != nil {
+			// The handler receives the whole JSON-RPC message
+			handler(envelope.Method, message)
+		}
+	}
 }
 
 // ConnectWithContext establishes a connection to the server with context
@@ -68,14 +106,13 @@ func (t *GRPCTransport) SetRequestTimeout(timeout time.Duration) {
 // SetConnectionTimeout sets the default timeout for connection operations
 func (t *GRPCTransport) SetConnectionTimeout(timeout time.Duration) {
 	t.connTimeout = timeout
-	// Could also update the transport's connection timeout if needed
 }
 
 // RegisterNotificationHandler registers a handler for server-initiated messages
 func (t *GRPCTransport) RegisterNotificationHandler(handler func(method string, params []byte)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.notifyHandler = handler
-	// Set up a goroutine to listen for notifications from the gRPC transport
-	// and forward them to the handler
 }
 
 // WithGRPC returns a client configuration option that uses gRPC transport.
@@ -123,6 +160,20 @@ func WithGRPCMaxMessageSize(size int) grpc.Option {
 	return grpc.WithMaxMessageSize(size)
 }
 
+// WithGRPCClientID sets the client ID sent when the gRPC session is initialized.
+func WithGRPCClientID(id string) grpc.Option {
+	return grpc.WithClientID(id)
+}
+
+// DiscoverGRPCServices lists the services of a gRPC server through server reflection,
+// which the server enables with server.WithGRPCReflection. Use it to check that a
+// server exposes the MCP service (grpc.ServiceName) before connecting.
+//
+// The options configure the connection as for WithGRPC.
+func DiscoverGRPCServices(ctx context.Context, address string, options ...grpc.Option) ([]string, error) {
+	return grpc.ListServices(ctx, address, options...)
+}
+
 // DefaultGRPCClientOptions returns a set of default options for gRPC client.
 func DefaultGRPCClientOptions() []grpc.Option {
 	return []grpc.Option{
@@ -3,10 +3,19 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/localrivet/gomcp/transport"
+	httptransport "github.com/localrivet/gomcp/transport/http"
 )
 
 // HTTPOption is a function that configures an HTTP transport.
@@ -23,6 +32,13 @@ type httpConfig struct {
 	pollInterval  time.Duration
 	retryAttempts int
 	retryDelay    time.Duration
+	tlsConfig     *tls.Config
+
+	// roundTripper, when set, replaces the transport of the client
+	roundTripper *http.Transport
+
+	// legacySSEFallback enables detection of servers that only speak HTTP+SSE
+	legacySSEFallback bool
 }
 
 // WithHTTPClient sets a custom HTTP client for the HTTP transport.
@@ -32,6 +48,22 @@ func WithHTTPClient(client *http.Client) HTTPOption {
 	}
 }
 
+// WithHTTPTransport sets the round tripper of the HTTP client, which controls
+// connection pooling, keep-alives and HTTP/2. It replaces the transport of a
+// client set with WithHTTPClient.
+func WithHTTPTransport(roundTripper *http.Transport) HTTPOption {
+	return func(cfg *httpConfig) {
+		cfg.roundTripper = roundTripper
+	}
+}
+
+// WithHTTPConnectionPool tunes how the HTTP client pools connections, for example
+// to raise the idle connections kept per host for a proxy forwarding many
+// concurrent requests. Without it, transport.DefaultConnectionPool applies.
+func WithHTTPConnectionPool(pool transport.ConnectionPool) HTTPOption {
+	return WithHTTPTransport(transport.NewHTTPTransport(pool))
+}
+
 // WithHTTPHeader adds a custom header to HTTP requests.
 func WithHTTPHeader(key, value string) HTTPOption {
 	return func(cfg *httpConfig) {
@@ -71,6 +103,26 @@ func WithHTTPTimeout(timeout time.Duration) HTTPOption {
 	}
 }
 
+// WithHTTPTLSConfig sets the TLS configuration for HTTPS connections, for example
+// to trust a private CA or to present a client certificate to a server requiring
+// mutual TLS. transport.LoadClientTLSConfig builds one from PEM files.
+func WithHTTPTLSConfig(config *tls.Config) HTTPOption {
+	return func(cfg *httpConfig) {
+		cfg.tlsConfig = config
+	}
+}
+
+// WithHTTPLegacySSEFallback enables or disables falling back to the HTTP+SSE
+// transport of protocol version 2024-11-05. When enabled, the default, a server
+// that rejects the initialize POST with 400, 404 or 405 is probed for an event
+// stream at the URL and at the /sse path next to it; if the stream announces a
+// message endpoint, the client keeps talking to the server over it.
+func WithHTTPLegacySSEFallback(enabled bool) HTTPOption {
+	return func(cfg *httpConfig) {
+		cfg.legacySSEFallback = enabled
+	}
+}
+
 // withHTTPTransport creates an adapter that implements the Transport interface
 // for HTTP communication.
 func withHTTPTransport(cfg *httpConfig) Transport {
@@ -81,6 +133,7 @@ func withHTTPTransport(cfg *httpConfig) Transport {
 		requestTimeout:    cfg.timeout,
 		connectionTimeout: cfg.timeout,
 		headers:           cfg.headers,
+		legacyFallback:    cfg.legacySSEFallback,
 	}
 }
 
@@ -108,7 +161,12 @@ func WithHTTP(url string, options ...HTTPOption) Option {
 			pollInterval:  2 * time.Second,
 			retryAttempts: 3,
 			retryDelay:    500 * time.Millisecond,
-			client:        &http.Client{Timeout: 30 * time.Second},
+			client: &http.Client{
+				Timeout:   30 * time.Second,
+				Transport: transport.NewHTTPTransport(transport.DefaultConnectionPool()),
+			},
+
+			legacySSEFallback: true,
 		}
 
 		// Apply options
@@ -116,6 +174,16 @@ func WithHTTP(url string, options ...HTTPOption) Option {
 			option(cfg)
 		}
 
+		// Applied last so that they also cover a client set with WithHTTPClient
+		if cfg.roundTripper != nil {
+			withTransport := *cfg.client
+			withTransport.Transport = cfg.roundTripper
+			cfg.client = &withTransport
+		}
+		if cfg.tlsConfig != nil {
+			cfg.client = transport.HTTPClientWithTLS(cfg.client, cfg.tlsConfig)
+		}
+
 		// Set the transport
 		c.transport = withHTTPTransport(cfg)
 
@@ -131,8 +199,20 @@ type httpTransport struct {
 	client              *http.Client
 	requestTimeout      time.Duration
 	connectionTimeout   time.Duration
-	notificationHandler func(method string, params []byte)
+	notificationHandler atomic.Pointer[func(method string, params []byte)]
 	headers             map[string]string
+
+	// sessionID is the Mcp-Session-Id assigned by the server on initialize, and
+	// stream the session's event stream
+	sessionMu sync.Mutex
+	sessionID string
+	stream    *httpEventStream
+
+	// legacy is the HTTP+SSE session used once the server turned out to predate
+	// Streamable HTTP
+	legacyFallback bool
+	legacyMu       sync.Mutex
+	legacy         *legacySSESession
 }
 
 // Connect implements the Transport interface.
@@ -148,9 +228,64 @@ func (t *httpTransport) ConnectWithContext(ctx context.Context) error {
 }
 
 // Disconnect implements the Transport interface.
+// If the server assigned a session, it is terminated with an HTTP DELETE.
 func (t *httpTransport) Disconnect() error {
-	// Nothing to disconnect for HTTP
-	return nil
+	t.legacyMu.Lock()
+	if t.legacy != nil {
+		t.legacy.close()
+		t.legacy = nil
+	}
+	t.legacyMu.Unlock()
+
+	sessionID := t.SessionID()
+	if sessionID == "" {
+		return nil
+	}
+	t.setSessionID("")
+	t.setEventStream(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.requestTimeout)
+	defer cancel()
+	return terminateHTTPSession(ctx, t.client, t.url, sessionID, t.headers)
+}
+
+// SessionID returns the session ID assigned by the server, or an empty string.
+func (t *httpTransport) SessionID() string {
+	t.sessionMu.Lock()
+	defer t.sessionMu.Unlock()
+	return t.sessionID
+}
+
+// setSessionID stores the session ID to replay on subsequent requests.
+func (t *httpTransport) setSessionID(sessionID string) {
+	t.sessionMu.Lock()
+	t.sessionID = sessionID
+	t.sessionMu.Unlock()
+}
+
+// eventStream returns the session's event stream, or nil.
+func (t *httpTransport) eventStream() *httpEventStream {
+	t.sessionMu.Lock()
+	defer t.sessionMu.Unlock()
+	return t.stream
+}
+
+// setEventStream replaces the session's event stream, closing the previous one.
+func (t *httpTransport) setEventStream(stream *httpEventStream) {
+	t.sessionMu.Lock()
+	previous := t.stream
+	t.stream = stream
+	t.sessionMu.Unlock()
+	if previous != nil {
+		previous.close()
+	}
+}
+
+// notify passes a message from the server to the notification handler.
+func (t *httpTransport) notify(message []byte) {
+	if handler := t.notificationHandler.Load(); handler != nil {
+		(*handler)("", message)
+	}
 }
 
 // Send implements the Transport interface.
@@ -162,6 +297,13 @@ func (t *httpTransport) Send(message []byte) ([]byte, error) {
 
 // SendWithContext implements the Transport interface.
 func (t *httpTransport) SendWithContext(ctx context.Context, message []byte) ([]byte, error) {
+	t.legacyMu.Lock()
+	legacy := t.legacy
+	t.legacyMu.Unlock()
+	if legacy != nil {
+		return legacy.send(ctx, message)
+	}
+
 	// Prepare the request
 	req, err := http.NewRequestWithContext(ctx, "POST", t.url, bytes.NewReader(message))
 	if err != nil {
@@ -173,6 +315,10 @@ func (t *httpTransport) SendWithContext(ctx context.Context, message []byte) ([]
 	for k, v := range t.headers {
 		req.Header.Set(k, v)
 	}
+	sessionID := t.SessionID()
+	if sessionID != "" {
+		req.Header.Set(httptransport.SessionIDHeader, sessionID)
+	}
 
 	// Send the request
 	resp, err := t.client.Do(req)
@@ -181,20 +327,96 @@ func (t *httpTransport) SendWithContext(ctx context.Context, message []byte) ([]
 	}
 	defer resp.Body.Close()
 
+	// Remember the session assigned on initialize
+	assigned := resp.Header.Get(httptransport.SessionIDHeader)
+	if assigned != "" {
+		t.setSessionID(assigned)
+	}
+
+	// The server no longer knows our session, so the client must initialize again
+	if resp.StatusCode == http.StatusNotFound && sessionID != "" {
+		t.setSessionID("")
+		t.setEventStream(nil)
+		return nil, fmt.Errorf("HTTP session %s expired: %w", sessionID, ErrSessionExpired)
+	}
+
+	// Servers predating Streamable HTTP reject the initialize POST
+	if t.legacyFallback && sessionID == "" && isLegacySSEFallbackStatus(resp.StatusCode) && isInitializeMessage(message) {
+		legacy, err := t.connectLegacySSE(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("HTTP request failed with status: %d; %w", resp.StatusCode, err)
+		}
+		return legacy.send(ctx, message)
+	}
+
 	// Check response status
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("HTTP request failed with status: %d", resp.StatusCode)
 	}
 
+	// The server sends its own messages to a new session on an event stream
+	if assigned != "" && assigned != sessionID {
+		t.setEventStream(openHTTPEventStream(t.client, t.url, assigned, t.headers, t.notify))
+	}
+
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
+	// Deliver what the server sent while handling a request before its response,
+	// such as the request's progress
+	if lastEvent, err := strconv.ParseUint(resp.Header.Get(httptransport.LastEventIDHeader), 10, 64); err == nil && transport.IsRequest(message) {
+		if stream := t.eventStream(); stream != nil {
+			stream.waitFor(ctx, lastEvent)
+		}
+	}
+
 	return body, nil
 }
 
+// connectLegacySSE opens an HTTP+SSE session, trying the server URL and then the
+// conventional /sse path
+func (t *httpTransport) connectLegacySSE(ctx context.Context) (*legacySSESession, error) {
+	var failures []error
+	for _, streamURL := range legacySSEURLs(t.url) {
+		// The client registers its handler after initialize, so look it up per message
+		notify := func(method string, params []byte) {
+			if handler := t.notificationHandler.Load(); handler != nil {
+				(*handler)(method, params)
+			}
+		}
+		legacy, err := dialLegacySSE(ctx, t.client, streamURL, t.headers, notify)
+		if err != nil {
+			failures = append(failures, err)
+			continue
+		}
+
+		t.legacyMu.Lock()
+		t.legacy = legacy
+		t.legacyMu.Unlock()
+		return legacy, nil
+	}
+	return nil, fmt.Errorf("no legacy SSE endpoint found: %w", errors.Join(failures...))
+}
+
+// UsesLegacySSE reports whether the transport fell back to the HTTP+SSE
+// transport of protocol version 2024-11-05.
+func (t *httpTransport) UsesLegacySSE() bool {
+	t.legacyMu.Lock()
+	defer t.legacyMu.Unlock()
+	return t.legacy != nil
+}
+
+// isInitializeMessage reports whether a message is an initialize request
+func isInitializeMessage(message []byte) bool {
+	var request struct {
+		Method string `json:"method"`
+	}
+	return json.Unmarshal(message, &request) == nil && request.Method == "initialize"
+}
+
 // SetRequestTimeout implements the Transport interface.
 func (t *httpTransport) SetRequestTimeout(timeout time.Duration) {
 	t.requestTimeout = timeout
@@ -210,5 +432,36 @@ func (t *httpTransport) SetConnectionTimeout(timeout time.Duration) {
 
 // RegisterNotificationHandler implements the Transport interface.
 func (t *httpTransport) RegisterNotificationHandler(handler func(method string, params []byte)) {
-	t.notificationHandler = handler
+	t.notificationHandler.Store(&handler)
+}
+
+// ErrSessionExpired is returned by the HTTP transport when the server rejects
+// the session ID with 404 Not Found. The client must initialize a new session.
+var ErrSessionExpired = errors.New("session expired")
+
+// terminateHTTPSession asks the server to terminate a session with an HTTP DELETE.
+// Servers that do not allow clients to terminate sessions answer 405, which is not
+// treated as an error.
+func terminateHTTPSession(ctx context.Context, client *http.Client, url, sessionID string, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create session termination request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set(httptransport.SessionIDHeader, sessionID)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to terminate session: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound, http.StatusMethodNotAllowed:
+		return nil
+	default:
+		return fmt.Errorf("session termination failed with status: %d", resp.StatusCode)
+	}
 }
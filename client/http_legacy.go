@@ -0,0 +1,308 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// errLegacySSEClosed is returned for requests pending when the event stream closes
+var errLegacySSEClosed = errors.New("legacy SSE event stream closed")
+
+// legacySSESession is a connection to a server that only speaks the HTTP+SSE
+// transport of protocol version 2024-11-05: the client keeps a GET event stream
+// open, posts messages to the endpoint announced in the stream's first event, and
+// receives responses over the stream.
+type legacySSESession struct {
+	client   *http.Client
+	headers  map[string]string
+	endpoint string
+	body     io.ReadCloser
+	cancel   context.CancelFunc
+	notify   func(method string, params []byte)
+
+	mu      sync.Mutex
+	pending map[string]chan []byte
+	err     error
+	done    chan struct{}
+}
+
+// isLegacySSEFallbackStatus reports whether a failed initialize POST indicates a
+// server without Streamable HTTP support, as the 2025-03-26 specification
+// describes for backwards compatibility
+func isLegacySSEFallbackStatus(status int) bool {
+	return status == http.StatusBadRequest || status == http.StatusNotFound || status == http.StatusMethodNotAllowed
+}
+
+// legacySSEURLs returns the URLs to open the event stream at: the server URL
+// itself, then the conventional /sse path next to it
+func legacySSEURLs(serverURL string) []string {
+	urls := []string{serverURL}
+	parsed, err := url.Parse(serverURL)
+	if err != nil || strings.HasSuffix(parsed.Path, "/sse") {
+		return urls
+	}
+
+	sibling := *parsed
+	sibling.RawQuery = ""
+	if i := strings.LastIndex(sibling.Path, "/"); i >= 0 {
+		sibling.Path = sibling.Path[:i]
+	}
+	sibling.Path += "/sse"
+	return append(urls, sibling.String())
+}
+
+// dialLegacySSE opens the event stream and waits for the endpoint event
+func dialLegacySSE(ctx context.Context, client *http.Client, streamURL string, headers map[string]string, notify func(method string, params []byte)) (*legacySSESession, error) {
+	// The stream outlives the dial context, so it gets a context of its own
+	streamCtx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, streamURL, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	// The client's timeout would cut the stream off
+	streamClient := *client
+	streamClient.Timeout = 0
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("no event stream at %s (status %d)", streamURL, resp.StatusCode)
+	}
+
+	s := &legacySSESession{
+		client:  client,
+		headers: headers,
+		body:    resp.Body,
+		cancel:  cancel,
+		notify:  notify,
+		pending: make(map[string]chan []byte),
+		done:    make(chan struct{}),
+	}
+
+	events := newSSEEventReader(resp.Body)
+	endpointCh := make(chan error, 1)
+	go func() {
+		event, data, err := events.next()
+		if err == nil && event != "endpoint" {
+			err = fmt.Errorf("expected endpoint event, got %q", event)
+		}
+		if err == nil {
+			s.endpoint, err = resolveLegacyEndpoint(streamURL, strings.TrimSpace(string(data)))
+		}
+		endpointCh <- err
+	}()
+
+	select {
+	case err = <-endpointCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		s.close()
+		return nil, fmt.Errorf("legacy SSE handshake with %s failed: %w", streamURL, err)
+	}
+
+	go s.read(events)
+	return s, nil
+}
+
+// resolveLegacyEndpoint resolves the endpoint event's URI, usually a path with a
+// session query, against the stream URL
+func resolveLegacyEndpoint(streamURL, endpoint string) (string, error) {
+	base, err := url.Parse(streamURL)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// read delivers the stream's messages until it closes
+func (s *legacySSESession) read(events *sseEventReader) {
+	var err error
+	for {
+		var event string
+		var data []byte
+		event, data, err = events.next()
+		if err != nil {
+			break
+		}
+		if event != "" && event != "message" {
+			continue
+		}
+		s.deliver(data)
+	}
+
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	close(s.done)
+}
+
+// deliver passes a message to the request waiting for it, or to the
+// notification handler
+func (s *legacySSESession) deliver(message []byte) {
+	var envelope struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return
+	}
+
+	if envelope.Method == "" && len(envelope.ID) > 0 {
+		s.mu.Lock()
+		responseCh, ok := s.pending[string(envelope.ID)]
+		delete(s.pending, string(envelope.ID))
+		s.mu.Unlock()
+		if ok {
+			responseCh <- message
+		}
+		return
+	}
+
+	if s.notify != nil {
+		s.notify("", message)
+	}
+}
+
+// send posts a message to the endpoint, and waits for the response to arrive on
+// the stream if the message is a request
+func (s *legacySSESession) send(ctx context.Context, message []byte) ([]byte, error) {
+	var envelope struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid JSON message: %w", err)
+	}
+
+	var responseCh chan []byte
+	if len(envelope.ID) > 0 && envelope.Method != "" {
+		key := string(envelope.ID)
+		responseCh = make(chan []byte, 1)
+		s.mu.Lock()
+		s.pending[key] = responseCh
+		s.mu.Unlock()
+		defer func() {
+			s.mu.Lock()
+			delete(s.pending, key)
+			s.mu.Unlock()
+		}()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(message))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return nil, fmt.Errorf("HTTP request failed with status: %d", resp.StatusCode)
+	}
+
+	if responseCh == nil {
+		return []byte{}, nil
+	}
+	// Some servers answer in the POST body as well as on the stream
+	if len(bytes.TrimSpace(body)) > 0 && json.Valid(body) {
+		return body, nil
+	}
+
+	select {
+	case response := <-responseCh:
+		return response, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.done:
+		s.mu.Lock()
+		err := s.err
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %v", errLegacySSEClosed, err)
+	}
+}
+
+// close closes the event stream
+func (s *legacySSESession) close() {
+	s.cancel()
+	s.body.Close()
+}
+
+// sseEventReader parses a text/event-stream into events
+type sseEventReader struct {
+	reader *bufio.Reader
+
+	// lastID is the ID of the last event that set one
+	lastID string
+}
+
+// newSSEEventReader creates an event reader for a stream
+func newSSEEventReader(r io.Reader) *sseEventReader {
+	return &sseEventReader{reader: bufio.NewReader(r)}
+}
+
+// next returns the type and data of the next event; multi-line data is joined
+// with newlines
+func (r *sseEventReader) next() (string, []byte, error) {
+	var event string
+	var data [][]byte
+	for {
+		line, err := r.reader.ReadBytes('\n')
+		if err != nil && len(line) == 0 {
+			return "", nil, err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+
+		switch {
+		case len(line) == 0:
+			if len(data) > 0 {
+				return event, bytes.Join(data, []byte("\n")), nil
+			}
+			event = ""
+		case bytes.HasPrefix(line, []byte(":")):
+			// Comment or keep-alive
+		case bytes.HasPrefix(line, []byte("id:")):
+			r.lastID = string(bytes.TrimSpace(bytes.TrimPrefix(line, []byte("id:"))))
+		case bytes.HasPrefix(line, []byte("event:")):
+			event = string(bytes.TrimSpace(bytes.TrimPrefix(line, []byte("event:"))))
+		case bytes.HasPrefix(line, []byte("data:")):
+			value := bytes.TrimPrefix(line, []byte("data:"))
+			data = append(data, bytes.TrimPrefix(value, []byte(" ")))
+		}
+
+		if err != nil {
+			return "", nil, err
+		}
+	}
+}
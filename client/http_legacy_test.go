@@ -0,0 +1,126 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// legacySSEServer serves the 2024-11-05 HTTP+SSE transport: an event stream at
+// /sse and a message endpoint whose responses go out on the stream
+func legacySSEServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	messages := make(chan string, 10)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: endpoint\ndata: /messages?session_id=abc\n\n")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case message := <-messages:
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", message)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("/messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("session_id") != "abc" {
+			http.Error(w, "unknown session", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+		switch {
+		case strings.Contains(string(body), `"initialize"`):
+			messages <- `{"jsonrpc":"2.0","method":"notifications/message","params":{"level":"info","data":"hi"}}`
+			messages <- `{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2024-11-05"}}`
+		case strings.Contains(string(body), `"tools/list"`):
+			messages <- `{"jsonrpc":"2.0","id":2,"result":{"tools":[]}}`
+		}
+	})
+	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestHTTPTransportLegacySSEFallback(t *testing.T) {
+	server := legacySSEServer(t)
+	defer server.Close()
+
+	notified := make(chan string, 1)
+	tr := &httpTransport{url: server.URL + "/mcp", client: server.Client(), requestTimeout: 2 * time.Second, legacyFallback: true}
+	tr.RegisterNotificationHandler(func(method string, params []byte) {
+		notified <- string(params)
+	})
+
+	response, err := tr.Send([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`))
+	if err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	if !strings.Contains(string(response), "2024-11-05") {
+		t.Errorf("expected the initialize result from the event stream, got %s", response)
+	}
+	if !tr.UsesLegacySSE() {
+		t.Fatal("expected the transport to fall back to HTTP+SSE")
+	}
+
+	select {
+	case message := <-notified:
+		if !strings.Contains(message, "notifications/message") {
+			t.Errorf("unexpected notification %s", message)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("expected the notification to reach the handler")
+	}
+
+	response, err = tr.Send([]byte(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`))
+	if err != nil {
+		t.Fatalf("tools/list failed: %v", err)
+	}
+	if !strings.Contains(string(response), `"tools"`) {
+		t.Errorf("unexpected tools/list response %s", response)
+	}
+
+	if err := tr.Disconnect(); err != nil {
+		t.Fatalf("disconnect failed: %v", err)
+	}
+	if tr.UsesLegacySSE() {
+		t.Error("expected the legacy session to be closed on disconnect")
+	}
+}
+
+func TestHTTPTransportLegacySSEFallbackDisabled(t *testing.T) {
+	server := legacySSEServer(t)
+	defer server.Close()
+
+	tr := &httpTransport{url: server.URL + "/mcp", client: server.Client(), requestTimeout: time.Second}
+	if _, err := tr.Send([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`)); err == nil {
+		t.Fatal("expected initialize to fail without the fallback")
+	}
+	if tr.UsesLegacySSE() {
+		t.Error("expected no legacy session")
+	}
+}
+
+func TestLegacySSEURLs(t *testing.T) {
+	tests := map[string][]string{
+		"http://localhost:8080/mcp": {"http://localhost:8080/mcp", "http://localhost:8080/sse"},
+		"http://localhost:8080":     {"http://localhost:8080", "http://localhost:8080/sse"},
+		"http://localhost:8080/sse": {"http://localhost:8080/sse"},
+	}
+	for input, want := range tests {
+		got := legacySSEURLs(input)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("legacySSEURLs(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/transport"
+)
+
+// httpRoundTripper returns the round tripper of the client WithHTTP configures
+func httpRoundTripper(t *testing.T, options ...HTTPOption) *http.Transport {
+	t.Helper()
+	c := &clientImpl{}
+	WithHTTP("http://localhost:8080/mcp", options...)(c)
+	roundTripper, ok := c.transport.(*httpTransport).client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Expected an *http.Transport, got %T", c.transport.(*httpTransport).client.Transport)
+	}
+	return roundTripper
+}
+
+func TestHTTPConnectionPool(t *testing.T) {
+	if rt := httpRoundTripper(t); rt.MaxIdleConnsPerHost != transport.DefaultConnectionPool().MaxIdleConnsPerHost {
+		t.Errorf("Expected the default pool, got %d idle connections per host", rt.MaxIdleConnsPerHost)
+	}
+
+	pool := transport.DefaultConnectionPool()
+	pool.MaxConnsPerHost = 8
+	if rt := httpRoundTripper(t, WithHTTPConnectionPool(pool)); rt.MaxConnsPerHost != 8 {
+		t.Errorf("Expected 8 connections per host, got %d", rt.MaxConnsPerHost)
+	}
+
+	// A custom transport replaces the transport of a custom client, and keeps its
+	// settings when TLS is configured
+	custom := &http.Transport{MaxIdleConnsPerHost: 64}
+	rt := httpRoundTripper(t,
+		WithHTTPClient(&http.Client{Timeout: time.Second}),
+		WithHTTPTransport(custom),
+		WithHTTPTLSConfig(&tls.Config{ServerName: "mcp.internal"}))
+	if rt.MaxIdleConnsPerHost != 64 || rt.TLSClientConfig.ServerName != "mcp.internal" {
+		t.Errorf("Unexpected transport settings %+v", rt)
+	}
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	httptransport "github.com/localrivet/gomcp/transport/http"
+)
+
+func TestHTTPTransportSessionID(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	terminated := ""
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.Header.Get(httptransport.SessionIDHeader)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet:
+			// A server without event streams
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case r.Method == http.MethodDelete:
+			terminated = sessionID
+			w.WriteHeader(http.StatusOK)
+		case sessionID == "":
+			w.Header().Set(httptransport.SessionIDHeader, "session-1")
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+		case sessionID == "session-1" && terminated == "":
+			seen = append(seen, sessionID)
+			w.Write([]byte(`{"jsonrpc":"2.0","id":2,"result":{}}`))
+		default:
+			http.Error(w, "Session not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tr := &httpTransport{url: server.URL, client: server.Client(), requestTimeout: time.Second}
+
+	if _, err := tr.Send([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`)); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	if tr.SessionID() != "session-1" {
+		t.Fatalf("expected session-1 to be stored, got %q", tr.SessionID())
+	}
+
+	if _, err := tr.Send([]byte(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)); err != nil {
+		t.Fatalf("request with session failed: %v", err)
+	}
+
+	mu.Lock()
+	if len(seen) != 1 || seen[0] != "session-1" {
+		t.Errorf("expected session header to be replayed, got %v", seen)
+	}
+	mu.Unlock()
+
+	if err := tr.Disconnect(); err != nil {
+		t.Fatalf("disconnect failed: %v", err)
+	}
+	mu.Lock()
+	if terminated != "session-1" {
+		t.Errorf("expected DELETE for session-1, got %q", terminated)
+	}
+	mu.Unlock()
+	if tr.SessionID() != "" {
+		t.Errorf("expected session to be cleared after disconnect, got %q", tr.SessionID())
+	}
+
+	// A session the server has forgotten surfaces as ErrSessionExpired
+	tr.setSessionID("session-1")
+	_, err := tr.Send([]byte(`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`))
+	if !errors.Is(err, ErrSessionExpired) {
+		t.Errorf("expected ErrSessionExpired, got %v", err)
+	}
+	if tr.SessionID() != "" {
+		t.Errorf("expected expired session to be cleared, got %q", tr.SessionID())
+	}
+}
+
+func TestHTTPTransportEventStream(t *testing.T) {
+	events := make(chan string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet:
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			for event := range events {
+				w.Write([]byte(event))
+				w.(http.Flusher).Flush()
+			}
+		case r.Header.Get(httptransport.SessionIDHeader) == "":
+			w.Header().Set(httptransport.SessionIDHeader, "session-1")
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+		default:
+			// The progress is sent while the request is handled, but written to the
+			// stream only after the response
+			w.Header().Set(httptransport.LastEventIDHeader, "1")
+			w.Write([]byte(`{"jsonrpc":"2.0","id":2,"result":{}}`))
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				events <- "id: 1\nevent: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n"
+				close(events)
+			}()
+		}
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var received []string
+	tr := &httpTransport{url: server.URL, client: server.Client(), requestTimeout: time.Second}
+	tr.RegisterNotificationHandler(func(method string, params []byte) {
+		mu.Lock()
+		received = append(received, string(params))
+		mu.Unlock()
+	})
+	defer tr.setEventStream(nil)
+
+	if _, err := tr.Send([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`)); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	if _, err := tr.Send([]byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call"}`)); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	// The event sent before the response is delivered before the response returns
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 || received[0] != `{"jsonrpc":"2.0","method":"notifications/progress"}` {
+		t.Errorf("expected the progress before the response, got %v", received)
+	}
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	httptransport "github.com/localrivet/gomcp/transport/http"
+)
+
+// eventStreamCatchUp bounds how long a response waits for the events the server
+// sent before it, should the stream fall behind for good, as it does while a
+// notification handler waits on a request of its own
+const eventStreamCatchUp = 2 * time.Second
+
+// httpEventStream is the GET event stream of a Streamable HTTP session, on which
+// the server sends its notifications and requests. Events are delivered in order,
+// and the ID of the last one delivered is kept, so that a response can wait for
+// the events the server sent before it.
+type httpEventStream struct {
+	cancel context.CancelFunc
+	done   chan struct{} // closed when the stream ends
+
+	mu        sync.Mutex
+	delivered uint64
+	changed   chan struct{} // closed and replaced whenever delivered grows
+}
+
+// openHTTPEventStream opens the event stream of a session in the background,
+// passing every message to notify. Servers without event streams answer the GET
+// with an error, which ends the stream at once.
+func openHTTPEventStream(client *http.Client, url, sessionID string, headers map[string]string, notify func(message []byte)) *httpEventStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &httpEventStream{
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		changed: make(chan struct{}),
+	}
+	go s.read(ctx, client, url, sessionID, headers, notify)
+	return s
+}
+
+// read opens the stream and delivers its messages until it ends
+func (s *httpEventStream) read(ctx context.Context, client *http.Client, url, sessionID string, headers map[string]string, notify func(message []byte)) {
+	defer close(s.done)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(httptransport.SessionIDHeader, sessionID)
+
+	// The client's timeout would cut the stream off
+	streamClient := *client
+	streamClient.Timeout = 0
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return
+	}
+
+	events := newSSEEventReader(resp.Body)
+	for {
+		event, data, err := events.next()
+		if err != nil {
+			return
+		}
+		if event == "" || event == "message" {
+			notify(data)
+		}
+		if id, err := strconv.ParseUint(events.lastID, 10, 64); err == nil {
+			s.mu.Lock()
+			if id > s.delivered {
+				s.delivered = id
+				close(s.changed)
+				s.changed = make(chan struct{})
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// waitFor waits until the event with the given ID was delivered, the stream ended,
+// ctx is done or eventStreamCatchUp has passed
+func (s *httpEventStream) waitFor(ctx context.Context, id uint64) {
+	timeout := time.NewTimer(eventStreamCatchUp)
+	defer timeout.Stop()
+	for {
+		s.mu.Lock()
+		delivered, changed := s.delivered, s.changed
+		s.mu.Unlock()
+		if delivered >= id {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-s.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-timeout.C:
+			return
+		}
+	}
+}
+
+// close ends the stream
+func (s *httpEventStream) close() {
+	s.cancel()
+}
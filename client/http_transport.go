@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	httptransport "github.com/localrivet/gomcp/transport/http"
@@ -19,6 +20,10 @@ type HTTPTransportAdapter struct {
 	notificationHandler func(method string, params []byte)
 	client              *http.Client
 	connected           bool
+
+	// sessionID is the Mcp-Session-Id assigned by the server on initialize
+	sessionMu sync.Mutex
+	sessionID string
 }
 
 // NewHTTPTransportAdapter creates a new HTTP transport adapter.
@@ -61,9 +66,22 @@ func (t *HTTPTransportAdapter) ConnectWithContext(ctx context.Context) error {
 }
 
 // Disconnect implements the Transport interface Disconnect method.
+// If the server assigned a session, it is terminated with an HTTP DELETE.
 func (t *HTTPTransportAdapter) Disconnect() error {
 	t.connected = false
-	return nil
+
+	t.sessionMu.Lock()
+	sessionID := t.sessionID
+	t.sessionID = ""
+	t.sessionMu.Unlock()
+
+	if sessionID == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.requestTimeout)
+	defer cancel()
+	return terminateHTTPSession(ctx, t.client, t.transport.GetAddr(), sessionID, nil)
 }
 
 // Send implements the Transport interface Send method.
@@ -84,6 +102,13 @@ func (t *HTTPTransportAdapter) SendWithContext(ctx context.Context, message []by
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
 
+	t.sessionMu.Lock()
+	sessionID := t.sessionID
+	t.sessionMu.Unlock()
+	if sessionID != "" {
+		req.Header.Set(httptransport.SessionIDHeader, sessionID)
+	}
+
 	// Send the request
 	resp, err := t.client.Do(req)
 	if err != nil {
@@ -91,6 +116,19 @@ func (t *HTTPTransportAdapter) SendWithContext(ctx context.Context, message []by
 	}
 	defer resp.Body.Close()
 
+	// Remember the session assigned on initialize, forget it once the server has
+	t.sessionMu.Lock()
+	if assigned := resp.Header.Get(httptransport.SessionIDHeader); assigned != "" {
+		t.sessionID = assigned
+	} else if resp.StatusCode == http.StatusNotFound && sessionID != "" {
+		t.sessionID = ""
+	}
+	t.sessionMu.Unlock()
+
+	if resp.StatusCode == http.StatusNotFound && sessionID != "" {
+		return nil, fmt.Errorf("HTTP session %s expired: %w", sessionID, ErrSessionExpired)
+	}
+
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/localrivet/gomcp/events"
 	"github.com/localrivet/gomcp/mcp"
@@ -21,6 +22,11 @@ func (c *clientImpl) Connect() error {
 		return nil
 	}
 
+	// Probe transports in order if asked to (see WithTransportFallback)
+	if c.transport == nil && c.usesTransportFallback() {
+		return c.connectFallback()
+	}
+
 	// If no transport has been set, select an appropriate one based on the URL
 	if c.transport == nil {
 		// Select transport based on URL scheme
@@ -45,19 +51,36 @@ func (c *clientImpl) Connect() error {
 		}
 	}
 
+	return c.connectTransport()
+}
+
+// connectTransport connects the client's transport and initializes the connection
+func (c *clientImpl) connectTransport() error {
 	// Set the timeout on the transport
 	c.transport.SetConnectionTimeout(c.connectionTimeout)
 	c.transport.SetRequestTimeout(c.requestTimeout)
 
 	// Connect to the server
 	if err := c.transport.Connect(); err != nil {
-		return fmt.Errorf("failed to connect to server: %w", err)
+		return fmt.Errorf("failed to connect to server: %w", newTransportError("connect", c.url, err))
 	}
 
 	c.connected = true
 
-	// Initialize the connection by negotiating the protocol version
-	if err := c.initialize(); err != nil {
+	// Initialize the connection by negotiating the protocol version, retrying
+	// servers that are still starting (see WithInitializeRetry)
+	err := c.initialize()
+	for attempt := 1; err != nil && attempt < c.initializeAttempts && isRetryableInitializeError(err); attempt++ {
+		if c.logger != nil {
+			c.logger.Warn("initialize failed, retrying", "attempt", attempt, "error", err)
+		}
+		select {
+		case <-time.After(c.initializeRetryDelay):
+		case <-c.ctx.Done():
+		}
+		err = c.initialize()
+	}
+	if err != nil {
 		if disconnectErr := c.transport.Disconnect(); disconnectErr != nil {
 			slog.Default().Error("Failed to disconnect transport after initialization failure", "error", disconnectErr)
 		}
@@ -68,6 +91,16 @@ func (c *clientImpl) Connect() error {
 	return nil
 }
 
+// isRetryableInitializeError reports whether a failed initialize handshake may succeed
+// when retried, because the server may still be starting
+func isRetryableInitializeError(err error) bool {
+	var transportErr *TransportError
+	if !errors.As(err, &transportErr) {
+		return false
+	}
+	return transportErr.Kind == TransportErrorTimeout || transportErr.Kind == TransportErrorConnectionRefused
+}
+
 // initialize performs the initial version negotiation with the server.
 func (c *clientImpl) initialize() error {
 	// Determine which protocol version to send
@@ -106,7 +139,7 @@ func (c *clientImpl) initialize() error {
 
 	responseJSON, err := c.transport.SendWithContext(ctx, requestJSON)
 	if err != nil {
-		return fmt.Errorf("failed to send initialize request: %w", err)
+		return fmt.Errorf("failed to send initialize request: %w", newTransportError("initialize", c.url, err))
 	}
 
 	// Parse the response
@@ -122,12 +155,12 @@ func (c *clientImpl) initialize() error {
 	}
 
 	if err := json.Unmarshal(responseJSON, &response); err != nil {
-		return fmt.Errorf("failed to parse initialize response: %w", err)
+		return fmt.Errorf("failed to parse initialize response: %w", newTransportError("initialize", c.url, err))
 	}
 
 	// Check for error response
 	if response.Error != nil {
-		return fmt.Errorf("server returned error: %s (code %d)", response.Error.Message, response.Error.Code)
+		return fmt.Errorf("server returned error: %w", newServerError(response.Error.Code, response.Error.Message, response.Error.Data))
 	}
 
 	// Extract the negotiated protocol version
@@ -301,9 +334,18 @@ func (c *clientImpl) registerNotificationHandler() {
 			Params  json.RawMessage `json:"params,omitempty"`
 		}
 
-		if err := json.Unmarshal(params, &request); err != nil {
-			c.logger.Error("failed to parse server message", "error", err)
-			return
+		// Most transports deliver the whole JSON-RPC message; some (MQTT, NATS) pass
+		// the method separately along with only the params.
+		if err := json.Unmarshal(params, &request); err != nil || request.Method == "" {
+			if method == "" {
+				if err != nil {
+					c.logger.Error("failed to parse server message", "error", err)
+				}
+				return
+			}
+			request.ID = 0
+			request.Method = method
+			request.Params = params
 		}
 
 		// Handle request methods
@@ -317,6 +359,13 @@ func (c *clientImpl) registerNotificationHandler() {
 				if err := c.handleSamplingCreateMessage(request.ID, request.Params); err != nil {
 					c.logger.Error("failed to handle sampling/createMessage request", "error", err)
 				}
+			case mcp.ElicitationCreateMethod:
+				// Users take their time, so later messages are not held up meanwhile
+				go func(id int64, params json.RawMessage) {
+					if err := c.handleElicitationCreate(id, params); err != nil {
+						c.logger.Error("failed to handle elicitation/create request", "error", err)
+					}
+				}(request.ID, request.Params)
 			default:
 				c.logger.Warn("received unsupported request method", "method", request.Method)
 				// Send method not found error
@@ -332,9 +381,18 @@ func (c *clientImpl) registerNotificationHandler() {
 
 		// Handle notification methods
 		switch request.Method {
-		// Handle server notifications here
-		default:
-			c.logger.Debug("received notification", "method", request.Method)
+		case "notifications/tools/list_changed":
+			if c.toolCache != nil {
+				c.toolCache.clear()
+				c.logger.Debug("tool result cache invalidated by tools/list_changed")
+			}
+			c.toolSchemas.clear()
+		case "notifications/progress":
+			c.handleRequestProgress(request.Params)
+		case mcp.SamplingChunkNotification:
+			c.handleSamplingChunk(request.Params)
 		}
+
+		c.dispatchNotification(request.Method, request.Params)
 	})
 }
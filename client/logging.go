@@ -0,0 +1,52 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/localrivet/gomcp/mcp"
+)
+
+// LogMessage is a log message sent by the server with notifications/message.
+type LogMessage struct {
+	// Level is the message's severity
+	Level mcp.LogLevel `json:"level"`
+
+	// Logger optionally names the server component that logged the message
+	Logger string `json:"logger,omitempty"`
+
+	// Data is the message payload; GoMCP servers send an object with a "message" key
+	Data interface{} `json:"data"`
+}
+
+// LogMessageHandler handles log messages sent by the server.
+type LogMessageHandler func(message LogMessage)
+
+// SetLogLevel asks the server to send log messages at or above the given level.
+func (c *clientImpl) SetLogLevel(level mcp.LogLevel) error {
+	if !level.IsValid() {
+		return fmt.Errorf("invalid log level: %q", level)
+	}
+
+	if _, err := c.sendRequest("logging/setLevel", map[string]interface{}{
+		"level": level,
+	}); err != nil {
+		return fmt.Errorf("failed to set log level: %w", err)
+	}
+	return nil
+}
+
+// OnLogMessage adds a handler for log messages sent by the server.
+func (c *clientImpl) OnLogMessage(handler LogMessageHandler) {
+	if handler == nil {
+		return
+	}
+	c.notifications.subscribe("notifications/message", func(params json.RawMessage) error {
+		var message LogMessage
+		if err := json.Unmarshal(params, &message); err != nil {
+			return fmt.Errorf("invalid log message: %w", err)
+		}
+		handler(message)
+		return nil
+	})
+}
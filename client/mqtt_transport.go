@@ -157,9 +157,15 @@ func (t *MQTTTransport) SendWithContext(ctx context.Context, message []byte) ([]
 		return nil, fmt.Errorf("invalid JSON message: %w", err)
 	}
 
+	// Send the request with client ID in topic for response routing
+	requestTopic := fmt.Sprintf("%s/%s/%s", t.topicPrefix, t.serverTopic, t.clientID)
+
+	// Notifications and responses to server requests are not answered
 	requestID := requestMap["id"]
-	if requestID == nil {
-		return nil, errors.New("request missing ID field")
+	if requestID == nil || requestMap["method"] == nil {
+		token := t.client.Publish(requestTopic, t.qos, false, message)
+		token.Wait()
+		return nil, token.Error()
 	}
 
 	// Create response channel for this request
@@ -178,9 +184,6 @@ func (t *MQTTTransport) SendWithContext(ctx context.Context, message []byte) ([]
 		close(responseCh)
 	}()
 
-	// Send the request with client ID in topic for response routing
-	requestTopic := fmt.Sprintf("%s/%s/%s", t.topicPrefix, t.serverTopic, t.clientID)
-
 	token := t.client.Publish(requestTopic, t.qos, false, message)
 	if token.Wait() && token.Error() != nil {
 		return nil, token.Error()
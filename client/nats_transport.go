@@ -201,8 +201,14 @@ func (t *NATSTransport) SendWithContext(ctx context.Context, message []byte) ([]
 		return nil, fmt.Errorf("invalid JSON message: %w", err)
 	}
 
-	// Use NATS request-reply pattern
-	subject := fmt.Sprintf("%s.%s", t.subjectPrefix, t.serverSubject)
+	// Use NATS request-reply pattern on the client's own server subject, so that
+	// the server can tell clients apart
+	subject := fmt.Sprintf("%s.%s.%s", t.subjectPrefix, t.serverSubject, t.clientID)
+
+	// Notifications and responses to server requests are not answered
+	if requestMap["id"] == nil || requestMap["method"] == nil {
+		return nil, t.conn.Publish(subject, message)
+	}
 
 	// Create a context with timeout for the request
 	reqCtx := ctx
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+)
+
+// NotificationHandler handles a notification from the server.
+// The params parameter holds the notification's raw params, if any.
+type NotificationHandler func(params json.RawMessage) error
+
+// ProgressNotification is a progress update sent by the server with notifications/progress.
+type ProgressNotification struct {
+	// ProgressToken identifies the operation the progress belongs to
+	ProgressToken interface{} `json:"progressToken"`
+
+	// Progress is the amount of work done so far
+	Progress float64 `json:"progress"`
+
+	// Total is the total amount of work, if known
+	Total float64 `json:"total,omitempty"`
+
+	// Message optionally describes the current progress (2025-03-26 and later)
+	Message string `json:"message,omitempty"`
+}
+
+// ProgressHandler handles progress notifications from the server.
+type ProgressHandler func(progress ProgressNotification)
+
+// ResourceUpdatedHandler handles notifications/resources/updated for a subscribed resource.
+type ResourceUpdatedHandler func(uri string)
+
+// ListChangedHandler handles list_changed notifications. The kind parameter is
+// "tools", "resources" or "prompts".
+type ListChangedHandler func(kind string)
+
+// knownNotifications are the server-to-client notifications defined by the MCP specification
+var knownNotifications = map[string]bool{
+	"notifications/cancelled":              true,
+	"notifications/message":                true,
+	"notifications/progress":               true,
+	"notifications/prompts/list_changed":   true,
+	"notifications/resources/list_changed": true,
+	"notifications/resources/updated":      true,
+	"notifications/sampling/chunk":         true,
+	"notifications/tools/list_changed":     true,
+}
+
+// listChangedKinds are the kinds of list whose changes ListChangedHandler receives
+var listChangedKinds = []string{"tools", "resources", "prompts"}
+
+// WithNotificationHandler registers a custom handler for server notifications with the given method.
+//
+// The handler runs after any built-in handling of the method, so it can also observe
+// standard notifications such as notifications/resources/updated. Notifications that
+// neither the client nor a custom handler handles are logged at debug level, counted
+// in UnknownMethodCounts and published as an UnknownMethodEvent.
+//
+// Example:
+//
+//	c, err := client.NewClient("ws://localhost:8080/mcp",
+//	    client.WithNotificationHandler("notifications/vendor/status", func(params json.RawMessage) error {
+//	        log.Printf("status: %s", params)
+//	        return nil
+//	    }),
+//	)
+func WithNotificationHandler(method string, handler NotificationHandler) Option {
+	return func(c *clientImpl) {
+		c.notifications.subscribe(method, handler)
+	}
+}
+
+// WithNotificationQueueSize sets how many notifications of one method may wait for
+// their handlers before the method's overflow policy applies. The default is
+// DefaultNotificationQueueSize. Methods configured with WithNotificationBuffer may
+// set their own size.
+func WithNotificationQueueSize(size int) Option {
+	return func(c *clientImpl) {
+		if size > 0 {
+			c.notifications.queueSize = size
+		}
+	}
+}
+
+// WithNotificationBuffer configures the queue of one notification method.
+//
+// By default every method queues up to the client's queue size and then drops newly
+// arriving notifications, except notifications/progress, which keeps only the latest
+// queued update per progress token.
+//
+// Example:
+//
+//	c, err := client.NewClient("ws://localhost:8080/mcp",
+//	    // Never lose a resource update
+//	    client.WithNotificationBuffer("notifications/resources/updated", client.NotificationBuffer{
+//	        Overflow: client.OverflowBlock,
+//	    }),
+//	    // Keep the latest 8 progress updates, one per token
+//	    client.WithNotificationBuffer("notifications/progress", client.NotificationBuffer{
+//	        Size:     8,
+//	        Overflow: client.OverflowDropOldest,
+//	        Coalesce: client.ProgressTokenKey,
+//	    }),
+//	)
+func WithNotificationBuffer(method string, buffer NotificationBuffer) Option {
+	return func(c *clientImpl) {
+		c.notifications.setBuffer(method, buffer)
+	}
+}
+
+// OnNotification adds a handler for server notifications with the given method.
+func (c *clientImpl) OnNotification(method string, handler NotificationHandler) {
+	c.notifications.subscribe(method, handler)
+}
+
+// OnProgress adds a handler for progress notifications.
+func (c *clientImpl) OnProgress(handler ProgressHandler) {
+	if handler == nil {
+		return
+	}
+	c.notifications.subscribe("notifications/progress", func(params json.RawMessage) error {
+		var progress ProgressNotification
+		if err := json.Unmarshal(params, &progress); err != nil {
+			return fmt.Errorf("invalid progress notification: %w", err)
+		}
+		handler(progress)
+		return nil
+	})
+}
+
+// OnResourceUpdated adds a handler for updates to subscribed resources.
+func (c *clientImpl) OnResourceUpdated(handler ResourceUpdatedHandler) {
+	if handler == nil {
+		return
+	}
+	c.notifications.subscribe("notifications/resources/updated", func(params json.RawMessage) error {
+		var updated struct {
+			URI string `json:"uri"`
+		}
+		if err := json.Unmarshal(params, &updated); err != nil {
+			return fmt.Errorf("invalid resource updated notification: %w", err)
+		}
+		handler(updated.URI)
+		return nil
+	})
+}
+
+// OnListChanged adds a handler for changes to the server's tool, resource and prompt lists.
+func (c *clientImpl) OnListChanged(handler ListChangedHandler) {
+	if handler == nil {
+		return
+	}
+	for _, kind := range listChangedKinds {
+		kind := kind
+		c.notifications.subscribe("notifications/"+kind+"/list_changed", func(json.RawMessage) error {
+			handler(kind)
+			return nil
+		})
+	}
+}
+
+// UnknownMethodCounts returns how many notifications of each unknown method were received.
+func (c *clientImpl) UnknownMethodCounts() map[string]int64 {
+	c.notificationMu.Lock()
+	defer c.notificationMu.Unlock()
+
+	counts := make(map[string]int64, len(c.unknownMethods))
+	for method, count := range c.unknownMethods {
+		counts[method] = count
+	}
+	return counts
+}
+
+// dispatchNotification routes a notification to its registered handlers, or records
+// it as unknown when neither the client nor a registered handler handles it
+func (c *clientImpl) dispatchNotification(method string, params json.RawMessage) {
+	if c.notifications.route(method, params) {
+		return
+	}
+
+	if knownNotifications[method] {
+		c.logger.Debug("received notification", "method", method)
+		return
+	}
+
+	c.notificationMu.Lock()
+	c.unknownMethods[method]++
+	count := c.unknownMethods[method]
+	c.notificationMu.Unlock()
+
+	c.logger.Debug("ignoring unknown notification", "method", method, "count", count)
+
+	go func() {
+		if err := events.Publish[events.UnknownMethodEvent](c.events, events.TopicUnknownMethod, events.UnknownMethodEvent{
+			Method:     method,
+			Count:      count,
+			ReceivedAt: time.Now(),
+		}); err != nil {
+			c.logger.Warn("failed to publish unknown method event", "error", err)
+		}
+	}()
+}
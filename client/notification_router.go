@@ -0,0 +1,303 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// DefaultNotificationQueueSize is the number of notifications of one method that may
+// wait for their handlers before the method's overflow policy applies.
+const DefaultNotificationQueueSize = 64
+
+// OverflowPolicy decides what happens to a notification that arrives while the
+// queue of its method is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest drops the arriving notification. This is the default.
+	OverflowDropNewest OverflowPolicy = iota
+
+	// OverflowDropOldest drops the oldest queued notification to make room.
+	OverflowDropOldest
+
+	// OverflowBlock makes the transport wait until the queue has room, so no
+	// notification is lost. A handler that never returns stalls the connection.
+	OverflowBlock
+)
+
+// String returns the name of the policy
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowDropNewest:
+		return "drop-newest"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowBlock:
+		return "block"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+	}
+}
+
+// NotificationBuffer configures the queue of one notification method.
+type NotificationBuffer struct {
+	// Size is the number of notifications that may wait for their handlers.
+	// Zero means the client's default queue size.
+	Size int
+
+	// Overflow decides what happens when a notification arrives while the queue is full
+	Overflow OverflowPolicy
+
+	// Coalesce optionally returns a key for a notification. A queued notification
+	// with the same non-empty key is replaced by the newer one rather than both
+	// being queued.
+	Coalesce func(params json.RawMessage) string
+}
+
+// ProgressTokenKey is a NotificationBuffer.Coalesce function for notifications/progress
+// that keeps only the latest queued update for each progress token.
+func ProgressTokenKey(params json.RawMessage) string {
+	var progress struct {
+		ProgressToken json.RawMessage `json:"progressToken"`
+	}
+	if err := json.Unmarshal(params, &progress); err != nil {
+		return ""
+	}
+	return string(progress.ProgressToken)
+}
+
+// defaultNotificationBuffers are the buffers of methods not configured with WithNotificationBuffer
+var defaultNotificationBuffers = map[string]NotificationBuffer{
+	// Only the latest progress of an operation matters, so a burst of updates
+	// never grows beyond one queued notification per token
+	"notifications/progress": {Coalesce: ProgressTokenKey},
+}
+
+// notificationRouter dispatches inbound notifications to registered handlers.
+//
+// Each method has its own queue and worker, so handlers see the notifications of a
+// method in the order they arrived, a slow handler only delays its own method, and
+// the transport's read loop only waits for a handler under OverflowBlock.
+type notificationRouter struct {
+	logger    *slog.Logger
+	queueSize int
+	done      <-chan struct{}
+
+	mu       sync.Mutex
+	handlers map[string][]NotificationHandler
+	buffers  map[string]NotificationBuffer
+	queues   map[string]*notificationQueue
+}
+
+// newNotificationRouter creates a router whose workers stop when done is closed
+func newNotificationRouter(logger *slog.Logger, queueSize int, done <-chan struct{}) *notificationRouter {
+	return &notificationRouter{
+		logger:    logger,
+		queueSize: queueSize,
+		done:      done,
+		handlers:  make(map[string][]NotificationHandler),
+		buffers:   make(map[string]NotificationBuffer),
+		queues:    make(map[string]*notificationQueue),
+	}
+}
+
+// subscribe adds a handler for a notification method
+func (r *notificationRouter) subscribe(method string, handler NotificationHandler) {
+	if handler == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[method] = append(r.handlers[method], handler)
+}
+
+// setBuffer configures the queue of a notification method. It applies to the
+// method's queue when the queue is created, on the method's first notification.
+func (r *notificationRouter) setBuffer(method string, buffer NotificationBuffer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buffers[method] = buffer
+}
+
+// bufferFor returns the buffer configuration of a method. The caller must hold r.mu.
+func (r *notificationRouter) bufferFor(method string) NotificationBuffer {
+	buffer, exists := r.buffers[method]
+	if !exists {
+		buffer = defaultNotificationBuffers[method]
+	}
+	if buffer.Size <= 0 {
+		buffer.Size = r.queueSize
+	}
+	return buffer
+}
+
+// route queues a notification for the handlers of its method. It reports whether
+// the method has any handlers.
+func (r *notificationRouter) route(method string, params json.RawMessage) bool {
+	r.mu.Lock()
+	if len(r.handlers[method]) == 0 {
+		r.mu.Unlock()
+		return false
+	}
+
+	queue, exists := r.queues[method]
+	if !exists {
+		queue = newNotificationQueue(r.bufferFor(method))
+		r.queues[method] = queue
+		go r.work(method, queue)
+	}
+	r.mu.Unlock()
+
+	if queue.push(params, r.done) {
+		r.logger.Warn("notification queue full, dropping notification",
+			"method", method,
+			"queueSize", queue.buffer.Size,
+			"overflow", queue.buffer.Overflow)
+	}
+	return true
+}
+
+// work delivers the queued notifications of one method to its handlers
+func (r *notificationRouter) work(method string, queue *notificationQueue) {
+	for {
+		params, ok := queue.pop(r.done)
+		if !ok {
+			return
+		}
+
+		r.mu.Lock()
+		handlers := append([]NotificationHandler(nil), r.handlers[method]...)
+		r.mu.Unlock()
+
+		for _, handler := range handlers {
+			r.deliver(method, handler, params)
+		}
+	}
+}
+
+// deliver runs one handler, containing failures so that later handlers still run
+func (r *notificationRouter) deliver(method string, handler NotificationHandler, params json.RawMessage) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			r.logger.Error("notification handler panicked", "method", method, "panic", recovered)
+		}
+	}()
+
+	if err := handler(params); err != nil {
+		r.logger.Error("notification handler failed", "method", method, "error", err)
+	}
+}
+
+// queuedNotification is a notification waiting in a notificationQueue
+type queuedNotification struct {
+	key    string
+	params json.RawMessage
+}
+
+// notificationQueue is the bounded queue of one notification method
+type notificationQueue struct {
+	buffer NotificationBuffer
+
+	mu    sync.Mutex
+	items []queuedNotification
+
+	// ready and space wake a waiting pop and push respectively
+	ready chan struct{}
+	space chan struct{}
+}
+
+// newNotificationQueue creates a queue with a resolved buffer configuration
+func newNotificationQueue(buffer NotificationBuffer) *notificationQueue {
+	return &notificationQueue{
+		buffer: buffer,
+		ready:  make(chan struct{}, 1),
+		space:  make(chan struct{}, 1),
+	}
+}
+
+// push adds a notification to the queue, applying the buffer's coalescing and
+// overflow policy. It reports whether a notification was dropped.
+func (q *notificationQueue) push(params json.RawMessage, done <-chan struct{}) (dropped bool) {
+	var key string
+	if q.buffer.Coalesce != nil {
+		key = q.buffer.Coalesce(params)
+	}
+
+	q.mu.Lock()
+	for {
+		if key != "" && q.replace(key, params) {
+			q.mu.Unlock()
+			return dropped
+		}
+
+		if len(q.items) < q.buffer.Size {
+			q.items = append(q.items, queuedNotification{key: key, params: params})
+			q.mu.Unlock()
+			wake(q.ready)
+			return dropped
+		}
+
+		switch q.buffer.Overflow {
+		case OverflowDropOldest:
+			q.items[0] = queuedNotification{}
+			q.items = q.items[1:]
+			dropped = true
+		case OverflowBlock:
+			q.mu.Unlock()
+			select {
+			case <-q.space:
+			case <-done:
+				return true
+			}
+			q.mu.Lock()
+		default:
+			q.mu.Unlock()
+			return true
+		}
+	}
+}
+
+// replace swaps the params of the queued notification with the given key. The
+// caller must hold q.mu.
+func (q *notificationQueue) replace(key string, params json.RawMessage) bool {
+	for i := range q.items {
+		if q.items[i].key == key {
+			q.items[i].params = params
+			return true
+		}
+	}
+	return false
+}
+
+// pop waits for the next notification. It returns false once done is closed.
+func (q *notificationQueue) pop(done <-chan struct{}) (json.RawMessage, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.items) > 0 {
+			params := q.items[0].params
+			q.items[0] = queuedNotification{}
+			q.items = q.items[1:]
+			q.mu.Unlock()
+			wake(q.space)
+			return params, true
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-q.ready:
+		case <-done:
+			return nil, false
+		}
+	}
+}
+
+// wake wakes a waiter on ch without blocking
+func wake(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
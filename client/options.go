@@ -2,6 +2,7 @@
 package client
 
 import (
+	"fmt"
 	"log/slog"
 	"time"
 
@@ -52,6 +53,17 @@ func WithConnectionTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithInitializeRetry retries the initialize handshake with servers that are slow to
+// start. Up to attempts handshakes are made, waiting delay between them; each waits
+// for the connection timeout. Only timeouts and refused connections are retried, as
+// other failures, such as a server process that exited, would fail again.
+func WithInitializeRetry(attempts int, delay time.Duration) Option {
+	return func(c *clientImpl) {
+		c.initializeAttempts = attempts
+		c.initializeRetryDelay = delay
+	}
+}
+
 // WithRoots sets the initial roots for the client.
 func WithRoots(roots []Root) Option {
 	return func(c *clientImpl) {
@@ -142,7 +154,12 @@ func WithProtocolNegotiation(enabled bool) Option {
 type ServerConfigOption func(*serverConfigParams)
 
 type serverConfigParams struct {
-	registryLogger *slog.Logger
+	registryLogger       *slog.Logger
+	initializeAttempts   int
+	initializeRetryDelay time.Duration
+	secretResolver       SecretResolver
+	stderrOptions        []ServerRegistryOption
+	cgroupParent         string
 }
 
 // WithServerRegistryLogger sets a logger for the server registry.
@@ -154,6 +171,33 @@ func WithServerRegistryLogger(logger *slog.Logger) ServerConfigOption {
 	}
 }
 
+// WithServerInitializeRetry retries the initialize handshake with servers that are
+// slow to start. See WithInitializeRetry.
+func WithServerInitializeRetry(attempts int, delay time.Duration) ServerConfigOption {
+	return func(p *serverConfigParams) {
+		p.initializeAttempts = attempts
+		p.initializeRetryDelay = delay
+	}
+}
+
+// registryOptions returns the options of the registry that starts the servers
+func (p *serverConfigParams) registryOptions() []ServerRegistryOption {
+	var registryOpts []ServerRegistryOption
+	if p.registryLogger != nil {
+		registryOpts = append(registryOpts, WithRegistryLogger(p.registryLogger))
+	}
+	if p.initializeAttempts > 1 {
+		registryOpts = append(registryOpts, WithRegistryInitializeRetry(p.initializeAttempts, p.initializeRetryDelay))
+	}
+	if p.secretResolver != nil {
+		registryOpts = append(registryOpts, WithSecretResolver(p.secretResolver))
+	}
+	if p.cgroupParent != "" {
+		registryOpts = append(registryOpts, WithCgroupParent(p.cgroupParent))
+	}
+	return append(registryOpts, p.stderrOptions...)
+}
+
 // WithServerConfig loads server configurations from a file and connects to a specific named server.
 // This is used to integrate with the server registry system to automatically manage server processes.
 // If the server requires starting a new process, it will be launched and managed by the registry.
@@ -167,17 +211,15 @@ func WithServerConfig(configPath string, serverName string, opts ...ServerConfig
 		}
 
 		// Create a new server registry with options
-		var registryOpts []ServerRegistryOption
-		if params.registryLogger != nil {
-			registryOpts = append(registryOpts, WithRegistryLogger(params.registryLogger))
-		}
-		registry := NewServerRegistry(registryOpts...)
+		registry := NewServerRegistry(params.registryOptions()...)
 
 		// Load the config
 		if err := registry.LoadConfig(configPath); err != nil {
 			if c.logger != nil {
 				c.logger.Error("Failed to load server config", "path", configPath, "error", err)
 			}
+			registry.Close()
+			c.optionErr = fmt.Errorf("failed to load server config %s: %w", configPath, err)
 			return
 		}
 
@@ -187,6 +229,8 @@ func WithServerConfig(configPath string, serverName string, opts ...ServerConfig
 			if c.logger != nil {
 				c.logger.Error("Failed to get client from registry", "server", serverName, "error", err)
 			}
+			registry.Close()
+			c.optionErr = err
 			return
 		}
 
@@ -216,17 +260,15 @@ func WithServers(config ServerConfig, serverName string, opts ...ServerConfigOpt
 		}
 
 		// Create a new server registry with options
-		var registryOpts []ServerRegistryOption
-		if params.registryLogger != nil {
-			registryOpts = append(registryOpts, WithRegistryLogger(params.registryLogger))
-		}
-		registry := NewServerRegistry(registryOpts...)
+		registry := NewServerRegistry(params.registryOptions()...)
 
 		// Apply the config directly
 		if err := registry.ApplyConfig(config); err != nil {
 			if c.logger != nil {
 				c.logger.Error("Failed to apply server config", "error", err)
 			}
+			registry.Close()
+			c.optionErr = fmt.Errorf("failed to apply server config: %w", err)
 			return
 		}
 
@@ -236,6 +278,8 @@ func WithServers(config ServerConfig, serverName string, opts ...ServerConfigOpt
 			if c.logger != nil {
 				c.logger.Error("Failed to get client from registry", "server", serverName, "error", err)
 			}
+			registry.Close()
+			c.optionErr = err
 			return
 		}
 
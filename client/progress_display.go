@@ -0,0 +1,248 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultProgressBarWidth is the number of cells of a ProgressBar's bar.
+const DefaultProgressBarWidth = 30
+
+// ProgressUpdate is a progress notification together with estimates derived from
+// the updates of the same operation before it.
+type ProgressUpdate struct {
+	ProgressNotification
+
+	// Fraction is the share of the work done, from 0 to 1, or 0 if the total is unknown
+	Fraction float64
+
+	// Elapsed is the time since the operation's first update
+	Elapsed time.Duration
+
+	// Rate is the amount of work done per second, or 0 until it can be estimated
+	Rate float64
+
+	// ETA is the estimated time until the work is done, or 0 if it is unknown
+	ETA time.Duration
+
+	// Done reports whether all of the work is done
+	Done bool
+}
+
+// ProgressEstimator turns progress notifications into ProgressUpdates, estimating
+// the rate and the time remaining of each operation from the updates it has seen.
+// It is safe for concurrent use.
+type ProgressEstimator struct {
+	mu         sync.Mutex
+	operations map[string]*progressHistory
+	now        func() time.Time
+}
+
+// progressHistory is the first update of an operation
+type progressHistory struct {
+	start    time.Time
+	progress float64
+}
+
+// NewProgressEstimator creates a ProgressEstimator.
+func NewProgressEstimator() *ProgressEstimator {
+	return &ProgressEstimator{
+		operations: make(map[string]*progressHistory),
+		now:        time.Now,
+	}
+}
+
+// Estimate returns the update for a progress notification. The rate is the average
+// since the operation's first update, so the estimate settles as work goes on.
+func (e *ProgressEstimator) Estimate(progress ProgressNotification) ProgressUpdate {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	key := fmt.Sprint(progress.ProgressToken)
+	history, exists := e.operations[key]
+	if !exists {
+		history = &progressHistory{start: now, progress: progress.Progress}
+		e.operations[key] = history
+	}
+
+	update := ProgressUpdate{ProgressNotification: progress, Elapsed: now.Sub(history.start)}
+	if seconds := update.Elapsed.Seconds(); seconds > 0 && progress.Progress > history.progress {
+		update.Rate = (progress.Progress - history.progress) / seconds
+	}
+	if progress.Total > 0 {
+		update.Fraction = min(max(progress.Progress/progress.Total, 0), 1)
+		update.Done = progress.Progress >= progress.Total
+		if !update.Done && update.Rate > 0 {
+			update.ETA = time.Duration((progress.Total - progress.Progress) / update.Rate * float64(time.Second))
+		}
+	}
+	if update.Done {
+		// A later operation may reuse the token
+		delete(e.operations, key)
+	}
+	return update
+}
+
+// ProgressChannel delivers progress notifications as ProgressUpdates on a channel.
+// Pass its Handle method to WithProgress or OnProgress:
+//
+//	updates := client.NewProgressChannel(16)
+//	go func() {
+//	    for update := range updates.Updates() {
+//	        fmt.Printf("%.0f%% ETA %s\n", update.Fraction*100, update.ETA)
+//	    }
+//	}()
+//	result, err := c.CallTool("long-task", args, client.WithProgress(updates.Handle))
+//	updates.Close()
+//
+// Handle never blocks the client: when the channel is full, the oldest update waiting
+// in it is dropped for the new one.
+type ProgressChannel struct {
+	estimator *ProgressEstimator
+
+	mu      sync.Mutex
+	updates chan ProgressUpdate
+	closed  bool
+}
+
+// NewProgressChannel creates a ProgressChannel whose channel holds up to size updates.
+func NewProgressChannel(size int) *ProgressChannel {
+	if size < 1 {
+		size = 1
+	}
+	return &ProgressChannel{
+		estimator: NewProgressEstimator(),
+		updates:   make(chan ProgressUpdate, size),
+	}
+}
+
+// Updates returns the channel of updates, which is closed by Close.
+func (p *ProgressChannel) Updates() <-chan ProgressUpdate {
+	return p.updates
+}
+
+// Handle is a ProgressHandler that queues the update for a progress notification.
+// Notifications arriving after Close are ignored.
+func (p *ProgressChannel) Handle(progress ProgressNotification) {
+	update := p.estimator.Estimate(progress)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	for {
+		select {
+		case p.updates <- update:
+			return
+		default:
+		}
+		select {
+		case <-p.updates:
+		default:
+		}
+	}
+}
+
+// Close closes the channel of updates once no more progress is expected, such as
+// when the request reporting it has returned.
+func (p *ProgressChannel) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		close(p.updates)
+	}
+}
+
+// ProgressBar renders progress notifications as a single-line progress bar on a
+// terminal, redrawing the line for each update:
+//
+//	[=============>                ]  45% 45/100 ETA 12s indexing files
+//
+// Progress without a total is shown as the amount of work done. Pass the bar's
+// Handle method to WithProgress or OnProgress, and call Finish once the request has
+// returned:
+//
+//	bar := client.NewProgressBar(os.Stderr, 0)
+//	result, err := c.CallTool("long-task", args, client.WithProgress(bar.Handle))
+//	bar.Finish()
+type ProgressBar struct {
+	estimator *ProgressEstimator
+	width     int
+
+	mu      sync.Mutex
+	w       io.Writer
+	lastLen int
+}
+
+// NewProgressBar creates a ProgressBar writing to w, with a bar of width cells, or
+// DefaultProgressBarWidth if width is not positive.
+func NewProgressBar(w io.Writer, width int) *ProgressBar {
+	if width <= 0 {
+		width = DefaultProgressBarWidth
+	}
+	return &ProgressBar{
+		estimator: NewProgressEstimator(),
+		width:     width,
+		w:         w,
+	}
+}
+
+// Handle is a ProgressHandler that redraws the bar for a progress notification.
+func (b *ProgressBar) Handle(progress ProgressNotification) {
+	line := b.render(b.estimator.Estimate(progress))
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Blank out what remains of a longer previous line
+	padding := ""
+	if len(line) < b.lastLen {
+		padding = strings.Repeat(" ", b.lastLen-len(line))
+	}
+	b.lastLen = len(line)
+	fmt.Fprint(b.w, "\r"+line+padding)
+}
+
+// Finish ends the bar's line, so that later output starts on a line of its own.
+func (b *ProgressBar) Finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.lastLen > 0 {
+		fmt.Fprintln(b.w)
+		b.lastLen = 0
+	}
+}
+
+// render returns the line showing an update
+func (b *ProgressBar) render(update ProgressUpdate) string {
+	var line strings.Builder
+	if update.Total > 0 {
+		filled := int(update.Fraction * float64(b.width))
+		bar := strings.Repeat("=", filled)
+		if filled < b.width {
+			bar += ">" + strings.Repeat(" ", b.width-filled-1)
+		}
+		fmt.Fprintf(&line, "[%s] %3.0f%% %s/%s", bar, update.Fraction*100,
+			formatProgress(update.Progress), formatProgress(update.Total))
+		if update.ETA > 0 {
+			fmt.Fprintf(&line, " ETA %s", update.ETA.Round(time.Second))
+		}
+	} else {
+		line.WriteString(formatProgress(update.Progress))
+	}
+	if update.Message != "" {
+		line.WriteString(" " + update.Message)
+	}
+	return line.String()
+}
+
+// formatProgress formats an amount of work without needless decimals
+func formatProgress(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a clock for an estimator that advances by step on every reading
+func fakeClock(step time.Duration) func() time.Time {
+	now := time.Unix(0, 0)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestProgressEstimator(t *testing.T) {
+	e := NewProgressEstimator()
+	e.now = fakeClock(time.Second)
+
+	first := e.Estimate(ProgressNotification{ProgressToken: "a", Progress: 10, Total: 100})
+	if first.Rate != 0 || first.ETA != 0 || first.Fraction != 0.1 {
+		t.Errorf("Expected no estimate from the first update, got %+v", first)
+	}
+
+	// 20 units in 2 seconds leave 70 units, 7 seconds
+	e.Estimate(ProgressNotification{ProgressToken: "b", Progress: 1})
+	update := e.Estimate(ProgressNotification{ProgressToken: "a", Progress: 30, Total: 100})
+	if update.Rate != 10 || update.ETA != 7*time.Second || update.Elapsed != 2*time.Second {
+		t.Errorf("Expected a rate of 10/s and an ETA of 7s, got %+v", update)
+	}
+
+	done := e.Estimate(ProgressNotification{ProgressToken: "a", Progress: 100, Total: 100})
+	if !done.Done || done.ETA != 0 || done.Fraction != 1 {
+		t.Errorf("Expected the operation to be done, got %+v", done)
+	}
+
+	unknown := e.Estimate(ProgressNotification{ProgressToken: "b", Progress: 7})
+	if unknown.Fraction != 0 || unknown.ETA != 0 || unknown.Rate != 2 {
+		t.Errorf("Expected only a rate without a total, got %+v", unknown)
+	}
+}
+
+func TestProgressChannel(t *testing.T) {
+	updates := NewProgressChannel(2)
+	for i := 1; i <= 3; i++ {
+		updates.Handle(ProgressNotification{ProgressToken: "a", Progress: float64(i), Total: 3})
+	}
+	updates.Close()
+	updates.Close()
+	updates.Handle(ProgressNotification{ProgressToken: "a", Progress: 3, Total: 3})
+
+	// The oldest update made room for the latest
+	var progress []float64
+	for update := range updates.Updates() {
+		progress = append(progress, update.Progress)
+	}
+	if len(progress) != 2 || progress[0] != 2 || progress[1] != 3 {
+		t.Errorf("Expected updates 2 and 3, got %v", progress)
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	var out bytes.Buffer
+	bar := NewProgressBar(&out, 10)
+	bar.estimator.now = fakeClock(time.Second)
+
+	bar.Handle(ProgressNotification{ProgressToken: "a", Progress: 0, Total: 4, Message: "starting up"})
+	bar.Handle(ProgressNotification{ProgressToken: "a", Progress: 1, Total: 4})
+	bar.Handle(ProgressNotification{ProgressToken: "b", Progress: 2.5})
+	bar.Finish()
+
+	lines := strings.Split(out.String(), "\r")
+	want := []string{
+		"",
+		"[>         ]   0% 0/4 starting up",
+		"[==>       ]  25% 1/4 ETA 3s" + strings.Repeat(" ", 5),
+		"2.5" + strings.Repeat(" ", 25) + "\n",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d redraws, got %q", len(want)-1, out.String())
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("Expected line %q, got %q", want[i], lines[i])
+		}
+	}
+}
@@ -3,8 +3,12 @@ package client
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -25,8 +29,19 @@ type RequestOptions struct {
 	// AllowProgressReset indicates whether progress notifications should reset the timeout clock
 	// Default is true as per MCP specification
 	AllowProgressReset bool
+
+	// OnRequestID is called with the JSON-RPC ID of the request before it is sent,
+	// so that the request can be cancelled with CancelRequest
+	OnRequestID func(id int64)
+
+	// OnProgress receives the progress notifications the server sends for this
+	// request, which is sent with a progress token when it is set
+	OnProgress ProgressHandler
 }
 
+// ErrRequestCancelled is returned by requests cancelled with CancelRequest
+var ErrRequestCancelled = errors.New("request cancelled")
+
 // DefaultRequestOptions returns default request options
 func DefaultRequestOptions() *RequestOptions {
 	return &RequestOptions{
@@ -88,6 +103,14 @@ func (c *clientImpl) sendRequestWithOptions(method string, params interface{}, o
 	requestID := c.generateRequestID()
 	requestIDStr := fmt.Sprintf("%d", requestID)
 
+	// Ask for progress under a token no other client of the server uses
+	if opts.OnProgress != nil {
+		token := newProgressToken(requestIDStr)
+		params = withProgressToken(params, token)
+		c.progressHandlers.Store(token, &requestProgress{requestID: requestIDStr, handler: opts.OnProgress})
+		defer time.AfterFunc(progressHandlerGrace, func() { c.progressHandlers.Delete(token) })
+	}
+
 	// Create the request using structured type
 	request := mcp.NewRequest(requestID, method, params)
 
@@ -122,20 +145,37 @@ func (c *clientImpl) sendRequestWithOptions(method string, params interface{}, o
 		defer c.unregisterProgressTracker(requestIDStr)
 	}
 
+	// Track the request so that CancelRequest can abort it
+	reqCtx, cancelReq := context.WithCancelCause(c.ctx)
+	c.inFlight.Store(requestID, cancelReq)
+	defer func() {
+		c.inFlight.Delete(requestID)
+		cancelReq(nil)
+	}()
+
+	if opts.OnRequestID != nil {
+		opts.OnRequestID(requestID)
+	}
+
 	// Create contexts for timeout management
-	ctx, cancel := context.WithTimeout(c.ctx, timeout)
+	ctx, cancel := context.WithTimeout(reqCtx, timeout)
 	defer cancel()
 
-	maxCtx, maxCancel := context.WithTimeout(c.ctx, maxTimeout)
+	maxCtx, maxCancel := context.WithTimeout(reqCtx, maxTimeout)
 	defer maxCancel()
 
 	// Send the request with timeout and progress reset logic
 	responseJSON, err := c.sendWithProgressAwareTimeout(ctx, maxCtx, requestJSON, tracker)
 	if err != nil {
-		// Check if this was a timeout error
-		if ctx.Err() == context.DeadlineExceeded || maxCtx.Err() == context.DeadlineExceeded {
-			// Send cancellation notification as required by MCP specification
-			c.sendCancellationNotification(requestIDStr, "Request timeout")
+		if errors.Is(context.Cause(reqCtx), ErrRequestCancelled) {
+			// CancelRequest has already notified the server
+			err = fmt.Errorf("request %d (%s): %w", requestID, method, ErrRequestCancelled)
+		} else {
+			if ctx.Err() == context.DeadlineExceeded || maxCtx.Err() == context.DeadlineExceeded {
+				// Send cancellation notification as required by MCP specification
+				c.sendCancellationNotification(requestID, "Request timeout")
+			}
+			err = newTransportError(method, c.url, err)
 		}
 
 		// Emit request failed event
@@ -177,12 +217,12 @@ func (c *clientImpl) sendRequestWithOptions(method string, params interface{}, o
 	}
 
 	if err := json.Unmarshal(responseJSON, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		return nil, fmt.Errorf("failed to parse response: %w", newTransportError(method, c.url, err))
 	}
 
 	// Check for JSON-RPC errors
 	if response.Error != nil {
-		return nil, fmt.Errorf("JSON-RPC error %d: %s", response.Error.Code, response.Error.Message)
+		return nil, newServerError(response.Error.Code, response.Error.Message, response.Error.Data)
 	}
 
 	return response.Result, nil
@@ -247,6 +287,93 @@ func (c *clientImpl) sendWithProgressAwareTimeout(ctx, maxCtx context.Context, r
 	}
 }
 
+// progressHandlerGrace is how long a request's progress handler keeps receiving
+// updates after the request returns. Transports that deliver notifications apart
+// from responses may deliver the last updates of a request after its response.
+const progressHandlerGrace = time.Second
+
+// requestProgress is the progress handler of an in-flight request
+type requestProgress struct {
+	requestID string
+	handler   ProgressHandler
+}
+
+// newProgressToken returns a progress token for a request. Servers track the
+// tokens of all their clients together, so the token is made unique with a random
+// prefix rather than being the request ID alone.
+func newProgressToken(requestID string) string {
+	prefix := make([]byte, 8)
+	if _, err := rand.Read(prefix); err != nil {
+		return fmt.Sprintf("%d-%s", time.Now().UnixNano(), requestID)
+	}
+	return hex.EncodeToString(prefix) + "-" + requestID
+}
+
+// withProgressToken returns a copy of request params with the progress token set in
+// their _meta. Params that are not a map are returned unchanged.
+func withProgressToken(params interface{}, token string) interface{} {
+	var original map[string]interface{}
+	switch p := params.(type) {
+	case nil:
+	case map[string]interface{}:
+		original = p
+	default:
+		return params
+	}
+
+	withToken := make(map[string]interface{}, len(original)+1)
+	for key, value := range original {
+		withToken[key] = value
+	}
+	meta := make(map[string]interface{})
+	if existing, ok := original["_meta"].(map[string]interface{}); ok {
+		for key, value := range existing {
+			meta[key] = value
+		}
+	}
+	meta["progressToken"] = token
+	withToken["_meta"] = meta
+	return withToken
+}
+
+// handleRequestProgress passes a progress notification to the handler of the
+// request it belongs to, if any, and counts it as progress of that request
+func (c *clientImpl) handleRequestProgress(params json.RawMessage) {
+	var progress ProgressNotification
+	if err := json.Unmarshal(params, &progress); err != nil {
+		return
+	}
+
+	var token string
+	switch t := progress.ProgressToken.(type) {
+	case string:
+		token = t
+	case float64:
+		token = strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return
+	}
+
+	value, ok := c.progressHandlers.Load(token)
+	if !ok {
+		return
+	}
+	request := value.(*requestProgress)
+
+	// Progress keeps the request from timing out
+	progressMu.RLock()
+	tracker := progressTrackers[request.requestID]
+	progressMu.RUnlock()
+	if tracker != nil {
+		tracker.mu.Lock()
+		tracker.progressReceived = true
+		tracker.lastProgressTime = time.Now()
+		tracker.mu.Unlock()
+	}
+
+	request.handler(progress)
+}
+
 // Progress tracking for timeout reset
 var (
 	progressTrackers = make(map[string]*progressTracker)
@@ -267,8 +394,21 @@ func (c *clientImpl) unregisterProgressTracker(requestID string) {
 	delete(progressTrackers, requestID)
 }
 
+// CancelRequest cancels an in-flight request by its JSON-RPC ID.
+func (c *clientImpl) CancelRequest(id int64) error {
+	value, ok := c.inFlight.LoadAndDelete(id)
+	if !ok {
+		return fmt.Errorf("no in-flight request with id %d", id)
+	}
+
+	// Abort the local wait first so the transport is free to send the notification
+	value.(context.CancelCauseFunc)(ErrRequestCancelled)
+	c.sendCancellationNotification(id, "Request cancelled by client")
+	return nil
+}
+
 // sendCancellationNotification sends a cancellation notification as required by MCP specification
-func (c *clientImpl) sendCancellationNotification(requestID string, reason string) {
+func (c *clientImpl) sendCancellationNotification(requestID int64, reason string) {
 	// Create the cancellation notification parameters
 	params := map[string]interface{}{
 		"requestId": requestID,
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/localrivet/gomcp/transport/quic"
+)
+
+// QUICTransport wraps a quic.Transport to implement the client.Transport interface
+type QUICTransport struct {
+	transport  *quic.Transport
+	reqTimeout time.Duration
+}
+
+// Connect establishes the QUIC connection to the server
+func (t *QUICTransport) Connect() error {
+	if err := t.transport.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize QUIC transport: %w", err)
+	}
+	return t.transport.Start()
+}
+
+// ConnectWithContext establishes a connection to the server with context
+func (t *QUICTransport) ConnectWithContext(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return t.Connect()
+	}
+}
+
+// Disconnect closes the QUIC connection
+func (t *QUICTransport) Disconnect() error {
+	return t.transport.Stop()
+}
+
+// Send sends a message to the server and waits for a response
+func (t *QUICTransport) Send(message []byte) ([]byte, error) {
+	ctx := context.Background()
+	if t.reqTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.reqTimeout)
+		defer cancel()
+	}
+	return t.transport.SendWithContext(ctx, message)
+}
+
+// SendWithContext sends a message with context for timeout/cancellation
+func (t *QUICTransport) SendWithContext(ctx context.Context, message []byte) ([]byte, error) {
+	return t.transport.SendWithContext(ctx, message)
+}
+
+// SetRequestTimeout sets the default timeout for request operations
+func (t *QUICTransport) SetRequestTimeout(timeout time.Duration) {
+	t.reqTimeout = timeout
+}
+
+// SetConnectionTimeout sets the timeout for establishing the QUIC connection
+func (t *QUICTransport) SetConnectionTimeout(timeout time.Duration) {
+	quic.WithConnectTimeout(timeout)(t.transport)
+}
+
+// RegisterNotificationHandler registers a handler for server-initiated messages
+func (t *QUICTransport) RegisterNotificationHandler(handler func(method string, params []byte)) {
+	t.transport.SetNotificationHandler(handler)
+}
+
+// WithQUIC returns a client configuration option that uses the experimental QUIC
+// transport. Each request travels on a stream of its own, so a lost packet only
+// delays the request it belongs to.
+//
+//	c, err := client.NewClient("quic-client",
+//	    client.WithQUIC("mcp.example.com:4433",
+//	        quic.WithTLSConfig(tlsConfig)))
+func WithQUIC(addr string, options ...quic.Option) Option {
+	return func(c *clientImpl) {
+		c.transport = &QUICTransport{
+			transport:  quic.NewTransport(addr, false, options...),
+			reqTimeout: c.requestTimeout,
+		}
+	}
+}
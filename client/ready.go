@@ -0,0 +1,173 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+)
+
+// readyPollInterval is how often readiness is checked while waiting for it.
+const readyPollInterval = 50 * time.Millisecond
+
+// ReadyCondition is a condition a client must meet, besides being connected and
+// initialized, to be ready. It returns nil when met, and otherwise an error saying
+// what is missing.
+type ReadyCondition func(c Client) error
+
+// ToolsAvailable is met once the server lists the named tools, or at least one
+// tool when no name is given.
+func ToolsAvailable(names ...string) ReadyCondition {
+	return func(c Client) error {
+		tools, err := c.ListTools()
+		if err != nil {
+			return fmt.Errorf("listing tools: %w", err)
+		}
+		if len(names) == 0 {
+			if len(tools) == 0 {
+				return errors.New("no tools available")
+			}
+			return nil
+		}
+
+		listed := make(map[string]bool, len(tools))
+		for _, tool := range tools {
+			listed[tool.Name] = true
+		}
+		var missing []string
+		for _, name := range names {
+			if !listed[name] {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("tools not available: %s", strings.Join(missing, ", "))
+		}
+		return nil
+	}
+}
+
+// CapabilityPresent is met when the server declared a capability: "tools",
+// "resources", "prompts", "logging", "completions" or the name of an
+// experimental capability.
+func CapabilityPresent(capability string) ReadyCondition {
+	return func(c Client) error {
+		caps := c.GetServerCapabilities()
+		if caps != nil {
+			present := false
+			switch capability {
+			case "tools":
+				present = caps.Tools != nil
+			case "resources":
+				present = caps.Resources != nil
+			case "prompts":
+				present = caps.Prompts != nil
+			case "logging":
+				present = caps.Logging != nil
+			case "completions":
+				present = caps.Completions != nil
+			default:
+				_, present = caps.Experimental[capability]
+			}
+			if present {
+				return nil
+			}
+		}
+		return fmt.Errorf("server lacks the %s capability", capability)
+	}
+}
+
+// InstructionsReceived is met when the server gave instructions when initializing.
+func InstructionsReceived() ReadyCondition {
+	return func(c Client) error {
+		if c.GetServerInstructions() == "" {
+			return errors.New("no server instructions received")
+		}
+		return nil
+	}
+}
+
+// WithReadyConditions sets the conditions the client must meet to be ready, for
+// WaitForReady called without conditions, OnReady handlers and the
+// events.ClientReadyEvent.
+//
+// Example:
+//
+//	client, err := client.NewClient(url,
+//		client.WithReadyConditions(client.ToolsAvailable("search"), client.InstructionsReceived()),
+//	)
+func WithReadyConditions(conditions ...ReadyCondition) Option {
+	return func(c *clientImpl) {
+		c.readyConditions = append(c.readyConditions, conditions...)
+	}
+}
+
+// checkReady returns nil when the client is connected, initialized, answers a
+// ping and meets the conditions, and otherwise why it is not ready
+func (c *clientImpl) checkReady(conditions []ReadyCondition) error {
+	if !c.IsConnected() || !c.IsInitialized() {
+		return errors.New("not initialized")
+	}
+	if err := c.Ping(); err != nil {
+		return fmt.Errorf("ping failed: %w", err)
+	}
+	for _, condition := range conditions {
+		if err := condition(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnReady adds a handler run once the client is ready, as for WaitForReady with
+// the conditions of WithReadyConditions. A handler added once the client is ready
+// runs at once. Handlers run in their own goroutine.
+func (c *clientImpl) OnReady(handler func()) {
+	c.readyMu.Lock()
+	defer c.readyMu.Unlock()
+	if c.ready {
+		go handler()
+		return
+	}
+	c.readyHandlers = append(c.readyHandlers, handler)
+}
+
+// watchReady waits in the background for the client to be ready, then runs the
+// OnReady handlers and publishes an events.ClientReadyEvent. Without conditions,
+// the client is ready once initialized.
+func (c *clientImpl) watchReady() {
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+
+	for len(c.readyConditions) > 0 && c.checkReady(c.readyConditions) != nil {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+
+	c.readyMu.Lock()
+	c.ready = true
+	handlers := c.readyHandlers
+	c.readyHandlers = nil
+	c.readyMu.Unlock()
+
+	for _, handler := range handlers {
+		go handler()
+	}
+
+	event := events.ClientReadyEvent{
+		URL:             c.url,
+		ProtocolVersion: c.Version(),
+		ReadyAt:         time.Now(),
+	}
+	if info := c.GetServerInfo(); info != nil {
+		event.ServerName = info.Name
+	}
+	if err := events.Publish[events.ClientReadyEvent](c.events, events.TopicClientReady, event); err != nil {
+		c.logger.Warn("failed to publish client ready event", "error", err)
+	}
+}
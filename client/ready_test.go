@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+)
+
+func TestWaitForReadyConditions(t *testing.T) {
+	c := newTestStub(t, weatherStub)
+
+	if err := c.WaitForReady(time.Second,
+		ToolsAvailable(), ToolsAvailable("forecast"),
+		CapabilityPresent("tools"), CapabilityPresent("prompts"),
+		InstructionsReceived(),
+	); err != nil {
+		t.Fatalf("Expected the stub to be ready, got %v", err)
+	}
+
+	err := c.WaitForReady(100*time.Millisecond, ToolsAvailable("forecast", "tides"))
+	if err == nil || !strings.Contains(err.Error(), "tools not available: tides") {
+		t.Errorf("Expected the missing tool to be reported, got %v", err)
+	}
+	err = c.WaitForReady(100*time.Millisecond, CapabilityPresent("logging"))
+	if err == nil || !strings.Contains(err.Error(), "logging capability") {
+		t.Errorf("Expected the missing capability to be reported, got %v", err)
+	}
+}
+
+func TestOnReady(t *testing.T) {
+	var met atomic.Bool
+	pending := func(c Client) error {
+		if !met.Load() {
+			return errors.New("warming up")
+		}
+		return nil
+	}
+
+	c, err := NewStubFromCatalog(&StubCatalog{ServerInfo: ServerInfo{Name: "warming"}},
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		WithReadyConditions(pending),
+	)
+	if err != nil {
+		t.Fatalf("NewStubFromCatalog failed: %v", err)
+	}
+	defer c.Close()
+
+	readyEvents := make(chan events.ClientReadyEvent, 1)
+	events.Subscribe[events.ClientReadyEvent](c.Events(), events.TopicClientReady,
+		func(ctx context.Context, event events.ClientReadyEvent) error {
+			readyEvents <- event
+			return nil
+		})
+	ready := make(chan struct{})
+	c.OnReady(func() { close(ready) })
+
+	// The conditions of WithReadyConditions apply to WaitForReady too
+	if err := c.WaitForReady(100 * time.Millisecond); err == nil || !strings.Contains(err.Error(), "warming up") {
+		t.Fatalf("Expected the client not to be ready yet, got %v", err)
+	}
+	select {
+	case <-ready:
+		t.Fatal("OnReady ran before the conditions were met")
+	default:
+	}
+
+	met.Store(true)
+	select {
+	case <-ready:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected OnReady to run once the conditions are met")
+	}
+	select {
+	case event := <-readyEvents:
+		if event.ServerName != "warming" || event.URL != "stub://warming" {
+			t.Errorf("Unexpected ready event: %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a ready event")
+	}
+
+	// Handlers added once ready run at once
+	late := make(chan struct{})
+	c.OnReady(func() { close(late) })
+	select {
+	case <-late:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a handler added once ready to run")
+	}
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/localrivet/gomcp/transport/redis"
+)
+
+// RedisTransport wraps a redis.Transport to implement the client.Transport interface
+type RedisTransport struct {
+	transport  *redis.Transport
+	reqTimeout time.Duration
+}
+
+// Connect connects to Redis and subscribes to the client's channels
+func (t *RedisTransport) Connect() error {
+	if err := t.transport.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize Redis transport: %w", err)
+	}
+	return t.transport.Start()
+}
+
+// ConnectWithContext establishes a connection to the server with context
+func (t *RedisTransport) ConnectWithContext(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return t.Connect()
+	}
+}
+
+// Disconnect unsubscribes and closes the Redis connections
+func (t *RedisTransport) Disconnect() error {
+	return t.transport.Stop()
+}
+
+// Send sends a message to the server and waits for a response
+func (t *RedisTransport) Send(message []byte) ([]byte, error) {
+	ctx := context.Background()
+	if t.reqTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.reqTimeout)
+		defer cancel()
+	}
+	return t.transport.SendWithContext(ctx, message)
+}
+
+// SendWithContext sends a message with context for timeout/cancellation
+func (t *RedisTransport) SendWithContext(ctx context.Context, message []byte) ([]byte, error) {
+	return t.transport.SendWithContext(ctx, message)
+}
+
+// SetRequestTimeout sets the default timeout for request operations
+func (t *RedisTransport) SetRequestTimeout(timeout time.Duration) {
+	t.reqTimeout = timeout
+}
+
+// SetConnectionTimeout sets the timeout for connecting to Redis
+func (t *RedisTransport) SetConnectionTimeout(timeout time.Duration) {
+	redis.WithConnectTimeout(timeout)(t.transport)
+}
+
+// RegisterNotificationHandler registers a handler for server-initiated messages
+func (t *RedisTransport) RegisterNotificationHandler(handler func(method string, params []byte)) {
+	t.transport.SetNotificationHandler(handler)
+}
+
+// ClientID returns the client ID that names the client's channels
+func (t *RedisTransport) ClientID() string {
+	return t.transport.ClientID()
+}
+
+// WithRedis returns a client configuration option that uses Redis pub/sub.
+// Requests are published to the client's request channel and responses are
+// received on its response channel.
+//
+//	c, err := client.NewClient("redis-client",
+//	    client.WithRedis("rediss://localhost:6380",
+//	        redis.WithCredentials("mcp", "secret"),
+//	        redis.WithClientID("agent-1")))
+func WithRedis(address string, options ...redis.Option) Option {
+	return func(c *clientImpl) {
+		c.transport = &RedisTransport{
+			transport:  redis.NewTransport(address, false, options...),
+			reqTimeout: c.requestTimeout,
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/localrivet/gomcp/transport"
+)
+
+// responseRouter matches the responses read from a transport that carries every
+// message over a single stream to the requests waiting for them, and passes all
+// other messages, notifications and server requests, to the notification handler.
+// Without it a notification arriving while a request is in flight would be taken
+// for that request's response.
+type responseRouter struct {
+	mu      sync.Mutex
+	pending map[string]chan []byte
+	notify  func(method string, params []byte)
+}
+
+// newResponseRouter creates a router with no requests in flight
+func newResponseRouter() *responseRouter {
+	return &responseRouter{pending: make(map[string]chan []byte)}
+}
+
+// setNotificationHandler sets the handler for messages that are not responses
+func (r *responseRouter) setNotificationHandler(handler func(method string, params []byte)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notify = handler
+}
+
+// send writes message and, if it is a request or a batch with requests, waits for
+// its response until ctx is done. Notifications and responses to server requests
+// return once written.
+func (r *responseRouter) send(ctx context.Context, message []byte, write func([]byte) error) ([]byte, error) {
+	envelopes, err := parseEnvelopes(message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON message: %w", err)
+	}
+
+	// A batch is answered by one message, which any of its IDs identifies
+	var keys []string
+	for _, envelope := range envelopes {
+		if envelope.HasID() && envelope.HasMethod {
+			keys = append(keys, string(envelope.ID))
+		}
+	}
+	if len(keys) == 0 {
+		return nil, write(message)
+	}
+
+	responseCh := make(chan []byte, 1)
+	r.mu.Lock()
+	for _, key := range keys {
+		r.pending[key] = responseCh
+	}
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		for _, key := range keys {
+			delete(r.pending, key)
+		}
+		r.mu.Unlock()
+	}()
+
+	if err := write(message); err != nil {
+		return nil, err
+	}
+
+	select {
+	case response := <-responseCh:
+		return response, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// deliver passes an inbound message to the request waiting for it, or to the
+// notification handler
+func (r *responseRouter) deliver(message []byte) {
+	envelopes, err := parseEnvelopes(message)
+	if err != nil || len(envelopes) == 0 {
+		return
+	}
+
+	if envelope := envelopes[0]; envelope.HasID() && !envelope.HasMethod {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, envelope := range envelopes {
+			if responseCh, ok := r.pending[string(envelope.ID)]; ok {
+				select {
+				case responseCh <- message:
+				default:
+				}
+				return
+			}
+		}
+		return
+	}
+
+	r.mu.Lock()
+	notify := r.notify
+	r.mu.Unlock()
+	if notify == nil {
+		return
+	}
+	if envelopes[0].HasID() {
+		// Server requests may take a while to answer, for example sampling, and must
+		// not hold up the responses behind them
+		go notify("", message)
+		return
+	}
+	notify("", message)
+}
+
+// parseEnvelopes decodes the envelopes of a message or batch
+func parseEnvelopes(message []byte) ([]transport.Envelope, error) {
+	trimmed := bytes.TrimSpace(message)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		envelope, err := transport.ParseEnvelope(trimmed)
+		if err != nil {
+			return nil, err
+		}
+		return []transport.Envelope{envelope}, nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(trimmed, &batch); err != nil {
+		return nil, err
+	}
+	envelopes := make([]transport.Envelope, 0, len(batch))
+	for _, item := range batch {
+		envelope, err := transport.ParseEnvelope(item)
+		if err != nil {
+			return nil, err
+		}
+		envelopes = append(envelopes, envelope)
+	}
+	return envelopes, nil
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestResponseRouterSeparatesNotificationsFromResponses(t *testing.T) {
+	r := newResponseRouter()
+	notifications := make(chan string, 1)
+	r.setNotificationHandler(func(method string, params []byte) {
+		notifications <- string(params)
+	})
+
+	// The server sends a notification before answering the request
+	write := func(message []byte) error {
+		go func() {
+			r.deliver([]byte(`{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`))
+			r.deliver([]byte(`{"jsonrpc":"2.0","id":1,"result":{"tools":[]}}`))
+		}()
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	response, err := r.send(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`), write)
+	if err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if string(response) != `{"jsonrpc":"2.0","id":1,"result":{"tools":[]}}` {
+		t.Errorf("Expected the response to the request, got %s", response)
+	}
+
+	select {
+	case notification := <-notifications:
+		if notification != `{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}` {
+			t.Errorf("Expected the notification, got %s", notification)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the notification to reach the handler")
+	}
+}
+
+func TestResponseRouterMatchesResponsesByID(t *testing.T) {
+	r := newResponseRouter()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	responses := make(chan string, 2)
+	for _, id := range []string{"1", "2"} {
+		id := id
+		go func() {
+			response, err := r.send(ctx, []byte(`{"jsonrpc":"2.0","id":`+id+`,"method":"ping"}`), func([]byte) error { return nil })
+			if err != nil {
+				responses <- err.Error()
+				return
+			}
+			responses <- id + ":" + string(response)
+		}()
+	}
+
+	// Wait until both requests are pending, then answer them out of order
+	deadline := time.Now().Add(time.Second)
+	for {
+		r.mu.Lock()
+		pending := len(r.pending)
+		r.mu.Unlock()
+		if pending == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	r.deliver([]byte(`{"jsonrpc":"2.0","id":2,"result":{}}`))
+	r.deliver([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+
+	want := map[string]bool{
+		`1:{"jsonrpc":"2.0","id":1,"result":{}}`: true,
+		`2:{"jsonrpc":"2.0","id":2,"result":{}}`: true,
+	}
+	for i := 0; i < 2; i++ {
+		if got := <-responses; !want[got] {
+			t.Errorf("Unexpected response %s", got)
+		}
+	}
+}
+
+func TestResponseRouterBatches(t *testing.T) {
+	r := newResponseRouter()
+	batch := `[{"jsonrpc":"2.0","id":3,"result":{}},{"jsonrpc":"2.0","id":4,"result":{}}]`
+	write := func([]byte) error {
+		go r.deliver([]byte(batch))
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	response, err := r.send(ctx, []byte(`[{"jsonrpc":"2.0","id":3,"method":"ping"},{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","id":4,"method":"ping"}]`), write)
+	if err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if string(response) != batch {
+		t.Errorf("Expected the batch response, got %s", response)
+	}
+}
+
+func TestResponseRouterDoesNotWaitForUnansweredMessages(t *testing.T) {
+	r := newResponseRouter()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	written := 0
+	write := func([]byte) error {
+		written++
+		return nil
+	}
+	for _, message := range []string{
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":7,"result":{"roots":[]}}`,
+	} {
+		if _, err := r.send(ctx, []byte(message), write); err != nil {
+			t.Errorf("Expected %s to be sent without waiting, got %v", message, err)
+		}
+	}
+	if written != 2 {
+		t.Errorf("Expected 2 messages written, got %d", written)
+	}
+}
@@ -6,52 +6,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/localrivet/gomcp/mcp"
 )
 
-// SamplingMessageContent represents the content of a sampling message.
-type SamplingMessageContent struct {
-	Type     string `json:"type"`
-	Text     string `json:"text,omitempty"`
-	Data     string `json:"data,omitempty"`
-	MimeType string `json:"mimeType,omitempty"`
-}
-
-// IsValidForVersion checks if the content type is valid for the given protocol version
-func (c *SamplingMessageContent) IsValidForVersion(version string) bool {
-	switch version {
-	case "draft", "2025-03-26":
-		// These versions support text, image, and audio content types
-		return c.Type == "text" || c.Type == "image" || c.Type == "audio"
-	case "2024-11-05":
-		// This version only supports text and image content types
-		return c.Type == "text" || c.Type == "image"
-	default:
-		// Unknown version, default to most restrictive
-		return c.Type == "text"
-	}
-}
+// SamplingMessageContent is an alias to the shared mcp.SamplingMessageContent type.
+type SamplingMessageContent = mcp.SamplingMessageContent
 
-// SamplingMessage represents a message in a sampling conversation.
-type SamplingMessage struct {
-	Role    string                 `json:"role"`
-	Content SamplingMessageContent `json:"content"`
-}
+// SamplingMessage is an alias to the shared mcp.SamplingMessage type.
+type SamplingMessage = mcp.SamplingMessage
 
-// SamplingModelHint represents a hint for model selection in sampling requests.
-type SamplingModelHint struct {
-	Name string `json:"name"`
-}
+// SamplingModelHint is an alias to the shared mcp.SamplingModelHint type.
+type SamplingModelHint = mcp.SamplingModelHint
 
-// SamplingModelPreferences represents the model preferences for a sampling request.
-type SamplingModelPreferences struct {
-	Hints                []SamplingModelHint `json:"hints,omitempty"`
-	CostPriority         *float64            `json:"costPriority,omitempty"`
-	SpeedPriority        *float64            `json:"speedPriority,omitempty"`
-	IntelligencePriority *float64            `json:"intelligencePriority,omitempty"`
-}
+// SamplingModelPreferences is an alias to the shared mcp.SamplingModelPreferences type.
+type SamplingModelPreferences = mcp.SamplingModelPreferences
 
 // SamplingOptions configures how sampling requests are made.
 type SamplingOptions struct {
@@ -60,6 +33,10 @@ type SamplingOptions struct {
 	ModelPreferences SamplingModelPreferences
 	SystemPrompt     string
 	MaxTokens        int
+	IncludeContext   string
+	Temperature      *float64
+	StopSequences    []string
+	Metadata         map[string]interface{}
 
 	// Request configuration
 	Context         context.Context
@@ -80,28 +57,15 @@ type SamplingOptions struct {
 	MaxInterval     time.Duration
 }
 
-// SamplingResponse represents the response to a sampling request.
-type SamplingResponse struct {
-	Role       string                 `json:"role"`
-	Content    SamplingMessageContent `json:"content"`
-	Model      string                 `json:"model,omitempty"`
-	StopReason string                 `json:"stopReason,omitempty"`
-
-	// Streaming fields
-	IsComplete bool `json:"isComplete,omitempty"` // Only for streaming responses
-	ChunkIndex int  `json:"chunkIndex,omitempty"` // Only for streaming responses
-}
+// SamplingResponse is an alias to the shared mcp.SamplingResponse type.
+type SamplingResponse = mcp.SamplingResponse
 
 // SamplingHandler is a function that handles sampling/createMessage requests from the server.
 type SamplingHandler func(params SamplingCreateMessageParams) (SamplingResponse, error)
 
-// SamplingCreateMessageParams represents the parameters for a sampling/createMessage request.
-type SamplingCreateMessageParams struct {
-	Messages         []SamplingMessage        `json:"messages"`
-	ModelPreferences SamplingModelPreferences `json:"modelPreferences"`
-	SystemPrompt     string                   `json:"systemPrompt,omitempty"`
-	MaxTokens        int                      `json:"maxTokens,omitempty"`
-}
+// SamplingCreateMessageParams is an alias to the shared mcp.SamplingCreateMessageParams
+// type, so that servers and clients exchange sampling requests without conversion.
+type SamplingCreateMessageParams = mcp.SamplingCreateMessageParams
 
 // DefaultSamplingOptions returns default sampling options.
 func DefaultSamplingOptions() *SamplingOptions {
@@ -147,6 +111,31 @@ func (opts *SamplingOptions) WithMaxTokens(maxTokens int) *SamplingOptions {
 	return opts
 }
 
+// WithIncludeContext sets which conversation context the receiver should add:
+// mcp.IncludeContextNone, mcp.IncludeContextThisServer or mcp.IncludeContextAllServers.
+func (opts *SamplingOptions) WithIncludeContext(includeContext string) *SamplingOptions {
+	opts.IncludeContext = includeContext
+	return opts
+}
+
+// WithTemperature sets the sampling temperature (between 0 and 2).
+func (opts *SamplingOptions) WithTemperature(temperature float64) *SamplingOptions {
+	opts.Temperature = &temperature
+	return opts
+}
+
+// WithStopSequences sets the sequences that end generation.
+func (opts *SamplingOptions) WithStopSequences(sequences ...string) *SamplingOptions {
+	opts.StopSequences = sequences
+	return opts
+}
+
+// WithMetadata sets provider-specific metadata for the sampling request.
+func (opts *SamplingOptions) WithMetadata(metadata map[string]interface{}) *SamplingOptions {
+	opts.Metadata = metadata
+	return opts
+}
+
 // WithStreaming enables streaming mode (only available in protocol version 2025-03-26).
 func (opts *SamplingOptions) WithStreaming(handler func(*SamplingResponse) error) *SamplingOptions {
 	opts.Streaming = true
@@ -166,6 +155,14 @@ func (opts *SamplingOptions) Validate() error {
 		return fmt.Errorf("at least one message is required")
 	}
 
+	if err := mcp.ValidateSamplingParameters(opts.ProtocolVersion, opts.Temperature, opts.StopSequences, opts.Metadata); err != nil {
+		return err
+	}
+
+	if err := mcp.ValidateIncludeContext(opts.IncludeContext); err != nil {
+		return err
+	}
+
 	// Validate streaming options
 	if opts.Streaming {
 		if opts.ProtocolVersion != "2025-03-26" {
@@ -226,18 +223,26 @@ func (c *clientImpl) RequestSampling(opts *SamplingOptions) (*SamplingResponse,
 	if opts.MaxTokens > 0 {
 		params["maxTokens"] = opts.MaxTokens
 	}
+	if opts.IncludeContext != "" {
+		params["includeContext"] = opts.IncludeContext
+	}
+	if opts.Temperature != nil {
+		params["temperature"] = *opts.Temperature
+	}
+	if len(opts.StopSequences) > 0 {
+		params["stopSequences"] = opts.StopSequences
+	}
+	if len(opts.Metadata) > 0 {
+		params["metadata"] = opts.Metadata
+	}
 
 	// Add streaming parameters if enabled
 	if opts.Streaming {
-		streamParams := map[string]interface{}{}
-		if opts.ChunkSize > 0 {
-			streamParams["chunkSize"] = opts.ChunkSize
+		params["streaming"] = mcp.SamplingStreamingParams{
+			ChunkSize:      opts.ChunkSize,
+			MaxChunks:      opts.MaxChunks,
+			StopOnComplete: opts.StopOnComplete,
 		}
-		if opts.MaxChunks > 0 {
-			streamParams["maxChunks"] = opts.MaxChunks
-		}
-		streamParams["stopOnComplete"] = opts.StopOnComplete
-		params["streaming"] = streamParams
 	}
 
 	// Update request with final params
@@ -251,7 +256,7 @@ func (c *clientImpl) RequestSampling(opts *SamplingOptions) (*SamplingResponse,
 
 	// Send request with retry logic
 	if opts.Streaming {
-		return c.sendStreamingSamplingRequest(opts, requestJSON)
+		return c.sendStreamingSamplingRequest(opts, requestID, requestJSON)
 	}
 	return c.sendRegularSamplingRequest(opts, requestJSON)
 }
@@ -303,7 +308,7 @@ func (c *clientImpl) sendRegularSamplingRequest(opts *SamplingOptions, requestJS
 	}
 
 	if jsonResponse.Error != nil {
-		return nil, fmt.Errorf("server error: %s (code %d)", jsonResponse.Error.Message, jsonResponse.Error.Code)
+		return nil, fmt.Errorf("server error: %w", newServerError(jsonResponse.Error.Code, jsonResponse.Error.Message, jsonResponse.Error.Data))
 	}
 
 	if jsonResponse.Result == nil {
@@ -319,11 +324,148 @@ func (c *clientImpl) sendRegularSamplingRequest(opts *SamplingOptions, requestJS
 	return jsonResponse.Result, nil
 }
 
-// sendStreamingSamplingRequest sends a streaming sampling request.
-func (c *clientImpl) sendStreamingSamplingRequest(opts *SamplingOptions, requestJSON []byte) (*SamplingResponse, error) {
-	// For now, return an error since streaming requires transport-level support
-	// In a real implementation, this would use WebSockets or SSE
-	return nil, fmt.Errorf("streaming sampling not yet implemented - requires transport-level streaming support")
+// sendStreamingSamplingRequest sends a streaming sampling request. The server sends
+// the response in chunks, as notifications correlated by the request ID, before the
+// response itself, which holds the complete text. Chunks are passed to the stream
+// handler in order, followed by the complete response with IsComplete set. Servers
+// that do not stream answer with the complete response only. Streaming requests
+// are not retried, since the chunks of a failed attempt have been delivered.
+func (c *clientImpl) sendStreamingSamplingRequest(opts *SamplingOptions, requestID int64, requestJSON []byte) (*SamplingResponse, error) {
+	stream := newSamplingStream(opts)
+	key := strconv.FormatInt(requestID, 10)
+	c.samplingStreams.Store(key, stream)
+	defer c.samplingStreams.Delete(key)
+
+	noRetries := *opts
+	noRetries.MaxRetries = 0
+	response, err := c.sendRegularSamplingRequest(&noRetries, requestJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	// Servers that do not stream send the complete response without a chunk count
+	if !response.IsComplete {
+		response.ChunkIndex = 0
+	}
+
+	// Transports that deliver notifications apart from responses, such as SSE, may
+	// deliver the last chunks after the response
+	if err := stream.finish(opts.Context, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// samplingStream passes the chunks of a streaming sampling request to its stream
+// handler in the order the server numbered them
+type samplingStream struct {
+	mu        sync.Mutex
+	handler   func(*SamplingResponse) error
+	maxChunks int
+	next      int                       // Index of the next chunk to pass on
+	early     map[int]*SamplingResponse // Chunks that arrived ahead of their turn
+	done      bool                      // Whether the complete response was passed on
+	err       error                     // Error returned by the handler
+	arrived   chan struct{}
+}
+
+// newSamplingStream creates the stream of a streaming sampling request
+func newSamplingStream(opts *SamplingOptions) *samplingStream {
+	return &samplingStream{
+		handler:   opts.StreamHandler,
+		maxChunks: opts.MaxChunks,
+		early:     make(map[int]*SamplingResponse),
+		arrived:   make(chan struct{}, 1),
+	}
+}
+
+// deliver passes a chunk, and any chunks held back for it, to the handler
+func (st *samplingStream) deliver(chunk *SamplingResponse) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.done || st.err != nil || chunk.ChunkIndex < st.next {
+		return
+	}
+	st.early[chunk.ChunkIndex] = chunk
+	for {
+		next, ok := st.early[st.next]
+		if !ok || (st.maxChunks > 0 && st.next >= st.maxChunks) {
+			break
+		}
+		delete(st.early, st.next)
+		st.next++
+		if err := st.handler(next); err != nil {
+			st.err = fmt.Errorf("stream handler failed: %w", err)
+			break
+		}
+	}
+
+	select {
+	case st.arrived <- struct{}{}:
+	default:
+	}
+}
+
+// finish waits up to the chunk grace period for the chunks the complete response
+// counts, then passes the complete response to the handler
+func (st *samplingStream) finish(ctx context.Context, response *SamplingResponse) error {
+	grace := time.NewTimer(samplingChunkGrace)
+	defer grace.Stop()
+
+	st.mu.Lock()
+	for st.err == nil && st.next < response.ChunkIndex && (st.maxChunks == 0 || st.next < st.maxChunks) {
+		st.mu.Unlock()
+		select {
+		case <-st.arrived:
+		case <-grace.C:
+			return fmt.Errorf("sampling stream ended after %d of %d chunks", st.received(), response.ChunkIndex)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		st.mu.Lock()
+	}
+	defer st.mu.Unlock()
+
+	if st.err != nil {
+		return st.err
+	}
+	st.done = true
+	complete := *response
+	complete.IsComplete = true
+	if err := st.handler(&complete); err != nil {
+		return fmt.Errorf("stream handler failed: %w", err)
+	}
+	return nil
+}
+
+// received returns the number of chunks passed to the handler
+func (st *samplingStream) received() int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.next
+}
+
+// samplingChunkGrace is how long a streaming sampling request waits, after its
+// response, for chunks still in transit
+const samplingChunkGrace = time.Second
+
+// handleSamplingChunk passes a chunk of a streamed sampling response to the stream
+// of the request it belongs to, if any
+func (c *clientImpl) handleSamplingChunk(params json.RawMessage) {
+	var chunk struct {
+		RequestID json.RawMessage  `json:"requestId"`
+		Chunk     SamplingResponse `json:"chunk"`
+	}
+	if err := json.Unmarshal(params, &chunk); err != nil {
+		return
+	}
+
+	value, ok := c.samplingStreams.Load(strings.Trim(string(chunk.RequestID), `"`))
+	if !ok {
+		return
+	}
+	value.(*samplingStream).deliver(&chunk.Chunk)
 }
 
 // isRetryableError determines if an error should trigger a retry.
@@ -368,6 +510,10 @@ func (c *clientImpl) handleSamplingCreateMessage(id int64, paramsJSON []byte) er
 		return c.sendJsonRpcErrorResponse(id, -32700, "Parse error", err.Error())
 	}
 
+	if err := mcp.ValidateIncludeContext(params.IncludeContext); err != nil {
+		return c.sendJsonRpcErrorResponse(id, -32602, "Invalid params", err.Error())
+	}
+
 	// Validate content types
 	for _, msg := range params.Messages {
 		if !msg.Content.IsValidForVersion(c.negotiatedVersion) {
@@ -0,0 +1,226 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNoModelRoute is returned when no route of a SamplingRouter can serve a request.
+var ErrNoModelRoute = errors.New("no model route available")
+
+// ModelRoute maps a concrete provider/model to the handler that serves it.
+type ModelRoute struct {
+	// Provider is the name of the backend serving the model (e.g. "openai")
+	Provider string
+
+	// Model is the concrete model name, reported in responses that do not set one
+	Model string
+
+	// Aliases are additional names that model hints may match (e.g. "sonnet", "gpt-4")
+	Aliases []string
+
+	// CostPerMillionTokens is the price of the model, used for the cost ceiling
+	// and to score routes against the cost priority
+	CostPerMillionTokens float64
+
+	// SpeedScore and IntelligenceScore rate the model from 0 to 1
+	SpeedScore        float64
+	IntelligenceScore float64
+
+	// Handler serves sampling requests routed to this model
+	Handler SamplingHandler
+}
+
+// matches reports whether a model hint refers to this route.
+// As in the MCP specification, hints are treated as substrings of model names.
+func (r ModelRoute) matches(hint string) bool {
+	hint = strings.ToLower(strings.TrimSpace(hint))
+	if hint == "" {
+		return false
+	}
+	if strings.Contains(strings.ToLower(r.Model), hint) {
+		return true
+	}
+	for _, alias := range r.Aliases {
+		if strings.Contains(strings.ToLower(alias), hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// SamplingRouterOption configures a SamplingRouter.
+type SamplingRouterOption func(*SamplingRouter)
+
+// WithSamplingCostCeiling excludes routes costing more than maxCostPerMillionTokens.
+// Zero means no ceiling.
+func WithSamplingCostCeiling(maxCostPerMillionTokens float64) SamplingRouterOption {
+	return func(r *SamplingRouter) {
+		r.costCeiling = maxCostPerMillionTokens
+	}
+}
+
+// WithSamplingFallback sets whether the router tries the next candidate route when
+// a handler fails. Fallback is enabled by default.
+func WithSamplingFallback(enabled bool) SamplingRouterOption {
+	return func(r *SamplingRouter) {
+		r.fallback = enabled
+	}
+}
+
+// SamplingRouter selects a concrete model for sampling requests from their model
+// preferences and dispatches each request to the handler of the chosen route.
+//
+// Routes matching an earlier model hint rank before routes matching a later one,
+// and routes matching no hint come last. Within each group, routes are ranked by
+// how well they fit the request's cost, speed and intelligence priorities.
+// Routes above the cost ceiling are never used.
+//
+// Example:
+//
+//	router := client.NewSamplingRouter(client.WithSamplingCostCeiling(20))
+//	router.AddRoute(client.ModelRoute{
+//	    Provider: "openai", Model: "gpt-4o", Aliases: []string{"gpt-4"},
+//	    CostPerMillionTokens: 5, SpeedScore: 0.7, IntelligenceScore: 0.9,
+//	    Handler: callOpenAI,
+//	})
+//	c = c.WithSamplingHandler(router.Handler())
+type SamplingRouter struct {
+	mu          sync.RWMutex
+	routes      []ModelRoute
+	costCeiling float64
+	fallback    bool
+}
+
+// NewSamplingRouter creates an empty sampling router.
+func NewSamplingRouter(options ...SamplingRouterOption) *SamplingRouter {
+	r := &SamplingRouter{fallback: true}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// AddRoute adds a route to the routing table.
+func (r *SamplingRouter) AddRoute(route ModelRoute) *SamplingRouter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, route)
+	return r
+}
+
+// Routes returns a copy of the routing table in the order routes were added.
+func (r *SamplingRouter) Routes() []ModelRoute {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]ModelRoute(nil), r.routes...)
+}
+
+// Select returns the routes eligible for the given preferences, best first.
+func (r *SamplingRouter) Select(prefs SamplingModelPreferences) []ModelRoute {
+	r.mu.RLock()
+	var candidates []ModelRoute
+	var maxCost float64
+	for _, route := range r.routes {
+		if r.costCeiling > 0 && route.CostPerMillionTokens > r.costCeiling {
+			continue
+		}
+		candidates = append(candidates, route)
+		if route.CostPerMillionTokens > maxCost {
+			maxCost = route.CostPerMillionTokens
+		}
+	}
+	r.mu.RUnlock()
+
+	type ranked struct {
+		route     ModelRoute
+		hintIndex int
+		score     float64
+	}
+
+	rankedRoutes := make([]ranked, len(candidates))
+	for i, route := range candidates {
+		hintIndex := len(prefs.Hints)
+		for h, hint := range prefs.Hints {
+			if route.matches(hint.Name) {
+				hintIndex = h
+				break
+			}
+		}
+
+		// Cheaper routes score higher on cost, relative to the most expensive candidate
+		costScore := 1.0
+		if maxCost > 0 {
+			costScore = 1 - route.CostPerMillionTokens/maxCost
+		}
+		score := priority(prefs.CostPriority)*costScore +
+			priority(prefs.SpeedPriority)*route.SpeedScore +
+			priority(prefs.IntelligencePriority)*route.IntelligenceScore
+
+		rankedRoutes[i] = ranked{route: route, hintIndex: hintIndex, score: score}
+	}
+
+	sort.SliceStable(rankedRoutes, func(i, j int) bool {
+		if rankedRoutes[i].hintIndex != rankedRoutes[j].hintIndex {
+			return rankedRoutes[i].hintIndex < rankedRoutes[j].hintIndex
+		}
+		return rankedRoutes[i].score > rankedRoutes[j].score
+	})
+
+	selected := make([]ModelRoute, len(rankedRoutes))
+	for i, rr := range rankedRoutes {
+		selected[i] = rr.route
+	}
+	return selected
+}
+
+// Route serves a sampling request with the best route for its preferences.
+// If fallback is enabled, each remaining candidate is tried in turn when a
+// handler fails.
+func (r *SamplingRouter) Route(params SamplingCreateMessageParams) (SamplingResponse, error) {
+	candidates := r.Select(params.ModelPreferences)
+	if len(candidates) == 0 {
+		return SamplingResponse{}, ErrNoModelRoute
+	}
+
+	var errs []error
+	for _, route := range candidates {
+		if route.Handler == nil {
+			continue
+		}
+
+		response, err := route.Handler(params)
+		if err == nil {
+			if response.Model == "" {
+				response.Model = route.Model
+			}
+			return response, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s/%s: %w", route.Provider, route.Model, err))
+		if !r.fallback {
+			break
+		}
+	}
+
+	if len(errs) == 0 {
+		return SamplingResponse{}, ErrNoModelRoute
+	}
+	return SamplingResponse{}, fmt.Errorf("all model routes failed: %w", errors.Join(errs...))
+}
+
+// Handler returns a SamplingHandler that dispatches requests through the router.
+func (r *SamplingRouter) Handler() SamplingHandler {
+	return r.Route
+}
+
+// priority returns the value of an optional priority, treating unset as zero.
+func priority(p *float64) float64 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
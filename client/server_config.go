@@ -24,12 +24,40 @@ type ServerConfig struct {
 	MCPServers map[string]ServerDefinition `json:"mcpServers"`
 }
 
-// ServerDefinition defines how to launch and connect to an MCP server
+// ServerDefinition defines how to launch and connect to an MCP server.
+//
+// Command, Args, Env values, URL and WorkingDirectory may reference environment
+// variables as ${VAR} or ${VAR:-default}, and secrets as ${secret:reference} (see
+// WithSecretResolver).
+// EnvFile names a dotenv-style file whose variables are added to the server's
+// environment and can be referenced too; when the definition is loaded with
+// LoadConfig, it is relative to the config file.
+//
+// The remaining fields contain servers that are not trusted: the directory they
+// run in, which of the registry's environment variables they see, the user and
+// group they run as (which requires the privilege to switch to them), and the
+// resources they may use.
 type ServerDefinition struct {
 	Command string            `json:"command"`
 	Args    []string          `json:"args"`
 	Env     map[string]string `json:"env,omitempty"`
+	EnvFile string            `json:"envFile,omitempty"`
 	URL     string            `json:"url,omitempty"`
+
+	// WorkingDirectory is the directory the server runs in; the registry's by default
+	WorkingDirectory string `json:"workingDirectory,omitempty"`
+
+	// CleanEnv keeps the registry's environment from the server, except for the
+	// variables named in PassEnv; Env and EnvFile variables are still set
+	CleanEnv bool     `json:"cleanEnv,omitempty"`
+	PassEnv  []string `json:"passEnv,omitempty"`
+
+	// User and Group, as names or numeric IDs, to run the server as
+	User  string `json:"user,omitempty"`
+	Group string `json:"group,omitempty"`
+
+	// Limits bound the resources of the server process
+	Limits *ProcessLimits `json:"limits,omitempty"`
 }
 
 // MCPServer represents a running MCP server process with a connected client
@@ -37,6 +65,10 @@ type MCPServer struct {
 	Name   string
 	Client Client
 	cmd    *exec.Cmd
+
+	// The ping health checks and the watchdog are waiting for, if any
+	pingMu sync.Mutex
+	ping   *pendingPing
 }
 
 // ProcessInfo tracks spawned processes for comprehensive cleanup
@@ -57,10 +89,32 @@ type ServerRegistry struct {
 	ctx     context.Context
 	cancel  context.CancelFunc
 
+	// How started server processes exited, by command; guarded by processMutex
+	exits map[*exec.Cmd]*processExit
+
 	// Process tracking for comprehensive cleanup (opt-in)
 	spawnedProcesses      map[int]*ProcessInfo
 	processMutex          sync.Mutex
 	enableProcessTracking bool // Only enable when needed for production use
+
+	// Periodic health checking of managed servers (opt-in, see WithHealthCheck)
+	health healthState
+
+	// Killing of hung servers (opt-in, see WithWatchdog)
+	watchdog watchdogState
+
+	// Initialize retries of started servers (see WithRegistryInitializeRetry)
+	initializeAttempts   int
+	initializeRetryDelay time.Duration
+
+	// secrets resolves ${secret:...} references in definitions (see WithSecretResolver)
+	secrets SecretResolver
+
+	// stderr is what is done with the stderr of launched servers (see WithStderrLogging)
+	stderr stderrConfig
+
+	// cgroupParent is where server cgroups are created (see WithCgroupParent)
+	cgroupParent string
 }
 
 // ServerRegistryOption configures a ServerRegistry
@@ -84,6 +138,15 @@ func WithProcessTracking() ServerRegistryOption {
 	}
 }
 
+// WithRegistryInitializeRetry retries the initialize handshake with started servers
+// that are slow to start. See WithInitializeRetry.
+func WithRegistryInitializeRetry(attempts int, delay time.Duration) ServerRegistryOption {
+	return func(r *ServerRegistry) {
+		r.initializeAttempts = attempts
+		r.initializeRetryDelay = delay
+	}
+}
+
 // NewServerRegistry creates a new empty server registry.
 // By default, no logging is enabled to avoid interfering with stdio-based MCP communication.
 func NewServerRegistry(opts ...ServerRegistryOption) *ServerRegistry {
@@ -96,12 +159,20 @@ func NewServerRegistry(opts ...ServerRegistryOption) *ServerRegistry {
 		ctx:              ctx,
 		cancel:           cancel,
 		spawnedProcesses: make(map[int]*ProcessInfo),
+		exits:            make(map[*exec.Cmd]*processExit),
+		health: healthState{
+			threshold: defaultHealthFailureThreshold,
+			status:    make(map[string]*ServerHealth),
+		},
 	}
 
 	for _, opt := range opts {
 		opt(r)
 	}
 
+	r.startHealthChecks()
+	r.startWatchdog()
+
 	return r
 }
 
@@ -116,6 +187,7 @@ func (r *ServerRegistry) LoadConfig(path string) error {
 	if err := json.Unmarshal(data, &config); err != nil {
 		return fmt.Errorf("failed to parse config file: %w", err)
 	}
+	resolveEnvFiles(&config, path)
 
 	return r.ApplyConfig(config)
 }
@@ -142,21 +214,19 @@ func (r *ServerRegistry) ApplyConfig(config ServerConfig) error {
 		}(name, def)
 	}
 
-	// Collect results and check for errors
-	var errors []string
-	successCount := 0
+	// Collect results and check for errors, keeping them wrapped so that callers can
+	// inspect them, for example with Diagnose
+	var failures []error
 	for i := 0; i < len(config.MCPServers); i++ {
 		result := <-resultCh
 		if result.err != nil {
-			errors = append(errors, fmt.Sprintf("server %s: %v", result.name, result.err))
-		} else {
-			successCount++
+			failures = append(failures, fmt.Errorf("server %s: %w", result.name, result.err))
 		}
 	}
 
 	// Return error information if any servers failed
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to start %d/%d servers: %s", len(errors), len(config.MCPServers), strings.Join(errors, "; "))
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to start %d/%d servers: %w", len(failures), len(config.MCPServers), errors.Join(failures...))
 	}
 
 	return nil
@@ -171,15 +241,19 @@ func (r *ServerRegistry) StartServer(name string, def ServerDefinition) error {
 		return fmt.Errorf("cannot start server %s: registry is closed", name)
 	}
 	r.mu.RUnlock()
+
+	// Expand variables and fetch secrets before anything is launched
+	def, err := r.resolveDefinition(def)
+	if err != nil {
+		return fmt.Errorf("failed to resolve definition of server %s: %w", name, err)
+	}
+
 	// Create command
 	cmd := exec.Command(def.Command, def.Args...)
 
-	// Set environment variables
-	env := os.Environ()
-	for k, v := range def.Env {
-		env = append(env, fmt.Sprintf("%s=%s", k, v))
-	}
-	cmd.Env = env
+	// Set the working directory and environment variables
+	cmd.Dir = def.WorkingDirectory
+	cmd.Env = serverEnv(def)
 
 	// Create a new process group to enable clean killing of child processes
 	// This is the recommended approach for handling process trees
@@ -187,6 +261,11 @@ func (r *ServerRegistry) StartServer(name string, def ServerDefinition) error {
 		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 	}
 
+	// Run the process as the configured user
+	if err := setCredential(cmd, def); err != nil {
+		return fmt.Errorf("failed to set credentials of server %s: %w", name, err)
+	}
+
 	// Set up stdio pipes for communication
 	stdinPipe, err := cmd.StdinPipe()
 	if err != nil {
@@ -195,14 +274,36 @@ func (r *ServerRegistry) StartServer(name string, def ServerDefinition) error {
 
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
+		stdinPipe.Close()
 		return fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
+	closePipes := func() {
+		stdinPipe.Close()
+		stdoutPipe.Close()
+	}
 
-	// Set stderr to go to the parent process stderr for debugging
-	cmd.Stderr = os.Stderr
+	// Pass stderr through to the parent process or capture it, keeping its tail to
+	// explain failures if the server exits
+	stderr := &stderrTail{}
+	stderrWriter, closeStderr, err := r.stderrWriter(name, stderr)
+	if err != nil {
+		closePipes()
+		return fmt.Errorf("failed to capture stderr of server %s: %w", name, err)
+	}
+	cmd.Stderr = stderrWriter
+
+	// Keep the process within its resource limits
+	limits, err := r.prepareLimits(name, cmd, def.Limits)
+	if err != nil {
+		closePipes()
+		closeStderr()
+		return fmt.Errorf("failed to limit resources of server %s: %w", name, err)
+	}
 
 	// Start the process
 	if err := cmd.Start(); err != nil {
+		closeStderr()
+		limits.release()
 		return fmt.Errorf("failed to start command: %w", err)
 	}
 
@@ -211,10 +312,23 @@ func (r *ServerRegistry) StartServer(name string, def ServerDefinition) error {
 		r.trackProcess(cmd.Process.Pid, name, def.Command)
 	}
 
+	exit := watchProcess(cmd, stderr)
+	go func() {
+		// Wait returns once stderr has been copied to the end
+		<-exit.done
+		closeStderr()
+		limits.release()
+	}()
+	r.processMutex.Lock()
+	r.exits[cmd] = exit
+	r.processMutex.Unlock()
+
 	// Create a transport for the client
 	transport := &stdioPipeTransport{
 		reader: stdoutPipe,
 		writer: stdinPipe,
+		name:   name,
+		exit:   exit,
 	}
 
 	// Create client options - use the standard WithTransport function
@@ -222,6 +336,9 @@ func (r *ServerRegistry) StartServer(name string, def ServerDefinition) error {
 		WithTransport(transport),
 		WithConnectionTimeout(5 * time.Second), // Short timeout for server registry to prevent test hangs
 	}
+	if r.initializeAttempts > 1 {
+		clientOpts = append(clientOpts, WithInitializeRetry(r.initializeAttempts, r.initializeRetryDelay))
+	}
 
 	// Add logger if configured
 	if r.logger != nil {
@@ -315,6 +432,7 @@ func (r *ServerRegistry) StopServer(name string) error {
 
 	// Remove from registry immediately to prevent double-cleanup
 	delete(r.servers, name)
+	r.forgetHealth(name)
 
 	// Gracefully terminate the process with proper timeout and escalation
 	if err := r.terminateProcess(server.cmd, name); err != nil {
@@ -353,8 +471,19 @@ func (r *ServerRegistry) terminateProcess(cmd *exec.Cmd, name string) error {
 		gracefulTimeout = 100 * time.Millisecond
 	}
 
+	// Wait through the process's watcher if it has one, as Wait may only be called once
+	r.processMutex.Lock()
+	exit := r.exits[cmd]
+	delete(r.exits, cmd)
+	r.processMutex.Unlock()
+
 	done := make(chan error, 1)
 	go func() {
+		if exit != nil {
+			<-exit.done
+			done <- exit.err
+			return
+		}
 		done <- cmd.Wait()
 	}()
 
@@ -533,6 +662,10 @@ type stdioPipeTransport struct {
 	connected      bool
 	mu             sync.RWMutex
 
+	// name and exit identify the server process, to explain a closed pipe
+	name string
+	exit *processExit
+
 	// Request/response correlation
 	pendingRequests map[int64]chan []byte
 	pendingMu       sync.RWMutex
@@ -623,10 +756,8 @@ func (t *stdioPipeTransport) startReader() {
 			}
 
 			if !scanner.Scan() {
-				if err := scanner.Err(); err != nil {
-					// Handle scan error - close all pending requests
-					t.closeAllPending()
-				}
+				// No more responses can arrive - fail all pending requests
+				t.closeAllPending()
 				return
 			}
 
@@ -754,17 +885,20 @@ func (t *stdioPipeTransport) SendWithContext(ctx context.Context, message []byte
 
 	// Write message to the writer
 	if _, err := t.writer.Write(append(message, '\n')); err != nil {
-		return nil, fmt.Errorf("failed to write message: %w", err)
+		return nil, t.exit.transportError("send", t.name, fmt.Errorf("failed to write message: %w", err))
 	}
 
 	// Wait for response or context cancellation
 	select {
-	case response := <-responseCh:
+	case response, ok := <-responseCh:
+		if !ok {
+			return nil, t.exit.transportError("send", t.name, io.EOF)
+		}
 		return response, nil
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case <-t.ctx.Done():
-		return nil, errors.New("transport disconnected")
+		return nil, t.exit.transportError("send", t.name, errors.New("transport disconnected"))
 	}
 }
 
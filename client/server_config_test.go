@@ -1,11 +1,13 @@
 package client
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 	"sync"
 	"syscall"
 	"testing"
@@ -490,3 +492,64 @@ func isProcessAlive(pid int) bool {
 	}
 	return len(output) > 0
 }
+
+func TestServerRegistry_DiagnosesExitedProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	registry := NewServerRegistry()
+	defer registry.Close()
+
+	err := registry.StartServer("broken", ServerDefinition{
+		Command: "sh",
+		Args:    []string{"-c", "echo 'missing API key' >&2; exit 3"},
+	})
+	if err == nil {
+		t.Fatal("Expected starting an exiting server to fail")
+	}
+
+	var transportErr *TransportError
+	if !errors.As(err, &transportErr) {
+		t.Fatalf("Expected a TransportError, got %T: %v", err, err)
+	}
+	if transportErr.Kind != TransportErrorProcessExited {
+		t.Fatalf("Expected kind %v, got %v", TransportErrorProcessExited, transportErr.Kind)
+	}
+
+	diagnosis := Diagnose(err)
+	for _, want := range []string{"broken", "exited with code 3", "missing API key"} {
+		if !strings.Contains(diagnosis, want) {
+			t.Errorf("Expected diagnosis to contain %q, got %q", want, diagnosis)
+		}
+	}
+}
+
+func TestWithServers_ReturnsStartupFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	config := ServerConfig{MCPServers: map[string]ServerDefinition{
+		"broken": {Command: "sh", Args: []string{"-c", "echo 'listening on stdio'; echo 'config.json not found' >&2; exit 2"}},
+	}}
+	_, err := NewClient("broken", WithServers(config, "broken"))
+	if err == nil {
+		t.Fatal("Expected NewClient to fail when the server exits")
+	}
+
+	// The error itself names the exit code and the last stderr lines
+	for _, want := range []string{"exit status 2", "config.json not found"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+		}
+	}
+
+	var transportErr *TransportError
+	if !errors.As(err, &transportErr) {
+		t.Fatalf("Expected a TransportError, got %T: %v", err, err)
+	}
+	if transportErr.ExitCode != 2 {
+		t.Errorf("Expected exit code 2, got %d", transportErr.ExitCode)
+	}
+}
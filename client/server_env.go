@@ -0,0 +1,219 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// secretPrefix marks a reference to a secret rather than an environment variable
+const secretPrefix = "secret:"
+
+// SecretResolver fetches the secrets referenced in server definitions, such as
+// ${secret:database/password}, from a secret store like Vault or a cloud KMS.
+// The reference is everything after "secret:".
+type SecretResolver interface {
+	ResolveSecret(ctx context.Context, reference string) (string, error)
+}
+
+// SecretResolverFunc adapts a function to the SecretResolver interface.
+type SecretResolverFunc func(ctx context.Context, reference string) (string, error)
+
+// ResolveSecret calls f(ctx, reference).
+func (f SecretResolverFunc) ResolveSecret(ctx context.Context, reference string) (string, error) {
+	return f(ctx, reference)
+}
+
+// WithSecretResolver sets how the registry resolves ${secret:...} references in
+// server definitions. Without one, definitions referencing secrets fail to start.
+func WithSecretResolver(resolver SecretResolver) ServerRegistryOption {
+	return func(r *ServerRegistry) {
+		r.secrets = resolver
+	}
+}
+
+// WithServerSecretResolver sets how ${secret:...} references in the server
+// definitions are resolved. See WithSecretResolver.
+func WithServerSecretResolver(resolver SecretResolver) ServerConfigOption {
+	return func(p *serverConfigParams) {
+		p.secretResolver = resolver
+	}
+}
+
+// resolveDefinition returns a definition with the references in its command,
+// arguments, environment, URL and working directory replaced, and with the variables of its env file
+// added to its environment.
+//
+// ${VAR} is replaced with the variable from the env file or the registry's
+// environment, ${VAR:-default} falls back to default when VAR is unset, and
+// ${secret:reference} is replaced with the secret fetched by the registry's
+// SecretResolver. Undefined variables are errors, so that a server is never
+// launched with a credential silently left empty.
+func (r *ServerRegistry) resolveDefinition(def ServerDefinition) (ServerDefinition, error) {
+	vars := map[string]string{}
+	if def.EnvFile != "" {
+		fileVars, err := readEnvFile(def.EnvFile)
+		if err != nil {
+			return def, err
+		}
+		vars = fileVars
+	}
+	lookup := func(name string) (string, bool) {
+		if value, ok := vars[name]; ok {
+			return value, true
+		}
+		return os.LookupEnv(name)
+	}
+
+	resolved := ServerDefinition{
+		EnvFile:  def.EnvFile,
+		CleanEnv: def.CleanEnv,
+		PassEnv:  def.PassEnv,
+		User:     def.User,
+		Group:    def.Group,
+		Limits:   def.Limits,
+	}
+	var err error
+	expand := func(field, value string) string {
+		if err != nil {
+			return value
+		}
+		var expanded string
+		if expanded, err = r.expand(value, lookup); err != nil {
+			err = fmt.Errorf("%s: %w", field, err)
+		}
+		return expanded
+	}
+
+	resolved.Command = expand("command", def.Command)
+	for i, arg := range def.Args {
+		resolved.Args = append(resolved.Args, expand(fmt.Sprintf("args[%d]", i), arg))
+	}
+	resolved.URL = expand("url", def.URL)
+	resolved.WorkingDirectory = expand("workingDirectory", def.WorkingDirectory)
+
+	if len(vars) > 0 || len(def.Env) > 0 {
+		resolved.Env = make(map[string]string, len(vars)+len(def.Env))
+		for name, value := range vars {
+			resolved.Env[name] = value
+		}
+
+		// Expand in a stable order so the first error reported does not vary
+		names := make([]string, 0, len(def.Env))
+		for name := range def.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			resolved.Env[name] = expand("env "+name, def.Env[name])
+		}
+	}
+
+	return resolved, err
+}
+
+// expand replaces the ${...} references in value
+func (r *ServerRegistry) expand(value string, lookup func(string) (string, bool)) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			b.WriteString(value)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(value[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated reference in %q", value)
+		}
+		b.WriteString(value[:start])
+		reference := value[start+2 : start+end]
+		value = value[start+end+1:]
+
+		if strings.HasPrefix(reference, secretPrefix) {
+			secret, err := r.resolveSecret(strings.TrimPrefix(reference, secretPrefix))
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(secret)
+			continue
+		}
+
+		name, fallback, hasDefault := strings.Cut(reference, ":-")
+		if name == "" {
+			return "", errors.New("empty variable reference ${}")
+		}
+		if variable, ok := lookup(name); ok {
+			b.WriteString(variable)
+		} else if hasDefault {
+			b.WriteString(fallback)
+		} else {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+	}
+}
+
+// resolveSecret fetches a secret with the registry's SecretResolver
+func (r *ServerRegistry) resolveSecret(reference string) (string, error) {
+	if r.secrets == nil {
+		return "", fmt.Errorf("secret %q referenced but no secret resolver is configured", reference)
+	}
+	secret, err := r.secrets.ResolveSecret(r.ctx, reference)
+	if err != nil {
+		// The reference names the secret without revealing it
+		return "", fmt.Errorf("failed to resolve secret %q: %w", reference, err)
+	}
+	return secret, nil
+}
+
+// readEnvFile reads KEY=VALUE lines from a dotenv-style file. Blank lines and
+// lines starting with # are skipped, an "export " prefix is allowed, and values
+// may be wrapped in single or double quotes.
+func readEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	defer file.Close()
+
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("env file %s line %d: expected KEY=VALUE", path, lineNumber)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		vars[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	return vars, nil
+}
+
+// resolveEnvFiles makes the env files of a configuration loaded from a file
+// relative to that file's directory
+func resolveEnvFiles(config *ServerConfig, configPath string) {
+	dir := filepath.Dir(configPath)
+	for name, def := range config.MCPServers {
+		if def.EnvFile != "" && !filepath.IsAbs(def.EnvFile) {
+			def.EnvFile = filepath.Join(dir, def.EnvFile)
+			config.MCPServers[name] = def
+		}
+	}
+}
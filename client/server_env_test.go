@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveDefinition(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "server.env")
+	content := "# Database settings\nexport DB_HOST=db.internal\nDB_NAME=\"orders\"\n\nGOMCP_TEST_SHADOWED='from file'\n"
+	if err := os.WriteFile(envFile, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+	t.Setenv("GOMCP_TEST_SHADOWED", "from process")
+	t.Setenv("GOMCP_TEST_BIN", "/opt/bin")
+
+	var references []string
+	registry := NewServerRegistry(WithSecretResolver(SecretResolverFunc(func(ctx context.Context, reference string) (string, error) {
+		references = append(references, reference)
+		return "s3cr3t", nil
+	})))
+	defer registry.Close()
+
+	def, err := registry.resolveDefinition(ServerDefinition{
+		Command: "${GOMCP_TEST_BIN}/server",
+		Args:    []string{"--db", "${DB_HOST}/${DB_NAME}", "--level=${GOMCP_TEST_LEVEL:-info}"},
+		Env: map[string]string{
+			"DB_PASSWORD": "${secret:vault:database/orders#password}",
+			"SOURCE":      "${GOMCP_TEST_SHADOWED}",
+		},
+		EnvFile: envFile,
+		URL:     "http://${DB_HOST}:8080",
+	})
+	if err != nil {
+		t.Fatalf("resolveDefinition failed: %v", err)
+	}
+
+	if def.Command != "/opt/bin/server" {
+		t.Errorf("Expected the command to be expanded, got %q", def.Command)
+	}
+	if got := strings.Join(def.Args, " "); got != "--db db.internal/orders --level=info" {
+		t.Errorf("Expected the args to be expanded, got %q", got)
+	}
+	if def.URL != "http://db.internal:8080" {
+		t.Errorf("Expected the URL to be expanded, got %q", def.URL)
+	}
+	want := map[string]string{
+		"DB_HOST":             "db.internal",
+		"DB_NAME":             "orders",
+		"GOMCP_TEST_SHADOWED": "from file",
+		"DB_PASSWORD":         "s3cr3t",
+		"SOURCE":              "from file",
+	}
+	for name, value := range want {
+		if def.Env[name] != value {
+			t.Errorf("Expected env %s=%q, got %q", name, value, def.Env[name])
+		}
+	}
+	if len(references) != 1 || references[0] != "vault:database/orders#password" {
+		t.Errorf("Expected the secret reference to be passed to the resolver, got %q", references)
+	}
+}
+
+func TestResolveDefinitionErrors(t *testing.T) {
+	failing := SecretResolverFunc(func(ctx context.Context, reference string) (string, error) {
+		return "", errors.New("permission denied")
+	})
+
+	tests := []struct {
+		name     string
+		def      ServerDefinition
+		resolver SecretResolver
+		want     string
+	}{
+		{"undefined variable", ServerDefinition{Command: "server", Args: []string{"${GOMCP_TEST_UNDEFINED}"}}, nil, "args[0]: environment variable GOMCP_TEST_UNDEFINED is not set"},
+		{"no resolver", ServerDefinition{Command: "server", Env: map[string]string{"TOKEN": "${secret:token}"}}, nil, `env TOKEN: secret "token" referenced but no secret resolver is configured`},
+		{"resolver error", ServerDefinition{Command: "server", Env: map[string]string{"TOKEN": "${secret:token}"}}, failing, `failed to resolve secret "token": permission denied`},
+		{"unterminated", ServerDefinition{Command: "${HOME"}, nil, "command: unterminated reference"},
+		{"missing env file", ServerDefinition{Command: "server", EnvFile: "/nonexistent/server.env"}, nil, "failed to read env file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []ServerRegistryOption
+			if tt.resolver != nil {
+				opts = append(opts, WithSecretResolver(tt.resolver))
+			}
+			registry := NewServerRegistry(opts...)
+			defer registry.Close()
+
+			_, err := registry.resolveDefinition(tt.def)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigResolvesEnvFileAndRejectsUndefinedVariables(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "mcp.json")
+	config := `{"mcpServers": {"orders": {"command": "${GOMCP_TEST_UNDEFINED}", "envFile": "orders.env"}}}`
+	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	registry := NewServerRegistry()
+	defer registry.Close()
+
+	// The env file is looked up next to the config file
+	err := registry.LoadConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(dir, "orders.env")) {
+		t.Fatalf("Expected the env file to be resolved against the config directory, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "orders.env"), []byte("ORDERS_DB=orders\n"), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+	err = registry.LoadConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "GOMCP_TEST_UNDEFINED is not set") {
+		t.Fatalf("Expected the undefined variable to be reported, got %v", err)
+	}
+	if names, _ := registry.GetServerNames(); len(names) != 0 {
+		t.Errorf("Expected no server to be started, got %v", names)
+	}
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/localrivet/gomcp/util/schema"
+)
+
+// ServerError is a JSON-RPC error response of the server. Use errors.As to inspect
+// it: servers that report why a request failed, such as gomcp servers for unknown
+// tools or unsupported protocol versions, fill in its Reason and Hint.
+//
+// Example:
+//
+//	var serverErr *client.ServerError
+//	if errors.As(err, &serverErr) && serverErr.Reason == mcp.ReasonToolNotFound {
+//	    refreshTools()
+//	}
+type ServerError struct {
+	// Code is the JSON-RPC error code
+	Code int
+
+	// Message is the JSON-RPC error message, such as "Invalid params"
+	Message string
+
+	// Reason tells why the request failed, if the server reported it
+	Reason mcp.ErrorReason
+
+	// Detail describes the failure, if the server reported more than the message
+	Detail string
+
+	// Hint tells how to fix the failure, if the server reported it
+	Hint string
+
+	// Violations lists the constraints invalid parameters violated
+	Violations []schema.FieldError
+
+	// Data is the data of the error response as decoded from JSON
+	Data interface{}
+}
+
+// Error returns the error code and message, followed by the detail if any.
+func (e *ServerError) Error() string {
+	message := fmt.Sprintf("JSON-RPC error %d: %s", e.Code, e.Message)
+	if e.Detail != "" && e.Detail != e.Message {
+		message = fmt.Sprintf("%s: %s", message, e.Detail)
+	}
+	return message
+}
+
+// Diagnose returns an actionable explanation of the failure, including the hint
+// of the server if it sent one.
+func (e *ServerError) Diagnose() string {
+	if e.Hint == "" {
+		return e.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.Error(), e.Hint)
+}
+
+// newServerError builds the error of an error response, picking the reason, hint
+// and violations out of its data
+func newServerError(code int, message string, data interface{}) *ServerError {
+	serverErr := &ServerError{Code: code, Message: message, Data: data}
+
+	switch data := data.(type) {
+	case string:
+		serverErr.Detail = data
+	case map[string]interface{}:
+		var decoded struct {
+			mcp.ErrorData
+			Violations []schema.FieldError `json:"violations"`
+		}
+		if encoded, err := json.Marshal(data); err == nil && json.Unmarshal(encoded, &decoded) == nil {
+			serverErr.Reason = decoded.Reason
+			serverErr.Detail = decoded.Message
+			serverErr.Hint = decoded.Hint
+			serverErr.Violations = decoded.Violations
+		}
+	}
+	return serverErr
+}
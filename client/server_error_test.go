@@ -0,0 +1,47 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/localrivet/gomcp/mcp"
+)
+
+func TestServerError(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(`{
+		"reason": "invalid_params",
+		"message": "invalid arguments: limit is too large",
+		"hint": "fix the arguments listed in violations",
+		"violations": [{"field": "limit", "constraint": "maximum", "message": "limit must be at most 100"}]
+	}`), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	err := fmt.Errorf("failed to call tool: %w", newServerError(-32602, "Invalid params", data))
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) {
+		t.Fatalf("Expected a server error, got %v", err)
+	}
+	if serverErr.Reason != mcp.ReasonInvalidParams {
+		t.Errorf("Expected reason %s, got %s", mcp.ReasonInvalidParams, serverErr.Reason)
+	}
+	if len(serverErr.Violations) != 1 || serverErr.Violations[0].Field != "limit" {
+		t.Errorf("Expected the violation of limit, got %+v", serverErr.Violations)
+	}
+	if want := "JSON-RPC error -32602: Invalid params: invalid arguments: limit is too large"; serverErr.Error() != want {
+		t.Errorf("Expected error %q, got %q", want, serverErr.Error())
+	}
+	if diagnosis := Diagnose(err); !strings.HasSuffix(diagnosis, ": fix the arguments listed in violations") {
+		t.Errorf("Expected the diagnosis to end with the hint, got %q", diagnosis)
+	}
+
+	// Servers that send no reason still produce a server error
+	plain := newServerError(-32603, "Internal error", "disk full")
+	if plain.Reason != "" || plain.Detail != "disk full" || plain.Diagnose() != "JSON-RPC error -32603: Internal error: disk full" {
+		t.Errorf("Unexpected server error %+v", plain)
+	}
+}
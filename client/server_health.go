@@ -0,0 +1,252 @@
+// Package client provides the client-side implementation of the MCP protocol.
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultHealthFailureThreshold is the number of consecutive failed pings
+// after which a managed server is reported as unhealthy.
+const defaultHealthFailureThreshold = 3
+
+// defaultHealthCheckTimeout bounds a health check ping when neither a timeout
+// nor an interval has been configured.
+const defaultHealthCheckTimeout = 5 * time.Second
+
+// errPingTimeout is the error of a ping the server did not answer in time.
+var errPingTimeout = errors.New("ping timed out")
+
+// ServerHealth describes the health of a server managed by a ServerRegistry.
+type ServerHealth struct {
+	// Name is the server name as registered in the configuration
+	Name string `json:"name"`
+
+	// Healthy is false once ConsecutiveFailures reaches the registry's failure threshold
+	Healthy bool `json:"healthy"`
+
+	// ConsecutiveFailures counts failed pings since the last successful one
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+
+	// LastCheck is when the server was last pinged (zero if never checked)
+	LastCheck time.Time `json:"lastCheck,omitempty"`
+
+	// LastSuccess is when the server last answered a ping (zero if never)
+	LastSuccess time.Time `json:"lastSuccess,omitempty"`
+
+	// LastError is the error from the most recent failed ping, if any
+	LastError string `json:"lastError,omitempty"`
+
+	// Latency is the round-trip time of the most recent successful ping
+	Latency time.Duration `json:"latency"`
+}
+
+// healthState holds the health check configuration and per-server status for a registry.
+type healthState struct {
+	interval  time.Duration
+	threshold int
+	timeout   time.Duration
+
+	mu     sync.RWMutex
+	status map[string]*ServerHealth
+	once   sync.Once
+}
+
+// WithHealthCheck enables periodic health checking of managed servers.
+// Every interval the registry pings each server; a server is marked unhealthy
+// after three consecutive failures (see WithHealthCheckThreshold) and healthy
+// again as soon as a ping succeeds. Current status is available via Health().
+func WithHealthCheck(interval time.Duration) ServerRegistryOption {
+	return func(r *ServerRegistry) {
+		r.health.interval = interval
+	}
+}
+
+// WithHealthCheckThreshold sets how many consecutive failed pings mark a server unhealthy.
+func WithHealthCheckThreshold(failures int) ServerRegistryOption {
+	return func(r *ServerRegistry) {
+		if failures > 0 {
+			r.health.threshold = failures
+		}
+	}
+}
+
+// WithHealthCheckTimeout sets how long a single health check ping may take.
+// It defaults to the health check interval, or five seconds if no interval is set.
+func WithHealthCheckTimeout(timeout time.Duration) ServerRegistryOption {
+	return func(r *ServerRegistry) {
+		r.health.timeout = timeout
+	}
+}
+
+// Health returns the current health status of every managed server, keyed by server name.
+// Servers that have not been checked yet are reported as healthy with a zero LastCheck.
+func (r *ServerRegistry) Health() map[string]ServerHealth {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.servers))
+	for name := range r.servers {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+
+	r.health.mu.RLock()
+	defer r.health.mu.RUnlock()
+
+	result := make(map[string]ServerHealth, len(names))
+	for _, name := range names {
+		if status, exists := r.health.status[name]; exists {
+			result[name] = *status
+		} else {
+			result[name] = ServerHealth{Name: name, Healthy: true}
+		}
+	}
+
+	return result
+}
+
+// IsHealthy reports whether the named server is currently considered healthy.
+func (r *ServerRegistry) IsHealthy(name string) bool {
+	status, exists := r.Health()[name]
+	return exists && status.Healthy
+}
+
+// startHealthChecks launches the background health check loop if it is enabled.
+// The loop stops when the registry is closed.
+func (r *ServerRegistry) startHealthChecks() {
+	if r.health.interval <= 0 {
+		return
+	}
+
+	r.health.once.Do(func() {
+		go func() {
+			ticker := time.NewTicker(r.health.interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-r.ctx.Done():
+					return
+				case <-ticker.C:
+					r.checkHealth()
+				}
+			}
+		}()
+	})
+}
+
+// checkHealth pings every managed server concurrently and records the results.
+func (r *ServerRegistry) checkHealth() {
+	r.mu.RLock()
+	servers := make([]*MCPServer, 0, len(r.servers))
+	for _, server := range r.servers {
+		servers = append(servers, server)
+	}
+	r.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server *MCPServer) {
+			defer wg.Done()
+			start := time.Now()
+			err := r.pingServer(server)
+			r.recordHealth(server.Name, start, time.Since(start), err)
+		}(server)
+	}
+	wg.Wait()
+}
+
+// pingServer pings a single server, bounded by the health check timeout.
+func (r *ServerRegistry) pingServer(server *MCPServer) error {
+	if server.Client == nil {
+		return fmt.Errorf("server %s has no connected client", server.Name)
+	}
+
+	timeout := r.health.timeout
+	if timeout <= 0 {
+		timeout = r.health.interval
+	}
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	return pingWithin(server, timeout)
+}
+
+// pendingPing is a ping of a server's client that has not returned yet.
+type pendingPing struct {
+	done chan struct{}
+	err  error
+}
+
+// pingWithin pings a server's client, failing with errPingTimeout if it does not
+// answer within timeout. Client.Ping cannot be abandoned, so while a ping is
+// outstanding later callers wait for it instead of starting another, and a hung
+// server holds one goroutine however often it is checked.
+func pingWithin(server *MCPServer, timeout time.Duration) error {
+	server.pingMu.Lock()
+	ping := server.ping
+	if ping == nil {
+		ping = &pendingPing{done: make(chan struct{})}
+		server.ping = ping
+		go func() {
+			err := server.Client.Ping()
+			server.pingMu.Lock()
+			server.ping = nil
+			server.pingMu.Unlock()
+			ping.err = err
+			close(ping.done)
+		}()
+	}
+	server.pingMu.Unlock()
+
+	select {
+	case <-ping.done:
+		return ping.err
+	case <-time.After(timeout):
+		return fmt.Errorf("%w after %s", errPingTimeout, timeout)
+	}
+}
+
+// recordHealth updates the health status of a server after a ping attempt.
+func (r *ServerRegistry) recordHealth(name string, checkedAt time.Time, latency time.Duration, err error) {
+	r.health.mu.Lock()
+	defer r.health.mu.Unlock()
+
+	status, exists := r.health.status[name]
+	if !exists {
+		status = &ServerHealth{Name: name, Healthy: true}
+		r.health.status[name] = status
+	}
+
+	status.LastCheck = checkedAt
+	if err == nil {
+		if !status.Healthy && r.logger != nil {
+			r.logger.Info("Server recovered", "server", name, "failures", status.ConsecutiveFailures)
+		}
+		status.Healthy = true
+		status.ConsecutiveFailures = 0
+		status.LastSuccess = checkedAt
+		status.LastError = ""
+		status.Latency = latency
+		return
+	}
+
+	status.ConsecutiveFailures++
+	status.LastError = err.Error()
+	if status.Healthy && status.ConsecutiveFailures >= r.health.threshold {
+		status.Healthy = false
+		if r.logger != nil {
+			r.logger.Warn("Server marked unhealthy", "server", name,
+				"failures", status.ConsecutiveFailures, "error", err)
+		}
+	}
+}
+
+// forgetHealth drops the health status of a server that is no longer managed.
+func (r *ServerRegistry) forgetHealth(name string) {
+	r.health.mu.Lock()
+	defer r.health.mu.Unlock()
+	delete(r.health.status, name)
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pingClient is a Client whose Ping result can be switched at runtime.
+type pingClient struct {
+	Client
+	mu  sync.Mutex
+	err error
+}
+
+func (c *pingClient) Ping() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *pingClient) setErr(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+func (c *pingClient) Close() error {
+	return nil
+}
+
+func TestServerRegistry_HealthCheckMarksUnhealthyAndRecovers(t *testing.T) {
+	registry := NewServerRegistry(WithHealthCheckThreshold(2))
+	defer registry.Close()
+
+	fake := &pingClient{}
+	registry.mu.Lock()
+	registry.servers["fake"] = &MCPServer{Name: "fake", Client: fake}
+	registry.mu.Unlock()
+
+	status := registry.Health()["fake"]
+	if !status.Healthy || !status.LastCheck.IsZero() {
+		t.Fatalf("Expected unchecked server to be healthy, got %+v", status)
+	}
+
+	registry.checkHealth()
+	status = registry.Health()["fake"]
+	if !status.Healthy || status.LastSuccess.IsZero() {
+		t.Fatalf("Expected healthy server after successful ping, got %+v", status)
+	}
+
+	fake.setErr(errors.New("connection reset"))
+	registry.checkHealth()
+	if !registry.IsHealthy("fake") {
+		t.Fatal("Server should stay healthy below the failure threshold")
+	}
+
+	registry.checkHealth()
+	status = registry.Health()["fake"]
+	if status.Healthy {
+		t.Fatal("Server should be unhealthy after reaching the failure threshold")
+	}
+	if status.ConsecutiveFailures != 2 || status.LastError != "connection reset" {
+		t.Fatalf("Unexpected failure tracking: %+v", status)
+	}
+
+	fake.setErr(nil)
+	registry.checkHealth()
+	status = registry.Health()["fake"]
+	if !status.Healthy || status.ConsecutiveFailures != 0 || status.LastError != "" {
+		t.Fatalf("Expected server to recover after successful ping, got %+v", status)
+	}
+
+	if err := registry.StopServer("fake"); err != nil {
+		t.Fatalf("Failed to stop server: %v", err)
+	}
+	if _, exists := registry.Health()["fake"]; exists {
+		t.Fatal("Stopped server should not be reported")
+	}
+}
+
+func TestServerRegistry_HealthCheckLoop(t *testing.T) {
+	registry := NewServerRegistry(
+		WithHealthCheck(10*time.Millisecond),
+		WithHealthCheckThreshold(1),
+	)
+	defer registry.Close()
+
+	fake := &pingClient{err: errors.New("unreachable")}
+	registry.mu.Lock()
+	registry.servers["fake"] = &MCPServer{Name: "fake", Client: fake}
+	registry.mu.Unlock()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for registry.IsHealthy("fake") {
+		if time.Now().After(deadline) {
+			t.Fatal("Health check loop did not mark failing server unhealthy")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestServerRegistry_HealthCheckNilClient(t *testing.T) {
+	registry := NewServerRegistry(WithHealthCheckThreshold(1))
+	defer registry.Close()
+
+	registry.mu.Lock()
+	registry.servers["detached"] = &MCPServer{Name: "detached"}
+	registry.mu.Unlock()
+
+	registry.checkHealth()
+	if registry.IsHealthy("detached") {
+		t.Fatal("Server without a client should be reported unhealthy")
+	}
+}
+
+// blockedClient is a Client whose Ping blocks until released, counting calls.
+type blockedClient struct {
+	Client
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (c *blockedClient) Ping() error {
+	c.calls.Add(1)
+	<-c.release
+	return nil
+}
+
+func (c *blockedClient) Close() error {
+	return nil
+}
+
+func TestServerRegistry_HealthCheckWaitsForPendingPing(t *testing.T) {
+	registry := NewServerRegistry(WithHealthCheckThreshold(2), WithHealthCheckTimeout(10*time.Millisecond))
+	defer registry.Close()
+
+	fake := &blockedClient{release: make(chan struct{})}
+	registry.mu.Lock()
+	registry.servers["slow"] = &MCPServer{Name: "slow", Client: fake}
+	registry.mu.Unlock()
+
+	for i := 0; i < 3; i++ {
+		registry.checkHealth()
+	}
+	if registry.IsHealthy("slow") {
+		t.Fatal("Server that does not answer should be reported unhealthy")
+	}
+	if calls := fake.calls.Load(); calls != 1 {
+		t.Fatalf("Expected checks to wait for the pending ping, got %d pings", calls)
+	}
+
+	close(fake.release)
+	deadline := time.Now().Add(2 * time.Second)
+	for !registry.IsHealthy("slow") {
+		if time.Now().After(deadline) {
+			t.Fatal("Server did not recover once it answered")
+		}
+		registry.checkHealth()
+		time.Sleep(5 * time.Millisecond)
+	}
+}
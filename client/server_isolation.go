@@ -0,0 +1,154 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ProcessLimits bound the resources of a server process launched by a
+// ServerRegistry, to contain servers that are not trusted. Zero values mean no
+// limit. Limits are only supported on Linux.
+type ProcessLimits struct {
+	// MemoryBytes limits the memory of the process: the memory of its cgroup when
+	// the registry has a cgroup parent (see WithCgroupParent), and otherwise its
+	// address space.
+	MemoryBytes int64 `json:"memoryBytes,omitempty"`
+
+	// CPUSeconds is the CPU time after which the process is killed.
+	CPUSeconds int64 `json:"cpuSeconds,omitempty"`
+
+	// CPUs limits the CPU the process gets, as a number of CPUs such as 0.5. It
+	// requires a cgroup parent.
+	CPUs float64 `json:"cpus,omitempty"`
+
+	// MaxOpenFiles limits the file descriptors the process may open.
+	MaxOpenFiles int64 `json:"maxOpenFiles,omitempty"`
+
+	// MaxProcesses limits the processes and threads the server may run. It
+	// requires a cgroup parent.
+	MaxProcesses int64 `json:"maxProcesses,omitempty"`
+}
+
+// isZero reports whether no limit is set
+func (l *ProcessLimits) isZero() bool {
+	return l == nil || *l == ProcessLimits{}
+}
+
+// WithCgroupParent enforces the memory, CPU and process limits of the servers the
+// registry launches with a cgroup v2 created for each server in dir, such as a
+// directory under /sys/fs/cgroup delegated to the registry's user. The memory,
+// cpu and pids controllers must be enabled in dir's cgroup.subtree_control.
+func WithCgroupParent(dir string) ServerRegistryOption {
+	return func(r *ServerRegistry) {
+		r.cgroupParent = dir
+	}
+}
+
+// WithServerCgroupParent sets where the cgroups enforcing the limits of the
+// servers are created. See WithCgroupParent.
+func WithServerCgroupParent(dir string) ServerConfigOption {
+	return func(p *serverConfigParams) {
+		p.cgroupParent = dir
+	}
+}
+
+// serverEnv returns the environment of a server process: the registry's
+// environment, or only its PassEnv variables when the definition asks for a clean
+// environment, followed by the definition's own variables
+func serverEnv(def ServerDefinition) []string {
+	// An empty but non-nil environment keeps the command from inheriting ours
+	env := []string{}
+	if def.CleanEnv {
+		for _, name := range def.PassEnv {
+			if value, ok := os.LookupEnv(name); ok {
+				env = append(env, name+"="+value)
+			}
+		}
+	} else {
+		env = os.Environ()
+	}
+	for k, v := range def.Env {
+		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	}
+	return env
+}
+
+// setCredential makes a command run as the definition's user and group, given as
+// names or numeric IDs. Without a group, the user's primary group is used.
+func setCredential(cmd *exec.Cmd, def ServerDefinition) error {
+	if def.User == "" && def.Group == "" {
+		return nil
+	}
+
+	uid, gid := uint32(os.Getuid()), uint32(os.Getgid())
+	if def.User != "" {
+		u, err := lookupUser(def.User)
+		if err != nil {
+			return err
+		}
+		if uid, err = parseID(u.Uid); err != nil {
+			return fmt.Errorf("user %s: %w", def.User, err)
+		}
+		if gid, err = parseID(u.Gid); err != nil {
+			return fmt.Errorf("user %s: %w", def.User, err)
+		}
+	}
+	if def.Group != "" {
+		g, err := lookupGroup(def.Group)
+		if err != nil {
+			return err
+		}
+		if gid, err = parseID(g.Gid); err != nil {
+			return fmt.Errorf("group %s: %w", def.Group, err)
+		}
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	// Supplementary groups are dropped along with the user's privileges
+	cmd.SysProcAttr.Credential = &syscall.Credential{Uid: uid, Gid: gid}
+	return nil
+}
+
+// lookupUser finds a user by name or numeric ID
+func lookupUser(name string) (*user.User, error) {
+	u, err := user.Lookup(name)
+	if err == nil {
+		return u, nil
+	}
+	if _, parseErr := parseID(name); parseErr == nil {
+		if u, idErr := user.LookupId(name); idErr == nil {
+			return u, nil
+		}
+		// A numeric ID need not name an account
+		return &user.User{Uid: name, Gid: strconv.Itoa(os.Getgid())}, nil
+	}
+	return nil, fmt.Errorf("unknown user %s: %w", name, err)
+}
+
+// lookupGroup finds a group by name or numeric ID
+func lookupGroup(name string) (*user.Group, error) {
+	g, err := user.LookupGroup(name)
+	if err == nil {
+		return g, nil
+	}
+	if _, parseErr := parseID(name); parseErr == nil {
+		return &user.Group{Gid: name}, nil
+	}
+	return nil, fmt.Errorf("unknown group %s: %w", name, err)
+}
+
+// parseID parses a numeric user or group ID
+func parseID(id string) (uint32, error) {
+	n, err := strconv.ParseUint(strings.TrimSpace(id), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID %q", id)
+	}
+	return uint32(n), nil
+}
@@ -0,0 +1,135 @@
+//go:build linux
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// shellPath is the shell that sets the rlimits of a server before running it
+const shellPath = "/bin/sh"
+
+// processLimits keeps a server process within its ProcessLimits, with rlimits and,
+// when the registry has a cgroup parent, a cgroup of its own
+type processLimits struct {
+	limits *ProcessLimits
+
+	cgroup    string
+	cgroupDir *os.File
+}
+
+// prepareLimits creates the cgroup a command is to start in, if its limits need
+// one, and makes the command set its rlimits
+func (r *ServerRegistry) prepareLimits(name string, cmd *exec.Cmd, limits *ProcessLimits) (*processLimits, error) {
+	p := &processLimits{limits: limits}
+	if limits.isZero() {
+		return p, nil
+	}
+	if r.cgroupParent == "" {
+		if limits.CPUs > 0 || limits.MaxProcesses > 0 {
+			return nil, errors.New("CPU and process limits require a cgroup parent (see WithCgroupParent)")
+		}
+		p.limitCommand(cmd)
+		return p, nil
+	}
+	if limits.MemoryBytes == 0 && limits.CPUs == 0 && limits.MaxProcesses == 0 {
+		p.limitCommand(cmd)
+		return p, nil
+	}
+
+	dir, err := os.MkdirTemp(r.cgroupParent, name+"-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cgroup: %w", err)
+	}
+	p.cgroup = dir
+
+	settings := map[string]string{}
+	if limits.MemoryBytes > 0 {
+		settings["memory.max"] = strconv.FormatInt(limits.MemoryBytes, 10)
+	}
+	if limits.CPUs > 0 {
+		const period = 100000
+		quota := int64(limits.CPUs * period)
+		if quota < 1000 {
+			quota = 1000
+		}
+		settings["cpu.max"] = fmt.Sprintf("%d %d", quota, period)
+	}
+	if limits.MaxProcesses > 0 {
+		settings["pids.max"] = strconv.FormatInt(limits.MaxProcesses, 10)
+	}
+	for file, value := range settings {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(value), 0); err != nil {
+			p.release()
+			return nil, fmt.Errorf("failed to set %s: %w", file, err)
+		}
+	}
+
+	if p.cgroupDir, err = os.Open(dir); err != nil {
+		p.release()
+		return nil, fmt.Errorf("failed to open cgroup: %w", err)
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(p.cgroupDir.Fd())
+	p.limitCommand(cmd)
+	return p, nil
+}
+
+// limitCommand makes a command set its rlimits before it runs the server: the
+// command is run by a shell that lowers the limits and then execs it, so the
+// server never runs without them. The shell runs with the command's credentials
+// and in its cgroup.
+func (p *processLimits) limitCommand(cmd *exec.Cmd) {
+	var ulimits []string
+	if p.limits.MemoryBytes > 0 && p.cgroup == "" {
+		// The shell's address space limit is in KiB
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -v %d", max(p.limits.MemoryBytes/1024, 1)))
+	}
+	if p.limits.CPUSeconds > 0 {
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -t %d", p.limits.CPUSeconds))
+	}
+	if p.limits.MaxOpenFiles > 0 {
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -n %d", p.limits.MaxOpenFiles))
+	}
+	if len(ulimits) == 0 || cmd.Err != nil {
+		// Start reports a command that was not found
+		return
+	}
+
+	script := strings.Join(ulimits, " && ") + ` && exec "$0" "$@"`
+	cmd.Args = append([]string{"sh", "-c", script, cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = shellPath
+}
+
+// release kills what is left in the process's cgroup once it has exited and
+// removes the cgroup
+func (p *processLimits) release() {
+	if p.cgroupDir != nil {
+		p.cgroupDir.Close()
+		p.cgroupDir = nil
+	}
+	if p.cgroup == "" {
+		return
+	}
+
+	// A cgroup can only be removed once its processes are gone
+	os.WriteFile(filepath.Join(p.cgroup, "cgroup.kill"), []byte("1"), 0)
+	for i := 0; i < 50; i++ {
+		if err := os.Remove(p.cgroup); err == nil || os.IsNotExist(err) {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	p.cgroup = ""
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package client
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// processLimits keeps a server process within its ProcessLimits, which are not
+// supported on this platform
+type processLimits struct{}
+
+// prepareLimits fails if any limit is set
+func (r *ServerRegistry) prepareLimits(name string, cmd *exec.Cmd, limits *ProcessLimits) (*processLimits, error) {
+	if !limits.isZero() {
+		return nil, errors.New("process limits are only supported on Linux")
+	}
+	return &processLimits{}, nil
+}
+
+func (p *processLimits) release() {}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// runIsolated starts a server that runs script and exits, and returns what the
+// script wrote to $OUT
+func runIsolated(t *testing.T, def ServerDefinition, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	out := filepath.Join(t.TempDir(), "out")
+	def.Command = "sh"
+	def.Args = []string{"-c", script + " > " + out}
+	registry := NewServerRegistry(WithStderrPassthrough(false))
+	defer registry.Close()
+
+	if err := registry.StartServer("isolated", def); err == nil {
+		t.Fatal("Expected starting an exiting server to fail")
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Expected the server to write its output: %v", err)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func TestServerRegistry_WorkingDirectory(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := runIsolated(t, ServerDefinition{WorkingDirectory: dir}, "pwd"); got != dir {
+		t.Errorf("Expected the server to run in %s, got %s", dir, got)
+	}
+}
+
+func TestServerRegistry_CleanEnv(t *testing.T) {
+	t.Setenv("ISOLATION_SECRET", "hunter2")
+	t.Setenv("ISOLATION_PASSED", "visible")
+
+	def := ServerDefinition{
+		Env:      map[string]string{"ISOLATION_OWN": "own"},
+		CleanEnv: true,
+		PassEnv:  []string{"ISOLATION_PASSED", "ISOLATION_UNSET"},
+	}
+	// $VAR is left to the shell, while ${VAR} would be expanded by the registry
+	got := runIsolated(t, def, `echo "[$ISOLATION_SECRET] $ISOLATION_PASSED $ISOLATION_OWN"`)
+	if got != "[] visible own" {
+		t.Errorf("Expected only the passed and own variables, got %q", got)
+	}
+
+	// Without CleanEnv the registry's environment is inherited
+	got = runIsolated(t, ServerDefinition{}, `echo "$ISOLATION_SECRET"`)
+	if got != "hunter2" {
+		t.Errorf("Expected the registry's environment, got %q", got)
+	}
+}
+
+func TestServerRegistry_Limits(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process limits are only supported on Linux")
+	}
+
+	// The limits are set before the server runs
+	def := ServerDefinition{Limits: &ProcessLimits{MaxOpenFiles: 64, CPUSeconds: 30}}
+	got := runIsolated(t, def, "echo $(ulimit -n) $(ulimit -t)")
+	if got != "64 30" {
+		t.Errorf("Expected the limits to apply, got %q", got)
+	}
+
+	// CPU shares and process counts need a cgroup
+	registry := NewServerRegistry()
+	defer registry.Close()
+	err := registry.StartServer("unlimited", ServerDefinition{Command: "true", Limits: &ProcessLimits{CPUs: 0.5}})
+	if err == nil || !strings.Contains(err.Error(), "cgroup parent") {
+		t.Errorf("Expected CPU limits to require a cgroup parent, got %v", err)
+	}
+}
+
+func TestSetCredential(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credentials are not supported on Windows")
+	}
+
+	cmd := exec.Command("true")
+	if err := setCredential(cmd, ServerDefinition{}); err != nil || cmd.SysProcAttr != nil {
+		t.Errorf("Expected no credential without a user or group, got %v", err)
+	}
+
+	// Numeric IDs need not name an account; the group defaults to the registry's
+	if err := setCredential(cmd, ServerDefinition{User: "54321"}); err != nil {
+		t.Fatalf("setCredential failed: %v", err)
+	}
+	if c := cmd.SysProcAttr.Credential; c.Uid != 54321 || c.Gid != uint32(os.Getgid()) {
+		t.Errorf("Unexpected credential %+v", c)
+	}
+
+	if err := setCredential(cmd, ServerDefinition{User: strconv.Itoa(os.Getuid()), Group: "54322"}); err != nil {
+		t.Fatalf("setCredential failed: %v", err)
+	}
+	if c := cmd.SysProcAttr.Credential; c.Uid != uint32(os.Getuid()) || c.Gid != 54322 {
+		t.Errorf("Unexpected credential %+v", c)
+	}
+
+	if err := setCredential(cmd, ServerDefinition{User: "no-such-user-gomcp"}); err == nil {
+		t.Error("Expected an unknown user to fail")
+	}
+}
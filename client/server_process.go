@@ -0,0 +1,97 @@
+package client
+
+import (
+	"errors"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// maxStderrTail is how much of a server process's stderr is kept for diagnostics
+const maxStderrTail = 4096
+
+// processExitGrace is how long a stdio transport whose pipe closed waits for the
+// server process to exit, so that it can report the exit code
+const processExitGrace = 500 * time.Millisecond
+
+// stderrTail keeps the last output a server process wrote to stderr
+type stderrTail struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+// Write implements io.Writer, discarding all but the last maxStderrTail bytes
+func (t *stderrTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > maxStderrTail {
+		t.buf = append([]byte(nil), t.buf[len(t.buf)-maxStderrTail:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the kept output
+func (t *stderrTail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.buf)
+}
+
+// processExit waits for a server process and records how it exited. It owns the
+// process's single call to Wait.
+type processExit struct {
+	done     chan struct{}
+	err      error
+	exitCode int
+	stderr   *stderrTail
+}
+
+// watchProcess waits for a started command in the background
+func watchProcess(cmd *exec.Cmd, stderr *stderrTail) *processExit {
+	exit := &processExit{done: make(chan struct{}), stderr: stderr}
+	go func() {
+		exit.err = cmd.Wait()
+		exit.exitCode = -1
+		if cmd.ProcessState != nil {
+			exit.exitCode = cmd.ProcessState.ExitCode()
+		}
+		close(exit.done)
+	}()
+	return exit
+}
+
+// exited reports whether the process exited within the grace period
+func (e *processExit) exited(grace time.Duration) bool {
+	select {
+	case <-e.done:
+		return true
+	case <-time.After(grace):
+		return false
+	}
+}
+
+// transportError describes the closed pipe of a stdio transport, as a process exit
+// when the server process is gone
+func (e *processExit) transportError(op, target string, err error) *TransportError {
+	if e == nil || !e.exited(processExitGrace) {
+		return &TransportError{Kind: TransportErrorClosed, Op: op, Target: target, Err: err}
+	}
+
+	cause := e.err
+	if cause == nil {
+		cause = errors.New("exit status 0")
+	}
+	transportErr := &TransportError{
+		Kind:     TransportErrorProcessExited,
+		Op:       op,
+		Target:   target,
+		Err:      cause,
+		ExitCode: e.exitCode,
+	}
+	if e.stderr != nil {
+		transportErr.Stderr = e.stderr.String()
+	}
+	return transportErr
+}
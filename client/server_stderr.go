@@ -0,0 +1,263 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+)
+
+// stderrConfig is what a registry does with the stderr of the server processes it
+// launches
+type stderrConfig struct {
+	// passthrough copies stderr to the parent's stderr; nil means only when
+	// stderr is not captured otherwise
+	passthrough *bool
+
+	logLevel *slog.Level
+
+	dir        string
+	maxBytes   int64
+	maxBackups int
+
+	subject *events.Subject
+}
+
+// WithStderrLogging logs each line a launched server process writes to stderr to
+// the registry's logger at the given level, with the server's name in the
+// "server" attribute. Lines are not logged without a registry logger (see
+// WithRegistryLogger).
+func WithStderrLogging(level slog.Level) ServerRegistryOption {
+	return func(r *ServerRegistry) {
+		r.stderr.logLevel = &level
+	}
+}
+
+// WithStderrFiles writes the stderr of each launched server process to
+// <dir>/<server>.stderr.log. A file reaching maxBytes is renamed with a .1 suffix,
+// older files moving to .2 and so on, and only maxBackups of them are kept.
+// Files are not rotated when maxBytes is zero.
+func WithStderrFiles(dir string, maxBytes int64, maxBackups int) ServerRegistryOption {
+	return func(r *ServerRegistry) {
+		r.stderr.dir = dir
+		r.stderr.maxBytes = maxBytes
+		r.stderr.maxBackups = maxBackups
+	}
+}
+
+// WithStderrEvents publishes each line a launched server process writes to stderr
+// to subject as an events.ServerStderrEvent on events.TopicServerStderr.
+func WithStderrEvents(subject *events.Subject) ServerRegistryOption {
+	return func(r *ServerRegistry) {
+		r.stderr.subject = subject
+	}
+}
+
+// WithStderrPassthrough sets whether the stderr of launched server processes is
+// copied to the parent's stderr. By default it is unless it is logged, written to
+// files or published as events.
+func WithStderrPassthrough(enabled bool) ServerRegistryOption {
+	return func(r *ServerRegistry) {
+		r.stderr.passthrough = &enabled
+	}
+}
+
+// WithServerStderr sets what is done with the stderr of the launched server, with
+// WithStderrLogging, WithStderrFiles, WithStderrEvents and WithStderrPassthrough.
+func WithServerStderr(options ...ServerRegistryOption) ServerConfigOption {
+	return func(p *serverConfigParams) {
+		p.stderrOptions = append(p.stderrOptions, options...)
+	}
+}
+
+// captures reports whether stderr is captured other than by passing it through
+func (c *stderrConfig) captures() bool {
+	return c.logLevel != nil || c.dir != "" || c.subject != nil
+}
+
+// stderrWriter returns where the stderr of a server process goes, besides tail,
+// and a function to call once the process has exited
+func (r *ServerRegistry) stderrWriter(name string, tail *stderrTail) (io.Writer, func(), error) {
+	c := &r.stderr
+	writers := []io.Writer{tail}
+	var closers []func()
+
+	passthrough := !c.captures()
+	if c.passthrough != nil {
+		passthrough = *c.passthrough
+	}
+	if passthrough {
+		writers = append(writers, os.Stderr)
+	}
+
+	if c.dir != "" {
+		file, err := openRotatingFile(filepath.Join(c.dir, name+".stderr.log"), c.maxBytes, c.maxBackups)
+		if err != nil {
+			return nil, nil, err
+		}
+		writers = append(writers, file)
+		closers = append(closers, func() { file.Close() })
+	}
+
+	var handlers []func(line string)
+	if c.logLevel != nil && r.logger != nil {
+		logger, level := r.logger.With("server", name), *c.logLevel
+		handlers = append(handlers, func(line string) {
+			logger.Log(r.ctx, level, line)
+		})
+	}
+	if c.subject != nil {
+		subject := c.subject
+		handlers = append(handlers, func(line string) {
+			if err := events.Publish[events.ServerStderrEvent](subject, events.TopicServerStderr, events.ServerStderrEvent{
+				Server:    name,
+				Line:      line,
+				WrittenAt: time.Now(),
+			}); err != nil && r.logger != nil {
+				r.logger.Warn("failed to publish server stderr event", "server", name, "error", err)
+			}
+		})
+	}
+	if len(handlers) > 0 {
+		lines := &lineWriter{handle: func(line string) {
+			for _, handle := range handlers {
+				handle(line)
+			}
+		}}
+		writers = append(writers, lines)
+		closers = append(closers, lines.flush)
+	}
+
+	return io.MultiWriter(writers...), func() {
+		for _, closer := range closers {
+			closer()
+		}
+	}, nil
+}
+
+// lineWriter calls handle with each complete line written to it
+type lineWriter struct {
+	mu      sync.Mutex
+	pending []byte
+	handle  func(line string)
+}
+
+// Write implements io.Writer
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(w.pending, p...)
+	for {
+		end := bytes.IndexByte(w.pending, '\n')
+		if end < 0 {
+			break
+		}
+		w.handle(strings.TrimSuffix(string(w.pending[:end]), "\r"))
+		w.pending = w.pending[end+1:]
+	}
+	return len(p), nil
+}
+
+// flush handles the last line when it is not terminated by a newline
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.pending) > 0 {
+		w.handle(string(w.pending))
+		w.pending = nil
+	}
+}
+
+// rotatingFile is a file renamed to make way for a new one when it reaches its
+// maximum size
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// openRotatingFile opens a file for appending, creating its directory if needed
+func openRotatingFile(path string, maxBytes int64, maxBackups int) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create stderr directory: %w", err)
+	}
+	f := &rotatingFile{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open stderr file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open stderr file: %w", err)
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Write implements io.Writer, rotating the file first if the write would take it
+// past its maximum size
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.maxBytes > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxBytes {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups up by one, dropping the oldest, and starts a new file
+func (f *rotatingFile) rotate() error {
+	f.file.Close()
+	f.file = nil
+
+	if f.maxBackups > 0 {
+		for i := f.maxBackups - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+		}
+		os.Rename(f.path, f.path+".1")
+	} else {
+		os.Remove(f.path)
+	}
+	return f.open()
+}
+
+// Close closes the file
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
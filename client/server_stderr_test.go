@@ -0,0 +1,131 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestServerRegistry_CapturesStderr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	var logs syncBuffer
+	dir := t.TempDir()
+	subject := events.NewSubject()
+	lines := make(chan events.ServerStderrEvent, 4)
+	events.Subscribe[events.ServerStderrEvent](subject, events.TopicServerStderr,
+		func(ctx context.Context, event events.ServerStderrEvent) error {
+			lines <- event
+			return nil
+		})
+
+	registry := NewServerRegistry(
+		WithRegistryLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		WithStderrLogging(slog.LevelWarn),
+		WithStderrFiles(dir, 0, 0),
+		WithStderrEvents(subject),
+	)
+	defer registry.Close()
+
+	err := registry.StartServer("broken", ServerDefinition{
+		Command: "sh",
+		Args:    []string{"-c", "echo 'loading config' >&2; printf 'missing API key' >&2; exit 3"},
+	})
+	if err == nil {
+		t.Fatal("Expected starting an exiting server to fail")
+	}
+
+	// Events are delivered concurrently, so their order may differ
+	received := map[string]bool{}
+	for len(received) < 2 {
+		select {
+		case event := <-lines:
+			if event.Server != "broken" {
+				t.Errorf("Expected the event of server broken, got %+v", event)
+			}
+			received[event.Line] = true
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected an event for each line, got %v", received)
+		}
+	}
+	if !received["loading config"] || !received["missing API key"] {
+		t.Errorf("Expected an event for each line, got %v", received)
+	}
+
+	// The last line is captured even without a trailing newline
+	for _, want := range []string{`level=WARN msg="loading config" server=broken`, `level=WARN msg="missing API key" server=broken`} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("Expected the log to contain %q, got %q", want, logs.String())
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "broken.stderr.log"))
+	if err != nil {
+		t.Fatalf("Expected the stderr file to be written: %v", err)
+	}
+	if string(data) != "loading config\nmissing API key" {
+		t.Errorf("Unexpected stderr file content %q", data)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "server.stderr.log")
+	file, err := openRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("openRotatingFile failed: %v", err)
+	}
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := file.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Each write would take the file past 10 bytes, so each starts a new file and
+	// only two backups are kept
+	want := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(name)
+		if err != nil || string(data) != content {
+			t.Errorf("Expected %s to hold %q, got %q (%v)", filepath.Base(name), content, data, err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected the oldest backup to be dropped, got %v", err)
+	}
+}
@@ -0,0 +1,271 @@
+package client
+
+import (
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+)
+
+// defaultWatchdogGrace is how long a hung server's process group has to exit
+// after SIGTERM before it is sent SIGKILL.
+const defaultWatchdogGrace = 5 * time.Second
+
+// watchdogState holds the watchdog configuration of a registry.
+type watchdogState struct {
+	timeout time.Duration
+	grace   time.Duration
+	subject *events.Subject
+	once    sync.Once
+}
+
+// WithWatchdog kills the servers that stop answering. Every timeout the registry
+// pings each server it launched; a server that does not answer within timeout is
+// removed from the registry and its process group is sent SIGTERM, then SIGKILL
+// if it is still running after the grace period (see WithWatchdogGrace). The
+// server process is reaped, and descendants that left its process group and
+// outlive it are reported as orphans.
+func WithWatchdog(timeout time.Duration) ServerRegistryOption {
+	return func(r *ServerRegistry) {
+		r.watchdog.timeout = timeout
+	}
+}
+
+// WithWatchdogGrace sets how long the process group of a hung server has to exit
+// after SIGTERM before the watchdog sends SIGKILL. It defaults to five seconds.
+func WithWatchdogGrace(grace time.Duration) ServerRegistryOption {
+	return func(r *ServerRegistry) {
+		if grace > 0 {
+			r.watchdog.grace = grace
+		}
+	}
+}
+
+// WithWatchdogEvents publishes an events.ServerHungEvent on events.TopicServerHung
+// to subject for each server the watchdog kills, and an events.ServerOrphanedEvent
+// on events.TopicServerOrphaned when some of its descendants survive it.
+func WithWatchdogEvents(subject *events.Subject) ServerRegistryOption {
+	return func(r *ServerRegistry) {
+		r.watchdog.subject = subject
+	}
+}
+
+// startWatchdog launches the background watchdog loop if it is enabled.
+// The loop stops when the registry is closed.
+func (r *ServerRegistry) startWatchdog() {
+	if r.watchdog.timeout <= 0 {
+		return
+	}
+
+	r.watchdog.once.Do(func() {
+		go func() {
+			ticker := time.NewTicker(r.watchdog.timeout)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-r.ctx.Done():
+					return
+				case <-ticker.C:
+					r.checkHung()
+				}
+			}
+		}()
+	})
+}
+
+// checkHung pings every launched server concurrently and kills those that do not
+// answer in time. Servers failing otherwise, such as after exiting, are left to
+// the health checks.
+func (r *ServerRegistry) checkHung() {
+	r.mu.RLock()
+	servers := make([]*MCPServer, 0, len(r.servers))
+	for _, server := range r.servers {
+		if server.Client != nil && server.cmd != nil && server.cmd.Process != nil {
+			servers = append(servers, server)
+		}
+	}
+	r.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server *MCPServer) {
+			defer wg.Done()
+			if err := pingWithin(server, r.watchdog.timeout); errors.Is(err, errPingTimeout) {
+				r.killHung(server, err)
+			}
+		}(server)
+	}
+	wg.Wait()
+}
+
+// killHung removes a hung server from the registry and kills its process group,
+// escalating from SIGTERM to SIGKILL, then reports its orphaned descendants
+func (r *ServerRegistry) killHung(server *MCPServer, cause error) {
+	r.mu.Lock()
+	if r.servers[server.Name] != server {
+		// Stopped or replaced while it was being pinged
+		r.mu.Unlock()
+		return
+	}
+	delete(r.servers, server.Name)
+	r.mu.Unlock()
+	r.forgetHealth(server.Name)
+
+	cmd := server.cmd
+	pid := cmd.Process.Pid
+	if r.logger != nil {
+		r.logger.Warn("Server hung, killing its process group", "server", server.Name, "pid", pid, "error", cause)
+	}
+
+	// Descendants are listed before the kill, as they are reparented once the
+	// server process is gone
+	descendants := r.findChildProcesses(pid)
+
+	r.processMutex.Lock()
+	exit := r.exits[cmd]
+	delete(r.exits, cmd)
+	r.processMutex.Unlock()
+
+	// Signal the whole group when the server leads one, as it does when launched
+	// by the registry
+	target := pid
+	if pgid, err := syscall.Getpgid(pid); err == nil && pgid == pid {
+		target = -pgid
+	}
+
+	grace := r.watchdog.grace
+	if grace <= 0 {
+		grace = defaultWatchdogGrace
+	}
+	signal := syscall.SIGTERM
+	syscall.Kill(target, syscall.SIGTERM)
+	if !waitGone(exit, target, grace) {
+		signal = syscall.SIGKILL
+		syscall.Kill(target, syscall.SIGKILL)
+		// The exit watcher's Wait reaps the server process, so it leaves no zombie
+		if !waitGone(exit, target, grace) && r.logger != nil {
+			r.logger.Error("Hung server did not exit after SIGKILL", "server", server.Name, "pid", pid)
+		}
+	}
+
+	if err := server.Client.Close(); err != nil && r.logger != nil {
+		r.logger.Debug("Failed to close client of hung server", "server", server.Name, "error", err)
+	}
+	if r.enableProcessTracking {
+		r.untrackProcess(pid)
+	}
+
+	publishWatchdogEvent(r, server.Name, events.TopicServerHung, events.ServerHungEvent{
+		Server:     server.Name,
+		PID:        pid,
+		Error:      cause.Error(),
+		Signal:     signalName(signal),
+		DetectedAt: time.Now(),
+	})
+
+	// Whatever survived the group kill escaped the process group, such as
+	// daemons that called setsid
+	var orphans []int
+	if live, err := liveProcesses(); err == nil {
+		for _, descendant := range descendants {
+			if _, alive := live[descendant]; alive {
+				orphans = append(orphans, descendant)
+			}
+		}
+	}
+	if len(orphans) == 0 {
+		return
+	}
+	if r.logger != nil {
+		r.logger.Warn("Hung server left orphaned processes", "server", server.Name, "pid", pid, "orphans", orphans)
+	}
+	publishWatchdogEvent(r, server.Name, events.TopicServerOrphaned, events.ServerOrphanedEvent{
+		Server:     server.Name,
+		PID:        pid,
+		Orphans:    orphans,
+		DetectedAt: time.Now(),
+	})
+}
+
+// publishWatchdogEvent publishes a watchdog event if the registry has a subject
+// for them
+func publishWatchdogEvent[T any](r *ServerRegistry, name, topic string, event T) {
+	if r.watchdog.subject == nil {
+		return
+	}
+	if err := events.Publish[T](r.watchdog.subject, topic, event); err != nil && r.logger != nil {
+		r.logger.Warn("failed to publish watchdog event", "server", name, "topic", topic, "error", err)
+	}
+}
+
+// waitGone waits up to grace for a server process to be reaped and the rest of
+// its process group to exit
+func waitGone(exit *processExit, target int, grace time.Duration) bool {
+	deadline := time.Now().Add(grace)
+	if exit != nil && !exit.exited(grace) {
+		return false
+	}
+	for groupAlive(target) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return true
+}
+
+// groupAlive reports whether a process, or a process group given as a negative
+// PID, still has running members. Zombies whose parent has not reaped them yet
+// are not counted.
+func groupAlive(target int) bool {
+	if syscall.Kill(target, syscall.Signal(0)) != nil {
+		return false
+	}
+	live, err := liveProcesses()
+	if err != nil {
+		return true
+	}
+	for pid, pgid := range live {
+		if pid == target || pgid == -target {
+			return true
+		}
+	}
+	return false
+}
+
+// liveProcesses returns the process group of every process that is running,
+// that is neither exited nor a zombie, by PID
+func liveProcesses() (map[int]int, error) {
+	output, err := exec.Command("ps", "-eo", "pid=,pgid=,stat=").Output()
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[int]int)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || strings.HasPrefix(fields[2], "Z") {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		pgid, err2 := strconv.Atoi(fields[1])
+		if err1 == nil && err2 == nil {
+			live[pid] = pgid
+		}
+	}
+	return live, nil
+}
+
+// signalName names the signals the watchdog sends
+func signalName(signal syscall.Signal) string {
+	if signal == syscall.SIGKILL {
+		return "SIGKILL"
+	}
+	return "SIGTERM"
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+)
+
+// hungClient is a Client whose Ping never returns.
+type hungClient struct {
+	Client
+}
+
+func (c *hungClient) Ping() error {
+	select {}
+}
+
+func (c *hungClient) Close() error {
+	return nil
+}
+
+func TestServerRegistry_WatchdogKillsHungServer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires process groups")
+	}
+	if _, err := exec.LookPath("setsid"); err != nil {
+		t.Skip("requires setsid")
+	}
+
+	subject := events.NewSubject()
+	hung := make(chan events.ServerHungEvent, 1)
+	orphaned := make(chan events.ServerOrphanedEvent, 1)
+	events.Subscribe[events.ServerHungEvent](subject, events.TopicServerHung,
+		func(ctx context.Context, event events.ServerHungEvent) error {
+			hung <- event
+			return nil
+		})
+	events.Subscribe[events.ServerOrphanedEvent](subject, events.TopicServerOrphaned,
+		func(ctx context.Context, event events.ServerOrphanedEvent) error {
+			orphaned <- event
+			return nil
+		})
+
+	registry := NewServerRegistry(
+		WithWatchdogGrace(200*time.Millisecond),
+		WithWatchdogEvents(subject),
+	)
+	defer registry.Close()
+
+	// The server ignores SIGTERM, as does the child in its group, while the setsid
+	// child leaves the group
+	cmd := exec.Command("sh", "-c", `trap "" TERM; sleep 30 & setsid sleep 30 & wait`)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	exit := watchProcess(cmd, nil)
+	registry.processMutex.Lock()
+	registry.exits[cmd] = exit
+	registry.processMutex.Unlock()
+	registry.mu.Lock()
+	registry.servers["hung"] = &MCPServer{Name: "hung", Client: &hungClient{}, cmd: cmd}
+	registry.mu.Unlock()
+	time.Sleep(200 * time.Millisecond)
+
+	registry.watchdog.timeout = 50 * time.Millisecond
+	registry.checkHung()
+
+	if _, exists := registry.Health()["hung"]; exists {
+		t.Error("Expected the hung server to be removed from the registry")
+	}
+	select {
+	case <-exit.done:
+	default:
+		t.Error("Expected the hung server to be reaped")
+	}
+	if groupAlive(-cmd.Process.Pid) {
+		t.Error("Expected the server's process group to be killed")
+	}
+
+	select {
+	case event := <-hung:
+		if event.Server != "hung" || event.PID != cmd.Process.Pid || event.Signal != "SIGKILL" {
+			t.Errorf("Unexpected hung event: %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a hung event")
+	}
+
+	select {
+	case event := <-orphaned:
+		if len(event.Orphans) != 1 {
+			t.Fatalf("Expected the setsid child as the only orphan, got %v", event.Orphans)
+		}
+		syscall.Kill(event.Orphans[0], syscall.SIGKILL)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected an orphaned event")
+	}
+}
+
+func TestServerRegistry_WatchdogSparesAnsweringServers(t *testing.T) {
+	registry := NewServerRegistry()
+	defer registry.Close()
+
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skip("requires sleep")
+	}
+	defer cmd.Process.Kill()
+
+	registry.mu.Lock()
+	registry.servers["fine"] = &MCPServer{Name: "fine", Client: &pingClient{}, cmd: cmd}
+	registry.mu.Unlock()
+
+	registry.watchdog.timeout = 50 * time.Millisecond
+	registry.checkHung()
+	if !registry.IsHealthy("fine") {
+		t.Error("Expected a server answering pings to be kept")
+	}
+}
@@ -4,6 +4,7 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,6 +15,7 @@ import (
 	"time"
 
 	"github.com/localrivet/gomcp/mcp"
+	"github.com/localrivet/gomcp/transport"
 	"github.com/localrivet/gomcp/transport/sse"
 )
 
@@ -22,13 +24,14 @@ type SSETransport struct {
 	transport           *sse.Transport
 	requestTimeout      time.Duration
 	connectionTimeout   time.Duration
-	notificationHandler func(method string, params []byte)
+	notificationHandler atomic.Pointer[func(method string, params []byte)]
 	respChan            chan []byte // channel for receiving responses
 	respErr             chan error  // channel for receiving errors
 	connected           atomic.Bool
 	postEndpoint        atomic.Pointer[string] // endpoint for sending messages (received from server)
 	debugEnabled        bool
 	logger              *slog.Logger
+	tlsConfig           *tls.Config
 }
 
 // NewSSETransport creates a new SSE transport adapter.
@@ -113,16 +116,19 @@ func (t *SSETransport) handleMessage(message []byte) ([]byte, error) {
 		}
 	}
 
-	// Forward to notification handler if it's a notification
-	if t.notificationHandler != nil {
+	// Forward to notification handler if it's a notification or a server request
+	if handler := t.notificationHandler.Load(); handler != nil {
 		// Try to determine if this is a JSON-RPC notification vs a response
 		var msg struct {
-			ID interface{} `json:"id"`
+			ID     interface{} `json:"id"`
+			Method string      `json:"method"`
 		}
-		if err := json.Unmarshal(message, &msg); err == nil && msg.ID == nil {
-			// No ID means it's a notification
-			t.logger.Debug("Detected notification (no ID), forwarding to handler")
-			go t.notificationHandler("", message)
+		if err := json.Unmarshal(message, &msg); err == nil && (msg.ID == nil || msg.Method != "") {
+			// No ID means it's a notification, and a method with an ID a request from the
+			// server, such as roots/list; they are passed in the order the stream
+			// delivers them
+			t.logger.Debug("Detected notification or server request, forwarding to handler", "method", msg.Method)
+			(*handler)("", message)
 			return nil, nil
 		}
 	}
@@ -149,9 +155,9 @@ func (t *SSETransport) handleEndpointMessage(message []byte) {
 	t.postEndpoint.Store(&endpointURL)
 
 	// Notify that the endpoint has been received
-	if t.notificationHandler != nil {
+	if handler := t.notificationHandler.Load(); handler != nil {
 		t.logger.Debug("Calling notification handler with endpoint")
-		t.notificationHandler("endpoint", message)
+		(*handler)("endpoint", message)
 	} else {
 		t.logger.Debug("No notification handler registered")
 	}
@@ -328,11 +334,18 @@ func (t *SSETransport) SendWithContext(ctx context.Context, message []byte) ([]b
 	// Set appropriate headers
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
+	// Name the stream the server should send this session's messages on
+	if streamID := t.transport.StreamID(); streamID != "" {
+		req.Header.Set(sse.StreamIDHeader, streamID)
+	}
 
 	// Create a client with appropriate timeout
 	client := &http.Client{
 		Timeout: t.requestTimeout,
 	}
+	if t.tlsConfig != nil {
+		client = transport.HTTPClientWithTLS(client, t.tlsConfig)
+	}
 
 	t.logger.Debug("Sending HTTP POST to", "endpoint", postEndpoint)
 
@@ -392,12 +405,24 @@ func (t *SSETransport) SetConnectionTimeout(timeout time.Duration) {
 
 // RegisterNotificationHandler registers a handler for server-initiated messages.
 func (t *SSETransport) RegisterNotificationHandler(handler func(method string, params []byte)) {
-	t.notificationHandler = handler
+	t.notificationHandler.Store(&handler)
 	if t.debugEnabled {
 		t.logger.Debug("Notification handler registered")
 	}
 }
 
+// SetTLSConfig sets the TLS configuration for both the event stream and message requests
+func (t *SSETransport) SetTLSConfig(config *tls.Config) {
+	t.tlsConfig = config
+	sse.SSE.WithTLSConfig(config)(t.transport)
+}
+
+// SetHeartbeatTimeout makes the event stream reconnect when it receives nothing,
+// not even a keepalive comment, for the given time
+func (t *SSETransport) SetHeartbeatTimeout(timeout time.Duration) {
+	sse.SSE.WithHeartbeatTimeout(timeout)(t.transport)
+}
+
 // SetDebugEnabled enables or disables debug logging
 func (t *SSETransport) SetDebugEnabled(enabled bool) {
 	t.debugEnabled = enabled
@@ -442,3 +467,27 @@ func WithSSE(url string) Option {
 		}
 	}
 }
+
+// WithSSETLSConfig configures the TLS settings of the SSE transport, for example to
+// trust a private CA or to present a client certificate to a server requiring
+// mutual TLS. transport.LoadClientTLSConfig builds one from PEM files.
+// This option must be used after WithSSE.
+func WithSSETLSConfig(config *tls.Config) Option {
+	return func(c *clientImpl) {
+		if transport, ok := c.transport.(*SSETransport); ok {
+			transport.SetTLSConfig(config)
+		}
+	}
+}
+
+// WithSSEHeartbeatTimeout makes the SSE transport reconnect its event stream when
+// the server sends nothing, not even a keepalive comment, for the given time. Use a
+// few multiples of the server's keepalive interval, 15 seconds for gomcp servers.
+// This option must be used after WithSSE.
+func WithSSEHeartbeatTimeout(timeout time.Duration) Option {
+	return func(c *clientImpl) {
+		if transport, ok := c.transport.(*SSETransport); ok {
+			transport.SetHeartbeatTimeout(timeout)
+		}
+	}
+}
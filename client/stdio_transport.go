@@ -12,13 +12,11 @@ import (
 
 // StdioTransport adapts the stdio transport to the client Transport interface.
 type StdioTransport struct {
-	transport           *stdio.Transport
-	requestTimeout      time.Duration
-	connectionTimeout   time.Duration
-	notificationHandler func(method string, params []byte)
-	mu                  sync.Mutex
-	respChan            chan []byte // channel for receiving responses
-	respErr             chan error  // channel for receiving errors
+	transport         *stdio.Transport
+	requestTimeout    time.Duration
+	connectionTimeout time.Duration
+	responses         *responseRouter
+	mu                sync.Mutex // serialises writes
 }
 
 // NewStdioTransport creates a new stdio transport adapter.
@@ -27,8 +25,7 @@ func NewStdioTransport() *StdioTransport {
 		transport:         stdio.NewTransport(),
 		requestTimeout:    30 * time.Second,
 		connectionTimeout: 10 * time.Second,
-		respChan:          make(chan []byte, 1),
-		respErr:           make(chan error, 1),
+		responses:         newResponseRouter(),
 	}
 
 	// Set message handler to capture responses
@@ -43,8 +40,7 @@ func NewStdioTransportWithIO(in io.Reader, out io.Writer) *StdioTransport {
 		transport:         stdio.NewTransportWithIO(in, out),
 		requestTimeout:    30 * time.Second,
 		connectionTimeout: 10 * time.Second,
-		respChan:          make(chan []byte, 1),
-		respErr:           make(chan error, 1),
+		responses:         newResponseRouter(),
 	}
 
 	// Set message handler to capture responses
@@ -53,23 +49,9 @@ func NewStdioTransportWithIO(in io.Reader, out io.Writer) *StdioTransport {
 	return t
 }
 
-// handleMessage processes incoming messages and routes them accordingly
+// handleMessage routes incoming messages to the requests waiting for them
 func (t *StdioTransport) handleMessage(message []byte) ([]byte, error) {
-	// In a real implementation, we'd parse the message to determine if it's a notification
-	// or a response to a previous request
-
-	// For now, we'll assume it's a response to the most recent request
-	select {
-	case t.respChan <- message:
-		// Message successfully sent to response channel
-	default:
-		// Channel is full or closed, possibly no request is waiting for a response
-		// This could be a notification
-		if t.notificationHandler != nil {
-			// Parse notification (method and params) in a real implementation
-			go t.notificationHandler("", message)
-		}
-	}
+	t.responses.deliver(message)
 
 	// Return nil to prevent the StdIO transport from automatically responding
 	return nil, nil
@@ -108,25 +90,14 @@ func (t *StdioTransport) Send(message []byte) ([]byte, error) {
 
 // SendWithContext sends a message with context for timeout/cancellation.
 func (t *StdioTransport) SendWithContext(ctx context.Context, message []byte) ([]byte, error) {
-	t.mu.Lock()
-	defer t.mu.Unlock()
-
-	// Send the message
-	if err := t.transport.Send(message); err != nil {
-		return nil, err
-	}
-
-	// Wait for response or timeout
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case err := <-t.respErr:
-		return nil, err
-	case response := <-t.respChan:
-		return response, nil
-	case <-time.After(t.requestTimeout):
-		return nil, context.DeadlineExceeded
-	}
+	ctx, cancel := context.WithTimeout(ctx, t.requestTimeout)
+	defer cancel()
+
+	return t.responses.send(ctx, message, func(message []byte) error {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return t.transport.Send(message)
+	})
 }
 
 // SetRequestTimeout sets the default timeout for request operations.
@@ -141,5 +112,5 @@ func (t *StdioTransport) SetConnectionTimeout(timeout time.Duration) {
 
 // RegisterNotificationHandler registers a handler for server-initiated messages.
 func (t *StdioTransport) RegisterNotificationHandler(handler func(method string, params []byte)) {
-	t.notificationHandler = handler
+	t.responses.setNotificationHandler(handler)
 }
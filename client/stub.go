@@ -0,0 +1,492 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StubCatalog describes the server a stub client stands in for: what it offers,
+// as exported by "gomcp schema", and how it answers. See NewStub.
+type StubCatalog struct {
+	ServerInfo   ServerInfo          `json:"serverInfo"`
+	Instructions string              `json:"instructions,omitempty"`
+	Capabilities *ServerCapabilities `json:"capabilities,omitempty"`
+
+	Tools             []StubTool             `json:"tools,omitempty"`
+	Resources         []StubResource         `json:"resources,omitempty"`
+	ResourceTemplates []StubResourceTemplate `json:"resourceTemplates,omitempty"`
+	Prompts           []StubPrompt           `json:"prompts,omitempty"`
+
+	// ToolHandlers script tools in Go, answering their calls instead of the
+	// responses of the catalog
+	ToolHandlers map[string]func(args map[string]interface{}) (interface{}, error) `json:"-"`
+}
+
+// StubTool is a tool of a stub catalog and its canned responses.
+type StubTool struct {
+	Tool
+	Responses []StubResponse `json:"responses,omitempty"`
+}
+
+// StubResource is a resource of a stub catalog and its canned responses.
+type StubResource struct {
+	Resource
+	Responses []StubResponse `json:"responses,omitempty"`
+}
+
+// StubResourceTemplate is a resource template of a stub catalog and its canned
+// responses, which match the parameters of the URI read.
+type StubResourceTemplate struct {
+	ResourceTemplate
+	Responses []StubResponse `json:"responses,omitempty"`
+}
+
+// StubPrompt is a prompt of a stub catalog and its canned responses.
+type StubPrompt struct {
+	Prompt
+	Responses []StubResponse `json:"responses,omitempty"`
+}
+
+// StubResponse is a canned answer of a stub. A request is answered by the first
+// response whose Match it satisfies and which has not been used up.
+type StubResponse struct {
+	// Match holds the arguments, or resource template parameters, the response
+	// answers. A response without Match answers any request.
+	Match map[string]interface{} `json:"match,omitempty"`
+
+	// Times is how many requests the response answers before the next matching
+	// response takes over, so that responses can be scripted in sequence. Zero
+	// means any number.
+	Times int `json:"times,omitempty"`
+
+	// Text answers with a single text item. {{name}} is replaced with the
+	// argument of that name.
+	Text string `json:"text,omitempty"`
+
+	// Result answers with a result exactly as a server would send it.
+	Result interface{} `json:"result,omitempty"`
+
+	// Error fails the request with this message. Tool calls fail with an error
+	// result, as servers report tool errors.
+	Error string `json:"error,omitempty"`
+}
+
+// stubError is a JSON-RPC error answered by a stub
+type stubError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *stubError) Error() string { return e.Message }
+
+// LoadStubCatalog reads a stub catalog from a JSON file.
+func LoadStubCatalog(path string) (*StubCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stub catalog: %w", err)
+	}
+	var catalog StubCatalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse stub catalog %s: %w", path, err)
+	}
+	return &catalog, nil
+}
+
+// NewStub returns a client answering from the stub catalog in a JSON file rather
+// than from a server, to build and test against servers that are not available
+// yet. The catalog lists the server's tools, resources and prompts, with canned
+// responses to their calls:
+//
+//	{
+//	  "serverInfo": {"name": "weather", "version": "0.1.0"},
+//	  "tools": [{
+//	    "name": "forecast",
+//	    "inputSchema": {"type": "object", "properties": {"city": {"type": "string"}}},
+//	    "responses": [
+//	      {"match": {"city": "Atlantis"}, "error": "unknown city"},
+//	      {"text": "Sunny in {{city}}"}
+//	    ]
+//	  }]
+//	}
+//
+// The catalog exported by "gomcp schema" is a valid stub catalog without
+// responses. The options are those of NewClient.
+func NewStub(catalogPath string, options ...Option) (Client, error) {
+	catalog, err := LoadStubCatalog(catalogPath)
+	if err != nil {
+		return nil, err
+	}
+	return NewStubFromCatalog(catalog, options...)
+}
+
+// NewStubFromCatalog returns a client answering from a stub catalog. See NewStub.
+func NewStubFromCatalog(catalog *StubCatalog, options ...Option) (Client, error) {
+	if catalog == nil {
+		return nil, errors.New("stub catalog is required")
+	}
+	transport := &stubTransport{catalog: catalog, used: make(map[*StubResponse]int)}
+	return NewClient("stub://"+catalog.ServerInfo.Name, append(options, WithTransport(transport))...)
+}
+
+// stubTransport implements the Transport interface by answering requests from a
+// stub catalog
+type stubTransport struct {
+	catalog *StubCatalog
+
+	// used counts the requests each response has answered
+	used map[*StubResponse]int
+	mu   sync.Mutex
+}
+
+func (t *stubTransport) Connect() error                                   { return nil }
+func (t *stubTransport) ConnectWithContext(ctx context.Context) error     { return nil }
+func (t *stubTransport) Disconnect() error                                { return nil }
+func (t *stubTransport) SetRequestTimeout(timeout time.Duration)          {}
+func (t *stubTransport) SetConnectionTimeout(timeout time.Duration)       {}
+func (t *stubTransport) RegisterNotificationHandler(func(string, []byte)) {}
+
+func (t *stubTransport) Send(message []byte) ([]byte, error) {
+	return t.SendWithContext(context.Background(), message)
+}
+
+func (t *stubTransport) SendWithContext(ctx context.Context, message []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(message, &batch); err == nil {
+		responses := make([]json.RawMessage, 0, len(batch))
+		for _, request := range batch {
+			if response := t.answer(request); response != nil {
+				responses = append(responses, response)
+			}
+		}
+		return json.Marshal(responses)
+	}
+	return t.answer(message), nil
+}
+
+// answer returns the response to a request, or nil for a notification
+func (t *stubTransport) answer(message []byte) json.RawMessage {
+	var request struct {
+		ID     interface{}            `json:"id"`
+		Method string                 `json:"method"`
+		Params map[string]interface{} `json:"params"`
+	}
+	response := map[string]interface{}{"jsonrpc": "2.0"}
+	if err := json.Unmarshal(message, &request); err != nil {
+		response["id"] = nil
+		response["error"] = &stubError{Code: -32700, Message: "parse error"}
+	} else if request.ID == nil {
+		return nil
+	} else {
+		response["id"] = request.ID
+		result, err := t.handle(request.Method, request.Params)
+		if err != nil {
+			stubErr, ok := err.(*stubError)
+			if !ok {
+				stubErr = &stubError{Code: -32603, Message: err.Error()}
+			}
+			response["error"] = stubErr
+		} else {
+			response["result"] = result
+		}
+	}
+
+	data, _ := json.Marshal(response)
+	return data
+}
+
+// handle answers a request from the catalog
+func (t *stubTransport) handle(method string, params map[string]interface{}) (interface{}, error) {
+	c := t.catalog
+	switch method {
+	case "initialize":
+		result := map[string]interface{}{
+			"protocolVersion": params["protocolVersion"],
+			"capabilities":    t.capabilities(),
+			"serverInfo":      c.ServerInfo,
+		}
+		if c.Instructions != "" {
+			result["instructions"] = c.Instructions
+		}
+		return result, nil
+
+	case "ping", "logging/setLevel", "resources/subscribe", "resources/unsubscribe":
+		return map[string]interface{}{}, nil
+
+	case "tools/list":
+		tools := make([]Tool, 0, len(c.Tools))
+		for _, tool := range c.Tools {
+			tools = append(tools, tool.Tool)
+		}
+		return map[string]interface{}{"tools": tools}, nil
+
+	case "resources/list":
+		resources := make([]Resource, 0, len(c.Resources))
+		for _, resource := range c.Resources {
+			resources = append(resources, resource.Resource)
+		}
+		return map[string]interface{}{"resources": resources}, nil
+
+	case "resources/templates/list":
+		templates := make([]ResourceTemplate, 0, len(c.ResourceTemplates))
+		for _, template := range c.ResourceTemplates {
+			templates = append(templates, template.ResourceTemplate)
+		}
+		return map[string]interface{}{"resourceTemplates": templates}, nil
+
+	case "prompts/list":
+		prompts := make([]Prompt, 0, len(c.Prompts))
+		for _, prompt := range c.Prompts {
+			prompts = append(prompts, prompt.Prompt)
+		}
+		return map[string]interface{}{"prompts": prompts}, nil
+
+	case "tools/call":
+		name, _ := params["name"].(string)
+		args, _ := params["arguments"].(map[string]interface{})
+		return t.callTool(name, args)
+
+	case "resources/read":
+		uri, _ := params["uri"].(string)
+		return t.readResource(uri)
+
+	case "prompts/get":
+		name, _ := params["name"].(string)
+		args, _ := params["arguments"].(map[string]interface{})
+		return t.getPrompt(name, args)
+	}
+	return nil, &stubError{Code: -32601, Message: "method not found: " + method}
+}
+
+// capabilities returns the catalog's capabilities, or those its lists imply
+func (t *stubTransport) capabilities() *ServerCapabilities {
+	c := t.catalog
+	if c.Capabilities != nil {
+		return c.Capabilities
+	}
+	capabilities := &ServerCapabilities{}
+	if len(c.Tools) > 0 {
+		capabilities.Tools = &ToolsCapability{}
+	}
+	if len(c.Resources) > 0 || len(c.ResourceTemplates) > 0 {
+		capabilities.Resources = &ResourcesCapability{}
+	}
+	if len(c.Prompts) > 0 {
+		capabilities.Prompts = &PromptsCapability{}
+	}
+	return capabilities
+}
+
+func (t *stubTransport) callTool(name string, args map[string]interface{}) (interface{}, error) {
+	if handler, ok := t.catalog.ToolHandlers[name]; ok {
+		result, err := handler(args)
+		if err != nil {
+			return toolErrorResult(err.Error()), nil
+		}
+		return toolResult(result), nil
+	}
+
+	for i := range t.catalog.Tools {
+		tool := &t.catalog.Tools[i]
+		if tool.Name != name {
+			continue
+		}
+		response, err := t.respond(tool.Responses, args, "tool "+name)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case response.Error != "":
+			return toolErrorResult(expandStubText(response.Error, args)), nil
+		case response.Result != nil:
+			return toolResult(response.Result), nil
+		default:
+			return toolResult(expandStubText(response.Text, args)), nil
+		}
+	}
+	return nil, &stubError{Code: -32602, Message: "tool not found: " + name}
+}
+
+func (t *stubTransport) readResource(uri string) (interface{}, error) {
+	var responses []StubResponse
+	var params map[string]interface{}
+	mimeType := ""
+	found := false
+	for i := range t.catalog.Resources {
+		if resource := &t.catalog.Resources[i]; resource.URI == uri {
+			responses, mimeType, found = resource.Responses, resource.MimeType, true
+			break
+		}
+	}
+	for i := 0; !found && i < len(t.catalog.ResourceTemplates); i++ {
+		template := &t.catalog.ResourceTemplates[i]
+		if matched, ok := matchStubTemplate(template.URITemplate, uri); ok {
+			responses, params, mimeType, found = template.Responses, matched, template.MimeType, true
+		}
+	}
+	if !found {
+		return nil, &stubError{Code: -32602, Message: "resource not found: " + uri}
+	}
+
+	response, err := t.respond(responses, params, "resource "+uri)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case response.Error != "":
+		return nil, &stubError{Code: -32603, Message: expandStubText(response.Error, params)}
+	case response.Result != nil:
+		if text, ok := response.Result.(string); ok {
+			return textResourceResult(uri, mimeType, text), nil
+		}
+		return response.Result, nil
+	default:
+		return textResourceResult(uri, mimeType, expandStubText(response.Text, params)), nil
+	}
+}
+
+func (t *stubTransport) getPrompt(name string, args map[string]interface{}) (interface{}, error) {
+	for i := range t.catalog.Prompts {
+		prompt := &t.catalog.Prompts[i]
+		if prompt.Name != name {
+			continue
+		}
+		response, err := t.respond(prompt.Responses, args, "prompt "+name)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case response.Error != "":
+			return nil, &stubError{Code: -32603, Message: expandStubText(response.Error, args)}
+		case response.Result != nil:
+			return response.Result, nil
+		default:
+			return map[string]interface{}{
+				"description": prompt.Description,
+				"messages": []interface{}{map[string]interface{}{
+					"role":    "user",
+					"content": map[string]interface{}{"type": "text", "text": expandStubText(response.Text, args)},
+				}},
+			}, nil
+		}
+	}
+	return nil, &stubError{Code: -32602, Message: "prompt not found: " + name}
+}
+
+// respond picks the response answering a request with the given arguments
+func (t *stubTransport) respond(responses []StubResponse, args map[string]interface{}, what string) (*StubResponse, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range responses {
+		response := &responses[i]
+		if !matchesStubArgs(response.Match, args) {
+			continue
+		}
+		if response.Times > 0 && t.used[response] >= response.Times {
+			continue
+		}
+		t.used[response]++
+		return response, nil
+	}
+	return nil, &stubError{Code: -32603, Message: "no stub response matches the request for " + what}
+}
+
+// matchesStubArgs reports whether args hold every value of match
+func matchesStubArgs(match, args map[string]interface{}) bool {
+	for name, want := range match {
+		got, ok := args[name]
+		if !ok || !reflect.DeepEqual(got, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// stubTemplateParam matches the {name} and {name*} parameters of a URI template
+var stubTemplateParam = regexp.MustCompile(`\{(\w+)(\*?)\}`)
+
+// matchStubTemplate matches a URI against a template, returning its parameters
+func matchStubTemplate(template, uri string) (map[string]interface{}, bool) {
+	var names []string
+	pattern := "^"
+	last := 0
+	for _, loc := range stubTemplateParam.FindAllStringSubmatchIndex(template, -1) {
+		pattern += regexp.QuoteMeta(template[last:loc[0]])
+		names = append(names, template[loc[2]:loc[3]])
+		if loc[5] > loc[4] {
+			pattern += "(.+)"
+		} else {
+			pattern += "([^/]+)"
+		}
+		last = loc[1]
+	}
+	pattern += regexp.QuoteMeta(template[last:]) + "$"
+
+	matches := regexp.MustCompile(pattern).FindStringSubmatch(uri)
+	if matches == nil {
+		return nil, false
+	}
+	params := make(map[string]interface{}, len(names))
+	for i, name := range names {
+		params[name] = matches[i+1]
+	}
+	return params, true
+}
+
+// expandStubText replaces the {{name}} references of a canned text
+func expandStubText(text string, args map[string]interface{}) string {
+	for name, value := range args {
+		text = strings.ReplaceAll(text, "{{"+name+"}}", fmt.Sprint(value))
+	}
+	return text
+}
+
+// toolResult wraps a canned value in a tool result unless it already is one
+func toolResult(value interface{}) interface{} {
+	if result, ok := value.(map[string]interface{}); ok {
+		if _, hasContent := result["content"]; hasContent {
+			return result
+		}
+	}
+	text, ok := value.(string)
+	if !ok {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return toolErrorResult(err.Error())
+		}
+		text = string(data)
+	}
+	return map[string]interface{}{
+		"content": []interface{}{map[string]interface{}{"type": "text", "text": text}},
+	}
+}
+
+// toolErrorResult is a tool result reporting an error
+func toolErrorResult(message string) map[string]interface{} {
+	return map[string]interface{}{
+		"content": []interface{}{map[string]interface{}{"type": "text", "text": message}},
+		"isError": true,
+	}
+}
+
+// textResourceResult is the result of reading a text resource
+func textResourceResult(uri, mimeType, text string) map[string]interface{} {
+	if mimeType == "" {
+		mimeType = "text/plain"
+	}
+	return map[string]interface{}{
+		"contents": []interface{}{map[string]interface{}{"uri": uri, "mimeType": mimeType, "text": text}},
+	}
+}
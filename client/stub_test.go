@@ -0,0 +1,145 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const weatherStub = `{
+  "serverInfo": {"name": "weather", "version": "0.1.0"},
+  "instructions": "Ask for forecasts by city",
+  "tools": [{
+    "name": "forecast",
+    "inputSchema": {"type": "object", "properties": {"city": {"type": "string"}}},
+    "responses": [
+      {"match": {"city": "Atlantis"}, "error": "unknown city {{city}}"},
+      {"match": {"city": "Paris"}, "times": 1, "text": "Computing"},
+      {"text": "Sunny in {{city}}"}
+    ]
+  }],
+  "resources": [{"uri": "/stations", "mimeType": "application/json", "responses": [{"text": "[\"LFPG\"]"}]}],
+  "resourceTemplates": [{"uriTemplate": "/stations/{id}", "responses": [{"text": "Station {{id}}"}]}],
+  "prompts": [{"name": "summary", "responses": [{"text": "Summarize the weather in {{city}}"}]}]
+}`
+
+func newTestStub(t *testing.T, content string) Client {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "weather.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write catalog: %v", err)
+	}
+	c, err := NewStub(path, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatalf("NewStub failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// resultText returns the text of a tool result and whether it is an error
+func resultText(t *testing.T, result interface{}) (string, bool) {
+	t.Helper()
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a result map, got %T", result)
+	}
+	content := resultMap["content"].([]interface{})
+	isError, _ := resultMap["isError"].(bool)
+	return content[0].(map[string]interface{})["text"].(string), isError
+}
+
+func TestStub(t *testing.T) {
+	c := newTestStub(t, weatherStub)
+
+	if info := c.GetServerInfo(); info == nil || info.Name != "weather" {
+		t.Errorf("Expected the catalog's server info, got %+v", info)
+	}
+	if c.GetServerInstructions() != "Ask for forecasts by city" {
+		t.Errorf("Expected the catalog's instructions, got %q", c.GetServerInstructions())
+	}
+	if !c.HasCapability("tools") || !c.HasCapability("resources") || !c.HasCapability("prompts") {
+		t.Error("Expected the capabilities the catalog implies")
+	}
+
+	tools, err := c.ListTools()
+	if err != nil || len(tools) != 1 || tools[0].Name != "forecast" {
+		t.Fatalf("Expected the catalog's tools, got %v, %v", tools, err)
+	}
+
+	calls := []struct {
+		city    string
+		want    string
+		isError bool
+	}{
+		{"Paris", "Computing", false},
+		{"Paris", "Sunny in Paris", false},
+		{"Lyon", "Sunny in Lyon", false},
+		{"Atlantis", "unknown city Atlantis", true},
+	}
+	for _, call := range calls {
+		result, err := c.CallTool("forecast", map[string]interface{}{"city": call.city})
+		if err != nil {
+			t.Fatalf("CallTool(%s) failed: %v", call.city, err)
+		}
+		if text, isError := resultText(t, result); text != call.want || isError != call.isError {
+			t.Errorf("CallTool(%s) = %q (error %v), expected %q (error %v)", call.city, text, isError, call.want, call.isError)
+		}
+	}
+	if _, err := c.CallTool("history", nil); err == nil || !strings.Contains(err.Error(), "tool not found") {
+		t.Errorf("Expected an unknown tool to fail, got %v", err)
+	}
+
+	stations, err := c.GetResource("/stations")
+	if err != nil || len(stations.Contents) == 0 || stations.Contents[0].Text != `["LFPG"]` {
+		t.Errorf("Expected the canned resource, got %+v, %v", stations, err)
+	}
+	station, err := c.GetResource("/stations/LFPG")
+	if err != nil || len(station.Contents) == 0 || station.Contents[0].Text != "Station LFPG" {
+		t.Errorf("Expected the template's response, got %+v, %v", station, err)
+	}
+
+	prompt, err := c.GetPrompt("summary", map[string]interface{}{"city": "Oslo"})
+	if err != nil || len(prompt.Messages) != 1 || prompt.Messages[0].Content.Text != "Summarize the weather in Oslo" {
+		t.Errorf("Expected the canned prompt, got %+v, %v", prompt, err)
+	}
+}
+
+func TestStubToolHandlers(t *testing.T) {
+	c, err := NewStubFromCatalog(&StubCatalog{
+		ServerInfo: ServerInfo{Name: "scripted", Version: "1.0.0"},
+		Tools:      []StubTool{{Tool: Tool{Name: "divide", InputSchema: map[string]interface{}{"type": "object"}}}},
+		ToolHandlers: map[string]func(args map[string]interface{}) (interface{}, error){
+			"divide": func(args map[string]interface{}) (interface{}, error) {
+				if args["b"] == 0.0 {
+					return nil, errors.New("division by zero")
+				}
+				return map[string]interface{}{"quotient": args["a"].(float64) / args["b"].(float64)}, nil
+			},
+		},
+	}, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatalf("NewStubFromCatalog failed: %v", err)
+	}
+	defer c.Close()
+
+	result, err := c.CallTool("divide", map[string]interface{}{"a": 6, "b": 3})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if text, isError := resultText(t, result); text != `{"quotient":2}` || isError {
+		t.Errorf("Expected the handler's result, got %q (error %v)", text, isError)
+	}
+
+	result, err = c.CallTool("divide", map[string]interface{}{"a": 6, "b": 0})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if text, isError := resultText(t, result); text != "division by zero" || !isError {
+		t.Errorf("Expected the handler's error as an error result, got %q (error %v)", text, isError)
+	}
+}
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/client"
+)
+
+func TestCancelRequest(t *testing.T) {
+	c, m := SetupClientWithMockTransport(t, "2025-03-26")
+	defer c.Close()
+
+	// Hold every response long enough for the request to be cancelled first
+	m.WithDefaultResponse([]byte(cachedToolResponse), nil)
+	m.WithNetworkConditions(NetworkConditions{Latency: 300 * time.Millisecond})
+
+	var requestID int64
+	begin := time.Now()
+	_, err := c.CallTool("slow", nil, client.WithRequestIDCallback(func(id int64) {
+		requestID = id
+		go func() {
+			if err := c.CancelRequest(id); err != nil {
+				t.Errorf("CancelRequest failed: %v", err)
+			}
+		}()
+	}))
+	if !errors.Is(err, client.ErrRequestCancelled) {
+		t.Fatalf("Expected ErrRequestCancelled, got %v", err)
+	}
+	if elapsed := time.Since(begin); elapsed >= 300*time.Millisecond {
+		t.Fatalf("Cancelled request should return immediately, took %v", elapsed)
+	}
+
+	if !m.WaitForNotification("notifications/cancelled", 2*time.Second) {
+		t.Fatal("Expected a notifications/cancelled to be sent")
+	}
+	var notifications []RequestRecord
+	for _, rec := range m.GetRequestHistory() {
+		if rec.Method == "notifications/cancelled" {
+			notifications = append(notifications, rec)
+		}
+	}
+	if len(notifications) != 1 {
+		t.Fatalf("Expected 1 cancellation notification, got %d", len(notifications))
+	}
+
+	var notification struct {
+		Params struct {
+			RequestID json.Number `json:"requestId"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(notifications[0].Message, &notification); err != nil {
+		t.Fatalf("Failed to parse cancellation notification: %v", err)
+	}
+	if id, err := notification.Params.RequestID.Int64(); err != nil || id != requestID {
+		t.Fatalf("Expected requestId %d, got %q", requestID, notification.Params.RequestID)
+	}
+
+	// The request is no longer in flight
+	if err := c.CancelRequest(requestID); err == nil {
+		t.Fatal("Expected an error cancelling a finished request")
+	}
+}
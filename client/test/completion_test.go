@@ -0,0 +1,49 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/localrivet/gomcp/client"
+)
+
+func TestComplete(t *testing.T) {
+	c, m := SetupClientWithMockTransport(t, "2025-03-26")
+	defer c.Close()
+
+	m.QueueConditionalResponse(
+		[]byte(`{"jsonrpc":"2.0","id":0,"result":{"completion":{"values":["python","pytorch"],"total":12,"hasMore":true}}}`),
+		nil, IsRequestMethod("completion/complete"))
+
+	result, err := c.Complete(client.PromptRef("review"), client.CompletionArgument{Name: "language", Value: "py"})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if len(result.Values) != 2 || result.Values[0] != "python" {
+		t.Fatalf("Unexpected completion values: %v", result.Values)
+	}
+	if result.Total != 12 || !result.HasMore {
+		t.Fatalf("Expected total 12 with more values, got total %d hasMore %v", result.Total, result.HasMore)
+	}
+
+	requests := m.GetRequestsByMethod("completion/complete")
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 completion/complete request, got %d", len(requests))
+	}
+
+	var request struct {
+		Params struct {
+			Ref      client.CompletionRef      `json:"ref"`
+			Argument client.CompletionArgument `json:"argument"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(requests[0].Message, &request); err != nil {
+		t.Fatalf("Failed to parse request: %v", err)
+	}
+	if request.Params.Ref.Type != "ref/prompt" || request.Params.Ref.Name != "review" {
+		t.Fatalf("Unexpected ref: %+v", request.Params.Ref)
+	}
+	if request.Params.Argument.Name != "language" || request.Params.Argument.Value != "py" {
+		t.Fatalf("Unexpected argument: %+v", request.Params.Argument)
+	}
+}
@@ -218,6 +218,54 @@ func TestListResourcesWithPagination(t *testing.T) {
 	t.Logf("Successfully retrieved %d resources across pages", len(resources))
 }
 
+// TestListResourceTemplates tests templated resource discovery across pages
+func TestListResourceTemplates(t *testing.T) {
+	c, m := SetupClientWithMockTransport(t, "2025-03-26")
+	defer c.Close()
+
+	pages := []map[string]interface{}{
+		{
+			"resourceTemplates": []interface{}{
+				map[string]interface{}{
+					"uriTemplate": "/users/{id}",
+					"name":        "User",
+					"description": "A user by ID",
+					"annotations": map[string]interface{}{"tags": []interface{}{"users"}},
+				},
+			},
+			"nextCursor": "page2",
+		},
+		{
+			"resourceTemplates": []interface{}{
+				map[string]interface{}{"uriTemplate": "/docs/{path*}", "name": "Docs", "mimeType": "text/markdown"},
+			},
+		},
+	}
+	for i, page := range pages {
+		response, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": i + 2, "result": page})
+		if err != nil {
+			t.Fatalf("Failed to marshal templates response: %v", err)
+		}
+		m.QueueConditionalResponse(response, nil, func(req []byte) bool {
+			return isRequestMethod(req, "resources/templates/list")
+		})
+	}
+
+	templates, err := c.ListResourceTemplates()
+	if err != nil {
+		t.Fatalf("Failed to list resource templates: %v", err)
+	}
+	if len(templates) != 2 {
+		t.Fatalf("Expected 2 templates, got %d", len(templates))
+	}
+	if templates[0].URITemplate != "/users/{id}" || templates[0].Description != "A user by ID" {
+		t.Errorf("Unexpected first template: %+v", templates[0])
+	}
+	if templates[1].URITemplate != "/docs/{path*}" || templates[1].MimeType != "text/markdown" {
+		t.Errorf("Unexpected second template: %+v", templates[1])
+	}
+}
+
 // TestListPrompts tests the prompt discovery functionality
 func TestListPrompts(t *testing.T) {
 	// Create client with mock transport
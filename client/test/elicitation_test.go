@@ -0,0 +1,85 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/client"
+	"github.com/localrivet/gomcp/server"
+	"github.com/localrivet/gomcp/transport/embedded"
+)
+
+func TestElicitationHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := server.NewServer("elicitation-server", server.WithLogger(logger))
+	s.Tool("greet", "Greet the user by name", func(ctx *server.Context, args struct{}) (interface{}, error) {
+		result, err := ctx.Elicit(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{"type": "string"},
+			},
+			"required": []string{"name"},
+		}, "What is your name?")
+		if err != nil {
+			return nil, err
+		}
+		if !result.Accepted() {
+			return "no greeting: " + string(result.Action), nil
+		}
+		return fmt.Sprintf("hello %v", result.Content["name"]), nil
+	})
+
+	serverTransport, clientTransport := embedded.NewTransportPair()
+	s.AsEmbedded(serverTransport)
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go s.Serve(ctx)
+
+	answers := make(chan client.ElicitationResult, 1)
+	prompts := make(chan string, 1)
+	c, err := client.NewClient("elicitation-client",
+		client.WithLogger(logger),
+		client.WithEmbedded(clientTransport),
+		client.WithProtocolVersion("draft"),
+		client.WithRequestTimeout(5*time.Second),
+		client.WithElicitationHandler(func(params client.ElicitationCreateParams) (client.ElicitationResult, error) {
+			prompts <- params.Message
+			return <-answers, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer c.Close()
+
+	tests := []struct {
+		name   string
+		answer client.ElicitationResult
+		want   string
+	}{
+		{"accepted", client.ElicitationResult{Action: client.ElicitationAccept, Content: map[string]interface{}{"name": "Ada"}}, "hello Ada"},
+		{"declined", client.ElicitationResult{Action: client.ElicitationDecline}, "no greeting: decline"},
+		// The client refuses to send input that does not match the schema
+		{"invalid", client.ElicitationResult{Action: client.ElicitationAccept, Content: map[string]interface{}{}}, "Invalid elicitation response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answers <- tt.answer
+			result, err := c.CallTool("greet", nil)
+			if err != nil {
+				t.Fatalf("Failed to call tool: %v", err)
+			}
+			if !strings.Contains(fmt.Sprint(result), tt.want) {
+				t.Errorf("Expected %q, got %v", tt.want, result)
+			}
+			if prompt := <-prompts; prompt != "What is your name?" {
+				t.Errorf("Expected the prompt, got %q", prompt)
+			}
+		})
+	}
+}
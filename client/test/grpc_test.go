@@ -1,10 +1,14 @@
 package test
 
 import (
+	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/localrivet/gomcp/client"
+	"github.com/localrivet/gomcp/server"
+	"github.com/localrivet/gomcp/transport/grpc"
 )
 
 // TestGRPCOption tests the client.WithGRPC option
@@ -77,3 +81,81 @@ func TestGRPCWithOptions(t *testing.T) {
 	// Clean up
 	c.Close()
 }
+
+// TestGRPCEndToEnd connects a gRPC client to a gRPC server, calls a tool, receives a
+// notification and discovers the MCP service through reflection
+func TestGRPCEndToEnd(t *testing.T) {
+	address := freeAddress(t)
+
+	s := server.NewServer("grpc-server").AsGRPC(address, server.WithGRPCReflection())
+	s.Tool("echo", "Echo a message", func(ctx *server.Context, args struct {
+		Message string `json:"message"`
+	}) (interface{}, error) {
+		return args.Message, nil
+	})
+	go s.Run()
+	defer s.Shutdown()
+	waitForListener(t, address)
+
+	services, err := client.DiscoverGRPCServices(context.Background(), address)
+	if err != nil {
+		t.Fatalf("Failed to discover services: %v", err)
+	}
+	found := false
+	for _, service := range services {
+		found = found || service == grpc.ServiceName
+	}
+	if !found {
+		t.Fatalf("Expected %s among discovered services, got %v", grpc.ServiceName, services)
+	}
+
+	c, err := client.NewClient("grpc-client",
+		client.WithGRPC(address, client.WithGRPCClientID("end-to-end")),
+		client.WithConnectionTimeout(5*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer c.Close()
+
+	resourcesChanged := make(chan struct{}, 1)
+	c.OnListChanged(func(kind string) {
+		if kind != "resources" {
+			return
+		}
+		select {
+		case resourcesChanged <- struct{}{}:
+		default:
+		}
+	})
+
+	result, err := c.CallTool("echo", map[string]interface{}{"message": "hello"})
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	if content, ok := result.(map[string]interface{})["content"].([]interface{}); !ok || len(content) == 0 ||
+		content[0].(map[string]interface{})["text"] != "hello" {
+		t.Fatalf("Unexpected tool result: %v", result)
+	}
+
+	// Server notifications reach the client over the stream
+	s.Resource("/late", "Registered after initialization", func(ctx *server.Context, args interface{}) (interface{}, error) {
+		return "late", nil
+	})
+	select {
+	case <-resourcesChanged:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the list_changed notification")
+	}
+}
+
+// TestGRPCTLSFailure checks that a client with unusable TLS credentials fails to
+// connect instead of falling back to an insecure connection
+func TestGRPCTLSFailure(t *testing.T) {
+	_, err := client.NewClient("grpc-client",
+		client.WithGRPC(freeAddress(t), client.WithGRPCTLS("", "", "/nonexistent/ca.pem")),
+	)
+	if err == nil || !strings.Contains(err.Error(), "failed to load TLS credentials") {
+		t.Fatalf("Expected a TLS credentials error, got %v", err)
+	}
+}
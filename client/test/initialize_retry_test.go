@@ -0,0 +1,75 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/client"
+)
+
+// failFirstInitialize makes the first initialize request to the mock transport fail
+func failFirstInitialize(m *MockTransport, err error) {
+	m.ResponseQueue = append([]ResponseConfig{{Error: err}}, m.ResponseQueue...)
+}
+
+func TestInitializeRetry(t *testing.T) {
+	m := SetupMockTransport("2025-03-26")
+	failFirstInitialize(m, context.DeadlineExceeded)
+
+	c, err := client.NewClient("test://server",
+		client.WithTransport(m),
+		client.WithInitializeRetry(3, 10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("Expected the retried initialize to succeed, got %v", err)
+	}
+	defer c.Close()
+
+	if got := len(m.GetRequestsByMethod("initialize")); got != 2 {
+		t.Errorf("Expected 2 initialize requests, got %d", got)
+	}
+}
+
+func TestInitializeWithoutRetry(t *testing.T) {
+	m := SetupMockTransport("2025-03-26")
+	failFirstInitialize(m, context.DeadlineExceeded)
+
+	_, err := client.NewClient("test://server", client.WithTransport(m))
+	if err == nil {
+		t.Fatal("Expected initialize to fail without retries")
+	}
+
+	var transportErr *client.TransportError
+	if !errors.As(err, &transportErr) || transportErr.Kind != client.TransportErrorTimeout {
+		t.Errorf("Expected a timeout TransportError, got %v", err)
+	}
+}
+
+func TestInitializeRetrySkipsExitedProcess(t *testing.T) {
+	m := SetupMockTransport("2025-03-26")
+	failFirstInitialize(m, &client.TransportError{
+		Kind:     client.TransportErrorProcessExited,
+		Op:       "initialize",
+		Target:   "weather",
+		ExitCode: 1,
+		Stderr:   "starting\nWEATHER_API_KEY is not set\n",
+	})
+
+	_, err := client.NewClient("test://server",
+		client.WithTransport(m),
+		client.WithInitializeRetry(3, 10*time.Millisecond),
+	)
+	if err == nil {
+		t.Fatal("Expected initialize to fail")
+	}
+	if got := len(m.GetRequestsByMethod("initialize")); got != 1 {
+		t.Errorf("Expected an exited server not to be retried, got %d initialize requests", got)
+	}
+
+	want := "server process weather exited with code 1, stderr: starting\nWEATHER_API_KEY is not set"
+	if got := client.Diagnose(err); got != want {
+		t.Errorf("Diagnose() = %q, want %q", got, want)
+	}
+}
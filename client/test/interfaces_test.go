@@ -0,0 +1,53 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/localrivet/gomcp/client"
+)
+
+// stubToolCaller is a ToolCaller that echoes the tool name
+type stubToolCaller struct {
+	calls []string
+}
+
+func (s *stubToolCaller) CallTool(name string, args map[string]interface{}, opts ...client.RequestOption) (interface{}, error) {
+	s.calls = append(s.calls, name)
+	return name, nil
+}
+func (s *stubToolCaller) ListTools() ([]client.Tool, error)                         { return nil, nil }
+func (s *stubToolCaller) SearchTools(query client.ToolQuery) ([]client.Tool, error) { return nil, nil }
+func (s *stubToolCaller) ClearToolCache()                                           {}
+
+// callTwice needs nothing but a ToolCaller
+func callTwice(tools client.ToolCaller, name string) error {
+	for i := 0; i < 2; i++ {
+		if _, err := tools.CallTool(name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestClientSubInterfaces(t *testing.T) {
+	c, _ := SetupClientWithMockTransport(t, "2025-03-26")
+	defer c.Close()
+
+	// A Client satisfies every sub-interface
+	var (
+		_ client.ToolCaller     = c
+		_ client.ResourceReader = c
+		_ client.PromptReader   = c
+		_ client.Sampler        = c
+		_ client.Admin          = c
+	)
+
+	// A small mock is enough for code that accepts a sub-interface
+	stub := &stubToolCaller{}
+	if err := callTwice(stub, "echo"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(stub.calls) != 2 {
+		t.Fatalf("Expected 2 tool calls, got %d", len(stub.calls))
+	}
+}
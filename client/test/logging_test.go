@@ -0,0 +1,51 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/client"
+	"github.com/localrivet/gomcp/mcp"
+)
+
+func TestSetLogLevelAndLogMessages(t *testing.T) {
+	c, m := SetupClientWithMockTransport(t, "2025-03-26")
+	defer c.Close()
+
+	m.QueueConditionalResponse([]byte(`{"jsonrpc":"2.0","id":0,"result":{}}`), nil, IsRequestMethod("logging/setLevel"))
+	if err := c.SetLogLevel(mcp.LogLevelWarning); err != nil {
+		t.Fatalf("SetLogLevel failed: %v", err)
+	}
+	if requests := m.GetRequestHistory(); len(requests) == 0 || requests[len(requests)-1].Method != "logging/setLevel" {
+		t.Fatal("Expected a logging/setLevel request")
+	}
+
+	if err := c.SetLogLevel("verbose"); err == nil {
+		t.Fatal("Expected an error for an invalid log level")
+	}
+
+	received := make(chan client.LogMessage, 1)
+	c.OnLogMessage(func(msg client.LogMessage) {
+		received <- msg
+	})
+
+	m.SimulateNotification("notifications/message",
+		[]byte(`{"jsonrpc":"2.0","method":"notifications/message","params":{"level":"warning","logger":"srv","data":{"message":"disk almost full"}}}`))
+
+	var msg client.LogMessage
+	select {
+	case msg = <-received:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a log message")
+	}
+	if msg.Level != mcp.LogLevelWarning || msg.Logger != "srv" {
+		t.Fatalf("Unexpected log message: %+v", msg)
+	}
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok || data["message"] != "disk almost full" {
+		t.Fatalf("Unexpected log data: %#v", msg.Data)
+	}
+	if counts := c.UnknownMethodCounts(); len(counts) != 0 {
+		t.Fatalf("Log messages should not be counted as unknown, got %v", counts)
+	}
+}
@@ -267,7 +267,9 @@ func (m *MockTransport) Send(message []byte) ([]byte, error) {
 	// A simplified approach with minimal locking to avoid deadlocks
 
 	// Store the original message for debugging
+	m.mu.Lock()
 	m.LastSentMessage = append([]byte{}, message...)
+	m.mu.Unlock()
 
 	// Apply request interceptor if set
 	if m.RequestInterceptor != nil {
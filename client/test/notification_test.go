@@ -0,0 +1,210 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/client"
+)
+
+// receive waits for a value from ch, failing the test after a second
+func receive[T any](t *testing.T, ch <-chan T, what string) T {
+	t.Helper()
+	select {
+	case value := <-ch:
+		return value
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for %s", what)
+		var zero T
+		return zero
+	}
+}
+
+func TestNotificationHandlersAndUnknownMethods(t *testing.T) {
+	statuses := make(chan string, 1)
+	c, m := SetupClientWithOptions(t, "2025-03-26",
+		client.WithNotificationHandler("notifications/vendor/status", func(params json.RawMessage) error {
+			var status struct {
+				State string `json:"state"`
+			}
+			if err := json.Unmarshal(params, &status); err != nil {
+				return err
+			}
+			statuses <- status.State
+			return nil
+		}))
+	defer c.Close()
+
+	m.SimulateNotification("notifications/vendor/status",
+		[]byte(`{"jsonrpc":"2.0","method":"notifications/vendor/status","params":{"state":"ready"}}`))
+	if state := receive(t, statuses, "the custom notification handler"); state != "ready" {
+		t.Fatalf("Expected state ready, got %q", state)
+	}
+
+	// Standard notifications are not reported as unknown
+	m.SimulateNotification("notifications/resources/updated",
+		[]byte(`{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"file:///a"}}`))
+
+	m.SimulateNotification("notifications/vendor/other", []byte(`{"jsonrpc":"2.0","method":"notifications/vendor/other"}`))
+	m.SimulateNotification("notifications/vendor/other", []byte(`{"jsonrpc":"2.0","method":"notifications/vendor/other"}`))
+
+	counts := c.UnknownMethodCounts()
+	if len(counts) != 1 || counts["notifications/vendor/other"] != 2 {
+		t.Fatalf("Expected 2 unknown notifications/vendor/other, got %v", counts)
+	}
+}
+
+func TestNotificationRouting(t *testing.T) {
+	c, m := SetupClientWithMockTransport(t, "2025-03-26")
+	defer c.Close()
+
+	progress := make(chan client.ProgressNotification, 10)
+	c.OnProgress(func(p client.ProgressNotification) {
+		progress <- p
+	})
+
+	updated := make(chan string, 1)
+	c.OnResourceUpdated(func(uri string) {
+		updated <- uri
+	})
+
+	changed := make(chan string, 3)
+	c.OnListChanged(func(kind string) {
+		changed <- kind
+	})
+
+	// A blocked handler delays only its own method
+	release := make(chan struct{})
+	c.OnNotification("notifications/vendor/slow", func(json.RawMessage) error {
+		<-release
+		return nil
+	})
+	defer close(release)
+	m.SimulateNotification("notifications/vendor/slow", []byte(`{"jsonrpc":"2.0","method":"notifications/vendor/slow"}`))
+
+	// Notifications of one method are delivered in order
+	for i := 1; i <= 5; i++ {
+		step := string(rune('0' + i))
+		m.SimulateNotification("notifications/progress", []byte(`{"jsonrpc":"2.0","method":"notifications/progress","params":{"progressToken":"job`+step+`","progress":`+step+`,"total":5,"message":"step"}}`))
+	}
+	for i := 1; i <= 5; i++ {
+		p := receive(t, progress, "a progress notification")
+		if p.Progress != float64(i) || p.Total != 5 || p.ProgressToken != "job"+string(rune('0'+i)) || p.Message != "step" {
+			t.Fatalf("Unexpected progress notification %d: %+v", i, p)
+		}
+	}
+
+	m.SimulateNotification("notifications/resources/updated",
+		[]byte(`{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"file:///data.json"}}`))
+	if uri := receive(t, updated, "a resource update"); uri != "file:///data.json" {
+		t.Fatalf("Unexpected updated resource %q", uri)
+	}
+
+	for _, kind := range []string{"tools", "resources", "prompts"} {
+		m.SimulateNotification("notifications/"+kind+"/list_changed",
+			[]byte(`{"jsonrpc":"2.0","method":"notifications/`+kind+`/list_changed"}`))
+		if got := receive(t, changed, kind+" list change"); got != kind {
+			t.Fatalf("Expected %s list change, got %s", kind, got)
+		}
+	}
+}
+
+func TestNotificationBufferPolicies(t *testing.T) {
+	burst := func(values ...string) []string {
+		notifications := make([]string, len(values))
+		for i, value := range values {
+			notifications[i] = `{"jsonrpc":"2.0","method":"notifications/vendor/burst","params":{"key":"` + value[:1] + `","value":"` + value + `"}}`
+		}
+		return notifications
+	}
+	byKey := func(params json.RawMessage) string {
+		var p struct {
+			Key string `json:"key"`
+		}
+		json.Unmarshal(params, &p)
+		return p.Key
+	}
+
+	tests := []struct {
+		name     string
+		buffer   client.NotificationBuffer
+		expected []string
+	}{
+		{"drop newest", client.NotificationBuffer{Size: 2}, []string{"a1", "b1", "c1"}},
+		{"drop oldest", client.NotificationBuffer{Size: 2, Overflow: client.OverflowDropOldest}, []string{"a1", "d1", "e1"}},
+		{"coalesce", client.NotificationBuffer{Size: 2, Coalesce: byKey}, []string{"a1", "b3", "c2"}},
+		{"block", client.NotificationBuffer{Size: 2, Overflow: client.OverflowBlock}, []string{"a1", "b1", "c1", "d1", "e1"}},
+	}
+
+	inputs := map[string][]string{
+		"drop newest": burst("a1", "b1", "c1", "d1", "e1"),
+		"drop oldest": burst("a1", "b1", "c1", "d1", "e1"),
+		"coalesce":    burst("a1", "b1", "c1", "b2", "c2", "b3"),
+		"block":       burst("a1", "b1", "c1", "d1", "e1"),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			release := make(chan struct{})
+			received := make(chan string, 10)
+
+			c, m := SetupClientWithOptions(t, "2025-03-26",
+				client.WithNotificationBuffer("notifications/vendor/burst", tt.buffer),
+				client.WithNotificationHandler("notifications/vendor/burst", func(params json.RawMessage) error {
+					var p struct {
+						Value string `json:"value"`
+					}
+					if err := json.Unmarshal(params, &p); err != nil {
+						return err
+					}
+					if p.Value == "a1" {
+						close(started)
+						<-release
+					}
+					received <- p.Value
+					return nil
+				}))
+			defer c.Close()
+
+			notifications := inputs[tt.name]
+
+			// Hold the worker in the handler of the first notification so the rest queue up
+			m.SimulateNotification("notifications/vendor/burst", []byte(notifications[0]))
+			receive(t, started, "the first notification")
+
+			sent := make(chan struct{})
+			go func() {
+				for _, notification := range notifications[1:] {
+					m.SimulateNotification("notifications/vendor/burst", []byte(notification))
+				}
+				close(sent)
+			}()
+
+			if tt.buffer.Overflow == client.OverflowBlock {
+				select {
+				case <-sent:
+					t.Fatal("Expected the transport to block while the queue is full")
+				case <-time.After(50 * time.Millisecond):
+				}
+			} else {
+				receive(t, sent, "the burst to be queued")
+			}
+
+			close(release)
+			receive(t, sent, "the burst to be queued")
+
+			for i, want := range tt.expected {
+				if got := receive(t, received, "a queued notification"); got != want {
+					t.Fatalf("Notification %d: expected %s, got %s", i, want, got)
+				}
+			}
+			select {
+			case extra := <-received:
+				t.Fatalf("Unexpected extra notification %s", extra)
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
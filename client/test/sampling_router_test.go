@@ -0,0 +1,145 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/localrivet/gomcp/client"
+)
+
+func routeHandler(model string, calls *[]string, err error) client.SamplingHandler {
+	return func(params client.SamplingCreateMessageParams) (client.SamplingResponse, error) {
+		*calls = append(*calls, model)
+		if err != nil {
+			return client.SamplingResponse{}, err
+		}
+		return client.SamplingResponse{
+			Role:    "assistant",
+			Content: client.SamplingMessageContent{Type: "text", Text: "from " + model},
+		}, nil
+	}
+}
+
+func newTestRouter(calls *[]string, failing map[string]bool, options ...client.SamplingRouterOption) *client.SamplingRouter {
+	router := client.NewSamplingRouter(options...)
+	for _, route := range []client.ModelRoute{
+		{Provider: "anthropic", Model: "claude-3-opus", Aliases: []string{"opus"}, CostPerMillionTokens: 75, SpeedScore: 0.3, IntelligenceScore: 1},
+		{Provider: "anthropic", Model: "claude-3-haiku", CostPerMillionTokens: 1, SpeedScore: 1, IntelligenceScore: 0.5},
+		{Provider: "openai", Model: "gpt-4o", Aliases: []string{"gpt-4"}, CostPerMillionTokens: 15, SpeedScore: 0.7, IntelligenceScore: 0.9},
+	} {
+		var err error
+		if failing[route.Model] {
+			err = errors.New("provider unavailable")
+		}
+		route.Handler = routeHandler(route.Model, calls, err)
+		router.AddRoute(route)
+	}
+	return router
+}
+
+func modelNames(routes []client.ModelRoute) []string {
+	names := make([]string, len(routes))
+	for i, route := range routes {
+		names[i] = route.Model
+	}
+	return names
+}
+
+func TestSamplingRouterSelect(t *testing.T) {
+	var calls []string
+	router := newTestRouter(&calls, nil)
+
+	high, low := 1.0, 0.0
+	tests := []struct {
+		name     string
+		prefs    client.SamplingModelPreferences
+		expected []string
+	}{
+		{
+			name:     "hints rank first in order",
+			prefs:    client.SamplingModelPreferences{Hints: []client.SamplingModelHint{{Name: "gpt-4"}, {Name: "claude"}}},
+			expected: []string{"gpt-4o", "claude-3-opus", "claude-3-haiku"},
+		},
+		{
+			name:     "cost priority prefers cheap models",
+			prefs:    client.SamplingModelPreferences{CostPriority: &high, IntelligencePriority: &low},
+			expected: []string{"claude-3-haiku", "gpt-4o", "claude-3-opus"},
+		},
+		{
+			name:     "intelligence priority prefers smart models",
+			prefs:    client.SamplingModelPreferences{IntelligencePriority: &high},
+			expected: []string{"claude-3-opus", "gpt-4o", "claude-3-haiku"},
+		},
+		{
+			name:     "priorities break ties between hint matches",
+			prefs:    client.SamplingModelPreferences{Hints: []client.SamplingModelHint{{Name: "claude"}}, SpeedPriority: &high},
+			expected: []string{"claude-3-haiku", "claude-3-opus", "gpt-4o"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := modelNames(router.Select(tt.prefs))
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("Expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSamplingRouterCostCeiling(t *testing.T) {
+	var calls []string
+	router := newTestRouter(&calls, nil, client.WithSamplingCostCeiling(20))
+
+	got := modelNames(router.Select(client.SamplingModelPreferences{
+		Hints: []client.SamplingModelHint{{Name: "opus"}},
+	}))
+	for _, model := range got {
+		if model == "claude-3-opus" {
+			t.Fatalf("Route above the cost ceiling should be excluded, got %v", got)
+		}
+	}
+	if len(got) != 2 {
+		t.Errorf("Expected 2 routes under the ceiling, got %v", got)
+	}
+}
+
+func TestSamplingRouterFallback(t *testing.T) {
+	var calls []string
+	router := newTestRouter(&calls, map[string]bool{"gpt-4o": true})
+
+	params := client.SamplingCreateMessageParams{
+		Messages:         []client.SamplingMessage{client.CreateTextMessage("user", "hi")},
+		ModelPreferences: client.SamplingModelPreferences{Hints: []client.SamplingModelHint{{Name: "gpt-4"}, {Name: "haiku"}}},
+	}
+
+	response, err := router.Handler()(params)
+	if err != nil {
+		t.Fatalf("Expected fallback to succeed, got %v", err)
+	}
+	if response.Model != "claude-3-haiku" {
+		t.Errorf("Expected response from fallback model, got %q", response.Model)
+	}
+	if len(calls) != 2 || calls[0] != "gpt-4o" {
+		t.Errorf("Expected gpt-4o to be tried before falling back, got %v", calls)
+	}
+
+	// Without fallback the first failure is returned
+	calls = nil
+	strict := newTestRouter(&calls, map[string]bool{"gpt-4o": true}, client.WithSamplingFallback(false))
+	if _, err := strict.Route(params); err == nil {
+		t.Error("Expected error without fallback")
+	}
+	if len(calls) != 1 {
+		t.Errorf("Expected a single attempt without fallback, got %v", calls)
+	}
+
+	if _, err := client.NewSamplingRouter().Route(params); !errors.Is(err, client.ErrNoModelRoute) {
+		t.Errorf("Expected ErrNoModelRoute for an empty router, got %v", err)
+	}
+}
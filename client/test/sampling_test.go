@@ -2,10 +2,12 @@ package test
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/localrivet/gomcp/client"
+	"github.com/localrivet/gomcp/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -326,3 +328,69 @@ func TestStreamingSupportDetection(t *testing.T) {
 		})
 	}
 }
+
+func TestSamplingOptions_GenerationParameters(t *testing.T) {
+	messages := []client.SamplingMessage{
+		client.CreateTextMessage("user", "Hello"),
+	}
+
+	opts := client.NewSamplingOptions(messages, client.SamplingModelPreferences{}).
+		WithTemperature(0.7).
+		WithStopSequences("\n\nHuman:", "END").
+		WithMetadata(map[string]interface{}{"user": "abc"})
+	opts.ProtocolVersion = "2024-11-05"
+
+	require.NotNil(t, opts.Temperature)
+	assert.Equal(t, 0.7, *opts.Temperature)
+	assert.Equal(t, []string{"\n\nHuman:", "END"}, opts.StopSequences)
+	assert.Equal(t, "abc", opts.Metadata["user"])
+	assert.NoError(t, opts.Validate())
+
+	opts.WithTemperature(-1)
+	assert.ErrorContains(t, opts.Validate(), "temperature")
+
+	opts.WithTemperature(1).WithStopSequences("")
+	assert.ErrorContains(t, opts.Validate(), "stop sequence")
+
+	opts.WithStopSequences("END")
+	opts.ProtocolVersion = "1999-01-01"
+	assert.ErrorContains(t, opts.Validate(), "not supported in protocol version")
+}
+
+func TestSamplingCreateMessageParams_GenerationParameters(t *testing.T) {
+	var params client.SamplingCreateMessageParams
+	raw := `{"messages":[{"role":"user","content":{"type":"text","text":"hi"}}],"modelPreferences":{},` +
+		`"temperature":0.2,"stopSequences":["STOP"],"metadata":{"trace":{"id":7}}}`
+	require.NoError(t, json.Unmarshal([]byte(raw), &params))
+
+	require.NotNil(t, params.Temperature)
+	assert.Equal(t, 0.2, *params.Temperature)
+	assert.Equal(t, []string{"STOP"}, params.StopSequences)
+	assert.Equal(t, map[string]interface{}{"id": float64(7)}, params.Metadata["trace"])
+}
+
+func TestSamplingIncludeContext(t *testing.T) {
+	opts := client.NewSamplingOptions([]client.SamplingMessage{client.CreateTextMessage("user", "Hello")}, client.SamplingModelPreferences{})
+	assert.NoError(t, opts.WithIncludeContext(mcp.IncludeContextAllServers).Validate())
+	assert.ErrorContains(t, opts.WithIncludeContext("everything").Validate(), "includeContext")
+
+	tests := []struct {
+		raw        string
+		inclusion  string
+		thisServer bool
+		allServers bool
+	}{
+		{raw: `{}`, inclusion: "none"},
+		{raw: `{"includeContext":"none"}`, inclusion: "none"},
+		{raw: `{"includeContext":"thisServer"}`, inclusion: "thisServer", thisServer: true},
+		{raw: `{"includeContext":"allServers"}`, inclusion: "allServers", thisServer: true, allServers: true},
+	}
+
+	for _, tt := range tests {
+		var params client.SamplingCreateMessageParams
+		require.NoError(t, json.Unmarshal([]byte(tt.raw), &params))
+		assert.Equal(t, tt.inclusion, params.ContextInclusion(), tt.raw)
+		assert.Equal(t, tt.thisServer, params.IncludesThisServerContext(), tt.raw)
+		assert.Equal(t, tt.allServers, params.IncludesAllServersContext(), tt.raw)
+	}
+}
@@ -0,0 +1,142 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/client"
+)
+
+const cachedToolResponse = `{"jsonrpc":"2.0","id":0,"result":{"content":[{"type":"text","text":"42"}],"isError":false}}`
+
+func countToolCalls(m *MockTransport) int {
+	count := 0
+	for _, rec := range m.GetRequestHistory() {
+		if rec.Method == "tools/call" {
+			count++
+		}
+	}
+	return count
+}
+
+func TestToolResultCache(t *testing.T) {
+	c, m := SetupClientWithOptions(t, "2025-03-26", client.WithToolResultCache(time.Minute, 10))
+	defer c.Close()
+
+	m.WithDefaultResponse([]byte(cachedToolResponse), nil)
+
+	args := map[string]interface{}{"a": 1, "b": map[string]interface{}{"y": 2, "x": 1}}
+	if _, err := c.CallTool("answer", args); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	// Same arguments built in a different order must hit the cache
+	sameArgs := map[string]interface{}{"b": map[string]interface{}{"x": 1, "y": 2}, "a": 1}
+	if _, err := c.CallTool("answer", sameArgs); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if calls := countToolCalls(m); calls != 1 {
+		t.Fatalf("Expected 1 tools/call request, got %d", calls)
+	}
+
+	// Different arguments and explicit bypass go to the server
+	if _, err := c.CallTool("answer", map[string]interface{}{"a": 2}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if _, err := c.CallTool("answer", args, client.WithoutToolCache()); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if calls := countToolCalls(m); calls != 3 {
+		t.Fatalf("Expected 3 tools/call requests, got %d", calls)
+	}
+
+	// A tools/list_changed notification invalidates the cache
+	m.SimulateNotification("notifications/tools/list_changed",
+		[]byte(`{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`))
+	if _, err := c.CallTool("answer", args); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if calls := countToolCalls(m); calls != 4 {
+		t.Fatalf("Expected cache to be invalidated by list_changed, got %d tools/call requests", calls)
+	}
+}
+
+func TestToolResultCacheExpiryAndEviction(t *testing.T) {
+	c, m := SetupClientWithOptions(t, "2025-03-26", client.WithToolResultCache(50*time.Millisecond, 1))
+	defer c.Close()
+
+	m.WithDefaultResponse([]byte(cachedToolResponse), nil)
+
+	call := func(name string) {
+		t.Helper()
+		if _, err := c.CallTool(name, nil); err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+	}
+
+	call("first")
+	call("second") // evicts "first"
+	call("first")
+	if calls := countToolCalls(m); calls != 3 {
+		t.Fatalf("Expected evicted entry to be refetched, got %d tools/call requests", calls)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	call("first")
+	if calls := countToolCalls(m); calls != 4 {
+		t.Fatalf("Expected expired entry to be refetched, got %d tools/call requests", calls)
+	}
+}
+
+func TestToolResultCacheSkipsErrors(t *testing.T) {
+	c, m := SetupClientWithOptions(t, "2025-03-26", client.WithToolResultCache(time.Minute, 10))
+	defer c.Close()
+
+	m.WithDefaultResponse([]byte(`{"jsonrpc":"2.0","id":0,"result":{"content":[{"type":"text","text":"boom"}],"isError":true}}`), nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.CallTool("fails", nil); err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+	}
+
+	if calls := countToolCalls(m); calls != 2 {
+		t.Fatalf("Expected error results not to be cached, got %d tools/call requests", calls)
+	}
+}
+
+func TestToolResultCacheReturnsCopies(t *testing.T) {
+	c, m := SetupClientWithOptions(t, "2025-03-26", client.WithToolResultCache(time.Minute, 10))
+	defer c.Close()
+
+	m.WithDefaultResponse([]byte(cachedToolResponse), nil)
+
+	mutate := func(result interface{}) {
+		t.Helper()
+		resultMap, ok := result.(map[string]interface{})
+		if !ok {
+			t.Fatalf("Expected a map result, got %T", result)
+		}
+		resultMap["isError"] = true
+		resultMap["content"].([]interface{})[0].(map[string]interface{})["text"] = "changed"
+	}
+
+	// Neither the result that filled the cache nor a cached one shares state with it
+	for i := 0; i < 3; i++ {
+		result, err := c.CallTool("answer", nil)
+		if err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+		text, err := client.DecodeToolResult[string](result)
+		if err != nil {
+			t.Fatalf("Call %d: failed to decode the result: %v", i+1, err)
+		}
+		if text != "42" {
+			t.Fatalf("Call %d: expected the original result, got %q", i+1, text)
+		}
+		mutate(result)
+	}
+	if calls := countToolCalls(m); calls != 1 {
+		t.Fatalf("Expected 1 tools/call request, got %d", calls)
+	}
+}
@@ -0,0 +1,155 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/localrivet/gomcp/client"
+	"github.com/localrivet/gomcp/server"
+	"github.com/localrivet/gomcp/transport/embedded"
+)
+
+type forecast struct {
+	City string  `json:"city"`
+	High float64 `json:"high"`
+}
+
+func TestDecodeToolResult(t *testing.T) {
+	structured := map[string]interface{}{
+		"content":           []interface{}{map[string]interface{}{"type": "text", "text": "Oslo will be mild"}},
+		"structuredContent": map[string]interface{}{"city": "Oslo", "high": 12.5},
+	}
+	result, err := client.ParseToolResult(structured)
+	if err != nil {
+		t.Fatalf("ParseToolResult failed: %v", err)
+	}
+	if !result.HasStructuredContent() || result.Text() != "Oslo will be mild" {
+		t.Errorf("Expected structured content and the text fallback, got %+v", result)
+	}
+	got, err := client.DecodeToolResult[forecast](structured)
+	if err != nil || got != (forecast{City: "Oslo", High: 12.5}) {
+		t.Errorf("Expected the structured content, got %+v, %v", got, err)
+	}
+
+	// Results of older servers are decoded from their JSON text
+	text := map[string]interface{}{
+		"content": []interface{}{map[string]interface{}{"type": "text", "text": `{"city":"Bergen","high":9}`}},
+	}
+	got, err = client.DecodeToolResult[forecast](text)
+	if err != nil || got != (forecast{City: "Bergen", High: 9}) {
+		t.Errorf("Expected the text content, got %+v, %v", got, err)
+	}
+
+	failed := map[string]interface{}{
+		"content": []interface{}{map[string]interface{}{"type": "text", "text": "city not found"}},
+		"isError": true,
+	}
+	var toolErr *client.ToolError
+	if _, err := client.DecodeToolResult[forecast](failed); !errors.As(err, &toolErr) || toolErr.Message != "city not found" {
+		t.Errorf("Expected a ToolError, got %v", err)
+	}
+
+	if _, err := client.ParseToolResult("not a result"); err == nil {
+		t.Error("Expected an error for a result that is not an object")
+	}
+}
+
+func TestStructuredContentRoundTrip(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := server.NewServer("structured-server", server.WithLogger(logger))
+	s.Tool("forecast", "Forecast a city", func(ctx *server.Context, args struct {
+		City string `json:"city"`
+	}) (interface{}, error) {
+		return forecast{City: args.City, High: 12.5}, nil
+	})
+
+	serverTransport, clientTransport := embedded.NewTransportPair()
+	s.AsEmbedded(serverTransport)
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go s.Serve(ctx)
+
+	c, err := client.NewClient("structured-client",
+		client.WithLogger(logger),
+		client.WithEmbedded(clientTransport),
+		client.WithProtocolVersion("draft"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer c.Close()
+
+	raw, err := c.CallTool("forecast", map[string]interface{}{"city": "Oslo"})
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	result, err := client.ParseToolResult(raw)
+	if err != nil {
+		t.Fatalf("ParseToolResult failed: %v", err)
+	}
+	if !result.HasStructuredContent() {
+		t.Fatalf("Expected structured content from a draft server, got %+v", result)
+	}
+	var got forecast
+	if err := result.Decode(&got); err != nil || got.City != "Oslo" {
+		t.Errorf("Expected the forecast for Oslo, got %+v, %v", got, err)
+	}
+}
+
+func TestToolResultMedia(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := server.NewServer("media-server", server.WithLogger(logger))
+	s.Resource("/notes/readme", "Readme", func(ctx *server.Context, args interface{}) (interface{}, error) {
+		return "read me first", nil
+	})
+	s.Tool("report", "Returns an image, audio and a resource", func(ctx *server.Context, args struct{}) ([]server.ContentItem, error) {
+		return []server.ContentItem{
+			server.ImageContent([]byte("\x89PNG\r\n\x1a\n"), "image/png"),
+			server.AudioContent([]byte("RIFF"), "audio/wav"),
+			server.EmbeddedResourceContent("/notes/readme"),
+		}, nil
+	})
+
+	serverTransport, clientTransport := embedded.NewTransportPair()
+	s.AsEmbedded(serverTransport)
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go s.Serve(ctx)
+
+	c, err := client.NewClient("media-client",
+		client.WithLogger(logger),
+		client.WithEmbedded(clientTransport),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer c.Close()
+
+	raw, err := c.CallTool("report", nil)
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	result, err := client.ParseToolResult(raw)
+	if err != nil {
+		t.Fatalf("ParseToolResult failed: %v", err)
+	}
+
+	images, err := result.Images()
+	if err != nil || len(images) != 1 || string(images[0].Data) != "\x89PNG\r\n\x1a\n" || images[0].MimeType != "image/png" {
+		t.Errorf("Expected the image, got %+v, %v", images, err)
+	}
+	audio, err := result.Audio()
+	if err != nil || len(audio) != 1 || string(audio[0].Data) != "RIFF" {
+		t.Errorf("Expected the audio, got %+v, %v", audio, err)
+	}
+	resources := result.Resources()
+	if len(resources) != 1 {
+		t.Fatalf("Expected one embedded resource, got %+v", resources)
+	}
+	if data, err := resources[0].Bytes(); err != nil || string(data) != "read me first" {
+		t.Errorf("Expected the resource contents, got %q, %v", data, err)
+	}
+}
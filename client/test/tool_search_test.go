@@ -0,0 +1,132 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/localrivet/gomcp/client"
+)
+
+func TestSearchTools(t *testing.T) {
+	c, m := SetupClientWithMockTransport(t, "2025-03-26")
+	defer c.Close()
+
+	// The mock server ignores the filter extension, so the client must filter locally
+	toolsResponse := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      2,
+		"result": map[string]interface{}{
+			"tools": []interface{}{
+				map[string]interface{}{
+					"name":        "fs_read",
+					"description": "Read a file from disk",
+					"inputSchema": map[string]interface{}{"type": "object"},
+					"annotations": map[string]interface{}{"tags": []string{"files", "read-only"}},
+				},
+				map[string]interface{}{
+					"name":        "fs_write",
+					"description": "Write a file to disk",
+					"inputSchema": map[string]interface{}{"type": "object"},
+					"annotations": map[string]interface{}{"tags": []string{"files"}},
+				},
+				map[string]interface{}{
+					"name":        "http_get",
+					"description": "Fetch a URL",
+					"inputSchema": map[string]interface{}{"type": "object"},
+				},
+			},
+		},
+	}
+
+	toolsJSON, err := json.Marshal(toolsResponse)
+	if err != nil {
+		t.Fatalf("Failed to marshal tools response: %v", err)
+	}
+
+	m.QueueConditionalResponse(toolsJSON, nil, func(req []byte) bool {
+		return isRequestMethod(req, "tools/list")
+	})
+
+	tools, err := c.SearchTools(client.ToolQuery{NamePrefix: "fs_", Tags: []string{"Read-Only"}, Text: "file"})
+	if err != nil {
+		t.Fatalf("SearchTools failed: %v", err)
+	}
+
+	if len(tools) != 1 || tools[0].Name != "fs_read" {
+		t.Fatalf("Expected only fs_read, got %+v", tools)
+	}
+
+	// The filter extension should have been sent to the server
+	requests := m.GetRequestsByMethod("tools/list")
+	if len(requests) == 0 {
+		t.Fatal("Expected a tools/list request")
+	}
+
+	var sent struct {
+		Params struct {
+			Filter struct {
+				NamePrefix string   `json:"namePrefix"`
+				Tags       []string `json:"tags"`
+			} `json:"filter"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(requests[len(requests)-1].Message, &sent); err != nil {
+		t.Fatalf("Failed to parse sent request: %v", err)
+	}
+
+	if sent.Params.Filter.NamePrefix != "fs_" || len(sent.Params.Filter.Tags) != 1 {
+		t.Errorf("Unexpected filter sent to server: %+v", sent.Params.Filter)
+	}
+}
+
+func TestToolQueryMatches(t *testing.T) {
+	tool := client.Tool{
+		Name:        "grep_files",
+		Description: "Search file contents with a regular expression",
+		Annotations: map[string]interface{}{"tags": []interface{}{"files", "search"}},
+	}
+
+	testCases := []struct {
+		name  string
+		query client.ToolQuery
+		want  bool
+	}{
+		{"empty query", client.ToolQuery{}, true},
+		{"text in description", client.ToolQuery{Text: "REGULAR expression"}, true},
+		{"text missing term", client.ToolQuery{Text: "regular network"}, false},
+		{"matching prefix", client.ToolQuery{NamePrefix: "grep"}, true},
+		{"wrong prefix", client.ToolQuery{NamePrefix: "fs_"}, false},
+		{"all tags present", client.ToolQuery{Tags: []string{"search", "files"}}, true},
+		{"missing tag", client.ToolQuery{Tags: []string{"files", "write"}}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.query.Matches(tool); got != tc.want {
+				t.Errorf("Matches() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestListPromptsWithTagFilter(t *testing.T) {
+	c, m := SetupClientWithMockTransport(t, "2025-03-26")
+	defer c.Close()
+
+	promptsJSON := []byte(`{"jsonrpc":"2.0","id":0,"result":{"prompts":[
+		{"name":"review","description":"Review code","annotations":{"tags":["code"]}},
+		{"name":"summarize","description":"Summarize text"}
+	]}}`)
+	m.QueueConditionalResponse(promptsJSON, nil, func(req []byte) bool {
+		return isRequestMethod(req, "prompts/list")
+	})
+
+	prompts, err := c.ListPrompts(client.WithTagFilter("code"))
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+
+	if len(prompts) != 1 || prompts[0].Name != "review" {
+		t.Fatalf("Expected only the review prompt, got %+v", prompts)
+	}
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/client"
+	"github.com/localrivet/gomcp/server"
+)
+
+// freeAddress returns a local address that nothing listens on
+func freeAddress(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find a free port: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+// waitForListener waits until a server accepts connections at address
+func waitForListener(t *testing.T, address string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if conn, err := net.Dial("tcp", address); err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("Server at %s did not start", address)
+}
+
+func TestTransportFallbackSelectsWebsocket(t *testing.T) {
+	address := freeAddress(t)
+	s := server.NewServer("ws-only").AsWebsocket(address)
+	s.Tool("echo", "Echo a message", func(ctx *server.Context, args struct {
+		Message string `json:"message"`
+	}) (interface{}, error) {
+		return args.Message, nil
+	})
+	go s.Run()
+	defer s.Shutdown()
+	waitForListener(t, address)
+
+	// Streamable HTTP and SSE are probed first, and fail against a WebSocket server
+	c, err := client.NewClient("auto://"+address, client.WithConnectionTimeout(2*time.Second))
+	if err != nil {
+		t.Fatalf("Expected the WebSocket transport to be selected, got %v", err)
+	}
+	defer c.Close()
+
+	result, err := c.CallTool("echo", map[string]interface{}{"message": "hello"})
+	if err != nil {
+		t.Fatalf("Failed to call tool over the selected transport: %v", err)
+	}
+	if !strings.Contains(fmt.Sprint(result), "hello") {
+		t.Errorf("Expected echoed message, got %v", result)
+	}
+}
+
+func TestTransportFallbackReportsEveryFailure(t *testing.T) {
+	address := freeAddress(t)
+
+	_, err := client.NewClient("http://"+address,
+		client.WithTransportFallback(client.TransportStreamableHTTP, client.TransportWebsocket),
+		client.WithConnectionTimeout(time.Second),
+	)
+	if err == nil {
+		t.Fatal("Expected connecting to a closed port to fail")
+	}
+
+	for _, want := range []string{"no transport could connect", "http: ", "websocket: "} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+		}
+	}
+	if strings.Contains(err.Error(), "sse: ") {
+		t.Errorf("Expected only the listed transports to be probed, got %q", err.Error())
+	}
+}
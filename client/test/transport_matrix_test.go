@@ -0,0 +1,687 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/client"
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/localrivet/gomcp/server"
+	"github.com/localrivet/gomcp/transport/embedded"
+	"github.com/localrivet/gomcp/transport/mqtt"
+	"github.com/localrivet/gomcp/transport/nats"
+	"github.com/localrivet/gomcp/transport/stdio"
+)
+
+// The transport matrix runs the same scenarios against every transport, so that a
+// feature which works on one transport but not another fails here rather than in
+// production. New features should add a scenario and pass on every transport.
+//
+// NATS and MQTT need a broker and run when GOMCP_TEST_NATS_URL or GOMCP_TEST_MQTT_URL
+// is set, as in CI.
+
+// matrixScenarios are the scenarios run against every transport, in order
+var matrixScenarios = []struct {
+	name string
+	run  func(t *testing.T, m *matrixServer, c client.Client)
+}{
+	{"initialize", scenarioInitialize},
+	{"list", scenarioList},
+	{"call", scenarioCall},
+	{"errors", scenarioErrors},
+	{"progress", scenarioProgress},
+	{"request progress", scenarioRequestProgress},
+	{"cancel", scenarioCancel},
+	{"notifications", scenarioNotifications},
+	{"sampling stream", scenarioSamplingStream},
+	{"roots", scenarioRoots},
+	{"reconnect", scenarioReconnect},
+	{"progress isolation", scenarioProgressIsolation},
+}
+
+// multiClientScenarios are the scenarios that connect a second client
+var multiClientScenarios = map[string]bool{
+	"reconnect":          true,
+	"progress isolation": true,
+}
+
+// matrixTransport serves a server on one transport and connects clients to it
+type matrixTransport struct {
+	name string
+
+	// env names a variable that must be set for the transport to run
+	env string
+
+	// serve configures s for the transport and returns a function connecting a new
+	// client. It is called before the server starts; ready is called after.
+	serve func(t *testing.T, s server.Server) (connect func(options ...client.Option) (client.Client, error), ready func())
+
+	// singleClient marks transports that connect exactly one client, which skip
+	// the scenarios connecting a second one
+	singleClient bool
+}
+
+var matrixTransports = []matrixTransport{
+	{
+		name: "stdio",
+		serve: func(t *testing.T, s server.Server) (func(...client.Option) (client.Client, error), func()) {
+			clientIn, serverOut := io.Pipe()
+			serverIn, clientOut := io.Pipe()
+			tr := stdio.NewTransportWithIO(serverIn, serverOut)
+			tr.DisableProcessMonitoring()
+			s.GetServer().SetTransport(tr)
+
+			connect := func(options ...client.Option) (client.Client, error) {
+				options = append(options, client.WithStdio(client.WithStdioInput(clientIn), client.WithStdioOutput(clientOut)))
+				return client.NewClient("stdio-matrix", options...)
+			}
+			ready := func() {
+				// Unlike a process's pipes, these have no buffer, so writes fail once the
+				// client is gone rather than blocking the server's shutdown
+				t.Cleanup(func() {
+					clientIn.Close()
+					serverIn.Close()
+				})
+			}
+			return connect, ready
+		},
+		// A stdio server serves the one process that launched it
+		singleClient: true,
+	},
+	{
+		name: "embedded",
+		serve: func(t *testing.T, s server.Server) (func(...client.Option) (client.Client, error), func()) {
+			serverTransport, clientTransport := embedded.NewTransportPair()
+			s.AsEmbedded(serverTransport)
+			return func(options ...client.Option) (client.Client, error) {
+				return client.NewClient("embedded-matrix", append(options, client.WithEmbedded(clientTransport))...)
+			}, func() {}
+		},
+		// An embedded transport pair connects exactly one client
+		singleClient: true,
+	},
+	{
+		name: "http",
+		serve: func(t *testing.T, s server.Server) (func(...client.Option) (client.Client, error), func()) {
+			address := freeAddress(t)
+			s.AsHTTP(address)
+			return func(options ...client.Option) (client.Client, error) {
+				return client.NewClient("http://"+address+"/mcp", options...)
+			}, func() { waitForListener(t, address) }
+		},
+	},
+	{
+		name: "sse",
+		serve: func(t *testing.T, s server.Server) (func(...client.Option) (client.Client, error), func()) {
+			address := freeAddress(t)
+			s.AsSSE(address)
+			return func(options ...client.Option) (client.Client, error) {
+				// SSE clients default to the oldest protocol version, which does not
+				// stream sampling responses
+				options = append([]client.Option{client.WithProtocolVersion(mcp.Version20250326)}, options...)
+				return client.NewClient("", append(options, client.WithSSE("http://"+address))...)
+			}, func() { waitForListener(t, address) }
+		},
+	},
+	{
+		name: "websocket",
+		serve: func(t *testing.T, s server.Server) (func(...client.Option) (client.Client, error), func()) {
+			address := freeAddress(t)
+			s.AsWebsocket(address)
+			return func(options ...client.Option) (client.Client, error) {
+				return client.NewClient("ws://"+address, options...)
+			}, func() { waitForListener(t, address) }
+		},
+	},
+	{
+		name: "grpc",
+		serve: func(t *testing.T, s server.Server) (func(...client.Option) (client.Client, error), func()) {
+			address := freeAddress(t)
+			s.AsGRPC(address)
+			return func(options ...client.Option) (client.Client, error) {
+				return client.NewClient("grpc-matrix", append(options, client.WithGRPC(address))...)
+			}, func() { waitForListener(t, address) }
+		},
+	},
+	{
+		name: "unix",
+		serve: func(t *testing.T, s server.Server) (func(...client.Option) (client.Client, error), func()) {
+			// The unix transport runs in client mode for relative paths only, and socket
+			// paths are limited to about 100 bytes, so clients connect from the socket's
+			// directory
+			dir, err := os.MkdirTemp("", "gomcp")
+			if err != nil {
+				t.Fatalf("Failed to create socket directory: %v", err)
+			}
+			t.Cleanup(func() { os.RemoveAll(dir) })
+			t.Chdir(dir)
+			socketPath := filepath.Join(dir, "mcp.sock")
+			s.AsUnixSocket(socketPath)
+			return func(options ...client.Option) (client.Client, error) {
+				return client.NewClient("unix-matrix", append(options, client.WithUnixSocket("mcp.sock"))...)
+			}, func() { waitForSocket(t, socketPath) }
+		},
+	},
+	{
+		name: "nats",
+		env:  "GOMCP_TEST_NATS_URL",
+		serve: func(t *testing.T, s server.Server) (func(...client.Option) (client.Client, error), func()) {
+			url := os.Getenv("GOMCP_TEST_NATS_URL")
+			prefix := fmt.Sprintf("mcp-matrix-%d", time.Now().UnixNano())
+			s.AsNATS(url, nats.WithSubjectPrefix(prefix))
+			clients := 0
+			return func(options ...client.Option) (client.Client, error) {
+				clients++
+				return client.NewClient("nats-matrix", append(options, client.WithNATS(url,
+					client.WithNATSSubjectPrefix(prefix),
+					client.WithNATSClientID(fmt.Sprintf("client-%d", clients)),
+				))...)
+			}, func() {}
+		},
+	},
+	{
+		name: "mqtt",
+		env:  "GOMCP_TEST_MQTT_URL",
+		serve: func(t *testing.T, s server.Server) (func(...client.Option) (client.Client, error), func()) {
+			url := os.Getenv("GOMCP_TEST_MQTT_URL")
+			prefix := fmt.Sprintf("mcp-matrix-%d", time.Now().UnixNano())
+			s.AsMQTT(url, mqtt.WithTopicPrefix(prefix))
+			clients := 0
+			return func(options ...client.Option) (client.Client, error) {
+				clients++
+				return client.NewClient("mqtt-matrix", append(options, client.WithMQTT(url,
+					client.WithMQTTTopicPrefix(prefix),
+					client.WithMQTTClientID(fmt.Sprintf("client-%d", clients)),
+				))...)
+			}, func() {}
+		},
+	},
+}
+
+// matrixServer is a running server with the tools the scenarios use
+type matrixServer struct {
+	server  server.Server
+	connect func(options ...client.Option) (client.Client, error)
+
+	// cancelled receives the ID of every request the wait tool saw cancelled
+	cancelled chan string
+}
+
+func TestTransportMatrix(t *testing.T) {
+	for _, tr := range matrixTransports {
+		tr := tr
+		t.Run(tr.name, func(t *testing.T) {
+			if tr.env != "" && os.Getenv(tr.env) == "" {
+				t.Skipf("set %s to run against a broker", tr.env)
+			}
+
+			m := startMatrixServer(t, tr)
+			c, err := m.connect()
+			if err != nil {
+				t.Fatalf("Failed to connect: %v", err)
+			}
+			t.Cleanup(func() { c.Close() })
+
+			for _, scenario := range matrixScenarios {
+				t.Run(scenario.name, func(t *testing.T) {
+					if tr.singleClient && multiClientScenarios[scenario.name] {
+						t.Skip("the transport connects a single client")
+					}
+					scenario.run(t, m, c)
+				})
+			}
+		})
+	}
+}
+
+// startMatrixServer starts a server with the scenario tools on a transport
+func startMatrixServer(t *testing.T, tr matrixTransport) *matrixServer {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	m := &matrixServer{
+		server: server.NewServer("matrix-server",
+			server.WithLogger(logger),
+			server.WithSamplingProvider(matrixSamplingProvider),
+		),
+		cancelled: make(chan string, 1),
+	}
+
+	// The transport is set before the tools are registered, whose list_changed
+	// notifications are sent through it
+	connect, ready := tr.serve(t, m.server)
+	registerMatrixTools(m)
+	m.connect = func(options ...client.Option) (client.Client, error) {
+		options = append([]client.Option{
+			client.WithLogger(logger),
+			client.WithConnectionTimeout(5 * time.Second),
+			client.WithRequestTimeout(5 * time.Second),
+			client.WithInitializeRetry(10, 100*time.Millisecond),
+		}, options...)
+		return connect(options...)
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		if err := m.server.Serve(ctx); err != nil {
+			t.Logf("Server stopped: %v", err)
+		}
+	}()
+	t.Cleanup(func() {
+		stop()
+		select {
+		case <-served:
+		case <-time.After(10 * time.Second):
+			t.Error("Server did not shut down")
+		}
+	})
+	ready()
+	return m
+}
+
+// registerMatrixTools adds the tools the scenarios call
+func registerMatrixTools(m *matrixServer) {
+	m.server.Tool("echo", "Echo a message", func(ctx *server.Context, args struct {
+		Message string `json:"message"`
+	}) (interface{}, error) {
+		return args.Message, nil
+	})
+
+	m.server.Tool("count", "Report progress for each step", func(ctx *server.Context, args struct {
+		Steps int `json:"steps"`
+	}) (interface{}, error) {
+		ctx.CreateProgressToken()
+		total := float64(args.Steps)
+		for i := 1; i <= args.Steps; i++ {
+			if err := ctx.SendProgress(float64(i), &total, fmt.Sprintf("step %d", i)); err != nil {
+				return nil, err
+			}
+		}
+		return fmt.Sprintf("counted %d", args.Steps), nil
+	})
+
+	m.server.Tool("wait", "Wait until cancelled", func(ctx *server.Context, args struct{}) (interface{}, error) {
+		select {
+		case <-ctx.RegisterForCancellation():
+			m.cancelled <- ctx.RequestID
+			return nil, errors.New("cancelled")
+		case <-time.After(10 * time.Second):
+			return "not cancelled", nil
+		}
+	})
+
+	m.server.Tool("notify", "Send a custom notification", func(ctx *server.Context, args struct {
+		Value string `json:"value"`
+	}) (interface{}, error) {
+		if err := ctx.Notify("matrix/event", map[string]interface{}{"value": args.Value}); err != nil {
+			return nil, err
+		}
+		return "sent", nil
+	})
+
+	m.server.Tool("roots", "List the client's roots", func(ctx *server.Context, args struct{}) (interface{}, error) {
+		return strings.Join(ctx.Session.Roots(), ","), nil
+	})
+}
+
+// matrixSamplingProvider answers sampling requests by streaming back the text of
+// the last message
+func matrixSamplingProvider(ctx *server.Context, params server.SamplingCreateMessageParams, stream *server.SamplingStream) (*server.SamplingResponse, error) {
+	text := params.Messages[len(params.Messages)-1].Content.Text
+	if stream != nil {
+		if err := stream.Send(text); err != nil {
+			return nil, err
+		}
+	}
+	return &server.SamplingResponse{
+		Role:    "assistant",
+		Content: server.SamplingMessageContent{Type: "text", Text: text},
+	}, nil
+}
+
+func scenarioInitialize(t *testing.T, m *matrixServer, c client.Client) {
+	if !c.IsInitialized() {
+		t.Fatal("Expected the client to be initialized")
+	}
+	info := c.GetServerInfo()
+	if info == nil || info.Name != "matrix-server" {
+		t.Errorf("Expected server info for matrix-server, got %+v", info)
+	}
+	if !c.HasCapability("tools") {
+		t.Error("Expected the server to offer tools")
+	}
+}
+
+func scenarioList(t *testing.T, m *matrixServer, c client.Client) {
+	tools, err := c.ListTools()
+	if err != nil {
+		t.Fatalf("Failed to list tools: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, tool := range tools {
+		names[tool.Name] = true
+	}
+	for _, want := range []string{"echo", "count", "wait", "notify"} {
+		if !names[want] {
+			t.Errorf("Expected tool %q in %v", want, names)
+		}
+	}
+}
+
+func scenarioCall(t *testing.T, m *matrixServer, c client.Client) {
+	result, err := c.CallTool("echo", map[string]interface{}{"message": "hello matrix"})
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	if !strings.Contains(fmt.Sprint(result), "hello matrix") {
+		t.Errorf("Expected echoed message, got %v", result)
+	}
+}
+
+func scenarioErrors(t *testing.T, m *matrixServer, c client.Client) {
+	_, err := c.CallTool("missing", nil)
+	var serverErr *client.ServerError
+	if !errors.As(err, &serverErr) {
+		t.Fatalf("Expected a server error, got %v", err)
+	}
+	if serverErr.Reason != mcp.ReasonToolNotFound || serverErr.Hint == "" {
+		t.Errorf("Expected the reason and hint of an unknown tool, got %+v", serverErr)
+	}
+	if !strings.Contains(client.Diagnose(err), serverErr.Hint) {
+		t.Errorf("Expected the diagnosis to include the hint, got %q", client.Diagnose(err))
+	}
+}
+
+func scenarioProgress(t *testing.T, m *matrixServer, c client.Client) {
+	updates := make(chan client.ProgressNotification, 16)
+	c.OnProgress(func(progress client.ProgressNotification) {
+		updates <- progress
+	})
+
+	if _, err := c.CallTool("count", map[string]interface{}{"steps": 3}); err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+
+	// Updates may be coalesced, but the last one always arrives
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case progress := <-updates:
+			if progress.Progress == 3 {
+				return
+			}
+		case <-deadline:
+			t.Fatal("Expected the final progress notification")
+		}
+	}
+}
+
+func scenarioRequestProgress(t *testing.T, m *matrixServer, c client.Client) {
+	updates := make(chan client.ProgressNotification, 16)
+	result, err := c.CallTool("count", map[string]interface{}{"steps": 3},
+		client.WithProgress(func(progress client.ProgressNotification) {
+			updates <- progress
+		}))
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	if !strings.Contains(fmt.Sprint(result), "counted 3") {
+		t.Errorf("Expected the count, got %v", result)
+	}
+
+	// Progress arrives under the token the request was sent with, and the last update
+	// is never coalesced away
+	var token interface{}
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case progress := <-updates:
+			if token == nil {
+				token = progress.ProgressToken
+			} else if progress.ProgressToken != token {
+				t.Errorf("Expected every update under token %v, got %v", token, progress.ProgressToken)
+			}
+			if progress.Progress == 3 {
+				return
+			}
+		case <-deadline:
+			t.Fatal("Expected the final progress notification")
+		}
+	}
+}
+
+func scenarioProgressIsolation(t *testing.T, m *matrixServer, c client.Client) {
+	other, err := m.connect()
+	if err != nil {
+		t.Fatalf("Failed to connect a second client: %v", err)
+	}
+	defer other.Close()
+	leaked := make(chan client.ProgressNotification, 16)
+	other.OnProgress(func(progress client.ProgressNotification) {
+		leaked <- progress
+	})
+
+	received := make(chan client.ProgressNotification, 16)
+	if _, err := c.CallTool("count", map[string]interface{}{"steps": 3},
+		client.WithProgress(func(progress client.ProgressNotification) {
+			received <- progress
+		})); err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+
+	select {
+	case <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected progress on the client that made the request")
+	}
+
+	// A ping over the other client's connection is answered after anything sent to it
+	// earlier, so progress sent to it would have arrived by now
+	if err := other.Ping(); err != nil {
+		t.Fatalf("Failed to ping: %v", err)
+	}
+	select {
+	case progress := <-leaked:
+		t.Errorf("Expected no progress on the other client, got %+v", progress)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func scenarioCancel(t *testing.T, m *matrixServer, c client.Client) {
+	ids := make(chan int64, 1)
+	called := make(chan error, 1)
+	go func() {
+		_, err := c.CallTool("wait", map[string]interface{}{}, client.RequestIDOption{Callback: func(id int64) { ids <- id }})
+		called <- err
+	}()
+
+	var id int64
+	select {
+	case id = <-ids:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the request ID")
+	}
+
+	// Cancel once the request has reached its handler
+	time.Sleep(100 * time.Millisecond)
+	if err := c.CancelRequest(id); err != nil {
+		t.Fatalf("Failed to cancel request: %v", err)
+	}
+
+	select {
+	case err := <-called:
+		if !errors.Is(err, client.ErrRequestCancelled) {
+			t.Errorf("Expected ErrRequestCancelled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the call to return once cancelled")
+	}
+
+	select {
+	case requestID := <-m.cancelled:
+		if requestID != fmt.Sprint(id) {
+			t.Errorf("Expected request %d to be cancelled, got %s", id, requestID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the server to see the cancellation")
+	}
+}
+
+func scenarioNotifications(t *testing.T, m *matrixServer, c client.Client) {
+	events := make(chan string, 1)
+	c.OnNotification("matrix/event", func(params json.RawMessage) error {
+		var event struct {
+			Value string `json:"value"`
+		}
+		if err := json.Unmarshal(params, &event); err != nil {
+			return err
+		}
+		events <- event.Value
+		return nil
+	})
+
+	if _, err := c.CallTool("notify", map[string]interface{}{"value": "ping"}); err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+
+	select {
+	case value := <-events:
+		if value != "ping" {
+			t.Errorf("Expected notification value ping, got %s", value)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the custom notification")
+	}
+}
+
+func scenarioSamplingStream(t *testing.T, m *matrixServer, c client.Client) {
+	var chunks []string
+	var complete *client.SamplingResponse
+	opts := client.NewSamplingOptions(
+		[]client.SamplingMessage{client.CreateTextMessage("user", "stream these words back to the client")},
+		client.SamplingModelPreferences{},
+	).WithMaxTokens(50).WithChunkSize(10).WithStreaming(func(chunk *client.SamplingResponse) error {
+		if chunk.IsComplete {
+			complete = chunk
+		} else {
+			chunks = append(chunks, chunk.Content.Text)
+		}
+		return nil
+	})
+
+	response, err := c.RequestSampling(opts)
+	if err != nil {
+		t.Fatalf("Failed to request sampling: %v", err)
+	}
+	if response.Content.Text != "stream these words back to the client" {
+		t.Errorf("Expected the complete text, got %q", response.Content.Text)
+	}
+
+	// Chunks arrive in order and before the complete response
+	if len(chunks) != 4 || strings.Join(chunks, "") != "stream these words back to the client" {
+		t.Errorf("Expected the text in 4 chunks of at most 10 characters, got %q", chunks)
+	}
+	if complete == nil || complete.ChunkIndex != len(chunks) {
+		t.Errorf("Expected the complete response to count the chunks, got %+v", complete)
+	}
+}
+
+func scenarioRoots(t *testing.T, m *matrixServer, c client.Client) {
+	dir := t.TempDir()
+	if err := c.AddRoot(dir, "workspace"); err != nil {
+		t.Fatalf("Failed to add root: %v", err)
+	}
+	defer c.RemoveRoot(dir)
+
+	// The server fetches the roots again when told they changed
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		result, err := c.CallTool("roots", nil)
+		if err != nil {
+			t.Fatalf("Failed to call tool: %v", err)
+		}
+		if strings.Contains(fmt.Sprint(result), dir) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the server to see root %s, got %v", dir, result)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if err := c.RemoveRoot(dir); err != nil {
+		t.Fatalf("Failed to remove root: %v", err)
+	}
+	deadline = time.Now().Add(3 * time.Second)
+	for {
+		result, err := c.CallTool("roots", nil)
+		if err != nil {
+			t.Fatalf("Failed to call tool: %v", err)
+		}
+		if !strings.Contains(fmt.Sprint(result), dir) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the server to drop root %s, got %v", dir, result)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func scenarioReconnect(t *testing.T, m *matrixServer, c client.Client) {
+	first, err := m.connect()
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if _, err := first.CallTool("echo", map[string]interface{}{"message": "first"}); err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	if err := first.Close(); err != nil {
+		t.Errorf("Failed to close client: %v", err)
+	}
+
+	second, err := m.connect()
+	if err != nil {
+		t.Fatalf("Failed to reconnect: %v", err)
+	}
+	defer second.Close()
+
+	result, err := second.CallTool("echo", map[string]interface{}{"message": "second"})
+	if err != nil {
+		t.Fatalf("Failed to call tool after reconnecting: %v", err)
+	}
+	if !strings.Contains(fmt.Sprint(result), "second") {
+		t.Errorf("Expected echoed message, got %v", result)
+	}
+
+	// Clients connected before the reconnect are unaffected
+	if _, err := c.CallTool("echo", map[string]interface{}{"message": "still here"}); err != nil {
+		t.Errorf("Failed to call tool on the original client: %v", err)
+	}
+}
+
+// waitForSocket waits until a server listens on a unix socket
+func waitForSocket(t *testing.T, socketPath string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if conn, err := net.Dial("unix", socketPath); err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("Server at %s did not start", socketPath)
+}
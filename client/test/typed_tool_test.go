@@ -0,0 +1,180 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/localrivet/gomcp/client"
+	"github.com/localrivet/gomcp/util/schema"
+)
+
+type addArgs struct {
+	A float64 `json:"a"`
+	B float64 `json:"b"`
+}
+
+type addResult struct {
+	Sum float64 `json:"sum"`
+}
+
+// queueTypedToolResponses answers tools/list with an "add" tool and tools/call
+// with the given result
+func queueTypedToolResponses(t *testing.T, m *MockTransport, callResult map[string]interface{}) {
+	t.Helper()
+
+	toolsJSON, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      2,
+		"result": map[string]interface{}{
+			"tools": []interface{}{
+				map[string]interface{}{
+					"name": "add",
+					"inputSchema": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"a": map[string]interface{}{"type": "number", "minimum": 0},
+							"b": map[string]interface{}{"type": "number", "minimum": 0},
+						},
+						"required": []string{"a", "b"},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal tools response: %v", err)
+	}
+	m.QueueConditionalResponse(toolsJSON, nil, func(req []byte) bool {
+		return isRequestMethod(req, "tools/list")
+	})
+
+	queueToolCallResponse(t, m, callResult)
+}
+
+// queueToolCallResponse answers the next tools/call with the given result
+func queueToolCallResponse(t *testing.T, m *MockTransport, callResult map[string]interface{}) {
+	t.Helper()
+
+	callJSON, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 3, "result": callResult})
+	if err != nil {
+		t.Fatalf("Failed to marshal call response: %v", err)
+	}
+	m.QueueConditionalResponse(callJSON, nil, func(req []byte) bool {
+		return isRequestMethod(req, "tools/call")
+	})
+}
+
+func TestCallToolTyped(t *testing.T) {
+	c, m := SetupClientWithMockTransport(t, "2025-03-26")
+	defer c.Close()
+
+	queueTypedToolResponses(t, m, map[string]interface{}{
+		"content": []interface{}{map[string]interface{}{"type": "text", "text": `{"sum": 3}`}},
+	})
+
+	result, err := client.CallToolTyped[addArgs, addResult](c, "add", addArgs{A: 1, B: 2})
+	if err != nil {
+		t.Fatalf("CallToolTyped failed: %v", err)
+	}
+	if result.Sum != 3 {
+		t.Errorf("Expected sum 3, got %v", result.Sum)
+	}
+
+	// The typed request was sent as the tool's arguments
+	requests := m.GetRequestsByMethod("tools/call")
+	if len(requests) != 1 {
+		t.Fatalf("Expected one tools/call request, got %d", len(requests))
+	}
+	var sent struct {
+		Params struct {
+			Name      string  `json:"name"`
+			Arguments addArgs `json:"arguments"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(requests[0].Message, &sent); err != nil {
+		t.Fatalf("Failed to parse sent request: %v", err)
+	}
+	if sent.Params.Name != "add" || sent.Params.Arguments != (addArgs{A: 1, B: 2}) {
+		t.Errorf("Unexpected tools/call params: %+v", sent.Params)
+	}
+
+	// Text results can be taken as they are
+	queueToolCallResponse(t, m, map[string]interface{}{
+		"content": []interface{}{map[string]interface{}{"type": "text", "text": `{"sum": 3}`}},
+	})
+	text, err := client.CallToolTyped[addArgs, string](c, "add", addArgs{A: 1, B: 2})
+	if err != nil {
+		t.Fatalf("CallToolTyped failed: %v", err)
+	}
+	if text != `{"sum": 3}` {
+		t.Errorf("Expected the text content, got %q", text)
+	}
+
+	// The schema was fetched once and reused
+	if lists := m.GetRequestsByMethod("tools/list"); len(lists) != 1 {
+		t.Errorf("Expected one tools/list request, got %d", len(lists))
+	}
+}
+
+func TestCallToolTypedValidatesRequest(t *testing.T) {
+	c, m := SetupClientWithMockTransport(t, "2025-03-26")
+	defer c.Close()
+
+	queueTypedToolResponses(t, m, map[string]interface{}{
+		"content": []interface{}{map[string]interface{}{"type": "text", "text": `{"sum": 0}`}},
+	})
+
+	_, err := client.CallToolTyped[addArgs, addResult](c, "add", addArgs{A: -1, B: 2})
+	var violations schema.ValidationErrors
+	if !errors.As(err, &violations) {
+		t.Fatalf("Expected validation errors, got %v", err)
+	}
+	if len(violations) != 1 || violations[0].Field != "a" || violations[0].Constraint != "minimum" {
+		t.Errorf("Unexpected violations: %+v", violations)
+	}
+
+	// Invalid requests are not sent
+	if calls := m.GetRequestsByMethod("tools/call"); len(calls) != 0 {
+		t.Errorf("Expected no tools/call request, got %d", len(calls))
+	}
+
+	// Unknown tools are reported
+	if _, err := client.CallToolTyped[addArgs, addResult](c, "subtract", addArgs{}); err == nil {
+		t.Error("Expected an error for an unknown tool")
+	}
+}
+
+func TestCallToolTypedResults(t *testing.T) {
+	c, m := SetupClientWithMockTransport(t, "2025-03-26")
+	defer c.Close()
+
+	// Structured content is preferred over text
+	queueTypedToolResponses(t, m, map[string]interface{}{
+		"content":           []interface{}{map[string]interface{}{"type": "text", "text": "3"}},
+		"structuredContent": map[string]interface{}{"sum": 3},
+	})
+	result, err := client.CallToolTyped[addArgs, addResult](c, "add", addArgs{A: 1, B: 2})
+	if err != nil {
+		t.Fatalf("CallToolTyped failed: %v", err)
+	}
+	if result.Sum != 3 {
+		t.Errorf("Expected sum 3, got %v", result.Sum)
+	}
+
+	// Error results become ToolErrors
+	c2, m2 := SetupClientWithMockTransport(t, "2025-03-26")
+	defer c2.Close()
+	queueTypedToolResponses(t, m2, map[string]interface{}{
+		"content": []interface{}{map[string]interface{}{"type": "text", "text": "overflow"}},
+		"isError": true,
+	})
+	_, err = client.CallToolTyped[addArgs, addResult](c2, "add", addArgs{A: 1, B: 2})
+	var toolErr *client.ToolError
+	if !errors.As(err, &toolErr) {
+		t.Fatalf("Expected a ToolError, got %v", err)
+	}
+	if toolErr.Tool != "add" || toolErr.Message != "overflow" {
+		t.Errorf("Unexpected tool error: %+v", toolErr)
+	}
+}
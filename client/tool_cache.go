@@ -0,0 +1,158 @@
+package client
+
+import (
+	"container/list"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// toolResultCache is an LRU cache of tool call results with a fixed time-to-live.
+// Entries are keyed on the tool name and its canonicalized arguments.
+type toolResultCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // front = most recently used
+}
+
+// toolCacheEntry is a single cached tool result. The result is kept encoded, so
+// every hit decodes a copy that callers can modify freely.
+type toolCacheEntry struct {
+	key       string
+	result    json.RawMessage
+	expiresAt time.Time
+}
+
+// WithToolResultCache enables caching of tool call results on the client.
+//
+// Successful results are cached for ttl, keyed on the tool name and its arguments
+// (argument order does not matter). At most maxEntries results are kept; the least
+// recently used entry is evicted first. The whole cache is invalidated when the
+// server sends notifications/tools/list_changed.
+//
+// Only enable this for servers whose tools are idempotent; individual calls can
+// skip the cache with WithoutToolCache().
+func WithToolResultCache(ttl time.Duration, maxEntries int) Option {
+	return func(c *clientImpl) {
+		if ttl <= 0 || maxEntries <= 0 {
+			c.toolCache = nil
+			return
+		}
+		c.toolCache = newToolResultCache(ttl, maxEntries)
+	}
+}
+
+// NoCacheOption is a request option that bypasses the tool result cache.
+type NoCacheOption struct{}
+
+func (NoCacheOption) apply() {}
+
+// WithoutToolCache makes a CallTool request go to the server even if a cached
+// result exists. The fresh result still replaces the cached one.
+func WithoutToolCache() NoCacheOption {
+	return NoCacheOption{}
+}
+
+// newToolResultCache creates an empty tool result cache.
+func newToolResultCache(ttl time.Duration, maxEntries int) *toolResultCache {
+	return &toolResultCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// toolCacheKey builds the cache key for a tool call.
+// encoding/json writes map keys in sorted order, which canonicalizes the arguments.
+func toolCacheKey(name string, args map[string]interface{}) (string, bool) {
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	return name + "\x00" + string(argsJSON), true
+}
+
+// get returns the cached result for key if present and not expired.
+func (tc *toolResultCache) get(key string) (interface{}, bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	elem, exists := tc.entries[key]
+	if !exists {
+		return nil, false
+	}
+
+	entry := elem.Value.(*toolCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		tc.order.Remove(elem)
+		delete(tc.entries, key)
+		return nil, false
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(entry.result, &result); err != nil {
+		return nil, false
+	}
+	tc.order.MoveToFront(elem)
+	return result, true
+}
+
+// put stores a result, evicting the least recently used entries if the cache is full.
+// Results that cannot be encoded are not cached.
+func (tc *toolResultCache) put(key string, result interface{}) {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	expiresAt := time.Now().Add(tc.ttl)
+	if elem, exists := tc.entries[key]; exists {
+		entry := elem.Value.(*toolCacheEntry)
+		entry.result = encoded
+		entry.expiresAt = expiresAt
+		tc.order.MoveToFront(elem)
+		return
+	}
+
+	tc.entries[key] = tc.order.PushFront(&toolCacheEntry{key: key, result: encoded, expiresAt: expiresAt})
+
+	for tc.order.Len() > tc.maxEntries {
+		oldest := tc.order.Back()
+		tc.order.Remove(oldest)
+		delete(tc.entries, oldest.Value.(*toolCacheEntry).key)
+	}
+}
+
+// clear removes every cached result.
+func (tc *toolResultCache) clear() {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.entries = make(map[string]*list.Element)
+	tc.order.Init()
+}
+
+// isToolErrorResult reports whether a tools/call result carries isError: true.
+// Such results are never cached.
+func isToolErrorResult(result interface{}) bool {
+	if resultMap, ok := result.(map[string]interface{}); ok {
+		if isError, ok := resultMap["isError"].(bool); ok {
+			return isError
+		}
+	}
+	return false
+}
+
+// ClearToolCache discards all cached tool results.
+func (c *clientImpl) ClearToolCache() {
+	if c.toolCache != nil {
+		c.toolCache.clear()
+	}
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ToolResult is the result of a tools/call request in typed form. CallTool returns
+// results as decoded JSON; ParseToolResult converts them.
+type ToolResult struct {
+	Content []ContentItem `json:"content"`
+
+	// StructuredContent is the tool's result as a JSON object. Servers of the draft
+	// protocol send it alongside a text fallback in Content when a tool returns a
+	// struct or a map.
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+
+	IsError bool `json:"isError,omitempty"`
+}
+
+// ParseToolResult converts a result returned by CallTool into a ToolResult.
+//
+// Example:
+//
+//	raw, err := c.CallTool("forecast", map[string]interface{}{"city": "Oslo"})
+//	if err != nil {
+//	    return err
+//	}
+//	result, err := client.ParseToolResult(raw)
+//	if err != nil {
+//	    return err
+//	}
+//	var forecast Forecast
+//	if err := result.Decode(&forecast); err != nil {
+//	    return err
+//	}
+func ParseToolResult(result interface{}) (*ToolResult, error) {
+	if _, ok := result.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("tool result must be an object, got %T", result)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tool result: %w", err)
+	}
+	var parsed ToolResult
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode tool result: %w", err)
+	}
+	if string(parsed.StructuredContent) == "null" {
+		parsed.StructuredContent = nil
+	}
+	return &parsed, nil
+}
+
+// HasStructuredContent reports whether the server sent structured content.
+func (r *ToolResult) HasStructuredContent() bool {
+	return len(r.StructuredContent) > 0
+}
+
+// Text returns the text of the first text content item, or an empty string.
+func (r *ToolResult) Text() string {
+	for _, item := range r.Content {
+		if item.Type == "text" {
+			return item.Text
+		}
+	}
+	return ""
+}
+
+// MediaContent is an image or audio content item of a tool result. Items that
+// carry their data have Data and MimeType set; items that refer to the media by
+// URL, as older servers send them, have URL set.
+type MediaContent struct {
+	Data     []byte
+	MimeType string
+	URL      string
+}
+
+// Images returns the image content items of the result, with their data decoded.
+func (r *ToolResult) Images() ([]MediaContent, error) {
+	return r.media("image")
+}
+
+// Audio returns the audio content items of the result, with their data decoded.
+// Servers send audio to clients of protocol version 2025-03-26 and later only.
+func (r *ToolResult) Audio() ([]MediaContent, error) {
+	return r.media("audio")
+}
+
+// media returns the content items of the given type with their data decoded
+func (r *ToolResult) media(contentType string) ([]MediaContent, error) {
+	var media []MediaContent
+	for _, item := range r.Content {
+		if item.Type != contentType {
+			continue
+		}
+		content := MediaContent{MimeType: item.MimeType, URL: item.URL}
+		if item.ImageURL != "" {
+			content.URL = item.ImageURL
+		}
+		if encoded, ok := item.Data.(string); ok {
+			data, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("%s content has invalid data: %w", contentType, err)
+			}
+			content.Data = data
+		}
+		media = append(media, content)
+	}
+	return media, nil
+}
+
+// Resources returns the resources embedded in the result. Their Bytes method
+// returns their contents.
+func (r *ToolResult) Resources() []EmbeddedResource {
+	var resources []EmbeddedResource
+	for _, item := range r.Content {
+		if item.Type == "resource" && item.Resource != nil {
+			resources = append(resources, *item.Resource)
+		}
+	}
+	return resources
+}
+
+// Decode decodes the tool's result into v: from the structured content if the
+// server sent it, and otherwise from the JSON in the first text content item, as
+// servers of older protocol versions send it. String targets receive the text
+// as is.
+func (r *ToolResult) Decode(v interface{}) error {
+	if r.HasStructuredContent() {
+		return json.Unmarshal(r.StructuredContent, v)
+	}
+
+	text := r.Text()
+	if target := reflect.ValueOf(v); target.Kind() == reflect.Ptr && target.Elem().Kind() == reflect.String {
+		target.Elem().SetString(text)
+		return nil
+	}
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("tool result has no content to decode")
+	}
+	return json.Unmarshal([]byte(text), v)
+}
+
+// DecodeToolResult decodes a result returned by CallTool into a T, preferring its
+// structured content as ToolResult.Decode does. A result with isError set is
+// returned as a *ToolError.
+//
+// Example:
+//
+//	raw, err := c.CallTool("forecast", map[string]interface{}{"city": "Oslo"})
+//	if err != nil {
+//	    return err
+//	}
+//	forecast, err := client.DecodeToolResult[Forecast](raw)
+func DecodeToolResult[T any](result interface{}) (T, error) {
+	var value T
+	parsed, err := ParseToolResult(result)
+	if err != nil {
+		return value, err
+	}
+	if parsed.IsError {
+		return value, &ToolError{Message: parsed.Text()}
+	}
+	if err := parsed.Decode(&value); err != nil {
+		return value, fmt.Errorf("failed to decode tool result: %w", err)
+	}
+	return value, nil
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"strings"
+
+	"github.com/localrivet/gomcp/mcp"
+)
+
+// ToolQuery describes which tools SearchTools should return.
+// All non-empty fields must match for a tool to be included.
+type ToolQuery struct {
+	// Text is matched case-insensitively against tool names and descriptions.
+	// Every whitespace-separated term must appear in one or the other.
+	Text string
+
+	// NamePrefix restricts results to tools whose name starts with this prefix
+	NamePrefix string
+
+	// Tags restricts results to tools carrying all of these tags
+	Tags []string
+}
+
+// SearchTools retrieves the tools matching a query.
+func (c *clientImpl) SearchTools(query ToolQuery) ([]Tool, error) {
+	filter := map[string]interface{}{}
+	if query.NamePrefix != "" {
+		filter["namePrefix"] = query.NamePrefix
+	}
+	if len(query.Tags) > 0 {
+		filter["tags"] = query.Tags
+	}
+
+	tools, err := c.listTools(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]Tool, 0, len(tools))
+	for _, tool := range tools {
+		if query.Matches(tool) {
+			matches = append(matches, tool)
+		}
+	}
+
+	return matches, nil
+}
+
+// Matches reports whether a tool satisfies the query.
+func (q ToolQuery) Matches(tool Tool) bool {
+	if q.NamePrefix != "" && !strings.HasPrefix(tool.Name, q.NamePrefix) {
+		return false
+	}
+
+	if !mcp.HasAllTags(tool.Annotations, q.Tags) {
+		return false
+	}
+
+	if q.Text == "" {
+		return true
+	}
+
+	haystack := strings.ToLower(tool.Name + " " + tool.Description)
+	for _, term := range strings.Fields(strings.ToLower(q.Text)) {
+		if !strings.Contains(haystack, term) {
+			return false
+		}
+	}
+
+	return true
+}
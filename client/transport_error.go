@@ -0,0 +1,226 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// TransportErrorKind classifies why a transport failed.
+type TransportErrorKind int
+
+const (
+	// TransportErrorUnknown is a failure that could not be classified
+	TransportErrorUnknown TransportErrorKind = iota
+
+	// TransportErrorDNS means the server's host name could not be resolved
+	TransportErrorDNS
+
+	// TransportErrorConnectionRefused means nothing accepted the connection
+	TransportErrorConnectionRefused
+
+	// TransportErrorTLS means the TLS handshake or certificate verification failed
+	TransportErrorTLS
+
+	// TransportErrorProtocol means the server sent something that is not MCP JSON-RPC
+	TransportErrorProtocol
+
+	// TransportErrorTimeout means the server did not answer in time
+	TransportErrorTimeout
+
+	// TransportErrorClosed means the connection was closed
+	TransportErrorClosed
+
+	// TransportErrorProcessExited means the server process of a stdio connection exited
+	TransportErrorProcessExited
+)
+
+// String returns a short description of the kind
+func (k TransportErrorKind) String() string {
+	switch k {
+	case TransportErrorDNS:
+		return "DNS failure"
+	case TransportErrorConnectionRefused:
+		return "connection refused"
+	case TransportErrorTLS:
+		return "TLS failure"
+	case TransportErrorProtocol:
+		return "protocol error"
+	case TransportErrorTimeout:
+		return "timeout"
+	case TransportErrorClosed:
+		return "connection closed"
+	case TransportErrorProcessExited:
+		return "server process exited"
+	default:
+		return "transport failure"
+	}
+}
+
+// TransportError is a classified transport failure. Use errors.As to inspect it and
+// Diagnose to explain it to users.
+type TransportError struct {
+	// Kind classifies the failure
+	Kind TransportErrorKind
+
+	// Op is the operation that failed, such as "connect" or a request method
+	Op string
+
+	// Target is the server URL, or the server name for registry servers
+	Target string
+
+	// Err is the underlying error
+	Err error
+
+	// ExitCode is the exit code of a server process that exited
+	ExitCode int
+
+	// Stderr holds the last output a server process wrote to stderr before exiting
+	Stderr string
+}
+
+// maxStderrErrorLines is how many of the last stderr lines of an exited server
+// process are included in the error message
+const maxStderrErrorLines = 3
+
+// Error implements the error interface. Errors of exited server processes include
+// the last lines the process wrote to stderr.
+func (e *TransportError) Error() string {
+	message := fmt.Sprintf("%s: %s", e.Op, e.Kind)
+	if e.Err != nil {
+		message = fmt.Sprintf("%s: %v", message, e.Err)
+	}
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		lines := strings.Split(stderr, "\n")
+		if len(lines) > maxStderrErrorLines {
+			lines = lines[len(lines)-maxStderrErrorLines:]
+		}
+		message = fmt.Sprintf("%s, stderr: %s", message, strings.Join(lines, "\n"))
+	}
+	return message
+}
+
+// Unwrap returns the underlying error
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// Diagnose returns an actionable explanation of the failure.
+func (e *TransportError) Diagnose() string {
+	target := e.Target
+	if target == "" {
+		target = "the server"
+	}
+
+	switch e.Kind {
+	case TransportErrorDNS:
+		return fmt.Sprintf("cannot resolve the host of %s: check the URL for typos and that DNS is reachable", target)
+	case TransportErrorConnectionRefused:
+		return fmt.Sprintf("connection to %s was refused: check that the server is running and listening on that address", target)
+	case TransportErrorTLS:
+		return fmt.Sprintf("TLS handshake with %s failed: check the server certificate and trusted CAs, and that the URL scheme (https/wss) matches the server", target)
+	case TransportErrorProtocol:
+		return fmt.Sprintf("%s sent a message that is not valid MCP JSON-RPC: check that the URL points at an MCP endpoint, and that stdio servers log to stderr rather than stdout", target)
+	case TransportErrorTimeout:
+		return fmt.Sprintf("%s did not respond in time during %s: the server may be overloaded, or the timeout too short", target, e.Op)
+	case TransportErrorClosed:
+		return fmt.Sprintf("connection to %s was closed during %s: the server may have stopped or dropped the connection", target, e.Op)
+	case TransportErrorProcessExited:
+		message := fmt.Sprintf("server process %s exited with code %d", target, e.ExitCode)
+		if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+			message += ", stderr: " + stderr
+		}
+		return message
+	default:
+		return fmt.Sprintf("%s failed for %s: %v", e.Op, target, e.Err)
+	}
+}
+
+// Diagnose returns an actionable explanation of a client error. Error responses
+// include the server's hint on fixing them, and other errors that do not come
+// from a transport are returned as their message.
+//
+// Example:
+//
+//	if err := c.Connect(); err != nil {
+//	    log.Fatal(client.Diagnose(err))
+//	}
+func Diagnose(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return transportErr.Diagnose()
+	}
+	var serverErr *ServerError
+	if errors.As(err, &serverErr) {
+		return serverErr.Diagnose()
+	}
+	if kind := classifyTransportError(err); kind != TransportErrorUnknown {
+		return (&TransportError{Kind: kind, Op: "request", Err: err}).Diagnose()
+	}
+	return err.Error()
+}
+
+// newTransportError classifies a transport failure. Errors that are already
+// classified, and request cancellations, are returned unchanged.
+func newTransportError(op, target string, err error) error {
+	if err == nil || errors.Is(err, ErrRequestCancelled) || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return err
+	}
+
+	return &TransportError{Kind: classifyTransportError(err), Op: op, Target: target, Err: err}
+}
+
+// classifyTransportError determines the kind of a transport failure
+func classifyTransportError(err error) TransportErrorKind {
+	var (
+		dnsErr         *net.DNSError
+		netErr         net.Error
+		syntaxErr      *json.SyntaxError
+		typeErr        *json.UnmarshalTypeError
+		recordErr      tls.RecordHeaderError
+		alertErr       tls.AlertError
+		certErr        *tls.CertificateVerificationError
+		unknownAuthErr x509.UnknownAuthorityError
+		hostnameErr    x509.HostnameError
+		invalidCertErr x509.CertificateInvalidError
+	)
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return TransportErrorDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return TransportErrorConnectionRefused
+	case errors.As(err, &recordErr), errors.As(err, &alertErr), errors.As(err, &certErr),
+		errors.As(err, &unknownAuthErr), errors.As(err, &hostnameErr), errors.As(err, &invalidCertErr),
+		strings.Contains(err.Error(), "tls: "):
+		return TransportErrorTLS
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return TransportErrorTimeout
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.ErrClosedPipe),
+		errors.Is(err, net.ErrClosed), errors.Is(err, os.ErrClosed),
+		errors.Is(err, syscall.EPIPE), errors.Is(err, syscall.ECONNRESET):
+		return TransportErrorClosed
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return TransportErrorProtocol
+	default:
+		return TransportErrorUnknown
+	}
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestClassifyTransportError(t *testing.T) {
+	var syntaxErr error = &json.SyntaxError{Offset: 1}
+
+	tests := []struct {
+		name string
+		err  error
+		want TransportErrorKind
+	}{
+		{"dns", &net.DNSError{Err: "no such host", Name: "mcp.invalid", IsNotFound: true}, TransportErrorDNS},
+		{"refused", &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, TransportErrorConnectionRefused},
+		{"tls", fmt.Errorf("handshake: %w", x509.UnknownAuthorityError{}), TransportErrorTLS},
+		{"timeout", fmt.Errorf("request: %w", context.DeadlineExceeded), TransportErrorTimeout},
+		{"closed", fmt.Errorf("read: %w", io.EOF), TransportErrorClosed},
+		{"broken pipe", syscall.EPIPE, TransportErrorClosed},
+		{"protocol", fmt.Errorf("failed to parse response: %w", syntaxErr), TransportErrorProtocol},
+		{"unknown", errors.New("something else"), TransportErrorUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyTransportError(tt.err); got != tt.want {
+				t.Errorf("classifyTransportError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTransportError(t *testing.T) {
+	if err := newTransportError("connect", "http://localhost", nil); err != nil {
+		t.Errorf("Expected nil for a nil error, got %v", err)
+	}
+	if err := newTransportError("tools/call", "", ErrRequestCancelled); err != ErrRequestCancelled {
+		t.Errorf("Expected cancellations to pass through, got %v", err)
+	}
+
+	err := newTransportError("connect", "http://localhost:1", syscall.ECONNREFUSED)
+	var transportErr *TransportError
+	if !errors.As(err, &transportErr) || transportErr.Kind != TransportErrorConnectionRefused {
+		t.Fatalf("Expected a connection refused TransportError, got %v", err)
+	}
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Error("Expected the TransportError to unwrap to its cause")
+	}
+
+	// Wrapping again keeps the original classification
+	wrapped := newTransportError("initialize", "other", fmt.Errorf("initialize: %w", err))
+	if !errors.As(wrapped, &transportErr) || transportErr.Target != "http://localhost:1" {
+		t.Errorf("Expected the original TransportError to be kept, got %v", wrapped)
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	if got := Diagnose(nil); got != "" {
+		t.Errorf("Diagnose(nil) = %q, want empty", got)
+	}
+
+	exited := &TransportError{
+		Kind:     TransportErrorProcessExited,
+		Op:       "initialize",
+		Target:   "weather",
+		ExitCode: 1,
+		Stderr:   "config not found\n",
+	}
+	want := "server process weather exited with code 1, stderr: config not found"
+	if got := Diagnose(fmt.Errorf("failed to create client: %w", exited)); got != want {
+		t.Errorf("Diagnose() = %q, want %q", got, want)
+	}
+
+	refused := &TransportError{Kind: TransportErrorConnectionRefused, Op: "connect", Target: "http://localhost:1"}
+	if got := Diagnose(refused); !strings.Contains(got, "check that the server is running") {
+		t.Errorf("Expected an actionable diagnosis, got %q", got)
+	}
+
+	// Unwrapped errors are classified on the fly
+	if got := Diagnose(context.DeadlineExceeded); !strings.Contains(got, "did not respond in time") {
+		t.Errorf("Expected a timeout diagnosis, got %q", got)
+	}
+	if got := Diagnose(errors.New("tool not found")); got != "tool not found" {
+		t.Errorf("Expected other errors to be returned as their message, got %q", got)
+	}
+}
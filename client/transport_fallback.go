@@ -0,0 +1,142 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// TransportKind names a transport that automatic transport selection can probe.
+type TransportKind string
+
+const (
+	// TransportStreamableHTTP is the streamable HTTP transport, at the URL's path or
+	// the default /mcp endpoint
+	TransportStreamableHTTP TransportKind = "http"
+
+	// TransportSSE is the legacy HTTP+SSE transport
+	TransportSSE TransportKind = "sse"
+
+	// TransportWebsocket is the WebSocket transport
+	TransportWebsocket TransportKind = "websocket"
+)
+
+// DefaultTransportFallback is the order in which transports are probed for "auto://"
+// URLs: the current streamable HTTP transport first, then older transports.
+var DefaultTransportFallback = []TransportKind{TransportStreamableHTTP, TransportSSE, TransportWebsocket}
+
+// WithTransportFallback sets the transports probed, in order, until one connects
+// and completes the initialize handshake. It applies to "auto://" URLs, and turns
+// http(s), ws(s) and sse URLs into a probe of the same host.
+//
+// Use "auto://host:port/path" to probe plain transports, and "auto+https://..." for
+// TLS. Without this option, auto URLs probe DefaultTransportFallback.
+//
+// Example:
+//
+//	c, err := client.NewClient("auto://localhost:8080",
+//	    client.WithTransportFallback(client.TransportStreamableHTTP, client.TransportWebsocket))
+func WithTransportFallback(kinds ...TransportKind) Option {
+	return func(c *clientImpl) {
+		c.transportFallback = append([]TransportKind(nil), kinds...)
+	}
+}
+
+// usesTransportFallback reports whether Connect probes transports
+func (c *clientImpl) usesTransportFallback() bool {
+	return c.transportFallback != nil || strings.HasPrefix(c.url, "auto://") || strings.HasPrefix(c.url, "auto+https://")
+}
+
+// transportCandidateURL returns the URL a transport is probed at
+func transportCandidateURL(rawURL string, kind TransportKind) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %q: %w", rawURL, err)
+	}
+
+	secure := false
+	switch parsed.Scheme {
+	case "auto", "http", "ws", "sse":
+	case "auto+https", "https", "wss":
+		secure = true
+	default:
+		return "", fmt.Errorf("transport fallback does not support %s URLs", parsed.Scheme)
+	}
+
+	candidate := *parsed
+	switch kind {
+	case TransportStreamableHTTP:
+		candidate.Scheme = "http"
+		if candidate.Path == "" || candidate.Path == "/" {
+			candidate.Path = "/mcp"
+		}
+	case TransportSSE:
+		candidate.Scheme = "http"
+	case TransportWebsocket:
+		candidate.Scheme = "ws"
+	default:
+		return "", fmt.Errorf("unknown transport %q", kind)
+	}
+	if secure {
+		candidate.Scheme += "s"
+	}
+	return candidate.String(), nil
+}
+
+// connectFallback probes the candidate transports in order, keeping the first one
+// that connects and completes the initialize handshake
+func (c *clientImpl) connectFallback() error {
+	kinds := c.transportFallback
+	if kinds == nil {
+		kinds = DefaultTransportFallback
+	}
+	if len(kinds) == 0 {
+		return errors.New("no transports to probe")
+	}
+
+	// Transport options adjust the client, so each probe starts from the same settings
+	requestTimeout := c.requestTimeout
+	connectionTimeout := c.connectionTimeout
+	negotiatedVersion := c.negotiatedVersion
+
+	var failures []error
+	for _, kind := range kinds {
+		candidateURL, err := transportCandidateURL(c.url, kind)
+		if err != nil {
+			return err
+		}
+
+		c.requestTimeout = requestTimeout
+		c.connectionTimeout = connectionTimeout
+		c.negotiatedVersion = negotiatedVersion
+
+		switch kind {
+		case TransportStreamableHTTP:
+			WithHTTP(candidateURL)(c)
+		case TransportSSE:
+			WithSSE(candidateURL)(c)
+		case TransportWebsocket:
+			WithWebsocket(candidateURL)(c)
+		}
+		c.requestTimeout = requestTimeout
+		c.connectionTimeout = connectionTimeout
+
+		err = c.connectTransport()
+		if err == nil {
+			if c.logger != nil {
+				c.logger.Info("selected transport", "transport", kind, "url", candidateURL)
+			}
+			return nil
+		}
+
+		if c.logger != nil {
+			c.logger.Debug("transport probe failed", "transport", kind, "url", candidateURL, "error", err)
+		}
+		failures = append(failures, fmt.Errorf("%s: %w", kind, err))
+		c.transport = nil
+	}
+
+	c.negotiatedVersion = negotiatedVersion
+	return fmt.Errorf("no transport could connect to %s: %w", c.url, errors.Join(failures...))
+}
@@ -0,0 +1,37 @@
+package client
+
+import "testing"
+
+func TestTransportCandidateURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		kind TransportKind
+		want string
+	}{
+		{"auto://localhost:8080", TransportStreamableHTTP, "http://localhost:8080/mcp"},
+		{"auto://localhost:8080", TransportSSE, "http://localhost:8080"},
+		{"auto://localhost:8080", TransportWebsocket, "ws://localhost:8080"},
+		{"auto://localhost:8080/api", TransportStreamableHTTP, "http://localhost:8080/api"},
+		{"auto+https://example.com", TransportStreamableHTTP, "https://example.com/mcp"},
+		{"auto+https://example.com", TransportWebsocket, "wss://example.com"},
+		{"wss://example.com/ws", TransportSSE, "https://example.com/ws"},
+	}
+
+	for _, tt := range tests {
+		got, err := transportCandidateURL(tt.url, tt.kind)
+		if err != nil {
+			t.Errorf("transportCandidateURL(%q, %s) failed: %v", tt.url, tt.kind, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("transportCandidateURL(%q, %s) = %q, want %q", tt.url, tt.kind, got, tt.want)
+		}
+	}
+
+	if _, err := transportCandidateURL("unix:///tmp/mcp.sock", TransportWebsocket); err == nil {
+		t.Error("Expected unix URLs to be rejected")
+	}
+	if _, err := transportCandidateURL("auto://localhost", TransportKind("carrier-pigeon")); err == nil {
+		t.Error("Expected unknown transports to be rejected")
+	}
+}
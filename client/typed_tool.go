@@ -0,0 +1,159 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/localrivet/gomcp/util/schema"
+)
+
+// ToolError is returned by CallToolTyped and DecodeToolResult when the tool ran
+// but reported an error (a result with isError set).
+type ToolError struct {
+	// Tool is the name of the tool that failed, if known
+	Tool string
+
+	// Message is the text content of the error result
+	Message string
+}
+
+// Error returns the tool's error message.
+func (e *ToolError) Error() string {
+	if e.Tool == "" {
+		return fmt.Sprintf("tool failed: %s", e.Message)
+	}
+	return fmt.Sprintf("tool %s failed: %s", e.Tool, e.Message)
+}
+
+// CallToolTyped calls a tool with a typed request and decodes its result into a
+// typed response, so callers need not build and pick apart maps.
+//
+// The request is marshaled to JSON using its json tags and validated against the
+// input schema the server published for the tool in tools/list; an invalid
+// request is not sent and the returned error wraps schema.ValidationErrors. The
+// response is decoded from the result's structuredContent if present, otherwise
+// from the JSON in its first text content item. If Resp is a string, the text is
+// returned as is. A result with isError set is returned as a *ToolError.
+//
+// Example:
+//
+//	type AddArgs struct {
+//	    A float64 `json:"a"`
+//	    B float64 `json:"b"`
+//	}
+//	sum, err := client.CallToolTyped[AddArgs, float64](c, "add", AddArgs{A: 1, B: 2})
+func CallToolTyped[Req, Resp any](c Client, name string, req Req, opts ...RequestOption) (Resp, error) {
+	var resp Resp
+
+	args, err := toolArguments(req)
+	if err != nil {
+		return resp, fmt.Errorf("failed to encode arguments for tool %s: %w", name, err)
+	}
+
+	inputSchema, err := toolInputSchema(c, name)
+	if err != nil {
+		return resp, err
+	}
+	if err := schema.ValidateArgs(inputSchema, args); err != nil {
+		return resp, fmt.Errorf("invalid arguments for tool %s: %w", name, err)
+	}
+
+	result, err := c.CallTool(name, args, opts...)
+	if err != nil {
+		return resp, err
+	}
+
+	if err := decodeToolResult(name, result, &resp); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// toolArguments converts a typed request into the arguments object of a tool call
+func toolArguments(req interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" {
+		return map[string]interface{}{}, nil
+	}
+	var args map[string]interface{}
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, fmt.Errorf("request must encode to a JSON object: %w", err)
+	}
+	return args, nil
+}
+
+// decodeToolResult decodes a tools/call result into resp
+func decodeToolResult(name string, result interface{}, resp interface{}) error {
+	parsed, err := ParseToolResult(result)
+	if err != nil {
+		return fmt.Errorf("invalid response format from tool %s: %w", name, err)
+	}
+	if parsed.IsError {
+		return &ToolError{Tool: name, Message: parsed.Text()}
+	}
+	if err := parsed.Decode(resp); err != nil {
+		return fmt.Errorf("failed to decode result of tool %s: %w", name, err)
+	}
+	return nil
+}
+
+// toolSchemaCache holds the input schemas the server published in tools/list.
+// It is filled on first use and cleared by notifications/tools/list_changed.
+type toolSchemaCache struct {
+	mu      sync.Mutex
+	schemas map[string]map[string]interface{}
+}
+
+// clear discards the cached schemas
+func (tc *toolSchemaCache) clear() {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.schemas = nil
+}
+
+// toolInputSchema returns the input schema the server published for a tool,
+// using the client's schema cache when c is a client created by NewClient
+func toolInputSchema(c Client, name string) (map[string]interface{}, error) {
+	if impl, ok := c.(*clientImpl); ok {
+		return impl.toolInputSchema(name)
+	}
+	tools, err := c.ListTools()
+	if err != nil {
+		return nil, err
+	}
+	for _, tool := range tools {
+		if tool.Name == name {
+			return tool.InputSchema, nil
+		}
+	}
+	return nil, fmt.Errorf("tool not found: %s", name)
+}
+
+// toolInputSchema returns a tool's input schema, listing the tools again if the
+// tool is not among the cached ones in case it was added since
+func (c *clientImpl) toolInputSchema(name string) (map[string]interface{}, error) {
+	c.toolSchemas.mu.Lock()
+	defer c.toolSchemas.mu.Unlock()
+
+	if inputSchema, ok := c.toolSchemas.schemas[name]; ok {
+		return inputSchema, nil
+	}
+
+	tools, err := c.ListTools()
+	if err != nil {
+		return nil, err
+	}
+	c.toolSchemas.schemas = make(map[string]map[string]interface{}, len(tools))
+	for _, tool := range tools {
+		c.toolSchemas.schemas[tool.Name] = tool.InputSchema
+	}
+
+	if inputSchema, ok := c.toolSchemas.schemas[name]; ok {
+		return inputSchema, nil
+	}
+	return nil, fmt.Errorf("tool not found: %s", name)
+}
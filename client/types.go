@@ -10,48 +10,30 @@ type Root struct {
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
-// ClientCapabilities represents the capabilities supported by this client.
-type ClientCapabilities struct {
-	Roots        RootsCapability        `json:"roots,omitempty"`
-	Sampling     map[string]interface{} `json:"sampling,omitempty"`
-	Experimental map[string]interface{} `json:"experimental,omitempty"`
-}
+// ClientCapabilities is an alias to the shared mcp.ClientCapabilities type.
+type ClientCapabilities = mcp.ClientCapabilities
 
-// RootsCapability represents the client's roots capability.
-type RootsCapability struct {
-	ListChanged bool `json:"listChanged"`
-}
+// RootsCapability is an alias to the shared mcp.RootsCapability type.
+type RootsCapability = mcp.RootsCapability
 
-// ServerCapabilities represents the capabilities declared by the MCP server during initialization.
-type ServerCapabilities struct {
-	Logging      *LoggingCapability     `json:"logging,omitempty"`
-	Prompts      *PromptsCapability     `json:"prompts,omitempty"`
-	Resources    *ResourcesCapability   `json:"resources,omitempty"`
-	Tools        *ToolsCapability       `json:"tools,omitempty"`
-	Experimental map[string]interface{} `json:"experimental,omitempty"`
-}
+// ServerCapabilities is an alias to the shared mcp.ServerCapabilities type, which
+// the server marshals its initialize response from.
+type ServerCapabilities = mcp.ServerCapabilities
 
-// LoggingCapability represents the server's logging capability.
-// Currently defined as an empty object in all MCP specification versions.
-type LoggingCapability struct {
-	// No fields defined in specification - empty object
-}
+// LoggingCapability is an alias to the shared mcp.LoggingCapability type.
+type LoggingCapability = mcp.LoggingCapability
 
-// PromptsCapability represents the server's prompt template capability.
-type PromptsCapability struct {
-	ListChanged bool `json:"listChanged,omitempty"`
-}
+// PromptsCapability is an alias to the shared mcp.PromptsCapability type.
+type PromptsCapability = mcp.PromptsCapability
 
-// ResourcesCapability represents the server's resource capability.
-type ResourcesCapability struct {
-	Subscribe   bool `json:"subscribe,omitempty"`
-	ListChanged bool `json:"listChanged,omitempty"`
-}
+// ResourcesCapability is an alias to the shared mcp.ResourcesCapability type.
+type ResourcesCapability = mcp.ResourcesCapability
 
-// ToolsCapability represents the server's tool capability.
-type ToolsCapability struct {
-	ListChanged bool `json:"listChanged,omitempty"`
-}
+// ToolsCapability is an alias to the shared mcp.ToolsCapability type.
+type ToolsCapability = mcp.ToolsCapability
+
+// CompletionsCapability is an alias to the shared mcp.CompletionsCapability type.
+type CompletionsCapability = mcp.CompletionsCapability
 
 // ServerInfo represents information about the MCP server.
 type ServerInfo struct {
@@ -62,41 +44,23 @@ type ServerInfo struct {
 // Tool is an alias to the shared mcp.Tool type for backward compatibility.
 type Tool = mcp.Tool
 
-// Resource represents a server resource that can be accessed via the MCP protocol.
-type Resource struct {
-	URI         string                 `json:"uri"`
-	Name        string                 `json:"name"`
-	Description string                 `json:"description,omitempty"`
-	MimeType    string                 `json:"mimeType,omitempty"`
-	Size        *int64                 `json:"size,omitempty"`
-	Annotations map[string]interface{} `json:"annotations,omitempty"`
-}
+// Resource is an alias to the shared mcp.Resource type.
+type Resource = mcp.Resource
 
-// Prompt represents a server prompt template that can be used to generate messages.
-type Prompt struct {
-	Name        string           `json:"name"`
-	Description string           `json:"description,omitempty"`
-	Arguments   []PromptArgument `json:"arguments,omitempty"`
-}
+// ResourceTemplate is an alias to the shared mcp.ResourceTemplate type.
+type ResourceTemplate = mcp.ResourceTemplate
 
-// PromptArgument represents a parameter for a prompt template.
-type PromptArgument struct {
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	Required    bool   `json:"required,omitempty"`
-}
+// Prompt is an alias to the shared mcp.Prompt type.
+type Prompt = mcp.Prompt
 
-// PromptMessage represents a rendered message from a prompt template.
-type PromptMessage struct {
-	Role    string        `json:"role"`
-	Content PromptContent `json:"content"`
-}
+// PromptArgument is an alias to the shared mcp.PromptArgument type.
+type PromptArgument = mcp.PromptArgument
 
-// PromptContent represents the content of a prompt message.
-type PromptContent struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
-}
+// PromptMessage is an alias to the shared mcp.PromptMessage type.
+type PromptMessage = mcp.PromptMessage
+
+// PromptContent is an alias to the shared mcp.PromptContent type.
+type PromptContent = mcp.PromptContent
 
 // PromptResponse represents the response from a prompt request.
 // This provides concrete types instead of interface{} for better type safety.
@@ -105,27 +69,14 @@ type PromptResponse struct {
 	Messages    []PromptMessage `json:"messages"`
 }
 
-// ContentItem represents a content item in a resource response.
-type ContentItem struct {
-	Type     string      `json:"type"`
-	Text     string      `json:"text,omitempty"`
-	ImageURL string      `json:"imageUrl,omitempty"`
-	AltText  string      `json:"altText,omitempty"`
-	URL      string      `json:"url,omitempty"`
-	Title    string      `json:"title,omitempty"`
-	Blob     string      `json:"blob,omitempty"`
-	MimeType string      `json:"mimeType,omitempty"`
-	Data     interface{} `json:"data,omitempty"`
-	Filename string      `json:"filename,omitempty"`
-}
+// ContentItem is an alias to the shared mcp.ContentItem type.
+type ContentItem = mcp.ContentItem
 
-// ResourceContent represents a single resource item (2025-03-26 format).
-type ResourceContent struct {
-	URI      string                 `json:"uri"`
-	Text     string                 `json:"text,omitempty"`
-	Content  []ContentItem          `json:"content"`
-	Metadata map[string]interface{} `json:"metadata,omitempty"`
-}
+// EmbeddedResource is an alias to the shared mcp.EmbeddedResource type.
+type EmbeddedResource = mcp.EmbeddedResource
+
+// ResourceContent is an alias to the shared mcp.ResourceContent type.
+type ResourceContent = mcp.ResourceContent
 
 // ResourceResponse represents the actual response from a resource request.
 // This matches the MCP protocol format exactly - no wrapper.
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/localrivet/gomcp/transport/unix"
@@ -126,11 +127,14 @@ func WithUnixSocket(socketPath string, options ...UnixSocketOption) Option {
 
 // unixTransportWrapper wraps the unix transport to add client-specific functionality
 type unixTransportWrapper struct {
-	transport           *unix.Transport
-	config              *unixConfig
-	reconnectCount      int
-	reconnecting        bool
-	notificationHandler func(method string, params []byte)
+	transport *unix.Transport
+	config    *unixConfig
+	responses *responseRouter
+
+	// mu guards the reconnection state, which concurrent writes share
+	mu             sync.Mutex
+	reconnectCount int
+	reconnecting   bool
 }
 
 // wrapUnixTransport wraps a unix transport with client-specific functionality
@@ -138,12 +142,28 @@ func wrapUnixTransport(transport *unix.Transport, config *unixConfig) Transport
 	return &unixTransportWrapper{
 		transport: transport,
 		config:    config,
+		responses: newResponseRouter(),
 	}
 }
 
 // Connect establishes a connection to the server
 func (w *unixTransportWrapper) Connect() error {
-	return w.transport.Initialize()
+	if err := w.transport.Initialize(); err != nil {
+		return err
+	}
+	go w.readLoop()
+	return nil
+}
+
+// readLoop routes the messages read from the connection until it closes
+func (w *unixTransportWrapper) readLoop() {
+	for {
+		message, err := w.transport.Receive()
+		if err != nil {
+			return
+		}
+		w.responses.deliver(message)
+	}
 }
 
 // ConnectWithContext establishes a connection to the server with context
@@ -159,30 +179,37 @@ func (w *unixTransportWrapper) Disconnect() error {
 
 // Send sends a message to the server and waits for a response
 func (w *unixTransportWrapper) Send(message []byte) ([]byte, error) {
-	// Send the message
+	ctx := context.Background()
+	if w.config.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.config.timeout)
+		defer cancel()
+	}
+	return w.SendWithContext(ctx, message)
+}
+
+// SendWithContext sends a message with context for timeout/cancellation
+func (w *unixTransportWrapper) SendWithContext(ctx context.Context, message []byte) ([]byte, error) {
+	return w.responses.send(ctx, message, w.write)
+}
+
+// write sends a message, reconnecting first if the connection was lost and
+// reconnection is enabled
+func (w *unixTransportWrapper) write(message []byte) error {
 	err := w.transport.Send(message)
 	if err != nil {
-		if w.config.reconnect && !w.reconnecting {
-			if w.tryReconnect() {
-				// Try again after successful reconnection
-				return w.Send(message)
-			}
+		if w.config.reconnect && w.tryReconnect() {
+			// Try again after successful reconnection
+			return w.write(message)
 		}
-		return nil, fmt.Errorf("failed to send message: %w", err)
+		return fmt.Errorf("failed to send message: %w", err)
 	}
 
 	// Reset reconnect count on successful send
+	w.mu.Lock()
 	w.reconnectCount = 0
-
-	// Receive the response
-	return w.transport.Receive()
-}
-
-// SendWithContext sends a message with context for timeout/cancellation
-func (w *unixTransportWrapper) SendWithContext(ctx context.Context, message []byte) ([]byte, error) {
-	// Unix transport doesn't support context directly, so just call normal Send
-	// A future implementation could add proper context support
-	return w.Send(message)
+	w.mu.Unlock()
+	return nil
 }
 
 // SetRequestTimeout sets the default timeout for request operations
@@ -197,17 +224,24 @@ func (w *unixTransportWrapper) SetConnectionTimeout(timeout time.Duration) {
 
 // RegisterNotificationHandler registers a handler for server-initiated messages
 func (w *unixTransportWrapper) RegisterNotificationHandler(handler func(method string, params []byte)) {
-	w.notificationHandler = handler
+	w.responses.setNotificationHandler(handler)
 }
 
-// tryReconnect attempts to reconnect to the server
+// tryReconnect attempts to reconnect to the server. Only one write reconnects at
+// a time; the others fail while it does.
 func (w *unixTransportWrapper) tryReconnect() bool {
-	if w.reconnectCount >= w.config.maxRetries {
+	w.mu.Lock()
+	if w.reconnecting || w.reconnectCount >= w.config.maxRetries {
+		w.mu.Unlock()
 		return false
 	}
-
 	w.reconnecting = true
-	defer func() { w.reconnecting = false }()
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.reconnecting = false
+		w.mu.Unlock()
+	}()
 
 	// Disconnect first
 	_ = w.transport.Stop()
@@ -218,9 +252,12 @@ func (w *unixTransportWrapper) tryReconnect() bool {
 	// Try to reconnect
 	err := w.transport.Initialize()
 	if err != nil {
+		w.mu.Lock()
 		w.reconnectCount++
+		w.mu.Unlock()
 		return false
 	}
 
+	go w.readLoop()
 	return true
 }
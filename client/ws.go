@@ -10,10 +10,10 @@ import (
 
 // WSTransport wraps a ws.Transport to implement the client.Transport interface
 type WSTransport struct {
-	transport     *ws.Transport
-	notifyHandler func(method string, params []byte)
-	reqTimeout    time.Duration
-	connTimeout   time.Duration
+	transport   *ws.Transport
+	responses   *responseRouter
+	reqTimeout  time.Duration
+	connTimeout time.Duration
 }
 
 // Connect establishes a connection to the server
@@ -21,7 +21,22 @@ func (t *WSTransport) Connect() error {
 	if err := t.transport.Initialize(); err != nil {
 		return fmt.Errorf("failed to initialize WebSocket transport: %w", err)
 	}
-	return t.transport.Start()
+	if err := t.transport.Start(); err != nil {
+		return err
+	}
+	go t.readLoop()
+	return nil
+}
+
+// readLoop routes the messages read from the connection until it closes
+func (t *WSTransport) readLoop() {
+	for {
+		message, err := t.transport.Receive()
+		if err != nil {
+			return
+		}
+		t.responses.deliver(message)
+	}
 }
 
 // ConnectWithContext establishes a connection to the server with context
@@ -42,70 +57,18 @@ func (t *WSTransport) Disconnect() error {
 
 // Send sends a message to the server and waits for a response
 func (t *WSTransport) Send(message []byte) ([]byte, error) {
-	if err := t.transport.Send(message); err != nil {
-		return nil, err
-	}
-
-	// Set up a timeout context for receiving the response
 	ctx := context.Background()
 	if t.reqTimeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, t.reqTimeout)
 		defer cancel()
 	}
-
-	// Create a separate goroutine to handle the response
-	responseCh := make(chan []byte, 1)
-	errorCh := make(chan error, 1)
-
-	go func() {
-		resp, err := t.transport.Receive()
-		if err != nil {
-			errorCh <- err
-			return
-		}
-		responseCh <- resp
-	}()
-
-	// Wait for response or timeout
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case err := <-errorCh:
-		return nil, err
-	case resp := <-responseCh:
-		return resp, nil
-	}
+	return t.SendWithContext(ctx, message)
 }
 
 // SendWithContext sends a message with context for timeout/cancellation
 func (t *WSTransport) SendWithContext(ctx context.Context, message []byte) ([]byte, error) {
-	if err := t.transport.Send(message); err != nil {
-		return nil, err
-	}
-
-	// Create a separate goroutine to handle the response
-	responseCh := make(chan []byte, 1)
-	errorCh := make(chan error, 1)
-
-	go func() {
-		resp, err := t.transport.Receive()
-		if err != nil {
-			errorCh <- err
-			return
-		}
-		responseCh <- resp
-	}()
-
-	// Wait for response or context cancellation
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case err := <-errorCh:
-		return nil, err
-	case resp := <-responseCh:
-		return resp, nil
-	}
+	return t.responses.send(ctx, message, t.transport.Send)
 }
 
 // SetRequestTimeout sets the default timeout for request operations
@@ -120,9 +83,7 @@ func (t *WSTransport) SetConnectionTimeout(timeout time.Duration) {
 
 // RegisterNotificationHandler registers a handler for server-initiated messages
 func (t *WSTransport) RegisterNotificationHandler(handler func(method string, params []byte)) {
-	t.notifyHandler = handler
-	// The WebSocket transport doesn't have a direct SetNotificationHandler method
-	// We would need to implement the notification handling via message parsing
+	t.responses.setNotificationHandler(handler)
 }
 
 // WithWebsocket returns a client configuration option that uses WebSocket transport.
@@ -130,17 +91,19 @@ func (t *WSTransport) RegisterNotificationHandler(handler func(method string, pa
 //
 // Parameters:
 //   - url: The WebSocket server URL to connect to (e.g., "ws://localhost:8080/ws")
+//   - options: Optional ws.Option values for TLS, compression, keepalive and message limits
 //
 // Returns:
 //   - A client configuration option
-func WithWebsocket(url string) Option {
+func WithWebsocket(url string, options ...ws.Option) Option {
 	return func(c *clientImpl) {
 		// Create the WebSocket transport
-		wsTransport := ws.NewTransport(url)
+		wsTransport := ws.NewTransport(url, options...)
 
 		// Wrap it with our adapter
 		transport := &WSTransport{
 			transport:   wsTransport,
+			responses:   newResponseRouter(),
 			reqTimeout:  c.requestTimeout,
 			connTimeout: c.connectionTimeout,
 		}
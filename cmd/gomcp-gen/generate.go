@@ -0,0 +1,425 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/localrivet/gomcp/client"
+	"github.com/localrivet/gomcp/mcp"
+)
+
+// initialisms are name parts written in upper case in Go identifiers
+var initialisms = map[string]bool{
+	"API": true, "CPU": true, "CSV": true, "DB": true, "DNS": true, "HTML": true,
+	"HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "SQL": true,
+	"TCP": true, "TLS": true, "UDP": true, "UI": true, "URI": true, "URL": true,
+	"UUID": true, "XML": true,
+}
+
+// server is what the generator reads from an MCP server
+type server struct {
+	Name      string
+	Tools     []client.Tool
+	Resources []client.Resource
+	Prompts   []client.Prompt
+}
+
+// generator builds the source of a typed client package
+type generator struct {
+	pkg    string
+	server server
+
+	// decls holds the type declarations, nested types before the types using them
+	decls []string
+
+	// names holds the package-level identifiers and Client methods in use
+	names map[string]bool
+}
+
+// generate returns the formatted source of a package named pkg with a typed
+// client for the tools, static resources and prompts of srv
+func generate(pkg string, srv server) ([]byte, error) {
+	g := &generator{
+		pkg:    pkg,
+		server: srv,
+		names:  map[string]bool{"Client": true, "New": true},
+	}
+
+	var methods bytes.Buffer
+	tools := append([]client.Tool(nil), srv.Tools...)
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	for _, tool := range tools {
+		g.toolMethod(&methods, tool)
+	}
+
+	resources := append([]client.Resource(nil), srv.Resources...)
+	sort.Slice(resources, func(i, j int) bool { return resources[i].URI < resources[j].URI })
+	for _, resource := range resources {
+		// Templated resources need parameters the listing does not describe
+		if strings.Contains(resource.URI, "{") {
+			continue
+		}
+		g.resourceMethod(&methods, resource)
+	}
+
+	prompts := append([]client.Prompt(nil), srv.Prompts...)
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
+	for _, prompt := range prompts {
+		g.promptMethod(&methods, prompt)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by gomcp-gen from the %s MCP server. DO NOT EDIT.\n\n", srv.Name)
+	fmt.Fprintf(&out, "// Package %s is a typed client for the %s MCP server.\n", pkg, srv.Name)
+	fmt.Fprintf(&out, "package %s\n\n", pkg)
+	fmt.Fprintf(&out, "import \"github.com/localrivet/gomcp/client\"\n\n")
+	fmt.Fprintf(&out, "// Client calls the tools, resources and prompts of the %s server with typed\n", srv.Name)
+	fmt.Fprintf(&out, "// arguments and results.\n")
+	fmt.Fprintf(&out, "type Client struct {\n\tconn client.Client\n}\n\n")
+	fmt.Fprintf(&out, "// New returns a Client that calls the server over a connected client.\n")
+	fmt.Fprintf(&out, "func New(conn client.Client) *Client {\n\treturn &Client{conn: conn}\n}\n\n")
+	for _, decl := range g.decls {
+		out.WriteString(decl)
+		out.WriteString("\n")
+	}
+	out.Write(methods.Bytes())
+
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return source, nil
+}
+
+// toolMethod writes the method calling a tool, declaring its argument and result types
+func (g *generator) toolMethod(w *bytes.Buffer, tool client.Tool) {
+	method := g.unique(goName(tool.Name))
+
+	argsType := g.unique(method + "Args")
+	g.structType(argsType, tool.InputSchema, fmt.Sprintf("%s are the arguments of the %s tool.", argsType, tool.Name))
+
+	// Tools without an output schema return text
+	resultType := "string"
+	if len(tool.OutputSchema) > 0 {
+		resultType = g.goType(tool.OutputSchema, method+"Result",
+			fmt.Sprintf("%sResult is the result of the %s tool.", method, tool.Name))
+	}
+
+	writeDoc(w, fmt.Sprintf("%s calls the %s tool.", method, tool.Name), tool.Description)
+	writeExamples(w, tool.Examples())
+	fmt.Fprintf(w, "func (c *Client) %s(args %s, opts ...client.RequestOption) (%s, error) {\n", method, argsType, resultType)
+	fmt.Fprintf(w, "\treturn client.CallToolTyped[%s, %s](c.conn, %q, args, opts...)\n}\n\n", argsType, resultType, tool.Name)
+}
+
+// resourceMethod writes the method reading a static resource
+func (g *generator) resourceMethod(w *bytes.Buffer, resource client.Resource) {
+	name := resource.Name
+	if name == "" {
+		name = resource.URI
+	}
+	method := g.unique("Read" + goName(name))
+
+	writeDoc(w, fmt.Sprintf("%s reads the %s resource.", method, resource.URI), resource.Description)
+	fmt.Fprintf(w, "func (c *Client) %s(opts ...client.RequestOption) (*client.ResourceResponse, error) {\n", method)
+	fmt.Fprintf(w, "\treturn c.conn.GetResource(%q, opts...)\n}\n\n", resource.URI)
+}
+
+// promptMethod writes the method getting a prompt, declaring its argument type
+func (g *generator) promptMethod(w *bytes.Buffer, prompt client.Prompt) {
+	method := g.unique(goName(prompt.Name) + "Prompt")
+
+	writeDoc(w, fmt.Sprintf("%s gets the %s prompt.", method, prompt.Name), prompt.Description)
+	if len(prompt.Arguments) == 0 {
+		fmt.Fprintf(w, "func (c *Client) %s(opts ...client.RequestOption) (*client.PromptResponse, error) {\n", method)
+		fmt.Fprintf(w, "\treturn c.conn.GetPrompt(%q, nil, opts...)\n}\n\n", prompt.Name)
+		return
+	}
+
+	argsType := g.unique(method + "Args")
+	var decl bytes.Buffer
+	writeDoc(&decl, fmt.Sprintf("%s are the arguments of the %s prompt.", argsType, prompt.Name), "")
+	fmt.Fprintf(&decl, "type %s struct {\n", argsType)
+	fields := make([]string, len(prompt.Arguments))
+	used := make(map[string]bool)
+	for i, arg := range prompt.Arguments {
+		fields[i] = uniqueIn(used, goName(arg.Name))
+		writeFieldDoc(&decl, arg.Description, nil, arg.Required)
+		fmt.Fprintf(&decl, "\t%s string `json:%q`\n", fields[i], jsonTag(arg.Name, arg.Required))
+	}
+	decl.WriteString("}\n")
+	g.decls = append(g.decls, decl.String())
+
+	fmt.Fprintf(w, "func (c *Client) %s(args %s, opts ...client.RequestOption) (*client.PromptResponse, error) {\n", method, argsType)
+	w.WriteString("\tvariables := map[string]interface{}{}\n")
+	for i, arg := range prompt.Arguments {
+		if arg.Required {
+			fmt.Fprintf(w, "\tvariables[%q] = args.%s\n", arg.Name, fields[i])
+			continue
+		}
+		fmt.Fprintf(w, "\tif args.%s != \"\" {\n\t\tvariables[%q] = args.%s\n\t}\n", fields[i], arg.Name, fields[i])
+	}
+	fmt.Fprintf(w, "\treturn c.conn.GetPrompt(%q, variables, opts...)\n}\n\n", prompt.Name)
+}
+
+// goType returns the Go type for a JSON schema, declaring a struct named name for
+// objects with properties
+func (g *generator) goType(schema map[string]interface{}, name, doc string) string {
+	switch schemaType(schema) {
+	case "string":
+		return "string"
+	case "integer":
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			return "[]" + g.goType(items, name+"Item", "")
+		}
+		return "[]interface{}"
+	case "object":
+		if properties, _ := schema["properties"].(map[string]interface{}); len(properties) > 0 {
+			name = g.unique(name)
+			g.structType(name, schema, doc)
+			return name
+		}
+		if values, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+			return "map[string]" + g.goType(values, name+"Value", "")
+		}
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+// structType declares a struct with a field for each property of an object schema
+func (g *generator) structType(name string, schema map[string]interface{}, doc string) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	required := make(map[string]bool)
+	switch names := schema["required"].(type) {
+	case []interface{}:
+		for _, n := range names {
+			if s, ok := n.(string); ok {
+				required[s] = true
+			}
+		}
+	case []string:
+		for _, n := range names {
+			required[n] = true
+		}
+	}
+
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var decl bytes.Buffer
+	if doc == "" {
+		doc = fmt.Sprintf("%s is generated from a JSON schema.", name)
+	}
+	writeDoc(&decl, doc, description(schema))
+	if len(keys) == 0 {
+		fmt.Fprintf(&decl, "type %s struct{}\n", name)
+		g.decls = append(g.decls, decl.String())
+		return
+	}
+	fmt.Fprintf(&decl, "type %s struct {\n", name)
+	used := make(map[string]bool)
+	for _, key := range keys {
+		property, _ := properties[key].(map[string]interface{})
+		field := uniqueIn(used, goName(key))
+		fieldType := g.goType(property, name+field, "")
+
+		// Optional scalars and structs are pointers so that zero values can be sent
+		if !required[key] && needsPointer(property, fieldType) {
+			fieldType = "*" + fieldType
+		}
+
+		writeFieldDoc(&decl, description(property), property["enum"], required[key])
+		fmt.Fprintf(&decl, "\t%s %s `json:%q`\n", field, fieldType, jsonTag(key, required[key]))
+	}
+	decl.WriteString("}\n")
+	g.decls = append(g.decls, decl.String())
+}
+
+// unique returns name, or name with a number appended if it is already in use
+func (g *generator) unique(name string) string {
+	return uniqueIn(g.names, name)
+}
+
+// uniqueIn returns name, or name with a number appended if used has it, and marks it used
+func uniqueIn(used map[string]bool, name string) string {
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		candidate = name + strconv.Itoa(i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// schemaType returns the JSON type of a schema, ignoring "null" in type unions
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		var types []string
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				types = append(types, s)
+			}
+		}
+		if len(types) == 1 {
+			return types[0]
+		}
+		return ""
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	return ""
+}
+
+// needsPointer reports whether an optional property of the given Go type is a pointer
+func needsPointer(schema map[string]interface{}, goType string) bool {
+	switch schemaType(schema) {
+	case "integer", "number", "boolean":
+		return true
+	case "object":
+		return !strings.HasPrefix(goType, "map[")
+	}
+	return false
+}
+
+// description returns the description of a schema
+func description(schema map[string]interface{}) string {
+	desc, _ := schema["description"].(string)
+	return desc
+}
+
+// jsonTag returns the json struct tag value for a property
+func jsonTag(key string, required bool) string {
+	if required {
+		return key
+	}
+	return key + ",omitempty"
+}
+
+// writeDoc writes a doc comment made of a summary line and a description
+func writeDoc(w *bytes.Buffer, summary, desc string) {
+	fmt.Fprintf(w, "// %s\n", summary)
+	if desc = strings.TrimSpace(desc); desc != "" {
+		w.WriteString("//\n")
+		writeCommentLines(w, "", desc)
+	}
+}
+
+// writeExamples continues a tool's doc comment with its examples, as JSON
+func writeExamples(w *bytes.Buffer, examples []mcp.ToolExample) {
+	for _, example := range examples {
+		args, err := json.Marshal(example.Input)
+		if err != nil {
+			continue
+		}
+		w.WriteString("//\n")
+		if desc := strings.TrimSpace(example.Description); desc != "" {
+			writeCommentLines(w, "", "Example: "+desc)
+		} else {
+			w.WriteString("// Example:\n")
+		}
+		w.WriteString("//\n")
+		fmt.Fprintf(w, "//\targs:   %s\n", args)
+		if example.Output != nil {
+			if result, err := json.Marshal(example.Output); err == nil {
+				fmt.Fprintf(w, "//\tresult: %s\n", result)
+			}
+		}
+	}
+}
+
+// writeFieldDoc writes the comment of a struct field
+func writeFieldDoc(w *bytes.Buffer, desc string, enum interface{}, required bool) {
+	if desc = strings.TrimSpace(desc); desc != "" {
+		writeCommentLines(w, "\t", desc)
+	}
+	if values, ok := enum.([]interface{}); ok && len(values) > 0 {
+		allowed := make([]string, len(values))
+		for i, v := range values {
+			allowed[i] = fmt.Sprint(v)
+		}
+		fmt.Fprintf(w, "\t// One of: %s.\n", strings.Join(allowed, ", "))
+	}
+	if required {
+		w.WriteString("\t// Required.\n")
+	}
+}
+
+// writeCommentLines writes text as comment lines with the given indentation
+func writeCommentLines(w *bytes.Buffer, indent, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if line == "" {
+			fmt.Fprintf(w, "%s//\n", indent)
+			continue
+		}
+		fmt.Fprintf(w, "%s// %s\n", indent, line)
+	}
+}
+
+// goName converts a tool, prompt or property name such as "get_weather",
+// "list-files" or "userId" to an exported Go identifier
+func goName(name string) string {
+	var parts []string
+	var part []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(part) > 0 {
+				parts = append(parts, string(part))
+				part = nil
+			}
+			continue
+		case unicode.IsUpper(r) && len(part) > 0:
+			// Split camelCase and the end of an acronym followed by a word
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				parts = append(parts, string(part))
+				part = nil
+			}
+		}
+		part = append(part, r)
+	}
+	if len(part) > 0 {
+		parts = append(parts, string(part))
+	}
+
+	var b strings.Builder
+	for _, p := range parts {
+		if upper := strings.ToUpper(p); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(strings.ToLower(p))
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+
+	result := b.String()
+	if result == "" {
+		return "X"
+	}
+	if unicode.IsDigit([]rune(result)[0]) {
+		return "X" + result
+	}
+	return result
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/localrivet/gomcp/client"
+)
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"get_weather":  "GetWeather",
+		"list-files":   "ListFiles",
+		"userId":       "UserID",
+		"HTTPServer":   "HTTPServer",
+		"db.query":     "DBQuery",
+		"fetch_url":    "FetchURL",
+		"2fa":          "X2fa",
+		"":             "X",
+		"already_Done": "AlreadyDone",
+	}
+	for name, want := range tests {
+		if got := goName(name); got != want {
+			t.Errorf("goName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	srv := server{
+		Name: "weather",
+		Tools: []client.Tool{
+			{
+				Name:        "get_forecast",
+				Description: "Returns the forecast for a city.",
+				InputSchema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"city":  map[string]interface{}{"type": "string", "description": "Name of the city"},
+						"days":  map[string]interface{}{"type": "integer"},
+						"units": map[string]interface{}{"type": "string", "enum": []interface{}{"metric", "imperial"}},
+						"location": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"lat": map[string]interface{}{"type": "number"},
+								"lon": map[string]interface{}{"type": "number"},
+							},
+							"required": []interface{}{"lat", "lon"},
+						},
+						"tags": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+					},
+					"required": []interface{}{"city"},
+				},
+				OutputSchema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"summary": map[string]interface{}{"type": "string"},
+						"highs":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "number"}},
+					},
+				},
+			},
+			{
+				Name:        "ping",
+				InputSchema: map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+				Annotations: map[string]interface{}{
+					"examples": []interface{}{
+						map[string]interface{}{"description": "Check the server is up", "input": map[string]interface{}{}, "output": "pong"},
+					},
+				},
+			},
+		},
+		Resources: []client.Resource{
+			{URI: "/stations", Name: "stations", Description: "All weather stations"},
+			{URI: "/stations/{id}", Name: "station"},
+		},
+		Prompts: []client.Prompt{
+			{
+				Name: "explain_forecast",
+				Arguments: []client.PromptArgument{
+					{Name: "city", Required: true},
+					{Name: "tone"},
+				},
+			},
+			{Name: "greeting"},
+		},
+	}
+
+	source, err := generate("weather", srv)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	code := string(source)
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "client.go", source, parser.AllErrors); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+
+	// Compare without gofmt's alignment of struct fields
+	compact := regexp.MustCompile(`[ \t]+`).ReplaceAllString(code, " ")
+
+	for _, want := range []string{
+		"// Code generated by gomcp-gen from the weather MCP server. DO NOT EDIT.",
+		"package weather",
+		"func New(conn client.Client) *Client",
+
+		// Tools
+		"type GetForecastArgs struct",
+		"City string `json:\"city\"`",
+		"Days *int64 `json:\"days,omitempty\"`",
+		"// One of: metric, imperial.",
+		"Location *GetForecastArgsLocation `json:\"location,omitempty\"`",
+		"type GetForecastArgsLocation struct",
+		"Lat float64 `json:\"lat\"`",
+		"Tags []string `json:\"tags,omitempty\"`",
+		"type GetForecastResult struct",
+		"Highs []float64 `json:\"highs,omitempty\"`",
+		"func (c *Client) GetForecast(args GetForecastArgs, opts ...client.RequestOption) (GetForecastResult, error)",
+		"client.CallToolTyped[GetForecastArgs, GetForecastResult](c.conn, \"get_forecast\", args, opts...)",
+		"type PingArgs struct{}",
+		"// Example: Check the server is up\n//\n// args: {}\n// result: \"pong\"\nfunc (c *Client) Ping(",
+		"func (c *Client) Ping(args PingArgs, opts ...client.RequestOption) (string, error)",
+
+		// Resources
+		"func (c *Client) ReadStations(opts ...client.RequestOption) (*client.ResourceResponse, error)",
+		"return c.conn.GetResource(\"/stations\", opts...)",
+
+		// Prompts
+		"type ExplainForecastPromptArgs struct",
+		"func (c *Client) ExplainForecastPrompt(args ExplainForecastPromptArgs, opts ...client.RequestOption) (*client.PromptResponse, error)",
+		"variables[\"city\"] = args.City",
+		"if args.Tone != \"\" {",
+		"func (c *Client) GreetingPrompt(opts ...client.RequestOption) (*client.PromptResponse, error)",
+	} {
+		if !strings.Contains(compact, want) {
+			t.Errorf("generated code is missing %q\n%s", want, code)
+		}
+	}
+
+	// Templated resources are skipped
+	if strings.Contains(code, "ReadStation(") {
+		t.Errorf("generated a method for a templated resource\n%s", code)
+	}
+
+	// Nested types are declared before the types using them
+	if strings.Index(code, "type GetForecastArgsLocation struct") > strings.Index(code, "type GetForecastArgs struct") {
+		t.Errorf("nested type declared after its parent\n%s", code)
+	}
+}
+
+func TestGenerateUniqueNames(t *testing.T) {
+	srv := server{
+		Name: "clash",
+		Tools: []client.Tool{
+			{Name: "new", InputSchema: map[string]interface{}{"type": "object"}},
+			{Name: "list-items", InputSchema: map[string]interface{}{"type": "object"}},
+			{Name: "list_items", InputSchema: map[string]interface{}{"type": "object"}},
+		},
+	}
+
+	source, err := generate("clash", srv)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	code := string(source)
+	for _, want := range []string{
+		"func (c *Client) New2(args New2Args",
+		"func (c *Client) ListItems(args ListItemsArgs",
+		"func (c *Client) ListItems2(args ListItems2Args",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code is missing %q\n%s", want, code)
+		}
+	}
+}
+
+func TestConnectRequiresOneServer(t *testing.T) {
+	if _, err := connect("", "", "", nil); err == nil {
+		t.Error("expected an error without a server")
+	}
+	if _, err := connect("http://localhost:1", "mcp.json", "", nil); err == nil {
+		t.Error("expected an error with two servers")
+	}
+	if _, err := connect("", "mcp.json", "", nil); err == nil {
+		t.Error("expected an error without -server")
+	}
+}
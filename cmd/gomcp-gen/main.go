@@ -0,0 +1,141 @@
+// Command gomcp-gen generates a typed Go client package for an MCP server.
+//
+// It connects to a running server, or launches one, reads its tools, resources and
+// prompts along with their schemas, and writes a package with one method per tool
+// taking and returning structs generated from the tool's input and output schemas,
+// one method per static resource and one method per prompt, much like protoc
+// generates stubs from a service definition.
+//
+// Usage:
+//
+//	gomcp-gen [flags] [-- command [args...]]
+//
+// The server is selected with exactly one of:
+//
+//	-url URL                       connect to a server at URL (http, https, ws, wss, sse or unix)
+//	-config FILE -server NAME      launch server NAME from an mcpServers config file
+//	-- command [args...]           launch a stdio server with the given command
+//
+// Examples:
+//
+//	gomcp-gen -url http://localhost:8080/mcp -package weather -out weather/client.go
+//	gomcp-gen -package weather -out weather/client.go -- go run ./cmd/weather-server
+//
+// The generated client wraps a connected client.Client:
+//
+//	c, err := client.NewClient("http://localhost:8080/mcp")
+//	...
+//	forecast, err := weather.New(c).GetForecast(weather.GetForecastArgs{City: "Paris"})
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/localrivet/gomcp/client"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "gomcp-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run parses the command line, generates the client and writes it to -out or stdout
+func run(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("gomcp-gen", flag.ContinueOnError)
+	url := flags.String("url", "", "URL of the MCP server to connect to")
+	configPath := flags.String("config", "", "mcpServers config file to launch the server from")
+	serverName := flags.String("server", "", "name of the server in the config file")
+	pkg := flags.String("package", "mcpclient", "name of the generated package")
+	out := flags.String("out", "", "file to write the generated code to (default stdout)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: gomcp-gen [flags] [-- command [args...]]\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := connect(*url, *configPath, *serverName, flags.Args())
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	srv, err := describe(c)
+	if err != nil {
+		return err
+	}
+
+	source, err := generate(*pkg, srv)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = stdout.Write(source)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(*out), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(*out, source, 0o644)
+}
+
+// connect connects to the server selected on the command line
+func connect(url, configPath, serverName string, command []string) (client.Client, error) {
+	// Keep client logs out of the generated code when it is written to stdout
+	discard := slog.New(slog.NewTextHandler(io.Discard, nil))
+	logger := client.WithLogger(discard)
+	registryLogger := client.WithServerRegistryLogger(discard)
+
+	switch {
+	case url != "" && configPath == "" && len(command) == 0:
+		return client.NewClient(url, logger)
+	case configPath != "" && url == "" && len(command) == 0:
+		if serverName == "" {
+			return nil, fmt.Errorf("-server is required with -config")
+		}
+		return client.NewClient(serverName, logger, client.WithServerConfig(configPath, serverName, registryLogger))
+	case len(command) > 0 && url == "" && configPath == "":
+		config := client.ServerConfig{
+			MCPServers: map[string]client.ServerDefinition{
+				"gomcp-gen": {Command: command[0], Args: command[1:]},
+			},
+		}
+		return client.NewClient("gomcp-gen", logger, client.WithServers(config, "gomcp-gen", registryLogger))
+	}
+	return nil, fmt.Errorf("select the server with exactly one of -url, -config or -- command")
+}
+
+// describe lists what the server offers
+func describe(c client.Client) (server, error) {
+	srv := server{Name: "MCP"}
+	if info := c.GetServerInfo(); info != nil && info.Name != "" {
+		srv.Name = info.Name
+	}
+
+	var err error
+	if c.HasCapability("tools") {
+		if srv.Tools, err = c.ListTools(); err != nil {
+			return srv, fmt.Errorf("failed to list tools: %w", err)
+		}
+	}
+	if c.HasCapability("resources") {
+		if srv.Resources, err = c.ListResources(); err != nil {
+			return srv, fmt.Errorf("failed to list resources: %w", err)
+		}
+	}
+	if c.HasCapability("prompts") {
+		if srv.Prompts, err = c.ListPrompts(); err != nil {
+			return srv, fmt.Errorf("failed to list prompts: %w", err)
+		}
+	}
+	return srv, nil
+}
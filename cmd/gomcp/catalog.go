@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/localrivet/gomcp/client"
+)
+
+// catalog is what a server offers, as exported by the schema command and rendered
+// by the docs command
+type catalog struct {
+	ServerInfo        client.ServerInfo         `json:"serverInfo"`
+	Instructions      string                    `json:"instructions,omitempty"`
+	Capabilities      client.ServerCapabilities `json:"capabilities"`
+	Tools             []client.Tool             `json:"tools,omitempty"`
+	Resources         []client.Resource         `json:"resources,omitempty"`
+	ResourceTemplates []client.ResourceTemplate `json:"resourceTemplates,omitempty"`
+	Prompts           []client.Prompt           `json:"prompts,omitempty"`
+}
+
+// describe lists what a connected server offers
+func describe(c client.Client) (catalog, error) {
+	var cat catalog
+	if info := c.GetServerInfo(); info != nil {
+		cat.ServerInfo = *info
+	}
+	if caps := c.GetServerCapabilities(); caps != nil {
+		cat.Capabilities = *caps
+	}
+	cat.Instructions = c.GetServerInstructions()
+
+	var err error
+	if c.HasCapability("tools") {
+		if cat.Tools, err = c.ListTools(); err != nil {
+			return cat, fmt.Errorf("failed to list tools: %w", err)
+		}
+	}
+	if c.HasCapability("resources") {
+		if cat.Resources, err = c.ListResources(); err != nil {
+			return cat, fmt.Errorf("failed to list resources: %w", err)
+		}
+		if cat.ResourceTemplates, err = c.ListResourceTemplates(); err != nil {
+			return cat, fmt.Errorf("failed to list resource templates: %w", err)
+		}
+	}
+	if c.HasCapability("prompts") {
+		if cat.Prompts, err = c.ListPrompts(); err != nil {
+			return cat, fmt.Errorf("failed to list prompts: %w", err)
+		}
+	}
+	return cat, nil
+}
+
+// readCatalog reads a catalog exported by the schema command
+func readCatalog(path string) (catalog, error) {
+	var cat catalog
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cat, err
+	}
+	if err := json.Unmarshal(data, &cat); err != nil {
+		return cat, fmt.Errorf("failed to read catalog %s: %w", path, err)
+	}
+	return cat, nil
+}
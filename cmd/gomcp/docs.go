@@ -0,0 +1,395 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/localrivet/gomcp/client"
+	"github.com/localrivet/gomcp/mcp"
+)
+
+// hints are the behaviour hints of tool annotations, in the order they are listed
+var hints = []struct{ key, name string }{
+	{"readOnlyHint", "read-only"},
+	{"destructiveHint", "destructive"},
+	{"idempotentHint", "idempotent"},
+	{"openWorldHint", "open world"},
+}
+
+// renderDocs returns a Markdown catalog of a server
+func renderDocs(cat catalog) ([]byte, error) {
+	var w bytes.Buffer
+
+	name := cat.ServerInfo.Name
+	if name == "" {
+		name = "MCP Server"
+	}
+	fmt.Fprintf(&w, "# %s\n\n", name)
+	if cat.ServerInfo.Version != "" {
+		fmt.Fprintf(&w, "Version %s. ", cat.ServerInfo.Version)
+	}
+	w.WriteString("Generated by `gomcp docs`; do not edit by hand.\n\n")
+	if instructions := strings.TrimSpace(cat.Instructions); instructions != "" {
+		fmt.Fprintf(&w, "%s\n\n", instructions)
+	}
+
+	writeCapabilities(&w, cat.Capabilities)
+
+	tools := append([]client.Tool(nil), cat.Tools...)
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	if len(tools) > 0 {
+		w.WriteString("## Tools\n\n")
+		for _, tool := range tools {
+			fmt.Fprintf(&w, "- [`%s`](#%s)", tool.Name, anchor(tool.Name))
+			if summary := firstLine(tool.Description); summary != "" {
+				fmt.Fprintf(&w, ": %s", summary)
+			}
+			w.WriteString("\n")
+		}
+		w.WriteString("\n")
+		for _, tool := range tools {
+			if err := writeTool(&w, tool); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	resources := append([]client.Resource(nil), cat.Resources...)
+	sort.Slice(resources, func(i, j int) bool { return resources[i].URI < resources[j].URI })
+	if len(resources) > 0 {
+		w.WriteString("## Resources\n\n| URI | Name | MIME type | Description |\n|-----|------|-----------|-------------|\n")
+		for _, resource := range resources {
+			fmt.Fprintf(&w, "| `%s` | %s | %s | %s |\n", resource.URI, cell(resource.Name), cell(resource.MimeType), cell(resource.Description))
+		}
+		w.WriteString("\n")
+	}
+
+	templates := append([]client.ResourceTemplate(nil), cat.ResourceTemplates...)
+	sort.Slice(templates, func(i, j int) bool { return templates[i].URITemplate < templates[j].URITemplate })
+	if len(templates) > 0 {
+		w.WriteString("## Resource Templates\n\n| URI template | Name | MIME type | Description |\n|--------------|------|-----------|-------------|\n")
+		for _, template := range templates {
+			fmt.Fprintf(&w, "| `%s` | %s | %s | %s |\n", template.URITemplate, cell(template.Name), cell(template.MimeType), cell(template.Description))
+		}
+		w.WriteString("\n")
+	}
+
+	prompts := append([]client.Prompt(nil), cat.Prompts...)
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
+	if len(prompts) > 0 {
+		w.WriteString("## Prompts\n\n")
+		for _, prompt := range prompts {
+			writePrompt(&w, prompt)
+		}
+	}
+
+	return append(bytes.TrimRight(w.Bytes(), "\n"), '\n'), nil
+}
+
+// writeCapabilities lists the capabilities the server declared
+func writeCapabilities(w *bytes.Buffer, caps client.ServerCapabilities) {
+	var lines []string
+	if caps.Tools != nil {
+		lines = append(lines, withListChanged("Tools", caps.Tools.ListChanged))
+	}
+	if caps.Resources != nil {
+		line := withListChanged("Resources", caps.Resources.ListChanged)
+		if caps.Resources.Subscribe {
+			line += ", with subscriptions"
+		}
+		lines = append(lines, line)
+	}
+	if caps.Prompts != nil {
+		lines = append(lines, withListChanged("Prompts", caps.Prompts.ListChanged))
+	}
+	if caps.Completions != nil {
+		lines = append(lines, "Argument completions")
+	}
+	if caps.Logging != nil {
+		lines = append(lines, "Logging")
+	}
+	if len(caps.Experimental) > 0 {
+		names := make([]string, 0, len(caps.Experimental))
+		for name := range caps.Experimental {
+			names = append(names, "`"+name+"`")
+		}
+		sort.Strings(names)
+		lines = append(lines, "Experimental: "+strings.Join(names, ", "))
+	}
+
+	w.WriteString("## Capabilities\n\n")
+	if len(lines) == 0 {
+		w.WriteString("The server declares no capabilities.\n\n")
+		return
+	}
+	for _, line := range lines {
+		fmt.Fprintf(w, "- %s\n", line)
+	}
+	w.WriteString("\n")
+}
+
+// withListChanged notes whether the server notifies clients of list changes
+func withListChanged(name string, listChanged bool) string {
+	if listChanged {
+		return name + ", notifying list changes"
+	}
+	return name
+}
+
+// writeTool documents a tool
+func writeTool(w *bytes.Buffer, tool client.Tool) error {
+	fmt.Fprintf(w, "### `%s`\n\n", tool.Name)
+	if desc := strings.TrimSpace(tool.Description); desc != "" {
+		fmt.Fprintf(w, "%s\n\n", desc)
+	}
+
+	var notes []string
+	display := tool.Display()
+	if display.Name != "" {
+		notes = append(notes, "**Title:** "+display.Name)
+	}
+	if display.Category != "" {
+		notes = append(notes, "**Category:** "+display.Category)
+	}
+	if _, declared := tool.Annotations[mcp.RiskAnnotation]; declared {
+		notes = append(notes, "**Risk:** "+string(tool.Risk()))
+	}
+	var behaviour []string
+	for _, hint := range hints {
+		if set, _ := tool.Annotations[hint.key].(bool); set {
+			behaviour = append(behaviour, hint.name)
+		}
+	}
+	if len(behaviour) > 0 {
+		notes = append(notes, "**Hints:** "+strings.Join(behaviour, ", "))
+	}
+	if tags := tool.Tags(); len(tags) > 0 {
+		notes = append(notes, "**Tags:** `"+strings.Join(tags, "`, `")+"`")
+	}
+	if len(notes) > 0 {
+		fmt.Fprintf(w, "%s\n\n", strings.Join(notes, " · "))
+	}
+
+	w.WriteString("#### Arguments\n\n")
+	writeProperties(w, tool.InputSchema, "This tool takes no arguments.")
+	if len(tool.OutputSchema) > 0 {
+		w.WriteString("#### Result\n\n")
+		writeProperties(w, tool.OutputSchema, "The result has no documented fields.")
+	}
+
+	if examples := tool.Examples(); len(examples) > 0 {
+		w.WriteString("#### Examples\n\n")
+		for _, example := range examples {
+			if example.Description != "" {
+				fmt.Fprintf(w, "%s:\n\n", strings.TrimSuffix(example.Description, "."))
+			}
+			if err := writeJSON(w, example.Input); err != nil {
+				return fmt.Errorf("failed to render an example of %s: %w", tool.Name, err)
+			}
+			if example.Output != nil {
+				w.WriteString("Result:\n\n")
+				if err := writeJSON(w, example.Output); err != nil {
+					return fmt.Errorf("failed to render an example of %s: %w", tool.Name, err)
+				}
+			}
+		}
+	}
+
+	if len(tool.InputSchema) > 0 {
+		w.WriteString("<details>\n<summary>Input schema</summary>\n\n")
+		if err := writeJSON(w, tool.InputSchema); err != nil {
+			return fmt.Errorf("failed to render the schema of %s: %w", tool.Name, err)
+		}
+		w.WriteString("</details>\n\n")
+	}
+	return nil
+}
+
+// writePrompt documents a prompt
+func writePrompt(w *bytes.Buffer, prompt client.Prompt) {
+	fmt.Fprintf(w, "### `%s`\n\n", prompt.Name)
+	if desc := strings.TrimSpace(prompt.Description); desc != "" {
+		fmt.Fprintf(w, "%s\n\n", desc)
+	}
+	if len(prompt.Arguments) == 0 {
+		w.WriteString("This prompt takes no arguments.\n\n")
+		return
+	}
+	w.WriteString("| Argument | Required | Description |\n|----------|----------|-------------|\n")
+	for _, arg := range prompt.Arguments {
+		fmt.Fprintf(w, "| `%s` | %s | %s |\n", arg.Name, yesNo(arg.Required), cell(arg.Description))
+	}
+	w.WriteString("\n")
+}
+
+// writeProperties writes a table of the properties of an object schema, nested
+// properties being named by their path
+func writeProperties(w *bytes.Buffer, schema map[string]interface{}, none string) {
+	var rows []string
+	collectProperties(&rows, schema, "")
+	if len(rows) == 0 {
+		fmt.Fprintf(w, "%s\n\n", none)
+		return
+	}
+	w.WriteString("| Name | Type | Required | Description |\n|------|------|----------|-------------|\n")
+	for _, row := range rows {
+		w.WriteString(row)
+	}
+	w.WriteString("\n")
+}
+
+// collectProperties appends a table row for each property of an object schema and
+// of the objects it contains
+func collectProperties(rows *[]string, schema map[string]interface{}, prefix string) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, name := range list {
+			if name, ok := name.(string); ok {
+				required[name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, _ := properties[name].(map[string]interface{})
+		path := prefix + name
+		*rows = append(*rows, fmt.Sprintf("| `%s` | %s | %s | %s |\n", path, cell(typeName(property)), yesNo(required[name]), cell(propertyDescription(property))))
+
+		switch schemaType(property) {
+		case "object":
+			collectProperties(rows, property, path+".")
+		case "array":
+			if items, ok := property["items"].(map[string]interface{}); ok && schemaType(items) == "object" {
+				collectProperties(rows, items, path+"[].")
+			}
+		}
+	}
+}
+
+// typeName describes the type of a schema
+func typeName(schema map[string]interface{}) string {
+	name := schemaType(schema)
+	switch name {
+	case "":
+		name = "any"
+	case "array":
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			name = "array of " + typeName(items)
+		}
+	}
+	if format, ok := schema["format"].(string); ok && format != "" {
+		name += " (" + format + ")"
+	}
+	return name
+}
+
+// propertyDescription returns the description of a property followed by its
+// allowed values and default
+func propertyDescription(schema map[string]interface{}) string {
+	parts := []string{}
+	if desc, _ := schema["description"].(string); desc != "" {
+		parts = append(parts, strings.TrimSpace(desc))
+	}
+	if values, ok := schema["enum"].([]interface{}); ok && len(values) > 0 {
+		names := make([]string, len(values))
+		for i, value := range values {
+			names[i] = "`" + compactJSON(value) + "`"
+		}
+		parts = append(parts, "One of "+strings.Join(names, ", ")+".")
+	}
+	if value, ok := schema["default"]; ok {
+		parts = append(parts, "Default `"+compactJSON(value)+"`.")
+	}
+	return strings.Join(parts, " ")
+}
+
+// schemaType returns the JSON type of a schema, ignoring "null" in type unions
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		var types []string
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				types = append(types, s)
+			}
+		}
+		return strings.Join(types, " or ")
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	return ""
+}
+
+// writeJSON writes a value as an indented JSON code block
+func writeJSON(w *bytes.Buffer, value interface{}) error {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "```json\n%s```\n\n", data.String())
+	return nil
+}
+
+// compactJSON returns a value as JSON on one line
+func compactJSON(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// anchor returns the fragment GitHub links a Markdown heading made of code to
+func anchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// firstLine returns the first line of a description
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = strings.TrimSpace(text[:i])
+	}
+	return text
+}
+
+// cell escapes text for a Markdown table cell
+func cell(text string) string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "|", `\|`)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// yesNo renders a boolean table cell
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
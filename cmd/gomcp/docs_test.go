@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/client"
+	"github.com/localrivet/gomcp/server"
+)
+
+type forecastArgs struct {
+	City  string  `json:"city" description:"Name of the city"`
+	Days  *int    `json:"days,omitempty" description:"Number of days"`
+	Units *string `json:"units,omitempty" jsonschema:"enum=metric|imperial"`
+}
+
+// startServer serves a weather server over WebSocket and returns its URL
+func startServer(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find a free port: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	s := server.NewServer("weather").AsWebsocket(address)
+	s.Tool("get_forecast", "Returns the forecast for a city.\n\nForecasts are updated hourly.", func(ctx *server.Context, args forecastArgs) (interface{}, error) {
+		return "sunny", nil
+	},
+		map[string]interface{}{"readOnlyHint": true},
+		server.WithTags("weather"),
+		server.WithExample(forecastArgs{City: "Paris"}, "sunny", "Forecast for Paris"),
+	)
+	s.Resource("/stations", "Weather stations", func(ctx *server.Context, args interface{}) (interface{}, error) {
+		return "[]", nil
+	})
+	s.Resource("/stations/{id}", "A weather station", func(ctx *server.Context, args interface{}) (interface{}, error) {
+		return "{}", nil
+	})
+	s.Prompt("summarize", "Summarize the weather", server.User("Summarize the weather in {{city}}"))
+	go s.Run()
+	t.Cleanup(func() { s.Shutdown() })
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if conn, err := net.Dial("tcp", address); err == nil {
+			conn.Close()
+			return "ws://" + address
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("Server at %s did not start", address)
+	return ""
+}
+
+func TestDocsFromServerAndExportedSchema(t *testing.T) {
+	url := startServer(t)
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "weather.json")
+
+	// Flags may follow the target
+	if err := run([]string{"schema", url, "--out", schemaPath}, nil); err != nil {
+		t.Fatalf("schema failed: %v", err)
+	}
+	var live bytes.Buffer
+	if err := run([]string{"docs", url}, &live); err != nil {
+		t.Fatalf("docs failed: %v", err)
+	}
+	var exported bytes.Buffer
+	if err := run([]string{"docs", schemaPath}, &exported); err != nil {
+		t.Fatalf("docs from the exported schema failed: %v", err)
+	}
+	if live.String() != exported.String() {
+		t.Errorf("Expected the same docs from the server and its exported schema, got:\n%s\n\nand:\n%s", live.String(), exported.String())
+	}
+
+	docs := live.String()
+	for _, want := range []string{
+		"# weather\n",
+		"- Tools, notifying list changes\n",
+		"- [`get_forecast`](#get_forecast): Returns the forecast for a city.\n",
+		"### `get_forecast`\n\nReturns the forecast for a city.\n\nForecasts are updated hourly.\n",
+		"**Hints:** read-only · **Tags:** `weather`",
+		"| `city` | string | yes | Name of the city |\n",
+		"| `units` | string | no | One of `metric`, `imperial`. |\n",
+		"Forecast for Paris:\n\n```json\n{\n  \"city\": \"Paris\"\n}\n```\n\nResult:\n\n```json\n\"sunny\"\n```\n",
+		"<summary>Input schema</summary>",
+		"| `/stations` |",
+		"## Resource Templates\n",
+		"| `/stations/{id}` |",
+		"### `summarize`\n\nSummarize the weather\n\n| Argument | Required | Description |\n",
+		"| `city` | yes |",
+	} {
+		if !strings.Contains(docs, want) {
+			t.Errorf("Expected the docs to contain %q, got:\n%s", want, docs)
+		}
+	}
+}
+
+func TestRenderNestedProperties(t *testing.T) {
+	docs, err := renderDocs(catalog{
+		ServerInfo: client.ServerInfo{Name: "shop", Version: "1.2.0"},
+		Tools: []client.Tool{{
+			Name: "place_order",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"address": map[string]interface{}{
+						"type":       "object",
+						"properties": map[string]interface{}{"zip": map[string]interface{}{"type": "string", "description": "Postal | ZIP code"}},
+						"required":   []interface{}{"zip"},
+					},
+					"items": map[string]interface{}{
+						"type": "array",
+						"items": map[string]interface{}{
+							"type":       "object",
+							"properties": map[string]interface{}{"sku": map[string]interface{}{"type": "string"}},
+						},
+					},
+					"express": map[string]interface{}{"type": []interface{}{"boolean", "null"}, "default": false},
+				},
+			},
+			OutputSchema: map[string]interface{}{"type": "object"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("renderDocs failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"# shop\n\nVersion 1.2.0. Generated by `gomcp docs`",
+		"The server declares no capabilities.",
+		"| `address` | object | no |  |\n| `address.zip` | string | yes | Postal \\| ZIP code |\n",
+		"| `express` | boolean | no | Default `false`. |\n",
+		"| `items` | array of object | no |  |\n| `items[].sku` | string | no |  |\n",
+		"#### Result\n\nThe result has no documented fields.\n",
+	} {
+		if !strings.Contains(string(docs), want) {
+			t.Errorf("Expected the docs to contain %q, got:\n%s", want, docs)
+		}
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		targets []string
+		command []string
+		out     string
+	}{
+		{[]string{"http://x", "--out", "a.md"}, []string{"http://x"}, nil, "a.md"},
+		{[]string{"-out", "a.md", "http://x"}, []string{"http://x"}, nil, "a.md"},
+		{[]string{"--out", "a.md", "--", "server", "--out", "b"}, nil, []string{"server", "--out", "b"}, "a.md"},
+	}
+	for _, tt := range tests {
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		out := flags.String("out", "", "")
+		targets, command, err := parseArgs(flags, tt.args)
+		if err != nil {
+			t.Fatalf("parseArgs(%q) failed: %v", tt.args, err)
+		}
+		if fmt.Sprint(targets) != fmt.Sprint(tt.targets) || fmt.Sprint(command) != fmt.Sprint(tt.command) || *out != tt.out {
+			t.Errorf("parseArgs(%q) = %q, %q, out %q", tt.args, targets, command, *out)
+		}
+	}
+}
+
+func TestLoadRejectsAmbiguousTargets(t *testing.T) {
+	if _, err := load([]string{"a.json", "b.json"}, nil); err == nil {
+		t.Error("Expected two targets to be rejected")
+	}
+	if _, err := load(nil, nil); err == nil {
+		t.Error("Expected a missing target to be rejected")
+	}
+	if _, err := load([]string{filepath.Join(t.TempDir(), "missing.json")}, nil); !os.IsNotExist(err) {
+		t.Errorf("Expected a missing catalog to be reported, got %v", err)
+	}
+}
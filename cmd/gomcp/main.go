@@ -0,0 +1,177 @@
+// Command gomcp inspects MCP servers and runs them as a service.
+//
+// Usage:
+//
+//	gomcp docs [flags] <target>      render a Markdown catalog of a server
+//	gomcp schema [flags] <target>    export what a server offers as JSON
+//	gomcp serve --config FILE        run the servers of a daemon config
+//	gomcp serve status|reload|stop   control a running daemon
+//
+// The docs command documents the server's capabilities, its tools with their
+// input and output schemas and examples, its resources and resource templates,
+// and its prompts, so the documentation published for a server never drifts from
+// what it actually serves. The schema command exports the same information, which
+// docs can later render without starting the server again.
+//
+// The target is one of:
+//
+//	URL                     connect to a server at URL (http, https, ws, wss, sse or unix)
+//	FILE.json               read a catalog exported by gomcp schema
+//	-- command [args...]    launch a stdio server with the given command
+//
+// Examples:
+//
+//	gomcp docs http://localhost:8080/mcp --out TOOLS.md
+//	gomcp docs --out TOOLS.md -- go run ./cmd/weather-server
+//	gomcp schema http://localhost:8080/mcp --out weather.json
+//	gomcp docs weather.json --out TOOLS.md
+//
+// The serve command runs the servers listed in a daemon config (see
+// server.DaemonConfig) until it receives SIGINT, SIGTERM or the stop command, and
+// reloads the config on SIGHUP or the reload command. It writes a pidfile if the
+// config asks for one and reports its readiness to systemd, so it runs under a
+// unit of Type=notify:
+//
+//	gomcp serve --config /etc/gomcp/gomcp.yaml
+//	gomcp serve status --config /etc/gomcp/gomcp.yaml
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/localrivet/gomcp/client"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "gomcp: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// usage is printed when no command is given
+const usage = `Usage:
+  gomcp docs [flags] <target>      render a Markdown catalog of a server
+  gomcp schema [flags] <target>    export what a server offers as JSON
+  gomcp serve --config FILE        run the servers of a daemon config
+  gomcp serve status|reload|stop   control a running daemon
+
+The target is a server URL, a catalog exported by gomcp schema, or -- command [args...]
+to launch a stdio server. Run gomcp <command> -h for the flags of a command.
+`
+
+// run dispatches the command line to a command
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no command given\n\n%s", usage)
+	}
+
+	var render func(c catalog) ([]byte, error)
+	switch args[0] {
+	case "docs":
+		render = renderDocs
+	case "schema":
+		render = func(c catalog) ([]byte, error) {
+			data, err := json.MarshalIndent(c, "", "  ")
+			return append(data, '\n'), err
+		}
+	case "serve":
+		return serve(args[1:], stdout)
+	case "help", "-h", "-help", "--help":
+		_, err := io.WriteString(stdout, usage)
+		return err
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], usage)
+	}
+
+	flags := flag.NewFlagSet("gomcp "+args[0], flag.ContinueOnError)
+	out := flags.String("out", "", "file to write to (default stdout)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: gomcp %s [flags] <target | -- command [args...]>\n\nFlags:\n", args[0])
+		flags.PrintDefaults()
+	}
+	targets, command, err := parseArgs(flags, args[1:])
+	if err != nil {
+		return err
+	}
+
+	c, err := load(targets, command)
+	if err != nil {
+		return err
+	}
+	output, err := render(c)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = stdout.Write(output)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(*out), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(*out, output, 0o644)
+}
+
+// parseArgs parses flags wherever they appear before "--", returning the other
+// arguments and the command following "--"
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, []string, error) {
+	var command []string
+	for i, arg := range args {
+		if arg == "--" {
+			args, command = args[:i], args[i+1:]
+			break
+		}
+	}
+
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, command, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+// load reads the catalog of the selected target
+func load(targets, command []string) (catalog, error) {
+	// Keep client logs out of the output when it is written to stdout
+	discard := slog.New(slog.NewTextHandler(io.Discard, nil))
+	logger := client.WithLogger(discard)
+
+	var c client.Client
+	var err error
+	switch {
+	case len(targets) == 1 && len(command) == 0 && !strings.Contains(targets[0], "://"):
+		return readCatalog(targets[0])
+	case len(targets) == 1 && len(command) == 0:
+		c, err = client.NewClient(targets[0], logger)
+	case len(targets) == 0 && len(command) > 0:
+		config := client.ServerConfig{
+			MCPServers: map[string]client.ServerDefinition{
+				"gomcp": {Command: command[0], Args: command[1:]},
+			},
+		}
+		c, err = client.NewClient("gomcp", logger, client.WithServers(config, "gomcp", client.WithServerRegistryLogger(discard)))
+	default:
+		return catalog{}, fmt.Errorf("select the server with exactly one of a URL, a catalog file or -- command")
+	}
+	if err != nil {
+		return catalog{}, err
+	}
+	defer c.Close()
+
+	return describe(c)
+}
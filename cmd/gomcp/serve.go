@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/localrivet/gomcp/server"
+)
+
+// serve runs a daemon, or sends a command to the daemon of a config
+func serve(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("gomcp serve", flag.ContinueOnError)
+	config := flags.String("config", "", "daemon config file (required)")
+	socket := flags.String("socket", "", "control socket of the daemon (default from the config)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: gomcp serve [status | reload | stop] --config FILE\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	commands, _, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(commands) > 1 {
+		return fmt.Errorf("expected at most one command, got %d", len(commands))
+	}
+	if *config == "" && (len(commands) == 0 || *socket == "") {
+		return errors.New("the --config flag is required")
+	}
+
+	if len(commands) == 0 {
+		return runDaemon(*config)
+	}
+
+	if *socket == "" {
+		daemonConfig, err := server.LoadDaemonConfig(*config)
+		if err != nil {
+			return err
+		}
+		*socket = daemonConfig.ControlSocket
+	}
+	switch commands[0] {
+	case server.DaemonStatusCommand, server.DaemonReloadCommand, server.DaemonStopCommand:
+	default:
+		return fmt.Errorf("unknown serve command %q, expected status, reload or stop", commands[0])
+	}
+	status, err := server.DaemonControl(*socket, commands[0])
+	if status != nil && commands[0] != server.DaemonStopCommand {
+		writeStatus(stdout, status)
+	}
+	return err
+}
+
+// runDaemon runs the daemon of a config until it is stopped by the stop command,
+// SIGINT or SIGTERM, reloading it on SIGHUP
+func runDaemon(config string) error {
+	d, err := server.NewDaemon(config)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+	go func() {
+		for range hangup {
+			if err := d.Reload(); err != nil {
+				fmt.Fprintf(os.Stderr, "gomcp: reload failed: %v\n", err)
+			}
+		}
+	}()
+
+	return d.Run(ctx)
+}
+
+// writeStatus prints the status of a daemon and a table of its servers
+func writeStatus(w io.Writer, status *server.DaemonStatus) {
+	fmt.Fprintf(w, "gomcp daemon (pid %d) running since %s, reloaded %d times\n\n",
+		status.PID, status.Started.Format(time.RFC3339), status.Reloads)
+
+	var failures []string
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tTRANSPORT\tADDRESS\tSTATE\tSESSIONS\tCONFIG")
+	for _, s := range status.Servers {
+		state := "running"
+		switch {
+		case s.Error != "":
+			state = "failed"
+			failures = append(failures, s.Error)
+		case !s.Running:
+			state = "stopped"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%d\t%s\n", s.Name, s.Transport, s.Address, state, s.Sessions, s.Config)
+	}
+	table.Flush()
+
+	for _, failure := range failures {
+		fmt.Fprintf(w, "\n%s", failure)
+	}
+	if len(failures) > 0 {
+		fmt.Fprintln(w)
+	}
+}
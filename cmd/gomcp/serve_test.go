@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServe(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "weather.yaml"), []byte("name: weather\ntransport:\n  type: http\n  address: 127.0.0.1:0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(dir, "gomcp.yaml")
+	if err := os.WriteFile(config, []byte("servers:\n  - config: weather.yaml\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- run([]string{"serve", "--config", config}, &bytes.Buffer{}) }()
+
+	// Wait for the daemon to answer on its control socket
+	var out bytes.Buffer
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		out.Reset()
+		err := run([]string{"serve", "status", "--config", config}, &out)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Daemon did not start: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	for _, want := range []string{"gomcp daemon (pid", "NAME", "weather", "http", "running"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected the status to contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	if err := run([]string{"serve", "stop", "--config", config}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("Failed to stop the daemon: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Daemon failed: %v", err)
+		}
+	case <-time.After(15 * time.Second):
+		t.Fatal("Daemon did not stop")
+	}
+
+	if err := run([]string{"serve", "restart", "--config", config}, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "unknown serve command") {
+		t.Errorf("Expected an unknown command error, got %v", err)
+	}
+}
@@ -0,0 +1,463 @@
+// Package aggregator is a tool pack that serves the tools and resources of several
+// MCP servers from one server.
+//
+// New returns a server.ToolProvider proxying the catalogs of its backends, the
+// clients added with AddBackend. The merged catalog follows the backends: when a
+// backend reports a list change, is removed, or stops answering, the aggregator
+// updates the tools and resources it registers and sends its own clients a single
+// list_changed notification for each list that changed:
+//
+//	agg := aggregator.New(aggregator.WithConflictPolicy(aggregator.Namespace))
+//	agg.AddBackend("git", gitClient, 0)
+//	agg.AddBackend("sql", sqlClient, 0)
+//
+//	s := server.NewServer("gateway")
+//	s.UseProvider(agg)
+//	s.AsStdio().Run()
+package aggregator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/localrivet/gomcp/client"
+	"github.com/localrivet/gomcp/server"
+)
+
+// DefaultSeparator joins backend and tool names under the Namespace policy.
+const DefaultSeparator = "_"
+
+// ConflictPolicy decides how tools offered by several backends under the same name
+// are served.
+type ConflictPolicy int
+
+const (
+	// Priority serves each tool under its own name. When backends offer the same
+	// name, the backend with the highest priority wins, ties going to the backend
+	// added first.
+	Priority ConflictPolicy = iota
+
+	// Namespace serves each tool as <backend><separator><tool>, so that tools of
+	// different backends never conflict.
+	Namespace
+)
+
+// Option configures an Aggregator.
+type Option func(*Aggregator)
+
+// WithConflictPolicy sets how tools offered by several backends are served. The
+// default is Priority. Resources are identified by their URIs, so conflicting
+// resources are always resolved by priority.
+func WithConflictPolicy(policy ConflictPolicy) Option {
+	return func(a *Aggregator) {
+		a.policy = policy
+	}
+}
+
+// WithSeparator sets what joins backend and tool names under the Namespace policy.
+// The default is "_".
+func WithSeparator(separator string) Option {
+	return func(a *Aggregator) {
+		a.separator = separator
+	}
+}
+
+// WithHealthCheck pings every backend at the given interval. The tools and
+// resources of a backend that fails to answer are withdrawn until it answers again.
+// Without a health check, a backend's catalog is only withdrawn when listing it
+// fails or it is removed.
+func WithHealthCheck(interval time.Duration) Option {
+	return func(a *Aggregator) {
+		a.healthInterval = interval
+	}
+}
+
+// Route is an entry of the merged catalog: a tool or resource the aggregator
+// serves, and where requests for it are forwarded.
+type Route struct {
+	// Kind is "tool" or "resource"
+	Kind string
+
+	// Name is the tool name or resource URI served by the aggregator
+	Name string
+
+	// Backend is the name of the backend serving it
+	Backend string
+
+	// Target is the tool name or resource URI on the backend
+	Target string
+}
+
+// Aggregator is a tool pack proxying the catalogs of several backends. Create it
+// with New.
+type Aggregator struct {
+	policy         ConflictPolicy
+	separator      string
+	healthInterval time.Duration
+
+	mu       sync.Mutex
+	srv      server.Server
+	backends []*backend
+	added    int
+	routes   map[routeKey]*route
+	stop     chan struct{}
+}
+
+// backend is a server whose catalog is proxied
+type backend struct {
+	name     string
+	client   client.Client
+	priority int
+	order    int
+
+	// refreshMu serializes refreshes, so that an older catalog never replaces a newer one
+	refreshMu sync.Mutex
+
+	// The fields below are protected by the aggregator's mu
+	removed   bool
+	available bool
+	tools     []client.Tool
+	resources []client.Resource
+	templates []client.ResourceTemplate
+}
+
+// routeKey identifies a registered tool or resource
+type routeKey struct {
+	kind string
+	name string
+}
+
+// route is a registered tool or resource. fingerprint identifies its definition,
+// so that unchanged entries are not registered again.
+type route struct {
+	backend     *backend
+	target      string
+	fingerprint string
+}
+
+// New returns an aggregator without backends.
+func New(options ...Option) *Aggregator {
+	a := &Aggregator{
+		separator: DefaultSeparator,
+		routes:    make(map[routeKey]*route),
+	}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+// Register implements server.ToolProvider. It registers the merged catalog of the
+// backends added so far, and starts the health check if one is configured.
+func (a *Aggregator) Register(s server.Server) error {
+	a.mu.Lock()
+	if a.srv != nil {
+		a.mu.Unlock()
+		return errors.New("aggregator is already registered with a server")
+	}
+	a.srv = s
+	a.syncLocked()
+	if a.healthInterval > 0 {
+		a.stop = make(chan struct{})
+		go a.checkHealth(a.healthInterval, a.stop)
+	}
+	a.mu.Unlock()
+	return nil
+}
+
+// AddBackend adds a server whose tools and resources are proxied, and subscribes to
+// its list changes. The priority settles conflicts with other backends, the
+// highest winning. The backend's catalog is listed before AddBackend returns, and
+// an error is returned if it cannot be.
+func (a *Aggregator) AddBackend(name string, c client.Client, priority int) error {
+	if name == "" {
+		return errors.New("backend name is required")
+	}
+
+	// Subscribe first, so that no change made while the catalog is listed is missed
+	b := &backend{name: name, client: c, priority: priority}
+	c.OnListChanged(func(kind string) {
+		if kind != "prompts" {
+			a.refresh(b)
+		}
+	})
+
+	b.refreshMu.Lock()
+	defer b.refreshMu.Unlock()
+	tools, resources, templates, err := listCatalog(c)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err != nil {
+		b.removed = true
+		return fmt.Errorf("failed to list the catalog of backend %s: %w", name, err)
+	}
+	for _, other := range a.backends {
+		if other.name == name {
+			b.removed = true
+			return fmt.Errorf("backend %s is already added", name)
+		}
+	}
+	b.order = a.added
+	a.added++
+	b.tools, b.resources, b.templates = tools, resources, templates
+	b.available = true
+	a.backends = append(a.backends, b)
+	a.syncLocked()
+	return nil
+}
+
+// RemoveBackend withdraws the tools and resources of a backend. The backend's
+// client is not closed.
+func (a *Aggregator) RemoveBackend(name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for i, b := range a.backends {
+		if b.name == name {
+			b.removed = true
+			a.backends = append(a.backends[:i], a.backends[i+1:]...)
+			a.syncLocked()
+			return nil
+		}
+	}
+	return fmt.Errorf("backend %s not found", name)
+}
+
+// Routes returns the merged catalog, tools first, each kind sorted by name.
+func (a *Aggregator) Routes() []Route {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	routes := make([]Route, 0, len(a.routes))
+	for key, r := range a.routes {
+		routes = append(routes, Route{Kind: key.kind, Name: key.name, Backend: r.backend.name, Target: r.target})
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Kind != routes[j].Kind {
+			return routes[i].Kind == "tool"
+		}
+		return routes[i].Name < routes[j].Name
+	})
+	return routes
+}
+
+// Close stops the health check. The backends' clients are not closed.
+func (a *Aggregator) Close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.stop != nil {
+		close(a.stop)
+		a.stop = nil
+	}
+}
+
+// refresh lists a backend's catalog again and updates the merged catalog. A
+// backend whose catalog cannot be listed is withdrawn until a refresh succeeds.
+func (a *Aggregator) refresh(b *backend) {
+	b.refreshMu.Lock()
+	defer b.refreshMu.Unlock()
+
+	tools, resources, templates, err := listCatalog(b.client)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if b.removed {
+		return
+	}
+	if err != nil {
+		a.logf("failed to list backend catalog, withdrawing it", "backend", b.name, "error", err)
+		b.available = false
+	} else {
+		b.tools, b.resources, b.templates = tools, resources, templates
+		b.available = true
+	}
+	a.syncLocked()
+}
+
+// checkHealth pings the backends until stop is closed
+func (a *Aggregator) checkHealth(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		a.mu.Lock()
+		backends := append([]*backend(nil), a.backends...)
+		a.mu.Unlock()
+
+		for _, b := range backends {
+			err := b.client.Ping()
+
+			a.mu.Lock()
+			available := b.available
+			if err != nil && available && !b.removed {
+				a.logf("backend failed health check, withdrawing it", "backend", b.name, "error", err)
+				b.available = false
+				a.syncLocked()
+			}
+			a.mu.Unlock()
+
+			if err == nil && !available {
+				a.refresh(b)
+			}
+		}
+	}
+}
+
+// syncLocked registers the merged catalog of the available backends with the
+// server, removing what is no longer offered, as one batch of changes. The caller
+// must hold a.mu.
+func (a *Aggregator) syncLocked() {
+	if a.srv == nil {
+		return
+	}
+
+	wanted := make(map[routeKey]*route)
+	definitions := make(map[routeKey]interface{})
+	offer := func(key routeKey, r *route, definition interface{}) {
+		if existing, ok := wanted[key]; ok {
+			a.logf("conflicting entry served by higher priority backend", "name", key.name, "backend", existing.backend.name, "shadowed", r.backend.name)
+			return
+		}
+		data, _ := json.Marshal(definition)
+		r.fingerprint = r.backend.name + "\x00" + string(data)
+		wanted[key] = r
+		definitions[key] = definition
+	}
+
+	for _, b := range a.byPriority() {
+		for _, tool := range b.tools {
+			name := tool.Name
+			if a.policy == Namespace {
+				name = b.name + a.separator + tool.Name
+			}
+			offer(routeKey{"tool", name}, &route{backend: b, target: tool.Name}, tool)
+		}
+		for _, resource := range b.resources {
+			offer(routeKey{"resource", resource.URI}, &route{backend: b, target: resource.URI}, resource)
+		}
+		for _, template := range b.templates {
+			offer(routeKey{"resource", template.URITemplate}, &route{backend: b, target: template.URITemplate}, template)
+		}
+	}
+
+	a.srv.GetServer().BatchChanges(func(srv server.Server) {
+		for key := range a.routes {
+			if _, ok := wanted[key]; ok {
+				continue
+			}
+			if key.kind == "tool" {
+				srv.GetServer().RemoveTool(key.name)
+			} else {
+				srv.GetServer().RemoveResource(key.name)
+			}
+			delete(a.routes, key)
+		}
+
+		for key, r := range wanted {
+			if existing, ok := a.routes[key]; ok && existing.fingerprint == r.fingerprint {
+				continue
+			}
+			switch definition := definitions[key].(type) {
+			case client.Tool:
+				registerTool(srv, key.name, definition, r)
+			case client.Resource:
+				srv.Resource(key.name, definition.Description, forwardResource(r), definition.Annotations)
+			case client.ResourceTemplate:
+				srv.Resource(key.name, definition.Description, forwardResource(r), definition.Annotations)
+			}
+			a.routes[key] = r
+		}
+	})
+}
+
+// byPriority returns the available backends, highest priority first and then in
+// the order they were added
+func (a *Aggregator) byPriority() []*backend {
+	backends := make([]*backend, 0, len(a.backends))
+	for _, b := range a.backends {
+		if b.available {
+			backends = append(backends, b)
+		}
+	}
+	sort.SliceStable(backends, func(i, j int) bool {
+		if backends[i].priority != backends[j].priority {
+			return backends[i].priority > backends[j].priority
+		}
+		return backends[i].order < backends[j].order
+	})
+	return backends
+}
+
+// logf logs at debug level through the server's logger
+func (a *Aggregator) logf(msg string, args ...interface{}) {
+	if a.srv != nil {
+		a.srv.Logger().Debug(msg, args...)
+	}
+}
+
+// registerTool registers a tool forwarding its calls to a backend
+func registerTool(srv server.Server, name string, tool client.Tool, r *route) {
+	inputSchema := tool.InputSchema
+	if inputSchema == nil {
+		inputSchema = map[string]interface{}{"type": "object"}
+	}
+	handler := func(ctx *server.Context, args map[string]interface{}) (interface{}, error) {
+		return r.backend.client.CallTool(r.target, args)
+	}
+	srv.GetServer().ToolWithSchema(name, tool.Description, inputSchema, handler, tool.Annotations)
+}
+
+// forwardResource returns a resource handler reading the requested URI from a
+// backend
+func forwardResource(r *route) func(ctx *server.Context, args interface{}) (interface{}, error) {
+	return func(ctx *server.Context, args interface{}) (interface{}, error) {
+		uri := r.target
+		if ctx.Request != nil && ctx.Request.ResourcePath != "" {
+			uri = ctx.Request.ResourcePath
+		}
+		response, err := r.backend.client.GetResource(uri)
+		if err != nil {
+			return nil, err
+		}
+
+		// Pass the contents through as a map, which the server serves as is
+		data, err := json.Marshal(response)
+		if err != nil {
+			return nil, err
+		}
+		var result map[string]interface{}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+}
+
+// listCatalog lists the tools, resources and resource templates of a backend
+func listCatalog(c client.Client) ([]client.Tool, []client.Resource, []client.ResourceTemplate, error) {
+	tools, err := c.ListTools()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	resources, err := c.ListResources()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+	templates, err := c.ListResourceTemplates()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list resource templates: %w", err)
+	}
+	return tools, resources, templates, nil
+}
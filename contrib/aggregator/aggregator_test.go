@@ -0,0 +1,216 @@
+package aggregator
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/client"
+	"github.com/localrivet/gomcp/server"
+	"github.com/localrivet/gomcp/transport/embedded"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// serve runs a server on an embedded transport pair and returns a client of it
+func serve(t *testing.T, s server.Server) client.Client {
+	t.Helper()
+
+	serverTransport, clientTransport := embedded.NewTransportPair()
+	s.AsEmbedded(serverTransport)
+
+	ctx, stop := context.WithCancel(context.Background())
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		_ = s.Serve(ctx)
+	}()
+
+	c, err := client.NewClient("aggregator-test",
+		client.WithEmbedded(clientTransport),
+		client.WithLogger(discard),
+		client.WithRequestTimeout(5*time.Second),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		c.Close()
+		stop()
+		<-served
+	})
+	return c
+}
+
+// startBackend starts a server with an echo tool for each name, answering with
+// the backend's name
+func startBackend(t *testing.T, name string, tools ...string) (server.Server, client.Client) {
+	t.Helper()
+
+	s := server.NewServer(name, server.WithLogger(discard))
+	for _, tool := range tools {
+		s.Tool(tool, "Answer with the backend's name", func(ctx *server.Context, args struct{}) (string, error) {
+			return name, nil
+		})
+	}
+	s.Resource("/"+name+"/status", "Status of "+name, func(ctx *server.Context, args interface{}) (string, error) {
+		return name + " is up", nil
+	})
+	s.Resource("/shared", "A resource every backend offers", func(ctx *server.Context, args interface{}) (string, error) {
+		return name, nil
+	})
+	return s, serve(t, s)
+}
+
+// startGateway starts a server proxying the aggregator and returns a client of it
+func startGateway(t *testing.T, agg *Aggregator) client.Client {
+	t.Helper()
+
+	s := server.NewServer("gateway", server.WithLogger(discard))
+	s.GetServer().UseProvider(agg)
+	t.Cleanup(agg.Close)
+	return serve(t, s)
+}
+
+// toolNames lists the tools a client sees, sorted
+func toolNames(t *testing.T, c client.Client) []string {
+	t.Helper()
+
+	tools, err := c.ListTools()
+	require.NoError(t, err)
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// callText calls a tool and returns the text of its result
+func callText(t *testing.T, c client.Client, name string) string {
+	t.Helper()
+
+	result, err := c.CallTool(name, map[string]interface{}{})
+	require.NoError(t, err)
+	content := result.(map[string]interface{})["content"].([]interface{})
+	return content[0].(map[string]interface{})["text"].(string)
+}
+
+func TestAggregatorPriority(t *testing.T) {
+	_, alpha := startBackend(t, "alpha", "search", "alpha_only")
+	_, beta := startBackend(t, "beta", "search", "beta_only")
+
+	agg := New()
+	require.NoError(t, agg.AddBackend("alpha", alpha, 0))
+	require.NoError(t, agg.AddBackend("beta", beta, 10))
+	assert.Error(t, agg.AddBackend("beta", beta, 0), "backend names are unique")
+	gateway := startGateway(t, agg)
+
+	assert.Equal(t, []string{"alpha_only", "beta_only", "search"}, toolNames(t, gateway))
+	assert.Equal(t, "beta", callText(t, gateway, "search"), "the highest priority wins")
+	assert.Equal(t, "alpha", callText(t, gateway, "alpha_only"))
+
+	shared, err := gateway.GetResource("/shared")
+	require.NoError(t, err)
+	require.NotEmpty(t, shared.Contents)
+	assert.Equal(t, "beta", shared.Contents[0].Text)
+
+	status, err := gateway.GetResource("/alpha/status")
+	require.NoError(t, err)
+	require.NotEmpty(t, status.Contents)
+	assert.Equal(t, "alpha is up", status.Contents[0].Text)
+
+	assert.Contains(t, agg.Routes(), Route{Kind: "tool", Name: "search", Backend: "beta", Target: "search"})
+}
+
+func TestAggregatorNamespace(t *testing.T) {
+	_, alpha := startBackend(t, "alpha", "search")
+	_, beta := startBackend(t, "beta", "search")
+
+	agg := New(WithConflictPolicy(Namespace), WithSeparator("."))
+	require.NoError(t, agg.AddBackend("alpha", alpha, 0))
+	require.NoError(t, agg.AddBackend("beta", beta, 0))
+	gateway := startGateway(t, agg)
+
+	assert.Equal(t, []string{"alpha.search", "beta.search"}, toolNames(t, gateway))
+	assert.Equal(t, "alpha", callText(t, gateway, "alpha.search"))
+	assert.Equal(t, "beta", callText(t, gateway, "beta.search"))
+}
+
+func TestAggregatorCoalescesListChanged(t *testing.T) {
+	alphaServer, alpha := startBackend(t, "alpha", "search")
+	_, beta := startBackend(t, "beta", "lookup")
+
+	agg := New()
+	require.NoError(t, agg.AddBackend("alpha", alpha, 0))
+	require.NoError(t, agg.AddBackend("beta", beta, 0))
+	gateway := startGateway(t, agg)
+
+	var mu sync.Mutex
+	changes := map[string]int{}
+	gateway.OnListChanged(func(kind string) {
+		mu.Lock()
+		changes[kind]++
+		mu.Unlock()
+	})
+	counted := func() map[string]int {
+		// Let notifications still in flight arrive
+		time.Sleep(200 * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		counts := changes
+		changes = map[string]int{}
+		return counts
+	}
+
+	// Discard the notifications of the initial registration
+	counted()
+
+	// A backend adding tools reaches upstream as one change
+	alphaServer.GetServer().BatchChanges(func(srv server.Server) {
+		srv.GetServer().RemoveTool("search")
+		for _, name := range []string{"search_v2", "index", "stats"} {
+			srv.Tool(name, "Answer with the backend's name", func(ctx *server.Context, args struct{}) (string, error) {
+				return "alpha", nil
+			})
+		}
+	})
+	require.Eventually(t, func() bool {
+		return len(toolNames(t, gateway)) == 4
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, []string{"index", "lookup", "search_v2", "stats"}, toolNames(t, gateway))
+	assert.Equal(t, map[string]int{"tools": 1}, counted())
+
+	// Removing a backend withdraws its tools and resources together
+	require.NoError(t, agg.RemoveBackend("alpha"))
+	assert.Equal(t, []string{"lookup"}, toolNames(t, gateway))
+	_, err := gateway.GetResource("/alpha/status")
+	assert.Error(t, err)
+	assert.Equal(t, map[string]int{"tools": 1, "resources": 1}, counted())
+	assert.Error(t, agg.RemoveBackend("alpha"))
+}
+
+func TestAggregatorWithdrawsUnavailableBackend(t *testing.T) {
+	_, alpha := startBackend(t, "alpha", "search")
+	betaServer := server.NewServer("beta", server.WithLogger(discard))
+	betaServer.Tool("lookup", "Look up", func(ctx *server.Context, args struct{}) (string, error) {
+		return "beta", nil
+	})
+	beta := serve(t, betaServer)
+
+	agg := New(WithHealthCheck(20 * time.Millisecond))
+	require.NoError(t, agg.AddBackend("alpha", alpha, 0))
+	require.NoError(t, agg.AddBackend("beta", beta, 0))
+	gateway := startGateway(t, agg)
+	assert.Equal(t, []string{"lookup", "search"}, toolNames(t, gateway))
+
+	require.NoError(t, beta.Close())
+	require.Eventually(t, func() bool {
+		names := toolNames(t, gateway)
+		return len(names) == 1 && names[0] == "search"
+	}, 5*time.Second, 20*time.Millisecond)
+}
@@ -0,0 +1,158 @@
+// Package gitmcp is a tool pack that exposes Git repositories through an MCP server.
+//
+// New returns a server.ToolProvider registering tools to inspect the status, log,
+// diffs, blame and commits of a repository, and resources listing its branches and
+// describing its commits. Repositories are read with go-git, so no git binary is
+// needed, and only repositories within the server's registered roots are served:
+//
+//	s := server.NewServer("git-server").Root("/srv/workspace")
+//	s.UseProvider(gitmcp.New(gitmcp.WithMaxLogEntries(100)))
+//	s.AsStdio().Run()
+package gitmcp
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/localrivet/gomcp/contrib/internal/rootpath"
+	"github.com/localrivet/gomcp/server"
+)
+
+// Default settings of the tool pack.
+const (
+	DefaultURIPrefix     = "/git"
+	DefaultMaxLogEntries = 50
+	DefaultMaxOutput     = 256 * 1024
+)
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithMaxLogEntries limits how many commits git_log returns, whatever the client
+// asks for.
+func WithMaxLogEntries(n int) Option {
+	return func(p *Provider) {
+		p.maxLogEntries = n
+	}
+}
+
+// WithMaxOutput limits the size in bytes of the patches git_diff and git_show
+// return, and of the lines git_blame returns. Longer output is truncated. Zero means
+// no limit.
+func WithMaxOutput(bytes int) Option {
+	return func(p *Provider) {
+		p.maxOutput = bytes
+	}
+}
+
+// WithURIPrefix sets the prefix of the resources. The default is "/git".
+func WithURIPrefix(prefix string) Option {
+	return func(p *Provider) {
+		p.prefix = strings.TrimSuffix(prefix, "/")
+	}
+}
+
+// Provider is a tool pack exposing Git repositories. Create it with New.
+type Provider struct {
+	maxLogEntries int
+	maxOutput     int
+	prefix        string
+}
+
+// New returns a provider of the Git tools and resources.
+func New(options ...Option) *Provider {
+	p := &Provider{
+		maxLogEntries: DefaultMaxLogEntries,
+		maxOutput:     DefaultMaxOutput,
+		prefix:        DefaultURIPrefix,
+	}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// Register implements server.ToolProvider. It registers:
+//
+//   - git_status, git_log, git_diff, git_blame and git_show, each taking the
+//     repository to read, by default the one containing the server's first root
+//   - <prefix>/branches, listing the branches of that repository
+//   - <prefix>/commits/{sha}, describing one of its commits
+func (p *Provider) Register(s server.Server) error {
+	readOnly := map[string]interface{}{"readOnlyHint": true}
+	s.Tool("git_status", "Show the current branch and the changed files of the working tree", p.status, readOnly)
+	s.Tool("git_log", "List the commits reachable from a revision, most recent first", p.log, readOnly)
+	s.Tool("git_diff", "Show the changes between two revisions as a unified diff", p.diff, readOnly)
+	s.Tool("git_blame", "Show the commit that last changed each line of a file", p.blame, readOnly)
+	s.Tool("git_show", "Show a commit and its changes as a unified diff", p.show, readOnly)
+
+	s.Resource(p.prefix+"/branches", "Branches of the Git repository", p.listBranches)
+	s.Resource(p.prefix+"/commits/{sha}", "A commit of the Git repository", p.describeCommit)
+	return nil
+}
+
+// openRepository opens the repository containing dir, which is relative to the
+// first root unless absolute. Both dir and the repository's working tree must be
+// within one of the roots once symbolic links are resolved.
+func openRepository(roots []string, dir string) (*git.Repository, error) {
+	if len(roots) == 0 {
+		return nil, errors.New("no roots are registered to serve repositories from")
+	}
+	if dir == "" {
+		dir = roots[0]
+	} else if !filepath.IsAbs(dir) {
+		dir = filepath.Join(roots[0], dir)
+	}
+
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository path %s: %w", dir, err)
+	}
+	if !rootpath.Within(roots, resolved) {
+		return nil, fmt.Errorf("repository path %s is outside the registered roots", dir)
+	}
+
+	repo, err := git.PlainOpenWithOptions(resolved, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository at %s: %w", dir, err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository at %s: %w", dir, err)
+	}
+	// The repository found may enclose the roots rather than lie within them
+	if !rootpath.Within(roots, worktree.Filesystem.Root()) {
+		return nil, fmt.Errorf("repository of %s is outside the registered roots", dir)
+	}
+	return repo, nil
+}
+
+// resolveCommit returns the commit a revision, such as HEAD, a branch, a tag or a
+// hash, refers to
+func resolveCommit(repo *git.Repository, revision string) (*object.Commit, error) {
+	if revision == "" {
+		revision = "HEAD"
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("unknown revision %s: %w", revision, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("revision %s is not a commit: %w", revision, err)
+	}
+	return commit, nil
+}
+
+// truncate cuts text to the maximum output size, reporting whether it did
+func (p *Provider) truncate(text string) (string, bool) {
+	if p.maxOutput > 0 && len(text) > p.maxOutput {
+		return text[:p.maxOutput], true
+	}
+	return text, false
+}
@@ -0,0 +1,213 @@
+package gitmcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/localrivet/gomcp/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupRepository creates a repository with two commits on main, a feature branch
+// at the first, and uncommitted changes, and a server serving it
+func setupRepository(t *testing.T, options ...Option) (server.Server, string, []string) {
+	t.Helper()
+
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	repo, err := git.PlainInitWithOptions(root, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	var commits []string
+	commit := func(content, message string, day int) {
+		require.NoError(t, os.WriteFile(filepath.Join(root, "notes.txt"), []byte(content), 0o644))
+		_, err := worktree.Add("notes.txt")
+		require.NoError(t, err)
+		hash, err := worktree.Commit(message, &git.CommitOptions{Author: &object.Signature{
+			Name: "Ada", Email: "ada@example.com", When: time.Date(2025, 1, day, 12, 0, 0, 0, time.UTC),
+		}})
+		require.NoError(t, err)
+		commits = append(commits, hash.String())
+	}
+	commit("one\n", "Add notes", 1)
+	commit("one\ntwo\n", "Add a second line", 2)
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/feature", plumbing.NewHash(commits[0]))))
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "notes.txt"), []byte("one\ntwo\nthree\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "draft.txt"), []byte("draft\n"), 0o644))
+
+	s := server.NewServer("git-test").Root(root)
+	s.GetServer().UseProvider(New(options...))
+	return s, root, commits
+}
+
+// call sends a request to the server and returns its result, or its error message
+func call(t *testing.T, s server.Server, method string, params interface{}) (json.RawMessage, string) {
+	t.Helper()
+
+	request, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	require.NoError(t, err)
+	response, err := server.HandleMessage(s.GetServer(), request)
+	require.NoError(t, err)
+
+	var decoded struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(response, &decoded))
+	if decoded.Error != nil {
+		return nil, decoded.Error.Message
+	}
+	return decoded.Result, ""
+}
+
+// callTool calls a tool and decodes its result into result, returning its error
+func callTool(t *testing.T, s server.Server, name string, args map[string]interface{}, result interface{}) string {
+	t.Helper()
+
+	raw, errData := call(t, s, "tools/call", map[string]interface{}{"name": name, "arguments": args})
+	if errData != "" {
+		return errData
+	}
+	var decoded struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	require.NotEmpty(t, decoded.Content)
+	if decoded.IsError {
+		return decoded.Content[0].Text
+	}
+	require.NoError(t, json.Unmarshal([]byte(decoded.Content[0].Text), result))
+	return ""
+}
+
+// readResource decodes the JSON contents of a resource into result, returning its error
+func readResource(t *testing.T, s server.Server, uri string, result interface{}) string {
+	t.Helper()
+
+	raw, errData := call(t, s, "resources/read", map[string]interface{}{"uri": uri})
+	if errData != "" {
+		return errData
+	}
+	var decoded struct {
+		Contents []struct {
+			Text string `json:"text"`
+		} `json:"contents"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	require.Len(t, decoded.Contents, 1)
+	if err := json.Unmarshal([]byte(decoded.Contents[0].Text), result); err != nil {
+		return decoded.Contents[0].Text
+	}
+	return ""
+}
+
+func TestTools(t *testing.T) {
+	s, _, commits := setupRepository(t)
+
+	var status StatusResult
+	require.Empty(t, callTool(t, s, "git_status", nil, &status))
+	assert.Equal(t, StatusResult{Branch: "main", Files: []FileStatus{
+		{Path: "draft.txt", Staging: "untracked", Worktree: "untracked"},
+		{Path: "notes.txt", Staging: "unmodified", Worktree: "modified"},
+	}}, status)
+
+	var log LogResult
+	require.Empty(t, callTool(t, s, "git_log", nil, &log))
+	require.Len(t, log.Commits, 2)
+	assert.Equal(t, commits[1], log.Commits[0].SHA)
+	assert.Equal(t, "Add a second line", log.Commits[0].Message)
+	assert.Equal(t, "Ada", log.Commits[0].Author)
+	assert.Equal(t, []string{commits[0]}, log.Commits[0].Parents)
+	require.Empty(t, callTool(t, s, "git_log", map[string]interface{}{"revision": "feature", "max": 1}, &log))
+	assert.Len(t, log.Commits, 1)
+	assert.Equal(t, commits[0], log.Commits[0].SHA)
+
+	var diff DiffResult
+	require.Empty(t, callTool(t, s, "git_diff", nil, &diff))
+	assert.Equal(t, commits[0], diff.From)
+	assert.Contains(t, diff.Patch, "+two")
+	require.Empty(t, callTool(t, s, "git_diff", map[string]interface{}{"from": "main", "to": "feature"}, &diff))
+	assert.Contains(t, diff.Patch, "-two")
+
+	var blame BlameResult
+	require.Empty(t, callTool(t, s, "git_blame", map[string]interface{}{"path": "notes.txt"}, &blame))
+	require.Len(t, blame.Lines, 2)
+	assert.Equal(t, BlameLine{Line: 2, SHA: commits[1], Author: "Ada", Date: blame.Lines[1].Date, Text: "two"}, blame.Lines[1])
+	assert.Equal(t, commits[0], blame.Lines[0].SHA)
+
+	// The root commit is shown against the empty tree
+	var show ShowResult
+	require.Empty(t, callTool(t, s, "git_show", map[string]interface{}{"revision": commits[0]}, &show))
+	assert.Equal(t, "Add notes", show.Message)
+	assert.Contains(t, show.Patch, "+one")
+
+	assert.Contains(t, callTool(t, s, "git_show", map[string]interface{}{"revision": "missing"}, &show), "unknown revision missing")
+	assert.Contains(t, callTool(t, s, "git_blame", map[string]interface{}{"path": "missing.txt"}, &blame), "failed to blame missing.txt")
+}
+
+func TestResources(t *testing.T) {
+	s, _, commits := setupRepository(t)
+
+	var branches []Branch
+	require.Empty(t, readResource(t, s, "/git/branches", &branches))
+	assert.Equal(t, []Branch{
+		{Name: "feature", SHA: commits[0], URI: "/git/commits/" + commits[0]},
+		{Name: "main", SHA: commits[1], URI: "/git/commits/" + commits[1], Head: true},
+	}, branches)
+
+	var details CommitDetails
+	require.Empty(t, readResource(t, s, "/git/commits/"+commits[1], &details))
+	assert.Equal(t, "Add a second line", details.Message)
+	assert.Equal(t, []FileChange{{Path: "notes.txt", Additions: 1}}, details.Files)
+
+	// Commits are only named by their hash
+	assert.NotEmpty(t, readResource(t, s, "/git/commits/main", &details))
+}
+
+func TestRepositoriesAreConfinedToRoots(t *testing.T) {
+	s, root, _ := setupRepository(t)
+	require.NoError(t, os.Mkdir(filepath.Join(root, "sub"), 0o755))
+
+	var status StatusResult
+	assert.Contains(t, callTool(t, s, "git_status", map[string]interface{}{"repo": ".."}, &status), "outside the registered roots")
+	assert.Contains(t, callTool(t, s, "git_status", map[string]interface{}{"repo": "/"}, &status), "outside the registered roots")
+
+	// A root within a repository does not give access to the repository
+	inner := server.NewServer("git-test").Root(filepath.Join(root, "sub"))
+	inner.GetServer().UseProvider(New())
+	errData := callTool(t, inner, "git_status", nil, &status)
+	assert.Contains(t, errData, "outside the registered roots")
+	assert.False(t, strings.Contains(errData, "draft.txt"))
+}
+
+func TestOutputLimits(t *testing.T) {
+	s, _, _ := setupRepository(t, WithMaxOutput(20), WithMaxLogEntries(1))
+
+	var log LogResult
+	require.Empty(t, callTool(t, s, "git_log", map[string]interface{}{"max": 5}, &log))
+	assert.Len(t, log.Commits, 1)
+	assert.True(t, log.Truncated)
+
+	var diff DiffResult
+	require.Empty(t, callTool(t, s, "git_diff", nil, &diff))
+	assert.Len(t, diff.Patch, 20)
+	assert.True(t, diff.Truncated)
+}
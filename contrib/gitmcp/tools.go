@@ -0,0 +1,478 @@
+package gitmcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/localrivet/gomcp/server"
+)
+
+// StatusArgs are the arguments of the git_status tool.
+type StatusArgs struct {
+	Repo *string `json:"repo,omitempty" description:"Path of the repository, relative to the first root; defaults to the first root"`
+}
+
+// LogArgs are the arguments of the git_log tool.
+type LogArgs struct {
+	Repo     *string `json:"repo,omitempty" description:"Path of the repository, relative to the first root; defaults to the first root"`
+	Revision *string `json:"revision,omitempty" description:"Branch, tag or commit to list the history of; defaults to HEAD"`
+	Path     *string `json:"path,omitempty" description:"Only list commits changing this file"`
+	Max      *int    `json:"max,omitempty" description:"Maximum number of commits to list"`
+}
+
+// DiffArgs are the arguments of the git_diff tool.
+type DiffArgs struct {
+	Repo *string `json:"repo,omitempty" description:"Path of the repository, relative to the first root; defaults to the first root"`
+	From *string `json:"from,omitempty" description:"Revision to compare from; defaults to the first parent of to"`
+	To   *string `json:"to,omitempty" description:"Revision to compare to; defaults to HEAD"`
+}
+
+// BlameArgs are the arguments of the git_blame tool.
+type BlameArgs struct {
+	Repo     *string `json:"repo,omitempty" description:"Path of the repository, relative to the first root; defaults to the first root"`
+	Path     string  `json:"path" description:"Path of the file within the repository"`
+	Revision *string `json:"revision,omitempty" description:"Revision to blame the file at; defaults to HEAD"`
+}
+
+// ShowArgs are the arguments of the git_show tool.
+type ShowArgs struct {
+	Repo     *string `json:"repo,omitempty" description:"Path of the repository, relative to the first root; defaults to the first root"`
+	Revision *string `json:"revision,omitempty" description:"Revision of the commit to show; defaults to HEAD"`
+}
+
+// Commit describes a commit.
+type Commit struct {
+	SHA     string    `json:"sha"`
+	URI     string    `json:"uri"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email"`
+	Date    time.Time `json:"date"`
+	Message string    `json:"message"`
+	Parents []string  `json:"parents,omitempty"`
+}
+
+// FileStatus is the status of a changed file, in the index (Staging) and in the
+// working tree (Worktree): "unmodified", "untracked", "modified", "added",
+// "deleted", "renamed", "copied" or "unmerged".
+type FileStatus struct {
+	Path     string `json:"path"`
+	Staging  string `json:"staging"`
+	Worktree string `json:"worktree"`
+}
+
+// StatusResult is the result of the git_status tool.
+type StatusResult struct {
+	Branch string       `json:"branch,omitempty"`
+	Clean  bool         `json:"clean"`
+	Files  []FileStatus `json:"files"`
+}
+
+// LogResult is the result of the git_log tool. Truncated is set when there are more
+// commits than were listed.
+type LogResult struct {
+	Commits   []Commit `json:"commits"`
+	Truncated bool     `json:"truncated,omitempty"`
+}
+
+// DiffResult is the result of the git_diff tool.
+type DiffResult struct {
+	From      string `json:"from,omitempty"`
+	To        string `json:"to"`
+	Patch     string `json:"patch"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// BlameLine is a line of a blamed file and the commit that last changed it.
+type BlameLine struct {
+	Line   int       `json:"line"`
+	SHA    string    `json:"sha"`
+	Author string    `json:"author"`
+	Date   time.Time `json:"date"`
+	Text   string    `json:"text"`
+}
+
+// BlameResult is the result of the git_blame tool.
+type BlameResult struct {
+	Path      string      `json:"path"`
+	Revision  string      `json:"revision"`
+	Lines     []BlameLine `json:"lines"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
+
+// ShowResult is the result of the git_show tool.
+type ShowResult struct {
+	Commit
+	Patch     string `json:"patch"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// Branch describes a branch.
+type Branch struct {
+	Name string `json:"name"`
+	SHA  string `json:"sha"`
+	URI  string `json:"uri"`
+	Head bool   `json:"head,omitempty"`
+}
+
+// CommitDetails describes a commit and the files it changed, as served by the
+// commit resource.
+type CommitDetails struct {
+	Commit
+	Files []FileChange `json:"files"`
+}
+
+// FileChange is the number of lines a commit added to and deleted from a file.
+type FileChange struct {
+	Path      string `json:"path"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// status runs the git_status tool
+func (p *Provider) status(ctx *server.Context, args StatusArgs) (interface{}, error) {
+	repo, err := openRepository(ctx.GetRoots(), optional(args.Repo))
+	if err != nil {
+		return nil, err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the working tree: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the status: %w", err)
+	}
+
+	result := StatusResult{Clean: status.IsClean(), Files: []FileStatus{}}
+	if head, err := repo.Head(); err == nil && head.Name().IsBranch() {
+		result.Branch = head.Name().Short()
+	}
+	for path, file := range status {
+		if file.Staging == git.Unmodified && file.Worktree == git.Unmodified {
+			continue
+		}
+		result.Files = append(result.Files, FileStatus{
+			Path:     path,
+			Staging:  statusName(file.Staging),
+			Worktree: statusName(file.Worktree),
+		})
+	}
+	sort.Slice(result.Files, func(i, j int) bool { return result.Files[i].Path < result.Files[j].Path })
+	return result, nil
+}
+
+// log runs the git_log tool
+func (p *Provider) log(ctx *server.Context, args LogArgs) (interface{}, error) {
+	repo, err := openRepository(ctx.GetRoots(), optional(args.Repo))
+	if err != nil {
+		return nil, err
+	}
+	from, err := resolveCommit(repo, optional(args.Revision))
+	if err != nil {
+		return nil, err
+	}
+
+	limit := p.maxLogEntries
+	if args.Max != nil && *args.Max > 0 && (limit <= 0 || *args.Max < limit) {
+		limit = *args.Max
+	}
+	options := &git.LogOptions{From: from.Hash, Order: git.LogOrderCommitterTime}
+	if path := optional(args.Path); path != "" {
+		options.FileName = &path
+	}
+	commits, err := repo.Log(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the log: %w", err)
+	}
+	defer commits.Close()
+
+	result := LogResult{Commits: []Commit{}}
+	err = commits.ForEach(func(commit *object.Commit) error {
+		if limit > 0 && len(result.Commits) >= limit {
+			result.Truncated = true
+			return storer.ErrStop
+		}
+		result.Commits = append(result.Commits, p.describe(commit))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the log: %w", err)
+	}
+	return result, nil
+}
+
+// diff runs the git_diff tool
+func (p *Provider) diff(ctx *server.Context, args DiffArgs) (interface{}, error) {
+	repo, err := openRepository(ctx.GetRoots(), optional(args.Repo))
+	if err != nil {
+		return nil, err
+	}
+	to, err := resolveCommit(repo, optional(args.To))
+	if err != nil {
+		return nil, err
+	}
+
+	var from *object.Commit
+	if revision := optional(args.From); revision != "" {
+		if from, err = resolveCommit(repo, revision); err != nil {
+			return nil, err
+		}
+	} else if from, err = firstParent(to); err != nil {
+		return nil, err
+	}
+
+	patch, err := patchBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	result := DiffResult{To: to.Hash.String()}
+	if from != nil {
+		result.From = from.Hash.String()
+	}
+	result.Patch, result.Truncated = p.truncate(patch)
+	return result, nil
+}
+
+// blame runs the git_blame tool
+func (p *Provider) blame(ctx *server.Context, args BlameArgs) (interface{}, error) {
+	if args.Path == "" {
+		return nil, errors.New("path is required")
+	}
+	repo, err := openRepository(ctx.GetRoots(), optional(args.Repo))
+	if err != nil {
+		return nil, err
+	}
+	commit, err := resolveCommit(repo, optional(args.Revision))
+	if err != nil {
+		return nil, err
+	}
+	blame, err := git.Blame(commit, args.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame %s: %w", args.Path, err)
+	}
+
+	result := BlameResult{Path: args.Path, Revision: commit.Hash.String(), Lines: []BlameLine{}}
+	size := 0
+	for i, line := range blame.Lines {
+		size += len(line.Text) + 1
+		if p.maxOutput > 0 && size > p.maxOutput {
+			result.Truncated = true
+			break
+		}
+		result.Lines = append(result.Lines, BlameLine{
+			Line:   i + 1,
+			SHA:    line.Hash.String(),
+			Author: line.AuthorName,
+			Date:   line.Date,
+			Text:   line.Text,
+		})
+	}
+	return result, nil
+}
+
+// show runs the git_show tool
+func (p *Provider) show(ctx *server.Context, args ShowArgs) (interface{}, error) {
+	repo, err := openRepository(ctx.GetRoots(), optional(args.Repo))
+	if err != nil {
+		return nil, err
+	}
+	commit, err := resolveCommit(repo, optional(args.Revision))
+	if err != nil {
+		return nil, err
+	}
+	parent, err := firstParent(commit)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := patchBetween(parent, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := ShowResult{Commit: p.describe(commit)}
+	result.Patch, result.Truncated = p.truncate(patch)
+	return result, nil
+}
+
+// listBranches serves the branch listing resource
+func (p *Provider) listBranches(ctx *server.Context, args interface{}) (interface{}, error) {
+	repo, err := openRepository(ctx.GetRoots(), "")
+	if err != nil {
+		return nil, err
+	}
+	refs, err := repo.Branches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	defer refs.Close()
+
+	var head plumbing.ReferenceName
+	if ref, err := repo.Head(); err == nil {
+		head = ref.Name()
+	}
+	branches := []Branch{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		branches = append(branches, Branch{
+			Name: ref.Name().Short(),
+			SHA:  ref.Hash().String(),
+			URI:  p.commitURI(ref.Hash().String()),
+			Head: ref.Name() == head,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	sort.Slice(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+
+	return jsonContents(p.prefix+"/branches", branches)
+}
+
+// describeCommit serves the resource describing a commit
+func (p *Provider) describeCommit(ctx *server.Context, args interface{}) (interface{}, error) {
+	params, _ := args.(map[string]interface{})
+	sha, _ := params["sha"].(string)
+	if sha == "" {
+		return nil, errors.New("commit sha is required")
+	}
+	repo, err := openRepository(ctx.GetRoots(), "")
+	if err != nil {
+		return nil, err
+	}
+	// Only hashes are accepted, so that the URI of a commit never changes meaning
+	hash, err := repo.ResolveRevision(plumbing.Revision(sha))
+	if err != nil || !strings.HasPrefix(hash.String(), strings.ToLower(sha)) {
+		return nil, fmt.Errorf("commit not found: %s", sha)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("commit not found: %s", sha)
+	}
+	stats, err := commit.Stats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the changes of %s: %w", sha, err)
+	}
+
+	details := CommitDetails{Commit: p.describe(commit), Files: []FileChange{}}
+	for _, stat := range stats {
+		details.Files = append(details.Files, FileChange{Path: stat.Name, Additions: stat.Addition, Deletions: stat.Deletion})
+	}
+	return jsonContents(p.commitURI(sha), details)
+}
+
+// describe returns the description of a commit
+func (p *Provider) describe(commit *object.Commit) Commit {
+	described := Commit{
+		SHA:     commit.Hash.String(),
+		URI:     p.commitURI(commit.Hash.String()),
+		Author:  commit.Author.Name,
+		Email:   commit.Author.Email,
+		Date:    commit.Author.When,
+		Message: strings.TrimSpace(commit.Message),
+	}
+	for _, parent := range commit.ParentHashes {
+		described.Parents = append(described.Parents, parent.String())
+	}
+	return described
+}
+
+// commitURI returns the resource URI of a commit
+func (p *Provider) commitURI(sha string) string {
+	return p.prefix + "/commits/" + sha
+}
+
+// firstParent returns the first parent of a commit, or nil for a root commit
+func firstParent(commit *object.Commit) (*object.Commit, error) {
+	if commit.NumParents() == 0 {
+		return nil, nil
+	}
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the parent of %s: %w", commit.Hash, err)
+	}
+	return parent, nil
+}
+
+// patchBetween returns the unified diff between two commits, from being nil for the
+// empty tree
+func patchBetween(from, to *object.Commit) (string, error) {
+	var fromTree *object.Tree
+	if from != nil {
+		var err error
+		if fromTree, err = from.Tree(); err != nil {
+			return "", fmt.Errorf("failed to read the tree of %s: %w", from.Hash, err)
+		}
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the tree of %s: %w", to.Hash, err)
+	}
+
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff: %w", err)
+	}
+	patch, err := changes.Patch()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff: %w", err)
+	}
+	return patch.String(), nil
+}
+
+// statusName names a file status code
+func statusName(code git.StatusCode) string {
+	switch code {
+	case git.Unmodified:
+		return "unmodified"
+	case git.Untracked:
+		return "untracked"
+	case git.Modified:
+		return "modified"
+	case git.Added:
+		return "added"
+	case git.Deleted:
+		return "deleted"
+	case git.Renamed:
+		return "renamed"
+	case git.Copied:
+		return "copied"
+	case git.UpdatedButUnmerged:
+		return "unmerged"
+	default:
+		return string(rune(code))
+	}
+}
+
+// optional returns the value of an optional string argument
+func optional(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// jsonContents returns a value as the JSON contents of a resource
+func jsonContents(uri string, value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", uri, err)
+	}
+
+	return map[string]interface{}{
+		"contents": []interface{}{
+			map[string]interface{}{
+				"uri":      uri,
+				"mimeType": "application/json",
+				"text":     string(data),
+			},
+		},
+	}, nil
+}
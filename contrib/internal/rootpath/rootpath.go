@@ -0,0 +1,24 @@
+// Package rootpath confines the paths the contrib tool packs use to the server's
+// registered roots.
+package rootpath
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Within reports whether a resolved path is within one of the roots. The roots are
+// resolved here, so a root that is a symbolic link contains what it points to.
+func Within(roots []string, path string) bool {
+	for _, root := range roots {
+		resolvedRoot, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(resolvedRoot, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
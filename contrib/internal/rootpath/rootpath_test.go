@@ -0,0 +1,25 @@
+package rootpath
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithin(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	link := filepath.Join(t.TempDir(), "link")
+	require.NoError(t, os.Symlink(root, link))
+
+	assert.True(t, Within([]string{root}, root))
+	assert.True(t, Within([]string{root}, filepath.Join(root, "sub", "file")))
+	assert.True(t, Within([]string{"/missing", link}, filepath.Join(root, "file")), "roots are resolved")
+	assert.True(t, Within([]string{root}, filepath.Join(root, "..file")))
+	assert.False(t, Within([]string{root}, filepath.Dir(root)))
+	assert.False(t, Within([]string{root}, root+"-sibling"))
+	assert.False(t, Within(nil, root))
+}
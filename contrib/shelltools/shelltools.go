@@ -0,0 +1,392 @@
+// Package shelltools is a tool pack that lets clients run commands on the server's
+// machine under a sandbox policy.
+//
+// New returns a server.ToolProvider exposing a run_command tool. Only the commands
+// the policy allows can run, always inside the server's registered roots, with a
+// scrubbed environment and bounded output:
+//
+//	s := server.NewServer("shell-server").Root("/srv/workspace")
+//	s.UseProvider(shelltools.New(
+//	    shelltools.WithAllowedCommands("go", "git", "ls"),
+//	    shelltools.WithTimeout(time.Minute),
+//	))
+//	s.AsStdio().Run()
+//
+// Commands are run directly, never through a shell, so their arguments are passed
+// as they are and cannot chain further commands. Arguments naming paths outside
+// the roots are rejected.
+package shelltools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/localrivet/gomcp/contrib/internal/rootpath"
+	"github.com/localrivet/gomcp/server"
+)
+
+// Default settings of the tool pack.
+const (
+	DefaultToolName  = "run_command"
+	DefaultTimeout   = 30 * time.Second
+	DefaultMaxOutput = 64 * 1024
+
+	// AllowAnyCommand allows every command that is not denied when passed to
+	// WithAllowedCommands
+	AllowAnyCommand = "*"
+)
+
+// DefaultDeniedCommands are the commands that are denied unless WithDeniedCommands
+// is used: shells, interpreters and programs that run other programs, which would
+// get around the allowlist and the checks of arguments, and programs that change
+// user. A denied name also denies its versions, such as python3 or python3.12.
+var DefaultDeniedCommands = []string{
+	"sh", "bash", "zsh", "dash", "ksh", "csh", "tcsh", "fish",
+	"python", "perl", "ruby", "node", "php", "lua", "awk", "gawk", "tclsh", "osascript",
+	"env", "xargs", "nohup", "nice", "timeout", "chroot", "busybox",
+	"sudo", "su", "doas",
+}
+
+// DefaultEnvironment are the names of the environment variables commands inherit
+// unless WithEnvironment is used. All other variables, such as credentials, are
+// scrubbed.
+var DefaultEnvironment = []string{"PATH", "HOME", "LANG", "LC_ALL", "TZ", "TMPDIR"}
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithAllowedCommands sets the commands clients may run, by program name. Pass
+// AllowAnyCommand to allow every command that is not denied. No command is allowed
+// by default.
+func WithAllowedCommands(names ...string) Option {
+	return func(p *Provider) {
+		p.allowed = make(map[string]bool, len(names))
+		for _, name := range names {
+			p.allowed[name] = true
+		}
+	}
+}
+
+// WithDeniedCommands sets the commands clients may never run, even if allowed. It
+// replaces DefaultDeniedCommands; include them to keep them denied.
+func WithDeniedCommands(names ...string) Option {
+	return func(p *Provider) {
+		p.denied = make(map[string]bool, len(names))
+		for _, name := range names {
+			p.denied[name] = true
+		}
+	}
+}
+
+// WithEnvironment sets the names of the server's environment variables that commands
+// inherit. The default is DefaultEnvironment.
+func WithEnvironment(names ...string) Option {
+	return func(p *Provider) {
+		p.environment = names
+	}
+}
+
+// WithTimeout limits how long a command may run before it is killed. Zero means no
+// limit other than the request's own cancellation.
+func WithTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		p.timeout = timeout
+	}
+}
+
+// WithMaxOutput limits how many bytes of standard output, and of standard error,
+// are returned. Longer output is truncated. Zero means no limit.
+func WithMaxOutput(bytes int) Option {
+	return func(p *Provider) {
+		p.maxOutput = bytes
+	}
+}
+
+// WithToolName sets the name of the tool. The default is "run_command".
+func WithToolName(name string) Option {
+	return func(p *Provider) {
+		p.toolName = name
+	}
+}
+
+// RunArgs are the arguments of the run_command tool.
+type RunArgs struct {
+	Command string    `json:"command" description:"Name of the program to run, such as git; it is run directly, not through a shell"`
+	Args    *[]string `json:"args,omitempty" description:"Arguments passed to the program as they are"`
+	Dir     *string   `json:"dir,omitempty" description:"Working directory, relative to the first root or absolute within a root"`
+}
+
+// RunResult is the result of the run_command tool. A command exiting with a non-zero
+// status is a result, not an error.
+type RunResult struct {
+	ExitCode  int    `json:"exitCode"`
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// Provider is a tool pack exposing the run_command tool. Create it with New.
+type Provider struct {
+	allowed     map[string]bool
+	denied      map[string]bool
+	environment []string
+	timeout     time.Duration
+	maxOutput   int
+	toolName    string
+}
+
+// New returns a provider of the run_command tool with the given policy. Register it
+// with server.UseProvider; registering fails if no command is allowed.
+func New(options ...Option) *Provider {
+	p := &Provider{
+		allowed:     map[string]bool{},
+		environment: DefaultEnvironment,
+		timeout:     DefaultTimeout,
+		maxOutput:   DefaultMaxOutput,
+		toolName:    DefaultToolName,
+	}
+	WithDeniedCommands(DefaultDeniedCommands...)(p)
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// Register implements server.ToolProvider
+func (p *Provider) Register(s server.Server) error {
+	if len(p.allowed) == 0 {
+		return errors.New("shelltools: no commands are allowed, see WithAllowedCommands")
+	}
+
+	s.Tool(p.toolName, "Run a program in the workspace and return its exit code and output", p.run,
+		map[string]interface{}{"destructiveHint": true, "openWorldHint": true})
+	return nil
+}
+
+// run runs the run_command tool
+func (p *Provider) run(ctx *server.Context, args RunArgs) (interface{}, error) {
+	if err := p.checkCommand(args.Command); err != nil {
+		return nil, err
+	}
+
+	dir := ""
+	if args.Dir != nil {
+		dir = *args.Dir
+	}
+	roots := ctx.GetRoots()
+	dir, err := workingDir(roots, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var commandArgs []string
+	if args.Args != nil {
+		commandArgs = *args.Args
+	}
+	if err := checkArgs(roots, dir, commandArgs); err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := p.context(ctx.Context())
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, args.Command, commandArgs...)
+	cmd.Dir = dir
+	cmd.Env = p.env()
+	// Don't wait forever for programs the command started that keep its output open
+	cmd.WaitDelay = time.Second
+
+	stdout := &limitedBuffer{max: p.maxOutput}
+	stderr := &limitedBuffer{max: p.maxOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+	if runCtx.Err() != nil {
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("command %s timed out after %s", args.Command, p.timeout)
+		}
+		return nil, fmt.Errorf("command %s was cancelled", args.Command)
+	}
+
+	result := RunResult{
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		Truncated: stdout.truncated || stderr.truncated,
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		return nil, fmt.Errorf("failed to run %s: %w", args.Command, err)
+	}
+	return result, nil
+}
+
+// checkCommand rejects commands the policy does not allow. Commands are program
+// names looked up in PATH; paths are rejected, as they would get around the policy.
+func (p *Provider) checkCommand(name string) error {
+	if name == "" {
+		return errors.New("command is required")
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("command %s must be a program name, not a path", name)
+	}
+	// Versions of a denied program are denied too
+	denied := p.denied[name] || p.denied[strings.TrimRight(name, "0123456789.")]
+	if denied || !(p.allowed[name] || p.allowed[AllowAnyCommand]) {
+		return fmt.Errorf("command %s is not allowed", name)
+	}
+	return nil
+}
+
+// context returns the context a command runs in
+func (p *Provider) context(parent context.Context) (context.Context, context.CancelFunc) {
+	if parent == nil {
+		parent = context.Background()
+	}
+	if p.timeout > 0 {
+		return context.WithTimeout(parent, p.timeout)
+	}
+	return context.WithCancel(parent)
+}
+
+// env returns the scrubbed environment of a command
+func (p *Provider) env() []string {
+	env := []string{}
+	for _, name := range p.environment {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// workingDir resolves the directory a command runs in, which must be within one of
+// the roots once symbolic links are resolved. Relative directories are relative to
+// the first root, which is also the default.
+func workingDir(roots []string, dir string) (string, error) {
+	if len(roots) == 0 {
+		return "", errors.New("no roots are registered to run commands in")
+	}
+	if dir == "" {
+		dir = roots[0]
+	} else if !filepath.IsAbs(dir) {
+		dir = filepath.Join(roots[0], dir)
+	}
+
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid working directory %s: %w", dir, err)
+	}
+	if !rootpath.Within(roots, resolved) {
+		return "", fmt.Errorf("working directory %s is outside the registered roots", dir)
+	}
+	return resolved, nil
+}
+
+// checkArgs rejects arguments that name paths outside the roots, read relative to
+// the working directory once symbolic links are resolved. Option values given as
+// --name=value or attached to short options, as in -f/etc/passwd, are checked too.
+func checkArgs(roots []string, dir string, args []string) error {
+	for _, arg := range args {
+		for _, value := range argPaths(arg) {
+			path := value
+			if !filepath.IsAbs(path) {
+				// Not joined, which would remove ".." before symbolic links are resolved
+				path = dir + string(filepath.Separator) + path
+			}
+			if !rootpath.Within(roots, resolvePath(path)) {
+				return fmt.Errorf("argument %s is outside the registered roots", arg)
+			}
+		}
+	}
+	return nil
+}
+
+// argPaths returns the paths an argument may name: the argument itself, the value
+// of an option given as --name=value, or the value attached to short options. As
+// short options can be grouped, as in -xf/etc/passwd, every point after the option
+// letters where a path-like value could start is tried.
+func argPaths(arg string) []string {
+	if !strings.HasPrefix(arg, "-") {
+		if arg == "" {
+			return nil
+		}
+		return []string{arg}
+	}
+	if i := strings.IndexByte(arg, '='); i >= 0 {
+		if arg[i+1:] == "" {
+			return nil
+		}
+		return []string{arg[i+1:]}
+	}
+	if strings.HasPrefix(arg, "--") {
+		return nil
+	}
+
+	var paths []string
+	for i := 2; i < len(arg) && isOptionLetter(arg[i-1]); i++ {
+		if value := arg[i:]; strings.Contains(value, "/") || strings.HasPrefix(value, ".") {
+			paths = append(paths, value)
+		}
+	}
+	return paths
+}
+
+// isOptionLetter reports whether a character can name a short option
+func isOptionLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// resolvePath resolves the symbolic links of a path that may not exist yet: those
+// of the longest part that exists, followed by the rest of the path
+func resolvePath(path string) string {
+	separator := string(filepath.Separator)
+	rest := ""
+	for {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			return filepath.Join(resolved, rest)
+		}
+		parent, name := filepath.Split(strings.TrimRight(path, separator))
+		if name == "" {
+			// Nothing of the path exists
+			return filepath.Join(path, rest)
+		}
+		rest = filepath.Join(name, rest)
+		if path = strings.TrimRight(parent, separator); path == "" {
+			path = separator
+		}
+	}
+}
+
+// limitedBuffer keeps the first max bytes written to it and discards the rest, so
+// that a command producing too much output is not blocked writing it
+type limitedBuffer struct {
+	max       int
+	data      []byte
+	truncated bool
+}
+
+// Write implements io.Writer
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.max > 0 && len(b.data)+len(p) > b.max {
+		b.data = append(b.data, p[:b.max-len(b.data)]...)
+		b.truncated = true
+		return len(p), nil
+	}
+	b.data = append(b.data, p...)
+	return len(p), nil
+}
+
+// String returns the bytes kept
+func (b *limitedBuffer) String() string {
+	return string(b.data)
+}
@@ -0,0 +1,168 @@
+package shelltools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupServer returns a server with the tool pack and a root, skipping on platforms
+// without the Unix programs the tests run
+func setupServer(t *testing.T, options ...Option) (server.Server, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the tests run Unix programs")
+	}
+
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, os.Mkdir(filepath.Join(root, "sub"), 0o755))
+
+	s := server.NewServer("shell-test").Root(root)
+	s.GetServer().UseProvider(New(options...))
+	return s, root
+}
+
+// runCommand calls the run_command tool and returns its decoded result, or its error
+func runCommand(t *testing.T, s server.Server, args RunArgs) (RunResult, string) {
+	t.Helper()
+
+	request, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0", "id": 1, "method": "tools/call",
+		"params": map[string]interface{}{"name": DefaultToolName, "arguments": args},
+	})
+	require.NoError(t, err)
+	response, err := server.HandleMessage(s.GetServer(), request)
+	require.NoError(t, err)
+
+	var decoded struct {
+		Result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+			IsError bool `json:"isError"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(response, &decoded))
+	if decoded.Error != nil {
+		return RunResult{}, decoded.Error.Message
+	}
+	require.NotEmpty(t, decoded.Result.Content)
+	if decoded.Result.IsError {
+		return RunResult{}, decoded.Result.Content[0].Text
+	}
+
+	var result RunResult
+	require.NoError(t, json.Unmarshal([]byte(decoded.Result.Content[0].Text), &result))
+	return result, ""
+}
+
+func argv(values ...string) *[]string { return &values }
+func dir(path string) *string         { return &path }
+
+func TestRunCommand(t *testing.T) {
+	t.Setenv("SHELLTOOLS_TEST_SECRET", "hunter2")
+	s, root := setupServer(t,
+		WithAllowedCommands("echo", "pwd", "printenv", "false", "head"),
+		WithMaxOutput(256),
+	)
+
+	result, errData := runCommand(t, s, RunArgs{Command: "echo", Args: argv("hello", "$HOME; ls")})
+	require.Empty(t, errData)
+	assert.Equal(t, RunResult{Stdout: "hello $HOME; ls\n"}, result, "arguments are not interpreted by a shell")
+
+	// Commands run in the first root unless given a directory within a root
+	result, errData = runCommand(t, s, RunArgs{Command: "pwd"})
+	require.Empty(t, errData)
+	assert.Equal(t, root+"\n", result.Stdout)
+	result, errData = runCommand(t, s, RunArgs{Command: "pwd", Dir: dir("sub")})
+	require.Empty(t, errData)
+	assert.Equal(t, filepath.Join(root, "sub")+"\n", result.Stdout)
+
+	result, errData = runCommand(t, s, RunArgs{Command: "false"})
+	require.Empty(t, errData)
+	assert.Equal(t, 1, result.ExitCode)
+
+	result, errData = runCommand(t, s, RunArgs{Command: "printenv", Args: argv("SHELLTOOLS_TEST_SECRET")})
+	require.Empty(t, errData)
+	assert.Equal(t, 1, result.ExitCode, "the environment is scrubbed")
+	assert.Empty(t, result.Stdout)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "large"), make([]byte, 100000), 0o644))
+	result, errData = runCommand(t, s, RunArgs{Command: "head", Args: argv("-c", "100000", "large")})
+	require.Empty(t, errData)
+	assert.Len(t, result.Stdout, 256)
+	assert.True(t, result.Truncated)
+}
+
+func TestRunCommandPolicy(t *testing.T) {
+	s, root := setupServer(t, WithAllowedCommands("echo", "bash", "sleep", "cat"), WithTimeout(100*time.Millisecond))
+	require.NoError(t, os.Symlink(os.TempDir(), filepath.Join(root, "escape")))
+
+	tests := []struct {
+		args RunArgs
+		want string
+	}{
+		{RunArgs{Command: "ls"}, "command ls is not allowed"},
+		{RunArgs{Command: "bash", Args: argv("-c", "ls")}, "command bash is not allowed"},
+		{RunArgs{Command: "/bin/echo"}, "must be a program name, not a path"},
+		{RunArgs{Command: "echo", Dir: dir("..")}, "outside the registered roots"},
+		{RunArgs{Command: "echo", Dir: dir("/")}, "outside the registered roots"},
+		{RunArgs{Command: "echo", Dir: dir("escape")}, "outside the registered roots"},
+		{RunArgs{Command: "echo", Dir: dir("missing")}, "invalid working directory"},
+		{RunArgs{Command: "cat", Args: argv("/etc")}, "argument /etc is outside the registered roots"},
+		{RunArgs{Command: "cat", Args: argv("../..")}, "argument ../.. is outside the registered roots"},
+		{RunArgs{Command: "cat", Args: argv("sub/../../x")}, "outside the registered roots"},
+		{RunArgs{Command: "cat", Args: argv("escape")}, "argument escape is outside the registered roots"},
+		{RunArgs{Command: "cat", Args: argv("escape/..")}, "argument escape/.. is outside the registered roots"},
+		{RunArgs{Command: "cat", Args: argv("-n", "--file=/etc/passwd")}, "argument --file=/etc/passwd is outside"},
+		{RunArgs{Command: "cat", Args: argv("-f/etc/passwd")}, "argument -f/etc/passwd is outside"},
+		{RunArgs{Command: "cat", Args: argv("-o/etc/x")}, "argument -o/etc/x is outside"},
+		{RunArgs{Command: "cat", Args: argv("-xf/etc/passwd")}, "argument -xf/etc/passwd is outside"},
+		{RunArgs{Command: "cat", Args: argv("-C../..")}, "argument -C../.. is outside"},
+		{RunArgs{Command: "cat", Args: argv("-oescape/x")}, "argument -oescape/x is outside"},
+		{RunArgs{Command: "sleep", Args: argv("5")}, "timed out after 100ms"},
+	}
+	for _, tt := range tests {
+		_, errData := runCommand(t, s, tt.args)
+		assert.Contains(t, errData, tt.want, "%+v", tt.args)
+	}
+
+	// Paths within the roots may not exist yet, and options are not paths
+	result, errData := runCommand(t, s, RunArgs{Command: "cat", Args: argv("-n", "missing/new", "--", "sub/../large")})
+	assert.Empty(t, errData)
+	assert.Equal(t, 1, result.ExitCode, "cat runs and reports the missing files")
+	result, errData = runCommand(t, s, RunArgs{Command: "echo", Args: argv("-n", "-la", "-o./out", "-Isub")})
+	assert.Empty(t, errData)
+	assert.Equal(t, "-la -o./out -Isub", result.Stdout, "short options with values within the roots run")
+
+	// Everything not denied can be allowed at once
+	s, _ = setupServer(t, WithAllowedCommands(AllowAnyCommand), WithDeniedCommands("echo"))
+	_, errData = runCommand(t, s, RunArgs{Command: "echo"})
+	assert.Contains(t, errData, "command echo is not allowed")
+	_, errData = runCommand(t, s, RunArgs{Command: "pwd"})
+	assert.Empty(t, errData)
+
+	// Shells and interpreters, in any version, are denied by default
+	s, _ = setupServer(t, WithAllowedCommands(AllowAnyCommand))
+	for _, command := range []string{"sh", "bash", "env", "python", "python3", "python3.12"} {
+		_, errData = runCommand(t, s, RunArgs{Command: command})
+		assert.Contains(t, errData, "command "+command+" is not allowed")
+	}
+}
+
+func TestRegisterRequiresAllowedCommands(t *testing.T) {
+	s := server.NewServer("shell-test")
+	s.GetServer().UseProvider(New())
+	assert.Empty(t, s.GetServer().GetTools())
+}
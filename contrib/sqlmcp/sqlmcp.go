@@ -0,0 +1,383 @@
+// Package sqlmcp exposes a SQL database through an MCP server.
+//
+// Register adds schema-introspection resources and a read-only query tool for a
+// *sql.DB, so database-backed servers don't need hand-rolled plumbing:
+//
+//	db, err := sql.Open("postgres", dsn)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	s := server.NewServer("db-server")
+//	sqlmcp.Register(s, db, sqlmcp.WithDialect(sqlmcp.Postgres))
+//	s.AsStdio().Run()
+//
+// The package only depends on database/sql; import the driver for your database
+// alongside it.
+package sqlmcp
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/localrivet/gomcp/server"
+)
+
+// Default settings of a registered database.
+const (
+	DefaultURIPrefix    = "/db"
+	DefaultToolName     = "query"
+	DefaultMaxRows      = 1000
+	DefaultQueryTimeout = 30 * time.Second
+)
+
+// DefaultAllowedStatements are the statement keywords the query tool accepts unless
+// WithAllowedStatements is used.
+var DefaultAllowedStatements = []string{"SELECT"}
+
+// Dialect holds the queries used to introspect a database's schema.
+type Dialect struct {
+	// Name identifies the dialect in resource descriptions
+	Name string
+
+	// TablesQuery returns the name of every table, one per row
+	TablesQuery string
+
+	// ColumnsQuery takes a table name as its only parameter and returns the name,
+	// data type and nullability ("YES" or "NO") of each of its columns
+	ColumnsQuery string
+}
+
+// Dialects of common databases.
+var (
+	Postgres = Dialect{
+		Name: "postgres",
+		TablesQuery: `SELECT table_name FROM information_schema.tables
+			WHERE table_schema = current_schema() AND table_type = 'BASE TABLE' ORDER BY table_name`,
+		ColumnsQuery: `SELECT column_name, data_type, is_nullable FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = $1 ORDER BY ordinal_position`,
+	}
+
+	MySQL = Dialect{
+		Name: "mysql",
+		TablesQuery: `SELECT table_name FROM information_schema.tables
+			WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE' ORDER BY table_name`,
+		ColumnsQuery: `SELECT column_name, data_type, is_nullable FROM information_schema.columns
+			WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position`,
+	}
+
+	SQLite = Dialect{
+		Name: "sqlite",
+		TablesQuery: `SELECT name FROM sqlite_master
+			WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`,
+		ColumnsQuery: `SELECT name, type, CASE WHEN "notnull" = 0 THEN 'YES' ELSE 'NO' END
+			FROM pragma_table_info(?) ORDER BY cid`,
+	}
+)
+
+// Option configures a database registered with Register.
+type Option func(*provider)
+
+// WithDialect sets the dialect used for schema introspection. The default is Postgres.
+func WithDialect(dialect Dialect) Option {
+	return func(p *provider) {
+		p.dialect = dialect
+	}
+}
+
+// WithAllowedStatements sets the statement keywords, such as "SELECT" or "WITH", that
+// the query tool accepts. Statements starting with any other keyword are rejected.
+func WithAllowedStatements(keywords ...string) Option {
+	return func(p *provider) {
+		p.allowed = make(map[string]bool, len(keywords))
+		for _, keyword := range keywords {
+			p.allowed[strings.ToUpper(strings.TrimSpace(keyword))] = true
+		}
+	}
+}
+
+// WithMaxRows limits how many rows the query tool returns. Results with more rows
+// are truncated.
+func WithMaxRows(maxRows int) Option {
+	return func(p *provider) {
+		p.maxRows = maxRows
+	}
+}
+
+// WithQueryTimeout limits how long a query may run.
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(p *provider) {
+		p.timeout = timeout
+	}
+}
+
+// WithURIPrefix sets the prefix of the schema resources. The default is "/db".
+func WithURIPrefix(prefix string) Option {
+	return func(p *provider) {
+		p.prefix = strings.TrimSuffix(prefix, "/")
+	}
+}
+
+// WithToolName sets the name of the query tool. The default is "query".
+func WithToolName(name string) Option {
+	return func(p *provider) {
+		p.toolName = name
+	}
+}
+
+// WithReadOnlyTransactions sets whether queries run in read-only transactions, which
+// is the default. Disable it for drivers that do not support read-only transactions.
+func WithReadOnlyTransactions(enabled bool) Option {
+	return func(p *provider) {
+		p.readOnly = enabled
+	}
+}
+
+// Column describes a column of a table.
+type Column struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// Table describes a table and its columns.
+type Table struct {
+	Name    string   `json:"name"`
+	URI     string   `json:"uri"`
+	Columns []Column `json:"columns,omitempty"`
+}
+
+// QueryArgs are the arguments of the query tool.
+type QueryArgs struct {
+	SQL    string         `json:"sql" description:"A read-only SQL statement, with placeholders for parameters"`
+	Params *[]interface{} `json:"params,omitempty" description:"Values for the statement's placeholders, in order"`
+}
+
+// QueryResult is the result of the query tool.
+type QueryResult struct {
+	Columns   []string        `json:"columns"`
+	Rows      [][]interface{} `json:"rows"`
+	Truncated bool            `json:"truncated,omitempty"`
+}
+
+// provider serves a database through a server
+type provider struct {
+	db       *sql.DB
+	dialect  Dialect
+	allowed  map[string]bool
+	maxRows  int
+	timeout  time.Duration
+	prefix   string
+	toolName string
+	readOnly bool
+}
+
+// Register exposes a database through the server:
+//
+//   - <prefix>/tables lists the tables of the database
+//   - <prefix>/tables/{name} describes the columns of a table
+//   - the query tool runs a parameterized, read-only SQL statement
+//
+// The query tool only accepts a single statement starting with an allowed keyword
+// (see WithAllowedStatements), and runs it in a read-only transaction, so clients
+// cannot modify the database.
+func Register(s server.Server, db *sql.DB, options ...Option) server.Server {
+	p := &provider{
+		db:       db,
+		dialect:  Postgres,
+		maxRows:  DefaultMaxRows,
+		timeout:  DefaultQueryTimeout,
+		prefix:   DefaultURIPrefix,
+		toolName: DefaultToolName,
+		readOnly: true,
+	}
+	WithAllowedStatements(DefaultAllowedStatements...)(p)
+	for _, option := range options {
+		option(p)
+	}
+
+	s.Resource(p.prefix+"/tables", "Tables of the "+p.dialect.Name+" database", p.listTables)
+	s.Resource(p.prefix+"/tables/{name}", "Columns of a table of the "+p.dialect.Name+" database", p.describeTable)
+	s.Tool(p.toolName, "Run a read-only SQL query against the "+p.dialect.Name+" database", p.query,
+		map[string]interface{}{"readOnlyHint": true})
+
+	return s
+}
+
+// listTables serves the table listing resource
+func (p *provider) listTables(ctx *server.Context, args interface{}) (interface{}, error) {
+	queryCtx, cancel := p.context(ctx.Context())
+	defer cancel()
+
+	rows, err := p.db.QueryContext(queryCtx, p.dialect.TablesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := []Table{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+		tables = append(tables, Table{Name: name, URI: p.tableURI(name)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	return jsonContents(p.prefix+"/tables", tables)
+}
+
+// describeTable serves the resource describing a table
+func (p *provider) describeTable(ctx *server.Context, args interface{}) (interface{}, error) {
+	params, _ := args.(map[string]interface{})
+	name, _ := params["name"].(string)
+	if name == "" {
+		return nil, errors.New("table name is required")
+	}
+
+	queryCtx, cancel := p.context(ctx.Context())
+	defer cancel()
+
+	rows, err := p.db.QueryContext(queryCtx, p.dialect.ColumnsQuery, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe table %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	table := Table{Name: name, URI: p.tableURI(name)}
+	for rows.Next() {
+		var column Column
+		var nullable string
+		if err := rows.Scan(&column.Name, &column.Type, &nullable); err != nil {
+			return nil, fmt.Errorf("failed to describe table %s: %w", name, err)
+		}
+		column.Nullable = strings.EqualFold(nullable, "YES")
+		table.Columns = append(table.Columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to describe table %s: %w", name, err)
+	}
+	if len(table.Columns) == 0 {
+		return nil, fmt.Errorf("table not found: %s", name)
+	}
+
+	return jsonContents(table.URI, table)
+}
+
+// query runs the query tool
+func (p *provider) query(ctx *server.Context, args QueryArgs) (interface{}, error) {
+	if err := p.checkStatement(args.SQL); err != nil {
+		return nil, err
+	}
+
+	queryCtx, cancel := p.context(ctx.Context())
+	defer cancel()
+
+	tx, err := p.db.BeginTx(queryCtx, &sql.TxOptions{ReadOnly: p.readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// The transaction only reads, so it is always rolled back
+	defer tx.Rollback()
+
+	var params []interface{}
+	if args.Params != nil {
+		params = *args.Params
+	}
+	rows, err := tx.QueryContext(queryCtx, args.SQL, params...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	result := QueryResult{Columns: columns, Rows: [][]interface{}{}}
+	for rows.Next() {
+		if p.maxRows > 0 && len(result.Rows) >= p.maxRows {
+			result.Truncated = true
+			break
+		}
+
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, fmt.Errorf("query failed: %w", err)
+		}
+		for i, value := range values {
+			// Drivers return text columns as bytes, which would be encoded as base64
+			if b, ok := value.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	return result, nil
+}
+
+// checkStatement rejects anything but a single statement starting with an allowed keyword
+func (p *provider) checkStatement(statement string) error {
+	keyword, single := parseStatement(statement)
+	if keyword == "" {
+		return errors.New("sql is required")
+	}
+	if !single {
+		return errors.New("only a single statement is allowed")
+	}
+	if !p.allowed[keyword] {
+		return fmt.Errorf("%s statements are not allowed", keyword)
+	}
+	return nil
+}
+
+// context returns the context a query runs in, which ends with the request
+func (p *provider) context(parent context.Context) (context.Context, context.CancelFunc) {
+	if parent == nil {
+		parent = context.Background()
+	}
+	if p.timeout > 0 {
+		return context.WithTimeout(parent, p.timeout)
+	}
+	return context.WithCancel(parent)
+}
+
+// tableURI returns the resource URI of a table
+func (p *provider) tableURI(name string) string {
+	return p.prefix + "/tables/" + name
+}
+
+// jsonContents returns a value as the JSON contents of a resource
+func jsonContents(uri string, value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", uri, err)
+	}
+
+	return map[string]interface{}{
+		"contents": []interface{}{
+			map[string]interface{}{
+				"uri":      uri,
+				"mimeType": "application/json",
+				"text":     string(data),
+			},
+		},
+	}, nil
+}
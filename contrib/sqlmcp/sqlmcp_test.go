@@ -0,0 +1,300 @@
+package sqlmcp
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResult is a canned result of the fake database
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+// fakeDB is a database/sql driver answering queries with canned results
+type fakeDB struct {
+	mu       sync.Mutex
+	results  map[string]fakeResult
+	queries  []string
+	readOnly []bool
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+// key identifies a canned result by query and arguments
+func key(query string, args ...interface{}) string {
+	return fmt.Sprint(query, args)
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c *fakeConn) Commit() error                       { return nil }
+func (c *fakeConn) Rollback() error                     { return nil }
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.readOnly = append(c.db.readOnly, opts.ReadOnly)
+	return c, nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.queries = append(c.db.queries, query)
+	result, ok := c.db.results[key(query, values...)]
+	if !ok {
+		return &fakeRows{}, nil
+	}
+	return &fakeRows{result: result}, nil
+}
+
+type fakeRows struct {
+	result fakeResult
+	next   int
+}
+
+func (r *fakeRows) Columns() []string { return r.result.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
+
+func setupDatabase(t *testing.T, options ...Option) (server.Server, *fakeDB) {
+	t.Helper()
+
+	fake := &fakeDB{results: map[string]fakeResult{
+		key(SQLite.TablesQuery): {
+			columns: []string{"name"},
+			rows:    [][]driver.Value{{"orders"}, {"users"}},
+		},
+		key(SQLite.ColumnsQuery, "users"): {
+			columns: []string{"name", "type", "nullable"},
+			rows:    [][]driver.Value{{"id", "INTEGER", "NO"}, {"email", "TEXT", "YES"}},
+		},
+		key("SELECT id, email FROM users WHERE id > ?", int64(0)): {
+			columns: []string{"id", "email"},
+			rows:    [][]driver.Value{{int64(1), []byte("a@example.com")}, {int64(2), []byte("b@example.com")}},
+		},
+	}}
+	db := sql.OpenDB(fake)
+	t.Cleanup(func() { db.Close() })
+
+	s := server.NewServer("sql-test")
+	Register(s, db, append([]Option{WithDialect(SQLite)}, options...)...)
+	return s, fake
+}
+
+// call sends a request to the server and returns its result, or its error data
+func call(t *testing.T, s server.Server, method string, params interface{}) (json.RawMessage, string) {
+	t.Helper()
+
+	request, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	require.NoError(t, err)
+	response, err := server.HandleMessage(s.GetServer(), request)
+	require.NoError(t, err)
+
+	var decoded struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(response, &decoded))
+	if decoded.Error != nil {
+		// Invalid arguments report their violations alongside the message
+		var data struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(decoded.Error.Data, &data) == nil {
+			return nil, data.Message
+		}
+		var message string
+		require.NoError(t, json.Unmarshal(decoded.Error.Data, &message))
+		return nil, message
+	}
+	return decoded.Result, ""
+}
+
+// readResource returns the JSON text of a resource
+func readResource(t *testing.T, s server.Server, uri string) (string, string) {
+	t.Helper()
+
+	result, errData := call(t, s, "resources/read", map[string]interface{}{"uri": uri})
+	if errData != "" {
+		return "", errData
+	}
+	var decoded struct {
+		Contents []struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+		} `json:"contents"`
+	}
+	require.NoError(t, json.Unmarshal(result, &decoded))
+	require.Len(t, decoded.Contents, 1)
+	assert.Equal(t, "application/json", decoded.Contents[0].MimeType)
+	return decoded.Contents[0].Text, ""
+}
+
+// callQuery calls the query tool and returns its decoded result, or its error
+func callQuery(t *testing.T, s server.Server, args QueryArgs) (QueryResult, string) {
+	t.Helper()
+
+	result, errData := call(t, s, "tools/call", map[string]interface{}{"name": "query", "arguments": args})
+	if errData != "" {
+		return QueryResult{}, errData
+	}
+	var decoded struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	require.NoError(t, json.Unmarshal(result, &decoded))
+	require.NotEmpty(t, decoded.Content)
+	if decoded.IsError {
+		return QueryResult{}, decoded.Content[0].Text
+	}
+
+	var queryResult QueryResult
+	require.NoError(t, json.Unmarshal([]byte(decoded.Content[0].Text), &queryResult))
+	return queryResult, ""
+}
+
+func TestSchemaResources(t *testing.T) {
+	s, _ := setupDatabase(t)
+
+	text, errData := readResource(t, s, "/db/tables")
+	require.Empty(t, errData)
+	var tables []Table
+	require.NoError(t, json.Unmarshal([]byte(text), &tables))
+	assert.Equal(t, []Table{
+		{Name: "orders", URI: "/db/tables/orders"},
+		{Name: "users", URI: "/db/tables/users"},
+	}, tables)
+
+	text, errData = readResource(t, s, "/db/tables/users")
+	require.Empty(t, errData)
+	var table Table
+	require.NoError(t, json.Unmarshal([]byte(text), &table))
+	assert.Equal(t, Table{
+		Name: "users",
+		URI:  "/db/tables/users",
+		Columns: []Column{
+			{Name: "id", Type: "INTEGER", Nullable: false},
+			{Name: "email", Type: "TEXT", Nullable: true},
+		},
+	}, table)
+
+	_, errData = readResource(t, s, "/db/tables/missing")
+	assert.Contains(t, errData, "table not found")
+}
+
+func TestQueryTool(t *testing.T) {
+	s, fake := setupDatabase(t)
+
+	result, errData := callQuery(t, s, QueryArgs{SQL: "SELECT id, email FROM users WHERE id > ?", Params: &[]interface{}{0}})
+	require.Empty(t, errData)
+	assert.Equal(t, []string{"id", "email"}, result.Columns)
+	assert.Equal(t, [][]interface{}{{float64(1), "a@example.com"}, {float64(2), "b@example.com"}}, result.Rows)
+	assert.False(t, result.Truncated)
+	assert.Equal(t, []bool{true}, fake.readOnly, "queries should run in read-only transactions")
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"", "'sql' is required"},
+		{"DELETE FROM users", "DELETE statements are not allowed"},
+		{"  -- comment\n update users set email = ''", "UPDATE statements are not allowed"},
+		{"SELECT 1; DROP TABLE users", "only a single statement is allowed"},
+		{"WITH x AS (SELECT 1) SELECT * FROM x", "WITH statements are not allowed"},
+	}
+	for _, tt := range tests {
+		_, errData := callQuery(t, s, QueryArgs{SQL: tt.sql})
+		assert.Contains(t, errData, tt.want, "statement %q", tt.sql)
+	}
+
+	// Rejected statements never reach the database
+	assert.Len(t, fake.queries, 1)
+}
+
+func TestQueryToolOptions(t *testing.T) {
+	s, fake := setupDatabase(t,
+		WithMaxRows(1),
+		WithAllowedStatements("select", "with"),
+		WithReadOnlyTransactions(false),
+	)
+
+	result, errData := callQuery(t, s, QueryArgs{SQL: "SELECT id, email FROM users WHERE id > ?", Params: &[]interface{}{0}})
+	require.Empty(t, errData)
+	assert.Len(t, result.Rows, 1)
+	assert.True(t, result.Truncated)
+	assert.Equal(t, []bool{false}, fake.readOnly)
+
+	_, errData = callQuery(t, s, QueryArgs{SQL: "WITH x AS (SELECT ';') SELECT * FROM x"})
+	assert.Empty(t, errData)
+}
+
+func TestQueryContext(t *testing.T) {
+	// Queries end with the request that runs them
+	request, cancelRequest := context.WithCancel(context.Background())
+	p := &provider{timeout: time.Minute}
+	queryCtx, cancel := p.context(request)
+	defer cancel()
+	cancelRequest()
+	assert.ErrorIs(t, queryCtx.Err(), context.Canceled)
+
+	// and with the provider's timeout
+	p = &provider{timeout: time.Millisecond}
+	queryCtx, cancel = p.context(context.Background())
+	defer cancel()
+	<-queryCtx.Done()
+	assert.ErrorIs(t, queryCtx.Err(), context.DeadlineExceeded)
+}
+
+func TestParseStatement(t *testing.T) {
+	tests := []struct {
+		statement string
+		keyword   string
+		single    bool
+	}{
+		{"select * from t", "SELECT", true},
+		{"/* hint */ SELECT 1;  -- done", "SELECT", true},
+		{"SELECT 'a;b', \"c;d\"", "SELECT", true},
+		{"SELECT 1; SELECT 2", "SELECT", false},
+		{"SELECT 1 -- ; DROP\n", "SELECT", true},
+		{"   ", "", true},
+	}
+	for _, tt := range tests {
+		keyword, single := parseStatement(tt.statement)
+		assert.Equal(t, tt.keyword, keyword, tt.statement)
+		assert.Equal(t, tt.single, single, tt.statement)
+	}
+}
@@ -0,0 +1,72 @@
+package sqlmcp
+
+import (
+	"strings"
+	"unicode"
+)
+
+// parseStatement returns the upper-cased leading keyword of a SQL statement, and
+// whether the text holds a single statement. Comments, quoted strings and quoted
+// identifiers are skipped, so that a semicolon inside them does not count.
+func parseStatement(statement string) (keyword string, single bool) {
+	rest := skipSpaceAndComments(statement)
+	end := strings.IndexFunc(rest, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if end < 0 {
+		end = len(rest)
+	}
+	keyword = strings.ToUpper(rest[:end])
+
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case '\'', '"', '`':
+			quote := rest[i]
+			for i++; i < len(rest) && rest[i] != quote; i++ {
+			}
+		case '-':
+			if strings.HasPrefix(rest[i:], "--") {
+				if newline := strings.IndexByte(rest[i:], '\n'); newline >= 0 {
+					i += newline
+				} else {
+					i = len(rest)
+				}
+			}
+		case '/':
+			if strings.HasPrefix(rest[i:], "/*") {
+				if close := strings.Index(rest[i+2:], "*/"); close >= 0 {
+					i += close + 3
+				} else {
+					i = len(rest)
+				}
+			}
+		case ';':
+			// A trailing semicolon is fine, anything after it is another statement
+			return keyword, skipSpaceAndComments(rest[i+1:]) == ""
+		}
+	}
+	return keyword, true
+}
+
+// skipSpaceAndComments returns s without its leading whitespace and comments
+func skipSpaceAndComments(s string) string {
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		switch {
+		case strings.HasPrefix(s, "--"):
+			newline := strings.IndexByte(s, '\n')
+			if newline < 0 {
+				return ""
+			}
+			s = s[newline+1:]
+		case strings.HasPrefix(s, "/*"):
+			close := strings.Index(s, "*/")
+			if close < 0 {
+				return ""
+			}
+			s = s[close+2:]
+		default:
+			return s
+		}
+	}
+}
@@ -209,6 +209,14 @@ func Complete(s *Subject) {
 	}
 }
 
+// Pending returns the number of published events waiting to be delivered.
+func Pending(s *Subject) int {
+	if s == nil {
+		return 0
+	}
+	return len(s.events)
+}
+
 type event struct {
 	topic   string
 	message any
@@ -273,13 +281,14 @@ func NewSubject(opts ...SubjectOption) *Subject {
 		s.cache.Store(&emptyCache)
 	}
 
+	// Register the loop before starting it so that Complete cannot miss it
+	s.wg.Add(1)
 	go s.eventLoop()
 	return s
 }
 
 // eventLoop processes events and distributes them to subscribers
 func (s *Subject) eventLoop() {
-	s.wg.Add(1)
 	defer s.wg.Done()
 
 	for {
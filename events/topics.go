@@ -14,11 +14,14 @@ const (
 	TopicClientDisconnected = "client.disconnected" // Client disconnected from server
 
 	// Registration events (server-side)
-	TopicToolRegistered     = "tool.registered"
-	TopicResourceRegistered = "resource.registered"
+	TopicToolRegistered       = "tool.registered"
+	TopicResourceRegistered   = "resource.registered"
+	TopicToolUnregistered     = "tool.unregistered"
+	TopicResourceUnregistered = "resource.unregistered"
 
 	// Operation events (can be emitted by both client and server for same operations)
 	TopicToolExecuted     = "tool.executed"     // Tool was executed
+	TopicToolFailed       = "tool.failed"       // Tool failed, or failed repeatedly with the same error (server-side)
 	TopicResourceAccessed = "resource.accessed" // Resource was accessed
 	TopicResourceChanged  = "resource.changed"  // Resource was modified (create/update/delete)
 	TopicPromptExecuted   = "prompt.executed"   // Prompt was executed
@@ -26,10 +29,36 @@ const (
 	// Error events
 	TopicRequestFailed = "request.failed" // Request failed
 
+	// Protocol events (can be emitted by both client and server)
+	TopicUnknownMethod = "method.unknown" // Notification with a method nobody handles was received
+
+	// Protocol version events (server-side)
+	TopicVersionDowngraded = "version.downgraded" // Client negotiated an older protocol version than the server prefers
+	TopicLegacyClient      = "client.legacy"      // Client used the deprecated HTTP+SSE endpoints of 2024-11-05
+
+	// Load events (server-side)
+	TopicRequestQueue = "request.queue" // Requests started waiting for a worker, the queue emptied, or a request was rejected
+
+	// Capability events (server-side)
+	TopicCapabilityReconciled = "capability.reconciled" // list_changed was re-sent because a client's view had drifted
+
+	// Maintenance events (server-side)
+	TopicCleanupCompleted = "cleanup.completed" // Background cleanup reclaimed expired state
+
+	// Security events (server-side)
+	TopicAccessViolation = "access.violation" // Transport rejected a client under its access policy
+	TopicToolBlocked     = "tool.blocked"     // Risk policy refused a tool call
+
 	// Client-specific lifecycle events
 	TopicClientInitializing = "client.initializing" // Client starting up
-	TopicClientInitialized  = "client.initialized"  // Client ready
+	TopicClientInitialized  = "client.initialized"  // Client connected and initialized
+	TopicClientReady        = "client.ready"        // Client meets its readiness conditions
 	TopicClientError        = "client.error"        // Client operation failed
+
+	// Process events (client-side)
+	TopicServerStderr   = "server.stderr"   // A server process launched by a ServerRegistry wrote a line to stderr
+	TopicServerHung     = "server.hung"     // A ServerRegistry's watchdog killed a server that stopped answering pings
+	TopicServerOrphaned = "server.orphaned" // Descendants of a killed server process outlived its process group
 )
 
 // Shared struct types for event data
@@ -52,6 +81,15 @@ type ClientInitializedEvent struct {
 	URL string `json:"url"` // The server URL that was connected to
 }
 
+// ClientReadyEvent is emitted once a client is initialized and meets the
+// readiness conditions it was configured with, such as tools being available
+type ClientReadyEvent struct {
+	URL             string    `json:"url"`
+	ServerName      string    `json:"serverName,omitempty"`
+	ProtocolVersion string    `json:"protocolVersion"`
+	ReadyAt         time.Time `json:"readyAt"`
+}
+
 // ClientErrorEvent is emitted when a client operation fails
 type ClientErrorEvent struct {
 	Error string `json:"error"` // The error message describing what failed
@@ -103,6 +141,134 @@ type ClientConnectedEvent struct {
 	Capabilities    map[string]interface{} `json:"capabilities"`
 }
 
+// UnknownMethodEvent is emitted when a notification arrives whose method neither the
+// library nor a registered custom handler handles
+type UnknownMethodEvent struct {
+	Method     string    `json:"method"`
+	SessionID  string    `json:"sessionId,omitempty"` // Server-side: session that sent the notification
+	Count      int64     `json:"count"`               // Times this method has been received so far
+	ReceivedAt time.Time `json:"receivedAt"`
+}
+
+// ServerStderrEvent is emitted for each line a server process launched by a
+// client's ServerRegistry writes to stderr
+type ServerStderrEvent struct {
+	Server    string    `json:"server"` // Name of the server in the registry
+	Line      string    `json:"line"`
+	WrittenAt time.Time `json:"writtenAt"`
+}
+
+// ServerHungEvent is emitted when the watchdog of a client's ServerRegistry kills
+// the process group of a server that did not answer a ping in time
+type ServerHungEvent struct {
+	Server     string    `json:"server"` // Name of the server in the registry
+	PID        int       `json:"pid"`
+	Error      string    `json:"error"`  // Why the ping failed
+	Signal     string    `json:"signal"` // Last signal sent to the process group: SIGTERM or SIGKILL
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// ServerOrphanedEvent is emitted when descendants of a server process killed by a
+// ServerRegistry's watchdog are still running, having left its process group
+type ServerOrphanedEvent struct {
+	Server     string    `json:"server"` // Name of the server in the registry
+	PID        int       `json:"pid"`    // PID of the killed server process
+	Orphans    []int     `json:"orphans"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// VersionDowngradedEvent is emitted when a client negotiates an older protocol version
+// than the server's preferred one. Counting these events per version shows how many
+// legacy clients remain before support for a version is dropped.
+type VersionDowngradedEvent struct {
+	SessionID         string     `json:"sessionId"`
+	ClientInfo        ClientInfo `json:"clientInfo"`
+	RequestedVersion  string     `json:"requestedVersion"`  // Version the client asked for
+	NegotiatedVersion string     `json:"negotiatedVersion"` // Version the session uses
+	PreferredVersion  string     `json:"preferredVersion"`  // Version the server would have chosen
+	DisabledFeatures  []string   `json:"disabledFeatures"`  // Features unavailable to this session as a result
+	NegotiatedAt      time.Time  `json:"negotiatedAt"`
+}
+
+// AccessViolationEvent is emitted when a network transport rejects a request or
+// connection under its access policy: a denied address (403), a client over its
+// rate limit (429), or a client over its connection limit.
+type AccessViolationEvent struct {
+	Kind       string    `json:"kind"` // "denied", "rate_limited" or "connection_limit"
+	RemoteIP   string    `json:"remoteIP"`
+	Method     string    `json:"method,omitempty"`
+	Path       string    `json:"path,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// LegacyClientEvent is emitted when a client uses the deprecated HTTP+SSE
+// endpoints of the 2024-11-05 protocol, such as /sse. Rejected is set when the
+// endpoints are disabled and the client was answered with a migration hint.
+type LegacyClientEvent struct {
+	RemoteIP  string    `json:"remoteIP"`
+	UserAgent string    `json:"userAgent,omitempty"`
+	Path      string    `json:"path"`
+	Rejected  bool      `json:"rejected"`
+	SeenAt    time.Time `json:"seenAt"`
+}
+
+// ToolFailedEvent is emitted when a tool fails. Identical failures of a tool within
+// the server's error report window are aggregated: the first is reported at once
+// with a Count of 1, and the repeats together once the window closes.
+type ToolFailedEvent struct {
+	Tool    string    `json:"tool"`
+	Error   string    `json:"error"`
+	Count   int       `json:"count"`   // Failures the event covers
+	FirstAt time.Time `json:"firstAt"` // When the first of them happened
+	LastAt  time.Time `json:"lastAt"`  // When the last of them happened
+}
+
+// ToolBlockedEvent is emitted when the server's risk policy refuses a tool call,
+// because the tool's risk level is blocked or the call was not approved
+type ToolBlockedEvent struct {
+	Tool      string    `json:"tool"`
+	Risk      string    `json:"risk"`   // Risk level of the tool
+	Action    string    `json:"action"` // "block" or "approve"
+	Reason    string    `json:"reason"`
+	SessionID string    `json:"sessionId,omitempty"`
+	BlockedAt time.Time `json:"blockedAt"`
+}
+
+// RequestQueueEvent reports the state of the server's request queue when the
+// number of concurrent requests is limited. It is emitted when requests start
+// waiting for a worker, when the queue empties again (Depth 0), and when a
+// request is rejected because the queue is full (Overflowed).
+type RequestQueueEvent struct {
+	Depth      int       `json:"depth"`      // Requests waiting for a worker
+	Capacity   int       `json:"capacity"`   // Length of the queue
+	Active     int       `json:"active"`     // Requests being handled
+	Workers    int       `json:"workers"`    // Maximum concurrent requests
+	Rejected   int64     `json:"rejected"`   // Requests rejected so far
+	Overflowed bool      `json:"overflowed"` // Whether this event reports a rejection
+	Policy     string    `json:"policy"`     // Overflow policy: "reject" or "wait"
+	ObservedAt time.Time `json:"observedAt"`
+}
+
+// CapabilityReconciledEvent reports that a list_changed notification was re-sent
+// because clients' views of a capability had drifted from the server's registry,
+// for example after a notification was dropped.
+type CapabilityReconciledEvent struct {
+	Capability  string    `json:"capability"`  // "tools", "resources" or "prompts"
+	Sessions    int       `json:"sessions"`    // Sessions whose views were out of date
+	Corrections int64     `json:"corrections"` // Corrections for the capability so far
+	ObservedAt  time.Time `json:"observedAt"`
+}
+
+// CleanupCompletedEvent reports what a run of the server's background cleanup
+// reclaimed. It is only published when the run reclaimed something.
+type CleanupCompletedEvent struct {
+	ProgressTokens int       `json:"progressTokens"` // Expired progress tokens removed
+	RateLimiters   int       `json:"rateLimiters"`   // Progress rate limiters of inactive tokens removed
+	Sessions       int       `json:"sessions"`       // Idle sessions expired
+	Requests       int       `json:"requests"`       // Server-initiated requests that never got a response
+	ObservedAt     time.Time `json:"observedAt"`
+}
+
 // Registration event structs
 
 // ToolRegisteredEvent is emitted when a tool is registered with the server
@@ -124,6 +290,18 @@ type ResourceRegisteredEvent struct {
 	RegisteredAt time.Time `json:"registeredAt"`
 }
 
+// ToolUnregisteredEvent is emitted when a tool is removed from the server
+type ToolUnregisteredEvent struct {
+	ToolName       string    `json:"toolName"`
+	UnregisteredAt time.Time `json:"unregisteredAt"`
+}
+
+// ResourceUnregisteredEvent is emitted when a resource is removed from the server
+type ResourceUnregisteredEvent struct {
+	URI            string    `json:"uri"`
+	UnregisteredAt time.Time `json:"unregisteredAt"`
+}
+
 // Operation event structs
 
 // RequestFailedEvent is emitted when an MCP request fails on either client or server
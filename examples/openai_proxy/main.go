@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/localrivet/gomcp/client"
 )
@@ -29,12 +30,13 @@ func main() {
 
 	// Check if MCP server URL is provided
 	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run main.go <mcp-server-url>")
+		fmt.Println("Usage: go run main.go <mcp-server-url> [tag,tag...]")
 		fmt.Println()
 		fmt.Println("Examples:")
 		fmt.Println("  go run main.go stdio://path/to/mcp-server")
 		fmt.Println("  go run main.go ws://localhost:8080/mcp")
 		fmt.Println("  go run main.go http://localhost:8080/mcp")
+		fmt.Println("  go run main.go http://localhost:8080/mcp files,read-only")
 		fmt.Println()
 		fmt.Println("💡 This example demonstrates how to:")
 		fmt.Println("   1. Connect to any MCP server")
@@ -56,9 +58,16 @@ func main() {
 	}
 	defer mcpClient.Close()
 
-	// Step 2: Discover available tools using ListTools()
-	fmt.Println("📋 Discovering available tools...")
-	tools, err := mcpClient.ListTools()
+	// Step 2: Discover available tools, optionally narrowed to the given tags
+	var tools []client.Tool
+	if len(os.Args) > 2 && os.Args[2] != "" {
+		tags := strings.Split(os.Args[2], ",")
+		fmt.Printf("📋 Discovering tools tagged %v...\n", tags)
+		tools, err = mcpClient.SearchTools(client.ToolQuery{Tags: tags})
+	} else {
+		fmt.Println("📋 Discovering available tools...")
+		tools, err = mcpClient.ListTools()
+	}
 	if err != nil {
 		log.Fatalf("Failed to list tools: %v", err)
 	}
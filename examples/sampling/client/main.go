@@ -28,33 +28,26 @@ func main() {
 	}
 	defer c.Close()
 
-	// Set up a sampling handler to respond to sampling requests from the server
-	c = c.WithSamplingHandler(func(params client.SamplingCreateMessageParams) (client.SamplingResponse, error) {
-		fmt.Printf("\n🤖 Server requested sampling with %d messages\n", len(params.Messages))
-
-		// Log the request details
-		for i, msg := range params.Messages {
-			fmt.Printf("  Message %d (%s): %s\n", i+1, msg.Role, getContentPreview(msg.Content))
-		}
-
-		if params.SystemPrompt != "" {
-			fmt.Printf("  System prompt: %s\n", params.SystemPrompt)
-		}
-
-		// Simulate AI model response
-		response := client.SamplingResponse{
-			Role: "assistant",
-			Content: client.SamplingMessageContent{
-				Type: "text",
-				Text: "This is a simulated AI response. In a real implementation, this would call an actual LLM.",
-			},
-			Model:      "simulated-model-v1",
-			StopReason: "endTurn",
-		}
-
-		fmt.Printf("  ✅ Responding with: %s\n", response.Content.Text)
-		return response, nil
+	// Route sampling requests from the server to a model based on its preferences.
+	// Routes above the cost ceiling are never used, and a failing route falls
+	// back to the next best candidate.
+	router := client.NewSamplingRouter(client.WithSamplingCostCeiling(50))
+	router.AddRoute(client.ModelRoute{
+		Provider: "simulated", Model: "simulated-large-v1", Aliases: []string{"gpt-4", "claude"},
+		CostPerMillionTokens: 30, SpeedScore: 0.4, IntelligenceScore: 0.9,
+		Handler: simulatedModel("simulated-large-v1"),
 	})
+	router.AddRoute(client.ModelRoute{
+		Provider: "simulated", Model: "simulated-small-v1", Aliases: []string{"haiku", "mini"},
+		CostPerMillionTokens: 1, SpeedScore: 0.9, IntelligenceScore: 0.5,
+		Handler: simulatedModel("simulated-small-v1"),
+	})
+	router.AddRoute(client.ModelRoute{
+		Provider: "simulated", Model: "simulated-huge-v1",
+		CostPerMillionTokens: 100, SpeedScore: 0.2, IntelligenceScore: 1,
+		Handler: simulatedModel("simulated-huge-v1"),
+	})
+	c = c.WithSamplingHandler(router.Handler())
 
 	fmt.Println("✅ Client connected with sampling support!")
 
@@ -85,6 +78,36 @@ func main() {
 	fmt.Println("\n🎉 All sampling examples completed successfully!")
 }
 
+// simulatedModel returns a sampling handler standing in for a real LLM provider.
+func simulatedModel(model string) client.SamplingHandler {
+	return func(params client.SamplingCreateMessageParams) (client.SamplingResponse, error) {
+		fmt.Printf("\n🤖 Server requested sampling with %d messages, routed to %s\n", len(params.Messages), model)
+
+		// Log the request details
+		for i, msg := range params.Messages {
+			fmt.Printf("  Message %d (%s): %s\n", i+1, msg.Role, getContentPreview(msg.Content))
+		}
+
+		if params.SystemPrompt != "" {
+			fmt.Printf("  System prompt: %s\n", params.SystemPrompt)
+		}
+
+		// Simulate AI model response
+		response := client.SamplingResponse{
+			Role: "assistant",
+			Content: client.SamplingMessageContent{
+				Type: "text",
+				Text: "This is a simulated AI response. In a real implementation, this would call an actual LLM.",
+			},
+			Model:      model,
+			StopReason: "endTurn",
+		}
+
+		fmt.Printf("  ✅ Responding with: %s\n", response.Content.Text)
+		return response, nil
+	}
+}
+
 func demonstrateBasicTextSampling(c client.Client) {
 	// Create a simple text message
 	messages := []client.SamplingMessage{
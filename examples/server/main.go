@@ -150,7 +150,7 @@ func main() {
 		return server.NewResourceResponse(
 			server.TextContent("Here's some information with mixed content types:"),
 			server.LinkContent("https://example.com", "Example Website"),
-			server.ImageContent("https://example.com/image.jpg", "Example Image"),
+			server.NewImageContent("https://example.com/image.jpg", "Example Image"),
 		), nil
 	})
 
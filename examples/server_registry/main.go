@@ -2,6 +2,7 @@
 package main
 
 import (
+	"embed"
 	"fmt"
 	"log"
 	"log/slog"
@@ -18,13 +19,13 @@ func main() {
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
 		case "math-server":
-			runMathServer()
+			runScenarioServer("math")
 			return
 		case "text-server":
-			runTextServer()
+			runScenarioServer("text")
 			return
 		case "slow-server":
-			runSlowServer()
+			runScenarioServer("slow")
 			return
 		case "demo":
 			runRegistryDemo()
@@ -341,114 +342,31 @@ func testConcurrentOperations(clients map[string]client.Client) {
 	fmt.Println("✓ All concurrent operations completed")
 }
 
-// Math server implementation
-func runMathServer() {
-	srv := server.NewServer("math-server").AsStdio()
-
-	srv.Tool("add", "Add two numbers", func(ctx *server.Context, args struct {
-		A float64 `json:"a"`
-		B float64 `json:"b"`
-	}) (map[string]interface{}, error) {
-		return map[string]interface{}{"result": args.A + args.B}, nil
-	})
-
-	srv.Tool("multiply", "Multiply two numbers", func(ctx *server.Context, args struct {
-		A float64 `json:"a"`
-		B float64 `json:"b"`
-	}) (map[string]interface{}, error) {
-		return map[string]interface{}{"result": args.A * args.B}, nil
-	})
-
-	srv.Tool("factorial", "Calculate factorial", func(ctx *server.Context, args struct {
-		N int `json:"n"`
-	}) (map[string]interface{}, error) {
-		if args.N < 0 {
-			return nil, fmt.Errorf("factorial not defined for negative numbers")
-		}
-
-		result := 1
-		for i := 2; i <= args.N; i++ {
-			result *= i
-		}
+// scenarios script the simulated servers of the demo
+//
+//go:embed scenarios/*.yaml
+var scenarios embed.FS
 
-		return map[string]interface{}{"result": result}, nil
-	})
-
-	if err := srv.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Math server error: %v\n", err)
+// runScenarioServer runs the server simulated by a scenario over stdio
+func runScenarioServer(name string) {
+	data, err := scenarios.ReadFile("scenarios/" + name + ".yaml")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Scenario error: %v\n", err)
 		os.Exit(1)
 	}
-}
-
-// Text server implementation
-func runTextServer() {
-	srv := server.NewServer("text-server").AsStdio()
-
-	srv.Tool("uppercase", "Convert text to uppercase", func(ctx *server.Context, args struct {
-		Text string `json:"text"`
-	}) (map[string]interface{}, error) {
-		return map[string]interface{}{"result": strings.ToUpper(args.Text)}, nil
-	})
-
-	srv.Tool("reverse", "Reverse text", func(ctx *server.Context, args struct {
-		Text string `json:"text"`
-	}) (map[string]interface{}, error) {
-		runes := []rune(args.Text)
-		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
-			runes[i], runes[j] = runes[j], runes[i]
-		}
-		return map[string]interface{}{"result": string(runes)}, nil
-	})
-
-	srv.Tool("count_words", "Count words in text", func(ctx *server.Context, args struct {
-		Text string `json:"text"`
-	}) (map[string]interface{}, error) {
-		words := strings.Fields(args.Text)
-		return map[string]interface{}{
-			"word_count": len(words),
-			"words":      words,
-		}, nil
-	})
-
-	if err := srv.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Text server error: %v\n", err)
+	scenario, err := server.ParseScenario(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Scenario error: %v\n", err)
 		os.Exit(1)
 	}
-}
-
-// Slow server implementation (for testing timeouts)
-func runSlowServer() {
-	srv := server.NewServer("slow-server").AsStdio()
-
-	srv.Tool("slow_process", "Simulate slow processing", func(ctx *server.Context, args struct {
-		Duration string  `json:"duration"`
-		Message  *string `json:"message,omitempty"`
-	}) (map[string]interface{}, error) {
-		duration, err := time.ParseDuration(args.Duration)
-		if err != nil {
-			return nil, fmt.Errorf("invalid duration: %v", err)
-		}
-
-		message := "Processing..."
-		if args.Message != nil {
-			message = *args.Message
-		}
-
-		// Simulate work
-		time.Sleep(duration)
-
-		return map[string]interface{}{
-			"result":   fmt.Sprintf("Completed: %s (took %v)", message, duration),
-			"duration": args.Duration,
-		}, nil
-	})
-
-	srv.Tool("quick_ping", "Quick response test", func(ctx *server.Context, args struct{}) (map[string]interface{}, error) {
-		return map[string]interface{}{"status": "pong", "timestamp": time.Now().Unix()}, nil
-	})
 
+	srv := server.NewServer(scenario.Name).AsStdio()
+	if err := scenario.Register(srv); err != nil {
+		fmt.Fprintf(os.Stderr, "Scenario error: %v\n", err)
+		os.Exit(1)
+	}
 	if err := srv.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Slow server error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "%s error: %v\n", scenario.Name, err)
 		os.Exit(1)
 	}
 }
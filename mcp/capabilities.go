@@ -0,0 +1,71 @@
+package mcp
+
+import "encoding/json"
+
+// ServerCapabilities represents the capabilities a server declares in its
+// initialize response. The server marshals its response from this type and the
+// client parses the response into it, so the two cannot disagree.
+type ServerCapabilities struct {
+	Logging      *LoggingCapability     `json:"logging,omitempty"`
+	Prompts      *PromptsCapability     `json:"prompts,omitempty"`
+	Resources    *ResourcesCapability   `json:"resources,omitempty"`
+	Tools        *ToolsCapability       `json:"tools,omitempty"`
+	Completions  *CompletionsCapability `json:"completions,omitempty"`
+	Experimental map[string]interface{} `json:"experimental,omitempty"`
+}
+
+// LoggingCapability represents the server's logging capability.
+// Currently defined as an empty object in all MCP specification versions.
+type LoggingCapability struct {
+	// No fields defined in specification - empty object
+}
+
+// PromptsCapability represents the server's prompt template capability.
+type PromptsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// ResourcesCapability represents the server's resource capability.
+type ResourcesCapability struct {
+	Subscribe   bool `json:"subscribe,omitempty"`
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// ToolsCapability represents the server's tool capability.
+type ToolsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// CompletionsCapability represents the server's argument completion capability.
+// Defined as an empty object in the MCP specification.
+type CompletionsCapability struct {
+	// No fields defined in specification - empty object
+}
+
+// ClientCapabilities represents the capabilities a client declares in its
+// initialize request.
+type ClientCapabilities struct {
+	Roots        RootsCapability        `json:"roots,omitempty"`
+	Sampling     map[string]interface{} `json:"sampling,omitempty"`
+	Elicitation  *ElicitationCapability `json:"elicitation,omitempty"`
+	Experimental map[string]interface{} `json:"experimental,omitempty"`
+}
+
+// MarshalJSON declares sampling whenever Sampling is set, including as an empty
+// object, which omitempty would drop.
+func (c ClientCapabilities) MarshalJSON() ([]byte, error) {
+	type capabilities ClientCapabilities
+	encoded := struct {
+		capabilities
+		Sampling *map[string]interface{} `json:"sampling,omitempty"`
+	}{capabilities: capabilities(c)}
+	if c.Sampling != nil {
+		encoded.Sampling = &c.Sampling
+	}
+	return json.Marshal(encoded)
+}
+
+// RootsCapability represents the client's roots capability.
+type RootsCapability struct {
+	ListChanged bool `json:"listChanged"`
+}
@@ -0,0 +1,45 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestClientCapabilitiesJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities ClientCapabilities
+		expected     string
+	}{
+		{
+			name:         "no sampling",
+			capabilities: ClientCapabilities{},
+			expected:     `{"roots":{"listChanged":false}}`,
+		},
+		{
+			name:         "empty sampling",
+			capabilities: ClientCapabilities{Sampling: map[string]interface{}{}},
+			expected:     `{"roots":{"listChanged":false},"sampling":{}}`,
+		},
+		{
+			name: "sampling and elicitation",
+			capabilities: ClientCapabilities{
+				Sampling:    map[string]interface{}{"audio": true},
+				Elicitation: &ElicitationCapability{},
+			},
+			expected: `{"roots":{"listChanged":false},"elicitation":{},"sampling":{"audio":true}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.capabilities)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// ContentType represents the type of content in a prompt
+type ContentType string
+
+// ContentTypeText is used for plain text content
+const ContentTypeText ContentType = "text"
+
+// ContentTypeImage is used for base64-encoded image content
+const ContentTypeImage ContentType = "image"
+
+// ContentTypeResource is used for the contents of an embedded resource
+const ContentTypeResource ContentType = "resource"
+
+// ContentItem represents a single content item in tool, prompt and resource
+// responses. It covers the fields of every content type; unused ones are omitted.
+type ContentItem struct {
+	Type     string      `json:"type"`
+	Text     string      `json:"text,omitempty"`
+	ImageURL string      `json:"imageUrl,omitempty"`
+	AltText  string      `json:"altText,omitempty"`
+	URL      string      `json:"url,omitempty"`
+	Title    string      `json:"title,omitempty"`
+	Blob     string      `json:"blob,omitempty"`
+	MimeType string      `json:"mimeType,omitempty"`
+	Data     interface{} `json:"data,omitempty"`
+	Filename string      `json:"filename,omitempty"`
+
+	// Resource holds the contents of resource content
+	Resource *EmbeddedResource `json:"resource,omitempty"`
+}
+
+// PromptMessage represents a rendered message from a prompt template.
+type PromptMessage struct {
+	Role    string        `json:"role"`
+	Content PromptContent `json:"content"`
+}
+
+// PromptContent represents the content of a prompt message: text, an image or an
+// embedded resource.
+type PromptContent struct {
+	Type ContentType `json:"type"`
+	Text string      `json:"text"`
+
+	// Data and MimeType hold the base64-encoded data and type of image content
+	Data     string `json:"data,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+
+	// Resource holds the contents of resource content
+	Resource *EmbeddedResource `json:"resource,omitempty"`
+}
+
+// MarshalJSON writes the fields of the content's type only, so that image and
+// resource content carry no empty text.
+func (c PromptContent) MarshalJSON() ([]byte, error) {
+	switch c.Type {
+	case ContentTypeImage:
+		return json.Marshal(struct {
+			Type     ContentType `json:"type"`
+			Data     string      `json:"data"`
+			MimeType string      `json:"mimeType"`
+		}{c.Type, c.Data, c.MimeType})
+	case ContentTypeResource:
+		return json.Marshal(struct {
+			Type     ContentType       `json:"type"`
+			Resource *EmbeddedResource `json:"resource"`
+		}{c.Type, c.Resource})
+	}
+	type content PromptContent
+	return json.Marshal(content(c))
+}
+
+// EmbeddedResource is a resource whose contents are included in a message, as
+// either text or base64-encoded binary data.
+type EmbeddedResource struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// Bytes returns the resource's contents: its text, or its blob decoded from base64.
+func (r EmbeddedResource) Bytes() ([]byte, error) {
+	if r.Blob == "" {
+		return []byte(r.Text), nil
+	}
+	data, err := base64.StdEncoding.DecodeString(r.Blob)
+	if err != nil {
+		return nil, fmt.Errorf("resource %s has an invalid blob: %w", r.URI, err)
+	}
+	return data, nil
+}
+
+// ResourceContent represents a single resource item in a resources/read response
+// (2025-03-26 format).
+type ResourceContent struct {
+	URI      string                 `json:"uri"`
+	Text     string                 `json:"text,omitempty"`
+	Content  []ContentItem          `json:"content,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
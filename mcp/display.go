@@ -0,0 +1,110 @@
+package mcp
+
+// Annotation keys under which the display metadata of tools, resources and prompts
+// is exposed in list responses, for hosts rendering them in pickers and menus.
+const (
+	DisplayNameAnnotation = "displayName"
+	CategoryAnnotation    = "category"
+	IconsAnnotation       = "icons"
+)
+
+// Icon is an image a host can show for a tool, resource or prompt, as in the
+// draft specification.
+type Icon struct {
+	// Src is the URL of the image, which may be a data: URL
+	Src string `json:"src"`
+
+	// MimeType is the type of the image, such as image/png
+	MimeType string `json:"mimeType,omitempty"`
+
+	// Sizes lists the sizes the image suits, such as "48x48" or "any"
+	Sizes []string `json:"sizes,omitempty"`
+}
+
+// Display is the display metadata of a tool, resource or prompt. Its fields are
+// empty when the server gives none.
+type Display struct {
+	// Name is the human-readable name to show instead of the identifier
+	Name string `json:"displayName,omitempty"`
+
+	// Category groups entries in a picker
+	Category string `json:"category,omitempty"`
+
+	Icons []Icon `json:"icons,omitempty"`
+}
+
+// DisplayFromAnnotations extracts the display metadata stored in an annotations
+// map, the name falling back to the "title" tool annotation of the 2025-03-26
+// specification. It accepts icons both as []Icon (as registered on the server)
+// and as []interface{} (as decoded from JSON on the client).
+func DisplayFromAnnotations(annotations map[string]interface{}) Display {
+	var display Display
+	if annotations == nil {
+		return display
+	}
+	display.Name, _ = annotations[DisplayNameAnnotation].(string)
+	if display.Name == "" {
+		display.Name, _ = annotations["title"].(string)
+	}
+	display.Category, _ = annotations[CategoryAnnotation].(string)
+
+	switch v := annotations[IconsAnnotation].(type) {
+	case []Icon:
+		display.Icons = v
+	case []interface{}:
+		for _, item := range v {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			icon := Icon{}
+			icon.Src, _ = entry["src"].(string)
+			if icon.Src == "" {
+				continue
+			}
+			icon.MimeType, _ = entry["mimeType"].(string)
+			if sizes, ok := entry["sizes"].([]interface{}); ok {
+				for _, size := range sizes {
+					if s, ok := size.(string); ok {
+						icon.Sizes = append(icon.Sizes, s)
+					}
+				}
+			}
+			display.Icons = append(display.Icons, icon)
+		}
+	}
+	return display
+}
+
+// display combines the title and icons of the specification's fields with the
+// display metadata of the annotations, the fields taking precedence
+func display(title string, icons []Icon, annotations map[string]interface{}) Display {
+	d := DisplayFromAnnotations(annotations)
+	if title != "" {
+		d.Name = title
+	}
+	if len(icons) > 0 {
+		d.Icons = icons
+	}
+	return d
+}
+
+// Display returns the display metadata of the tool.
+func (t Tool) Display() Display {
+	return display(t.Title, t.Icons, t.Annotations)
+}
+
+// Display returns the display metadata of the resource.
+func (r Resource) Display() Display {
+	return display(r.Title, r.Icons, r.Annotations)
+}
+
+// Display returns the display metadata of the resource template.
+func (r ResourceTemplate) Display() Display {
+	return display(r.Title, r.Icons, r.Annotations)
+}
+
+// Display returns the display metadata of the prompt.
+func (p Prompt) Display() Display {
+	return display(p.Title, p.Icons, p.Annotations)
+}
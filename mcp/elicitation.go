@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ElicitationCreateMethod is the method of the requests a server sends to ask the
+// user of a client for input. Elicitation is defined by the draft specification.
+const ElicitationCreateMethod = "elicitation/create"
+
+// ElicitationAction is the user's answer to an elicitation request.
+type ElicitationAction string
+
+// Actions a client answers an elicitation request with
+const (
+	// ElicitationAccept means the user submitted the requested input, which is
+	// in the result's content.
+	ElicitationAccept ElicitationAction = "accept"
+
+	// ElicitationDecline means the user explicitly declined to give the input.
+	ElicitationDecline ElicitationAction = "decline"
+
+	// ElicitationCancel means the user dismissed the request without choosing.
+	ElicitationCancel ElicitationAction = "cancel"
+)
+
+// ElicitationCapability represents the client's elicitation capability.
+// Defined as an empty object in the MCP specification.
+type ElicitationCapability struct {
+	// No fields defined in specification - empty object
+}
+
+// ElicitationCreateParams represents the parameters of an elicitation/create request.
+type ElicitationCreateParams struct {
+	// Message is shown to the user, explaining what input is needed and why
+	Message string `json:"message"`
+
+	// RequestedSchema is the schema of the input: an object whose properties are
+	// strings, numbers, integers or booleans (see ValidateElicitationSchema)
+	RequestedSchema map[string]interface{} `json:"requestedSchema"`
+}
+
+// ElicitationResult represents the client's answer to an elicitation/create request.
+type ElicitationResult struct {
+	Action ElicitationAction `json:"action"`
+
+	// Content holds the user's input, matching the requested schema. It is only
+	// set when Action is ElicitationAccept.
+	Content map[string]interface{} `json:"content,omitempty"`
+}
+
+// Accepted reports whether the user submitted the requested input.
+func (r *ElicitationResult) Accepted() bool {
+	return r != nil && r.Action == ElicitationAccept
+}
+
+// ValidateElicitationAction checks that action is one of the defined actions.
+func ValidateElicitationAction(action ElicitationAction) error {
+	switch action {
+	case ElicitationAccept, ElicitationDecline, ElicitationCancel:
+		return nil
+	default:
+		return fmt.Errorf("invalid elicitation action '%s', must be one of '%s', '%s' or '%s'",
+			action, ElicitationAccept, ElicitationDecline, ElicitationCancel)
+	}
+}
+
+// ValidateElicitationSchema checks that schema, given as decoded JSON, is a schema
+// clients can render as a form. The specification restricts requested schemas to
+// flat objects whose properties are strings, numbers, integers or booleans, so
+// nested objects and arrays are rejected.
+func ValidateElicitationSchema(schema map[string]interface{}) error {
+	if schemaType, _ := schema["type"].(string); schemaType != "object" {
+		return fmt.Errorf("requested schema must have type 'object', got '%v'", schema["type"])
+	}
+
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok || len(properties) == 0 {
+		return fmt.Errorf("requested schema must define at least one property")
+	}
+
+	// Check properties in a stable order, so that the error is too
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := properties[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("property '%s' of the requested schema must be an object", name)
+		}
+		switch property["type"] {
+		case "string", "number", "integer", "boolean":
+		default:
+			return fmt.Errorf("property '%s' of the requested schema has type '%v', but only string, number, integer and boolean properties are allowed",
+				name, property["type"])
+		}
+	}
+	return nil
+}
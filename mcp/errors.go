@@ -0,0 +1,51 @@
+package mcp
+
+// ErrorReason is a machine-readable code carried in the data of error responses,
+// telling why a request failed so that clients can react to common
+// misconfigurations without matching error messages.
+type ErrorReason string
+
+// Error reasons reported by servers.
+const (
+	// ReasonMethodNotFound means the server does not implement the method
+	ReasonMethodNotFound ErrorReason = "method_not_found"
+
+	// ReasonInvalidParams means the parameters of the request are invalid, such as
+	// tool arguments that violate the tool's input schema
+	ReasonInvalidParams ErrorReason = "invalid_params"
+
+	// ReasonToolNotFound means no tool of that name is available
+	ReasonToolNotFound ErrorReason = "tool_not_found"
+
+	// ReasonResourceNotFound means no resource or resource template matches the URI
+	ReasonResourceNotFound ErrorReason = "resource_not_found"
+
+	// ReasonPromptNotFound means no prompt of that name is available
+	ReasonPromptNotFound ErrorReason = "prompt_not_found"
+
+	// ReasonUnsupportedCapability means the request needs a capability the
+	// receiver does not provide, such as a client capability sent to a server
+	ReasonUnsupportedCapability ErrorReason = "unsupported_capability"
+
+	// ReasonUnsupportedVersion means the server does not support the protocol
+	// version the client asked for
+	ReasonUnsupportedVersion ErrorReason = "unsupported_protocol_version"
+
+	// ReasonMissingRoots means the request needs roots and none are configured
+	ReasonMissingRoots ErrorReason = "missing_roots"
+
+	// ReasonOutsideRoots means the request names a path outside of the roots
+	ReasonOutsideRoots ErrorReason = "outside_roots"
+)
+
+// ErrorData is the data of error responses that carry a reason.
+type ErrorData struct {
+	// Reason tells why the request failed
+	Reason ErrorReason `json:"reason"`
+
+	// Message describes the failure
+	Message string `json:"message"`
+
+	// Hint tells a human how to fix the failure
+	Hint string `json:"hint,omitempty"`
+}
@@ -0,0 +1,58 @@
+package mcp
+
+// ExamplesAnnotation is the annotation key under which example calls of a tool are
+// exposed in list responses.
+const ExamplesAnnotation = "examples"
+
+// ToolExample is an example call of a tool: the arguments it is called with and,
+// optionally, the result it returns. Examples show models how a tool is meant to be
+// called.
+type ToolExample struct {
+	// Description says what the example shows
+	Description string `json:"description,omitempty"`
+
+	// Input holds the arguments of the call
+	Input interface{} `json:"input"`
+
+	// Output holds the result of the call, if given
+	Output interface{} `json:"output,omitempty"`
+}
+
+// ExamplesFromAnnotations extracts the examples stored in an annotations map.
+// It accepts both []ToolExample (as registered on the server) and []interface{}
+// (as decoded from JSON on the client) and ignores entries that are not examples.
+func ExamplesFromAnnotations(annotations map[string]interface{}) []ToolExample {
+	if annotations == nil {
+		return nil
+	}
+
+	switch v := annotations[ExamplesAnnotation].(type) {
+	case []ToolExample:
+		return v
+	case []interface{}:
+		examples := make([]ToolExample, 0, len(v))
+		for _, item := range v {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if _, hasInput := entry["input"]; !hasInput {
+				continue
+			}
+			description, _ := entry["description"].(string)
+			examples = append(examples, ToolExample{
+				Description: description,
+				Input:       entry["input"],
+				Output:      entry["output"],
+			})
+		}
+		return examples
+	default:
+		return nil
+	}
+}
+
+// Examples returns the examples attached to the tool through its annotations.
+func (t Tool) Examples() []ToolExample {
+	return ExamplesFromAnnotations(t.Annotations)
+}
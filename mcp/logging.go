@@ -0,0 +1,40 @@
+package mcp
+
+// LogLevel is the severity of a log message sent with notifications/message.
+// The levels follow RFC 5424 syslog severities.
+type LogLevel string
+
+// Log levels in increasing order of severity.
+const (
+	LogLevelDebug     LogLevel = "debug"
+	LogLevelInfo      LogLevel = "info"
+	LogLevelNotice    LogLevel = "notice"
+	LogLevelWarning   LogLevel = "warning"
+	LogLevelError     LogLevel = "error"
+	LogLevelCritical  LogLevel = "critical"
+	LogLevelAlert     LogLevel = "alert"
+	LogLevelEmergency LogLevel = "emergency"
+)
+
+// logLevelSeverity ranks the log levels from least to most severe
+var logLevelSeverity = map[LogLevel]int{
+	LogLevelDebug:     0,
+	LogLevelInfo:      1,
+	LogLevelNotice:    2,
+	LogLevelWarning:   3,
+	LogLevelError:     4,
+	LogLevelCritical:  5,
+	LogLevelAlert:     6,
+	LogLevelEmergency: 7,
+}
+
+// IsValid reports whether the level is one of the levels defined by the specification.
+func (l LogLevel) IsValid() bool {
+	_, ok := logLevelSeverity[l]
+	return ok
+}
+
+// AtLeast reports whether the level is at least as severe as min.
+func (l LogLevel) AtLeast(min LogLevel) bool {
+	return logLevelSeverity[l] >= logLevelSeverity[min]
+}
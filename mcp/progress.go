@@ -310,6 +310,10 @@ type ProgressToken struct {
 
 	// ProtocolVersion tracks which MCP version this token is for
 	ProtocolVersion string
+
+	// SessionID is the client session the token's progress is delivered to, empty
+	// when it is not bound to one
+	SessionID string
 }
 
 // ProgressTokenManager manages progress tokens in a thread-safe manner
@@ -358,6 +362,51 @@ func (ptm *ProgressTokenManager) GenerateTokenForVersion(requestID string, proto
 	return token
 }
 
+// RegisterToken tracks a token chosen by the client, such as the progressToken in
+// a request's _meta. It returns false, leaving the existing token untouched, if
+// the token is already active.
+func (ptm *ProgressTokenManager) RegisterToken(token string, requestID string, protocolVersion string) bool {
+	ptm.mu.Lock()
+	defer ptm.mu.Unlock()
+
+	if existing, exists := ptm.tokens[token]; exists && existing.IsActive {
+		return false
+	}
+
+	ptm.tokens[token] = &ProgressToken{
+		Token:           token,
+		RequestID:       requestID,
+		CreatedAt:       time.Now(),
+		LastUpdate:      time.Now(),
+		IsActive:        true,
+		LastProgress:    -1,
+		ProtocolVersion: protocolVersion,
+	}
+	return true
+}
+
+// BindSession binds a token to the client session its progress is delivered to
+func (ptm *ProgressTokenManager) BindSession(token string, sessionID string) {
+	ptm.mu.Lock()
+	defer ptm.mu.Unlock()
+
+	if progressToken, exists := ptm.tokens[token]; exists {
+		progressToken.SessionID = sessionID
+	}
+}
+
+// TokenSession returns the client session a token is bound to, or "" if the token
+// is unknown or not bound to a session
+func (ptm *ProgressTokenManager) TokenSession(token string) string {
+	ptm.mu.RLock()
+	defer ptm.mu.RUnlock()
+
+	if progressToken, exists := ptm.tokens[token]; exists {
+		return progressToken.SessionID
+	}
+	return ""
+}
+
 // ValidateToken checks if a progress token is valid and active
 func (ptm *ProgressTokenManager) ValidateToken(token string) bool {
 	ptm.mu.RLock()
@@ -440,6 +489,23 @@ func (ptm *ProgressTokenManager) DeactivateToken(token string) error {
 	return nil
 }
 
+// RemoveToken forgets a token once the operation it tracked can no longer report
+// progress, such as when its request has finished
+func (ptm *ProgressTokenManager) RemoveToken(token string) {
+	ptm.mu.Lock()
+	defer ptm.mu.Unlock()
+
+	delete(ptm.tokens, token)
+}
+
+// Len returns the number of tokens being tracked, active or not
+func (ptm *ProgressTokenManager) Len() int {
+	ptm.mu.RLock()
+	defer ptm.mu.RUnlock()
+
+	return len(ptm.tokens)
+}
+
 // GetToken retrieves a progress token by its string value
 func (ptm *ProgressTokenManager) GetToken(token string) (*ProgressToken, error) {
 	ptm.mu.RLock()
@@ -819,14 +885,17 @@ func (pr *ProgressReporter) Complete(message ...string) error {
 	return nil
 }
 
-// Cancel cancels the progress reporting and deactivates the token
+// Cancel cancels the progress reporting and deactivates the token, along with those
+// of all child reporters
 func (pr *ProgressReporter) Cancel(message ...string) error {
-	if !pr.IsActive() {
+	// Mark as inactive, exactly once
+	if !atomic.CompareAndSwapInt32(&pr.isActive, 1, 0) {
 		return nil // Already inactive
 	}
 
-	// Mark as inactive
-	atomic.StoreInt32(&pr.isActive, 0)
+	for _, child := range pr.GetChildren() {
+		_ = child.Cancel(message...)
+	}
 
 	pr.mu.Lock()
 	if len(message) > 0 && message[0] != "" {
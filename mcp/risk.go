@@ -0,0 +1,74 @@
+package mcp
+
+// RiskAnnotation is the annotation key under which the risk level of a tool is
+// exposed in list responses.
+const RiskAnnotation = "risk"
+
+// RiskLevel classifies what calling a tool may do, so that servers can gate risky
+// calls and hosts can warn before making them.
+type RiskLevel string
+
+// Risk levels, from the least to the most risky.
+const (
+	// RiskUnclassified is the level of tools that declare neither a risk level nor
+	// the readOnlyHint or destructiveHint annotations
+	RiskUnclassified RiskLevel = "unclassified"
+
+	// RiskSafe tools have no side effects that matter, such as computations
+	RiskSafe RiskLevel = "safe"
+
+	// RiskReadOnly tools read data without modifying anything
+	RiskReadOnly RiskLevel = "read-only"
+
+	// RiskDestructive tools modify or delete data in a way that can be undone,
+	// such as from a backup or history
+	RiskDestructive RiskLevel = "destructive"
+
+	// RiskIrreversible tools have effects that cannot be undone, such as sending
+	// an email or a payment
+	RiskIrreversible RiskLevel = "irreversible"
+)
+
+// RiskLevels lists the risk levels a tool can declare, from the least to the most
+// risky.
+var RiskLevels = []RiskLevel{RiskSafe, RiskReadOnly, RiskDestructive, RiskIrreversible}
+
+// Valid reports whether the level is one of the known risk levels.
+func (l RiskLevel) Valid() bool {
+	if l == RiskUnclassified {
+		return true
+	}
+	for _, level := range RiskLevels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// RiskFromAnnotations returns the risk level declared in an annotations map. Tools
+// without a risk annotation are classified from the specification's hints:
+// destructiveHint makes them destructive and readOnlyHint read-only.
+func RiskFromAnnotations(annotations map[string]interface{}) RiskLevel {
+	if annotations == nil {
+		return RiskUnclassified
+	}
+	if level, ok := annotations[RiskAnnotation].(string); ok && RiskLevel(level).Valid() {
+		return RiskLevel(level)
+	}
+	if level, ok := annotations[RiskAnnotation].(RiskLevel); ok && level.Valid() {
+		return level
+	}
+	if destructive, _ := annotations["destructiveHint"].(bool); destructive {
+		return RiskDestructive
+	}
+	if readOnly, _ := annotations["readOnlyHint"].(bool); readOnly {
+		return RiskReadOnly
+	}
+	return RiskUnclassified
+}
+
+// Risk returns the risk level of the tool.
+func (t Tool) Risk() RiskLevel {
+	return RiskFromAnnotations(t.Annotations)
+}
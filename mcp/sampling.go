@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"fmt"
+	"math"
+)
+
+// MaxSamplingTemperature is the highest sampling temperature accepted.
+// Providers differ in their upper bound; 2 covers the common ones.
+const MaxSamplingTemperature = 2.0
+
+// Values of the includeContext sampling parameter, which asks the client to add
+// conversation context from its MCP sessions to the prompt
+const (
+	// IncludeContextNone requests no additional context. It is the default.
+	IncludeContextNone = "none"
+
+	// IncludeContextThisServer requests context from the requesting server's session only
+	IncludeContextThisServer = "thisServer"
+
+	// IncludeContextAllServers requests context from every server the client is connected to
+	IncludeContextAllServers = "allServers"
+)
+
+// ValidateIncludeContext checks that value is a known includeContext value.
+// An empty value is accepted and means IncludeContextNone.
+func ValidateIncludeContext(value string) error {
+	switch value {
+	case "", IncludeContextNone, IncludeContextThisServer, IncludeContextAllServers:
+		return nil
+	default:
+		return fmt.Errorf("invalid includeContext '%s', must be one of '%s', '%s' or '%s'",
+			value, IncludeContextNone, IncludeContextThisServer, IncludeContextAllServers)
+	}
+}
+
+// ValidateSamplingParameters validates the optional generation parameters of a
+// sampling/createMessage request for a protocol version.
+//
+// Every released version defines temperature, stopSequences and metadata, so
+// they are rejected only for versions this library does not know. An empty
+// version, before negotiation, is accepted. Temperature must be between 0 and
+// MaxSamplingTemperature, and stop sequences must not be empty. Metadata is
+// provider-specific and passed through unchecked.
+func ValidateSamplingParameters(version string, temperature *float64, stopSequences []string, metadata map[string]interface{}) error {
+	if temperature == nil && len(stopSequences) == 0 && len(metadata) == 0 {
+		return nil
+	}
+
+	switch version {
+	case Version20241105, Version20250326, VersionDraft:
+	case "":
+		// Not negotiated yet; the peer decides
+	default:
+		return fmt.Errorf("sampling parameters not supported in protocol version '%s'", version)
+	}
+
+	if temperature != nil {
+		if math.IsNaN(*temperature) || *temperature < 0 || *temperature > MaxSamplingTemperature {
+			return fmt.Errorf("temperature must be between 0 and %g, got %g", MaxSamplingTemperature, *temperature)
+		}
+	}
+
+	for i, stop := range stopSequences {
+		if stop == "" {
+			return fmt.Errorf("stop sequence %d is empty", i)
+		}
+	}
+
+	return nil
+}
+
+// SamplingMessageContent represents the content of a sampling message: its type
+// (text, image or audio) and the text or base64-encoded data.
+type SamplingMessageContent struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// IsValidForVersion checks if the content type is valid for the given protocol version
+func (c *SamplingMessageContent) IsValidForVersion(version string) bool {
+	switch version {
+	case "draft", "2025-03-26":
+		// These versions support text, image, and audio content types
+		return c.Type == "text" || c.Type == "image" || c.Type == "audio"
+	case "2024-11-05":
+		// This version only supports text and image content types
+		return c.Type == "text" || c.Type == "image"
+	default:
+		// Unknown version, default to most restrictive
+		return c.Type == "text"
+	}
+}
+
+// SamplingMessage represents a message in a sampling conversation.
+type SamplingMessage struct {
+	Role    string                 `json:"role"`
+	Content SamplingMessageContent `json:"content"`
+}
+
+// SamplingModelHint represents a hint for model selection in sampling requests.
+type SamplingModelHint struct {
+	Name string `json:"name"`
+}
+
+// SamplingModelPreferences represents the model preferences for a sampling request.
+type SamplingModelPreferences struct {
+	Hints                []SamplingModelHint `json:"hints,omitempty"`
+	CostPriority         *float64            `json:"costPriority,omitempty"`
+	SpeedPriority        *float64            `json:"speedPriority,omitempty"`
+	IntelligencePriority *float64            `json:"intelligencePriority,omitempty"`
+}
+
+// SamplingCreateMessageParams represents the parameters for a sampling/createMessage request.
+// The server sends them and the client's sampling handler receives them as this type.
+type SamplingCreateMessageParams struct {
+	Messages         []SamplingMessage        `json:"messages"`
+	ModelPreferences SamplingModelPreferences `json:"modelPreferences"`
+	SystemPrompt     string                   `json:"systemPrompt,omitempty"`
+	MaxTokens        int                      `json:"maxTokens,omitempty"`
+	IncludeContext   string                   `json:"includeContext,omitempty"`
+	Temperature      *float64                 `json:"temperature,omitempty"`
+	StopSequences    []string                 `json:"stopSequences,omitempty"`
+	Metadata         map[string]interface{}   `json:"metadata,omitempty"`
+
+	// Streaming asks for the response to be streamed in chunks, sent as
+	// SamplingChunkNotification notifications ahead of the response
+	Streaming *SamplingStreamingParams `json:"streaming,omitempty"`
+}
+
+// SamplingChunkNotification is the method of the notifications that carry the
+// chunks of a streamed sampling response.
+const SamplingChunkNotification = "notifications/sampling/chunk"
+
+// SamplingStreamingParams configure a streamed sampling response. Streaming is
+// available from protocol version 2025-03-26.
+type SamplingStreamingParams struct {
+	ChunkSize      int  `json:"chunkSize,omitempty"`      // Maximum characters per chunk
+	MaxChunks      int  `json:"maxChunks,omitempty"`      // Maximum number of chunks (0 for unlimited)
+	StopOnComplete bool `json:"stopOnComplete,omitempty"` // Stop streaming when isComplete=true
+}
+
+// SamplingChunkParams are the params of a SamplingChunkNotification. Chunks are
+// numbered from 0 by ChunkIndex. The response to the request follows the last
+// chunk, with IsComplete set and ChunkIndex holding the number of chunks sent.
+type SamplingChunkParams struct {
+	RequestID interface{}      `json:"requestId"` // ID of the sampling request the chunk belongs to
+	Chunk     SamplingResponse `json:"chunk"`
+}
+
+// ContextInclusion returns the requested includeContext value, treating an
+// absent value as IncludeContextNone.
+func (p SamplingCreateMessageParams) ContextInclusion() string {
+	if p.IncludeContext == "" {
+		return IncludeContextNone
+	}
+	return p.IncludeContext
+}
+
+// IncludesThisServerContext reports whether the handler should add conversation
+// context from the requesting server's session. It is true for both
+// "thisServer" and "allServers", since the latter includes the requesting server.
+func (p SamplingCreateMessageParams) IncludesThisServerContext() bool {
+	return p.IncludeContext == IncludeContextThisServer || p.IncludeContext == IncludeContextAllServers
+}
+
+// IncludesAllServersContext reports whether the handler should add conversation
+// context from every server the client is connected to.
+func (p SamplingCreateMessageParams) IncludesAllServersContext() bool {
+	return p.IncludeContext == IncludeContextAllServers
+}
+
+// SamplingResponse represents the response to a sampling/createMessage request.
+type SamplingResponse struct {
+	Role       string                 `json:"role"`
+	Content    SamplingMessageContent `json:"content"`
+	Model      string                 `json:"model,omitempty"`
+	StopReason string                 `json:"stopReason,omitempty"`
+
+	// Streaming fields
+	IsComplete bool `json:"isComplete,omitempty"` // Only for streaming responses
+	ChunkIndex int  `json:"chunkIndex,omitempty"` // Only for streaming responses
+}
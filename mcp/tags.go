@@ -0,0 +1,62 @@
+package mcp
+
+import "strings"
+
+// TagsAnnotation is the annotation key under which tool, resource and prompt
+// tags are exposed in list responses.
+const TagsAnnotation = "tags"
+
+// TagsFromAnnotations extracts the tags stored in an annotations map.
+// It accepts both []string (as registered on the server) and []interface{}
+// (as decoded from JSON on the client) and ignores non-string entries.
+func TagsFromAnnotations(annotations map[string]interface{}) []string {
+	if annotations == nil {
+		return nil
+	}
+
+	switch v := annotations[TagsAnnotation].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		tags := make([]string, 0, len(v))
+		for _, item := range v {
+			if tag, ok := item.(string); ok {
+				tags = append(tags, tag)
+			}
+		}
+		return tags
+	case string:
+		return []string{v}
+	default:
+		return nil
+	}
+}
+
+// HasAllTags reports whether annotations carry every one of the wanted tags.
+// Tags are compared case-insensitively. An empty wanted list always matches.
+func HasAllTags(annotations map[string]interface{}, wanted []string) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+
+	have := TagsFromAnnotations(annotations)
+	for _, want := range wanted {
+		found := false
+		for _, tag := range have {
+			if strings.EqualFold(tag, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Tags returns the tags attached to the tool through its annotations.
+func (t Tool) Tags() []string {
+	return TagsFromAnnotations(t.Annotations)
+}
@@ -13,6 +13,10 @@ type Tool struct {
 	InputSchema  map[string]interface{} `json:"inputSchema"`
 	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
 	Annotations  map[string]interface{} `json:"annotations,omitempty"`
+
+	// Title and Icons are the display metadata of the draft specification (see Display)
+	Title string `json:"title,omitempty"`
+	Icons []Icon `json:"icons,omitempty"`
 }
 
 // Resource represents a resource available from an MCP server.
@@ -22,7 +26,26 @@ type Resource struct {
 	Name        string                 `json:"name,omitempty"`
 	Description string                 `json:"description,omitempty"`
 	MimeType    string                 `json:"mimeType,omitempty"`
+	Size        *int64                 `json:"size,omitempty"`
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+
+	// Title and Icons are the display metadata of the draft specification (see Display)
+	Title string `json:"title,omitempty"`
+	Icons []Icon `json:"icons,omitempty"`
+}
+
+// ResourceTemplate represents a templated resource available from an MCP server,
+// whose URI template takes parameters such as /users/{id}.
+type ResourceTemplate struct {
+	URITemplate string                 `json:"uriTemplate"`
+	Name        string                 `json:"name,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	MimeType    string                 `json:"mimeType,omitempty"`
 	Annotations map[string]interface{} `json:"annotations,omitempty"`
+
+	// Title and Icons are the display metadata of the draft specification (see Display)
+	Title string `json:"title,omitempty"`
+	Icons []Icon `json:"icons,omitempty"`
 }
 
 // Prompt represents a prompt template available from an MCP server.
@@ -32,6 +55,10 @@ type Prompt struct {
 	Description string                 `json:"description,omitempty"`
 	Arguments   []PromptArgument       `json:"arguments,omitempty"`
 	Annotations map[string]interface{} `json:"annotations,omitempty"`
+
+	// Title and Icons are the display metadata of the draft specification (see Display)
+	Title string `json:"title,omitempty"`
+	Icons []Icon `json:"icons,omitempty"`
 }
 
 // PromptArgument represents an argument for a prompt template.
@@ -0,0 +1,551 @@
+package mcptest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/localrivet/gomcp/server"
+)
+
+// Names of the checks of the conformance suite, in the order they run
+const (
+	CheckInitialize           = "initialize"
+	CheckVersionNegotiation   = "version negotiation"
+	CheckHandshakeOrdering    = "handshake ordering"
+	CheckPing                 = "ping"
+	CheckRequestIDs           = "request ids"
+	CheckParseError           = "parse error"
+	CheckMethodNotFound       = "method not found"
+	CheckUnknownTool          = "unknown tool"
+	CheckUnknownResource      = "unknown resource"
+	CheckPagination           = "pagination"
+	CheckProgressNotification = "progress notifications"
+)
+
+// Result is the outcome of one check of the conformance suite.
+type Result struct {
+	Check string
+
+	// Err is why the server failed the check, nil if it passed
+	Err error
+
+	// Skipped is why the check did not run, such as a capability the server does
+	// not declare, or empty if it ran
+	Skipped string
+}
+
+// Report is the outcome of the conformance suite.
+type Report struct {
+	Results []Result
+}
+
+// Failed returns the results of the checks the server failed.
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// String returns one line per check, such as "FAIL ping: ..."
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, result := range r.Results {
+		switch {
+		case result.Err != nil:
+			fmt.Fprintf(&b, "FAIL %s: %v\n", result.Check, result.Err)
+		case result.Skipped != "":
+			fmt.Fprintf(&b, "SKIP %s: %s\n", result.Check, result.Skipped)
+		default:
+			fmt.Fprintf(&b, "PASS %s\n", result.Check)
+		}
+	}
+	return b.String()
+}
+
+// Option configures the conformance suite.
+type Option func(*config)
+
+type config struct {
+	version      string
+	timeout      time.Duration
+	progressTool string
+	progressArgs map[string]interface{}
+	skip         map[string]bool
+}
+
+// WithProtocolVersion sets the protocol version the checks initialize with. The
+// default is the latest stable version.
+func WithProtocolVersion(version string) Option {
+	return func(c *config) {
+		c.version = version
+	}
+}
+
+// WithTimeout sets how long the checks wait for each response. The default is
+// DefaultTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.timeout = timeout
+	}
+}
+
+// WithProgressTool names a tool that reports progress when called with args, for
+// the check of progress notifications. Without it, that check is skipped.
+func WithProgressTool(name string, args map[string]interface{}) Option {
+	return func(c *config) {
+		c.progressTool = name
+		c.progressArgs = args
+	}
+}
+
+// WithSkip skips the named checks, such as CheckPagination.
+func WithSkip(checks ...string) Option {
+	return func(c *config) {
+		for _, name := range checks {
+			c.skip[name] = true
+		}
+	}
+}
+
+// check is one check of the conformance suite
+type check struct {
+	name string
+
+	// handshake is set for checks that perform the initialize handshake themselves;
+	// the others run on a connection that completed it
+	handshake bool
+
+	run func(c *Conn, init *initializeResult, cfg *config) error
+}
+
+// initializeResult is the result of an initialize request
+type initializeResult struct {
+	ProtocolVersion string                     `json:"protocolVersion"`
+	Capabilities    map[string]json.RawMessage `json:"capabilities"`
+	ServerInfo      struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"serverInfo"`
+}
+
+// skipped is returned by checks that do not apply to the server
+type skipped string
+
+func (s skipped) Error() string {
+	return string(s)
+}
+
+var checks = []check{
+	{CheckInitialize, true, checkInitialize},
+	{CheckVersionNegotiation, true, checkVersionNegotiation},
+	{CheckHandshakeOrdering, true, checkHandshakeOrdering},
+	{CheckPing, false, checkPing},
+	{CheckRequestIDs, false, checkRequestIDs},
+	{CheckParseError, false, checkParseError},
+	{CheckMethodNotFound, false, checkMethodNotFound},
+	{CheckUnknownTool, false, checkUnknownTool},
+	{CheckUnknownResource, false, checkUnknownResource},
+	{CheckPagination, false, checkPagination},
+	{CheckProgressNotification, false, checkProgressNotifications},
+}
+
+// Conformance checks servers made by newServer against the protocol specification:
+// the initialize handshake, JSON-RPC error codes, pagination and the ordering of
+// notifications. Each check runs against a new server, over the embedded transport.
+//
+// Example:
+//
+//	report := mcptest.Conformance(newWeatherServer)
+//	if failed := report.Failed(); len(failed) > 0 {
+//	    log.Fatal(report)
+//	}
+func Conformance(newServer func() server.Server, options ...Option) *Report {
+	cfg := &config{
+		version: mcp.Version20250326,
+		timeout: DefaultTimeout,
+		skip:    map[string]bool{},
+	}
+	for _, option := range options {
+		option(cfg)
+	}
+
+	report := &Report{}
+	for _, chk := range checks {
+		report.Results = append(report.Results, runCheck(newServer, chk, cfg))
+	}
+	return report
+}
+
+// Run runs the conformance suite as subtests of t, one per check, and fails those
+// the server does not pass. See Conformance.
+func Run(t *testing.T, newServer func() server.Server, options ...Option) {
+	t.Helper()
+	for _, result := range Conformance(newServer, options...).Results {
+		result := result
+		t.Run(result.Check, func(t *testing.T) {
+			if result.Skipped != "" {
+				t.Skip(result.Skipped)
+			}
+			if result.Err != nil {
+				t.Error(result.Err)
+			}
+		})
+	}
+}
+
+// runCheck runs a check against a new server
+func runCheck(newServer func() server.Server, chk check, cfg *config) Result {
+	result := Result{Check: chk.name}
+	if cfg.skip[chk.name] {
+		result.Skipped = "skipped with WithSkip"
+		return result
+	}
+
+	conn, err := Connect(newServer())
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer conn.Close()
+	conn.Timeout = cfg.timeout
+
+	var init *initializeResult
+	if !chk.handshake {
+		if init, err = handshake(conn, cfg.version); err != nil {
+			result.Err = fmt.Errorf("initialize handshake failed: %w", err)
+			return result
+		}
+	}
+
+	err = chk.run(conn, init, cfg)
+	var skip skipped
+	if errors.As(err, &skip) {
+		result.Skipped = string(skip)
+	} else {
+		result.Err = err
+	}
+	return result
+}
+
+// handshake initializes a connection, returning the server's initialize result
+func handshake(c *Conn, version string) (*initializeResult, error) {
+	response, err := c.Initialize(version)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("server refused protocol version %s: %w", version, response.Error)
+	}
+	var init initializeResult
+	if err := json.Unmarshal(response.Result, &init); err != nil {
+		return nil, fmt.Errorf("invalid initialize result: %w", err)
+	}
+	return &init, nil
+}
+
+func checkInitialize(c *Conn, _ *initializeResult, cfg *config) error {
+	init, err := handshake(c, cfg.version)
+	if err != nil {
+		return err
+	}
+	if !knownVersion(init.ProtocolVersion) {
+		return fmt.Errorf("server answered with unknown protocol version %q", init.ProtocolVersion)
+	}
+	if init.ProtocolVersion != cfg.version {
+		return fmt.Errorf("server answered with protocol version %s instead of the supported %s", init.ProtocolVersion, cfg.version)
+	}
+	if init.ServerInfo.Name == "" {
+		return errors.New("initialize result has no serverInfo.name")
+	}
+	if init.Capabilities == nil {
+		return errors.New("initialize result has no capabilities object")
+	}
+	return nil
+}
+
+// checkVersionNegotiation checks that a version the server does not support is
+// answered with one it does, or refused with Invalid params
+func checkVersionNegotiation(c *Conn, _ *initializeResult, _ *config) error {
+	response, err := c.Initialize("1999-01-01")
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		if response.Error.Code != -32602 {
+			return fmt.Errorf("unsupported protocol version refused with code %d, want -32602", response.Error.Code)
+		}
+		return nil
+	}
+	var init initializeResult
+	if err := json.Unmarshal(response.Result, &init); err != nil {
+		return fmt.Errorf("invalid initialize result: %w", err)
+	}
+	if !knownVersion(init.ProtocolVersion) {
+		return fmt.Errorf("unsupported protocol version answered with %q, not a version the server supports", init.ProtocolVersion)
+	}
+	return nil
+}
+
+// checkHandshakeOrdering checks that the server sends nothing but log messages and
+// pings before it answered initialize, and that its notifications carry no ID
+func checkHandshakeOrdering(c *Conn, _ *initializeResult, cfg *config) error {
+	if _, err := handshake(c, cfg.version); err != nil {
+		return err
+	}
+	if _, err := c.Call("ping", nil); err != nil {
+		return err
+	}
+
+	answered := false
+	for _, message := range c.Messages() {
+		if message.IsResponse() {
+			answered = true
+			continue
+		}
+		if message.Method != "" && message.ID != nil && strings.HasPrefix(message.Method, "notifications/") {
+			return fmt.Errorf("notification %s carries an ID", message.Method)
+		}
+		if !answered && message.Method != "notifications/message" && message.Method != "ping" {
+			return fmt.Errorf("server sent %s before answering initialize", message.Method)
+		}
+	}
+	return nil
+}
+
+func checkPing(c *Conn, _ *initializeResult, _ *config) error {
+	response, err := c.Call("ping", nil)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return fmt.Errorf("ping failed: %w", response.Error)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(response.Result, &result); err != nil || result == nil {
+		return fmt.Errorf("ping result is %s, want an object", response.Result)
+	}
+	return nil
+}
+
+// checkRequestIDs checks that responses echo string and number IDs, as JSON-RPC 2.0
+// responses
+func checkRequestIDs(c *Conn, _ *initializeResult, _ *config) error {
+	for _, id := range []interface{}{"mcptest-id", 7} {
+		response, err := c.CallWithID(id, "ping", nil)
+		if err != nil {
+			return err
+		}
+		if response.JSONRPC != "2.0" {
+			return fmt.Errorf("response to request %v has jsonrpc %q, want 2.0", id, response.JSONRPC)
+		}
+	}
+	return nil
+}
+
+func checkParseError(c *Conn, _ *initializeResult, _ *config) error {
+	response, err := c.Exchange([]byte(`{"jsonrpc":"2.0","id":1,"method":`))
+	if err != nil {
+		return err
+	}
+	return wantErrorCode(response, -32700)
+}
+
+func checkMethodNotFound(c *Conn, _ *initializeResult, _ *config) error {
+	response, err := c.Call("mcptest/no-such-method", nil)
+	if err != nil {
+		return err
+	}
+	return wantErrorCode(response, -32601)
+}
+
+func checkUnknownTool(c *Conn, init *initializeResult, _ *config) error {
+	if init.Capabilities["tools"] == nil {
+		return skipped("server declares no tools capability")
+	}
+	response, err := c.Call("tools/call", map[string]interface{}{"name": "mcptest-no-such-tool", "arguments": map[string]interface{}{}})
+	if err != nil {
+		return err
+	}
+	return wantErrorCode(response, -32602)
+}
+
+func checkUnknownResource(c *Conn, init *initializeResult, _ *config) error {
+	if init.Capabilities["resources"] == nil {
+		return skipped("server declares no resources capability")
+	}
+	response, err := c.Call("resources/read", map[string]interface{}{"uri": "mcptest://no-such-resource"})
+	if err != nil {
+		return err
+	}
+	return wantErrorCode(response, -32002)
+}
+
+// maxPages bounds the pages a list may have, so that a cursor that never ends fails
+const maxPages = 1000
+
+// checkPagination follows the cursors of every list the server declares, checking
+// that the pages end and never repeat an item
+func checkPagination(c *Conn, init *initializeResult, _ *config) error {
+	lists := []struct {
+		capability, method, field, key string
+	}{
+		{"tools", "tools/list", "tools", "name"},
+		{"resources", "resources/list", "resources", "uri"},
+		{"prompts", "prompts/list", "prompts", "name"},
+	}
+
+	checked := 0
+	for _, list := range lists {
+		if init.Capabilities[list.capability] == nil {
+			continue
+		}
+		checked++
+
+		seen := map[string]bool{}
+		cursor := ""
+		for page := 1; ; page++ {
+			if page > maxPages {
+				return fmt.Errorf("%s did not end after %d pages", list.method, maxPages)
+			}
+			var params interface{}
+			if cursor != "" {
+				params = map[string]interface{}{"cursor": cursor}
+			}
+			response, err := c.Call(list.method, params)
+			if err != nil {
+				return err
+			}
+			if response.Error != nil {
+				return fmt.Errorf("%s page %d failed: %w", list.method, page, response.Error)
+			}
+
+			var result map[string]json.RawMessage
+			if err := json.Unmarshal(response.Result, &result); err != nil {
+				return fmt.Errorf("%s page %d is not an object: %w", list.method, page, err)
+			}
+			var items []map[string]interface{}
+			if err := json.Unmarshal(result[list.field], &items); err != nil || items == nil {
+				return fmt.Errorf("%s page %d has no %s array", list.method, page, list.field)
+			}
+			for _, item := range items {
+				key, _ := item[list.key].(string)
+				if seen[key] {
+					return fmt.Errorf("%s returned %s %q on more than one page", list.method, list.key, key)
+				}
+				seen[key] = true
+			}
+
+			next := ""
+			if raw, ok := result["nextCursor"]; ok {
+				if err := json.Unmarshal(raw, &next); err != nil {
+					return fmt.Errorf("%s page %d has a nextCursor that is not a string", list.method, page)
+				}
+			}
+			if next == "" {
+				break
+			}
+			if next == cursor {
+				return fmt.Errorf("%s returned cursor %q for the page it was given", list.method, next)
+			}
+			cursor = next
+		}
+	}
+	if checked == 0 {
+		return skipped("server declares no tools, resources or prompts")
+	}
+	return nil
+}
+
+// progressGrace is how long to wait for stray progress notifications after a response
+const progressGrace = 100 * time.Millisecond
+
+// checkProgressNotifications calls the progress tool with a progress token, and
+// checks that its progress notifications carry the token, increase, and arrive
+// before the response to the call
+func checkProgressNotifications(c *Conn, _ *initializeResult, cfg *config) error {
+	if cfg.progressTool == "" {
+		return skipped("no tool that reports progress; see WithProgressTool")
+	}
+
+	const token = "mcptest-progress"
+	args := cfg.progressArgs
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	response, err := c.CallWithID("mcptest-progress-call", "tools/call", map[string]interface{}{
+		"name":      cfg.progressTool,
+		"arguments": args,
+		"_meta":     map[string]interface{}{"progressToken": token},
+	})
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return fmt.Errorf("calling %s failed: %w", cfg.progressTool, response.Error)
+	}
+	time.Sleep(progressGrace)
+
+	answered := false
+	last, notifications := -1.0, 0
+	for _, message := range c.Messages() {
+		if message.IsResponse() && idKey(message.ID) == idKey(response.ID) {
+			answered = true
+			continue
+		}
+		if message.Method != "notifications/progress" {
+			continue
+		}
+		var params struct {
+			ProgressToken interface{} `json:"progressToken"`
+			Progress      float64     `json:"progress"`
+		}
+		if err := json.Unmarshal(message.Params, &params); err != nil {
+			return fmt.Errorf("invalid progress notification: %w", err)
+		}
+		if params.ProgressToken != token {
+			return fmt.Errorf("progress notification carries token %v, want %s", params.ProgressToken, token)
+		}
+		if answered {
+			return fmt.Errorf("progress notification (progress %v) arrived after the response", params.Progress)
+		}
+		if params.Progress <= last {
+			return fmt.Errorf("progress went from %v to %v; it must increase", last, params.Progress)
+		}
+		last = params.Progress
+		notifications++
+	}
+	if notifications == 0 {
+		return fmt.Errorf("%s reported no progress on the request's token", cfg.progressTool)
+	}
+	return nil
+}
+
+// wantErrorCode checks that a response is an error with the given code
+func wantErrorCode(response *Message, code int) error {
+	if response.Error == nil {
+		return fmt.Errorf("got result %s, want error %d", response.Result, code)
+	}
+	if response.Error.Code != code {
+		return fmt.Errorf("got error %d (%s), want error %d", response.Error.Code, response.Error.Message, code)
+	}
+	return nil
+}
+
+// knownVersion reports whether a protocol version is one gomcp supports
+func knownVersion(version string) bool {
+	for _, supported := range mcp.SupportedVersions {
+		if version == supported {
+			return true
+		}
+	}
+	return false
+}
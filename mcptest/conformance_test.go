@@ -0,0 +1,85 @@
+package mcptest
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/server"
+)
+
+// newServer returns a server with more tools than fit on one page, a resource, a
+// prompt and a tool that reports progress
+func newServer() server.Server {
+	s := server.NewServer("conformance", server.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	for i := 0; i < 60; i++ {
+		s.Tool(fmt.Sprintf("tool-%02d", i), "A tool", func(ctx *server.Context, args struct{}) (string, error) {
+			return "ok", nil
+		})
+	}
+	s.Tool("count", "Counts to three, reporting progress", func(ctx *server.Context, args struct{}) (string, error) {
+		for i := 1; i <= 3; i++ {
+			if err := ctx.SendProgress(float64(i), nil, ""); err != nil {
+				return "", err
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		return "done", nil
+	})
+	s.Resource("/status", "Status", func(ctx *server.Context, args interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	s.Prompt("greet", "Greets the user", server.User("Hello"))
+	return s
+}
+
+func TestConformance(t *testing.T) {
+	Run(t, newServer, WithProgressTool("count", nil))
+}
+
+func TestConformanceReport(t *testing.T) {
+	report := Conformance(newServer, WithProgressTool("missing", nil), WithSkip(CheckPagination))
+
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Check != CheckProgressNotification {
+		t.Fatalf("Expected only the progress check to fail, got:\n%s", report)
+	}
+	if !strings.Contains(failed[0].Err.Error(), "tool not found: missing") {
+		t.Errorf("Expected the server's error, got %v", failed[0].Err)
+	}
+	if !strings.Contains(report.String(), "SKIP pagination: skipped with WithSkip") {
+		t.Errorf("Expected pagination to be skipped, got:\n%s", report)
+	}
+}
+
+func TestConn(t *testing.T) {
+	conn, err := Connect(newServer())
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer conn.Close()
+
+	if response, err := conn.Initialize("2025-03-26"); err != nil || response.Error != nil {
+		t.Fatalf("Initialize failed: %v, %v", err, response)
+	}
+	response, err := conn.Call("tools/call", map[string]interface{}{"name": "count", "arguments": map[string]interface{}{}})
+	if err != nil || response.Error != nil || !strings.Contains(string(response.Result), "done") {
+		t.Fatalf("Expected the tool's result, got %v, %v", response, err)
+	}
+	if response, err := conn.Call("mcptest/no-such-method", nil); err != nil || response.Error == nil || response.Error.Code != -32601 {
+		t.Errorf("Expected Method not found, got %v, %v", response, err)
+	}
+
+	// Every message is kept, the notifications the server queued until the
+	// handshake included
+	notified := false
+	for _, message := range conn.Messages() {
+		notified = notified || message.IsNotification() && message.Method == "notifications/tools/list_changed"
+	}
+	if !notified {
+		t.Errorf("Expected the queued list_changed notifications, got %+v", conn.Messages())
+	}
+}
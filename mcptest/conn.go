@@ -0,0 +1,303 @@
+// Package mcptest provides utilities for testing MCP servers built with gomcp.
+//
+// Conn speaks raw JSON-RPC to a server over the embedded transport, so tests can
+// look at exactly what the server sends, error codes and notifications included.
+// Conformance and Run check a server against the protocol specification, and can
+// run in the CI of any server built with gomcp:
+//
+//	func TestConformance(t *testing.T) {
+//	    mcptest.Run(t, func() server.Server {
+//	        return newWeatherServer()
+//	    })
+//	}
+//
+// FakeServer and FakeClient script one side of a connection for unit tests of the
+// other: they answer calls as their expectations say, and record every call.
+package mcptest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/localrivet/gomcp/transport/embedded"
+)
+
+// DefaultTimeout is how long a Conn waits for the response to a request
+const DefaultTimeout = 5 * time.Second
+
+// Message is a JSON-RPC message received from the server: a response, a
+// notification or a request.
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// IsResponse reports whether the message is a response to a request.
+func (m *Message) IsResponse() bool {
+	return m.Method == "" && m.ID != nil
+}
+
+// IsNotification reports whether the message is a notification.
+func (m *Message) IsNotification() bool {
+	return m.Method != "" && (m.ID == nil || string(m.ID) == "null")
+}
+
+// Error is the error of a JSON-RPC response.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error implements the error interface, including the error's data if it has any
+func (e *Error) Error() string {
+	if len(e.Data) > 0 {
+		return fmt.Sprintf("JSON-RPC error %d: %s: %s", e.Code, e.Message, e.Data)
+	}
+	return fmt.Sprintf("JSON-RPC error %d: %s", e.Code, e.Message)
+}
+
+// Conn is a raw JSON-RPC connection to a server over the embedded transport. It
+// keeps every message the server sends, and answers the server's pings so that it
+// behaves as a client that declared no capabilities.
+type Conn struct {
+	// Timeout is how long to wait for the response to a request; DefaultTimeout if zero
+	Timeout time.Duration
+
+	transport *embedded.Transport
+	stop      context.CancelFunc
+	served    chan error
+
+	mu       sync.Mutex
+	messages []Message
+	pending  map[string]chan Message
+	nextID   int64
+}
+
+// Connect serves srv over a new embedded transport and returns a connection to it.
+// The server must not have been started. Close the connection to shut it down.
+//
+// Example:
+//
+//	conn, err := mcptest.Connect(srv)
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	defer conn.Close()
+//	if _, err := conn.Initialize("2025-03-26"); err != nil {
+//	    t.Fatal(err)
+//	}
+//	response, err := conn.Call("tools/list", nil)
+func Connect(srv server.Server) (*Conn, error) {
+	serverTransport, clientTransport := embedded.NewTransportPair()
+	srv.AsEmbedded(serverTransport)
+	if err := clientTransport.Initialize(); err != nil {
+		return nil, fmt.Errorf("failed to initialize transport: %w", err)
+	}
+	if err := clientTransport.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start transport: %w", err)
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	c := &Conn{
+		transport: clientTransport,
+		stop:      stop,
+		served:    make(chan error, 1),
+		pending:   make(map[string]chan Message),
+	}
+	go func() {
+		c.served <- srv.Serve(ctx)
+	}()
+	go c.receive()
+	return c, nil
+}
+
+// Initialize performs the initialize handshake, requesting the given protocol
+// version, and sends notifications/initialized once the server accepted it. It
+// returns the initialize response, which holds the server's error if it refused.
+func (c *Conn) Initialize(version string) (*Message, error) {
+	response, err := c.Call("initialize", map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": "mcptest", "version": "1.0.0"},
+	})
+	if err != nil || response.Error != nil {
+		return response, err
+	}
+	return response, c.Notify("notifications/initialized", nil)
+}
+
+// Call sends a request and waits for its response. Error responses are returned
+// as messages with Error set; the error is for requests that got no response.
+func (c *Conn) Call(method string, params interface{}) (*Message, error) {
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	c.mu.Unlock()
+	return c.CallWithID(id, method, params)
+}
+
+// CallWithID sends a request with the given ID, a string or a number, and waits
+// for its response.
+func (c *Conn) CallWithID(id interface{}, method string, params interface{}) (*Message, error) {
+	request := map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method}
+	if params != nil {
+		request["params"] = params
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	return c.Exchange(data)
+}
+
+// Exchange sends a raw message and waits for the response with its ID. Messages
+// whose ID cannot be read, such as malformed JSON, are answered with a null ID.
+func (c *Conn) Exchange(message []byte) (*Message, error) {
+	var request struct {
+		ID json.RawMessage `json:"id"`
+	}
+	key := "null"
+	if json.Unmarshal(message, &request) == nil && request.ID != nil {
+		key = idKey(request.ID)
+	}
+
+	responses := make(chan Message, 1)
+	c.mu.Lock()
+	if _, waiting := c.pending[key]; waiting {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("a request with ID %s is already waiting for its response", key)
+	}
+	c.pending[key] = responses
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, key)
+		c.mu.Unlock()
+	}()
+
+	if err := c.transport.Send(message); err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	select {
+	case response := <-responses:
+		return &response, nil
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("no response with ID %s within %s", key, timeout)
+	}
+}
+
+// Notify sends a notification.
+func (c *Conn) Notify(method string, params interface{}) error {
+	notification := map[string]interface{}{"jsonrpc": "2.0", "method": method}
+	if params != nil {
+		notification["params"] = params
+	}
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	return c.transport.Send(data)
+}
+
+// Messages returns the messages received from the server so far, in the order
+// they arrived.
+func (c *Conn) Messages() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Message(nil), c.messages...)
+}
+
+// Close shuts the server down and waits until it stopped.
+func (c *Conn) Close() error {
+	c.stop()
+	select {
+	case err := <-c.served:
+		return err
+	case <-time.After(DefaultTimeout):
+		return errors.New("server did not stop")
+	}
+}
+
+// receive reads the server's messages until the transport stops
+func (c *Conn) receive() {
+	for {
+		data, err := c.transport.Receive()
+		if err != nil {
+			return
+		}
+
+		var messages []Message
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+			if json.Unmarshal(trimmed, &messages) != nil {
+				continue
+			}
+		} else {
+			var message Message
+			if json.Unmarshal(data, &message) != nil {
+				continue
+			}
+			messages = append(messages, message)
+		}
+		for _, message := range messages {
+			c.handle(message)
+		}
+	}
+}
+
+// handle records a message, delivers responses to the requests waiting for them,
+// and answers requests from the server
+func (c *Conn) handle(message Message) {
+	c.mu.Lock()
+	c.messages = append(c.messages, message)
+	var waiting chan Message
+	if message.IsResponse() {
+		waiting = c.pending[idKey(message.ID)]
+	}
+	c.mu.Unlock()
+
+	if waiting != nil {
+		select {
+		case waiting <- message:
+		default:
+		}
+		return
+	}
+
+	if message.Method == "" || message.IsNotification() {
+		return
+	}
+	response := map[string]interface{}{"jsonrpc": "2.0", "id": message.ID}
+	if message.Method == "ping" {
+		response["result"] = map[string]interface{}{}
+	} else {
+		response["error"] = Error{Code: -32601, Message: "Method not found"}
+	}
+	if data, err := json.Marshal(response); err == nil {
+		_ = c.transport.Send(data)
+	}
+}
+
+// idKey returns the key of a request ID, the same for an ID and its echo
+func idKey(id json.RawMessage) string {
+	var compact bytes.Buffer
+	if json.Compact(&compact, id) != nil {
+		return string(id)
+	}
+	return compact.String()
+}
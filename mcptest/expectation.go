@@ -0,0 +1,216 @@
+package mcptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// Call is an invocation a fake received: a tool call or resource read of a
+// FakeServer, or a sampling or elicitation request to a FakeClient.
+type Call struct {
+	// Method is the request's method, such as "tools/call"
+	Method string
+
+	// Name is the name of the tool or the URI of the resource; empty for sampling
+	// and elicitation requests
+	Name string
+
+	// Args holds the tool's arguments, or the params of sampling and elicitation
+	// requests
+	Args map[string]interface{}
+
+	// Expected reports whether an expectation answered the call
+	Expected bool
+}
+
+// String describes the call, such as "tools/call add map[a:1 b:2]"
+func (c Call) String() string {
+	description := c.Method
+	if c.Name != "" {
+		description += " " + c.Name
+	}
+	if len(c.Args) > 0 {
+		description += fmt.Sprintf(" %v", c.Args)
+	}
+	return description
+}
+
+// Expectation scripts the answer to a call a fake expects. An expectation answers
+// every matching call unless Times limits it, and must be called at least once.
+// Expectations are matched in the order they were made, so a limited expectation
+// can answer the first calls and a later one the rest.
+type Expectation struct {
+	mu *sync.Mutex
+
+	method string
+	name   string
+	args   map[string]interface{}
+	result interface{}
+	err    error
+	times  int
+	calls  int
+}
+
+// WithArgs makes the expectation match only calls whose arguments include args.
+// For sampling and elicitation requests, args are compared with the request's
+// params, such as the "message" of an elicitation.
+func (e *Expectation) WithArgs(args map[string]interface{}) *Expectation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.args = normalizeArgs(args)
+	return e
+}
+
+// Return sets the result the call is answered with: the tool's result, the
+// resource's content, a client.SamplingResponse or a client.ElicitationResult.
+func (e *Expectation) Return(result interface{}) *Expectation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.result, e.err = result, nil
+	return e
+}
+
+// ReturnError makes the call fail with err. Failed tool calls reach the client as
+// results with isError set.
+func (e *Expectation) ReturnError(err error) *Expectation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.result, e.err = nil, err
+	return e
+}
+
+// Times makes the expectation answer exactly n calls.
+func (e *Expectation) Times(n int) *Expectation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.times = n
+	return e
+}
+
+// Calls returns how many calls the expectation answered.
+func (e *Expectation) Calls() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.calls
+}
+
+// matches reports whether the expectation answers a call; the caller holds mu
+func (e *Expectation) matches(method, name string, args map[string]interface{}) bool {
+	if e.method != method || e.name != name || (e.times > 0 && e.calls >= e.times) {
+		return false
+	}
+	for key, want := range e.args {
+		if got, ok := args[key]; !ok || !reflect.DeepEqual(got, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// describe describes the calls the expectation matches; the caller holds mu
+func (e *Expectation) describe() string {
+	return Call{Method: e.method, Name: e.name, Args: e.args}.String()
+}
+
+// script holds the expectations of a fake and the calls it received
+type script struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	calls        []Call
+}
+
+// expect adds an expectation of calls of a method
+func (s *script) expect(method, name string) *Expectation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expectation := &Expectation{mu: &s.mu, method: method, name: name}
+	s.expectations = append(s.expectations, expectation)
+	return expectation
+}
+
+// answer records a call and answers it with the first expectation that matches
+func (s *script) answer(method, name string, args map[string]interface{}) (interface{}, error) {
+	args = normalizeArgs(args)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	call := Call{Method: method, Name: name, Args: args}
+	for _, expectation := range s.expectations {
+		if expectation.matches(method, name, args) {
+			expectation.calls++
+			call.Expected = true
+			s.calls = append(s.calls, call)
+			return expectation.result, expectation.err
+		}
+	}
+	s.calls = append(s.calls, call)
+	return nil, fmt.Errorf("unexpected call: %s", call)
+}
+
+// Calls returns the calls the fake received, in the order it received them.
+func (s *script) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// AssertExpectations fails t for every expectation that was not called as often
+// as expected, and for every call no expectation answered.
+func (s *script) AssertExpectations(t testing.TB) {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, expectation := range s.expectations {
+		switch {
+		case expectation.times > 0 && expectation.calls != expectation.times:
+			t.Errorf("expected %s to be called %d times, was called %d times", expectation.describe(), expectation.times, expectation.calls)
+		case expectation.calls == 0:
+			t.Errorf("expected %s to be called, was not called", expectation.describe())
+		}
+	}
+	for _, call := range s.calls {
+		if !call.Expected {
+			t.Errorf("unexpected call: %s", call)
+		}
+	}
+}
+
+// normalizeArgs returns arguments as they are decoded from JSON, so that expected
+// arguments compare equal to received ones
+func normalizeArgs(args map[string]interface{}) map[string]interface{} {
+	if args == nil {
+		return nil
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return args
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return args
+	}
+	return normalized
+}
+
+// toArgs returns a value encoded as a JSON object as a map, for recording the
+// params of requests
+func toArgs(v interface{}) map[string]interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var args map[string]interface{}
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil
+	}
+	return args
+}
+
+// discardLogger keeps fakes quiet in test output
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
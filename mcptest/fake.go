@@ -0,0 +1,255 @@
+package mcptest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/localrivet/gomcp/client"
+	"github.com/localrivet/gomcp/server"
+	"github.com/localrivet/gomcp/transport/embedded"
+)
+
+// FakeServer is a server whose tools and resources answer as scripted, for unit
+// testing code that uses a client. It only has the tools and resources it expects
+// calls of, records every call, and fails the calls no expectation answers.
+//
+// Example:
+//
+//	fake := mcptest.NewFakeServer()
+//	defer fake.Close()
+//	fake.ExpectToolCall("add").WithArgs(map[string]interface{}{"a": 1, "b": 2}).Return(3)
+//
+//	c, err := fake.Client()
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	total, err := calculator.Sum(c, 1, 2)
+//	...
+//	fake.AssertExpectations(t)
+type FakeServer struct {
+	script
+
+	server    server.Server
+	tools     map[string]bool
+	resources map[string]bool
+	closer    func()
+}
+
+// NewFakeServer returns a fake server without expectations.
+func NewFakeServer() *FakeServer {
+	return &FakeServer{
+		server:    server.NewServer("fake-server", server.WithLogger(discardLogger)),
+		tools:     make(map[string]bool),
+		resources: make(map[string]bool),
+	}
+}
+
+// ExpectToolCall expects calls of the named tool, adding the tool to the server.
+// The tool accepts any arguments and returns what the expectation's Return gives
+// it, as a handler would.
+func (f *FakeServer) ExpectToolCall(name string) *Expectation {
+	f.mu.Lock()
+	if !f.tools[name] {
+		f.tools[name] = true
+		f.server.GetServer().ToolWithSchema(name, "Scripted tool", map[string]interface{}{"type": "object"},
+			func(ctx *server.Context, args map[string]interface{}) (interface{}, error) {
+				return f.answer("tools/call", name, args)
+			})
+	}
+	f.mu.Unlock()
+	return f.expect("tools/call", name)
+}
+
+// ExpectResourceRead expects reads of the resource with the given URI, adding the
+// resource to the server.
+func (f *FakeServer) ExpectResourceRead(uri string) *Expectation {
+	f.mu.Lock()
+	if !f.resources[uri] {
+		f.resources[uri] = true
+		f.server.Resource(uri, "Scripted resource", func(ctx *server.Context, args interface{}) (interface{}, error) {
+			return f.answer("resources/read", uri, nil)
+		})
+	}
+	f.mu.Unlock()
+	return f.expect("resources/read", uri)
+}
+
+// Server returns the fake's server, to add anything that needs no script.
+func (f *FakeServer) Server() server.Server {
+	return f.server
+}
+
+// Client serves the fake over the embedded transport and returns a client
+// connected to it, negotiating the draft protocol version unless options choose
+// another. A fake server can serve one client.
+func (f *FakeServer) Client(options ...client.Option) (client.Client, error) {
+	f.mu.Lock()
+	connected := f.closer != nil
+	f.mu.Unlock()
+	if connected {
+		return nil, fmt.Errorf("the fake server already serves a client")
+	}
+
+	options = append([]client.Option{client.WithLogger(discardLogger), client.WithProtocolVersion("draft")}, options...)
+	c, closer, err := serveClient(f.server, "fake-client", options)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.closer = closer
+	f.mu.Unlock()
+	return c, nil
+}
+
+// Close disconnects the client and shuts the server down.
+func (f *FakeServer) Close() {
+	f.mu.Lock()
+	closer := f.closer
+	f.closer = nil
+	f.mu.Unlock()
+	if closer != nil {
+		closer()
+	}
+}
+
+// FakeClient is a client whose answers to the requests of servers are scripted,
+// for unit testing tools that sample the client's model or elicit input from the
+// user. It records every request, and fails the ones no expectation answers.
+//
+// Example:
+//
+//	fake := mcptest.NewFakeClient()
+//	defer fake.Close()
+//	fake.ExpectElicitation().Return(client.ElicitationResult{
+//	    Action:  client.ElicitationAccept,
+//	    Content: map[string]interface{}{"branch": "main"},
+//	})
+//
+//	c, err := fake.Connect(newDeployServer())
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	result, err := c.CallTool("deploy", nil)
+//	...
+//	fake.AssertExpectations(t)
+type FakeClient struct {
+	script
+
+	closer func()
+}
+
+// NewFakeClient returns a fake client without expectations.
+func NewFakeClient() *FakeClient {
+	return &FakeClient{}
+}
+
+// ExpectSampling expects sampling/createMessage requests. Return must be given a
+// client.SamplingResponse.
+func (f *FakeClient) ExpectSampling() *Expectation {
+	return f.expect("sampling/createMessage", "")
+}
+
+// ExpectElicitation expects elicitation/create requests. Return must be given a
+// client.ElicitationResult.
+func (f *FakeClient) ExpectElicitation() *Expectation {
+	return f.expect("elicitation/create", "")
+}
+
+// Connect serves srv over the embedded transport and returns the client that
+// answers its requests as scripted. The server must not have been started. The
+// client declares the sampling and elicitation capabilities and negotiates the
+// draft protocol version unless options choose another.
+func (f *FakeClient) Connect(srv server.Server, options ...client.Option) (client.Client, error) {
+	options = append([]client.Option{
+		client.WithLogger(discardLogger),
+		client.WithProtocolVersion("draft"),
+		client.WithSamplingCapability(true, nil),
+		client.WithElicitationHandler(f.elicit),
+	}, options...)
+	c, closer, err := serveClient(srv, "fake-client", options)
+	if err != nil {
+		return nil, err
+	}
+	c.WithSamplingHandler(f.sample)
+
+	f.mu.Lock()
+	f.closer = closer
+	f.mu.Unlock()
+	return c, nil
+}
+
+// Close disconnects the client and shuts the server down.
+func (f *FakeClient) Close() {
+	f.mu.Lock()
+	closer := f.closer
+	f.closer = nil
+	f.mu.Unlock()
+	if closer != nil {
+		closer()
+	}
+}
+
+// sample answers a sampling request as scripted
+func (f *FakeClient) sample(params client.SamplingCreateMessageParams) (client.SamplingResponse, error) {
+	result, err := f.answer("sampling/createMessage", "", toArgs(params))
+	if err != nil {
+		return client.SamplingResponse{}, err
+	}
+	switch response := result.(type) {
+	case client.SamplingResponse:
+		return response, nil
+	case *client.SamplingResponse:
+		if response != nil {
+			return *response, nil
+		}
+	}
+	return client.SamplingResponse{}, fmt.Errorf("sampling expectation returns %T, want client.SamplingResponse", result)
+}
+
+// elicit answers an elicitation request as scripted
+func (f *FakeClient) elicit(params client.ElicitationCreateParams) (client.ElicitationResult, error) {
+	result, err := f.answer("elicitation/create", "", toArgs(params))
+	if err != nil {
+		return client.ElicitationResult{}, err
+	}
+	switch response := result.(type) {
+	case client.ElicitationResult:
+		return response, nil
+	case *client.ElicitationResult:
+		if response != nil {
+			return *response, nil
+		}
+	}
+	return client.ElicitationResult{}, fmt.Errorf("elicitation expectation returns %T, want client.ElicitationResult", result)
+}
+
+// serveClient serves srv over a new embedded transport and connects a client to
+// it, returning a function that disconnects the client and stops the server
+func serveClient(srv server.Server, name string, options []client.Option) (client.Client, func(), error) {
+	serverTransport, clientTransport := embedded.NewTransportPair()
+	srv.AsEmbedded(serverTransport)
+
+	ctx, stop := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(ctx)
+	}()
+	shutdown := func() {
+		stop()
+		select {
+		case <-served:
+		case <-time.After(DefaultTimeout):
+		}
+	}
+
+	c, err := client.NewClient(name, append([]client.Option{client.WithEmbedded(clientTransport)}, options...)...)
+	if err != nil {
+		shutdown()
+		return nil, nil, fmt.Errorf("failed to connect to the fake: %w", err)
+	}
+	return c, func() {
+		_ = c.Close()
+		shutdown()
+	}, nil
+}
@@ -0,0 +1,180 @@
+package mcptest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/localrivet/gomcp/client"
+	"github.com/localrivet/gomcp/server"
+)
+
+// recorder is a testing.TB that keeps the errors reported to it
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestFakeServer(t *testing.T) {
+	fake := NewFakeServer()
+	defer fake.Close()
+	fake.ExpectToolCall("add").WithArgs(map[string]interface{}{"a": 1, "b": 2}).Return(3).Times(1)
+	fake.ExpectToolCall("add").Return(0)
+	fake.ExpectToolCall("divide").ReturnError(errors.New("division by zero"))
+	fake.ExpectResourceRead("/config").Return("debug=true")
+
+	c, err := fake.Client()
+	if err != nil {
+		t.Fatalf("Failed to connect to the fake server: %v", err)
+	}
+	if _, err := fake.Client(); err == nil {
+		t.Error("Expected a second client to be refused")
+	}
+
+	add := func(a, b int) int {
+		t.Helper()
+		raw, err := c.CallTool("add", map[string]interface{}{"a": a, "b": b})
+		if err != nil {
+			t.Fatalf("Failed to call add: %v", err)
+		}
+		sum, err := client.DecodeToolResult[int](raw)
+		if err != nil {
+			t.Fatalf("Failed to decode the result of add: %v", err)
+		}
+		return sum
+	}
+	if sum := add(1, 2); sum != 3 {
+		t.Errorf("Expected the first call to return 3, got %d", sum)
+	}
+	if sum := add(1, 2); sum != 0 {
+		t.Errorf("Expected the limited expectation to be used up, got %d", sum)
+	}
+
+	raw, err := c.CallTool("divide", map[string]interface{}{"a": 1, "b": 0})
+	if err != nil {
+		t.Fatalf("Failed to call divide: %v", err)
+	}
+	var toolErr *client.ToolError
+	if _, err := client.DecodeToolResult[int](raw); !errors.As(err, &toolErr) || !strings.Contains(toolErr.Message, "division by zero") {
+		t.Errorf("Expected the scripted error, got %v", err)
+	}
+
+	resource, err := c.GetResource("/config")
+	if err != nil {
+		t.Fatalf("Failed to read the resource: %v", err)
+	}
+	if len(resource.Contents) == 0 || !strings.Contains(fmt.Sprint(resource.Contents), "debug=true") {
+		t.Errorf("Expected the scripted content, got %+v", resource.Contents)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 4 {
+		t.Fatalf("Expected 4 recorded calls, got %v", calls)
+	}
+	if got := calls[0].String(); got != "tools/call add map[a:1 b:2]" {
+		t.Errorf("Unexpected first call: %s", got)
+	}
+	if calls[3].Method != "resources/read" || calls[3].Name != "/config" || !calls[3].Expected {
+		t.Errorf("Unexpected last call: %+v", calls[3])
+	}
+	fake.AssertExpectations(t)
+}
+
+func TestFakeServerAssertExpectations(t *testing.T) {
+	fake := NewFakeServer()
+	defer fake.Close()
+	fake.ExpectToolCall("add").WithArgs(map[string]interface{}{"a": 1}).Return(1).Times(2)
+	fake.ExpectToolCall("reset")
+
+	c, err := fake.Client()
+	if err != nil {
+		t.Fatalf("Failed to connect to the fake server: %v", err)
+	}
+	if _, err := c.CallTool("add", map[string]interface{}{"a": 1}); err != nil {
+		t.Fatalf("Failed to call add: %v", err)
+	}
+	raw, err := c.CallTool("add", map[string]interface{}{"a": 2})
+	if err != nil {
+		t.Fatalf("Failed to call add: %v", err)
+	}
+	if _, err := client.DecodeToolResult[int](raw); err == nil || !strings.Contains(err.Error(), "unexpected call") {
+		t.Errorf("Expected an unexpected call to fail, got %v", err)
+	}
+
+	r := &recorder{TB: t}
+	fake.AssertExpectations(r)
+	want := []string{
+		"expected tools/call add map[a:1] to be called 2 times, was called 1 times",
+		"expected tools/call reset to be called, was not called",
+		"unexpected call: tools/call add map[a:2]",
+	}
+	if strings.Join(r.errors, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected failures:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(r.errors, "\n"))
+	}
+}
+
+func TestFakeClient(t *testing.T) {
+	s := server.NewServer("deploy-server", server.WithLogger(discardLogger))
+	s.Tool("deploy", "Deploy a branch", func(ctx *server.Context, args struct{}) (interface{}, error) {
+		result, err := ctx.Elicit(map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"branch": map[string]interface{}{"type": "string"}},
+		}, "Which branch?")
+		if err != nil {
+			return nil, err
+		}
+		if !result.Accepted() {
+			return "cancelled", nil
+		}
+		summary, err := ctx.RequestSampling([]server.SamplingMessage{
+			server.CreateTextSamplingMessage("user", fmt.Sprintf("Summarize deploying %v", result.Content["branch"])),
+		}, server.SamplingModelPreferences{}, "", 100)
+		if err != nil {
+			return nil, err
+		}
+		return summary.Content.Text, nil
+	})
+
+	fake := NewFakeClient()
+	defer fake.Close()
+	fake.ExpectElicitation().WithArgs(map[string]interface{}{"message": "Which branch?"}).Return(client.ElicitationResult{
+		Action:  client.ElicitationAccept,
+		Content: map[string]interface{}{"branch": "main"},
+	})
+	fake.ExpectSampling().Return(client.SamplingResponse{
+		Role:    "assistant",
+		Content: client.SamplingMessageContent{Type: "text", Text: "main is deployed"},
+	})
+
+	c, err := fake.Connect(s)
+	if err != nil {
+		t.Fatalf("Failed to connect the fake client: %v", err)
+	}
+	raw, err := c.CallTool("deploy", nil)
+	if err != nil {
+		t.Fatalf("Failed to call deploy: %v", err)
+	}
+	summary, err := client.DecodeToolResult[string](raw)
+	if err != nil {
+		t.Fatalf("Failed to decode the result of deploy: %v", err)
+	}
+	if summary != "main is deployed" {
+		t.Errorf("Expected the scripted summary, got %q", summary)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 2 || calls[0].Method != "elicitation/create" || calls[1].Method != "sampling/createMessage" {
+		t.Fatalf("Unexpected calls: %v", calls)
+	}
+	if !strings.Contains(fmt.Sprint(calls[1].Args["messages"]), "Summarize deploying main") {
+		t.Errorf("Expected the sampling request's messages to be recorded, got %v", calls[1].Args)
+	}
+	fake.AssertExpectations(t)
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Defaults for adaptive tool timeouts.
+const (
+	// adaptiveTimeoutWindow is how many recent latencies are kept per tool
+	adaptiveTimeoutWindow = 256
+
+	// adaptiveTimeoutMinSamples is how many calls a tool needs before its p99 is trusted
+	adaptiveTimeoutMinSamples = 20
+
+	// defaultAdaptiveTimeoutFloor keeps fast tools from getting unrealistically tight deadlines
+	defaultAdaptiveTimeoutFloor = time.Second
+)
+
+// adaptiveTimeouts derives per-tool call deadlines from observed latencies.
+type adaptiveTimeouts struct {
+	multiplier float64
+	floor      time.Duration
+	ceiling    time.Duration
+
+	mu      sync.Mutex
+	history map[string]*latencyWindow
+}
+
+// latencyWindow is a ring buffer of a tool's most recent successful call latencies.
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+// WithAdaptiveTimeouts gives every tool call a deadline of multiplier times the
+// tool's observed p99 latency, instead of relying on a single global timeout.
+//
+// Latencies of the last 256 successful calls are tracked per tool. Until a tool
+// has 20 samples it runs under the ceiling set by WithAdaptiveTimeoutBounds, or
+// without a deadline if no ceiling is set. Computed deadlines never drop below
+// the floor (one second by default). When a deadline passes, the handler's
+// context is cancelled and the call fails with a timeout error.
+//
+// Example:
+//
+//	server.NewServer("my-server",
+//	    server.WithAdaptiveTimeouts(3),
+//	    server.WithAdaptiveTimeoutBounds(500*time.Millisecond, 2*time.Minute),
+//	)
+func WithAdaptiveTimeouts(multiplier float64) Option {
+	return func(s *serverImpl) {
+		if multiplier <= 0 {
+			s.adaptiveTimeouts = nil
+			return
+		}
+		if s.adaptiveTimeouts == nil {
+			s.adaptiveTimeouts = &adaptiveTimeouts{
+				floor:   defaultAdaptiveTimeoutFloor,
+				history: make(map[string]*latencyWindow),
+			}
+		}
+		s.adaptiveTimeouts.multiplier = multiplier
+	}
+}
+
+// WithAdaptiveTimeoutBounds clamps adaptive tool deadlines between floor and ceiling.
+// The ceiling also applies to tools without enough latency history; zero means no ceiling.
+// It has no effect unless WithAdaptiveTimeouts is also given, in either order.
+func WithAdaptiveTimeoutBounds(floor, ceiling time.Duration) Option {
+	return func(s *serverImpl) {
+		if s.adaptiveTimeouts == nil {
+			s.adaptiveTimeouts = &adaptiveTimeouts{
+				history: make(map[string]*latencyWindow),
+			}
+		}
+		s.adaptiveTimeouts.floor = floor
+		s.adaptiveTimeouts.ceiling = ceiling
+	}
+}
+
+// observe records the latency of a successful call.
+func (at *adaptiveTimeouts) observe(tool string, latency time.Duration) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+
+	window, exists := at.history[tool]
+	if !exists {
+		window = &latencyWindow{samples: make([]time.Duration, 0, adaptiveTimeoutWindow)}
+		at.history[tool] = window
+	}
+
+	if len(window.samples) < adaptiveTimeoutWindow {
+		window.samples = append(window.samples, latency)
+		return
+	}
+	window.samples[window.next] = latency
+	window.next = (window.next + 1) % adaptiveTimeoutWindow
+}
+
+// timeoutFor returns the deadline for the next call of a tool, or zero for none.
+func (at *adaptiveTimeouts) timeoutFor(tool string) time.Duration {
+	// Options may have set bounds without enabling adaptive timeouts
+	if at.multiplier <= 0 {
+		return 0
+	}
+
+	at.mu.Lock()
+	window, exists := at.history[tool]
+	var sorted []time.Duration
+	if exists && len(window.samples) >= adaptiveTimeoutMinSamples {
+		sorted = append(sorted, window.samples...)
+	}
+	at.mu.Unlock()
+
+	if sorted == nil {
+		return at.ceiling
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	timeout := time.Duration(float64(percentile(sorted, 99)) * at.multiplier)
+
+	if timeout < at.floor {
+		timeout = at.floor
+	}
+	if at.ceiling > 0 && timeout > at.ceiling {
+		timeout = at.ceiling
+	}
+
+	return timeout
+}
+
+// toolTimeout returns the adaptive deadline for a tool call, or zero if there is none.
+func (s *serverImpl) toolTimeout(tool string) time.Duration {
+	if s.adaptiveTimeouts == nil {
+		return 0
+	}
+	return s.adaptiveTimeouts.timeoutFor(tool)
+}
+
+// observeToolLatency feeds a successful call's latency into the adaptive timeouts.
+func (s *serverImpl) observeToolLatency(tool string, latency time.Duration) {
+	if s.adaptiveTimeouts != nil {
+		s.adaptiveTimeouts.observe(tool, latency)
+	}
+}
@@ -0,0 +1,288 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// UsageResourceURI is the URI of the resource exposing the usage report
+// when usage analytics are enabled.
+const UsageResourceURI = "analytics://usage"
+
+// maxLatencySamples bounds the latency samples kept per tool per bucket.
+// Beyond this, samples are replaced by reservoir sampling.
+const maxLatencySamples = 1024
+
+// UsageReport summarizes tool usage over the analytics window.
+type UsageReport struct {
+	// GeneratedAt is when the report was produced
+	GeneratedAt time.Time `json:"generatedAt"`
+
+	// WindowStart and WindowEnd bound the period covered by the report
+	WindowStart time.Time `json:"windowStart"`
+	WindowEnd   time.Time `json:"windowEnd"`
+
+	// Tools holds per-tool statistics, sorted by tool name
+	Tools []ToolUsage `json:"tools"`
+}
+
+// ToolUsage holds the usage statistics of a single tool.
+type ToolUsage struct {
+	Name           string        `json:"name"`
+	Calls          int64         `json:"calls"`
+	Errors         int64         `json:"errors"`
+	ErrorRate      float64       `json:"errorRate"`
+	P50Latency     time.Duration `json:"p50LatencyNs"`
+	P95Latency     time.Duration `json:"p95LatencyNs"`
+	LastCalled     time.Time     `json:"lastCalled"`
+	UniqueSessions int           `json:"uniqueSessions"`
+}
+
+// usageTracker aggregates tool calls into a ring of fixed-width time buckets.
+// Old buckets are reused as time moves on, so memory stays bounded and the
+// report always covers the most recent bucketWidth*len(buckets).
+type usageTracker struct {
+	mu          sync.Mutex
+	bucketWidth time.Duration
+	buckets     []usageBucket
+}
+
+// usageBucket holds the statistics recorded during one bucket period.
+type usageBucket struct {
+	start time.Time
+	tools map[string]*toolBucketStats
+}
+
+// toolBucketStats holds one tool's statistics within a bucket.
+type toolBucketStats struct {
+	calls      int64
+	errors     int64
+	latencies  []time.Duration
+	lastCalled time.Time
+	sessions   map[string]struct{}
+}
+
+// WithUsageAnalytics enables per-tool usage analytics.
+//
+// Calls are aggregated into the given number of buckets of bucketWidth each,
+// so the report covers the last bucketWidth*buckets. The report is available via
+// UsageReport() and as the analytics://usage resource.
+//
+// Example:
+//
+//	server.NewServer("my-server", server.WithUsageAnalytics(time.Minute, 60)) // last hour
+func WithUsageAnalytics(bucketWidth time.Duration, buckets int) Option {
+	return func(s *serverImpl) {
+		if bucketWidth <= 0 || buckets <= 0 {
+			s.usage = nil
+			return
+		}
+		s.usage = newUsageTracker(bucketWidth, buckets)
+	}
+}
+
+// newUsageTracker creates an empty usage tracker.
+func newUsageTracker(bucketWidth time.Duration, buckets int) *usageTracker {
+	return &usageTracker{
+		bucketWidth: bucketWidth,
+		buckets:     make([]usageBucket, buckets),
+	}
+}
+
+// bucketFor returns the bucket for time t, resetting it if it holds an older period.
+// The caller must hold ut.mu.
+func (ut *usageTracker) bucketFor(t time.Time) *usageBucket {
+	start := t.Truncate(ut.bucketWidth)
+	index := int((start.UnixNano() / int64(ut.bucketWidth)) % int64(len(ut.buckets)))
+	bucket := &ut.buckets[index]
+	if !bucket.start.Equal(start) {
+		bucket.start = start
+		bucket.tools = make(map[string]*toolBucketStats)
+	}
+	return bucket
+}
+
+// record adds a completed tool call to the current bucket.
+func (ut *usageTracker) record(tool, sessionID string, latency time.Duration, failed bool) {
+	now := time.Now()
+
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+
+	bucket := ut.bucketFor(now)
+	stats, exists := bucket.tools[tool]
+	if !exists {
+		stats = &toolBucketStats{sessions: make(map[string]struct{})}
+		bucket.tools[tool] = stats
+	}
+
+	stats.calls++
+	if failed {
+		stats.errors++
+	}
+	stats.lastCalled = now
+	if sessionID != "" {
+		stats.sessions[sessionID] = struct{}{}
+	}
+
+	if len(stats.latencies) < maxLatencySamples {
+		stats.latencies = append(stats.latencies, latency)
+	} else if i := rand.Int63n(stats.calls); i < maxLatencySamples {
+		stats.latencies[i] = latency
+	}
+}
+
+// report merges the buckets inside the current window into a UsageReport.
+func (ut *usageTracker) report() UsageReport {
+	now := time.Now()
+
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+
+	windowEnd := now.Truncate(ut.bucketWidth).Add(ut.bucketWidth)
+	windowStart := windowEnd.Add(-ut.bucketWidth * time.Duration(len(ut.buckets)))
+
+	type aggregate struct {
+		usage     ToolUsage
+		latencies []time.Duration
+		sessions  map[string]struct{}
+	}
+	merged := make(map[string]*aggregate)
+
+	for i := range ut.buckets {
+		bucket := &ut.buckets[i]
+		if bucket.tools == nil || bucket.start.Before(windowStart) {
+			continue
+		}
+
+		for name, stats := range bucket.tools {
+			agg, exists := merged[name]
+			if !exists {
+				agg = &aggregate{usage: ToolUsage{Name: name}, sessions: make(map[string]struct{})}
+				merged[name] = agg
+			}
+			agg.usage.Calls += stats.calls
+			agg.usage.Errors += stats.errors
+			if stats.lastCalled.After(agg.usage.LastCalled) {
+				agg.usage.LastCalled = stats.lastCalled
+			}
+			agg.latencies = append(agg.latencies, stats.latencies...)
+			for session := range stats.sessions {
+				agg.sessions[session] = struct{}{}
+			}
+		}
+	}
+
+	report := UsageReport{
+		GeneratedAt: now,
+		WindowStart: windowStart,
+		WindowEnd:   windowEnd,
+		Tools:       make([]ToolUsage, 0, len(merged)),
+	}
+
+	for _, agg := range merged {
+		usage := agg.usage
+		if usage.Calls > 0 {
+			usage.ErrorRate = float64(usage.Errors) / float64(usage.Calls)
+		}
+		sort.Slice(agg.latencies, func(i, j int) bool { return agg.latencies[i] < agg.latencies[j] })
+		usage.P50Latency = percentile(agg.latencies, 50)
+		usage.P95Latency = percentile(agg.latencies, 95)
+		usage.UniqueSessions = len(agg.sessions)
+		report.Tools = append(report.Tools, usage)
+	}
+
+	sort.Slice(report.Tools, func(i, j int) bool { return report.Tools[i].Name < report.Tools[j].Name })
+
+	return report
+}
+
+// reset discards all recorded statistics.
+func (ut *usageTracker) reset() {
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+
+	for i := range ut.buckets {
+		ut.buckets[i] = usageBucket{}
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted samples.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// UsageReport returns per-tool usage statistics (call counts, latency percentiles,
+// error rate, last call and unique sessions) for the analytics window.
+//
+// Usage analytics must be enabled with WithUsageAnalytics; otherwise the report is empty.
+// The same report is served as the analytics://usage resource.
+//
+// Example:
+//
+//	for _, tool := range server.UsageReport().Tools {
+//	    fmt.Printf("%s: %d calls, p95 %s\n", tool.Name, tool.Calls, tool.P95Latency)
+//	}
+func (s *serverImpl) UsageReport() UsageReport {
+	if s.usage == nil {
+		now := time.Now()
+		return UsageReport{GeneratedAt: now, WindowStart: now, WindowEnd: now, Tools: []ToolUsage{}}
+	}
+	return s.usage.report()
+}
+
+// ResetUsage discards all recorded usage statistics.
+func (s *serverImpl) ResetUsage() {
+	if s.usage != nil {
+		s.usage.reset()
+	}
+}
+
+// ExportUsage writes the current usage report to w as JSON.
+func (s *serverImpl) ExportUsage(w io.Writer) error {
+	if err := json.NewEncoder(w).Encode(s.UsageReport()); err != nil {
+		return fmt.Errorf("failed to export usage report: %w", err)
+	}
+	return nil
+}
+
+// recordToolUsage records a finished tool call if usage analytics are enabled.
+func (s *serverImpl) recordToolUsage(ctx *Context, tool string, latency time.Duration, failed bool) {
+	if s.usage == nil {
+		return
+	}
+
+	var sessionID string
+	if ctx != nil {
+		if ctx.Session != nil {
+			sessionID = string(ctx.Session.ID)
+		} else if id, ok := ctx.Metadata["sessionID"].(string); ok {
+			sessionID = id
+		}
+	}
+
+	s.usage.record(tool, sessionID, latency, failed)
+}
+
+// registerUsageResource exposes the usage report as the analytics://usage resource.
+func (s *serverImpl) registerUsageResource() {
+	s.Resource(UsageResourceURI, "Per-tool usage statistics", func(ctx *Context, args interface{}) (interface{}, error) {
+		data, err := json.Marshal(s.UsageReport())
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode usage report: %w", err)
+		}
+		return string(data), nil
+	})
+}
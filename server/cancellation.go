@@ -1,13 +1,19 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 
 	"github.com/localrivet/gomcp/mcp"
 )
 
+// ErrRequestCancelled is the cause of a request context cancelled by notifications/cancelled.
+var ErrRequestCancelled = errors.New("request cancelled")
+
 // CancelledNotificationParams contains parameters for a cancelled notification
 type CancelledNotificationParams struct {
 	RequestID string `json:"requestId"`        // ID of the request being cancelled
@@ -17,27 +23,53 @@ type CancelledNotificationParams struct {
 // RequestCanceller manages cancellable requests and handles cancellation notifications
 type RequestCanceller struct {
 	mu            sync.RWMutex
-	cancellations map[interface{}]chan struct{} // Maps request IDs to cancellation channels
+	cancellations map[interface{}]chan struct{}           // Maps request IDs to cancellation channels
+	contexts      map[interface{}]context.CancelCauseFunc // Maps request IDs to their contexts' cancel functions
 }
 
 // NewRequestCanceller creates a new request canceller
 func NewRequestCanceller() *RequestCanceller {
 	return &RequestCanceller{
 		cancellations: make(map[interface{}]chan struct{}),
+		contexts:      make(map[interface{}]context.CancelCauseFunc),
 	}
 }
 
-// Register registers a request as cancellable and returns a channel that will be closed on cancellation
+// Register registers a request as cancellable and returns a channel that will be closed on cancellation.
+// Registering a request that is already registered returns its existing channel.
 func (rc *RequestCanceller) Register(requestID interface{}) <-chan struct{} {
 	rc.mu.Lock()
 	defer rc.mu.Unlock()
 
+	if cancelCh, exists := rc.cancellations[requestID]; exists {
+		return cancelCh
+	}
+
 	// Create a cancellation channel for this request
 	cancelCh := make(chan struct{})
 	rc.cancellations[requestID] = cancelCh
 	return cancelCh
 }
 
+// WithCancellation registers a request and returns a context derived from parent that
+// is cancelled, with ErrRequestCancelled as its cause, when the request is cancelled.
+// The returned release function must be called once the request completes.
+func (rc *RequestCanceller) WithCancellation(parent context.Context, requestID interface{}) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+	rc.Register(requestID)
+
+	rc.mu.Lock()
+	rc.contexts[requestID] = cancel
+	rc.mu.Unlock()
+
+	return ctx, func() {
+		// Deregister first so that the context's cause is ErrRequestCancelled only if
+		// the request really was cancelled
+		rc.Deregister(requestID)
+		cancel(nil)
+	}
+}
+
 // Cancel cancels a request by closing its cancellation channel
 // Returns true if the request was found and cancelled, false otherwise
 func (rc *RequestCanceller) Cancel(requestID interface{}, reason string) bool {
@@ -49,6 +81,13 @@ func (rc *RequestCanceller) Cancel(requestID interface{}, reason string) bool {
 		return false
 	}
 
+	// Cancel the request's context before signalling the channel, so that anything
+	// woken by the channel observes the cancellation cause
+	if cancel, ok := rc.contexts[requestID]; ok {
+		cancel(ErrRequestCancelled)
+		delete(rc.contexts, requestID)
+	}
+
 	// Close the cancellation channel to signal cancellation in a safe way
 	func() {
 		defer func() {
@@ -69,6 +108,8 @@ func (rc *RequestCanceller) Deregister(requestID interface{}) {
 	rc.mu.Lock()
 	defer rc.mu.Unlock()
 
+	delete(rc.contexts, requestID)
+
 	// Check if the channel exists
 	cancelCh, exists := rc.cancellations[requestID]
 	if !exists {
@@ -110,11 +151,20 @@ func (rc *RequestCanceller) IsCancelled(requestID interface{}) bool {
 
 // HandleCancelledNotification processes a notifications/cancelled notification
 func (s *serverImpl) HandleCancelledNotification(message []byte) error {
-	// Parse the notification
+	return s.handleCancelledNotification(s.defaultSession, message)
+}
+
+// handleCancelledNotification cancels the request of the given session named by a
+// notifications/cancelled notification
+func (s *serverImpl) handleCancelledNotification(session *ClientSession, message []byte) error {
+	// Parse the notification; request IDs may be strings or numbers
 	var notification struct {
-		JSONRPC string                      `json:"jsonrpc"`
-		Method  string                      `json:"method"`
-		Params  CancelledNotificationParams `json:"params"`
+		JSONRPC string `json:"jsonrpc"`
+		Method  string `json:"method"`
+		Params  struct {
+			RequestID interface{} `json:"requestId"`
+			Reason    string      `json:"reason,omitempty"`
+		} `json:"params"`
 	}
 
 	if err := json.Unmarshal(message, &notification); err != nil {
@@ -122,7 +172,7 @@ func (s *serverImpl) HandleCancelledNotification(message []byte) error {
 	}
 
 	// Extract the request ID
-	requestID := notification.Params.RequestID
+	requestID := stringify(notification.Params.RequestID)
 	reason := notification.Params.Reason
 
 	// Cancel the request
@@ -130,7 +180,7 @@ func (s *serverImpl) HandleCancelledNotification(message []byte) error {
 		return fmt.Errorf("invalid request ID in cancelled notification")
 	}
 
-	if cancelled := s.requestCanceller.Cancel(requestID, reason); cancelled {
+	if cancelled := s.requestCanceller.Cancel(cancellationKey(session, requestID), reason); cancelled {
 		s.logger.Info("request cancelled", "requestId", requestID, "reason", reason)
 	} else {
 		s.logger.Debug("cancellation requested for unknown request", "requestId", requestID)
@@ -139,7 +189,9 @@ func (s *serverImpl) HandleCancelledNotification(message []byte) error {
 	return nil
 }
 
-// SendCancelledNotification sends a notifications/cancelled notification
+// SendCancelledNotification sends a notifications/cancelled notification. It goes to
+// the session the cancelled request was sent to, if the server sent it, and to all
+// clients otherwise.
 func (s *serverImpl) SendCancelledNotification(requestID string, reason string) error {
 	// Create the notification parameters
 	params := CancelledNotificationParams{
@@ -156,9 +208,16 @@ func (s *serverImpl) SendCancelledNotification(requestID string, reason string)
 		return fmt.Errorf("failed to marshal cancelled notification: %w", err)
 	}
 
-	// Send the notification
+	// Send the notification to the session of the request, if known
+	var sessionID SessionID
+	s.mu.RLock()
+	tracker := s.requestTracker
+	s.mu.RUnlock()
+	if id, err := strconv.Atoi(requestID); err == nil && tracker != nil {
+		sessionID, _ = tracker.sessions.Lookup(id)
+	}
 	if s.transport != nil {
-		if err := s.transport.Send(message); err != nil {
+		if err := s.sendToSession(sessionID, message); err != nil {
 			return fmt.Errorf("failed to send cancelled notification: %w", err)
 		}
 	} else {
@@ -180,3 +239,12 @@ func (s *serverImpl) CancelRequestWithError(requestID string, reason string) err
 
 	return fmt.Errorf("request cancelled: %s", reason)
 }
+
+// cancellationKey identifies a request in the RequestCanceller. Request IDs are only
+// unique per client, so they are qualified with the session that sent the request.
+func cancellationKey(session *ClientSession, requestID string) string {
+	if session == nil {
+		return requestID
+	}
+	return string(session.ID) + "/" + requestID
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelledNotificationCancelsHandlerContext(t *testing.T) {
+	s := NewServer("cancellation-test").GetServer()
+	s.SetTransport(&recordingTransport{})
+
+	started := make(chan struct{}, 1)
+	handlerErr := make(chan error, 1)
+	s.Tool("wait", "Blocks until its request is cancelled", func(ctx *Context, args struct{}) (interface{}, error) {
+		started <- struct{}{}
+		select {
+		case <-ctx.Context().Done():
+			handlerErr <- context.Cause(ctx.Context())
+			return nil, ctx.Context().Err()
+		case <-time.After(2 * time.Second):
+			handlerErr <- nil
+			return "not cancelled", nil
+		}
+	})
+
+	type result struct {
+		response []byte
+		err      error
+	}
+	called := make(chan result, 1)
+	go func() {
+		response, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"wait","arguments":{}}}`))
+		called <- result{response, err}
+	}()
+	<-started
+
+	// Numeric request IDs match the request they cancel
+	response, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"user aborted"}}`))
+	require.NoError(t, err)
+	assert.Nil(t, response)
+
+	select {
+	case cause := <-handlerErr:
+		assert.True(t, errors.Is(cause, ErrRequestCancelled), "handler context should be cancelled by the notification, got %v", cause)
+	case <-time.After(time.Second):
+		t.Fatal("handler context was not cancelled")
+	}
+
+	// The cancelled request gets no response
+	select {
+	case r := <-called:
+		assert.NoError(t, r.err)
+		assert.Nil(t, r.response)
+	case <-time.After(time.Second):
+		t.Fatal("cancelled request did not return")
+	}
+}
+
+func TestRequestCancellerWithCancellation(t *testing.T) {
+	rc := NewRequestCanceller()
+
+	ctx, release := rc.WithCancellation(context.Background(), "s/1")
+	assert.True(t, rc.Cancel("s/1", "test"))
+	<-ctx.Done()
+	assert.ErrorIs(t, context.Cause(ctx), ErrRequestCancelled)
+	release()
+
+	// Completed requests are released without being reported as cancelled
+	ctx, release = rc.WithCancellation(context.Background(), "s/2")
+	release()
+	assert.NotErrorIs(t, context.Cause(ctx), ErrRequestCancelled)
+	assert.False(t, rc.Cancel("s/2", "too late"))
+}
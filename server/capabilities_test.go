@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitializeCapabilitiesParseAsDeclared(t *testing.T) {
+	s := NewServer("test-server").AsHTTP("localhost:0").(*serverImpl)
+	s.Tool("echo", "Echo the input", func(ctx *Context, args struct{}) (string, error) {
+		return "ok", nil
+	})
+
+	request := []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}`)
+	ctx, err := NewContext(context.Background(), request, s)
+	require.NoError(t, err)
+	result, err := s.ProcessInitialize(ctx)
+	require.NoError(t, err)
+
+	// The client parses the response into the same type the server marshals it from
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	var response struct {
+		Capabilities mcp.ServerCapabilities `json:"capabilities"`
+	}
+	require.NoError(t, json.Unmarshal(data, &response))
+
+	assert.Equal(t, s.serverCapabilities(), response.Capabilities)
+	assert.NotNil(t, response.Capabilities.Logging)
+	assert.True(t, response.Capabilities.Tools != nil && response.Capabilities.Tools.ListChanged)
+	assert.Nil(t, response.Capabilities.Prompts)
+	assert.Nil(t, response.Capabilities.Resources)
+	assert.Contains(t, string(data), `"logging":{}`)
+}
+
+func TestClientSupportsRoots(t *testing.T) {
+	assert.True(t, clientSupportsRoots(json.RawMessage(`{"capabilities":{"roots":{"listChanged":true}}}`)))
+	assert.True(t, clientSupportsRoots(map[string]interface{}{
+		"capabilities": map[string]interface{}{"roots": map[string]interface{}{"listChanged": true}},
+	}))
+	assert.False(t, clientSupportsRoots(json.RawMessage(`{"capabilities":{"roots":{"listChanged":false}}}`)))
+	assert.False(t, clientSupportsRoots(json.RawMessage(`{"capabilities":{}}`)))
+	assert.True(t, clientSupportsRoots(json.RawMessage(`{"capabilities":{"sampling":true,"roots":{"listChanged":true}}}`)))
+	assert.False(t, clientSupportsRoots(nil))
+}
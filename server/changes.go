@@ -0,0 +1,58 @@
+package server
+
+// changeKinds are the lists clients are notified about, in the order batched
+// notifications are sent
+var changeKinds = []string{"tools", "resources", "prompts"}
+
+// BatchChanges makes the registrations and removals fn makes count as one
+// change: clients are sent a single list_changed notification for each list
+// that changed once fn returns, rather than one per tool, resource or prompt.
+// Batches may be nested, and changes made concurrently by other goroutines while
+// a batch is in progress are coalesced into it.
+//
+// Example:
+//
+//	server.BatchChanges(func(srv server.Server) {
+//	    srv.GetServer().RemoveTool("search_v1")
+//	    srv.Tool("search", "Search the index", search)
+//	})
+func (s *serverImpl) BatchChanges(fn func(srv Server)) Server {
+	s.changesMu.Lock()
+	s.changeBatches++
+	s.changesMu.Unlock()
+
+	defer func() {
+		s.changesMu.Lock()
+		s.changeBatches--
+		var changed map[string]bool
+		if s.changeBatches == 0 {
+			changed, s.batchedChanges = s.batchedChanges, nil
+		}
+		s.changesMu.Unlock()
+
+		for _, kind := range changeKinds {
+			if changed[kind] {
+				s.sendCapabilityNotification(kind)
+			}
+		}
+	}()
+
+	fn(s)
+	return s
+}
+
+// deferChange records a list change while a batch is in progress, reporting
+// whether its notification is deferred to the end of the batch
+func (s *serverImpl) deferChange(kind string) bool {
+	s.changesMu.Lock()
+	defer s.changesMu.Unlock()
+
+	if s.changeBatches == 0 {
+		return false
+	}
+	if s.batchedChanges == nil {
+		s.batchedChanges = make(map[string]bool)
+	}
+	s.batchedChanges[kind] = true
+	return true
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+)
+
+// Defaults for CleanupConfig fields left at zero
+const (
+	DefaultCleanupInterval  = time.Minute
+	DefaultProgressTokenTTL = 10 * time.Minute
+	DefaultRequestTTL       = 10 * time.Minute
+)
+
+// CleanupConfig configures the background cleanup that reclaims state the server
+// would otherwise keep forever. Zero fields use the defaults above.
+type CleanupConfig struct {
+	// Interval is how often cleanup runs. A negative interval disables cleanup.
+	Interval time.Duration
+
+	// ProgressTokenTTL removes progress tokens that have not been updated for this
+	// long, along with their rate limiters. Tokens are normally released when their
+	// request ends; this reclaims those created outside of a request.
+	ProgressTokenTTL time.Duration
+
+	// RequestTTL drops server-initiated requests, such as roots/list, that have
+	// waited this long for a response without a timeout of their own.
+	RequestTTL time.Duration
+}
+
+// WithCleanup configures the background cleanup, which runs every minute by
+// default once the server is serving. Each run removes expired progress tokens
+// and rate limiters, sessions idle for longer than the TTL set with
+// WithSessionTTL along with the state kept for them, and stale server-initiated
+// requests.
+//
+// What each run reclaims is counted in CleanupReclaimed and published on
+// events.TopicCleanupCompleted.
+//
+// Example:
+//
+//	server.NewServer("my-server",
+//	    server.WithSessionTTL(30*time.Minute),
+//	    server.WithCleanup(server.CleanupConfig{Interval: 5 * time.Minute}),
+//	)
+func WithCleanup(config CleanupConfig) Option {
+	return func(s *serverImpl) {
+		if config.Interval < 0 {
+			s.cleaner = nil
+			return
+		}
+		s.cleaner = newCleaner(config)
+	}
+}
+
+// cleaner runs the background cleanup
+type cleaner struct {
+	config    CleanupConfig
+	startOnce sync.Once
+
+	mu        sync.Mutex
+	reclaimed map[string]int64
+}
+
+// newCleaner creates a cleaner, filling in defaults; its loop starts with the server
+func newCleaner(config CleanupConfig) *cleaner {
+	if config.Interval == 0 {
+		config.Interval = DefaultCleanupInterval
+	}
+	if config.ProgressTokenTTL <= 0 {
+		config.ProgressTokenTTL = DefaultProgressTokenTTL
+	}
+	if config.RequestTTL <= 0 {
+		config.RequestTTL = DefaultRequestTTL
+	}
+	return &cleaner{
+		config:    config,
+		reclaimed: make(map[string]int64),
+	}
+}
+
+// start launches the cleanup loop, which runs until the server shuts down
+func (c *cleaner) start(s *serverImpl) {
+	c.startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(c.config.Interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case now := <-ticker.C:
+					s.cleanup(now)
+				case <-s.done:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// sessionSweeper is implemented by session stores that expire idle sessions, such
+// as MemorySessionStore
+type sessionSweeper interface {
+	Sweep() int
+}
+
+// cleanup reclaims expired state once and returns what it removed, by kind
+func (s *serverImpl) cleanup(now time.Time) events.CleanupCompletedEvent {
+	evt := events.CleanupCompletedEvent{ObservedAt: now}
+	c := s.cleaner
+	if c == nil {
+		return evt
+	}
+
+	evt.ProgressTokens = s.progressTokenManager.CleanupExpiredTokens(c.config.ProgressTokenTTL)
+	if s.progressNotificationHandler != nil {
+		evt.RateLimiters = s.progressNotificationHandler.CleanupRateLimiters()
+	}
+
+	if sweeper, ok := s.sessionManager.Store().(sessionSweeper); ok {
+		// The default session stands for the client of single-client transports, which
+		// is connected for as long as the server runs; looking it up keeps it alive
+		s.mu.RLock()
+		defaultSession := s.defaultSession
+		s.mu.RUnlock()
+		if defaultSession != nil {
+			s.sessionManager.GetSession(defaultSession.ID)
+		}
+		evt.Sessions = sweeper.Sweep()
+	}
+	s.forgetClosedSessions()
+
+	s.mu.RLock()
+	tracker := s.requestTracker
+	s.mu.RUnlock()
+	if tracker != nil {
+		evt.Requests = tracker.removeStale(c.config.RequestTTL, now)
+	}
+
+	if evt.ProgressTokens+evt.RateLimiters+evt.Sessions+evt.Requests == 0 {
+		return evt
+	}
+
+	c.mu.Lock()
+	c.reclaimed["progressTokens"] += int64(evt.ProgressTokens)
+	c.reclaimed["rateLimiters"] += int64(evt.RateLimiters)
+	c.reclaimed["sessions"] += int64(evt.Sessions)
+	c.reclaimed["requests"] += int64(evt.Requests)
+	c.mu.Unlock()
+
+	s.logger.Debug("cleanup reclaimed expired state",
+		"progressTokens", evt.ProgressTokens,
+		"rateLimiters", evt.RateLimiters,
+		"sessions", evt.Sessions,
+		"requests", evt.Requests)
+	if s.events != nil {
+		go events.Publish[events.CleanupCompletedEvent](s.events, events.TopicCleanupCompleted, evt)
+	}
+	return evt
+}
+
+// forgetClosedSessions drops the state kept for sessions that no longer exist, such
+// as sessions the store expired. Sessions are listed rather than looked up one by
+// one, since a lookup counts as activity and would keep them from expiring.
+func (s *serverImpl) forgetClosedSessions() {
+	sessions, err := s.sessionManager.ListSessions()
+	if err != nil {
+		s.logger.Warn("failed to list sessions for cleanup", "error", err)
+		return
+	}
+	open := make(map[SessionID]bool, len(sessions))
+	for _, session := range sessions {
+		open[session.ID] = true
+	}
+
+	s.logLevelMu.Lock()
+	defer s.logLevelMu.Unlock()
+	for id := range s.logLevels {
+		if !open[id] {
+			delete(s.logLevels, id)
+		}
+	}
+}
+
+// CleanupReclaimed returns how many items the background cleanup has reclaimed,
+// by kind: "progressTokens", "rateLimiters", "sessions" and "requests". See
+// WithCleanup.
+func (s *serverImpl) CleanupReclaimed() map[string]int64 {
+	counts := make(map[string]int64)
+	c := s.cleaner
+	if c == nil {
+		return counts
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for kind, count := range c.reclaimed {
+		counts[kind] = count
+	}
+	return counts
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanupReclaimsExpiredState(t *testing.T) {
+	const ttl = 20 * time.Millisecond
+	s := NewServer("cleanup-test",
+		WithSessionTTL(ttl),
+		WithCleanup(CleanupConfig{ProgressTokenTTL: ttl, RequestTTL: time.Minute}),
+	).GetServer()
+	s.SetTransport(&recordingTransport{})
+	s.requestTracker = newRequestTracker(s.requestSessions)
+
+	cleaned := make(chan events.CleanupCompletedEvent, 2)
+	events.Subscribe[events.CleanupCompletedEvent](s.Events(), events.TopicCleanupCompleted,
+		func(ctx context.Context, evt events.CleanupCompletedEvent) error {
+			cleaned <- evt
+			return nil
+		})
+
+	// A session that sets a log level and goes quiet
+	_, err := s.handleSessionMessage("idle", []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`))
+	require.NoError(t, err)
+	_, err = s.handleSessionMessage("idle", []byte(`{"jsonrpc":"2.0","id":2,"method":"logging/setLevel","params":{"level":"debug"}}`))
+	require.NoError(t, err)
+	require.Len(t, s.logLevels, 1)
+
+	// A progress token created outside of a request, which nothing releases
+	token := s.CreateProgressToken("background")
+	require.NoError(t, s.SendProgressNotification(token, 1, nil, "working"))
+	require.NoError(t, s.progressTokenManager.DeactivateToken(token))
+
+	// A server request that was never answered, and one with a timeout of its own
+	s.requestTracker.addRequest(1, "idle")
+	s.requestTracker.addRequest(2, "idle")
+	s.requestTracker.setupTimeout(2, time.Hour)
+	defer s.requestTracker.removeRequest(2)
+
+	// Nothing has expired yet
+	now := time.Now()
+	evt := s.cleanup(now)
+	assert.Zero(t, evt.ProgressTokens+evt.Sessions+evt.Requests)
+	assert.Equal(t, 1, evt.RateLimiters, "the inactive token's rate limiter")
+
+	time.Sleep(2 * ttl)
+	evt = s.cleanup(now.Add(2 * time.Minute))
+	assert.Equal(t, 1, evt.ProgressTokens)
+	assert.Equal(t, 1, evt.Sessions)
+	assert.Equal(t, 1, evt.Requests)
+
+	assert.Equal(t, 0, s.progressTokenManager.Len())
+	_, exists := s.sessionManager.GetSession("idle")
+	assert.False(t, exists)
+	assert.Empty(t, s.logLevels)
+	assert.Len(t, s.requestTracker.requests, 1)
+	_, bound := s.requestTracker.sessions.Lookup(1)
+	assert.False(t, bound)
+
+	assert.Equal(t, map[string]int64{
+		"progressTokens": 1,
+		"rateLimiters":   1,
+		"sessions":       1,
+		"requests":       1,
+	}, s.CleanupReclaimed())
+
+	// Runs that reclaim something are published
+	for i := 0; i < 2; i++ {
+		select {
+		case <-cleaned:
+		case <-time.After(time.Second):
+			t.Fatal("expected a cleanup event for each run")
+		}
+	}
+}
+
+func TestCleanupRunsWhileServing(t *testing.T) {
+	s := NewServer("cleanup-test",
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		WithCleanup(CleanupConfig{Interval: 10 * time.Millisecond, ProgressTokenTTL: time.Millisecond}),
+	).GetServer()
+	s.SetTransport(&recordingTransport{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(ctx) }()
+	defer func() {
+		cancel()
+		<-served
+	}()
+
+	s.progressTokenManager.GenerateTokenForVersion("background", "draft")
+	require.Eventually(t, func() bool {
+		return s.CleanupReclaimed()["progressTokens"] == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCleanupCanBeDisabled(t *testing.T) {
+	s := NewServer("cleanup-test", WithCleanup(CleanupConfig{Interval: -1})).GetServer()
+	assert.Nil(t, s.cleaner)
+	assert.Empty(t, s.CleanupReclaimed())
+}
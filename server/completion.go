@@ -1,8 +1,68 @@
 package server
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MaxCompletionValues is the maximum number of values returned in a completion/complete
+// response, as required by the MCP specification. Handlers may return more; the
+// response then reports the full total and sets hasMore.
+const MaxCompletionValues = 100
+
+// CompletionHandler returns completion suggestions for the partial value of an argument.
+type CompletionHandler func(ctx *Context, value string) ([]string, error)
+
+// completionKey identifies a completion provider by reference and argument name
+type completionKey struct {
+	ref      string
+	argument string
+}
+
+// CompletionResult is the completion field of a completion/complete response.
+type CompletionResult struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total,omitempty"`
+	HasMore bool     `json:"hasMore,omitempty"`
+}
+
+// Completion registers a completion provider for an argument of a prompt or resource.
+//
+// The ref parameter is a prompt name or a resource URI template, and argument
+// names the argument being completed. The handler receives the value typed so far
+// and returns suggestions for completion/complete requests; at most
+// MaxCompletionValues are sent. Registering any provider declares the
+// completions capability.
+//
+// Example:
+//
+//	server.Completion("review", "language", func(ctx *server.Context, value string) ([]string, error) {
+//	    return filterPrefix([]string{"go", "python", "rust"}, value), nil
+//	})
+func (s *serverImpl) Completion(ref, argument string, handler CompletionHandler) Server {
+	if handler == nil {
+		s.logger.Error("completion handler cannot be nil", "ref", ref, "argument", argument)
+		return s
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.completions[completionKey{ref: ref, argument: argument}] = handler
+	return s
+}
+
+// hasCompletions reports whether any completion provider is registered
+func (s *serverImpl) hasCompletions() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.completions) > 0
+}
+
 // ProcessCompletionComplete processes a completion request from the client.
-// This method handles requests for text completion operations, which allow clients
-// to receive completion suggestions for partially typed content.
+// This method handles requests for argument completion, which allow clients
+// to offer suggestions while a user fills in prompt arguments or resource URIs.
 //
 // Parameters:
 //   - ctx: The request context containing client information and request details
@@ -11,9 +71,61 @@ package server
 //   - A response containing completion suggestions
 //   - An error if the completion operation fails
 //
-// Note: This is currently a placeholder implementation that will be expanded
-// in future versions of the protocol.
+// Arguments without a registered provider complete to an empty list.
 func (s *serverImpl) ProcessCompletionComplete(ctx *Context) (interface{}, error) {
-	// TODO: Implement completion
-	return map[string]interface{}{"completions": []interface{}{}}, nil
+	if ctx.Request.Params == nil {
+		return nil, errors.New("missing params in completion request")
+	}
+
+	var params struct {
+		Ref struct {
+			Type string `json:"type"`
+			Name string `json:"name"`
+			URI  string `json:"uri"`
+		} `json:"ref"`
+		Argument struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"argument"`
+	}
+	if err := json.Unmarshal(ctx.Request.Params, &params); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	var ref string
+	switch params.Ref.Type {
+	case "ref/prompt":
+		ref = params.Ref.Name
+	case "ref/resource":
+		ref = params.Ref.URI
+	default:
+		return nil, NewInvalidParametersError(fmt.Sprintf("unsupported completion reference type: %q", params.Ref.Type))
+	}
+	if ref == "" || params.Argument.Name == "" {
+		return nil, NewInvalidParametersError("completion requires a reference and an argument name")
+	}
+
+	// Prompts and resources hidden from the session complete as if unknown
+	s.mu.RLock()
+	handler, exists := s.completions[completionKey{ref: ref, argument: params.Argument.Name}]
+	exists = exists && s.visibleTo(ctx.Session, ref)
+	s.mu.RUnlock()
+
+	completion := CompletionResult{Values: []string{}}
+	if exists {
+		values, err := handler(ctx, params.Argument.Value)
+		if err != nil {
+			return nil, fmt.Errorf("completion failed for %s argument %q: %w", ref, params.Argument.Name, err)
+		}
+		if values != nil {
+			completion.Values = values
+		}
+		if len(values) > MaxCompletionValues {
+			completion.Values = values[:MaxCompletionValues]
+			completion.Total = len(values)
+			completion.HasMore = true
+		}
+	}
+
+	return map[string]interface{}{"completion": completion}, nil
 }
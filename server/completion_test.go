@@ -0,0 +1,67 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionComplete(t *testing.T) {
+	s := NewServer("completion-test").GetServer()
+	s.SetTransport(&recordingTransport{})
+
+	languages := []string{"go", "javascript", "python", "rust"}
+	s.Prompt("review", "Code review", User("Review this {{language}} code"))
+	s.Completion("review", "language", func(ctx *Context, value string) ([]string, error) {
+		var matches []string
+		for _, language := range languages {
+			if strings.HasPrefix(language, value) {
+				matches = append(matches, language)
+			}
+		}
+		return matches, nil
+	})
+	s.Completion("file:///{path}", "path", func(ctx *Context, value string) ([]string, error) {
+		values := make([]string, 150)
+		for i := range values {
+			values[i] = fmt.Sprintf("%s%d", value, i)
+		}
+		return values, nil
+	})
+
+	complete := func(ref, argument string) string {
+		t.Helper()
+		request := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{"ref":%s,"argument":%s}}`, ref, argument)
+		response, err := s.handleMessage([]byte(request))
+		require.NoError(t, err)
+		return string(response)
+	}
+
+	// Prompt arguments complete through their registered provider
+	response := complete(`{"type":"ref/prompt","name":"review"}`, `{"name":"language","value":"py"}`)
+	assert.Contains(t, response, `"values":["python"]`)
+	assert.NotContains(t, response, "hasMore")
+
+	// Large result sets are capped and report the total
+	response = complete(`{"type":"ref/resource","uri":"file:///{path}"}`, `{"name":"path","value":"f"}`)
+	assert.Contains(t, response, `"total":150`)
+	assert.Contains(t, response, `"hasMore":true`)
+	assert.Contains(t, response, `"f99"`)
+	assert.NotContains(t, response, `"f100"`)
+
+	// Arguments without a provider complete to nothing
+	response = complete(`{"type":"ref/prompt","name":"review"}`, `{"name":"style","value":""}`)
+	assert.Contains(t, response, `"values":[]`)
+
+	// Unknown reference types are rejected
+	response = complete(`{"type":"ref/tool","name":"x"}`, `{"name":"a","value":""}`)
+	assert.Contains(t, response, `"error"`)
+
+	// The capability is declared once a provider is registered
+	initialize, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":2,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"client","version":"1.0"}}}`))
+	require.NoError(t, err)
+	assert.Contains(t, string(initialize), `"completions":{}`)
+}
@@ -0,0 +1,781 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/localrivet/gomcp/transport"
+	"github.com/localrivet/gomcp/transport/http"
+	"github.com/localrivet/gomcp/transport/sse"
+	"github.com/localrivet/gomcp/transport/ws"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a declarative server configuration, read from a YAML or JSON file by
+// LoadConfig and NewServerFromConfig. It covers the operational settings of a
+// server, so they can change without recompiling it; tools are still registered
+// in code.
+//
+// String values may reference environment variables as ${VAR}, or ${VAR:-default}
+// to fall back to a default when VAR is unset. Durations are written as Go
+// durations, such as "30s" or "5m".
+//
+// Example:
+//
+//	name: weather
+//	transport:
+//	  type: http
+//	  address: ":${PORT:-8080}"
+//	  tls:
+//	    cert: /etc/weather/cert.pem
+//	    key: /etc/weather/key.pem
+//	  access:
+//	    requestsPerSecond: 20
+//	logging:
+//	  level: info
+//	  format: json
+//	limits:
+//	  maxConcurrentRequests: 16
+//	sampling:
+//	  maxRequestsPerMinute: 60
+//	risk:
+//	  environment: ${DEPLOY_ENV:-production}
+//	  policies:
+//	    staging:
+//	      destructive: allow
+//	      irreversible: approve
+//	features:
+//	  strict-validation: true
+//	resources:
+//	  - uri: /docs/usage
+//	    description: How to use the weather tools
+//	    file: docs/usage.md
+//	prompts:
+//	  - name: summarize
+//	    description: Summarize the weather
+//	    messages:
+//	      - role: user
+//	        text: Summarize the weather in {{city}}
+type Config struct {
+	// Name identifies the server to clients
+	Name string `yaml:"name"`
+
+	// ProtocolVersion enforces a protocol version instead of negotiating one
+	ProtocolVersion string `yaml:"protocolVersion"`
+
+	Transport TransportConfig   `yaml:"transport"`
+	Logging   LoggingConfig     `yaml:"logging"`
+	Limits    LimitsConfig      `yaml:"limits"`
+	Sampling  *SamplingSettings `yaml:"sampling"`
+	Risk      *RiskSettings     `yaml:"risk"`
+	Features  map[string]bool   `yaml:"features"`
+	Resources []ResourceConfig  `yaml:"resources"`
+	Prompts   []PromptConfig    `yaml:"prompts"`
+
+	// dir is the directory of the config file, which relative paths are resolved against
+	dir string
+}
+
+// TransportConfig selects the transport of a Config and its options.
+type TransportConfig struct {
+	// Type is one of stdio (the default), http, sse, websocket or unix
+	Type string `yaml:"type"`
+
+	// Address is the listening address of network transports, such as ":8080"
+	Address string `yaml:"address"`
+
+	// Path is the socket path of the unix transport
+	Path string `yaml:"path"`
+
+	// PathPrefix prefixes the endpoints of the http and sse transports
+	PathPrefix string `yaml:"pathPrefix"`
+
+	// Endpoint is the MCP endpoint of the http and sse transports
+	Endpoint string `yaml:"endpoint"`
+
+	// LogFile redirects the logs of the stdio transport
+	LogFile string `yaml:"logFile"`
+
+	// TLS serves the http, sse and websocket transports over TLS
+	TLS *TLSSettings `yaml:"tls"`
+
+	// Limits bounds the http and sse transports, see transport.ServerLimits
+	Limits *ServerLimitSettings `yaml:"limits"`
+
+	// Access filters and rate limits clients of the http, sse and websocket
+	// transports, see transport.AccessPolicy
+	Access *AccessSettings `yaml:"access"`
+
+	// AllowedOrigins restricts the origins the websocket transport accepts
+	AllowedOrigins []string `yaml:"allowedOrigins"`
+
+	// DisableLegacy stops the sse transport from serving the deprecated HTTP+SSE
+	// endpoints of 2024-11-05, answering them with a migration hint instead
+	DisableLegacy bool `yaml:"disableLegacy"`
+}
+
+// TLSSettings are the TLS files of a TransportConfig.
+type TLSSettings struct {
+	Cert string `yaml:"cert"`
+	Key  string `yaml:"key"`
+
+	// ClientCA enables mutual TLS, requiring client certificates signed by these CAs
+	ClientCA string `yaml:"clientCA"`
+}
+
+// ServerLimitSettings are the transport.ServerLimits of a TransportConfig.
+type ServerLimitSettings struct {
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+	ReadTimeout       time.Duration `yaml:"readTimeout"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout"`
+	MaxHeaderBytes    int           `yaml:"maxHeaderBytes"`
+	MaxConnections    int           `yaml:"maxConnections"`
+}
+
+// AccessSettings are the transport.AccessPolicy of a TransportConfig.
+type AccessSettings struct {
+	Allow               []string `yaml:"allow"`
+	Deny                []string `yaml:"deny"`
+	RequestsPerSecond   float64  `yaml:"requestsPerSecond"`
+	Burst               int      `yaml:"burst"`
+	MaxConnectionsPerIP int      `yaml:"maxConnectionsPerIP"`
+}
+
+// LoggingConfig configures the server's logger.
+type LoggingConfig struct {
+	// Level is debug, info (the default), warn or error
+	Level string `yaml:"level"`
+
+	// Format is text (the default) or json
+	Format string `yaml:"format"`
+
+	// Output is stderr (the default), stdout or the path of a file to append to
+	Output string `yaml:"output"`
+}
+
+// LimitsConfig bounds the server's request handling.
+type LimitsConfig struct {
+	// MaxConcurrentRequests handles requests on a pool of this many workers,
+	// see WithMaxConcurrentRequests
+	MaxConcurrentRequests int `yaml:"maxConcurrentRequests"`
+
+	// RequestQueue is how many requests wait for a worker, see WithRequestQueue
+	RequestQueue int `yaml:"requestQueue"`
+
+	// Overflow is what happens to requests overflowing the queue: reject (the
+	// default) or wait
+	Overflow string `yaml:"overflow"`
+
+	// DrainTimeout bounds how long shutdown waits for in-flight requests, see
+	// WithDrainTimeout
+	DrainTimeout *time.Duration `yaml:"drainTimeout"`
+
+	// SessionTTL expires idle sessions, see WithSessionTTL
+	SessionTTL time.Duration `yaml:"sessionTTL"`
+}
+
+// SamplingSettings override the defaults of NewDefaultSamplingConfig. Fields left
+// unset keep their default.
+type SamplingSettings struct {
+	MaxRequestsPerMinute  int            `yaml:"maxRequestsPerMinute"`
+	MaxConcurrentRequests int            `yaml:"maxConcurrentRequests"`
+	MaxTokensPerRequest   int            `yaml:"maxTokensPerRequest"`
+	PerClientRateLimit    *bool          `yaml:"perClientRateLimit"`
+	DefaultTimeout        time.Duration  `yaml:"defaultTimeout"`
+	MaxTimeout            time.Duration  `yaml:"maxTimeout"`
+	MaxRetries            *int           `yaml:"maxRetries"`
+	RetryBackoff          time.Duration  `yaml:"retryBackoff"`
+	Prioritization        *bool          `yaml:"prioritization"`
+	DefaultPriority       int            `yaml:"defaultPriority"`
+	ResourceQuota         map[string]int `yaml:"resourceQuota"`
+	CacheTTL              time.Duration  `yaml:"cacheTTL"`
+	CacheMaxEntries       int            `yaml:"cacheMaxEntries"`
+	ShareCache            bool           `yaml:"shareCache"`
+	Deduplicate           bool           `yaml:"deduplicate"`
+}
+
+// RiskSettings select the RiskPolicy of the deployment environment. Policies maps
+// environments to policies; environments without one get the preset of
+// RiskPolicyFor.
+type RiskSettings struct {
+	Environment string                `yaml:"environment"`
+	Policies    map[string]RiskPolicy `yaml:"policies"`
+}
+
+// policy returns the policy of the configured environment
+func (r RiskSettings) policy() RiskPolicy {
+	if policy, ok := r.Policies[r.Environment]; ok {
+		return policy
+	}
+	return RiskPolicyFor(r.Environment)
+}
+
+// ResourceConfig is a static resource served from the config, with its contents
+// given inline as Text or read from File on every request.
+type ResourceConfig struct {
+	URI         string   `yaml:"uri"`
+	Description string   `yaml:"description"`
+	MimeType    string   `yaml:"mimeType"`
+	Text        string   `yaml:"text"`
+	File        string   `yaml:"file"`
+	Tags        []string `yaml:"tags"`
+}
+
+// PromptConfig is a prompt template served from the config.
+type PromptConfig struct {
+	Name        string          `yaml:"name"`
+	Description string          `yaml:"description"`
+	Tags        []string        `yaml:"tags"`
+	Messages    []MessageConfig `yaml:"messages"`
+}
+
+// MessageConfig is a message of a PromptConfig. Exactly one of Text, Image or
+// Resource is set, as for the User, UserImage and UserResource templates.
+type MessageConfig struct {
+	// Role is user or assistant
+	Role string `yaml:"role"`
+
+	Text     string `yaml:"text"`
+	Image    string `yaml:"image"`
+	Resource string `yaml:"resource"`
+}
+
+// envReference matches ${VAR} and ${VAR:-default}
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// NewServerFromConfig creates a server from a YAML or JSON config file; see Config
+// for its format. The options are applied after the config, so they can add to it
+// or override it.
+//
+// Example:
+//
+//	s, err := server.NewServerFromConfig("weather.yaml")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	s.Tool("get_forecast", "Returns the forecast for a city", getForecast)
+//	s.Run()
+func NewServerFromConfig(path string, options ...Option) (Server, error) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return config.NewServer(options...)
+}
+
+// LoadConfig reads a YAML or JSON config file, expanding the environment
+// variables it references.
+func LoadConfig(path string) (*Config, error) {
+	config := &Config{dir: filepath.Dir(path)}
+	if err := loadDocument(path, config); err != nil {
+		return nil, err
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// loadDocument reads a YAML or JSON file into out, expanding the environment
+// variables it references
+func loadDocument(path string, out interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	// Expand variables in the parsed values rather than the raw text, so that
+	// values with quotes or newlines cannot break the document's syntax
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	var missing []string
+	expandNode(&document, &missing)
+	if len(missing) > 0 {
+		return fmt.Errorf("config %s references undefined environment variables: %s", path, strings.Join(missing, ", "))
+	}
+
+	if document.Kind != 0 {
+		if err := decodeStrict(&document, out); err != nil {
+			return fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// decodeStrict decodes a document, rejecting fields the target does not have so
+// that misspelt settings are reported instead of ignored
+func decodeStrict(document *yaml.Node, out interface{}) error {
+	data, err := yaml.Marshal(document)
+	if err != nil {
+		return err
+	}
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// expandNode replaces the environment variable references in the scalars of a
+// document, recording the variables that are undefined and have no default
+func expandNode(node *yaml.Node, missing *[]string) {
+	if node.Kind == yaml.ScalarNode && strings.Contains(node.Value, "${") {
+		node.Value = envReference.ReplaceAllStringFunc(node.Value, func(reference string) string {
+			match := envReference.FindStringSubmatch(reference)
+			if value, ok := os.LookupEnv(match[1]); ok {
+				return value
+			}
+			if strings.Contains(reference, ":-") {
+				return match[2]
+			}
+			*missing = append(*missing, match[1])
+			return ""
+		})
+		// Let unquoted values be resolved again, so "${PORT}" can fill an int
+		if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			node.Tag = ""
+		}
+	}
+	for _, child := range node.Content {
+		expandNode(child, missing)
+	}
+}
+
+// validate checks the settings that cannot be checked by decoding alone
+func (c *Config) validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+
+	switch c.Transport.Type {
+	case "", "stdio":
+	case "http", "sse", "websocket":
+		if c.Transport.Address == "" {
+			return fmt.Errorf("transport address is required for %s", c.Transport.Type)
+		}
+	case "unix":
+		if c.Transport.Path == "" {
+			return errors.New("transport path is required for unix")
+		}
+	default:
+		return fmt.Errorf("unsupported transport type %q", c.Transport.Type)
+	}
+	if tls := c.Transport.TLS; tls != nil && (tls.Cert == "" || tls.Key == "") {
+		return errors.New("transport tls requires both cert and key")
+	}
+
+	if _, err := parseLogLevel(c.Logging.Level); err != nil {
+		return err
+	}
+	switch c.Logging.Format {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("unsupported logging format %q", c.Logging.Format)
+	}
+	switch c.Limits.Overflow {
+	case "", "reject", "wait":
+	default:
+		return fmt.Errorf("unsupported overflow policy %q", c.Limits.Overflow)
+	}
+
+	if c.Risk != nil {
+		for environment, policy := range c.Risk.Policies {
+			if err := policy.validate(); err != nil {
+				return fmt.Errorf("risk policy %s: %w", environment, err)
+			}
+		}
+	}
+
+	for i, resource := range c.Resources {
+		if resource.URI == "" {
+			return fmt.Errorf("resource %d has no uri", i+1)
+		}
+		if resource.Text != "" && resource.File != "" {
+			return fmt.Errorf("resource %s has both text and file", resource.URI)
+		}
+		if resource.File != "" {
+			if _, err := os.Stat(c.resolve(resource.File)); err != nil {
+				return fmt.Errorf("resource %s: %w", resource.URI, err)
+			}
+		}
+	}
+	for i, prompt := range c.Prompts {
+		if prompt.Name == "" {
+			return fmt.Errorf("prompt %d has no name", i+1)
+		}
+		if len(prompt.Messages) == 0 {
+			return fmt.Errorf("prompt %s has no messages", prompt.Name)
+		}
+		for _, message := range prompt.Messages {
+			if _, err := message.template(); err != nil {
+				return fmt.Errorf("prompt %s: %w", prompt.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// NewServer creates a server from the config. The options are applied after the
+// config, so they can add to it or override it.
+func (c *Config) NewServer(options ...Option) (Server, error) {
+	configured, err := c.options()
+	if err != nil {
+		return nil, err
+	}
+	s := NewServer(c.Name, append(configured, options...)...)
+
+	for _, resource := range c.Resources {
+		c.registerResource(s.GetServer(), resource)
+	}
+	for _, prompt := range c.Prompts {
+		templates := make([]PromptTemplate, len(prompt.Messages))
+		for i, message := range prompt.Messages {
+			templates[i], _ = message.template()
+		}
+		s.Prompt(prompt.Name, prompt.Description, templates...)
+		if len(prompt.Tags) > 0 {
+			s.GetServer().TagPrompt(prompt.Name, prompt.Tags...)
+		}
+	}
+	return s, nil
+}
+
+// options returns the server options the config stands for
+func (c *Config) options() ([]Option, error) {
+	var options []Option
+
+	// The logger comes first, as the other options log with it
+	logger, err := c.Logging.logger(c.resolve)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, WithLogger(logger))
+
+	if c.ProtocolVersion != "" {
+		options = append(options, WithProtocolVersion(c.ProtocolVersion))
+	}
+
+	if c.Limits.MaxConcurrentRequests > 0 {
+		options = append(options, WithMaxConcurrentRequests(c.Limits.MaxConcurrentRequests))
+		if c.Limits.RequestQueue > 0 || c.Limits.Overflow != "" {
+			length := c.Limits.RequestQueue
+			if length <= 0 {
+				length = DefaultRequestQueueLength
+			}
+			policy := OverflowReject
+			if c.Limits.Overflow == "wait" {
+				policy = OverflowWait
+			}
+			options = append(options, WithRequestQueue(length, policy))
+		}
+	}
+	if c.Limits.DrainTimeout != nil {
+		options = append(options, WithDrainTimeout(*c.Limits.DrainTimeout))
+	}
+	if c.Limits.SessionTTL > 0 {
+		options = append(options, WithSessionTTL(c.Limits.SessionTTL))
+	}
+
+	if c.Sampling != nil {
+		options = append(options, WithSamplingConfig(c.Sampling.samplingConfig()))
+	}
+	if c.Risk != nil {
+		options = append(options, WithRiskPolicy(c.Risk.policy()))
+	}
+
+	for name, enabled := range c.Features {
+		options = append(options, WithFeature(name, enabled))
+	}
+
+	return append(options, Configure(c.transport())), nil
+}
+
+// transport returns the transport selected by the config
+func (c *Config) transport() Transport {
+	t := c.Transport
+	switch t.Type {
+	case "http":
+		var options []http.Option
+		if t.PathPrefix != "" {
+			options = append(options, http.WithPathPrefix(t.PathPrefix))
+		}
+		if t.Endpoint != "" {
+			options = append(options, http.WithMCPEndpoint(t.Endpoint))
+		}
+		if t.TLS != nil {
+			options = append(options, http.WithTLS(c.resolve(t.TLS.Cert), c.resolve(t.TLS.Key)))
+			if t.TLS.ClientCA != "" {
+				options = append(options, http.WithMutualTLS(c.resolve(t.TLS.ClientCA)))
+			}
+		}
+		if t.Limits != nil {
+			options = append(options, http.WithServerLimits(t.Limits.serverLimits()))
+		}
+		if t.Access != nil {
+			options = append(options, http.WithAccessPolicy(t.Access.accessPolicy()))
+		}
+		return HTTP(t.Address, options...)
+	case "sse":
+		var options []sse.Option
+		if t.PathPrefix != "" {
+			options = append(options, sse.SSE.WithPathPrefix(t.PathPrefix))
+		}
+		if t.Endpoint != "" {
+			options = append(options, sse.SSE.WithMCPEndpoint(t.Endpoint))
+		}
+		if t.TLS != nil {
+			options = append(options, sse.SSE.WithTLS(c.resolve(t.TLS.Cert), c.resolve(t.TLS.Key)))
+			if t.TLS.ClientCA != "" {
+				options = append(options, sse.SSE.WithMutualTLS(c.resolve(t.TLS.ClientCA)))
+			}
+		}
+		if t.Limits != nil {
+			options = append(options, sse.SSE.WithServerLimits(t.Limits.serverLimits()))
+		}
+		if t.Access != nil {
+			options = append(options, sse.SSE.WithAccessPolicy(t.Access.accessPolicy()))
+		}
+		if t.DisableLegacy {
+			options = append(options, sse.SSE.WithoutLegacyEndpoints())
+		}
+		return SSE(t.Address, options...)
+	case "websocket":
+		var options []ws.Option
+		if t.TLS != nil {
+			options = append(options, ws.WithTLS(c.resolve(t.TLS.Cert), c.resolve(t.TLS.Key)))
+		}
+		if t.Access != nil {
+			options = append(options, ws.WithAccessPolicy(t.Access.accessPolicy()))
+		}
+		if len(t.AllowedOrigins) > 0 {
+			options = append(options, ws.WithAllowedOrigins(t.AllowedOrigins...))
+		}
+		return Websocket(t.Address, options...)
+	case "unix":
+		return UnixSocket(t.Path)
+	default:
+		if t.LogFile != "" {
+			return Stdio(c.resolve(t.LogFile))
+		}
+		return Stdio()
+	}
+}
+
+// registerResource registers a static resource of the config
+func (c *Config) registerResource(s *serverImpl, resource ResourceConfig) {
+	mimeType := resource.MimeType
+	if mimeType == "" {
+		mimeType = "text/plain"
+		if resource.File != "" {
+			if detected := mime.TypeByExtension(filepath.Ext(resource.File)); detected != "" {
+				mimeType = detected
+			}
+		}
+	}
+
+	file := ""
+	if resource.File != "" {
+		file = c.resolve(resource.File)
+	}
+	handler := func(ctx *Context, args interface{}) (interface{}, error) {
+		text := resource.Text
+		if file != "" {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", resource.URI, err)
+			}
+			text = string(data)
+		}
+		return map[string]interface{}{
+			"contents": []interface{}{
+				map[string]interface{}{
+					"uri":      resource.URI,
+					"mimeType": mimeType,
+					"text":     text,
+				},
+			},
+		}, nil
+	}
+
+	var annotations []map[string]interface{}
+	if len(resource.Tags) > 0 {
+		annotations = append(annotations, WithTags(resource.Tags...))
+	}
+	s.Resource(resource.URI, resource.Description, handler, annotations...)
+
+	// Resource listings take the MIME type from the resource's schema
+	s.mu.Lock()
+	if registered, ok := s.resources[resource.URI]; ok {
+		schema, _ := registered.Schema.(map[string]interface{})
+		if schema == nil {
+			schema = map[string]interface{}{}
+		}
+		schema["mimeType"] = mimeType
+		registered.Schema = schema
+	}
+	s.mu.Unlock()
+}
+
+// resolve returns a path relative to the config file's directory
+func (c *Config) resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.dir, path)
+}
+
+// template returns the prompt template of a message
+func (m MessageConfig) template() (PromptTemplate, error) {
+	if m.Role != "user" && m.Role != "assistant" {
+		return PromptTemplate{}, fmt.Errorf("unsupported message role %q, expected user or assistant", m.Role)
+	}
+
+	set := 0
+	template := PromptTemplate{Role: m.Role}
+	if m.Text != "" {
+		set++
+		template.Content = m.Text
+	}
+	if m.Image != "" {
+		set++
+		template.Content, template.Type = m.Image, ContentTypeImage
+	}
+	if m.Resource != "" {
+		set++
+		template.Content, template.Type = m.Resource, ContentTypeResource
+	}
+	if set != 1 {
+		return PromptTemplate{}, errors.New("each message needs exactly one of text, image or resource")
+	}
+	return template, nil
+}
+
+// logger returns the logger the logging config stands for
+func (l LoggingConfig) logger(resolve func(string) string) (*slog.Logger, error) {
+	level, err := parseLogLevel(l.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	var output io.Writer
+	switch l.Output {
+	case "", "stderr":
+		output = os.Stderr
+	case "stdout":
+		output = os.Stdout
+	default:
+		file, err := os.OpenFile(resolve(l.Output), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log output: %w", err)
+		}
+		output = file
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	if l.Format == "json" {
+		return slog.New(slog.NewJSONHandler(output, options)), nil
+	}
+	return slog.New(slog.NewTextHandler(output, options)), nil
+}
+
+// parseLogLevel parses the level of a logging config, defaulting to info
+func parseLogLevel(level string) (slog.Level, error) {
+	if level == "" {
+		return slog.LevelInfo, nil
+	}
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return 0, fmt.Errorf("unsupported logging level %q", level)
+	}
+	return parsed, nil
+}
+
+// serverLimits returns the transport limits of the settings, keeping the defaults
+// for those left unset
+func (l ServerLimitSettings) serverLimits() transport.ServerLimits {
+	limits := transport.DefaultServerLimits()
+	if l.ReadHeaderTimeout > 0 {
+		limits.ReadHeaderTimeout = l.ReadHeaderTimeout
+	}
+	if l.ReadTimeout > 0 {
+		limits.ReadTimeout = l.ReadTimeout
+	}
+	if l.WriteTimeout > 0 {
+		limits.WriteTimeout = l.WriteTimeout
+	}
+	if l.IdleTimeout > 0 {
+		limits.IdleTimeout = l.IdleTimeout
+	}
+	if l.MaxHeaderBytes > 0 {
+		limits.MaxHeaderBytes = l.MaxHeaderBytes
+	}
+	if l.MaxConnections > 0 {
+		limits.MaxConnections = l.MaxConnections
+	}
+	return limits
+}
+
+// accessPolicy returns the transport access policy of the settings
+func (a AccessSettings) accessPolicy() transport.AccessPolicy {
+	return transport.AccessPolicy{
+		Allow:               a.Allow,
+		Deny:                a.Deny,
+		RequestsPerSecond:   a.RequestsPerSecond,
+		Burst:               a.Burst,
+		MaxConnectionsPerIP: a.MaxConnectionsPerIP,
+	}
+}
+
+// samplingConfig returns the default sampling config overridden by the settings
+func (s SamplingSettings) samplingConfig() *SamplingConfig {
+	config := NewDefaultSamplingConfig()
+	if s.MaxRequestsPerMinute > 0 {
+		config.MaxRequestsPerMinute = s.MaxRequestsPerMinute
+	}
+	if s.MaxConcurrentRequests > 0 {
+		config.MaxConcurrentRequests = s.MaxConcurrentRequests
+	}
+	if s.MaxTokensPerRequest > 0 {
+		config.MaxTokensPerRequest = s.MaxTokensPerRequest
+	}
+	if s.PerClientRateLimit != nil {
+		config.PerClientRateLimit = *s.PerClientRateLimit
+	}
+	if s.DefaultTimeout > 0 {
+		config.DefaultTimeout = s.DefaultTimeout
+	}
+	if s.MaxTimeout > 0 {
+		config.MaxTimeout = s.MaxTimeout
+	}
+	if s.MaxRetries != nil {
+		config.WithRetry(*s.MaxRetries, s.RetryBackoff, nil)
+	}
+	if s.Prioritization != nil {
+		config.EnablePrioritization = *s.Prioritization
+	}
+	if s.DefaultPriority > 0 {
+		config.DefaultPriority = s.DefaultPriority
+	}
+	for name, quota := range s.ResourceQuota {
+		config.ResourceQuota[name] = quota
+	}
+	if s.CacheMaxEntries > 0 {
+		config.CacheMaxEntries = s.CacheMaxEntries
+	}
+	config.CacheTTL = s.CacheTTL
+	config.ShareCache = s.ShareCache
+	config.Deduplicate = s.Deduplicate
+	return config
+}
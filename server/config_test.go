@@ -0,0 +1,160 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const weatherConfig = `
+name: weather
+transport:
+  type: http
+  address: "127.0.0.1:${WEATHER_PORT}"
+  endpoint: /weather
+  access:
+    requestsPerSecond: 5
+logging:
+  level: ${WEATHER_LOG_LEVEL:-warn}
+  format: json
+limits:
+  maxConcurrentRequests: ${WEATHER_WORKERS}
+  overflow: wait
+  drainTimeout: 5s
+sampling:
+  maxRequestsPerMinute: 30
+  maxRetries: 2
+  retryBackoff: 250ms
+  cacheTTL: 5m
+  deduplicate: true
+resources:
+  - uri: /docs/usage
+    description: How to use the weather tools
+    file: usage.md
+    tags: [docs]
+  - uri: /motd
+    text: "${WEATHER_MOTD}"
+prompts:
+  - name: summarize
+    description: Summarize the weather
+    messages:
+      - role: user
+        text: Summarize the weather in {{city}}
+      - role: assistant
+        resource: /motd
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestNewServerFromConfig(t *testing.T) {
+	t.Setenv("WEATHER_PORT", "18181")
+	t.Setenv("WEATHER_WORKERS", "4")
+	t.Setenv("WEATHER_MOTD", "Sunny: \"bring a hat\"")
+	path := writeConfig(t, weatherConfig)
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "usage.md"), []byte("# Usage"), 0644))
+
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "warn", config.Logging.Level, "defaults apply to unset variables")
+	assert.Equal(t, 4, config.Limits.MaxConcurrentRequests, "variables can fill numbers")
+	assert.Equal(t, "Sunny: \"bring a hat\"", config.Resources[1].Text, "values are expanded after parsing")
+
+	srv, err := NewServerFromConfig(path)
+	require.NoError(t, err)
+	s := srv.GetServer()
+
+	assert.Equal(t, "weather", s.GetName())
+	httpTransport, ok := s.GetTransport().(*http.Transport)
+	require.True(t, ok, "expected the HTTP transport")
+	assert.Equal(t, "/weather", httpTransport.GetFullMCPEndpoint())
+	require.NotNil(t, s.requestPool)
+	assert.Equal(t, OverflowWait, s.requestPool.policy)
+	assert.Equal(t, 5*time.Second, s.drainTimeout)
+	assert.Equal(t, 30, s.samplingConfig.MaxRequestsPerMinute)
+	require.NotNil(t, s.samplingConfig.Retry)
+	assert.Equal(t, 2, s.samplingConfig.Retry.MaxRetries)
+	assert.Equal(t, 250*time.Millisecond, s.samplingConfig.Retry.Backoff)
+	assert.Equal(t, 5*time.Minute, s.samplingConfig.CacheTTL)
+	assert.True(t, s.samplingConfig.Deduplicate)
+
+	s.SetTransport(&recordingTransport{})
+	send := func(message string) string {
+		t.Helper()
+		response, err := s.handleMessage([]byte(message))
+		require.NoError(t, err)
+		return string(response)
+	}
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+
+	list := send(`{"jsonrpc":"2.0","id":2,"method":"resources/list"}`)
+	assert.Contains(t, list, `"mimeType":"text/markdown`)
+	assert.Contains(t, list, `"tags":["docs"]`)
+
+	assert.Contains(t, send(`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"/docs/usage"}}`), "# Usage")
+
+	// Files are read on every request, so edits show without a restart
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "usage.md"), []byte("# Usage, revised"), 0644))
+	assert.Contains(t, send(`{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"/docs/usage"}}`), "# Usage, revised")
+
+	prompt := send(`{"jsonrpc":"2.0","id":5,"method":"prompts/get","params":{"name":"summarize","arguments":{"city":"Paris"}}}`)
+	assert.Contains(t, prompt, "Summarize the weather in Paris")
+	assert.Contains(t, prompt, `bring a hat`)
+}
+
+func TestNewServerFromConfigOptionsOverride(t *testing.T) {
+	path := writeConfig(t, "name: plain\nlimits:\n  maxConcurrentRequests: 8\n")
+
+	srv, err := NewServerFromConfig(path, WithMaxConcurrentRequests(0))
+	require.NoError(t, err)
+	assert.Nil(t, srv.GetServer().requestPool, "options are applied after the config")
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := map[string]struct {
+		config string
+		err    string
+	}{
+		"missing name":         {"transport:\n  type: stdio\n", "name is required"},
+		"unknown field":        {"name: x\ntransprot:\n  type: http\n", "field transprot not found"},
+		"undefined variable":   {"name: ${GOMCP_UNDEFINED_A}-${GOMCP_UNDEFINED_B}\n", "GOMCP_UNDEFINED_A, GOMCP_UNDEFINED_B"},
+		"unsupported type":     {"name: x\ntransport:\n  type: carrier-pigeon\n", `unsupported transport type "carrier-pigeon"`},
+		"missing address":      {"name: x\ntransport:\n  type: http\n", "transport address is required for http"},
+		"bad level":            {"name: x\nlogging:\n  level: loud\n", `unsupported logging level "loud"`},
+		"bad duration":         {"name: x\nlimits:\n  drainTimeout: soon\n", "into time.Duration"},
+		"missing file":         {"name: x\nresources:\n  - uri: /a\n    file: missing.md\n", "resource /a"},
+		"bad role":             {"name: x\nprompts:\n  - name: p\n    messages:\n      - role: system\n        text: hi\n", `unsupported message role "system"`},
+		"ambiguous message":    {"name: x\nprompts:\n  - name: p\n    messages:\n      - role: user\n        text: hi\n        image: abc\n", "exactly one of text, image or resource"},
+		"text and file":        {"name: x\nresources:\n  - uri: /a\n    text: a\n    file: a.md\n", "both text and file"},
+		"tls without key file": {"name: x\ntransport:\n  type: http\n  address: :1\n  tls:\n    cert: c.pem\n", "both cert and key"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfig(t, tt.config))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestLoadConfigJSON(t *testing.T) {
+	t.Setenv("GOMCP_SOCKET", "/tmp/gomcp-config-test.sock")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"name": "json", "transport": {"type": "unix", "path": "${GOMCP_SOCKET}"}, "limits": {"sessionTTL": "10m"}}`), 0644))
+
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/gomcp-config-test.sock", config.Transport.Path)
+	assert.Equal(t, 10*time.Minute, config.Limits.SessionTTL)
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"github.com/localrivet/gomcp/transport"
+	"github.com/localrivet/gomcp/transport/embedded"
+	"github.com/localrivet/gomcp/transport/grpc"
+	"github.com/localrivet/gomcp/transport/http"
+	"github.com/localrivet/gomcp/transport/mqtt"
+	"github.com/localrivet/gomcp/transport/nats"
+	"github.com/localrivet/gomcp/transport/sse"
+	"github.com/localrivet/gomcp/transport/udp"
+	"github.com/localrivet/gomcp/transport/unix"
+	"github.com/localrivet/gomcp/transport/ws"
+)
+
+// Transport selects and configures the transport a server communicates over.
+// Create one with a transport constructor such as HTTP or Stdio, and apply it with
+// the Configure option or the Server's Configure method.
+type Transport func(s *serverImpl)
+
+// Configure returns an option that selects the server's transport.
+//
+// Example:
+//
+//	s := server.NewServer("my-server", server.Configure(server.HTTP(":8080")))
+func Configure(t Transport) Option {
+	return func(s *serverImpl) {
+		s.Configure(t)
+	}
+}
+
+// Configure selects the transport the server communicates over.
+func (s *serverImpl) Configure(t Transport) Server {
+	if t == nil {
+		s.logger.Error("transport cannot be nil")
+		return s
+	}
+
+	t(s)
+	return s
+}
+
+// Stdio selects Standard I/O, optionally redirecting logs to logFile.
+// See AsStdio.
+func Stdio(logFile ...string) Transport {
+	return func(s *serverImpl) {
+		s.AsStdio(logFile...)
+	}
+}
+
+// HTTP selects HTTP on the given address. See AsHTTP.
+func HTTP(address string, options ...http.Option) Transport {
+	return func(s *serverImpl) {
+		s.AsHTTP(address, options...)
+	}
+}
+
+// GRPC selects gRPC on the given address. See AsGRPC.
+func GRPC(address string, options ...grpc.Option) Transport {
+	return func(s *serverImpl) {
+		s.AsGRPC(address, options...)
+	}
+}
+
+// Websocket selects WebSocket on the given address. See AsWebsocket.
+func Websocket(address string, options ...ws.Option) Transport {
+	return func(s *serverImpl) {
+		s.AsWebsocket(address, options...)
+	}
+}
+
+// SSE selects Server-Sent Events on the given address. See AsSSE.
+func SSE(address string, options ...sse.Option) Transport {
+	return func(s *serverImpl) {
+		s.AsSSE(address, options...)
+	}
+}
+
+// UnixSocket selects a Unix domain socket at the given path. See AsUnixSocket.
+func UnixSocket(socketPath string, options ...unix.UnixSocketOption) Transport {
+	return func(s *serverImpl) {
+		s.AsUnixSocket(socketPath, options...)
+	}
+}
+
+// UDP selects UDP on the given address. See AsUDP.
+func UDP(address string, options ...udp.UDPOption) Transport {
+	return func(s *serverImpl) {
+		s.AsUDP(address, options...)
+	}
+}
+
+// MQTT selects MQTT through the given broker. See AsMQTT.
+func MQTT(brokerURL string, options ...mqtt.MQTTOption) Transport {
+	return func(s *serverImpl) {
+		s.AsMQTT(brokerURL, options...)
+	}
+}
+
+// NATS selects NATS through the given server. See AsNATS.
+func NATS(serverURL string, options ...nats.NATSOption) Transport {
+	return func(s *serverImpl) {
+		s.AsNATS(serverURL, options...)
+	}
+}
+
+// Embedded selects the server side of an in-process transport pair. See AsEmbedded.
+func Embedded(t *embedded.Transport) Transport {
+	return func(s *serverImpl) {
+		s.AsEmbedded(t)
+	}
+}
+
+// CustomTransport selects a transport implemented outside this module.
+//
+// Example:
+//
+//	s := server.NewServer("my-server", server.Configure(server.CustomTransport(myTransport)))
+func CustomTransport(t transport.Transport) Transport {
+	return func(s *serverImpl) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		t.SetMessageHandler(s.handleMessage)
+		s.transport = t
+
+		s.logger.Info("server configured with custom transport")
+	}
+}
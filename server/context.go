@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"reflect"
+	"sync"
 
 	"github.com/localrivet/gomcp/mcp"
 	"github.com/localrivet/gomcp/util/schema"
@@ -44,6 +45,18 @@ type Context struct {
 	// Progress token for long-running operations (if present)
 	ProgressToken string
 
+	// progressTokens are the tokens created for this request, released when it ends,
+	// and progressReporters its reporters, cancelled if the request is
+	progressMu        sync.Mutex
+	progressTokens    []string
+	progressReporters []*mcp.ProgressReporter
+
+	// clientProgressToken is the progress token the client sent in the request's _meta
+	clientProgressToken string
+
+	// cancelled is closed when the request is cancelled or finishes
+	cancelled <-chan struct{}
+
 	// Metadata for storing contextual information during request processing
 	Metadata map[string]interface{}
 
@@ -116,12 +129,12 @@ func NewContext(ctx context.Context, requestBytes []byte, server *serverImpl) (*
 		server:       server,
 		Logger:       server.logger,
 		Metadata:     make(map[string]interface{}),
-		Session:      server.defaultSession, // ✅ Attach the current session
+		Session:      server.sessionForContext(ctx), // ✅ Attach the message's session
 	}
 
-	// If we have a default session, set the sessionID in metadata
-	if server.defaultSession != nil {
-		reqCtx.Metadata["sessionID"] = string(server.defaultSession.ID)
+	// If we have a session, set the sessionID in metadata
+	if reqCtx.Session != nil {
+		reqCtx.Metadata["sessionID"] = string(reqCtx.Session.ID)
 	}
 
 	// Parse the request
@@ -138,8 +151,8 @@ func NewContext(ctx context.Context, requestBytes []byte, server *serverImpl) (*
 		reqCtx.ProgressToken = progressToken
 	}
 
-	// Default to latest protocol version if not specified
-	reqCtx.Version = "2025-03-26"
+	// Use the protocol version negotiated with this session's client
+	reqCtx.Version = server.sessionProtocolVersion(reqCtx.Session)
 
 	// Parse specific request type based on method
 	switch request.Method {
@@ -210,6 +223,17 @@ func stringify(id interface{}) string {
 	}
 }
 
+// Context returns the standard Go context of this request. It is cancelled when the
+// client cancels the request with notifications/cancelled, or when the request's
+// deadline passes, so long-running handlers can select on its Done channel or pass
+// it to functions that accept a context.
+func (c *Context) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
 // Done returns a channel that's closed when this context is canceled.
 // This method implements part of the standard Go context.Context interface,
 // allowing the Context to be used with functions expecting a cancellable context.
@@ -467,10 +491,27 @@ func (c *Context) GetSamplingController() (*SamplingController, error) {
 	return c.server.samplingController, nil
 }
 
+// sessionID returns the ID of the session that issued the request, or an empty ID
+// if the context has none
+func (c *Context) sessionID() SessionID {
+	if c == nil {
+		return ""
+	}
+	if c.Session != nil {
+		return c.Session.ID
+	}
+	if sessionID, ok := c.Metadata["sessionID"].(string); ok {
+		return SessionID(sessionID)
+	}
+	return ""
+}
+
 // RequestSampling sends a sampling request using the context's session information.
 // This is a convenience wrapper around the server's RequestSamplingFromContext method,
 // which automatically uses the current context's session, protocol version, and other
-// metadata when making the sampling request.
+// metadata when making the sampling request. The request is sent to the client of the
+// session that issued this request, and fails without being sent if that client did
+// not declare the sampling capability or cannot take the content of the messages.
 //
 // Parameters:
 //   - messages: A slice of SamplingMessage objects representing the conversation
@@ -519,26 +560,9 @@ func (c *Context) RequestSamplingWithPriority(messages []SamplingMessage, prefer
 		return nil, err
 	}
 
+	// Rate and concurrency limits are applied when the request is sent
 	options := controller.GetRequestOptions(priority)
-
-	// Apply rate limiting
-	sessionID := SessionID("")
-	if sessionVal, ok := c.Metadata["sessionID"]; ok {
-		if sessionIDStr, ok := sessionVal.(string); ok {
-			sessionID = SessionID(sessionIDStr)
-		}
-	}
-
-	// Check if we can process this request based on rate limits
-	if !controller.CanProcessRequest(sessionID) {
-		return nil, fmt.Errorf("request rate limit exceeded")
-	}
-
-	// Record the request for rate limiting purposes
-	controller.RecordRequest(sessionID)
-
-	// Set up deferred completion recording
-	defer controller.CompleteRequest(sessionID)
+	sessionID := c.sessionID()
 
 	// Execute with appropriate options
 	return c.server.RequestSamplingWithSessionAndOptions(
@@ -552,6 +576,35 @@ func (c *Context) RequestSamplingWithPriority(messages []SamplingMessage, prefer
 	)
 }
 
+// RequestSamplingWithOptions sends a sampling request using the context's session
+// and custom options, for example to override the server's retry policy for one
+// request through RequestSamplingOptions.Retry.
+//
+// Parameters:
+//   - messages: A slice of SamplingMessage objects representing the conversation
+//   - preferences: Model preferences for the sampling request
+//   - systemPrompt: Optional system prompt to help guide the model's behavior
+//   - maxTokens: Maximum number of tokens to generate in the response
+//   - options: Timeout, retry and generation options for this request
+//
+// Returns:
+//   - A SamplingResponse containing the model's generated content
+//   - An error if the sampling request fails or the server is not available
+func (c *Context) RequestSamplingWithOptions(messages []SamplingMessage, preferences SamplingModelPreferences,
+	systemPrompt string, maxTokens int, options RequestSamplingOptions) (*SamplingResponse, error) {
+
+	if c.server == nil {
+		return nil, fmt.Errorf("server not available in context")
+	}
+
+	sessionID := c.sessionID()
+	if sessionID == "" {
+		return c.server.RequestSamplingWithOptions(messages, preferences, systemPrompt, maxTokens, options)
+	}
+
+	return c.server.RequestSamplingWithSessionAndOptions(sessionID, c.Version, messages, preferences, systemPrompt, maxTokens, options)
+}
+
 // ValidateSamplingRequest validates that a sampling request is valid for the current protocol version
 // and client capabilities. It checks that the message content types are supported in the
 // negotiated protocol version and that the requested token count is within acceptable limits.
@@ -590,7 +643,7 @@ func (c *Context) IsCancelled() bool {
 
 	// Then check MCP cancellation
 	if c.RequestID != "" && c.server != nil && c.server.requestCanceller != nil {
-		return c.server.requestCanceller.IsCancelled(c.RequestID)
+		return c.server.requestCanceller.IsCancelled(cancellationKey(c.Session, c.RequestID))
 	}
 
 	return false
@@ -611,15 +664,22 @@ func (c *Context) CheckCancellation() error {
 }
 
 // RegisterForCancellation registers this context's request to be cancellable
-// Returns a channel that will be closed if the request is cancelled
+// Returns a channel that will be closed if the request is cancelled, or once it has
+// finished for requests the server is handling
 func (c *Context) RegisterForCancellation() <-chan struct{} {
+	// The request is already registered for as long as it runs; registering it again
+	// after it finished would leave an entry behind that nothing removes
+	if c.cancelled != nil {
+		return c.cancelled
+	}
+
 	if c.RequestID == "" || c.server == nil || c.server.requestCanceller == nil {
 		// Return a never-closing channel if we can't register properly
 		ch := make(chan struct{})
 		return ch
 	}
 
-	return c.server.requestCanceller.Register(c.RequestID)
+	return c.server.requestCanceller.Register(cancellationKey(c.Session, c.RequestID))
 }
 
 // CancelRequest sends a cancellation notification for this context's request
@@ -633,17 +693,43 @@ func (c *Context) CancelRequest(reason string) error {
 }
 
 // CreateProgressToken creates a new progress token for this request
-// This should be called at the beginning of long-running operations to enable progress tracking
+// This should be called at the beginning of long-running operations to enable progress tracking.
+// If the client sent a progress token with the request, that token is returned instead,
+// since the client only recognizes progress reported on it.
 func (c *Context) CreateProgressToken() string {
 	if c.server == nil {
 		return ""
 	}
+	if c.clientProgressToken != "" {
+		c.ProgressToken = c.clientProgressToken
+		return c.clientProgressToken
+	}
 
-	token := c.server.CreateProgressToken(c.RequestID)
-	c.ProgressToken = token
+	token := c.server.CreateProgressTokenForVersion(c.RequestID, c.protocolVersion())
+	c.setProgressToken(token)
 	return token
 }
 
+// setProgressToken makes a token created for this request its progress token, and
+// delivers the token's progress to the session that made the request
+func (c *Context) setProgressToken(token string) {
+	c.ProgressToken = token
+	c.progressMu.Lock()
+	c.progressTokens = append(c.progressTokens, token)
+	c.progressMu.Unlock()
+	if c.Session != nil && c.server != nil {
+		c.server.progressTokenManager.BindSession(token, string(c.Session.ID))
+	}
+}
+
+// trackProgressReporter remembers a reporter of this request, so that it is cancelled
+// with the request
+func (c *Context) trackProgressReporter(reporter *mcp.ProgressReporter) {
+	c.progressMu.Lock()
+	c.progressReporters = append(c.progressReporters, reporter)
+	c.progressMu.Unlock()
+}
+
 // SendProgress sends a progress notification for this context's progress token
 // If no progress token exists, this method does nothing and returns nil
 func (c *Context) SendProgress(progress float64, total *float64, message string) error {
@@ -651,7 +737,7 @@ func (c *Context) SendProgress(progress float64, total *float64, message string)
 		return nil // No progress token, nothing to do
 	}
 
-	return c.server.SendProgressNotification(c.ProgressToken, progress, total, message)
+	return c.server.sendProgressNotificationForVersion(c.ProgressToken, progress, total, message, c.protocolVersion())
 }
 
 // CompleteProgress marks the progress as complete and deactivates the progress token
@@ -661,7 +747,7 @@ func (c *Context) CompleteProgress(finalMessage string) error {
 		return nil // No progress token, nothing to do
 	}
 
-	return c.server.CompleteProgress(c.ProgressToken, finalMessage)
+	return c.server.completeProgressForVersion(c.ProgressToken, finalMessage, c.protocolVersion())
 }
 
 // HasProgressToken returns true if this context has an active progress token
@@ -676,10 +762,11 @@ func (c *Context) CreateProgressReporter(total *float64, initialMessage string)
 		return nil
 	}
 
-	reporter := c.server.CreateProgressReporter(c.RequestID, total, initialMessage)
+	reporter := c.server.createProgressReporterForVersion(c.RequestID, total, initialMessage, c.protocolVersion())
 
 	// Update context with the reporter's token
-	c.ProgressToken = reporter.GetToken()
+	c.setProgressToken(reporter.GetToken())
+	c.trackProgressReporter(reporter)
 
 	return reporter
 }
@@ -693,7 +780,8 @@ func (c *Context) CreateSimpleProgressReporter(total *float64) *mcp.ProgressRepo
 	reporter := c.server.CreateSimpleProgressReporter(c.RequestID, total)
 
 	// Update context with the reporter's token
-	c.ProgressToken = reporter.GetToken()
+	c.setProgressToken(reporter.GetToken())
+	c.trackProgressReporter(reporter)
 
 	return reporter
 }
@@ -708,7 +796,8 @@ func (c *Context) StartProgressOperation(total *float64, initialMessage string)
 	reporter := c.server.StartProgressOperation(c.RequestID, total, initialMessage)
 
 	// Update context with the reporter's token
-	c.ProgressToken = reporter.GetToken()
+	c.setProgressToken(reporter.GetToken())
+	c.trackProgressReporter(reporter)
 
 	return reporter
 }
@@ -794,6 +883,24 @@ func (c *Context) GetPrimaryRoot() string {
 	return ""
 }
 
+// RequireRoots returns the roots the client declared for its session, or the
+// roots registered on the server if it declared none. Handlers that need a
+// workspace can return its error as is: with no roots at all, it is a
+// RemediableError telling the user how to add one.
+func (c *Context) RequireRoots() ([]string, error) {
+	if roots := c.Session.Roots(); len(roots) > 0 {
+		return roots, nil
+	}
+	if roots := c.GetRoots(); len(roots) > 0 {
+		return roots, nil
+	}
+	return nil, &RemediableError{
+		Reason:  mcp.ReasonMissingRoots,
+		Message: "no roots are configured",
+		Hint:    "add a workspace root with AddRoot on the client, or with Root on the server",
+	}
+}
+
 // InRoots checks if a path is within any registered root
 func (c *Context) InRoots(path string) bool {
 	if c.server != nil {
@@ -0,0 +1,436 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DaemonConfig configures a Daemon, read from a YAML or JSON file by
+// LoadDaemonConfig. Each of its servers is described by a Config file of its own.
+// String values may reference environment variables as in a Config.
+//
+// Example:
+//
+//	pidFile: /run/gomcp/gomcp.pid
+//	controlSocket: /run/gomcp/control.sock
+//	servers:
+//	  - config: weather.yaml
+//	  - config: git.yaml
+//	    providers: [git]
+type DaemonConfig struct {
+	// PIDFile is where the daemon writes its process ID, if set
+	PIDFile string `yaml:"pidFile"`
+
+	// ControlSocket is the path of the unix socket serving the status, reload and
+	// stop commands. The default is the config file's path with the extension
+	// .sock.
+	ControlSocket string `yaml:"controlSocket"`
+
+	// Servers are the servers the daemon runs
+	Servers []DaemonServerConfig `yaml:"servers"`
+
+	// path is the config file, which the daemon reads again on reload
+	path string
+}
+
+// DaemonServerConfig is a server run by a Daemon.
+type DaemonServerConfig struct {
+	// Config is the path of the server's Config file, relative to the daemon's
+	Config string `yaml:"config"`
+
+	// Providers are the tool providers, registered with RegisterProvider, that the
+	// server loads
+	Providers []string `yaml:"providers"`
+}
+
+// LoadDaemonConfig reads a daemon config file, expanding the environment variables
+// it references. The config files of its servers are read when they start.
+func LoadDaemonConfig(path string) (*DaemonConfig, error) {
+	config := &DaemonConfig{path: path}
+	if err := loadDocument(path, config); err != nil {
+		return nil, err
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// validate checks the settings and resolves relative paths against the config
+// file's directory
+func (c *DaemonConfig) validate() error {
+	dir := filepath.Dir(c.path)
+	resolve := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(dir, path)
+	}
+
+	if c.ControlSocket == "" {
+		c.ControlSocket = strings.TrimSuffix(c.path, filepath.Ext(c.path)) + ".sock"
+	}
+	c.ControlSocket = resolve(c.ControlSocket)
+	c.PIDFile = resolve(c.PIDFile)
+
+	if len(c.Servers) == 0 {
+		return errors.New("no servers configured")
+	}
+	available := Providers()
+	for i := range c.Servers {
+		entry := &c.Servers[i]
+		if entry.Config == "" {
+			return fmt.Errorf("server %d has no config", i+1)
+		}
+		entry.Config = resolve(entry.Config)
+		for _, provider := range entry.Providers {
+			if !slices.Contains(available, provider) {
+				return fmt.Errorf("server %s uses unknown provider %q, available: %s",
+					entry.Config, provider, strings.Join(available, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// DaemonOption configures a Daemon.
+type DaemonOption func(*Daemon)
+
+// WithDaemonLogger sets the logger of the daemon itself; each server logs as its
+// config says.
+func WithDaemonLogger(logger *slog.Logger) DaemonOption {
+	return func(d *Daemon) {
+		if logger != nil {
+			d.logger = logger
+		}
+	}
+}
+
+// WithDaemonSetup sets a function called with the name and server of every server
+// the daemon creates, after its config and providers are applied and before it
+// starts, so that programs embedding a daemon can register the tools they
+// implement in code. Servers whose setup fails are not started.
+//
+// Example:
+//
+//	server.NewDaemon("gomcp.yaml", server.WithDaemonSetup(func(name string, s server.Server) error {
+//	    if name == "weather" {
+//	        s.Tool("get_forecast", "Returns the forecast for a city", getForecast)
+//	    }
+//	    return nil
+//	}))
+func WithDaemonSetup(setup func(name string, s Server) error) DaemonOption {
+	return func(d *Daemon) {
+		d.setup = setup
+	}
+}
+
+// Daemon runs the servers of a DaemonConfig as a long-lived service. It writes a
+// pidfile, serves the status, reload and stop commands on a control socket (see
+// DaemonControl), and reports its readiness to systemd when started by a unit of
+// Type=notify.
+type Daemon struct {
+	path   string
+	logger *slog.Logger
+	setup  func(name string, s Server) error
+
+	// mu serializes reloads and guards the fields below it
+	mu      sync.Mutex
+	config  *DaemonConfig
+	servers []*daemonServer
+	started time.Time
+	reloads int
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// daemonServer is a server run by a daemon
+type daemonServer struct {
+	entry   DaemonServerConfig
+	config  *Config
+	server  *serverImpl
+	started time.Time
+	err     error
+}
+
+// DaemonStatus is the state of a daemon, as reported by the status command.
+type DaemonStatus struct {
+	PID     int                  `json:"pid"`
+	Config  string               `json:"config"`
+	Started time.Time            `json:"started"`
+	Reloads int                  `json:"reloads"`
+	Servers []DaemonServerStatus `json:"servers"`
+}
+
+// DaemonServerStatus is the state of a server run by a daemon.
+type DaemonServerStatus struct {
+	Name      string    `json:"name"`
+	Config    string    `json:"config"`
+	Transport string    `json:"transport"`
+	Address   string    `json:"address,omitempty"`
+	Running   bool      `json:"running"`
+	Started   time.Time `json:"started,omitempty"`
+	Sessions  int       `json:"sessions"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// NewDaemon creates a daemon running the servers of a daemon config file.
+//
+// Example:
+//
+//	d, err := server.NewDaemon("/etc/gomcp/gomcp.yaml")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+//	defer stop()
+//	if err := d.Run(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+func NewDaemon(path string, options ...DaemonOption) (*Daemon, error) {
+	config, err := LoadDaemonConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Daemon{
+		path:   path,
+		logger: slog.Default(),
+		config: config,
+		stop:   make(chan struct{}),
+	}
+	for _, option := range options {
+		option(d)
+	}
+	return d, nil
+}
+
+// Run starts the servers and blocks until ctx is done or the stop command is
+// received, then shuts the servers down gracefully. It fails if a server cannot
+// start or another daemon already serves the control socket.
+func (d *Daemon) Run(ctx context.Context) error {
+	listener, err := listenControl(d.config.ControlSocket)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(d.config.ControlSocket)
+	defer listener.Close()
+
+	if d.config.PIDFile != "" {
+		if err := writePIDFile(d.config.PIDFile); err != nil {
+			return err
+		}
+		defer os.Remove(d.config.PIDFile)
+	}
+
+	d.mu.Lock()
+	d.started = time.Now()
+	servers, err := d.startServers(d.config, nil)
+	d.servers = servers
+	d.mu.Unlock()
+	if err != nil {
+		d.stopServers(servers)
+		return err
+	}
+
+	go d.serveControl(listener)
+	d.logger.Info("daemon started", "config", d.path, "servers", len(servers), "controlSocket", d.config.ControlSocket)
+	sdNotify(fmt.Sprintf("READY=1\nMAINPID=%d\nSTATUS=Serving %d servers", os.Getpid(), len(servers)))
+
+	select {
+	case <-ctx.Done():
+	case <-d.stop:
+	}
+
+	sdNotify("STOPPING=1")
+	d.logger.Info("daemon stopping")
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stopServers(d.servers)
+}
+
+// Stop makes Run shut the servers down and return.
+func (d *Daemon) Stop() {
+	d.stopOnce.Do(func() { close(d.stop) })
+}
+
+// Reload reads the config files again and applies them: servers whose config or
+// providers changed are restarted, removed servers are shut down and new ones are
+// started, while the others keep running and serving their clients. If a config
+// file is invalid, nothing changes. The pidfile and control socket keep their
+// paths until the daemon restarts.
+func (d *Daemon) Reload() error {
+	config, err := LoadDaemonConfig(d.path)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	sdNotify("RELOADING=1")
+	defer sdNotify("READY=1")
+
+	// Read every server's config before changing anything
+	configs := make([]*Config, len(config.Servers))
+	for i, entry := range config.Servers {
+		if configs[i], err = LoadConfig(entry.Config); err != nil {
+			return err
+		}
+	}
+
+	// Stop the servers that are gone or changed, keeping the others
+	var kept []*daemonServer
+	var stale []*daemonServer
+	for _, running := range d.servers {
+		i := slices.IndexFunc(config.Servers, func(entry DaemonServerConfig) bool { return entry.Config == running.entry.Config })
+		if i >= 0 && running.err == nil && slices.Equal(running.entry.Providers, config.Servers[i].Providers) &&
+			reflect.DeepEqual(running.config, configs[i]) {
+			kept = append(kept, running)
+		} else {
+			stale = append(stale, running)
+		}
+	}
+	stopErr := d.stopServers(stale)
+
+	servers, err := d.startServers(config, kept)
+	d.servers = servers
+	d.reloads++
+	d.logger.Info("daemon reloaded", "servers", len(servers), "restarted", len(stale), "error", err)
+	return errors.Join(stopErr, err)
+}
+
+// Status returns the state of the daemon and its servers.
+func (d *Daemon) Status() DaemonStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	status := DaemonStatus{
+		PID:     os.Getpid(),
+		Config:  d.path,
+		Started: d.started,
+		Reloads: d.reloads,
+	}
+	for _, ds := range d.servers {
+		serverStatus := DaemonServerStatus{Config: ds.entry.Config, Started: ds.started}
+		if ds.config != nil {
+			serverStatus.Name = ds.config.Name
+			serverStatus.Transport = ds.config.Transport.Type
+			serverStatus.Address = ds.config.Transport.Address
+			if serverStatus.Address == "" {
+				serverStatus.Address = ds.config.Transport.Path
+			}
+		}
+		if ds.err != nil {
+			serverStatus.Error = ds.err.Error()
+		}
+		if ds.server != nil {
+			select {
+			case <-ds.server.done:
+			default:
+				serverStatus.Running = true
+			}
+			if sessions, err := ds.server.sessionManager.ListSessions(); err == nil {
+				serverStatus.Sessions = len(sessions)
+			}
+		}
+		status.Servers = append(status.Servers, serverStatus)
+	}
+	return status
+}
+
+// startServers starts the servers of a config that are not among those running,
+// returning all of them in the order of the config. Servers that fail to start are
+// returned with their error.
+func (d *Daemon) startServers(config *DaemonConfig, running []*daemonServer) ([]*daemonServer, error) {
+	var servers []*daemonServer
+	var errs []error
+	for _, entry := range config.Servers {
+		if i := slices.IndexFunc(running, func(ds *daemonServer) bool { return ds.entry.Config == entry.Config }); i >= 0 {
+			servers = append(servers, running[i])
+			continue
+		}
+
+		ds := &daemonServer{entry: entry}
+		if err := d.startServer(ds); err != nil {
+			ds.err = fmt.Errorf("server %s: %w", entry.Config, err)
+			errs = append(errs, ds.err)
+			d.logger.Error("failed to start server", "config", entry.Config, "error", err)
+		}
+		servers = append(servers, ds)
+	}
+	return servers, errors.Join(errs...)
+}
+
+// startServer creates a server from its config and starts it
+func (d *Daemon) startServer(ds *daemonServer) error {
+	config, err := LoadConfig(ds.entry.Config)
+	if err != nil {
+		return err
+	}
+	ds.config = config
+	if config.Transport.Type == "" || config.Transport.Type == "stdio" {
+		return errors.New("servers run by a daemon need a network or unix transport, not stdio")
+	}
+
+	srv, err := config.NewServer()
+	if err != nil {
+		return err
+	}
+	srv.GetServer().UseProviderByName(ds.entry.Providers...)
+	if d.setup != nil {
+		if err := d.setup(config.Name, srv); err != nil {
+			return fmt.Errorf("setup failed: %w", err)
+		}
+	}
+
+	ds.server = srv.GetServer()
+	if err := ds.server.start(); err != nil {
+		ds.server = nil
+		return err
+	}
+	ds.started = time.Now()
+	return nil
+}
+
+// stopServers shuts servers down concurrently, each waiting for its in-flight
+// requests up to its drain timeout
+func (d *Daemon) stopServers(servers []*daemonServer) error {
+	errs := make([]error, len(servers))
+	var wg sync.WaitGroup
+	for i, ds := range servers {
+		if ds.server == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, ds *daemonServer) {
+			defer wg.Done()
+			if err := ds.server.ShutdownContext(context.Background()); err != nil {
+				errs[i] = fmt.Errorf("server %s: %w", ds.config.Name, err)
+			}
+		}(i, ds)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// writePIDFile writes the process ID to a file, creating its directory
+func writePIDFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create pidfile directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write pidfile: %w", err)
+	}
+	return nil
+}
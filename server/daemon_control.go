@@ -0,0 +1,164 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Commands served on the control socket of a Daemon
+const (
+	DaemonStatusCommand = "status"
+	DaemonReloadCommand = "reload"
+	DaemonStopCommand   = "stop"
+)
+
+// controlTimeout bounds how long a control connection may take to send its command
+const controlTimeout = 5 * time.Second
+
+// controlRequest is a command sent to the control socket
+type controlRequest struct {
+	Command string `json:"command"`
+}
+
+// controlResponse is the answer to a command, with the daemon's status after it
+type controlResponse struct {
+	Error  string        `json:"error,omitempty"`
+	Status *DaemonStatus `json:"status,omitempty"`
+}
+
+// DaemonControl sends a command, one of DaemonStatusCommand, DaemonReloadCommand
+// or DaemonStopCommand, to the daemon serving a control socket. It returns the
+// daemon's status once the command has been carried out; stop returns the status
+// before the servers shut down.
+//
+// Example:
+//
+//	status, err := server.DaemonControl("/run/gomcp/control.sock", server.DaemonStatusCommand)
+func DaemonControl(socket, command string) (*DaemonStatus, error) {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("daemon is not running (control socket %s): %w", socket, err)
+	}
+	defer conn.Close()
+
+	if err := json.NewEncoder(conn).Encode(controlRequest{Command: command}); err != nil {
+		return nil, fmt.Errorf("failed to send command: %w", err)
+	}
+	var response controlResponse
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if response.Error != "" {
+		return response.Status, errors.New(response.Error)
+	}
+	return response.Status, nil
+}
+
+// listenControl listens on the control socket, replacing a socket left behind by
+// a daemon that is no longer running
+func listenControl(socket string) (net.Listener, error) {
+	if conn, err := net.Dial("unix", socket); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("daemon already running (control socket %s)", socket)
+	}
+	if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale control socket: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(socket), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create control socket directory: %w", err)
+	}
+
+	// Bind in a directory only the daemon's user can enter, and move the socket
+	// into place once it is restricted, so no one else can connect in between
+	private, err := os.MkdirTemp(filepath.Dir(socket), ".control-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create control socket directory: %w", err)
+	}
+	defer os.RemoveAll(private)
+	bound := filepath.Join(private, "control.sock")
+
+	listener, err := net.Listen("unix", bound)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on control socket: %w", err)
+	}
+	// Run removes the socket; the listener would only remove the path it was bound to
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(bound, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict control socket: %w", err)
+	}
+	if err := os.Rename(bound, socket); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on control socket: %w", err)
+	}
+	return listener, nil
+}
+
+// serveControl answers the commands sent to the control socket until it is closed
+func (d *Daemon) serveControl(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go d.handleControl(conn)
+	}
+}
+
+// handleControl carries out the command of one control connection
+func (d *Daemon) handleControl(conn net.Conn) {
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(controlTimeout))
+
+	var request controlRequest
+	var response controlResponse
+	if err := json.NewDecoder(conn).Decode(&request); err != nil {
+		response.Error = fmt.Sprintf("invalid command: %v", err)
+		json.NewEncoder(conn).Encode(response)
+		return
+	}
+	d.logger.Info("control command received", "command", request.Command)
+
+	switch request.Command {
+	case DaemonStatusCommand:
+	case DaemonReloadCommand:
+		if err := d.Reload(); err != nil {
+			response.Error = err.Error()
+		}
+	case DaemonStopCommand:
+		defer d.Stop()
+	default:
+		response.Error = fmt.Sprintf("unknown command %q, expected %s, %s or %s",
+			request.Command, DaemonStatusCommand, DaemonReloadCommand, DaemonStopCommand)
+	}
+	status := d.Status()
+	response.Status = &status
+	json.NewEncoder(conn).Encode(response)
+}
+
+// sdNotify sends a state change, such as READY=1, to systemd when the process was
+// started by a unit of Type=notify, which sets NOTIFY_SOCKET. It does nothing
+// otherwise.
+func sdNotify(state string) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return
+	}
+	// Abstract sockets are given with a leading @
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.Write([]byte(state))
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDaemon(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("alpha.yaml", "name: alpha\ntransport:\n  type: http\n  address: 127.0.0.1:0\n")
+	write("beta.yaml", "name: beta\ntransport:\n  type: websocket\n  address: 127.0.0.1:0\n")
+	write("gamma.yaml", "name: gamma\ntransport:\n  type: sse\n  address: 127.0.0.1:0\n")
+	write("gomcp.yaml", "pidFile: run/gomcp.pid\nservers:\n  - config: alpha.yaml\n  - config: beta.yaml\n")
+
+	// Stand in for systemd's notification socket
+	notifySocket := filepath.Join(dir, "notify.sock")
+	notifications, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: notifySocket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer notifications.Close()
+	t.Setenv("NOTIFY_SOCKET", notifySocket)
+	nextNotification := func(t *testing.T) string {
+		t.Helper()
+		buf := make([]byte, 1024)
+		require.NoError(t, notifications.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, err := notifications.Read(buf)
+		require.NoError(t, err)
+		return string(buf[:n])
+	}
+
+	var setUp []string
+	d, err := NewDaemon(filepath.Join(dir, "gomcp.yaml"), WithDaemonSetup(func(name string, s Server) error {
+		setUp = append(setUp, name)
+		return nil
+	}))
+	require.NoError(t, err)
+	socket := filepath.Join(dir, "gomcp.sock")
+
+	done := make(chan error, 1)
+	go func() { done <- d.Run(context.Background()) }()
+	assert.Contains(t, nextNotification(t), "READY=1")
+
+	t.Run("status", func(t *testing.T) {
+		status, err := DaemonControl(socket, DaemonStatusCommand)
+		require.NoError(t, err)
+		assert.Equal(t, os.Getpid(), status.PID)
+		require.Len(t, status.Servers, 2)
+		assert.Equal(t, "alpha", status.Servers[0].Name)
+		assert.Equal(t, "websocket", status.Servers[1].Transport)
+		assert.True(t, status.Servers[0].Running && status.Servers[1].Running)
+		assert.Equal(t, []string{"alpha", "beta"}, setUp)
+
+		pid, err := os.ReadFile(filepath.Join(dir, "run", "gomcp.pid"))
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(status.PID), strings.TrimSpace(string(pid)))
+
+		// Only the daemon's user may control it
+		info, err := os.Stat(socket)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		matches, err := filepath.Glob(filepath.Join(dir, ".control-*"))
+		require.NoError(t, err)
+		assert.Empty(t, matches, "the directory the socket was bound in should be removed")
+
+		// A second daemon on the same control socket refuses to start
+		other, err := NewDaemon(filepath.Join(dir, "gomcp.yaml"))
+		require.NoError(t, err)
+		assert.ErrorContains(t, other.Run(context.Background()), "already running")
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		before := d.Status()
+		write("gomcp.yaml", "pidFile: run/gomcp.pid\nservers:\n  - config: alpha.yaml\n  - config: gamma.yaml\n")
+
+		status, err := DaemonControl(socket, DaemonReloadCommand)
+		require.NoError(t, err)
+		assert.Equal(t, "RELOADING=1", nextNotification(t))
+		assert.Equal(t, "READY=1", nextNotification(t))
+		assert.Equal(t, 1, status.Reloads)
+		require.Len(t, status.Servers, 2)
+		assert.Equal(t, "gamma", status.Servers[1].Name)
+
+		// Unchanged servers keep running
+		assert.True(t, before.Servers[0].Started.Equal(status.Servers[0].Started))
+
+		// Invalid configs leave the daemon as it was
+		write("gomcp.yaml", "servers: []\n")
+		_, err = DaemonControl(socket, DaemonReloadCommand)
+		assert.ErrorContains(t, err, "no servers configured")
+		assert.Len(t, d.Status().Servers, 2)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		_, err := DaemonControl(socket, DaemonStopCommand)
+		require.NoError(t, err)
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(15 * time.Second):
+			t.Fatal("daemon did not stop")
+		}
+		assert.Equal(t, "STOPPING=1", nextNotification(t))
+		assert.NoFileExists(t, socket)
+		assert.NoFileExists(t, filepath.Join(dir, "run", "gomcp.pid"))
+
+		_, err = DaemonControl(socket, DaemonStatusCommand)
+		assert.ErrorContains(t, err, "not running")
+	})
+}
+
+func TestDaemonConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{"no servers", "pidFile: gomcp.pid\n", "no servers configured"},
+		{"no server config", "servers:\n  - providers: []\n", "server 1 has no config"},
+		{"unknown provider", "servers:\n  - config: a.yaml\n    providers: [missing]\n", `unknown provider "missing"`},
+		{"unknown field", "servers:\n  - config: a.yaml\n    provider: [missing]\n", "field provider not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "gomcp.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.config), 0o644))
+			_, err := LoadDaemonConfig(path)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	// Servers the daemon runs need a transport clients can reach
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stdio.yaml"), []byte("name: stdio\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gomcp.yaml"), []byte("servers:\n  - config: stdio.yaml\n"), 0o644))
+	d, err := NewDaemon(filepath.Join(dir, "gomcp.yaml"))
+	require.NoError(t, err)
+	assert.ErrorContains(t, d.Run(context.Background()), "not stdio")
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"github.com/localrivet/gomcp/mcp"
+)
+
+// WithDisplayName returns an annotations map giving a tool, resource or prompt a
+// human-readable name for hosts to show instead of its identifier. It is exposed
+// under the "displayName" annotation in list responses, and as the "title" of the
+// entry to clients of the draft protocol.
+//
+// Example:
+//
+//	server.Tool("read_file", "Read a file", handler, server.WithDisplayName("Read File"))
+func WithDisplayName(name string) map[string]interface{} {
+	return map[string]interface{}{mcp.DisplayNameAnnotation: name}
+}
+
+// WithCategory returns an annotations map placing a tool, resource or prompt in a
+// category, for hosts to group entries by in pickers. It is exposed under the
+// "category" annotation in list responses. Unlike tags, an entry has a single
+// category.
+func WithCategory(category string) map[string]interface{} {
+	return map[string]interface{}{mcp.CategoryAnnotation: category}
+}
+
+// WithIcon returns an annotations map attaching an icon to a tool, resource or
+// prompt: the URL of an image, which may be a data: URL, its MIME type and the
+// sizes it suits, such as "48x48" or "any". Icons are exposed under the "icons"
+// annotation in list responses, and as the "icons" of the entry to clients of the
+// draft protocol. Give WithIcon several times for several sizes.
+//
+// Example:
+//
+//	server.Tool("deploy", "Deploy the service", handler,
+//	    server.WithIcon("https://example.com/deploy.svg", "image/svg+xml", "any"))
+func WithIcon(src, mimeType string, sizes ...string) map[string]interface{} {
+	return map[string]interface{}{
+		mcp.IconsAnnotation: []mcp.Icon{{Src: src, MimeType: mimeType, Sizes: sizes}},
+	}
+}
+
+// specDisplay returns the title and icons an entry with the given annotations
+// carries in the fields of the specification itself, which only the draft
+// protocol has
+func specDisplay(ctx *Context, annotations map[string]interface{}) (string, []mcp.Icon) {
+	if ctx == nil || (ctx.Version == "" && ctx.server == nil) || ctx.protocolVersion() != mcp.VersionDraft {
+		return "", nil
+	}
+	display := mcp.DisplayFromAnnotations(annotations)
+	return display.Name, display.Icons
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisplayMetadata(t *testing.T) {
+	newServer := func() *serverImpl {
+		s := NewServer("display-test").GetServer()
+		s.SetTransport(&recordingTransport{})
+		s.Tool("deploy", "Deploy the service", func(ctx *Context, args struct{}) (string, error) {
+			return "deployed", nil
+		},
+			WithDisplayName("Deploy Service"),
+			WithCategory("Operations"),
+			WithIcon("https://example.com/deploy-48.png", "image/png", "48x48"),
+			WithIcon("https://example.com/deploy.svg", "image/svg+xml", "any"),
+		)
+		s.Prompt("review", "Code review", User("Review {{code}}"))
+		s.AnnotatePrompt("review", WithDisplayName("Code Review"), WithCategory("Development"))
+		return s
+	}
+	list := func(t *testing.T, s *serverImpl, version, method, key string) map[string]interface{} {
+		t.Helper()
+		_, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"` + version + `","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`))
+		require.NoError(t, err)
+		response, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":2,"method":"` + method + `"}`))
+		require.NoError(t, err)
+
+		var decoded struct {
+			Result map[string][]map[string]interface{} `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(response, &decoded))
+		require.Len(t, decoded.Result[key], 1)
+		return decoded.Result[key][0]
+	}
+
+	t.Run("annotations", func(t *testing.T) {
+		tool := list(t, newServer(), "2025-03-26", "tools/list", "tools")
+		annotations := tool["annotations"].(map[string]interface{})
+		assert.Equal(t, "Deploy Service", annotations[mcp.DisplayNameAnnotation])
+		assert.Equal(t, "Operations", annotations[mcp.CategoryAnnotation])
+		assert.Len(t, annotations[mcp.IconsAnnotation], 2, "Expected icons to be combined")
+		assert.NotContains(t, tool, "title", "Expected no draft fields for older protocols")
+		assert.NotContains(t, tool, "icons")
+
+		display := mcp.DisplayFromAnnotations(annotations)
+		assert.Equal(t, mcp.Display{
+			Name:     "Deploy Service",
+			Category: "Operations",
+			Icons: []mcp.Icon{
+				{Src: "https://example.com/deploy-48.png", MimeType: "image/png", Sizes: []string{"48x48"}},
+				{Src: "https://example.com/deploy.svg", MimeType: "image/svg+xml", Sizes: []string{"any"}},
+			},
+		}, display)
+	})
+
+	t.Run("draft fields", func(t *testing.T) {
+		tool := list(t, newServer(), mcp.VersionDraft, "tools/list", "tools")
+		assert.Equal(t, "Deploy Service", tool["title"])
+		assert.Len(t, tool["icons"], 2)
+
+		prompt := list(t, newServer(), mcp.VersionDraft, "prompts/list", "prompts")
+		assert.Equal(t, "Code Review", prompt["title"])
+		assert.Equal(t, "Development", prompt["annotations"].(map[string]interface{})[mcp.CategoryAnnotation])
+	})
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/localrivet/gomcp/util/schema"
+)
+
+// ElicitationCreateParams represents the parameters of an elicitation/create
+// request. It is an alias to the shared mcp.ElicitationCreateParams type.
+type ElicitationCreateParams = mcp.ElicitationCreateParams
+
+// ElicitationResult represents the client's answer to an elicitation request.
+// It is an alias to the shared mcp.ElicitationResult type.
+type ElicitationResult = mcp.ElicitationResult
+
+// ElicitationAction is the user's answer to an elicitation request. It is an alias
+// to the shared mcp.ElicitationAction type.
+type ElicitationAction = mcp.ElicitationAction
+
+// Actions a client answers an elicitation request with
+const (
+	ElicitationAccept  = mcp.ElicitationAccept
+	ElicitationDecline = mcp.ElicitationDecline
+	ElicitationCancel  = mcp.ElicitationCancel
+)
+
+// Elicit asks the user of the client that made the current request for input, by
+// sending it an elicitation/create request, and waits for the answer until the
+// request's context is done.
+//
+// requestedSchema is either a JSON schema as a map or a struct, whose schema is
+// generated as for tool arguments. The specification only allows flat objects
+// with string, number, integer and boolean properties. prompt is shown to the
+// user and should explain what is needed and why.
+//
+// The user may decline or cancel, so check the result's Action before using its
+// Content, which has been validated against the schema. Elicit fails without
+// sending anything if the client did not declare the elicitation capability or
+// negotiated a version without elicitation; these errors are *RemediableError
+// values with reason unsupported_capability.
+//
+// Example:
+//
+//	result, err := ctx.Elicit(struct {
+//	    Branch string `json:"branch" description:"Branch to deploy"`
+//	    Force  *bool  `json:"force,omitempty"`
+//	}{}, "Which branch should be deployed?")
+//	if err != nil {
+//	    return nil, err
+//	}
+//	if !result.Accepted() {
+//	    return "deployment cancelled", nil
+//	}
+//	branch := result.Content["branch"].(string)
+func (c *Context) Elicit(requestedSchema interface{}, prompt string) (*ElicitationResult, error) {
+	if prompt == "" {
+		return nil, fmt.Errorf("elicitation prompt cannot be empty")
+	}
+	schemaMap, err := elicitationSchema(requestedSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID := c.sessionID()
+	clientInfo, exists := c.server.getClientInfoForSession(sessionID)
+	if !exists {
+		return nil, fmt.Errorf("elicitation session not found: %s", sessionID)
+	}
+	if err := validateElicitationForClient(clientInfo, c.protocolVersion()); err != nil {
+		return nil, err
+	}
+
+	params := ElicitationCreateParams{Message: prompt, RequestedSchema: schemaMap}
+	result, err := c.server.sendElicitationRequest(c.Context(), sessionID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mcp.ValidateElicitationAction(result.Action); err != nil {
+		return nil, fmt.Errorf("invalid elicitation response: %w", err)
+	}
+	if !result.Accepted() {
+		result.Content = nil
+		return result, nil
+	}
+	if result.Content == nil {
+		result.Content = map[string]interface{}{}
+	}
+	if err := schema.ValidateArgs(schemaMap, result.Content); err != nil {
+		return nil, fmt.Errorf("elicitation response does not match the requested schema: %w", err)
+	}
+	return result, nil
+}
+
+// elicitationSchema returns the requested schema of an elicitation as decoded JSON,
+// generating it from a struct if needed, and checks that clients can render it
+func elicitationSchema(requestedSchema interface{}) (map[string]interface{}, error) {
+	var source interface{} = requestedSchema
+	if _, isMap := requestedSchema.(map[string]interface{}); !isMap {
+		t := reflect.TypeOf(requestedSchema)
+		if t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("elicitation schema must be a map or a struct, got %T", requestedSchema)
+		}
+		generated, err := schema.NewGenerator().GenerateSchema(requestedSchema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate elicitation schema: %w", err)
+		}
+		source = generated
+	}
+
+	// Normalize generated property types to decoded JSON
+	data, err := json.Marshal(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode elicitation schema: %w", err)
+	}
+	var schemaMap map[string]interface{}
+	if err := json.Unmarshal(data, &schemaMap); err != nil {
+		return nil, fmt.Errorf("failed to decode elicitation schema: %w", err)
+	}
+
+	if err := mcp.ValidateElicitationSchema(schemaMap); err != nil {
+		return nil, fmt.Errorf("invalid elicitation schema: %w", err)
+	}
+	return schemaMap, nil
+}
+
+// validateElicitationForClient checks that a client declared the elicitation
+// capability and negotiated a protocol version that defines it
+func validateElicitationForClient(clientInfo ClientInfo, protocolVersion string) error {
+	if !clientInfo.ElicitationSupported {
+		return &RemediableError{
+			Reason:  mcp.ReasonUnsupportedCapability,
+			Message: "client does not support elicitation",
+			Hint:    "the client must declare the elicitation capability when it initializes, for example with client.WithElicitationHandler",
+		}
+	}
+	if !supportsElicitation(protocolVersion) {
+		return &RemediableError{
+			Reason:  mcp.ReasonUnsupportedCapability,
+			Message: fmt.Sprintf("elicitation requires protocol version draft, the client negotiated %s", protocolVersion),
+			Hint:    "connect with a client that negotiates the draft protocol version",
+		}
+	}
+	return nil
+}
+
+// sendElicitationRequest sends an elicitation/create request to the client of a
+// session and waits for its answer until ctx is done, in which case the client is
+// told the request was cancelled. Users take their time, so there is no timeout
+// of its own.
+func (s *serverImpl) sendElicitationRequest(ctx context.Context, sessionID SessionID, params ElicitationCreateParams) (*ElicitationResult, error) {
+	requestID := s.generateRequestID()
+	request := mcp.NewRequest(requestID, mcp.ElicitationCreateMethod, params)
+	requestJSON, err := request.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal elicitation request: %w", err)
+	}
+
+	if s.requestTracker == nil {
+		s.requestTracker = newRequestTracker(s.requestSessions)
+	}
+	responseChan := s.requestTracker.addRequest(int(requestID), sessionID)
+
+	s.logger.Debug("sending elicitation request", "id", requestID, "sessionID", string(sessionID))
+	if err := s.sendToSession(sessionID, requestJSON); err != nil {
+		s.requestTracker.removeRequest(int(requestID))
+		return nil, fmt.Errorf("failed to send elicitation request: %w", err)
+	}
+
+	var responseJSON json.RawMessage
+	select {
+	case responseJSON = <-responseChan:
+	case <-ctx.Done():
+		if err := s.SendCancelledNotification(strconv.FormatInt(requestID, 10), "the request waiting for input ended"); err != nil {
+			s.logger.Debug("failed to cancel elicitation request", "id", requestID, "error", err)
+		}
+		s.requestTracker.removeRequest(int(requestID))
+		return nil, ctx.Err()
+	}
+
+	var response struct {
+		Result *ElicitationResult `json:"result,omitempty"`
+		Error  *struct {
+			Code    int         `json:"code"`
+			Message string      `json:"message"`
+			Data    interface{} `json:"data,omitempty"`
+		} `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(responseJSON, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse elicitation response: %w", err)
+	}
+	if response.Error != nil {
+		if response.Error.Data != nil {
+			return nil, fmt.Errorf("client failed the elicitation request: %s (code %d): %v",
+				response.Error.Message, response.Error.Code, response.Error.Data)
+		}
+		return nil, fmt.Errorf("client failed the elicitation request: %s (code %d)", response.Error.Message, response.Error.Code)
+	}
+	if response.Result == nil {
+		return nil, fmt.Errorf("elicitation response contains no result")
+	}
+	return response.Result, nil
+}
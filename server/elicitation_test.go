@@ -0,0 +1,122 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElicit(t *testing.T) {
+	s := NewServer("elicitation-test").GetServer()
+	tr := &sharedChannelTransport{}
+	s.SetTransport(tr)
+
+	s.Tool("deploy", "Deploy after asking for the branch", func(ctx *Context, args struct{}) (string, error) {
+		result, err := ctx.Elicit(struct {
+			Branch string `json:"branch" description:"Branch to deploy"`
+			Force  *bool  `json:"force,omitempty"`
+		}{}, "Which branch should be deployed?")
+		if err != nil {
+			return "", err
+		}
+		if !result.Accepted() {
+			return "deployment " + string(result.Action), nil
+		}
+		return fmt.Sprintf("deploying %v", result.Content["branch"]), nil
+	})
+
+	send := func(sessionID, request string) string {
+		t.Helper()
+		response, err := s.handleSessionMessage(sessionID, []byte(request))
+		require.NoError(t, err)
+		return string(response)
+	}
+	send("draft", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"draft","capabilities":{"elicitation":{}},"clientInfo":{"name":"a","version":"1.0"}}}`)
+	send("undeclared", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"draft","capabilities":{},"clientInfo":{"name":"b","version":"1.0"}}}`)
+	send("stable", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{"elicitation":{}},"clientInfo":{"name":"c","version":"1.0"}}}`)
+
+	// call calls the deploy tool on the draft session and answers its elicitation
+	// request with result
+	call := func(t *testing.T, result string) string {
+		t.Helper()
+		sent := len(tr.sentTo("draft"))
+		done := make(chan string, 1)
+		go func() {
+			done <- send("draft", `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"deploy","arguments":{}}}`)
+		}()
+
+		var request struct {
+			ID     int64                   `json:"id"`
+			Params ElicitationCreateParams `json:"params"`
+		}
+		require.Eventually(t, func() bool {
+			for _, message := range tr.sentTo("draft")[sent:] {
+				if strings.Contains(message, "elicitation/create") {
+					return json.Unmarshal([]byte(message), &request) == nil
+				}
+			}
+			return false
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, "Which branch should be deployed?", request.Params.Message)
+		assert.Equal(t, []interface{}{"branch"}, request.Params.RequestedSchema["required"])
+
+		send("draft", fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%s}`, request.ID, result))
+		select {
+		case response := <-done:
+			return response
+		case <-time.After(time.Second):
+			t.Fatal("expected the tool call to complete")
+			return ""
+		}
+	}
+
+	t.Run("accepted", func(t *testing.T) {
+		assert.Contains(t, call(t, `{"action":"accept","content":{"branch":"main"}}`), "deploying main")
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		assert.Contains(t, call(t, `{"action":"decline","content":{"branch":"main"}}`), "deployment decline")
+	})
+
+	t.Run("invalid content", func(t *testing.T) {
+		response := call(t, `{"action":"accept","content":{"branch":42}}`)
+		assert.Contains(t, response, "does not match the requested schema")
+		assert.NotContains(t, response, "deploying")
+	})
+
+	t.Run("gated", func(t *testing.T) {
+		for sessionID, want := range map[string]string{
+			"undeclared": "client does not support elicitation",
+			"stable":     "elicitation requires protocol version draft",
+		} {
+			response := send(sessionID, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"deploy","arguments":{}}}`)
+			assert.Contains(t, response, want)
+			assert.Contains(t, response, `"isError":true`)
+			for _, message := range tr.sentTo(sessionID) {
+				assert.NotContains(t, message, "elicitation/create")
+			}
+		}
+	})
+}
+
+func TestElicitationSchema(t *testing.T) {
+	schemaMap, err := elicitationSchema(map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"approve": map[string]interface{}{"type": "boolean"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "object", schemaMap["type"])
+
+	_, err = elicitationSchema(struct {
+		Tags []string `json:"tags"`
+	}{})
+	assert.ErrorContains(t, err, "only string, number, integer and boolean properties are allowed")
+
+	_, err = elicitationSchema("branch")
+	assert.ErrorContains(t, err, "must be a map or a struct")
+}
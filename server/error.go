@@ -1,7 +1,11 @@
 package server
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/localrivet/gomcp/mcp"
+	"github.com/localrivet/gomcp/util/schema"
 )
 
 // createErrorResponse creates a JSON-RPC 2.0 error response.
@@ -30,3 +34,101 @@ func createErrorResponse(id interface{}, code int, message string, data interfac
 func (e *RPCError) Error() string {
 	return e.Message
 }
+
+// RemediableError is an error with a machine-readable reason and a hint telling
+// a human how to fix it. Handlers can return one, wrapped or not, to have the
+// reason and hint sent in the data of the error response, where clients surface
+// them as a client.ServerError.
+//
+// Example:
+//
+//	return nil, &server.RemediableError{
+//	    Reason:  mcp.ReasonMissingRoots,
+//	    Message: "no workspace to search",
+//	    Hint:    "add the project directory as a root",
+//	}
+type RemediableError struct {
+	// Code is the JSON-RPC error code, -32603 (internal error) if zero
+	Code int
+
+	// Reason tells why the request failed
+	Reason mcp.ErrorReason
+
+	// Message describes the failure
+	Message string
+
+	// Hint tells a human how to fix the failure
+	Hint string
+}
+
+// Error returns the error message.
+func (e *RemediableError) Error() string {
+	return e.Message
+}
+
+// errorData is the data of error responses, adding the violations of invalid
+// parameters to the reason and hint
+type errorData struct {
+	mcp.ErrorData
+	Violations []schema.FieldError `json:"violations,omitempty"`
+}
+
+// errorResponseFor returns the JSON-RPC code, message and data of the error
+// response of a failed request
+func errorResponseFor(err error) (int, string, interface{}) {
+	var invalidParams *InvalidParametersError
+	if errors.As(err, &invalidParams) {
+		hint := invalidParams.Hint
+		if hint == "" && len(invalidParams.Violations) > 0 {
+			hint = "fix the arguments listed in violations, as declared by the input schema in the list response"
+		}
+		return -32602, "Invalid params", errorData{
+			ErrorData: mcp.ErrorData{
+				Reason:  mcp.ReasonInvalidParams,
+				Message: invalidParams.Message,
+				Hint:    hint,
+			},
+			Violations: invalidParams.Violations,
+		}
+	}
+
+	var remediable *RemediableError
+	if errors.As(err, &remediable) {
+		code := remediable.Code
+		if code == 0 {
+			code = -32603
+		}
+		return code, errorMessageFor(code), errorData{ErrorData: mcp.ErrorData{
+			Reason:  remediable.Reason,
+			Message: err.Error(),
+			Hint:    remediable.Hint,
+		}}
+	}
+
+	return -32603, "Internal error", err.Error()
+}
+
+// errorMessageFor returns the message of a JSON-RPC error code
+func errorMessageFor(code int) string {
+	switch code {
+	case -32601:
+		return "Method not found"
+	case -32602:
+		return "Invalid params"
+	case -32002:
+		return "Resource not found"
+	default:
+		return "Internal error"
+	}
+}
+
+// clientCapabilityError is the error of requests for a client capability, such as
+// roots or sampling, sent to the server
+func clientCapabilityError(method, capability string) error {
+	return &RemediableError{
+		Code:    -32601,
+		Reason:  mcp.ReasonUnsupportedCapability,
+		Message: fmt.Sprintf("method not supported: %s", method),
+		Hint:    fmt.Sprintf("%s is a client capability: servers send %s to clients, which declare the capability when they initialize", capability, method),
+	}
+}
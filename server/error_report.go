@@ -0,0 +1,127 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+)
+
+// DefaultErrorReportWindow is how long repeats of a tool error are aggregated by
+// default before they are reported together.
+const DefaultErrorReportWindow = 10 * time.Second
+
+// WithErrorReportWindow sets how long repeats of a tool error are aggregated.
+// The first failure of a tool with a given error is logged and published as an
+// events.ToolFailedEvent straight away; identical failures within the window are
+// only counted, and reported as a single summary once the window closes. This
+// keeps a tool failing in a tight agent loop from flooding logs and event
+// subscribers. Zero or less reports every failure. The default is
+// DefaultErrorReportWindow.
+//
+// Example:
+//
+//	server.NewServer("my-server", server.WithErrorReportWindow(time.Minute))
+func WithErrorReportWindow(window time.Duration) Option {
+	return func(s *serverImpl) {
+		s.errorReports.window = window
+	}
+}
+
+// errorKey identifies identical tool errors
+type errorKey struct {
+	tool    string
+	message string
+}
+
+// errorRepeats counts the repeats of a tool error within its window
+type errorRepeats struct {
+	count   int
+	firstAt time.Time
+	lastAt  time.Time
+	timer   *time.Timer
+}
+
+// errorReporter aggregates repeated tool errors before logging and publishing them
+type errorReporter struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	repeats map[errorKey]*errorRepeats
+}
+
+// reportToolError reports a failure of a tool, or counts it if the same failure
+// was reported within the window
+func (s *serverImpl) reportToolError(tool string, err error) {
+	r := &s.errorReports
+	key := errorKey{tool: tool, message: err.Error()}
+	now := time.Now()
+
+	if r.window > 0 {
+		r.mu.Lock()
+		if repeats, exists := r.repeats[key]; exists {
+			repeats.count++
+			repeats.lastAt = now
+			r.mu.Unlock()
+			return
+		}
+		if r.repeats == nil {
+			r.repeats = make(map[errorKey]*errorRepeats)
+		}
+		r.repeats[key] = &errorRepeats{
+			firstAt: now,
+			timer:   time.AfterFunc(r.window, func() { s.flushToolError(key) }),
+		}
+		r.mu.Unlock()
+	}
+
+	s.logger.Warn("tool failed", "tool", tool, "error", key.message)
+	s.publishToolFailed(key, 1, now, now)
+}
+
+// flushToolError closes the window of a tool error, reporting its repeats if any
+func (s *serverImpl) flushToolError(key errorKey) {
+	r := &s.errorReports
+	r.mu.Lock()
+	repeats, exists := r.repeats[key]
+	delete(r.repeats, key)
+	r.mu.Unlock()
+
+	if !exists || repeats.count == 0 {
+		return
+	}
+	s.logger.Warn("tool failed repeatedly", "tool", key.tool, "error", key.message,
+		"count", repeats.count, "window", r.window)
+	s.publishToolFailed(key, repeats.count, repeats.firstAt, repeats.lastAt)
+}
+
+// flushToolErrors reports the repeats of every tool error without waiting for
+// their windows to close, as the server shuts down
+func (s *serverImpl) flushToolErrors() {
+	r := &s.errorReports
+	r.mu.Lock()
+	keys := make([]errorKey, 0, len(r.repeats))
+	for key, repeats := range r.repeats {
+		repeats.timer.Stop()
+		keys = append(keys, key)
+	}
+	r.mu.Unlock()
+
+	for _, key := range keys {
+		s.flushToolError(key)
+	}
+}
+
+// publishToolFailed publishes a ToolFailedEvent covering count failures
+func (s *serverImpl) publishToolFailed(key errorKey, count int, firstAt, lastAt time.Time) {
+	if s.events == nil {
+		return
+	}
+	events.Publish[events.ToolFailedEvent](s.events, events.TopicToolFailed, events.ToolFailedEvent{
+		Tool:    key.tool,
+		Error:   key.message,
+		Count:   count,
+		FirstAt: firstAt,
+		LastAt:  lastAt,
+	})
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockedBuffer is a buffer safe to log to from several goroutines
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestToolErrorReports(t *testing.T) {
+	newServer := func(window time.Duration) (*serverImpl, *lockedBuffer, chan events.ToolFailedEvent) {
+		logs := &lockedBuffer{}
+		s := NewServer("error-report-test",
+			WithLogger(slog.New(slog.NewTextHandler(logs, nil))),
+			WithErrorReportWindow(window),
+		).GetServer()
+		s.SetTransport(&recordingTransport{})
+		s.Tool("flaky", "Fails", func(ctx *Context, args struct {
+			Reason string `json:"reason"`
+		}) (string, error) {
+			return "", errors.New(args.Reason)
+		})
+
+		failures := make(chan events.ToolFailedEvent, 16)
+		events.Subscribe[events.ToolFailedEvent](s.Events(), events.TopicToolFailed,
+			func(ctx context.Context, evt events.ToolFailedEvent) error {
+				failures <- evt
+				return nil
+			})
+		return s, logs, failures
+	}
+	call := func(t *testing.T, s *serverImpl, reason string) {
+		t.Helper()
+		_, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"flaky","arguments":{"reason":"` + reason + `"}}}`))
+		require.NoError(t, err)
+	}
+	receive := func(t *testing.T, failures chan events.ToolFailedEvent) events.ToolFailedEvent {
+		t.Helper()
+		select {
+		case evt := <-failures:
+			return evt
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected a tool failed event")
+			return events.ToolFailedEvent{}
+		}
+	}
+
+	t.Run("repeats are aggregated", func(t *testing.T) {
+		s, logs, failures := newServer(100 * time.Millisecond)
+		for i := 0; i < 5; i++ {
+			call(t, s, "disk full")
+		}
+		call(t, s, "timeout")
+
+		// The first failure of each error is reported at once
+		first := map[string]events.ToolFailedEvent{}
+		for i := 0; i < 2; i++ {
+			evt := receive(t, failures)
+			first[evt.Error] = evt
+		}
+		assert.Equal(t, 1, first["disk full"].Count)
+		assert.Equal(t, 1, first["timeout"].Count)
+
+		// The repeats are summarized once the window closes
+		summary := receive(t, failures)
+		assert.Equal(t, "flaky", summary.Tool)
+		assert.Equal(t, "disk full", summary.Error)
+		assert.Equal(t, 4, summary.Count)
+		assert.False(t, summary.LastAt.Before(summary.FirstAt))
+		select {
+		case evt := <-failures:
+			t.Fatalf("Expected no summary of an error that did not repeat, got %+v", evt)
+		case <-time.After(200 * time.Millisecond):
+		}
+
+		output := logs.String()
+		assert.Equal(t, 2, strings.Count(output, `msg="tool failed"`))
+		assert.Equal(t, 1, strings.Count(output, `msg="tool failed repeatedly"`))
+		assert.Contains(t, output, "count=4")
+
+		// Once the window has closed, the error is reported anew
+		call(t, s, "disk full")
+		assert.Equal(t, 1, receive(t, failures).Count)
+	})
+
+	t.Run("shutdown flushes pending repeats", func(t *testing.T) {
+		s, logs, _ := newServer(time.Hour)
+		call(t, s, "disk full")
+		call(t, s, "disk full")
+		require.NoError(t, s.Shutdown())
+		assert.Contains(t, logs.String(), `msg="tool failed repeatedly" tool=flaky error="disk full" count=1`)
+	})
+
+	t.Run("no window", func(t *testing.T) {
+		s, _, failures := newServer(0)
+		for i := 0; i < 3; i++ {
+			call(t, s, "disk full")
+		}
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, 1, receive(t, failures).Count)
+		}
+	})
+}
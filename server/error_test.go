@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorData(t *testing.T) {
+	s := NewServer("error-data-test").GetServer()
+	s.SetTransport(&recordingTransport{})
+	s.Tool("workspace", "Needs a workspace", func(ctx *Context, args struct{}) (string, error) {
+		roots, err := ctx.RequireRoots()
+		if err != nil {
+			return "", err
+		}
+		return roots[0], nil
+	})
+
+	send := func(t *testing.T, request string) (int, mcp.ErrorData) {
+		t.Helper()
+		response, err := s.handleMessage([]byte(request))
+		require.NoError(t, err)
+		var decoded struct {
+			Error *struct {
+				Code int           `json:"code"`
+				Data mcp.ErrorData `json:"data"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(response, &decoded), string(response))
+		require.NotNil(t, decoded.Error, string(response))
+		assert.NotEmpty(t, decoded.Error.Data.Message)
+		assert.NotEmpty(t, decoded.Error.Data.Hint)
+		return decoded.Error.Code, decoded.Error.Data
+	}
+
+	tests := []struct {
+		name    string
+		request string
+		code    int
+		reason  mcp.ErrorReason
+	}{
+		{"unknown method", `{"jsonrpc":"2.0","id":1,"method":"x-myorg/missing"}`, -32601, mcp.ReasonMethodNotFound},
+		{"unknown tool", `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"missing"}}`, -32602, mcp.ReasonToolNotFound},
+		{"unknown prompt", `{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"missing"}}`, -32602, mcp.ReasonPromptNotFound},
+		{"unknown resource", `{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"file:///missing"}}`, -32002, mcp.ReasonResourceNotFound},
+		{"client capability", `{"jsonrpc":"2.0","id":1,"method":"roots/list"}`, -32601, mcp.ReasonUnsupportedCapability},
+		{"unsupported version", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`, -32602, mcp.ReasonUnsupportedVersion},
+		{"invalid params", `{"jsonrpc":"2.0","id":1,"method":"logging/setLevel","params":{"level":"loud"}}`, -32602, mcp.ReasonInvalidParams},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, data := send(t, tt.request)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.reason, data.Reason)
+		})
+	}
+
+	t.Run("tool failures include the hint", func(t *testing.T) {
+		response, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"workspace","arguments":{}}}`))
+		require.NoError(t, err)
+		assert.Contains(t, string(response), `"isError":true`)
+		assert.Contains(t, string(response), "no roots are configured (hint: add a workspace root")
+	})
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/localrivet/gomcp/transport"
+	"github.com/localrivet/gomcp/util/schema"
+)
+
+// Feature flags built into the server. They gate behaviours that are riskier than
+// the defaults, so that they can be tried out and turned off again without a
+// release.
+const (
+	// FeatureParallelStdio handles the requests read by transports such as stdio
+	// concurrently instead of one after the other, so that a slow request no
+	// longer holds up the ones behind it. The WebSocket, Unix socket, gRPC, NATS
+	// and MQTT transports run the requests of each client concurrently with it.
+	// Notifications and responses, such as cancellations, are handled while
+	// requests run either way.
+	FeatureParallelStdio = "parallel-stdio"
+
+	// FeatureStrictValidation rejects tool calls with arguments the tool's input
+	// schema does not declare, instead of ignoring them
+	FeatureStrictValidation = "strict-validation"
+)
+
+// FeaturesCapability is the key of the experimental capability under which the
+// server reports its feature flags in initialize responses.
+const FeaturesCapability = "features"
+
+// WithFeature sets a feature flag. Besides the flags built into the server, such
+// as FeatureParallelStdio and FeatureStrictValidation, any name can be set and
+// checked by handlers with FeatureEnabled to gate their own changes. The flags
+// set are reported in the experimental capabilities of initialize responses, so
+// that what a server runs with can be seen from its clients.
+//
+// Example:
+//
+//	server.NewServer("my-server", server.WithFeature(server.FeatureParallelStdio, true))
+func WithFeature(name string, enabled bool) Option {
+	return func(s *serverImpl) {
+		s.setFeature(name, enabled)
+	}
+}
+
+// SetFeature sets a feature flag while the server is running, such as to turn off
+// a change that misbehaves. Clients see the change in their next initialize
+// response.
+func (s *serverImpl) SetFeature(name string, enabled bool) {
+	s.setFeature(name, enabled)
+
+	s.mu.RLock()
+	t := s.transport
+	s.mu.RUnlock()
+	if t != nil && name == FeatureParallelStdio {
+		s.applyFeatures(t)
+	}
+	s.logger.Info("feature flag set", "feature", name, "enabled", enabled)
+}
+
+// FeatureEnabled reports whether a feature flag is set and enabled.
+func (s *serverImpl) FeatureEnabled(name string) bool {
+	s.featuresMu.RLock()
+	defer s.featuresMu.RUnlock()
+	return s.features[name]
+}
+
+// setFeature records a feature flag
+func (s *serverImpl) setFeature(name string, enabled bool) {
+	s.featuresMu.Lock()
+	defer s.featuresMu.Unlock()
+	if s.features == nil {
+		s.features = make(map[string]bool)
+	}
+	s.features[name] = enabled
+}
+
+// featureFlags returns a copy of the feature flags set, or nil if there are none
+func (s *serverImpl) featureFlags() map[string]interface{} {
+	s.featuresMu.RLock()
+	defer s.featuresMu.RUnlock()
+	if len(s.features) == 0 {
+		return nil
+	}
+	flags := make(map[string]interface{}, len(s.features))
+	for name, enabled := range s.features {
+		flags[name] = enabled
+	}
+	return flags
+}
+
+// applyFeatures passes the feature flags a transport gates its behaviours on to it
+func (s *serverImpl) applyFeatures(t transport.Transport) {
+	if cd, ok := t.(transport.ConcurrentDispatcher); ok {
+		cd.SetConcurrentDispatch(s.FeatureEnabled(FeatureParallelStdio))
+	}
+}
+
+// checkStrictArguments rejects arguments the input schema of a tool does not
+// declare, when strict validation is enabled. Schemas that declare no properties
+// at all, or allow additional ones, accept any argument.
+func (s *serverImpl) checkStrictArguments(tool *Tool, args map[string]interface{}) error {
+	if len(args) == 0 || !s.FeatureEnabled(FeatureStrictValidation) {
+		return nil
+	}
+	inputSchema := schemaMap(tool.Schema)
+	// Reflected schemas hold their properties as schema.PropertyDetail
+	properties := schemaMap(inputSchema["properties"])
+	if properties == nil {
+		return nil
+	}
+	switch additional := inputSchema["additionalProperties"].(type) {
+	case bool:
+		if additional {
+			return nil
+		}
+	case map[string]interface{}:
+		return nil
+	}
+
+	var violations schema.ValidationErrors
+	for name := range args {
+		if _, declared := properties[name]; !declared {
+			violations = append(violations, schema.FieldError{
+				Field:      name,
+				Constraint: "additionalProperties",
+				Message:    fmt.Sprintf("%s is not a known argument", name),
+			})
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Field < violations[j].Field })
+
+	fields := make([]string, len(violations))
+	for i, v := range violations {
+		fields[i] = v.Field
+	}
+	return &InvalidParametersError{
+		Message:    fmt.Sprintf("invalid arguments: unknown arguments %s", strings.Join(fields, ", ")),
+		Violations: violations,
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dispatchTransport records whether the server asked it to dispatch concurrently
+type dispatchTransport struct {
+	recordingTransport
+	concurrent bool
+}
+
+func (d *dispatchTransport) SetConcurrentDispatch(enabled bool) {
+	d.concurrent = enabled
+}
+
+func TestFeatureFlags(t *testing.T) {
+	initialize := func(t *testing.T, s *serverImpl) map[string]interface{} {
+		t.Helper()
+		response, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`))
+		require.NoError(t, err)
+		var decoded struct {
+			Result struct {
+				Capabilities map[string]interface{} `json:"capabilities"`
+			} `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(response, &decoded), string(response))
+		return decoded.Result.Capabilities
+	}
+
+	t.Run("reported in capabilities", func(t *testing.T) {
+		s := NewServer("features-test").GetServer()
+		s.SetTransport(&recordingTransport{})
+		assert.NotContains(t, initialize(t, s), "experimental")
+
+		s = NewServer("features-test",
+			WithFeature(FeatureStrictValidation, true),
+			WithFeature("new-ranking", false),
+		).GetServer()
+		s.SetTransport(&recordingTransport{})
+		assert.Equal(t, map[string]interface{}{
+			FeaturesCapability: map[string]interface{}{
+				FeatureStrictValidation: true,
+				"new-ranking":           false,
+			},
+		}, initialize(t, s)["experimental"])
+		assert.True(t, s.FeatureEnabled(FeatureStrictValidation))
+		assert.False(t, s.FeatureEnabled("new-ranking"))
+		assert.False(t, s.FeatureEnabled("unset"))
+	})
+
+	t.Run("strict validation", func(t *testing.T) {
+		s := NewServer("features-test").GetServer()
+		s.SetTransport(&recordingTransport{})
+		s.Tool("greet", "Greet someone", func(ctx *Context, args struct {
+			Name string `json:"name"`
+		}) (string, error) {
+			return "hello " + args.Name, nil
+		})
+		call := func() string {
+			response, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"greet","arguments":{"name":"ada","nmae":"ada","extra":1}}}`))
+			require.NoError(t, err)
+			return string(response)
+		}
+
+		assert.Contains(t, call(), "hello ada", "Expected unknown arguments to be ignored by default")
+
+		s.SetFeature(FeatureStrictValidation, true)
+		response := call()
+		assert.Contains(t, response, `"code":-32602`)
+		assert.Contains(t, response, "unknown arguments extra, nmae")
+
+		s.SetFeature(FeatureStrictValidation, false)
+		assert.Contains(t, call(), "hello ada")
+	})
+
+	t.Run("parallel dispatch", func(t *testing.T) {
+		s := NewServer("features-test").GetServer()
+		d := &dispatchTransport{}
+		s.SetTransport(d)
+		s.applyFeatures(d)
+		assert.False(t, d.concurrent)
+
+		s.SetFeature(FeatureParallelStdio, true)
+		assert.True(t, d.concurrent, "Expected the running transport to be updated")
+		s.SetFeature(FeatureParallelStdio, false)
+		assert.False(t, d.concurrent)
+	})
+
+	t.Run("config", func(t *testing.T) {
+		s, err := NewServerFromConfig(writeConfig(t, "name: flags\nfeatures:\n  strict-validation: true\n"))
+		require.NoError(t, err)
+		assert.True(t, s.GetServer().FeatureEnabled(FeatureStrictValidation))
+	})
+}
@@ -0,0 +1,357 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/localrivet/gomcp/events"
+	"github.com/localrivet/gomcp/mcp"
+)
+
+// DefaultFileSystemPollInterval is how often a FileSystemResource checks its directory for changes.
+const DefaultFileSystemPollInterval = 2 * time.Second
+
+// FileSystemOption configures a directory mounted with FileSystemResource.
+type FileSystemOption func(*fileSystemMount)
+
+// WithFileSystemPollInterval sets how often the mounted directory is checked for
+// changes. A zero or negative interval disables change notifications.
+func WithFileSystemPollInterval(interval time.Duration) FileSystemOption {
+	return func(m *fileSystemMount) {
+		m.pollInterval = interval
+	}
+}
+
+// FileSystemEntry is an entry in the directory listing of a FileSystemResource.
+type FileSystemEntry struct {
+	Name        string    `json:"name"`
+	URI         string    `json:"uri"`
+	IsDirectory bool      `json:"isDirectory"`
+	Size        int64     `json:"size"`
+	MimeType    string    `json:"mimeType,omitempty"`
+	ModifiedAt  time.Time `json:"modifiedAt"`
+}
+
+// fileSystemMount is a directory served as resources
+type fileSystemMount struct {
+	server       *serverImpl
+	root         string
+	baseURI      string
+	pollInterval time.Duration
+}
+
+// fileState is what a fileSystemMount remembers about a file between polls
+type fileState struct {
+	modTime time.Time
+	size    int64
+	isDir   bool
+}
+
+// FileSystemResource mounts a directory as browsable resources.
+//
+// The directory itself is served at its file:// URI as a JSON listing of its entries,
+// and everything below it through the file://<dir>/{path*} template: directories as
+// listings and files as their contents, with the MIME type detected from the file
+// extension or contents. Text files are returned as text, other files as base64 blobs.
+//
+// The directory is registered as a root, and every path is checked with IsPathInRoots
+// after resolving symlinks, so neither ".." segments nor links can reach outside the
+// registered roots.
+//
+// The directory is polled for changes (see WithFileSystemPollInterval). Changes are
+// published as ResourceChangedEvents, and clients subscribed to a changed file or
+// its directory are sent notifications/resources/updated.
+//
+// Example:
+//
+//	server.FileSystemResource("./docs")
+func (s *serverImpl) FileSystemResource(rootDir string, options ...FileSystemOption) Server {
+	root, err := filepath.Abs(rootDir)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		s.logger.Error("invalid file system resource root", "root", rootDir, "error", err)
+		return s
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		s.logger.Error("file system resource root must be a directory", "root", root)
+		return s
+	}
+
+	m := &fileSystemMount{
+		server:       s,
+		root:         root,
+		baseURI:      "file://" + filepath.ToSlash(root),
+		pollInterval: DefaultFileSystemPollInterval,
+	}
+	for _, option := range options {
+		option(m)
+	}
+
+	s.Root(root)
+
+	s.Resource(m.baseURI, "Directory listing of "+root, func(ctx *Context, args interface{}) (interface{}, error) {
+		return m.read("")
+	})
+	s.Resource(m.baseURI+"/{path*}", "Files under "+root, func(ctx *Context, args interface{}) (interface{}, error) {
+		params, _ := args.(map[string]interface{})
+		rel, _ := params["path"].(string)
+		return m.read(rel)
+	})
+
+	if m.pollInterval > 0 {
+		go m.watch()
+	}
+
+	return s
+}
+
+// uri returns the resource URI of a slash-separated path relative to the root
+func (m *fileSystemMount) uri(rel string) string {
+	if rel == "" || rel == "." {
+		return m.baseURI
+	}
+	return m.baseURI + "/" + rel
+}
+
+// resolve returns the real path of a slash-separated path relative to the root,
+// refusing paths that resolve outside the server's roots
+func (m *fileSystemMount) resolve(rel string) (string, error) {
+	path := filepath.Join(m.root, filepath.FromSlash(rel))
+	if !m.server.IsPathInRoots(path) {
+		return "", outsideRootsError(rel)
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", &RemediableError{
+			Code:    -32002,
+			Reason:  mcp.ReasonResourceNotFound,
+			Message: fmt.Sprintf("file not found: %s", rel),
+			Hint:    "read the directory listing of the mount for the files it holds",
+		}
+	}
+	if !m.server.IsPathInRoots(resolved) {
+		return "", outsideRootsError(rel)
+	}
+	return resolved, nil
+}
+
+// outsideRootsError is the error of paths that resolve outside the server's roots
+func outsideRootsError(rel string) error {
+	return &RemediableError{
+		Code:    -32602,
+		Reason:  mcp.ReasonOutsideRoots,
+		Message: fmt.Sprintf("path outside of roots: %s", rel),
+		Hint:    "only files under the server's roots can be read; symbolic links leading out of them are refused",
+	}
+}
+
+// read returns the resource contents of a file or directory
+func (m *fileSystemMount) read(rel string) (interface{}, error) {
+	path, err := m.resolve(rel)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", rel, err)
+	}
+
+	uri := m.uri(rel)
+	if info.IsDir() {
+		return m.readDir(uri, rel, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+	}
+
+	mimeType := detectMimeType(path, data)
+	content := map[string]interface{}{
+		"uri":      uri,
+		"mimeType": mimeType,
+	}
+	if isTextMimeType(mimeType) && utf8.Valid(data) {
+		content["text"] = string(data)
+	} else {
+		content["blob"] = base64.StdEncoding.EncodeToString(data)
+	}
+
+	return map[string]interface{}{"contents": []interface{}{content}}, nil
+}
+
+// readDir returns a JSON listing of a directory
+func (m *fileSystemMount) readDir(uri, rel, path string) (interface{}, error) {
+	dirEntries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", rel, err)
+	}
+
+	entries := make([]FileSystemEntry, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+
+		entry := FileSystemEntry{
+			Name:        dirEntry.Name(),
+			URI:         m.uri(strings.TrimPrefix(rel+"/"+dirEntry.Name(), "/")),
+			IsDirectory: info.IsDir(),
+			ModifiedAt:  info.ModTime(),
+		}
+		if !info.IsDir() {
+			entry.Size = info.Size()
+			entry.MimeType = mime.TypeByExtension(filepath.Ext(dirEntry.Name()))
+		}
+		entries = append(entries, entry)
+	}
+
+	listing, err := json.Marshal(entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode listing of %s: %w", rel, err)
+	}
+
+	return map[string]interface{}{
+		"contents": []interface{}{
+			map[string]interface{}{
+				"uri":      uri,
+				"mimeType": "application/json",
+				"text":     string(listing),
+			},
+		},
+	}, nil
+}
+
+// watch polls the directory until the server shuts down, reporting changes
+func (m *fileSystemMount) watch() {
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	previous := m.snapshot()
+	for {
+		select {
+		case <-ticker.C:
+			current := m.snapshot()
+			m.reportChanges(previous, current)
+			previous = current
+		case <-m.server.done:
+			return
+		}
+	}
+}
+
+// snapshot records the state of every file below the root
+func (m *fileSystemMount) snapshot() map[string]fileState {
+	states := make(map[string]fileState)
+	filepath.WalkDir(m.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || path == m.root {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(m.root, path)
+		if err != nil {
+			return nil
+		}
+		states[filepath.ToSlash(rel)] = fileState{modTime: info.ModTime(), size: info.Size(), isDir: entry.IsDir()}
+		return nil
+	})
+	return states
+}
+
+// reportChanges publishes the differences between two snapshots
+func (m *fileSystemMount) reportChanges(previous, current map[string]fileState) {
+	changes := make(map[string]string)
+	for rel, state := range current {
+		old, existed := previous[rel]
+		switch {
+		case !existed:
+			changes[rel] = "created"
+		case !state.isDir && (state.modTime != old.modTime || state.size != old.size):
+			changes[rel] = "modified"
+		}
+	}
+	for rel := range previous {
+		if _, exists := current[rel]; !exists {
+			changes[rel] = "deleted"
+		}
+	}
+
+	rels := make([]string, 0, len(changes))
+	for rel := range changes {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+
+	updated := make(map[string]bool)
+	for _, rel := range rels {
+		action := changes[rel]
+		uri := m.uri(rel)
+
+		m.server.logger.Debug("file system resource changed", "uri", uri, "action", action)
+		go events.Publish[events.ResourceChangedEvent](m.server.events, events.TopicResourceChanged, events.ResourceChangedEvent{
+			URI:       uri,
+			Action:    action,
+			ChangedAt: time.Now(),
+		})
+
+		if action == "modified" {
+			updated[uri] = true
+		} else {
+			// Creating or deleting an entry changes its directory's listing
+			updated[m.uri(pathDir(rel))] = true
+		}
+	}
+
+	for uri := range updated {
+		m.server.notifyResourceUpdated(uri)
+	}
+}
+
+// pathDir returns the directory of a slash-separated relative path, or "" for the root
+func pathDir(rel string) string {
+	if i := strings.LastIndex(rel, "/"); i >= 0 {
+		return rel[:i]
+	}
+	return ""
+}
+
+// detectMimeType returns the MIME type of a file from its extension, falling back
+// to sniffing its contents
+func detectMimeType(path string, data []byte) string {
+	if mimeType := mime.TypeByExtension(filepath.Ext(path)); mimeType != "" {
+		return mimeType
+	}
+	return http.DetectContentType(data)
+}
+
+// isTextMimeType reports whether content of the MIME type should be returned as text
+func isTextMimeType(mimeType string) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/json" ||
+		mediaType == "application/xml" ||
+		mediaType == "application/javascript" ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml")
+}
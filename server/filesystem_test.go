@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSystemResource(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "notes.md"), []byte("# Notes"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sub", "dir"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "sub", "dir", "data.json"), []byte(`{"a":1}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "image"), []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0xff, 0x00}, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "escape.txt")))
+
+	s := NewServer("fs-test").GetServer()
+	s.SetTransport(&recordingTransport{})
+	s.FileSystemResource(root, WithFileSystemPollInterval(0))
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	require.NoError(t, err)
+	base := "file://" + filepath.ToSlash(realRoot)
+
+	read := func(uri string) (map[string]interface{}, *mcp.ErrorData) {
+		t.Helper()
+		request := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":%q}}`, uri)
+		response, err := s.handleMessage([]byte(request))
+		require.NoError(t, err)
+
+		var decoded struct {
+			Result struct {
+				Contents []map[string]interface{} `json:"contents"`
+			} `json:"result"`
+			Error *struct {
+				Data mcp.ErrorData `json:"data"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(response, &decoded))
+		if decoded.Error != nil {
+			return nil, &decoded.Error.Data
+		}
+		require.Len(t, decoded.Result.Contents, 1)
+		return decoded.Result.Contents[0], nil
+	}
+
+	assert.True(t, s.IsPathInRoots(filepath.Join(realRoot, "notes.md")), "the mounted directory should be a root")
+
+	// The root is listed with its entries
+	content, errData := read(base)
+	require.Nil(t, errData)
+	assert.Equal(t, "application/json", content["mimeType"])
+	var entries []FileSystemEntry
+	require.NoError(t, json.Unmarshal([]byte(content["text"].(string)), &entries))
+	names := map[string]FileSystemEntry{}
+	for _, entry := range entries {
+		names[entry.Name] = entry
+	}
+	assert.True(t, names["sub"].IsDirectory)
+	assert.Equal(t, base+"/notes.md", names["notes.md"].URI)
+	assert.Equal(t, int64(7), names["notes.md"].Size)
+
+	// Nested directories are listed too
+	content, errData = read(base + "/sub/dir")
+	require.Nil(t, errData)
+	assert.Contains(t, content["text"], `"uri":"`+base+`/sub/dir/data.json"`)
+
+	// Text files are returned as text with a detected MIME type
+	content, errData = read(base + "/sub/dir/data.json")
+	require.Nil(t, errData)
+	assert.Equal(t, "application/json", content["mimeType"])
+	assert.Equal(t, `{"a":1}`, content["text"])
+
+	content, errData = read(base + "/notes.md")
+	require.Nil(t, errData)
+	assert.Contains(t, content["mimeType"], "text/markdown")
+
+	// Binary files are returned as blobs, sniffing the MIME type when the extension is unknown
+	content, errData = read(base + "/image")
+	require.Nil(t, errData)
+	assert.Equal(t, "image/png", content["mimeType"])
+	blob, err := base64.StdEncoding.DecodeString(content["blob"].(string))
+	require.NoError(t, err)
+	assert.Len(t, blob, 10)
+	assert.NotContains(t, content, "text")
+
+	// Symlinks and traversal out of the roots are refused
+	_, errData = read(base + "/escape.txt")
+	require.NotNil(t, errData)
+	assert.Equal(t, mcp.ReasonOutsideRoots, errData.Reason)
+	assert.Contains(t, errData.Message, "outside of roots")
+	assert.NotEmpty(t, errData.Hint)
+	_, errData = read(base + "/sub/../../" + filepath.Base(outside) + "/secret.txt")
+	assert.NotNil(t, errData)
+
+	_, errData = read(base + "/missing.txt")
+	require.NotNil(t, errData)
+	assert.Equal(t, mcp.ReasonResourceNotFound, errData.Reason)
+	assert.Contains(t, errData.Message, "not found")
+}
+
+func TestFileSystemResourceChangeNotifications(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "watched.txt")
+	require.NoError(t, os.WriteFile(file, []byte("v1"), 0644))
+
+	s := NewServer("fs-watch-test").GetServer()
+	transport := &recordingTransport{}
+	s.SetTransport(transport)
+	defer s.Shutdown()
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	require.NoError(t, err)
+	uri := "file://" + filepath.ToSlash(realRoot) + "/watched.txt"
+
+	s.sessionManager.UpdateSession(s.defaultSession.ID, func(session *ClientSession) {
+		session.ResourceSubscriptions = append(session.ResourceSubscriptions, uri)
+	})
+
+	s.FileSystemResource(root, WithFileSystemPollInterval(10*time.Millisecond))
+	time.Sleep(30 * time.Millisecond)
+
+	// Change the size as well as the modification time, as some file systems
+	// have coarse timestamps
+	require.NoError(t, os.WriteFile(file, []byte("version 2"), 0644))
+
+	assert.Eventually(t, func() bool {
+		return transport.sentContaining(`"method":"notifications/resources/updated"`) &&
+			transport.sentContaining(uri)
+	}, time.Second, 10*time.Millisecond)
+}
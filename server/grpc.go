@@ -54,6 +54,12 @@ func WithGRPCMaxMessageSize(size int) grpc.Option {
 	return grpc.WithMaxMessageSize(size)
 }
 
+// WithGRPCReflection enables gRPC server reflection, so clients can discover the MCP
+// service with client.DiscoverGRPCServices or tools such as grpcurl.
+func WithGRPCReflection() grpc.Option {
+	return grpc.WithReflection()
+}
+
 // DefaultGRPCServerOptions returns a set of default options for gRPC server.
 func DefaultGRPCServerOptions() []grpc.Option {
 	return []grpc.Option{
@@ -14,6 +14,11 @@ import (
 //
 // Returns:
 //   - The server instance for method chaining
+//
+// Example usage:
+//
+//	// Serve HTTPS and require client certificates signed by ca.pem
+//	server.AsHTTP(":8443", http.WithTLS("cert.pem", "key.pem"), http.WithMutualTLS("ca.pem"))
 func (s *serverImpl) AsHTTP(address string, options ...http.Option) Server {
 	s.mu.Lock()
 	defer s.mu.Unlock()
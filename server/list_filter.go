@@ -0,0 +1,114 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/localrivet/gomcp/mcp"
+)
+
+// ListFilter is the optional "filter" parameter of tools/list, resources/list
+// and prompts/list requests. It is a gomcp extension that lets clients facing
+// servers with many entries narrow the list before it is sent over the wire.
+// Clients that do not send a filter receive the full list as usual.
+type ListFilter struct {
+	// NamePrefix restricts the list to entries whose name (URI for resources) starts with this prefix
+	NamePrefix string `json:"namePrefix,omitempty"`
+
+	// Tags restricts the list to entries carrying all of these tags
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Matches reports whether an entry with the given name and annotations satisfies the filter.
+// A nil filter matches everything.
+func (f *ListFilter) Matches(name string, annotations map[string]interface{}) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.NamePrefix != "" && !strings.HasPrefix(name, f.NamePrefix) {
+		return false
+	}
+
+	return mcp.HasAllTags(annotations, f.Tags)
+}
+
+// WithTags returns an annotations map that tags a tool or resource with categories.
+// Tags are exposed under the "tags" annotation in list responses and can be
+// used by clients to filter list results. Prompts are tagged with TagPrompt.
+//
+// Example:
+//
+//	server.Tool("read_file", "Read a file", handler, server.WithTags("files", "read-only"))
+func WithTags(tags ...string) map[string]interface{} {
+	return map[string]interface{}{
+		mcp.TagsAnnotation: append([]string(nil), tags...),
+	}
+}
+
+// WithExample returns an annotations map that attaches an example call to a tool:
+// the arguments it is called with, the result it returns (nil for none) and what
+// the example shows. Examples are exposed under the "examples" annotation in list
+// responses and as the "examples" of the tool's input schema, so that models see
+// how the tool is meant to be called. Give WithExample several times for several
+// examples.
+//
+// Example:
+//
+//	server.Tool("convert", "Convert between currencies", handler,
+//	    server.WithExample(ConvertArgs{Amount: 10, From: "USD", To: "EUR"},
+//	        map[string]interface{}{"amount": 9.2}, "Convert dollars to euros"))
+func WithExample(args, result interface{}, description string) map[string]interface{} {
+	return map[string]interface{}{
+		mcp.ExamplesAnnotation: []mcp.ToolExample{{Description: description, Input: args, Output: result}},
+	}
+}
+
+// mergeAnnotations merges annotation maps in order, later keys overriding earlier ones.
+// Tags, examples and icons from every map are combined rather than overridden.
+func mergeAnnotations(annotations ...map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	var tags []string
+	var examples []mcp.ToolExample
+	var icons []mcp.Icon
+	for _, annotationMap := range annotations {
+		for k, v := range annotationMap {
+			merged[k] = v
+		}
+		tags = append(tags, mcp.TagsFromAnnotations(annotationMap)...)
+		examples = append(examples, mcp.ExamplesFromAnnotations(annotationMap)...)
+		icons = append(icons, mcp.DisplayFromAnnotations(annotationMap).Icons...)
+	}
+
+	if len(tags) > 0 {
+		merged[mcp.TagsAnnotation] = tags
+	}
+	if len(examples) > 0 {
+		merged[mcp.ExamplesAnnotation] = examples
+	}
+	if len(icons) > 0 {
+		merged[mcp.IconsAnnotation] = icons
+	}
+
+	return merged
+}
+
+// schemaWithExamples returns a copy of a tool's input schema listing the inputs of
+// the tool's examples under the JSON Schema "examples" keyword, or the schema itself
+// if the tool has no examples
+func schemaWithExamples(schema, annotations map[string]interface{}) map[string]interface{} {
+	examples := mcp.ExamplesFromAnnotations(annotations)
+	if len(examples) == 0 || schema == nil {
+		return schema
+	}
+
+	withExamples := make(map[string]interface{}, len(schema)+1)
+	for k, v := range schema {
+		withExamples[k] = v
+	}
+	inputs := make([]interface{}, len(examples))
+	for i, example := range examples {
+		inputs[i] = example.Input
+	}
+	withExamples["examples"] = inputs
+	return withExamples
+}
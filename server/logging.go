@@ -2,31 +2,109 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+
+	"github.com/localrivet/gomcp/mcp"
 )
 
+// defaultClientLogLevel is the minimum level sent to clients that have not called logging/setLevel
+const defaultClientLogLevel = mcp.LogLevelInfo
+
 // ProcessLoggingSetLevel processes a logging set level request.
-// This method handles client requests to change the server's logging level,
-// allowing dynamic control of log verbosity during server operation.
+// This method handles client requests to change the minimum level of log messages
+// the server sends to them with notifications/message. The level applies to the
+// requesting client's session only.
 //
 // Parameters:
 //   - ctx: The request context containing client information and request details
 //
 // Returns:
-//   - A success response if the log level was updated
+//   - An empty result if the log level was updated
 //   - An error if the request is invalid or the operation fails
 func (s *serverImpl) ProcessLoggingSetLevel(ctx *Context) (interface{}, error) {
+	if ctx.Request.Params == nil {
+		return nil, errors.New("missing params in logging/setLevel request")
+	}
+
 	// Parse the request
 	var params struct {
-		Level string `json:"level"`
+		Level mcp.LogLevel `json:"level"`
 	}
 	if err := json.Unmarshal(ctx.Request.Params, &params); err != nil {
 		return nil, fmt.Errorf("invalid params: %w", err)
 	}
+	if !params.Level.IsValid() {
+		return nil, &InvalidParametersError{
+			Message: fmt.Sprintf("invalid log level: %q", params.Level),
+			Hint:    "use one of debug, info, notice, warning, error, critical, alert or emergency",
+		}
+	}
+
+	s.setSessionLogLevel(ctx.Session, params.Level)
+	s.logger.Debug("client log level set", "level", params.Level)
+
+	return map[string]interface{}{}, nil
+}
+
+// setSessionLogLevel records the minimum log level a session wants to receive
+func (s *serverImpl) setSessionLogLevel(session *ClientSession, level mcp.LogLevel) {
+	if session == nil {
+		return
+	}
 
-	// Update the logger level
-	// TODO: Implement proper level setting
-	s.logger.Debug("setting log level", "level", params.Level)
+	s.logLevelMu.Lock()
+	defer s.logLevelMu.Unlock()
+	s.logLevels[session.ID] = level
+}
 
-	return map[string]interface{}{"success": true}, nil
+// sessionLogLevel returns the minimum log level a session wants to receive
+func (s *serverImpl) sessionLogLevel(session *ClientSession) mcp.LogLevel {
+	if session == nil {
+		return defaultClientLogLevel
+	}
+
+	s.logLevelMu.RLock()
+	defer s.logLevelMu.RUnlock()
+	if level, ok := s.logLevels[session.ID]; ok {
+		return level
+	}
+	return defaultClientLogLevel
+}
+
+// Log sends a log message to the client with notifications/message.
+//
+// Messages below the level the client chose with logging/setLevel (info by default)
+// are dropped. The message is sent under the "message" key of the notification's
+// data, alongside the entries of data.
+//
+// Example:
+//
+//	ctx.Log(mcp.LogLevelWarning, "cache miss", map[string]interface{}{"key": key})
+func (c *Context) Log(level mcp.LogLevel, msg string, data map[string]interface{}) error {
+	if !level.IsValid() {
+		return fmt.Errorf("invalid log level: %q", level)
+	}
+	if c.server == nil {
+		return errors.New("cannot log: context has no server")
+	}
+
+	if !level.AtLeast(c.server.sessionLogLevel(c.Session)) {
+		return nil
+	}
+
+	payload := make(map[string]interface{}, len(data)+1)
+	for key, value := range data {
+		payload[key] = value
+	}
+	payload["message"] = msg
+
+	if err := c.server.writeSessionNotification(c.Session, "notifications/message", map[string]interface{}{
+		"level":  level,
+		"logger": c.server.name,
+		"data":   payload,
+	}); err != nil {
+		c.server.logger.Error("failed to send notification", "method", "notifications/message", "error", err)
+	}
+	return nil
 }
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextLogHonorsSetLevel(t *testing.T) {
+	s := NewServer("logging-test").GetServer()
+	tr := &recordingTransport{}
+	s.SetTransport(tr)
+
+	s.Tool("work", "Logs at two levels", func(ctx *Context, args struct{}) (interface{}, error) {
+		if err := ctx.Log(mcp.LogLevelDebug, "debug detail", nil); err != nil {
+			return nil, err
+		}
+		return "ok", ctx.Log(mcp.LogLevelWarning, "running low", map[string]interface{}{"remaining": 3})
+	})
+
+	call := func() {
+		t.Helper()
+		response, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"work","arguments":{}}}`))
+		require.NoError(t, err)
+		require.NotContains(t, string(response), `"error"`)
+	}
+
+	// Messages below the default info level are dropped
+	call()
+	assert.True(t, tr.sentContaining(`"message":"running low"`))
+	assert.True(t, tr.sentContaining(`"remaining":3`))
+	assert.False(t, tr.sentContaining("debug detail"))
+
+	response, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":2,"method":"logging/setLevel","params":{"level":"debug"}}`))
+	require.NoError(t, err)
+	assert.Contains(t, string(response), `"result":{}`)
+
+	call()
+	assert.True(t, tr.sentContaining(`"level":"debug"`))
+	assert.True(t, tr.sentContaining("debug detail"))
+
+	// Unknown levels are rejected
+	response, err = s.handleMessage([]byte(`{"jsonrpc":"2.0","id":3,"method":"logging/setLevel","params":{"level":"verbose"}}`))
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "-32602")
+}
@@ -6,9 +6,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/localrivet/gomcp/events"
 	"github.com/localrivet/gomcp/mcp"
+	"github.com/localrivet/gomcp/transport"
 )
 
 // handleMessage processes incoming JSON-RPC messages from clients.
@@ -16,35 +19,76 @@ import (
 // For requests, it calls HandleMessage to process them; for responses, it calls
 // HandleJSONRPCResponse to match them with pending requests.
 func (s *serverImpl) handleMessage(message []byte) ([]byte, error) {
-	// Check if this is a response (has no "method" field but has "id")
-	var msg map[string]interface{}
-	if err := json.Unmarshal(message, &msg); err == nil {
-		if _, hasMethod := msg["method"]; !hasMethod {
-			if _, hasID := msg["id"]; hasID {
-				// This is a response, process it differently
-				if err := s.HandleJSONRPCResponse(message); err != nil {
-					s.logger.Error("failed to handle JSON-RPC response", "error", err)
-				}
-				return nil, nil
+	return s.handleMessageContext(context.Background(), message)
+}
+
+// handleSessionMessage processes a message that a multi-session transport received
+// on the given session, so that requests use that session's state
+func (s *serverImpl) handleSessionMessage(sessionID string, message []byte) ([]byte, error) {
+	return s.handleMessageContext(withTransportSession(context.Background(), SessionID(sessionID)), message)
+}
+
+// handleMessageContext routes a message like handleMessage, passing ctx to request handling
+func (s *serverImpl) handleMessageContext(ctx context.Context, message []byte) ([]byte, error) {
+	// Check if this is a response (has no "method" field but has "id"). Only the
+	// envelope is decoded here; params are decoded once, by the request handler.
+	envelope, err := transport.ParseEnvelope(message)
+	if err == nil {
+		hasMethod, hasID := envelope.HasMethod, envelope.HasID()
+		if !hasMethod && hasID {
+			// This is a response, process it differently
+			if err := s.handleJSONRPCResponse(ctx, message); err != nil {
+				s.logger.Error("failed to handle JSON-RPC response", "error", err)
 			}
+			return nil, nil
+		}
+		if hasMethod && !hasID {
+			// Notifications such as cancellations are still handled while draining
+			return handleMessageContext(ctx, s, message)
 		}
 	}
 
-	// This is a request, process normally
-	return HandleMessage(s, message)
+	// This is a request, track it so that shutdown can wait for it to finish
+	if !s.beginRequest() {
+		var id interface{}
+		if envelope.HasID() {
+			id = envelope.ID
+		}
+		return createErrorResponse(id, -32000, "Server shutting down", ErrServerShuttingDown.Error()), nil
+	}
+	defer s.endRequest()
+
+	if s.requestPool.enabled() {
+		if isBatchMessage(message) {
+			// Each request of the batch takes a place in the pool of its own
+			s.requestPool.unreserve()
+			return handleMessageContext(ctx, s, message)
+		}
+		var id interface{}
+		if envelope.HasID() {
+			id = envelope.ID
+		}
+		return s.handlePooled(ctx, id, message)
+	}
+	return handleMessageContext(ctx, s, message)
 }
 
 // HandleMessage handles an incoming message from the transport.
 // It parses the message, routes it to the appropriate handler, and returns the response.
 // Supports both single JSON-RPC messages and batch messages (arrays) as required by the MCP specification.
 func HandleMessage(s *serverImpl, message []byte) ([]byte, error) {
+	return handleMessageContext(context.Background(), s, message)
+}
+
+// handleMessageContext handles a message like HandleMessage, creating request contexts from ctx
+func handleMessageContext(ctx context.Context, s *serverImpl, message []byte) ([]byte, error) {
 	// Detect if this is a batch message (JSON array) or single message (JSON object)
 	if isBatchMessage(message) {
-		return handleBatchMessage(s, message)
+		return handleBatchMessage(ctx, s, message)
 	}
 
 	// Handle single message (existing logic)
-	return handleSingleMessage(s, message)
+	return handleSingleMessage(ctx, s, message)
 }
 
 // isBatchMessage determines if the incoming message is a JSON array (batch) or single object
@@ -54,8 +98,21 @@ func isBatchMessage(message []byte) bool {
 	return len(trimmed) > 0 && trimmed[0] == '['
 }
 
+// DefaultBatchConcurrency is how many messages of a JSON-RPC batch are processed
+// at once by default.
+const DefaultBatchConcurrency = 8
+
+// WithBatchConcurrency sets how many messages of a JSON-RPC batch are processed at
+// once. Responses are returned in the order of the requests whatever the limit;
+// one processes batches sequentially.
+func WithBatchConcurrency(n int) Option {
+	return func(s *serverImpl) {
+		s.batchConcurrency = n
+	}
+}
+
 // handleBatchMessage processes a JSON-RPC batch message according to the JSON-RPC 2.0 specification
-func handleBatchMessage(s *serverImpl, message []byte) ([]byte, error) {
+func handleBatchMessage(ctx context.Context, s *serverImpl, message []byte) ([]byte, error) {
 	// Parse the batch array
 	var batch []json.RawMessage
 	if err := json.Unmarshal(message, &batch); err != nil {
@@ -69,11 +126,36 @@ func handleBatchMessage(s *serverImpl, message []byte) ([]byte, error) {
 		return createErrorResponse(nil, -32600, "Invalid Request", "Batch cannot be empty"), nil
 	}
 
-	// Process each message in the batch
+	// Batches are only part of the protocol from 2025-03-26 on
+	if version := s.sessionProtocolVersion(s.sessionForContext(ctx)); !supportsBatching(version) {
+		return createErrorResponse(nil, -32600, "Invalid Request",
+			fmt.Sprintf("Batch messages are not supported in protocol version %s", version)), nil
+	}
+
+	// Process the messages concurrently, keeping the responses in the order of the
+	// requests and skipping notifications, which get none
+	results := make([]interface{}, len(batch))
+	limit := s.batchConcurrency
+	if limit <= 0 {
+		limit = DefaultBatchConcurrency
+	}
+	slots := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, rawMessage := range batch {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int, rawMessage json.RawMessage) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			results[i] = processBatchItem(ctx, s, rawMessage)
+		}(i, rawMessage)
+	}
+	wg.Wait()
+
 	var responses []interface{}
-	for _, rawMessage := range batch {
-		response := processBatchItem(s, rawMessage)
-		// Only add responses for requests (not notifications)
+	for _, response := range results {
 		if response != nil {
 			responses = append(responses, response)
 		}
@@ -95,36 +177,61 @@ func handleBatchMessage(s *serverImpl, message []byte) ([]byte, error) {
 }
 
 // processBatchItem processes a single item within a batch and returns the response (or nil for notifications)
-func processBatchItem(s *serverImpl, rawMessage json.RawMessage) interface{} {
-	// Process the individual message
-	responseBytes, _ := handleSingleMessage(s, rawMessage)
+func processBatchItem(ctx context.Context, s *serverImpl, rawMessage json.RawMessage) interface{} {
+	// Items that are not objects are invalid requests, answered with a null ID
+	if trimmed := bytes.TrimSpace(rawMessage); len(trimmed) == 0 || trimmed[0] != '{' {
+		return mcp.NewErrorResponse(nil, -32600, "Invalid Request", "Batch items must be objects")
+	}
+
+	// Process the individual message, requests on the request pool if there is one
+	var responseBytes []byte
+	if envelope, err := transport.ParseEnvelope(rawMessage); err == nil && envelope.HasMethod && envelope.HasID() && s.requestPool.enabled() {
+		responseBytes, _ = s.handlePooled(ctx, envelope.ID, rawMessage)
+	} else {
+		responseBytes, _ = handleSingleMessage(ctx, s, rawMessage)
+	}
 
 	// If there's no response (notification), return nil
 	if responseBytes == nil {
 		return nil
 	}
 
-	// Parse the response back to an object for inclusion in the batch response
-	var response interface{}
-	if err := json.Unmarshal(responseBytes, &response); err != nil {
-		// If we can't parse the response, create an error response using structs
-		s.logger.Error("failed to parse individual response in batch", "error", err)
+	// The response is already JSON; include it in the batch response as is
+	if !json.Valid(responseBytes) {
+		// If the response is not valid JSON, create an error response using structs
+		s.logger.Error("invalid individual response in batch")
 		errorResp := mcp.NewErrorResponse(nil, -32603, "Internal error", "Failed to parse individual response")
 		return errorResp
 	}
 
-	return response
+	return json.RawMessage(responseBytes)
 }
 
 // handleSingleMessage processes a single JSON-RPC message (extracted from original HandleMessage logic)
-func handleSingleMessage(s *serverImpl, message []byte) ([]byte, error) {
+func handleSingleMessage(goCtx context.Context, s *serverImpl, message []byte) ([]byte, error) {
 	// Create a new context with the incoming message
-	ctx, err := NewContext(context.Background(), message, s)
+	ctx, err := NewContext(goCtx, message, s)
 	if err != nil {
 		s.logger.Error("failed to create context", "error", err)
 		return createErrorResponse(nil, -32700, "Parse error", err.Error()), nil
 	}
 
+	// Let a notifications/cancelled for this request cancel its handler's context
+	if ctx.Request.ID != nil && ctx.Request.Method != "" {
+		var release func()
+		ctx.ctx, release = s.requestCanceller.WithCancellation(ctx.ctx, cancellationKey(ctx.Session, ctx.RequestID))
+		ctx.cancelled = ctx.ctx.Done()
+		defer release()
+		defer s.releaseProgressTokens(ctx)
+		s.trackRequestProgressToken(ctx)
+	}
+
+	// Notifications never get a response
+	if ctx.Request.ID == nil && strings.HasPrefix(ctx.Request.Method, "notifications/") {
+		s.handleNotification(ctx, message)
+		return nil, nil
+	}
+
 	var result interface{}
 
 	// Process the message based on its method
@@ -173,38 +280,35 @@ func handleSingleMessage(s *serverImpl, message []byte) ([]byte, error) {
 		result, err = s.ProcessSamplingCreateMessage(ctx)
 	case "roots/list":
 		// This is typically a client method that the server calls
-		err = fmt.Errorf("method not implemented: %s", ctx.Request.Method)
+		err = clientCapabilityError(ctx.Request.Method, "roots")
 
-	// Notifications
-	case "notifications/initialized":
-		// The client has finished initialization, process any pending notifications
-		// Run asynchronously to avoid potential deadlocks with mutex acquisition\n\t\tgo s.handleInitializedNotification()
-		return nil, nil
-	case "notifications/cancelled":
-		// Handle cancellation notification
-		if err := s.HandleCancelledNotification(message); err != nil {
-			s.logger.Error("failed to handle cancellation notification", "error", err)
+	default:
+		if ctx.Request.ID == nil && ctx.Request.Method != "" {
+			// A notification with a method that is not part of the protocol
+			s.handleNotification(ctx, message)
+			return nil, nil
 		}
-		return nil, nil
-	case "notifications/progress":
-		// Handle progress notification
-		if err := s.HandleProgressNotification(message); err != nil {
-			s.logger.Error("failed to handle progress notification", "error", err)
+		if handler, ok := s.methodHandler(ctx.Request.Method); ok {
+			result, err = handler(ctx, ctx.Request.Params)
+			break
 		}
-		return nil, nil
-	case "notifications/message":
-	case "notifications/resources/list_changed":
-	case "notifications/resources/updated":
-	case "notifications/tools/list_changed":
-	case "notifications/prompts/list_changed":
-	case "notifications/roots/list_changed":
-		// Handle roots list changed notification by fetching updated roots from client
-		s.fetchWorkspaceRoots()
-		// Notifications don't need responses
-		return nil, nil
+		err = &RemediableError{
+			Code:    -32601,
+			Reason:  mcp.ReasonMethodNotFound,
+			Message: fmt.Sprintf("method not found: %s", ctx.Request.Method),
+			Hint:    "check the method name against the capabilities in the initialize response; custom methods are listed under experimental",
+		}
+	}
 
-	default:
-		err = fmt.Errorf("method not found: %s", ctx.Request.Method)
+	// Remember what the client was sent, so that drift can be reconciled
+	if err == nil {
+		s.recordListView(ctx)
+	}
+
+	// Cancelled requests get no response, as the client has stopped waiting for it
+	if errors.Is(context.Cause(ctx.ctx), ErrRequestCancelled) {
+		s.logger.Debug("dropping response of cancelled request", "requestId", ctx.RequestID, "method", ctx.Request.Method)
+		return nil, nil
 	}
 
 	// Handle errors
@@ -218,21 +322,11 @@ func handleSingleMessage(s *serverImpl, message []byte) ([]byte, error) {
 			})
 		}()
 
-		// Determine the appropriate error code based on error type
-		var errorCode int
-		var errorMessage string
-
-		// Check if this is an InvalidParametersError
-		if _, ok := err.(*InvalidParametersError); ok {
-			errorCode = -32602 // Invalid params
-			errorMessage = "Invalid params"
-		} else {
-			errorCode = -32603 // Internal error
-			errorMessage = "Internal error"
-		}
+		// Errors with a reason carry it in the data, along with a hint on fixing them
+		errorCode, errorMessage, errorData := errorResponseFor(err)
 
 		// Return error response
-		return createErrorResponse(ctx.Request.ID, errorCode, errorMessage, err.Error()), nil
+		return createErrorResponse(ctx.Request.ID, errorCode, errorMessage, errorData), nil
 	}
 
 	// Check if this is a notification (no ID)
@@ -241,6 +335,9 @@ func handleSingleMessage(s *serverImpl, message []byte) ([]byte, error) {
 		return nil, nil
 	}
 
+	// Convert the result into the shape of the session's protocol version
+	result = adaptResultForVersion(ctx.Request.Method, result, ctx.protocolVersion())
+
 	// Create success response using structs
 	response := mcp.NewSuccessResponse(ctx.Request.ID, result)
 	responseBytes, err := response.Marshal()
@@ -327,7 +424,7 @@ func HandleMessageWithVersion(srv Server, message []byte, version string) ([]byt
 		return errorResp.Marshal()
 	}
 
-	// Return the result using structs
-	response := mcp.NewSuccessResponse(request.ID, result)
+	// Return the result using structs, in the shape of the forced version
+	response := mcp.NewSuccessResponse(request.ID, adaptResultForVersion(request.Method, result, version))
 	return response.Marshal()
 }
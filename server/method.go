@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// MethodHandler handles a request for a custom JSON-RPC method. The params
+// parameter holds the request's raw params, if any. The result is sent back as the
+// response's result; returning an InvalidParametersError answers with an invalid
+// params error, and any other error with an internal error.
+type MethodHandler func(ctx *Context, params json.RawMessage) (interface{}, error)
+
+// protocolMethods lists the request methods the server implements itself, which
+// cannot be registered as custom methods
+var protocolMethods = map[string]bool{
+	"initialize":               true,
+	"shutdown":                 true,
+	"ping":                     true,
+	"tools/list":               true,
+	"tools/call":               true,
+	"resources/list":           true,
+	"resources/read":           true,
+	"resources/subscribe":      true,
+	"resources/unsubscribe":    true,
+	"resources/templates/list": true,
+	"prompts/list":             true,
+	"prompts/get":              true,
+	"logging/setLevel":         true,
+	"completion/complete":      true,
+	"sampling/createMessage":   true,
+	"roots/list":               true,
+	"elicitation/create":       true,
+}
+
+// Method registers a handler for a custom JSON-RPC method, for vendor
+// extensions the protocol does not cover.
+//
+// Custom methods are advertised as keys of the experimental capability in the
+// initialize response, and are called with client.Call. They should be
+// namespaced, such as "x-myorg/reindex", so that they do not clash with future
+// protocol methods. Methods of the protocol and notifications cannot be
+// registered.
+//
+// Example:
+//
+//	server.Method("x-myorg/reindex", func(ctx *server.Context, params json.RawMessage) (interface{}, error) {
+//	    return index.Rebuild()
+//	})
+func (s *serverImpl) Method(name string, handler MethodHandler) Server {
+	switch {
+	case handler == nil:
+		s.logger.Error("method handler cannot be nil", "method", name)
+		return s
+	case name == "" || protocolMethods[name] || strings.HasPrefix(name, "notifications/"):
+		s.logger.Error("cannot register a protocol method as a custom method", "method", name)
+		return s
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.methods == nil {
+		s.methods = make(map[string]MethodHandler)
+	}
+	s.methods[name] = handler
+	return s
+}
+
+// methodHandler returns the handler of a custom method, if one is registered
+func (s *serverImpl) methodHandler(name string) (MethodHandler, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	handler, ok := s.methods[name]
+	return handler, ok
+}
+
+// customMethods returns the names of the custom methods registered
+func (s *serverImpl) customMethods() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.methods))
+	for name := range s.methods {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomMethods(t *testing.T) {
+	s := NewServer("method-test").GetServer()
+	s.SetTransport(&recordingTransport{})
+	s.Method("x-myorg/reindex", func(ctx *Context, params json.RawMessage) (interface{}, error) {
+		var args struct {
+			Collection string `json:"collection"`
+		}
+		if err := json.Unmarshal(params, &args); err != nil || args.Collection == "" {
+			return nil, NewInvalidParametersError("collection is required")
+		}
+		return map[string]interface{}{"reindexed": args.Collection}, nil
+	})
+	s.Method("tools/list", func(ctx *Context, params json.RawMessage) (interface{}, error) {
+		return "hijacked", nil
+	})
+
+	send := func(message string) string {
+		t.Helper()
+		response, err := s.handleMessage([]byte(message))
+		require.NoError(t, err)
+		return string(response)
+	}
+
+	var initialize struct {
+		Result struct {
+			Capabilities struct {
+				Experimental map[string]interface{} `json:"experimental"`
+			} `json:"capabilities"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)), &initialize))
+	assert.Equal(t, map[string]interface{}{"x-myorg/reindex": map[string]interface{}{}}, initialize.Result.Capabilities.Experimental)
+
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"result":{"reindexed":"docs"}}`,
+		send(`{"jsonrpc":"2.0","id":2,"method":"x-myorg/reindex","params":{"collection":"docs"}}`))
+	assert.Contains(t, send(`{"jsonrpc":"2.0","id":3,"method":"x-myorg/reindex","params":{}}`), `"code":-32602`)
+	assert.NotContains(t, send(`{"jsonrpc":"2.0","id":4,"method":"tools/list"}`), "hijacked",
+		"Expected protocol methods not to be replaceable")
+	assert.Contains(t, send(`{"jsonrpc":"2.0","id":5,"method":"x-myorg/unknown"}`), "method not found")
+}
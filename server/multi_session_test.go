@@ -0,0 +1,69 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentSessionsKeepTheirOwnState(t *testing.T) {
+	s := NewServer("multi-session-test").GetServer()
+	tr := &sharedChannelTransport{}
+	s.SetTransport(tr)
+	defaultSessionID := s.defaultSession.ID
+
+	s.Tool("whoami", "Describe the calling session", func(ctx *Context, args struct{}) (string, error) {
+		if err := ctx.Log(mcp.LogLevelInfo, "whoami called", nil); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s", ctx.Version, strings.Join(ctx.Session.Roots(), ",")), nil
+	})
+	s.Resource("/status", "Status", func(ctx *Context, args interface{}) (interface{}, error) { return "ok", nil })
+
+	send := func(t *testing.T, sessionID, request string) string {
+		t.Helper()
+		response, err := s.handleSessionMessage(sessionID, []byte(request))
+		require.NoError(t, err)
+		return string(response)
+	}
+	send(t, "client-a", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"a","version":"1.0","roots":[{"uri":"file:///work/a"}]}}}`)
+	send(t, "client-b", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"b","version":"1.0","roots":[{"uri":"file:///work/b"}]}}}`)
+
+	t.Run("sessions", func(t *testing.T) {
+		// Neither client takes over the default session or the server's roots
+		assert.Equal(t, defaultSessionID, s.defaultSession.ID)
+		assert.NotContains(t, s.GetRoots(), "/work/a")
+		assert.NotContains(t, s.GetRoots(), "/work/b")
+
+		assert.Contains(t, send(t, "client-a", `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"whoami","arguments":{}}}`), "2024-11-05 /work/a")
+		assert.Contains(t, send(t, "client-b", `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"whoami","arguments":{}}}`), "2025-03-26 /work/b")
+	})
+
+	t.Run("notifications", func(t *testing.T) {
+		// Log messages reach the client that made the request only
+		for _, sessionID := range []string{"client-a", "client-b"} {
+			var logs int
+			for _, message := range tr.sentTo(sessionID) {
+				if strings.Contains(message, "whoami called") {
+					logs++
+				}
+			}
+			assert.Equal(t, 1, logs, sessionID)
+		}
+
+		// Resource updates reach the subscribed client only
+		send(t, "client-a", `{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"/status"}}`)
+		s.notifyResourceUpdated("/status")
+		assert.Contains(t, strings.Join(tr.sentTo("client-a"), "\n"), "notifications/resources/updated")
+		assert.NotContains(t, strings.Join(tr.sentTo("client-b"), "\n"), "notifications/resources/updated")
+
+		tr.mu.Lock()
+		defer tr.mu.Unlock()
+		assert.NotContains(t, strings.Join(tr.sent, "\n"), "whoami called")
+		assert.NotContains(t, strings.Join(tr.sent, "\n"), "notifications/resources/updated")
+	})
+}
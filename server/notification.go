@@ -0,0 +1,279 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+)
+
+// NotificationHandler handles a notification from a client.
+// The params parameter holds the notification's raw params, if any.
+type NotificationHandler func(ctx *Context, params json.RawMessage) error
+
+// OnNotification registers a custom handler for client notifications.
+//
+// The handler runs after any built-in handling of the method, so it can also
+// observe notifications such as notifications/roots/list_changed. Notifications
+// that neither the server nor a custom handler handles are logged at debug
+// level, counted in UnknownMethodCounts and published as an UnknownMethodEvent.
+//
+// Example:
+//
+//	server.OnNotification("notifications/vendor/heartbeat", func(ctx *server.Context, params json.RawMessage) error {
+//	    lastHeartbeat.Store(time.Now())
+//	    return nil
+//	})
+func (s *serverImpl) OnNotification(method string, handler NotificationHandler) Server {
+	if handler == nil {
+		s.logger.Error("notification handler cannot be nil", "method", method)
+		return s
+	}
+
+	s.notificationMu.Lock()
+	defer s.notificationMu.Unlock()
+
+	s.notificationHandlers[method] = handler
+	return s
+}
+
+// maxUnknownMethods caps how many unknown notification methods are counted separately
+const maxUnknownMethods = 256
+
+// otherUnknownMethods counts unknown notifications received once maxUnknownMethods
+// methods are already counted
+const otherUnknownMethods = "(other)"
+
+// UnknownMethodCounts returns how many notifications of each unknown method were received.
+// Once 256 methods are counted, notifications of further methods are counted as "(other)".
+func (s *serverImpl) UnknownMethodCounts() map[string]int64 {
+	s.notificationMu.Lock()
+	defer s.notificationMu.Unlock()
+
+	counts := make(map[string]int64, len(s.unknownMethods))
+	for method, count := range s.unknownMethods {
+		counts[method] = count
+	}
+	return counts
+}
+
+// handleNotification processes a client notification. Notifications never get a
+// response, so failures are logged rather than returned.
+func (s *serverImpl) handleNotification(ctx *Context, message []byte) {
+	known := true
+
+	switch ctx.Request.Method {
+	case "notifications/initialized":
+		// The client has finished initialization, process any pending notifications
+		// Run asynchronously to avoid potential deadlocks with mutex acquisition
+		go s.handleInitializedNotification(ctx.Session)
+	case "notifications/cancelled":
+		if err := s.handleCancelledNotification(ctx.Session, message); err != nil {
+			s.logger.Error("failed to handle cancellation notification", "error", err)
+		}
+	case "notifications/progress":
+		if err := s.HandleProgressNotification(message); err != nil {
+			s.logger.Error("failed to handle progress notification", "error", err)
+		}
+	case "notifications/roots/list_changed":
+		// Fetch the updated roots from the client
+		s.fetchWorkspaceRoots(ctx.Session)
+	case "notifications/message",
+		"notifications/resources/list_changed",
+		"notifications/resources/updated",
+		"notifications/tools/list_changed",
+		"notifications/prompts/list_changed":
+		// Server-to-client notifications; nothing to do when a client echoes them
+	default:
+		known = false
+	}
+
+	s.notificationMu.Lock()
+	handler, hasHandler := s.notificationHandlers[ctx.Request.Method]
+	s.notificationMu.Unlock()
+
+	if hasHandler {
+		if err := handler(ctx, ctx.Request.Params); err != nil {
+			s.logger.Error("notification handler failed", "method", ctx.Request.Method, "error", err)
+		}
+		return
+	}
+
+	if !known {
+		s.recordUnknownNotification(ctx)
+	}
+}
+
+// recordUnknownNotification logs, counts and publishes a notification nobody handles
+func (s *serverImpl) recordUnknownNotification(ctx *Context) {
+	method := ctx.Request.Method
+
+	// Method names come from clients, so only so many are counted separately
+	counted := method
+	s.notificationMu.Lock()
+	if _, ok := s.unknownMethods[method]; !ok && len(s.unknownMethods) >= maxUnknownMethods {
+		counted = otherUnknownMethods
+	}
+	s.unknownMethods[counted]++
+	count := s.unknownMethods[counted]
+	s.notificationMu.Unlock()
+
+	var sessionID string
+	if ctx.Session != nil {
+		sessionID = string(ctx.Session.ID)
+	}
+
+	s.logger.Debug("ignoring unknown notification", "method", method, "sessionID", sessionID, "count", count)
+
+	go events.Publish[events.UnknownMethodEvent](s.events, events.TopicUnknownMethod, events.UnknownMethodEvent{
+		Method:     method,
+		SessionID:  sessionID,
+		Count:      count,
+		ReceivedAt: time.Now(),
+	})
+}
+
+// ErrInvalidNotification is returned by Notify and Broadcast for notifications that
+// the negotiated protocol version does not allow servers to send as custom ones.
+var ErrInvalidNotification = errors.New("invalid notification")
+
+// protocolNotifications maps the notifications the protocol defines to the version
+// that introduced them. The library sends these itself, with the params each
+// version expects, so they cannot be sent as custom notifications.
+var protocolNotifications = map[string]string{
+	"notifications/initialized":            "2024-11-05",
+	"notifications/cancelled":              "2024-11-05",
+	"notifications/progress":               "2024-11-05",
+	"notifications/message":                "2024-11-05",
+	"notifications/resources/updated":      "2024-11-05",
+	"notifications/resources/list_changed": "2024-11-05",
+	"notifications/tools/list_changed":     "2024-11-05",
+	"notifications/prompts/list_changed":   "2024-11-05",
+	"notifications/roots/list_changed":     "2024-11-05",
+}
+
+// Notify sends a custom notification to the client that made the current request,
+// for example a vendor extension such as "notifications/vendor/build_finished".
+//
+// The notification is checked against the protocol version negotiated with the
+// client: notifications the protocol defines are rejected, since the library sends
+// those itself (use Log and SendProgress instead), as are params that are not a
+// JSON object. Params may be nil.
+//
+// Example:
+//
+//	err := ctx.Notify("notifications/vendor/build_finished", map[string]interface{}{
+//	    "target": "release",
+//	})
+func (c *Context) Notify(method string, params interface{}) error {
+	if c.server == nil {
+		return errors.New("cannot notify: context has no server")
+	}
+	if err := validateCustomNotification(method, params, c.protocolVersion()); err != nil {
+		return err
+	}
+	return c.server.writeSessionNotification(c.Session, method, params)
+}
+
+// Broadcast sends a custom notification to all connected clients.
+//
+// The notification is checked against the protocol version of every active
+// session: notifications the protocol defines, which the server sends itself,
+// are rejected, as are params that are not a JSON object. Use Context.Notify
+// to notify the client of the current request only.
+//
+// Example:
+//
+//	server.Broadcast("notifications/vendor/index_rebuilt", map[string]interface{}{
+//	    "documents": count,
+//	})
+func (s *serverImpl) Broadcast(method string, params interface{}) error {
+	sessions, err := s.sessionManager.ListSessions()
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	versions := map[string]bool{s.sessionProtocolVersion(nil): true}
+	for _, session := range sessions {
+		versions[s.sessionProtocolVersion(session)] = true
+	}
+	for version := range versions {
+		if err := validateCustomNotification(method, params, version); err != nil {
+			return err
+		}
+	}
+	return s.writeNotification(method, params)
+}
+
+// NotifySession sends a custom notification to the client of one session only.
+//
+// On servers several clients share, such as HTTP, SSE or WebSocket servers
+// serving different tenants, this keeps a notification meant for one client
+// from reaching the others. It is validated as Broadcast does, against the
+// protocol version of the session, and fails if the session does not exist.
+//
+// Example:
+//
+//	server.NotifySession(sessionID, "notifications/vendor/quota_exceeded", map[string]interface{}{
+//	    "limit": limit,
+//	})
+func (s *serverImpl) NotifySession(sessionID SessionID, method string, params interface{}) error {
+	session, exists := s.sessionManager.GetSession(sessionID)
+	if !exists {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	if err := validateCustomNotification(method, params, s.sessionProtocolVersion(session)); err != nil {
+		return err
+	}
+	return s.writeSessionNotification(session, method, params)
+}
+
+// sendListChangedToSession sends a list_changed notification, such as
+// notifications/tools/list_changed, to the client of one session
+func (s *serverImpl) sendListChangedToSession(sessionID SessionID, method string) error {
+	session, exists := s.sessionManager.GetSession(sessionID)
+	if !exists {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	if err := s.writeSessionNotification(session, method, nil); err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	s.logger.Debug("sent notification to session", "method", method, "sessionID", sessionID)
+	return nil
+}
+
+// validateCustomNotification checks that a server may send a custom notification
+// to a client using the given protocol version
+func validateCustomNotification(method string, params interface{}, version string) error {
+	if method == "" || strings.ContainsAny(method, " \t\r\n") {
+		return fmt.Errorf("%w: method %q is not a valid method name", ErrInvalidNotification, method)
+	}
+	if strings.HasPrefix(method, "rpc.") {
+		return fmt.Errorf("%w: methods starting with \"rpc.\" are reserved by JSON-RPC", ErrInvalidNotification)
+	}
+
+	order := versionOrder(version)
+	if order < 0 {
+		return fmt.Errorf("%w: unsupported protocol version %q", ErrInvalidNotification, version)
+	}
+	if since, ok := protocolNotifications[method]; ok && order >= versionOrder(since) {
+		return fmt.Errorf("%w: %s is defined by protocol version %s and sent by the server itself",
+			ErrInvalidNotification, method, version)
+	}
+
+	// MCP requires params to be an object
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("%w: failed to marshal params: %v", ErrInvalidNotification, err)
+		}
+		var object map[string]json.RawMessage
+		if string(data) != "null" && json.Unmarshal(data, &object) != nil {
+			return fmt.Errorf("%w: params of %s must be a JSON object", ErrInvalidNotification, method)
+		}
+	}
+	return nil
+}
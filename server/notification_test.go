@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnknownNotifications(t *testing.T) {
+	s := NewServer("notification-test").GetServer()
+	s.SetTransport(&recordingTransport{})
+
+	unknown := make(chan events.UnknownMethodEvent, 4)
+	events.Subscribe[events.UnknownMethodEvent](s.Events(), events.TopicUnknownMethod,
+		func(ctx context.Context, event events.UnknownMethodEvent) error {
+			unknown <- event
+			return nil
+		})
+
+	handled := make(chan string, 1)
+	s.OnNotification("notifications/vendor/heartbeat", func(ctx *Context, params json.RawMessage) error {
+		handled <- string(params)
+		return nil
+	})
+
+	send := func(message string) {
+		t.Helper()
+		response, err := s.handleMessage([]byte(message))
+		require.NoError(t, err)
+		assert.Nil(t, response, "notifications must not get a response")
+	}
+
+	// Custom handlers receive the notification's params
+	send(`{"jsonrpc":"2.0","method":"notifications/vendor/heartbeat","params":{"seq":1}}`)
+	assert.JSONEq(t, `{"seq":1}`, <-handled)
+
+	// Unknown notifications are counted and published, not answered with errors
+	send(`{"jsonrpc":"2.0","method":"notifications/vendor/unknown"}`)
+	send(`{"jsonrpc":"2.0","method":"notifications/vendor/unknown"}`)
+	send(`{"jsonrpc":"2.0","method":"custom/event"}`)
+
+	assert.Equal(t, map[string]int64{"notifications/vendor/unknown": 2, "custom/event": 1}, s.UnknownMethodCounts())
+
+	received := map[string]int64{}
+	for i := 0; i < 3; i++ {
+		select {
+		case event := <-unknown:
+			if event.Count > received[event.Method] {
+				received[event.Method] = event.Count
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected an UnknownMethodEvent for each unknown notification")
+		}
+	}
+	assert.Equal(t, int64(2), received["notifications/vendor/unknown"])
+	assert.Equal(t, int64(1), received["custom/event"])
+
+	// Built-in notifications are not reported as unknown
+	send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}`)
+	assert.NotContains(t, s.UnknownMethodCounts(), "notifications/cancelled")
+
+	// Unknown requests still get an error response
+	response, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":5,"method":"custom/event"}`))
+	require.NoError(t, err)
+	assert.Contains(t, string(response), `"error"`)
+}
+
+func TestContextNotify(t *testing.T) {
+	s := NewServer("notification-test").GetServer()
+	tr := &recordingTransport{}
+	s.SetTransport(tr)
+
+	notified := make(chan error, 1)
+	s.Tool("build", "Builds the project", func(ctx *Context, args struct{}) (interface{}, error) {
+		notified <- ctx.Notify("notifications/vendor/build_finished", map[string]interface{}{"target": "release"})
+		return "built", nil
+	})
+
+	_, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"build","arguments":{}}}`))
+	require.NoError(t, err)
+	require.NoError(t, <-notified)
+	assert.True(t, tr.sentContaining(`"method":"notifications/vendor/build_finished","params":{"target":"release"}`))
+
+	// Notifications the protocol defines are sent by the library
+	ctx := &Context{server: s, Version: "2024-11-05"}
+	assert.ErrorIs(t, ctx.Notify("notifications/message", nil), ErrInvalidNotification)
+	assert.ErrorIs(t, ctx.Notify("notifications/vendor/list", []string{"a"}), ErrInvalidNotification)
+	assert.ErrorIs(t, ctx.Notify("", nil), ErrInvalidNotification)
+	assert.NoError(t, ctx.Notify("notifications/vendor/ping", nil))
+
+	ctx.Version = "1999-01-01"
+	assert.ErrorIs(t, ctx.Notify("notifications/vendor/ping", nil), ErrInvalidNotification)
+}
+
+func TestBroadcast(t *testing.T) {
+	s := NewServer("notification-test").GetServer()
+	tr := &recordingTransport{}
+	s.SetTransport(tr)
+
+	require.NoError(t, s.Broadcast("notifications/vendor/index_rebuilt", map[string]interface{}{"documents": 3}))
+	assert.True(t, tr.sentContaining(`"method":"notifications/vendor/index_rebuilt"`))
+
+	assert.ErrorIs(t, s.Broadcast("notifications/tools/list_changed", nil), ErrInvalidNotification)
+	assert.ErrorIs(t, s.Broadcast("rpc.vendor", nil), ErrInvalidNotification)
+}
+
+func TestNotifySession(t *testing.T) {
+	s := NewServer("notification-test").GetServer()
+	tr := &sharedChannelTransport{}
+	s.SetTransport(tr)
+	for _, sessionID := range []string{"tenant-a", "tenant-b"} {
+		_, err := s.handleSessionMessage(sessionID, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`))
+		require.NoError(t, err)
+	}
+	sentTo := func(sessionID, method string) bool {
+		for _, message := range tr.sentTo(sessionID) {
+			if strings.Contains(message, `"method":"`+method+`"`) {
+				return true
+			}
+		}
+		return false
+	}
+
+	require.NoError(t, s.NotifySession("tenant-a", "notifications/vendor/quota_exceeded", map[string]interface{}{"limit": 10}))
+	assert.True(t, sentTo("tenant-a", "notifications/vendor/quota_exceeded"))
+	assert.False(t, sentTo("tenant-b", "notifications/vendor/quota_exceeded"))
+	assert.ErrorIs(t, s.NotifySession("tenant-a", "notifications/tools/list_changed", nil), ErrInvalidNotification)
+	assert.ErrorContains(t, s.NotifySession("missing", "notifications/vendor/quota_exceeded", nil), "session not found")
+
+	require.NoError(t, s.SendToolsListChangedNotificationToSession("tenant-b"))
+	require.NoError(t, s.SendResourcesListChangedNotificationToSession("tenant-b"))
+	require.NoError(t, s.SendPromptsListChangedNotificationToSession("tenant-b"))
+	for _, method := range []string{"notifications/tools/list_changed", "notifications/resources/list_changed", "notifications/prompts/list_changed"} {
+		assert.True(t, sentTo("tenant-b", method), method)
+		assert.False(t, sentTo("tenant-a", method), method)
+	}
+
+	token := s.CreateProgressToken("job-1")
+	require.NoError(t, s.SendProgressNotificationToSession("tenant-a", token, 1, nil, "started"))
+	assert.True(t, sentTo("tenant-a", "notifications/progress"))
+	assert.False(t, sentTo("tenant-b", "notifications/progress"))
+	assert.ErrorContains(t, s.SendProgressNotificationToSession("tenant-b", token, 2, nil, "stolen"), "belongs to another session")
+
+	// Cancelling a request the server sent notifies the session it was sent to
+	s.requestTracker = newRequestTracker(nil)
+	s.requestTracker.addRequest(42, "tenant-b")
+	require.NoError(t, s.SendCancelledNotification("42", "timed out"))
+	assert.True(t, sentTo("tenant-b", "notifications/cancelled"))
+	assert.False(t, sentTo("tenant-a", "notifications/cancelled"))
+
+	// Nothing was sent to every client
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	assert.Empty(t, tr.sent)
+}
@@ -0,0 +1,594 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultOpenAPITimeout is how long OpenAPITools waits for the API to answer a call.
+const DefaultOpenAPITimeout = 30 * time.Second
+
+// maxOpenAPIResponseSize limits how much of an API response is returned as a tool result
+const maxOpenAPIResponseSize = 10 << 20
+
+// maxOpenAPIRefDepth limits how deeply $refs are inlined into tool schemas, so that
+// recursive schemas terminate
+const maxOpenAPIRefDepth = 16
+
+// openAPIMethods are the operations of an OpenAPI path item, in registration order
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// OpenAPIOption configures the tools generated by OpenAPITools.
+type OpenAPIOption func(*openAPIProxy)
+
+// WithOpenAPIBaseURL sets the URL that API calls are sent to, overriding the first
+// server listed in the spec.
+func WithOpenAPIBaseURL(baseURL string) OpenAPIOption {
+	return func(p *openAPIProxy) {
+		p.baseURL = baseURL
+	}
+}
+
+// WithOpenAPIHeader adds a header to every API call, such as an API key.
+//
+// Example:
+//
+//	server.WithOpenAPIHeader("X-API-Key", os.Getenv("API_KEY"))
+func WithOpenAPIHeader(name, value string) OpenAPIOption {
+	return WithOpenAPIHeaderFunc(name, func() (string, error) {
+		return value, nil
+	})
+}
+
+// WithOpenAPIBearerToken authenticates every API call with a bearer token.
+func WithOpenAPIBearerToken(token string) OpenAPIOption {
+	return WithOpenAPIHeader("Authorization", "Bearer "+token)
+}
+
+// WithOpenAPIHeaderFunc adds a header to every API call whose value is computed per
+// call, such as a token that is refreshed when it expires. A call fails if value
+// returns an error.
+func WithOpenAPIHeaderFunc(name string, value func() (string, error)) OpenAPIOption {
+	return func(p *openAPIProxy) {
+		p.headers = append(p.headers, openAPIHeader{name: name, value: value})
+	}
+}
+
+// WithOpenAPIHTTPClient sets the HTTP client used to fetch the spec and call the API.
+func WithOpenAPIHTTPClient(client *http.Client) OpenAPIOption {
+	return func(p *openAPIProxy) {
+		p.client = client
+	}
+}
+
+// WithOpenAPIToolPrefix prefixes the name of every generated tool, to keep the tools
+// of several APIs apart.
+func WithOpenAPIToolPrefix(prefix string) OpenAPIOption {
+	return func(p *openAPIProxy) {
+		p.prefix = prefix
+	}
+}
+
+// WithOpenAPIOperations limits the generated tools to the operations with the given
+// operation IDs.
+func WithOpenAPIOperations(operationIDs ...string) OpenAPIOption {
+	return func(p *openAPIProxy) {
+		p.operations = make(map[string]bool, len(operationIDs))
+		for _, id := range operationIDs {
+			p.operations[id] = true
+		}
+	}
+}
+
+// openAPIHeader is a header added to every API call
+type openAPIHeader struct {
+	name  string
+	value func() (string, error)
+}
+
+// openAPIProxy calls the API described by a spec
+type openAPIProxy struct {
+	spec       map[string]interface{}
+	baseURL    string
+	client     *http.Client
+	headers    []openAPIHeader
+	prefix     string
+	operations map[string]bool
+}
+
+// openAPIParameter is a parameter of an API operation
+type openAPIParameter struct {
+	Name        string                 `json:"name"`
+	In          string                 `json:"in"`
+	Description string                 `json:"description"`
+	Required    bool                   `json:"required"`
+	Schema      map[string]interface{} `json:"schema"`
+}
+
+// openAPIOperation is an API operation exposed as a tool
+type openAPIOperation struct {
+	OperationID string             `json:"operationId"`
+	Summary     string             `json:"summary"`
+	Description string             `json:"description"`
+	Tags        []string           `json:"tags"`
+	Parameters  []openAPIParameter `json:"parameters"`
+	RequestBody *struct {
+		Description string `json:"description"`
+		Required    bool   `json:"required"`
+		Content     map[string]struct {
+			Schema map[string]interface{} `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+
+	method string
+	path   string
+}
+
+// OpenAPITools registers every operation of an OpenAPI 3 spec as a tool that calls
+// the API.
+//
+// The spec is read from a file or fetched from an http(s) URL, in JSON or YAML. Each
+// operation becomes a tool named after its operationId (or its method and path), with
+// an input schema derived from its parameters and request body: parameters become
+// properties of the same name, and a JSON request body the "body" property. Calling
+// the tool sends the request to the API and returns the response body; error
+// responses are returned as tool errors.
+//
+// API calls go to the first server in the spec unless WithOpenAPIBaseURL is used.
+// Use WithOpenAPIHeader, WithOpenAPIBearerToken or WithOpenAPIHeaderFunc to
+// authenticate them.
+//
+// Example:
+//
+//	server.OpenAPITools("https://petstore3.swagger.io/api/v3/openapi.json",
+//	    server.WithOpenAPIBearerToken(os.Getenv("PETSTORE_TOKEN")))
+func (s *serverImpl) OpenAPITools(spec string, options ...OpenAPIOption) Server {
+	p := &openAPIProxy{
+		client: &http.Client{Timeout: DefaultOpenAPITimeout},
+	}
+	for _, option := range options {
+		option(p)
+	}
+
+	if err := p.load(spec); err != nil {
+		s.logger.Error("failed to load OpenAPI spec", "spec", spec, "error", err)
+		return s
+	}
+
+	if p.baseURL == "" {
+		if servers, ok := p.spec["servers"].([]interface{}); ok && len(servers) > 0 {
+			if first, ok := servers[0].(map[string]interface{}); ok {
+				p.baseURL, _ = first["url"].(string)
+			}
+		}
+	}
+	if p.baseURL == "" {
+		s.logger.Error("OpenAPI spec has no server URL, use WithOpenAPIBaseURL", "spec", spec)
+		return s
+	}
+	// Relative server URLs are relative to the spec's location
+	if base, err := url.Parse(spec); err == nil && (base.Scheme == "http" || base.Scheme == "https") {
+		if resolved, err := base.Parse(p.baseURL); err == nil {
+			p.baseURL = resolved.String()
+		}
+	}
+	p.baseURL = strings.TrimSuffix(p.baseURL, "/")
+
+	operations, err := p.operationList()
+	if err != nil {
+		s.logger.Error("invalid OpenAPI spec", "spec", spec, "error", err)
+		return s
+	}
+
+	for _, op := range operations {
+		if p.operations != nil && !p.operations[op.OperationID] {
+			continue
+		}
+
+		op := op
+		annotations := []map[string]interface{}{WithTags(op.Tags...)}
+		if op.method == "get" || op.method == "head" {
+			annotations = append(annotations, map[string]interface{}{"readOnlyHint": true})
+		}
+
+		handler := func(ctx *Context, args interface{}) (interface{}, error) {
+			arguments, _ := args.(map[string]interface{})
+			return p.call(ctx, op, arguments)
+		}
+		s.registerTool(p.toolName(op), p.description(op), handler, p.schema(op), mergeAnnotations(annotations...))
+	}
+
+	s.logger.Info("registered OpenAPI tools", "spec", spec, "baseURL", p.baseURL)
+	return s
+}
+
+// load reads and decodes the spec
+func (p *openAPIProxy) load(spec string) error {
+	var data []byte
+	if strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://") {
+		resp, err := p.client.Get(spec)
+		if err != nil {
+			return fmt.Errorf("failed to fetch spec: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("failed to fetch spec: HTTP %d", resp.StatusCode)
+		}
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return fmt.Errorf("failed to fetch spec: %w", err)
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(spec); err != nil {
+			return fmt.Errorf("failed to read spec: %w", err)
+		}
+	}
+
+	// YAML is a superset of JSON, but decoding JSON specs as JSON is faster and stricter
+	var decoded interface{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return fmt.Errorf("failed to parse spec: %w", err)
+		}
+	} else if err := yaml.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("failed to parse spec: %w", err)
+	} else {
+		decoded = stringKeys(decoded)
+	}
+
+	document, ok := decoded.(map[string]interface{})
+	if !ok {
+		return errors.New("spec must be an object")
+	}
+	if version, _ := document["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		return fmt.Errorf("unsupported OpenAPI version %q, only 3.x is supported", version)
+	}
+	p.spec = document
+	return nil
+}
+
+// stringKeys converts the maps of a decoded YAML value to map[string]interface{}.
+// YAML allows non-string keys, such as the unquoted status codes of responses.
+func stringKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = stringKeys(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = stringKeys(item)
+		}
+		return converted
+	case []interface{}:
+		for i, item := range v {
+			v[i] = stringKeys(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// operationList returns the spec's operations, sorted by path, with $refs resolved
+func (p *openAPIProxy) operationList() ([]openAPIOperation, error) {
+	paths, _ := p.spec["paths"].(map[string]interface{})
+
+	pathNames := make([]string, 0, len(paths))
+	for path := range paths {
+		pathNames = append(pathNames, path)
+	}
+	sort.Strings(pathNames)
+
+	var operations []openAPIOperation
+	for _, path := range pathNames {
+		item, _ := p.resolve(paths[path], 0).(map[string]interface{})
+
+		// Parameters of the path item apply to all of its operations
+		var shared []openAPIParameter
+		if err := p.decode(item["parameters"], &shared); err != nil {
+			return nil, fmt.Errorf("invalid parameters of %s: %w", path, err)
+		}
+
+		for _, method := range openAPIMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+
+			op := openAPIOperation{method: method, path: path}
+			if err := p.decode(raw, &op); err != nil {
+				return nil, fmt.Errorf("invalid operation %s %s: %w", strings.ToUpper(method), path, err)
+			}
+			op.Parameters = mergeOpenAPIParameters(shared, op.Parameters)
+			operations = append(operations, op)
+		}
+	}
+	return operations, nil
+}
+
+// decode resolves the $refs of a spec value and decodes it into target
+func (p *openAPIProxy) decode(value interface{}, target interface{}) error {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(p.resolve(value, 0))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// resolve returns a copy of a spec value with its local $refs inlined
+func (p *openAPIProxy) resolve(value interface{}, depth int) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			if depth >= maxOpenAPIRefDepth {
+				return map[string]interface{}{}
+			}
+			return p.resolve(p.lookup(ref), depth+1)
+		}
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved[key] = p.resolve(item, depth)
+		}
+		return resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved[i] = p.resolve(item, depth)
+		}
+		return resolved
+	default:
+		return value
+	}
+}
+
+// lookup returns the target of a local $ref such as "#/components/schemas/Pet"
+func (p *openAPIProxy) lookup(ref string) interface{} {
+	if !strings.HasPrefix(ref, "#/") {
+		return map[string]interface{}{}
+	}
+
+	var current interface{} = p.spec
+	for _, segment := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return map[string]interface{}{}
+		}
+		current = object[segment]
+	}
+	if current == nil {
+		return map[string]interface{}{}
+	}
+	return current
+}
+
+// mergeOpenAPIParameters adds path item parameters that an operation doesn't override
+func mergeOpenAPIParameters(shared, own []openAPIParameter) []openAPIParameter {
+	merged := append([]openAPIParameter(nil), own...)
+	for _, param := range shared {
+		overridden := false
+		for _, ownParam := range own {
+			if ownParam.Name == param.Name && ownParam.In == param.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, param)
+		}
+	}
+	return merged
+}
+
+// invalidToolNameChars matches the characters replaced in generated tool names
+var invalidToolNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// toolName returns the name of the tool of an operation
+func (p *openAPIProxy) toolName(op openAPIOperation) string {
+	name := op.OperationID
+	if name == "" {
+		name = op.method + " " + op.path
+	}
+	name = strings.Trim(invalidToolNameChars.ReplaceAllString(name, "_"), "_")
+	return p.prefix + name
+}
+
+// description returns the description of the tool of an operation
+func (p *openAPIProxy) description(op openAPIOperation) string {
+	parts := make([]string, 0, 2)
+	if op.Summary != "" {
+		parts = append(parts, op.Summary)
+	}
+	if op.Description != "" && op.Description != op.Summary {
+		parts = append(parts, op.Description)
+	}
+	if len(parts) == 0 {
+		return strings.ToUpper(op.method) + " " + op.path
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// schema returns the input schema of the tool of an operation
+func (p *openAPIProxy) schema(op openAPIOperation) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+
+	for _, param := range op.Parameters {
+		if param.In == "cookie" {
+			continue
+		}
+		property := map[string]interface{}{"type": "string"}
+		if param.Schema != nil {
+			property = copyOpenAPISchema(param.Schema)
+		}
+		if param.Description != "" {
+			property["description"] = param.Description
+		}
+		properties[param.Name] = property
+		if param.Required || param.In == "path" {
+			required = append(required, param.Name)
+		}
+	}
+
+	if body, ok := jsonRequestBody(op); ok {
+		property := copyOpenAPISchema(body)
+		if op.RequestBody.Description != "" {
+			property["description"] = op.RequestBody.Description
+		}
+		properties["body"] = property
+		if op.RequestBody.Required {
+			required = append(required, "body")
+		}
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// copyOpenAPISchema returns a shallow copy of a schema, so that setting its
+// description doesn't change the spec
+func copyOpenAPISchema(schema map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(schema)+1)
+	for key, value := range schema {
+		copied[key] = value
+	}
+	return copied
+}
+
+// jsonRequestBody returns the schema of an operation's JSON request body
+func jsonRequestBody(op openAPIOperation) (map[string]interface{}, bool) {
+	if op.RequestBody == nil {
+		return nil, false
+	}
+	for contentType, media := range op.RequestBody.Content {
+		if contentType == "application/json" || strings.HasSuffix(contentType, "+json") {
+			if media.Schema == nil {
+				return map[string]interface{}{}, true
+			}
+			return media.Schema, true
+		}
+	}
+	return nil, false
+}
+
+// call sends an operation's request to the API and returns the response body
+func (p *openAPIProxy) call(ctx *Context, op openAPIOperation, args map[string]interface{}) (interface{}, error) {
+	path := op.path
+	query := url.Values{}
+	header := http.Header{}
+
+	for _, param := range op.Parameters {
+		value, ok := args[param.Name]
+		if !ok || value == nil {
+			if param.Required || param.In == "path" {
+				return nil, fmt.Errorf("missing required parameter: %s", param.Name)
+			}
+			continue
+		}
+
+		switch param.In {
+		case "path":
+			// PathEscape leaves dot segments alone, which would walk the API's paths
+			segment := openAPIParamString(value)
+			if segment == "." || segment == ".." {
+				return nil, fmt.Errorf("invalid path parameter %s: %q", param.Name, segment)
+			}
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(segment))
+		case "query":
+			if values, ok := value.([]interface{}); ok {
+				for _, item := range values {
+					query.Add(param.Name, openAPIParamString(item))
+				}
+			} else {
+				query.Set(param.Name, openAPIParamString(value))
+			}
+		case "header":
+			header.Set(param.Name, openAPIParamString(value))
+		}
+	}
+
+	var body io.Reader
+	if _, ok := jsonRequestBody(op); ok {
+		if value, ok := args["body"]; ok {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode request body: %w", err)
+			}
+			body = bytes.NewReader(data)
+			header.Set("Content-Type", "application/json")
+		} else if op.RequestBody.Required {
+			return nil, errors.New("missing required parameter: body")
+		}
+	}
+
+	target := p.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx.Context(), strings.ToUpper(op.method), target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header = header
+	req.Header.Set("Accept", "application/json")
+	for _, h := range p.headers {
+		value, err := h.value()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s header: %w", h.name, err)
+		}
+		req.Header.Set(h.name, value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxOpenAPIResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return string(data), nil
+}
+
+// openAPIParamString formats a parameter value for a path, query or header
+func openAPIParamString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		// JSON numbers arrive as float64; integers should not be formatted as 1e+06
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v))
+		}
+		return fmt.Sprint(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
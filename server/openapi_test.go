@@ -0,0 +1,221 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petstoreSpec = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: 1.0.0
+servers:
+  - url: /api
+paths:
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        description: ID of the pet
+        schema:
+          type: integer
+    get:
+      operationId: getPet
+      summary: Get a pet
+      tags: [pets]
+      parameters:
+        - name: fields
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+      responses:
+        200:
+          description: The pet
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+  /pets:
+    post:
+      operationId: createPet
+      description: Add a pet to the store
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        "201":
+          description: Created
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        friend:
+          $ref: '#/components/schemas/Pet'
+`
+
+func TestOpenAPITools(t *testing.T) {
+	var lastRequest *http.Request
+	var lastBody string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, petstoreSpec)
+	})
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lastRequest, lastBody = r, string(body)
+		if r.URL.Path == "/api/pets/404" {
+			http.Error(w, "pet not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"name":"Rex"}`)
+	})
+	api := httptest.NewServer(mux)
+	defer api.Close()
+
+	s := NewServer("openapi-test").GetServer()
+	s.SetTransport(&recordingTransport{})
+	s.OpenAPITools(api.URL+"/openapi.yaml", WithOpenAPIBearerToken("secret"), WithOpenAPIToolPrefix("petstore_"))
+
+	require.Contains(t, s.tools, "petstore_getPet")
+	require.Contains(t, s.tools, "petstore_createPet")
+
+	// Parameters become properties, and the path item's parameters are shared
+	getPet := s.tools["petstore_getPet"]
+	assert.Equal(t, "Get a pet", getPet.Description)
+	assert.Equal(t, true, getPet.Annotations["readOnlyHint"])
+	schema := getPet.Schema.(map[string]interface{})
+	properties := schema["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "integer", "description": "ID of the pet"}, properties["petId"])
+	assert.Equal(t, "array", properties["fields"].(map[string]interface{})["type"])
+	assert.Equal(t, []string{"petId"}, schema["required"])
+
+	// The request body becomes the body property, with $refs inlined
+	createPet := s.tools["petstore_createPet"]
+	assert.Equal(t, "Add a pet to the store", createPet.Description)
+	schema = createPet.Schema.(map[string]interface{})
+	body := schema["properties"].(map[string]interface{})["body"].(map[string]interface{})
+	assert.Equal(t, "object", body["type"])
+	assert.Contains(t, body["properties"], "friend", "recursive schemas should be inlined up to a depth")
+	assert.Equal(t, []string{"body"}, schema["required"])
+
+	call := func(name string, args map[string]interface{}) (string, bool) {
+		t.Helper()
+		request, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0", "id": 1, "method": "tools/call",
+			"params": map[string]interface{}{"name": name, "arguments": args},
+		})
+		require.NoError(t, err)
+		response, err := s.handleMessage(request)
+		require.NoError(t, err)
+
+		var decoded struct {
+			Result struct {
+				Content []struct {
+					Text string `json:"text"`
+				} `json:"content"`
+				IsError bool `json:"isError"`
+			} `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(response, &decoded))
+		require.NotEmpty(t, decoded.Result.Content)
+		return decoded.Result.Content[0].Text, decoded.Result.IsError
+	}
+
+	text, isError := call("petstore_getPet", map[string]interface{}{"petId": 42, "fields": []interface{}{"name", "tag"}})
+	require.False(t, isError, text)
+	assert.JSONEq(t, `{"name":"Rex"}`, text)
+	assert.Equal(t, http.MethodGet, lastRequest.Method)
+	assert.Equal(t, "/api/pets/42", lastRequest.URL.Path)
+	assert.Equal(t, []string{"name", "tag"}, lastRequest.URL.Query()["fields"])
+	assert.Equal(t, "Bearer secret", lastRequest.Header.Get("Authorization"))
+
+	text, isError = call("petstore_createPet", map[string]interface{}{"body": map[string]interface{}{"name": "Rex"}})
+	require.False(t, isError, text)
+	assert.Equal(t, http.MethodPost, lastRequest.Method)
+	assert.Equal(t, "application/json", lastRequest.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"name":"Rex"}`, lastBody)
+
+	// Error responses and missing parameters are tool errors
+	text, isError = call("petstore_getPet", map[string]interface{}{"petId": 404})
+	assert.True(t, isError)
+	assert.Contains(t, text, "HTTP 404: pet not found")
+
+	text, isError = call("petstore_createPet", map[string]interface{}{})
+	assert.True(t, isError)
+	assert.Contains(t, text, "missing required parameter: body")
+
+	// Dot segments cannot walk the API's paths
+	lastRequest = nil
+	for _, segment := range []string{".", ".."} {
+		text, isError = call("petstore_getPet", map[string]interface{}{"petId": segment})
+		assert.True(t, isError)
+		assert.Contains(t, text, "invalid path parameter petId")
+	}
+	assert.Nil(t, lastRequest, "no request should reach the API")
+}
+
+func TestOpenAPIToolsFromFile(t *testing.T) {
+	var header string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-API-Key")
+		fmt.Fprint(w, "ok")
+	}))
+	defer api.Close()
+
+	spec := filepath.Join(t.TempDir(), "openapi.json")
+	require.NoError(t, os.WriteFile(spec, []byte(`{
+		"openapi": "3.1.0",
+		"info": {"title": "Status", "version": "1"},
+		"paths": {
+			"/status": {"get": {"responses": {"200": {"description": "OK"}}}},
+			"/ignored": {"get": {"operationId": "ignored", "responses": {"200": {"description": "OK"}}}}
+		}
+	}`), 0644))
+
+	keys := 0
+	s := NewServer("openapi-test").GetServer()
+	s.OpenAPITools(spec,
+		WithOpenAPIBaseURL(api.URL),
+		WithOpenAPIHeaderFunc("X-API-Key", func() (string, error) {
+			keys++
+			return fmt.Sprintf("key-%d", keys), nil
+		}),
+		WithOpenAPIOperations(""),
+	)
+
+	// Operations without an operationId are named after their method and path
+	require.Contains(t, s.tools, "get_status")
+	assert.NotContains(t, s.tools, "ignored")
+	assert.Equal(t, "GET /status", s.tools["get_status"].Description)
+
+	handler := s.tools["get_status"].Handler.(func(*Context, interface{}) (interface{}, error))
+	result, err := handler(&Context{}, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result)
+	assert.Equal(t, "key-1", header)
+
+	_, err = handler(&Context{}, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, "key-2", header, "header values should be computed per call")
+}
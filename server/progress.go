@@ -2,6 +2,8 @@ package server
 
 import (
 	"container/list"
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -313,11 +315,14 @@ func (pnh *ProgressNotificationHandler) GetOrCreateRateLimiter(progressToken str
 	return limiter
 }
 
-// CleanupRateLimiters removes rate limiters for inactive tokens
-func (pnh *ProgressNotificationHandler) CleanupRateLimiters() {
+// CleanupRateLimiters removes rate limiters for inactive tokens and returns how
+// many were removed
+func (pnh *ProgressNotificationHandler) CleanupRateLimiters() int {
 	pnh.mu.Lock()
 	defer pnh.mu.Unlock()
 
+	removed := 0
+
 	for token, limiter := range pnh.rateLimiters {
 		// Check if token is still active
 		if pnh.server != nil && pnh.server.progressTokenManager != nil {
@@ -329,9 +334,34 @@ func (pnh *ProgressNotificationHandler) CleanupRateLimiters() {
 				limiter.mu.Lock()
 				limiter.buffer.Init()
 				limiter.mu.Unlock()
+				removed++
 			}
 		}
 	}
+
+	return removed
+}
+
+// RemoveRateLimiter removes the rate limiter for a token, dropping the notifications
+// still buffered for it except the latest, which it returns (nil if none)
+func (pnh *ProgressNotificationHandler) RemoveRateLimiter(progressToken string) *mcp.ProgressNotification {
+	pnh.mu.Lock()
+	limiter, exists := pnh.rateLimiters[progressToken]
+	delete(pnh.rateLimiters, progressToken)
+	pnh.mu.Unlock()
+
+	if !exists {
+		return nil
+	}
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	var latest *mcp.ProgressNotification
+	if back := limiter.buffer.Back(); back != nil {
+		latest, _ = back.Value.(*mcp.ProgressNotification)
+	}
+	limiter.buffer.Init()
+	return latest
 }
 
 // GetAllStatistics returns statistics for all rate limiters
@@ -406,19 +436,36 @@ func (s *serverImpl) HandleProgressNotification(message []byte) error {
 	return nil
 }
 
-// SendProgressNotification sends a notifications/progress notification using rate limiting
+// SendProgressNotification sends a notifications/progress notification using rate limiting.
+// It is sent in the shape of the protocol version of the session the token belongs to.
 func (s *serverImpl) SendProgressNotification(progressToken string, progress float64, total *float64, message string) error {
+	return s.sendProgressNotificationForVersion(progressToken, progress, total, message, canonicalVersion)
+}
+
+// SendProgressNotificationToSession sends a notifications/progress notification to
+// the client of one session, formatted for the protocol version it negotiated. The
+// token is bound to the session, so that later progress on it is delivered there
+// too; tokens bound to another session are refused.
+func (s *serverImpl) SendProgressNotificationToSession(sessionID SessionID, progressToken string, progress float64, total *float64, message string) error {
+	session, exists := s.sessionManager.GetSession(sessionID)
+	if !exists {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	if bound := s.progressTokenManager.TokenSession(progressToken); bound != "" && bound != string(sessionID) {
+		return fmt.Errorf("progress token %s belongs to another session", progressToken)
+	}
+	s.progressTokenManager.BindSession(progressToken, string(sessionID))
+	return s.sendProgressNotificationForVersion(progressToken, progress, total, message, s.sessionProtocolVersion(session))
+}
+
+// sendProgressNotificationForVersion sends a progress notification formatted for the
+// given protocol version, such as the version of the session that made the request
+func (s *serverImpl) sendProgressNotificationForVersion(progressToken string, progress float64, total *float64, message string, protocolVersion string) error {
 	// Validate that the progress token exists and is active
 	if !s.progressTokenManager.ValidateToken(progressToken) {
 		return fmt.Errorf("invalid or inactive progress token: %s", progressToken)
 	}
 
-	// Get the protocol version for this server
-	protocolVersion := s.protocolVersion
-	if protocolVersion == "" {
-		protocolVersion = "draft" // Default fallback
-	}
-
 	// Create the notification using the new type with protocol version awareness
 	notification := mcp.NewProgressNotificationForVersion(progressToken, progress, total, message, protocolVersion)
 
@@ -434,11 +481,7 @@ func (s *serverImpl) SendProgressNotification(progressToken string, progress flo
 func (s *serverImpl) SendProgressNotificationDirect(notification *mcp.ProgressNotification) error {
 	// Set protocol version if not already set
 	if notification.GetProtocolVersion() == "" {
-		protocolVersion := s.protocolVersion
-		if protocolVersion == "" {
-			protocolVersion = "draft"
-		}
-		notification.SetProtocolVersion(protocolVersion)
+		notification.SetProtocolVersion(s.sessionProtocolVersion(s.defaultSession))
 	}
 
 	// Validate the notification
@@ -514,15 +557,37 @@ func (s *serverImpl) sendProgressNotificationDirect(notification *mcp.ProgressNo
 		}
 	}
 
-	// Convert to JSON
-	messageBytes, err := notification.ToJSON()
-	if err != nil {
-		return fmt.Errorf("failed to marshal progress notification: %w", err)
+	if err := s.deliverProgressNotification(notification); err != nil {
+		return err
+	}
+
+	// Update the token's progress and last update time
+	if s.progressTokenManager != nil {
+		if err := s.progressTokenManager.UpdateTokenWithProgress(notification.Params.ProgressToken, notification.Params.Progress); err != nil {
+			s.logger.Warn("failed to update progress token after sending notification",
+				"error", err, "progressToken", notification.Params.ProgressToken)
+		}
 	}
 
-	// Send the notification via transport
+	return nil
+}
+
+// deliverProgressNotification sends a notification to the session that made the
+// request and publishes it to the progress channel
+func (s *serverImpl) deliverProgressNotification(notification *mcp.ProgressNotification) error {
+	// Send the notification to the session that made the request, in the shape of
+	// the protocol version it negotiated
 	if s.transport != nil {
-		if err := s.transport.Send(messageBytes); err != nil {
+		sessionID := SessionID(s.progressTokenManager.TokenSession(notification.Params.ProgressToken))
+		session, exists := s.sessionManager.GetSession(sessionID)
+		if !exists {
+			session = s.defaultSession
+		}
+		messageBytes, err := adaptProgressForVersion(notification, s.sessionProtocolVersion(session)).ToJSON()
+		if err != nil {
+			return fmt.Errorf("failed to marshal progress notification: %w", err)
+		}
+		if err := s.sendToSession(sessionID, messageBytes); err != nil {
 			return fmt.Errorf("failed to send progress notification: %w", err)
 		}
 	} else {
@@ -540,14 +605,6 @@ func (s *serverImpl) sendProgressNotificationDirect(notification *mcp.ProgressNo
 		}
 	}
 
-	// Update the token's progress and last update time
-	if s.progressTokenManager != nil {
-		if err := s.progressTokenManager.UpdateTokenWithProgress(notification.Params.ProgressToken, notification.Params.Progress); err != nil {
-			s.logger.Warn("failed to update progress token after sending notification",
-				"error", err, "progressToken", notification.Params.ProgressToken)
-		}
-	}
-
 	return nil
 }
 
@@ -632,11 +689,7 @@ func (s *serverImpl) UnsubscribeFromProgress(progressToken string) {
 
 // CreateProgressToken creates a new progress token for a request
 func (s *serverImpl) CreateProgressToken(requestID string) string {
-	protocolVersion := s.protocolVersion
-	if protocolVersion == "" {
-		protocolVersion = "draft"
-	}
-	return s.progressTokenManager.GenerateTokenForVersion(requestID, protocolVersion)
+	return s.progressTokenManager.GenerateTokenForVersion(requestID, s.sessionProtocolVersion(s.defaultSession))
 }
 
 // CreateProgressTokenForVersion creates a new progress token for a specific protocol version
@@ -646,9 +699,15 @@ func (s *serverImpl) CreateProgressTokenForVersion(requestID string, protocolVer
 
 // CompleteProgress marks a progress token as completed and sends a final notification
 func (s *serverImpl) CompleteProgress(progressToken string, finalMessage string) error {
+	return s.completeProgressForVersion(progressToken, finalMessage, s.sessionProtocolVersion(s.defaultSession))
+}
+
+// completeProgressForVersion completes a progress token, formatting the final
+// notification for the given protocol version
+func (s *serverImpl) completeProgressForVersion(progressToken string, finalMessage string, protocolVersion string) error {
 	// Send a final progress notification (100% complete)
 	total := 100.0
-	if err := s.SendProgressNotification(progressToken, 100.0, &total, finalMessage); err != nil {
+	if err := s.sendProgressNotificationForVersion(progressToken, 100.0, &total, finalMessage, protocolVersion); err != nil {
 		s.logger.Warn("failed to send final progress notification", "error", err, "progressToken", progressToken)
 	}
 
@@ -666,6 +725,124 @@ func (s *serverImpl) CleanupExpiredProgressTokens(expiration time.Duration) int
 	return s.progressTokenManager.CleanupExpiredTokens(expiration)
 }
 
+// trackRequestProgressToken tracks the progress token a client sent in a request's
+// _meta, so that the request's handler can report progress on it with SendProgress
+func (s *serverImpl) trackRequestProgressToken(ctx *Context) {
+	token := ctx.ProgressToken
+	if token == "" {
+		return
+	}
+	if !s.progressTokenManager.RegisterToken(token, ctx.RequestID, ctx.protocolVersion()) {
+		// Never report this request's progress to whichever client owns the token
+		s.logger.Warn("progress token is already in use by another request", "progressToken", token)
+		ctx.ProgressToken = ""
+		return
+	}
+	ctx.clientProgressToken = token
+	ctx.setProgressToken(token)
+}
+
+// releaseProgressTokens forgets the progress tokens of a request once it has
+// finished. Progress must not be reported after the response, so their rate limiters
+// go too; the latest notification still buffered in one is sent first.
+func (s *serverImpl) releaseProgressTokens(ctx *Context) {
+	ctx.progressMu.Lock()
+	tokens, reporters := ctx.progressTokens, ctx.progressReporters
+	ctx.progressTokens, ctx.progressReporters = nil, nil
+	ctx.progressMu.Unlock()
+
+	if cause := requestAbortCause(ctx); cause != nil {
+		s.cancelProgress(ctx, cause, tokens, reporters)
+		return
+	}
+
+	for _, token := range tokens {
+		if s.progressNotificationHandler != nil {
+			// The client sees the latest progress before the response, even if rate
+			// limiting held it back
+			if latest := s.progressNotificationHandler.RemoveRateLimiter(token); latest != nil {
+				if err := s.sendProgressNotificationDirect(latest); err != nil {
+					s.logger.Warn("failed to send buffered progress notification",
+						"error", err, "progressToken", token)
+				}
+			}
+		}
+		s.progressTokenManager.RemoveToken(token)
+	}
+}
+
+// requestAbortCause returns why a request was aborted, ErrRequestCancelled or
+// context.DeadlineExceeded, or nil if it ran to completion
+func requestAbortCause(ctx *Context) error {
+	if ctx.ctx == nil {
+		return nil
+	}
+	cause := context.Cause(ctx.ctx)
+	if errors.Is(cause, ErrRequestCancelled) || errors.Is(cause, context.DeadlineExceeded) {
+		return cause
+	}
+	return nil
+}
+
+// cancelProgress ends the progress of a request that was cancelled or timed out. Its
+// reporters and their children are cancelled, whatever rate limiting still buffers
+// is dropped, and where the protocol version has progress messages, each token gets
+// a final notification saying that the operation was cancelled.
+func (s *serverImpl) cancelProgress(ctx *Context, cause error, tokens []string, reporters []*mcp.ProgressReporter) {
+	message := "cancelled"
+	if errors.Is(cause, context.DeadlineExceeded) {
+		message = "cancelled: timed out"
+	}
+
+	// Cancelling deactivates the tokens, so the handler, which may still be running,
+	// can no longer report progress on them
+	totals := make(map[string]*float64, len(reporters))
+	var childTokens []string
+	for _, reporter := range reporters {
+		_, total, _, _ := reporter.GetProgress()
+		totals[reporter.GetToken()] = total
+		childTokens = append(childTokens, descendantTokens(reporter)...)
+		_ = reporter.Cancel(message)
+	}
+	for _, token := range tokens {
+		_ = s.progressTokenManager.DeactivateToken(token)
+	}
+
+	version := ctx.protocolVersion()
+	for _, token := range tokens {
+		if s.progressNotificationHandler != nil {
+			s.progressNotificationHandler.RemoveRateLimiter(token)
+		}
+		if version == "draft" || version == "2025-03-26" {
+			// Tokens without any progress yet were never seen by the client
+			if progress, err := s.progressTokenManager.GetLastProgress(token); err == nil && progress >= 0 {
+				notification := mcp.NewProgressNotificationForVersion(token, progress, totals[token], message, version)
+				if err := s.deliverProgressNotification(notification); err != nil {
+					s.logger.Warn("failed to send cancelled progress notification",
+						"error", err, "progressToken", token)
+				}
+			}
+		}
+		s.progressTokenManager.RemoveToken(token)
+	}
+	for _, token := range childTokens {
+		if s.progressNotificationHandler != nil {
+			s.progressNotificationHandler.RemoveRateLimiter(token)
+		}
+		s.progressTokenManager.RemoveToken(token)
+	}
+}
+
+// descendantTokens returns the tokens of a reporter's children, their children and so on
+func descendantTokens(reporter *mcp.ProgressReporter) []string {
+	var tokens []string
+	for token, child := range reporter.GetChildren() {
+		tokens = append(tokens, token)
+		tokens = append(tokens, descendantTokens(child)...)
+	}
+	return tokens
+}
+
 // CreateProgressReporter creates a new ProgressReporter with the server as the notification sender
 func (s *serverImpl) CreateProgressReporter(requestID string, total *float64, initialMessage string) *mcp.ProgressReporter {
 	return mcp.NewProgressReporter(mcp.ProgressReporterConfig{
@@ -677,6 +854,30 @@ func (s *serverImpl) CreateProgressReporter(requestID string, total *float64, in
 	})
 }
 
+// versionedProgressSender sends progress notifications formatted for one protocol version
+type versionedProgressSender struct {
+	server  *serverImpl
+	version string
+}
+
+// SendProgressNotification implements mcp.ProgressNotificationSender
+func (v versionedProgressSender) SendProgressNotification(progressToken string, progress float64, total *float64, message string) error {
+	return v.server.sendProgressNotificationForVersion(progressToken, progress, total, message, v.version)
+}
+
+// createProgressReporterForVersion creates a ProgressReporter whose notifications are
+// formatted for the given protocol version, such as the version of the requesting session
+func (s *serverImpl) createProgressReporterForVersion(requestID string, total *float64, initialMessage string, protocolVersion string) *mcp.ProgressReporter {
+	return mcp.NewProgressReporter(mcp.ProgressReporterConfig{
+		RequestID:          requestID,
+		Total:              total,
+		InitialMessage:     initialMessage,
+		ProtocolVersion:    protocolVersion,
+		TokenManager:       s.progressTokenManager,
+		NotificationSender: versionedProgressSender{server: s, version: protocolVersion},
+	})
+}
+
 // CreateSimpleProgressReporter creates a basic ProgressReporter with minimal configuration
 func (s *serverImpl) CreateSimpleProgressReporter(requestID string, total *float64) *mcp.ProgressReporter {
 	return mcp.NewProgressReporter(mcp.ProgressReporterConfig{
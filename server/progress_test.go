@@ -0,0 +1,163 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressGoesToTheRequestingSession(t *testing.T) {
+	s := NewServer("progress-test").GetServer()
+	tr := &sharedChannelTransport{}
+	s.SetTransport(tr)
+
+	release := make(chan struct{})
+	s.Tool("count", "Reports progress while counting", func(ctx *Context, args struct {
+		To int `json:"to"`
+	}) (interface{}, error) {
+		total := float64(args.To)
+		for i := 1; i <= args.To; i++ {
+			if err := ctx.SendProgress(float64(i), &total, "counting"); err != nil {
+				return nil, err
+			}
+		}
+		return "counted", nil
+	})
+	s.Tool("hold", "Reports progress, then waits", func(ctx *Context, args struct{}) (interface{}, error) {
+		total := 1.0
+		if err := ctx.SendProgress(1, &total, "holding"); err != nil {
+			return nil, err
+		}
+		<-release
+		return "held", nil
+	})
+
+	send := func(sessionID, message string) string {
+		t.Helper()
+		response, err := s.handleSessionMessage(sessionID, []byte(message))
+		require.NoError(t, err)
+		return string(response)
+	}
+	call := func(sessionID string, id int, tool, token string) string {
+		return send(sessionID, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":%q,"arguments":{"to":3},"_meta":{"progressToken":%q}}}`, id, tool, token))
+	}
+	progressSentTo := func(sessionID string) []string {
+		var progress []string
+		for _, message := range tr.sentTo(sessionID) {
+			if strings.Contains(message, "notifications/progress") {
+				progress = append(progress, message)
+			}
+		}
+		return progress
+	}
+	for _, sessionID := range []string{"client-a", "client-b"} {
+		send(sessionID, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	}
+
+	// Progress on the client's token reaches that client only, and the latest update
+	// is sent before the response even if rate limiting held it back
+	assert.Contains(t, call("client-a", 2, "count", "a-1"), "counted")
+	progress := progressSentTo("client-a")
+	require.NotEmpty(t, progress)
+	for _, message := range progress {
+		assert.Contains(t, message, `"progressToken":"a-1"`)
+	}
+	assert.Contains(t, progress[len(progress)-1], `"progress":3`)
+	assert.Empty(t, progressSentTo("client-b"))
+	assert.False(t, tr.sentContaining("notifications/progress"), "progress is never broadcast")
+	assert.Equal(t, 0, s.progressTokenManager.Len(), "the token is released with the request")
+
+	// A token already in use by another request gets no progress at all
+	held := make(chan string, 1)
+	go func() { held <- call("client-a", 3, "hold", "shared") }()
+	require.Eventually(t, func() bool { return len(progressSentTo("client-a")) > len(progress) }, time.Second, 10*time.Millisecond)
+	assert.Contains(t, call("client-b", 2, "count", "shared"), "counted")
+	assert.Empty(t, progressSentTo("client-b"))
+	close(release)
+	assert.Contains(t, <-held, "held")
+}
+
+func TestProgressIsCancelledWithTheRequest(t *testing.T) {
+	s := NewServer("progress-test",
+		WithAdaptiveTimeouts(3),
+		WithAdaptiveTimeoutBounds(0, 100*time.Millisecond),
+	).GetServer()
+	tr := &sharedChannelTransport{}
+	s.SetTransport(tr)
+
+	type reporters struct{ parent, child *mcp.ProgressReporter }
+	stopped := make(chan reporters, 1)
+	s.Tool("stuck", "Reports progress, then waits until its request is aborted", func(ctx *Context, args struct{}) (interface{}, error) {
+		total := 20.0
+		for i := 1; i <= 20; i++ {
+			if err := ctx.SendProgress(float64(i), &total, "working"); err != nil {
+				return nil, err
+			}
+		}
+		parent := ctx.CreateProgressReporter(&total, "working")
+		child := parent.CreateChild("child", 0.5, &total)
+		<-ctx.Context().Done()
+		stopped <- reporters{parent, child}
+		return nil, ctx.Context().Err()
+	})
+
+	send := func(sessionID, message string) string {
+		t.Helper()
+		response, err := s.handleSessionMessage(sessionID, []byte(message))
+		require.NoError(t, err)
+		return string(response)
+	}
+	progressSentTo := func(sessionID string) []string {
+		var progress []string
+		for _, message := range tr.sentTo(sessionID) {
+			if strings.Contains(message, "notifications/progress") {
+				progress = append(progress, message)
+			}
+		}
+		return progress
+	}
+	for sessionID, version := range map[string]string{"client-new": "2025-03-26", "client-old": "2024-11-05"} {
+		send(sessionID, fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":%q,"capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`, version))
+	}
+	call := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"stuck","arguments":{},"_meta":{"progressToken":"job"}}}`
+
+	// Cancelling the request cancels its reporters and their children, drops the
+	// progress rate limiting held back and ends with a cancelled notification
+	go send("client-new", call)
+	require.Eventually(t, func() bool { return len(progressSentTo("client-new")) > 0 }, time.Second, 10*time.Millisecond)
+	send("client-new", `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":2}}`)
+	cancelled := <-stopped
+	require.Eventually(t, func() bool { return s.progressTokenManager.Len() == 0 }, time.Second, 10*time.Millisecond)
+
+	assert.False(t, cancelled.parent.IsActive())
+	assert.False(t, cancelled.child.IsActive())
+	assert.Error(t, cancelled.parent.Update(1), "a cancelled reporter reports no more progress")
+	var progress []string
+	for _, message := range progressSentTo("client-new") {
+		if strings.Contains(message, `"progressToken":"job"`) {
+			progress = append(progress, message)
+		}
+	}
+	last := progress[len(progress)-1]
+	assert.Contains(t, last, `"message":"cancelled"`)
+	assert.Contains(t, last, `"progress":10`)
+	for _, message := range progress {
+		assert.NotContains(t, message, `"progress":20`, "buffered progress is dropped")
+	}
+	assert.Equal(t, 0, s.progressNotificationHandler.GetAllStatistics()["totalRateLimiters"])
+
+	// A request that times out ends the same way, but without a final notification
+	// for a version whose progress has no message
+	assert.Contains(t, send("client-old", call), "timed out")
+	timedOut := <-stopped
+	assert.False(t, timedOut.child.IsActive())
+	for _, message := range progressSentTo("client-old") {
+		assert.NotContains(t, message, "cancelled")
+	}
+	assert.Equal(t, 0, s.progressTokenManager.Len())
+}
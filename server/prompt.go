@@ -1,22 +1,34 @@
 package server
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/localrivet/gomcp/events"
 	"github.com/localrivet/gomcp/mcp"
+	"github.com/localrivet/gomcp/util/schema"
 )
 
 // InvalidParametersError represents an error with invalid parameters
-// for prompt rendering or template variable substitution.
+// for prompt rendering, template variable substitution or tool arguments.
 type InvalidParametersError struct {
 	// Message contains the error description
 	Message string
+
+	// Violations lists every constraint the parameters violated, when they were
+	// validated against a schema
+	Violations []schema.FieldError
+
+	// Hint tells a human how to fix the parameters, sent in the data of the error
+	// response
+	Hint string
 }
 
 // Error returns the error message string.
@@ -33,14 +45,14 @@ func NewInvalidParametersError(message string) *InvalidParametersError {
 
 // Content type constants define the supported content types for prompts.
 const (
-	// ContentTypeImage is used for image content, which requires an imageUrl
-	ContentTypeImage ContentType = "image"
+	// ContentTypeImage is used for base64-encoded image content
+	ContentTypeImage = mcp.ContentTypeImage
 
 	// ContentTypeAudio is used for audio content, which requires audio data
 	ContentTypeAudio ContentType = "audio"
 
-	// ContentTypeResource is used for referencing resources by URI
-	ContentTypeResource ContentType = "resource"
+	// ContentTypeResource is used for the contents of an embedded resource
+	ContentTypeResource = mcp.ContentTypeResource
 )
 
 // PromptTemplate represents a template for a prompt with a role and content.
@@ -51,8 +63,13 @@ type PromptTemplate struct {
 	// Role defines who is speaking in this template ("user" or "assistant")
 	Role string
 
-	// Content contains the template text with variables in {{variable}} format
+	// Content contains the template text with variables in {{variable}} format.
+	// For image templates it holds the image data, and for resource templates the
+	// URI of the resource to embed.
 	Content string
+
+	// Type is the type of content the template renders to; empty means text
+	Type ContentType
 }
 
 // Prompt represents a prompt registered with the server.
@@ -70,6 +87,9 @@ type Prompt struct {
 
 	// Arguments are the parameters that can be passed when rendering the prompt
 	Arguments []PromptArgument
+
+	// Annotations contains additional metadata about the prompt, such as tags
+	Annotations map[string]interface{}
 }
 
 // User creates a user prompt template.
@@ -84,15 +104,68 @@ func Assistant(content string) PromptTemplate {
 	return PromptTemplate{Role: "assistant", Content: content}
 }
 
+// UserImage creates a user prompt template carrying an image.
+// data is the base64-encoded image or a data URL, such as
+// "data:image/png;base64,...", and may be a {{variable}} the client passes it in.
+// The MIME type is taken from a data URL or detected from the image.
+func UserImage(data string) PromptTemplate {
+	return PromptTemplate{Role: "user", Content: data, Type: ContentTypeImage}
+}
+
+// AssistantImage creates an assistant prompt template carrying an image.
+// See UserImage for the format of data.
+func AssistantImage(data string) PromptTemplate {
+	return PromptTemplate{Role: "assistant", Content: data, Type: ContentTypeImage}
+}
+
+// UserResource creates a user prompt template that embeds the contents of one of
+// the server's resources. The uri may contain {{variables}}; the resource is read
+// when the prompt is rendered.
+func UserResource(uri string) PromptTemplate {
+	return PromptTemplate{Role: "user", Content: uri, Type: ContentTypeResource}
+}
+
+// AssistantResource creates an assistant prompt template that embeds the contents
+// of one of the server's resources. See UserResource.
+func AssistantResource(uri string) PromptTemplate {
+	return PromptTemplate{Role: "assistant", Content: uri, Type: ContentTypeResource}
+}
+
 // Prompt registers a prompt with the server.
 // The function returns the server instance to allow for method chaining.
 // The name parameter is used as the identifier for the prompt.
 // The description parameter explains what the prompt does.
 // The templates parameter contains one or more PromptTemplate instances.
 func (s *serverImpl) Prompt(name string, description string, templates ...PromptTemplate) Server {
+	return s.registerPrompt(name, description, templates, false)
+}
+
+// ReplacePrompt replaces a registered prompt's description and templates in a
+// single step and notifies clients that the prompt list changed. Tags attached
+// with TagPrompt are kept. Replacing a prompt that is not registered logs an
+// error and leaves the registry unchanged.
+//
+// Example:
+//
+//	server.ReplacePrompt("greeting", "A friendlier greeting",
+//	    server.User("Hello there, {{name}}!"),
+//	)
+func (s *serverImpl) ReplacePrompt(name, description string, templates ...PromptTemplate) Server {
+	return s.registerPrompt(name, description, templates, true)
+}
+
+// registerPrompt stores a prompt in the server's registry. When replace is set the
+// prompt must already be registered, and its annotations are carried over.
+func (s *serverImpl) registerPrompt(name, description string, templates []PromptTemplate, replace bool) Server {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	existing, exists := s.prompts[name]
+	if replace && !exists {
+		s.logger.Error("cannot replace unknown prompt", "name", name)
+		return s
+	}
+
 	if name == "" {
 		s.logger.Error("prompt name cannot be empty")
 		return s
@@ -116,6 +189,9 @@ func (s *serverImpl) Prompt(name string, description string, templates ...Prompt
 		Templates:   promptTemplates,
 		Arguments:   arguments,
 	}
+	if replace {
+		s.prompts[name].Annotations = existing.Annotations
+	}
 
 	// Mark prompts as changed for potential notifications
 	s.capabilityCache.MarkPromptsChanged()
@@ -126,6 +202,88 @@ func (s *serverImpl) Prompt(name string, description string, templates ...Prompt
 	return s
 }
 
+// TagPrompt attaches category tags to a registered prompt.
+//
+// Tags are exposed under the "tags" annotation in prompts/list responses and
+// can be used to filter the list. Tools and resources are tagged at registration
+// with WithTags; prompts use this method because Prompt's variadic parameter
+// is reserved for templates. Tags are added to any the prompt already has.
+//
+// Example:
+//
+//	server.Prompt("review", "Code review", server.User("Review {{code}}"))
+//	server.GetServer().TagPrompt("review", "code", "quality")
+func (s *serverImpl) TagPrompt(name string, tags ...string) Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prompt, exists := s.prompts[name]
+	if !exists {
+		s.logger.Error("cannot tag unknown prompt", "name", name)
+		return s
+	}
+
+	prompt.Annotations = mergeAnnotations(prompt.Annotations, WithTags(tags...))
+
+	// Mark prompts as changed for potential notifications
+	s.capabilityCache.MarkPromptsChanged()
+	s.sendCapabilityNotification("prompts")
+
+	return s
+}
+
+// AnnotatePrompt attaches annotations, such as those of WithDisplayName,
+// WithCategory and WithIcon, to a registered prompt, for the same reason as
+// TagPrompt. Annotations are merged with those the prompt already has.
+//
+// Example:
+//
+//	server.Prompt("review", "Code review", server.User("Review {{code}}"))
+//	server.GetServer().AnnotatePrompt("review", server.WithDisplayName("Code Review"), server.WithCategory("Development"))
+func (s *serverImpl) AnnotatePrompt(name string, annotations ...map[string]interface{}) Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prompt, exists := s.prompts[name]
+	if !exists {
+		s.logger.Error("cannot annotate unknown prompt", "name", name)
+		return s
+	}
+
+	prompt.Annotations = mergeAnnotations(append([]map[string]interface{}{prompt.Annotations}, annotations...)...)
+
+	// Mark prompts as changed for potential notifications
+	s.capabilityCache.MarkPromptsChanged()
+	s.sendCapabilityNotification("prompts")
+
+	return s
+}
+
+// RemovePrompt unregisters a prompt and notifies clients that the prompt list
+// changed. It is safe to call while the server is running.
+//
+// Example:
+//
+//	server.RemovePrompt("greeting")
+func (s *serverImpl) RemovePrompt(name string) Server {
+	s.mu.Lock()
+	_, exists := s.prompts[name]
+	delete(s.prompts, name)
+	remaining := len(s.prompts) > 0
+	s.mu.Unlock()
+
+	if !exists {
+		s.logger.Error("cannot remove unknown prompt", "name", name)
+		return s
+	}
+
+	// Mark prompts as changed and send the notification
+	s.capabilityCache.MarkPromptsRemoved(remaining)
+	s.sendCapabilityNotification("prompts")
+
+	return s
+}
+
 // extractArguments extracts variable names from templates and creates arguments list.
 // It uses a regular expression to find all {{variable}} patterns in the templates
 // and creates a corresponding list of required arguments.
@@ -165,14 +323,17 @@ func extractArguments(templates []PromptTemplate) []PromptArgument {
 func (s *serverImpl) ProcessPromptList(ctx *Context) (interface{}, error) {
 	// Get pagination cursor if provided
 	var cursor string
+	var filter *ListFilter
 	if ctx.Request.Params != nil {
 		var params struct {
-			Cursor string `json:"cursor"`
+			Cursor string      `json:"cursor"`
+			Filter *ListFilter `json:"filter,omitempty"`
 		}
 		if err := json.Unmarshal(ctx.Request.Params, &params); err != nil {
 			return nil, fmt.Errorf("invalid params: %w", err)
 		}
 		cursor = params.Cursor
+		filter = params.Filter
 	}
 
 	s.mu.RLock()
@@ -184,19 +345,33 @@ func (s *serverImpl) ProcessPromptList(ctx *Context) (interface{}, error) {
 	var nextCursor string
 
 	// Convert prompts to the expected format
+	names := make([]string, 0, len(s.prompts))
+	for name := range s.prompts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
 	i := 0
-	for name, prompt := range s.prompts {
+	for _, name := range names {
+		prompt := s.prompts[name]
+
 		// If we have a cursor, skip until we find it
 		if cursor != "" && name <= cursor {
 			continue
 		}
 
+		if !filter.Matches(prompt.Name, prompt.Annotations) || !s.visibleTo(ctx.Session, prompt.Name) {
+			continue
+		}
+
 		// Add the prompt to the result
 		promptInfo := PromptInfo{
 			Name:        prompt.Name,
 			Description: prompt.Description,
 			Arguments:   prompt.Arguments, // Always include arguments field, even if empty
+			Annotations: prompt.Annotations,
 		}
+		promptInfo.Title, promptInfo.Icons = specDisplay(ctx, prompt.Annotations)
 
 		prompts = append(prompts, promptInfo)
 
@@ -295,8 +470,13 @@ func (s *serverImpl) ProcessPromptRequest(ctx *Context) (interface{}, error) {
 	prompt, exists := s.prompts[promptName]
 	s.mu.RUnlock()
 
-	if !exists {
-		return nil, NewInvalidParametersError(fmt.Sprintf("prompt not found: %s", promptName))
+	if !exists || !s.visibleTo(ctx.Session, promptName) {
+		return nil, &RemediableError{
+			Code:    -32602,
+			Reason:  mcp.ReasonPromptNotFound,
+			Message: fmt.Sprintf("prompt not found: %s", promptName),
+			Hint:    "call prompts/list for the prompts the server provides",
+		}
 	}
 
 	// Validate required arguments
@@ -318,12 +498,13 @@ func (s *serverImpl) ProcessPromptRequest(ctx *Context) (interface{}, error) {
 		}
 
 		// Create a message from the template with proper content format
+		content, err := s.renderPromptContent(ctx, template.Type, renderedContent)
+		if err != nil {
+			return nil, err
+		}
 		renderedTemplates = append(renderedTemplates, PromptMessage{
-			Role: template.Role,
-			Content: PromptContent{
-				Type: ContentTypeText,
-				Text: renderedContent,
-			},
+			Role:    template.Role,
+			Content: content,
 		})
 	}
 
@@ -353,6 +534,93 @@ func (s *serverImpl) ProcessPromptRequest(ctx *Context) (interface{}, error) {
 	return NewPromptGetResponse(prompt.Description, renderedTemplates), nil
 }
 
+// renderPromptContent turns a template's content, with its variables substituted,
+// into a content block of the template's type
+func (s *serverImpl) renderPromptContent(ctx *Context, contentType ContentType, content string) (PromptContent, error) {
+	switch contentType {
+	case ContentTypeImage:
+		data, mimeType, err := decodePromptImage(content)
+		if err != nil {
+			return PromptContent{}, NewInvalidParametersError(err.Error())
+		}
+		return PromptContent{Type: ContentTypeImage, Data: data, MimeType: mimeType}, nil
+	case ContentTypeResource:
+		resource, err := s.embedResource(ctx, content)
+		if err != nil {
+			return PromptContent{}, err
+		}
+		return PromptContent{Type: ContentTypeResource, Resource: resource}, nil
+	}
+	return PromptContent{Type: ContentTypeText, Text: content}, nil
+}
+
+// decodePromptImage returns the base64 data and MIME type of an image given as
+// base64 data or a data URL
+func decodePromptImage(image string) (string, string, error) {
+	data, mimeType := strings.TrimSpace(image), ""
+	if rest, ok := strings.CutPrefix(data, "data:"); ok {
+		header, encoded, found := strings.Cut(rest, ",")
+		if !found || !strings.HasSuffix(header, ";base64") {
+			return "", "", errors.New("image data URL must be base64-encoded")
+		}
+		data, mimeType = encoded, strings.TrimSuffix(header, ";base64")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", "", fmt.Errorf("image data is not valid base64: %w", err)
+	}
+	if mimeType == "" {
+		mimeType = http.DetectContentType(decoded)
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", "", fmt.Errorf("image data is not an image: detected %s", mimeType)
+	}
+	return data, mimeType, nil
+}
+
+// embedResource reads one of the server's resources for embedding in a prompt
+// message
+func (s *serverImpl) embedResource(ctx *Context, uri string) (*mcp.EmbeddedResource, error) {
+	params, err := json.Marshal(map[string]string{"uri": uri})
+	if err != nil {
+		return nil, err
+	}
+	read := &Context{
+		ctx:      ctx.ctx,
+		server:   s,
+		Logger:   ctx.Logger,
+		Version:  "2025-03-26", // read in the format with a contents array
+		Metadata: ctx.Metadata,
+		Session:  ctx.Session,
+		Request:  &Request{JSONRPC: "2.0", Method: "resources/read", Params: params},
+	}
+	result, err := s.ProcessResourceRequest(read)
+	if err != nil {
+		return nil, NewInvalidParametersError(fmt.Sprintf("cannot embed resource %s: %v", uri, err))
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Contents []mcp.EmbeddedResource `json:"contents"`
+	}
+	if err := json.Unmarshal(encoded, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Contents) == 0 {
+		return nil, NewInvalidParametersError(fmt.Sprintf("cannot embed resource %s: it has no contents", uri))
+	}
+
+	resource := response.Contents[0]
+	if resource.URI == "" {
+		resource.URI = uri
+	}
+	return &resource, nil
+}
+
 // SendPromptsListChangedNotification sends a notification to inform clients that the prompt list has changed.
 // This is called when prompts are added, removed, or updated, allowing clients to refresh their available prompts.
 func (s *serverImpl) SendPromptsListChangedNotification() error {
@@ -391,3 +659,9 @@ func (s *serverImpl) SendPromptsListChangedNotification() error {
 	s.logger.Debug("sent prompts/list_changed notification")
 	return nil
 }
+
+// SendPromptsListChangedNotificationToSession tells the client of one session that
+// the prompt list has changed, without notifying the other clients.
+func (s *serverImpl) SendPromptsListChangedNotificationToSession(sessionID SessionID) error {
+	return s.sendListChangedToSession(sessionID, "notifications/prompts/list_changed")
+}
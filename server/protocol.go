@@ -3,6 +3,9 @@ package server
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+
+	"github.com/localrivet/gomcp/mcp"
 )
 
 // ValidateProtocolVersion validates that the requested protocol version is supported.
@@ -35,7 +38,12 @@ func (s *serverImpl) ValidateProtocolVersion(clientVersion string) (string, erro
 	// Validate the version
 	validatedVersion, err := s.versionDetector.ValidateVersion(clientVersion)
 	if err != nil {
-		return "", fmt.Errorf("unsupported protocol version: %s", clientVersion)
+		return "", &RemediableError{
+			Code:    -32602,
+			Reason:  mcp.ReasonUnsupportedVersion,
+			Message: fmt.Sprintf("unsupported protocol version: %s", clientVersion),
+			Hint:    fmt.Sprintf("use one of the protocol versions the server supports: %s", strings.Join(s.versionDetector.Supported, ", ")),
+		}
 	}
 
 	s.logger.Debug("using validated protocol version", "requestedVersion", clientVersion, "validatedVersion", validatedVersion)
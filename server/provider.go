@@ -0,0 +1,168 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ToolProvider is a reusable pack of tools, resources and prompts, such as a set of
+// filesystem, git or shell tools, that registers itself with a server.
+type ToolProvider interface {
+	// Register adds the provider's tools, resources and prompts to srv
+	Register(srv Server) error
+}
+
+// ToolProviderFunc adapts a function to the ToolProvider interface
+type ToolProviderFunc func(srv Server) error
+
+// Register calls f(srv)
+func (f ToolProviderFunc) Register(srv Server) error {
+	return f(srv)
+}
+
+// ProviderFactory creates a provider registered by name with RegisterProvider
+type ProviderFactory func() (ToolProvider, error)
+
+var (
+	providerFactoriesMu sync.RWMutex
+	providerFactories   = make(map[string]ProviderFactory)
+)
+
+// RegisterProvider makes a provider available by name to UseProviderByName, so that
+// tool packs can register themselves from an init function and servers can choose
+// the packs they load from configuration. Registering the same name twice, or a
+// nil factory, panics.
+//
+// Example:
+//
+//	func init() {
+//	    server.RegisterProvider("git", func() (server.ToolProvider, error) {
+//	        return &GitTools{}, nil
+//	    })
+//	}
+func RegisterProvider(name string, factory ProviderFactory) {
+	providerFactoriesMu.Lock()
+	defer providerFactoriesMu.Unlock()
+
+	if factory == nil {
+		panic("server: RegisterProvider factory is nil for " + name)
+	}
+	if _, exists := providerFactories[name]; exists {
+		panic("server: RegisterProvider called twice for " + name)
+	}
+	providerFactories[name] = factory
+}
+
+// Providers returns the names of the providers registered with RegisterProvider, sorted
+func Providers() []string {
+	providerFactoriesMu.RLock()
+	defer providerFactoriesMu.RUnlock()
+
+	names := make([]string, 0, len(providerFactories))
+	for name := range providerFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UseProvider registers the tools, resources and prompts of reusable tool packs.
+//
+// Each provider's Register method is called with the server. A provider that
+// fails is logged and whatever it registered before failing is removed again.
+//
+// Example:
+//
+//	server.UseProvider(fstools.New("./workspace"), gittools.New())
+func (s *serverImpl) UseProvider(providers ...ToolProvider) Server {
+	for _, provider := range providers {
+		if provider == nil {
+			continue
+		}
+		if err := s.useProvider(provider); err != nil {
+			s.logger.Error("failed to register tool provider",
+				"provider", fmt.Sprintf("%T", provider), "error", err)
+		}
+	}
+	return s
+}
+
+// UseProviderByName registers the tool packs registered under the given names
+// with RegisterProvider, such as packs chosen in a configuration file.
+//
+// Example:
+//
+//	server.UseProviderByName("filesystem", "git")
+func (s *serverImpl) UseProviderByName(names ...string) Server {
+	for _, name := range names {
+		providerFactoriesMu.RLock()
+		factory, exists := providerFactories[name]
+		providerFactoriesMu.RUnlock()
+		if !exists {
+			s.logger.Error("unknown tool provider", "provider", name, "available", Providers())
+			continue
+		}
+
+		provider, err := factory()
+		if err != nil {
+			s.logger.Error("failed to create tool provider", "provider", name, "error", err)
+			continue
+		}
+		if provider == nil {
+			s.logger.Error("tool provider factory returned no provider", "provider", name)
+			continue
+		}
+		if err := s.useProvider(provider); err != nil {
+			s.logger.Error("failed to register tool provider", "provider", name, "error", err)
+		}
+	}
+	return s
+}
+
+// useProvider registers one provider, removing what it added if it fails
+func (s *serverImpl) useProvider(provider ToolProvider) error {
+	tools, resources, prompts := s.registeredNames()
+	err := provider.Register(s)
+	if err == nil {
+		return nil
+	}
+
+	addedTools, addedResources, addedPrompts := s.registeredNames()
+	for name := range addedTools {
+		if !tools[name] {
+			s.RemoveTool(name)
+		}
+	}
+	for uri := range addedResources {
+		if !resources[uri] {
+			s.RemoveResource(uri)
+		}
+	}
+	for name := range addedPrompts {
+		if !prompts[name] {
+			s.RemovePrompt(name)
+		}
+	}
+	return err
+}
+
+// registeredNames returns the names of the registered tools, resources and prompts
+func (s *serverImpl) registeredNames() (tools, resources, prompts map[string]bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tools = make(map[string]bool, len(s.tools))
+	for name := range s.tools {
+		tools[name] = true
+	}
+	resources = make(map[string]bool, len(s.resources))
+	for uri := range s.resources {
+		resources[uri] = true
+	}
+	prompts = make(map[string]bool, len(s.prompts))
+	for name := range s.prompts {
+		prompts[name] = true
+	}
+	return tools, resources, prompts
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mathTools is a tool pack with one tool and one prompt
+type mathTools struct{}
+
+func (mathTools) Register(srv Server) error {
+	srv.Tool("add", "Add two numbers", func(ctx *Context, args struct {
+		A, B float64
+	}) (interface{}, error) {
+		return args.A + args.B, nil
+	})
+	srv.Prompt("explain-math", "Explain a calculation", User("Explain {{expression}}"))
+	return nil
+}
+
+func TestUseProvider(t *testing.T) {
+	s := NewServer("provider-test").GetServer()
+	s.Tool("existing", "Registered before the providers", func(ctx *Context, args struct{}) (interface{}, error) { return nil, nil })
+
+	failing := ToolProviderFunc(func(srv Server) error {
+		srv.Tool("half", "Registered before failing", func(ctx *Context, args struct{}) (interface{}, error) { return nil, nil })
+		srv.Resource("/half", "Registered before failing", func(ctx *Context, args interface{}) (interface{}, error) { return nil, nil })
+		srv.GetServer().ReplaceTool("existing", "Replaced before failing", func(ctx *Context, args struct{}) (interface{}, error) { return nil, nil })
+		return errors.New("missing credentials")
+	})
+	s.UseProvider(mathTools{}, failing, nil)
+
+	assert.Contains(t, s.GetTools(), "add")
+	assert.Contains(t, s.GetPrompts(), "explain-math")
+
+	// A failing provider leaves nothing of its own behind, and what was registered
+	// before it stays
+	assert.NotContains(t, s.GetTools(), "half")
+	assert.NotContains(t, s.GetResources(), "/half")
+	assert.Contains(t, s.GetTools(), "existing")
+}
+
+func TestUseProviderByName(t *testing.T) {
+	RegisterProvider("provider-test-math", func() (ToolProvider, error) { return mathTools{}, nil })
+	RegisterProvider("provider-test-broken", func() (ToolProvider, error) { return nil, errors.New("not configured") })
+	assert.Subset(t, Providers(), []string{"provider-test-broken", "provider-test-math"})
+
+	assert.Panics(t, func() {
+		RegisterProvider("provider-test-math", func() (ToolProvider, error) { return mathTools{}, nil })
+	})
+	assert.Panics(t, func() { RegisterProvider("provider-test-nil", nil) })
+
+	s := NewServer("provider-test").GetServer()
+	s.UseProviderByName("provider-test-broken", "provider-test-unknown", "provider-test-math")
+	assert.Contains(t, s.GetTools(), "add")
+	assert.Len(t, s.GetTools(), 1)
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"github.com/localrivet/gomcp/transport/quic"
+)
+
+// QUIC selects the experimental QUIC transport on the given address.
+//
+// Every connection is a session, and every request arrives on a stream of its
+// own, so requests are multiplexed without head-of-line blocking. Without a TLS
+// configuration the server uses a self-signed certificate.
+//
+// Parameters:
+//   - addr: The UDP address to listen on (e.g., ":4433")
+//   - options: Optional configuration settings (TLS, idle timeout, keep-alive, etc.)
+//
+// Example:
+//
+//	s := server.NewServer("inventory", server.Configure(server.QUIC(":4433")))
+//	// With options:
+//	s.Configure(server.QUIC(":4433",
+//	    quic.WithTLSConfig(tlsConfig),
+//	    quic.WithIdleTimeout(2*time.Minute)))
+func QUIC(addr string, options ...quic.Option) Transport {
+	return func(s *serverImpl) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		// Create QUIC transport in server mode
+		quicTransport := quic.NewTransport(addr, true, options...)
+
+		// Configure the message handler; Run adds the session handler
+		quicTransport.SetMessageHandler(s.handleMessage)
+
+		// Set as the server's transport
+		s.transport = quicTransport
+
+		s.logger.Info("server configured with QUIC transport",
+			"address", addr)
+	}
+}
@@ -0,0 +1,270 @@
+package server
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+)
+
+// maxReconciliationAttempts bounds how often list_changed is re-sent for the same
+// drift, so that clients which never re-list are not notified forever
+const maxReconciliationAttempts = 3
+
+// listMethods maps list requests to the capability whose view they refresh
+var listMethods = map[string]string{
+	"tools/list":               "tools",
+	"resources/list":           "resources",
+	"resources/templates/list": "resources",
+	"prompts/list":             "prompts",
+}
+
+// WithCapabilityReconciliation periodically checks that clients' views of the
+// tools, resources and prompts they have listed match the server's registry, and
+// re-sends the matching list_changed notification when a view has been out of
+// date for a whole interval, for example because a notification was dropped on a
+// flaky transport. A view is re-notified at most three times for the same drift.
+// Zero or less disables reconciliation, which is the default.
+//
+// Each correction is counted in ReconciliationCorrections and published on
+// events.TopicCapabilityReconciled.
+//
+// Example:
+//
+//	server.NewServer("my-server",
+//	    server.WithCapabilityReconciliation(time.Minute),
+//	)
+func WithCapabilityReconciliation(interval time.Duration) Option {
+	return func(s *serverImpl) {
+		if interval <= 0 {
+			s.reconciler = nil
+			return
+		}
+		s.reconciler = newCapabilityReconciler(interval)
+	}
+}
+
+// capabilityReconciler tracks what each session last listed
+type capabilityReconciler struct {
+	interval  time.Duration
+	startOnce sync.Once
+
+	mu          sync.Mutex
+	views       map[SessionID]map[string]*listView
+	corrections map[string]int64
+}
+
+// listView is a session's view of one capability
+type listView struct {
+	// fingerprint identifies the registry's contents when the session listed it
+	fingerprint string
+
+	// driftSince is when the view was first seen to be out of date
+	driftSince time.Time
+
+	// attempts counts list_changed notifications re-sent for the current drift
+	attempts int
+}
+
+// newCapabilityReconciler creates a reconciler; its loop starts once a client is initialized
+func newCapabilityReconciler(interval time.Duration) *capabilityReconciler {
+	return &capabilityReconciler{
+		interval:    interval,
+		views:       make(map[SessionID]map[string]*listView),
+		corrections: make(map[string]int64),
+	}
+}
+
+// start launches the reconciliation loop, which runs until the server shuts down
+func (r *capabilityReconciler) start(s *serverImpl) {
+	r.startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(r.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case now := <-ticker.C:
+					s.reconcileCapabilities(now)
+				case <-s.done:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// recordListView remembers what the request's session was sent by a list request
+func (s *serverImpl) recordListView(ctx *Context) {
+	r := s.reconciler
+	if r == nil || ctx.Request == nil || ctx.Session == nil {
+		return
+	}
+	capability, ok := listMethods[ctx.Request.Method]
+	if !ok {
+		return
+	}
+	fingerprint := s.registryFingerprint(capability)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	views := r.views[ctx.Session.ID]
+	if views == nil {
+		views = make(map[string]*listView)
+		r.views[ctx.Session.ID] = views
+	}
+	views[capability] = &listView{fingerprint: fingerprint}
+}
+
+// reconcileCapabilities re-sends list_changed for every capability that some
+// session has had an out-of-date view of for at least an interval
+func (s *serverImpl) reconcileCapabilities(now time.Time) {
+	r := s.reconciler
+	if r == nil {
+		return
+	}
+
+	fingerprints := map[string]string{
+		"tools":     s.registryFingerprint("tools"),
+		"resources": s.registryFingerprint("resources"),
+		"prompts":   s.registryFingerprint("prompts"),
+	}
+
+	// Find the capabilities to re-notify and how many sessions are behind on each
+	stale := make(map[string]int)
+	r.mu.Lock()
+	for sessionID, views := range r.views {
+		if !s.sessionExists(sessionID) {
+			delete(r.views, sessionID)
+			continue
+		}
+		for capability, view := range views {
+			if view.fingerprint == fingerprints[capability] {
+				view.driftSince = time.Time{}
+				view.attempts = 0
+				continue
+			}
+			if view.driftSince.IsZero() {
+				// Give the regular notification and the client's re-list time to happen
+				view.driftSince = now
+				continue
+			}
+			if now.Sub(view.driftSince) < r.interval || view.attempts >= maxReconciliationAttempts {
+				continue
+			}
+			view.driftSince = now
+			view.attempts++
+			stale[capability]++
+		}
+	}
+	r.mu.Unlock()
+
+	capabilities := make([]string, 0, len(stale))
+	for capability := range stale {
+		capabilities = append(capabilities, capability)
+	}
+	sort.Strings(capabilities)
+
+	for _, capability := range capabilities {
+		var err error
+		switch capability {
+		case "tools":
+			err = s.SendToolsListChangedNotification()
+		case "resources":
+			err = s.SendResourcesListChangedNotification()
+		case "prompts":
+			err = s.SendPromptsListChangedNotification()
+		}
+		if err != nil {
+			s.logger.Warn("failed to re-send list_changed notification", "capability", capability, "error", err)
+			continue
+		}
+
+		r.mu.Lock()
+		r.corrections[capability]++
+		corrections := r.corrections[capability]
+		r.mu.Unlock()
+
+		s.logger.Info("re-sent list_changed notification to reconcile client views",
+			"capability", capability, "sessions", stale[capability])
+		if s.events != nil {
+			evt := events.CapabilityReconciledEvent{
+				Capability:  capability,
+				Sessions:    stale[capability],
+				Corrections: corrections,
+				ObservedAt:  now,
+			}
+			go events.Publish[events.CapabilityReconciledEvent](s.events, events.TopicCapabilityReconciled, evt)
+		}
+	}
+}
+
+// ReconciliationCorrections returns how many times list_changed was re-sent for
+// each capability ("tools", "resources" or "prompts") because a client's view
+// had drifted from the registry. See WithCapabilityReconciliation.
+func (s *serverImpl) ReconciliationCorrections() map[string]int64 {
+	counts := make(map[string]int64)
+	r := s.reconciler
+	if r == nil {
+		return counts
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for capability, count := range r.corrections {
+		counts[capability] = count
+	}
+	return counts
+}
+
+// sessionExists reports whether a session is still open
+func (s *serverImpl) sessionExists(id SessionID) bool {
+	s.mu.RLock()
+	defaultSession := s.defaultSession
+	s.mu.RUnlock()
+	if defaultSession != nil && defaultSession.ID == id {
+		return true
+	}
+	_, ok := s.sessionManager.GetSession(id)
+	return ok
+}
+
+// registryFingerprint summarizes what a list request for a capability returns,
+// so that two fingerprints differ whenever clients should re-list
+func (s *serverImpl) registryFingerprint(capability string) string {
+	type entry struct {
+		key  string
+		data []interface{}
+	}
+	var entries []entry
+
+	s.mu.RLock()
+	switch capability {
+	case "tools":
+		for name, tool := range s.tools {
+			entries = append(entries, entry{name, []interface{}{tool.Description, tool.Schema, tool.Annotations}})
+		}
+	case "resources":
+		for path, resource := range s.resources {
+			entries = append(entries, entry{path, []interface{}{resource.Description, resource.IsTemplate, resource.Annotations}})
+		}
+	case "prompts":
+		for name, prompt := range s.prompts {
+			entries = append(entries, entry{name, []interface{}{prompt.Description, prompt.Arguments, prompt.Annotations}})
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+	h := fnv.New64a()
+	for _, e := range entries {
+		h.Write([]byte(e.key))
+		h.Write([]byte{0})
+		data, _ := json.Marshal(e.data)
+		h.Write(data)
+		h.Write([]byte{0})
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countSent returns how many sent messages contain substr
+func (r *recordingTransport) countSent(substr string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, message := range r.sent {
+		if strings.Contains(message, substr) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestCapabilityReconciliation(t *testing.T) {
+	const interval = time.Minute
+	s := NewServer("reconcile-test", WithCapabilityReconciliation(interval)).GetServer()
+	tr := &recordingTransport{}
+	s.SetTransport(tr)
+
+	reconciled := make(chan events.CapabilityReconciledEvent, 8)
+	events.Subscribe[events.CapabilityReconciledEvent](s.Events(), events.TopicCapabilityReconciled,
+		func(ctx context.Context, evt events.CapabilityReconciledEvent) error {
+			reconciled <- evt
+			return nil
+		})
+
+	tool := func(ctx *Context, args struct{}) (interface{}, error) { return "ok", nil }
+	s.Tool("first", "The first tool", tool)
+	s.Prompt("greeting", "A greeting", User("Hello"))
+
+	send := func(message string) {
+		t.Helper()
+		_, err := s.handleMessage([]byte(message))
+		require.NoError(t, err)
+	}
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	send(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	send(`{"jsonrpc":"2.0","id":3,"method":"prompts/list"}`)
+
+	// Notifications queued before initialization are flushed once
+	const toolsChanged = `"notifications/tools/list_changed"`
+	const promptsChanged = `"notifications/prompts/list_changed"`
+	require.Eventually(t, func() bool { return tr.countSent(toolsChanged) == 1 }, time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return tr.countSent(promptsChanged) == 1 }, time.Second, 10*time.Millisecond)
+	start := time.Now()
+
+	// Views that match the registry need no correction
+	s.reconcileCapabilities(start)
+	s.reconcileCapabilities(start.Add(interval))
+	assert.Empty(t, s.ReconciliationCorrections())
+
+	// A registry change whose notification the client missed is drift
+	s.Tool("second", "The second tool", tool)
+	require.Eventually(t, func() bool { return tr.countSent(toolsChanged) == 2 }, time.Second, 10*time.Millisecond)
+
+	// Drift is tolerated for an interval, then list_changed is re-sent
+	now := start.Add(2 * interval)
+	s.reconcileCapabilities(now)
+	assert.Equal(t, 2, tr.countSent(toolsChanged))
+	s.reconcileCapabilities(now.Add(interval / 2))
+	assert.Equal(t, 2, tr.countSent(toolsChanged))
+	s.reconcileCapabilities(now.Add(interval))
+	assert.Equal(t, 3, tr.countSent(toolsChanged))
+	assert.Equal(t, map[string]int64{"tools": 1}, s.ReconciliationCorrections())
+
+	select {
+	case evt := <-reconciled:
+		assert.Equal(t, "tools", evt.Capability)
+		assert.Equal(t, 1, evt.Sessions)
+		assert.Equal(t, int64(1), evt.Corrections)
+	case <-time.After(time.Second):
+		t.Fatal("expected a CapabilityReconciledEvent")
+	}
+
+	// Re-notifying gives up after a few attempts if the client never re-lists
+	for i := 2; i <= maxReconciliationAttempts+2; i++ {
+		s.reconcileCapabilities(now.Add(time.Duration(i) * interval))
+	}
+	assert.Equal(t, 2+maxReconciliationAttempts, tr.countSent(toolsChanged))
+	assert.Equal(t, int64(maxReconciliationAttempts), s.ReconciliationCorrections()["tools"])
+
+	// Re-listing brings the view up to date again
+	send(`{"jsonrpc":"2.0","id":4,"method":"tools/list"}`)
+	s.Tool("third", "The third tool", tool)
+	require.Eventually(t, func() bool { return tr.countSent(toolsChanged) == 3+maxReconciliationAttempts }, time.Second, 10*time.Millisecond)
+	send(`{"jsonrpc":"2.0","id":5,"method":"tools/list"}`)
+	later := now.Add(10 * interval)
+	s.reconcileCapabilities(later)
+	s.reconcileCapabilities(later.Add(interval))
+	assert.Equal(t, 3+maxReconciliationAttempts, tr.countSent(toolsChanged))
+
+	// Prompts were never out of date
+	assert.Equal(t, 1, tr.countSent(promptsChanged))
+}
+
+func TestCapabilityReconciliationDisabled(t *testing.T) {
+	s := NewServer("reconcile-test").GetServer()
+	assert.Nil(t, s.reconciler)
+	s.reconcileCapabilities(time.Now())
+	assert.Empty(t, s.ReconciliationCorrections())
+
+	s = NewServer("reconcile-test", WithCapabilityReconciliation(time.Second), WithCapabilityReconciliation(0)).GetServer()
+	assert.Nil(t, s.reconciler)
+}
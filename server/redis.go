@@ -0,0 +1,42 @@
+package server
+
+import (
+	"github.com/localrivet/gomcp/transport/redis"
+)
+
+// Redis selects Redis pub/sub through the given server.
+//
+// The server subscribes to the request channel of every client and publishes each
+// response to the requesting client's response channel, so deployments that
+// already run Redis need no other broker.
+//
+// Parameters:
+//   - address: The Redis address (e.g., "localhost:6379", "redis://:password@host:6379",
+//     or "rediss://host:6380" for TLS)
+//   - options: Optional configuration settings (credentials, TLS, channel prefix, etc.)
+//
+// Example:
+//
+//	s := server.NewServer("inventory", server.Configure(server.Redis("localhost:6379")))
+//	// With options:
+//	s.Configure(server.Redis("localhost:6379",
+//	    redis.WithCredentials("mcp", "secret"),
+//	    redis.WithChannelPrefix("inventory")))
+func Redis(address string, options ...redis.Option) Transport {
+	return func(s *serverImpl) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		// Create Redis transport in server mode
+		redisTransport := redis.NewTransport(address, true, options...)
+
+		// Configure the message handler; Run adds the session handler
+		redisTransport.SetMessageHandler(s.handleMessage)
+
+		// Set as the server's transport
+		s.transport = redisTransport
+
+		s.logger.Info("server configured with Redis transport",
+			"address", address)
+	}
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveAndReplaceAtRuntime(t *testing.T) {
+	s := NewServer("registry-test").GetServer()
+	tr := &recordingTransport{}
+	s.SetTransport(tr)
+
+	version := func(v string) func(ctx *Context, args struct{}) (interface{}, error) {
+		return func(ctx *Context, args struct{}) (interface{}, error) { return v, nil }
+	}
+	resource := func(v string) func(ctx *Context, args interface{}) (interface{}, error) {
+		return func(ctx *Context, args interface{}) (interface{}, error) { return v, nil }
+	}
+	s.Tool("plugin", "A plugin tool", version("v1"))
+	s.Tool("core", "A core tool", version("core"))
+	s.Resource("/plugin/data", "Plugin data", resource("v1"))
+	s.Prompt("plugin", "A plugin prompt", User("Hello"))
+	s.TagPrompt("plugin", "plugins")
+
+	send := func(message string) string {
+		t.Helper()
+		response, err := s.handleMessage([]byte(message))
+		require.NoError(t, err)
+		return string(response)
+	}
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	const toolsChanged = `"notifications/tools/list_changed"`
+	const resourcesChanged = `"notifications/resources/list_changed"`
+	const promptsChanged = `"notifications/prompts/list_changed"`
+	require.Eventually(t, func() bool { return tr.countSent(toolsChanged) > 0 }, time.Second, 10*time.Millisecond)
+	tools, resources, prompts := tr.countSent(toolsChanged), tr.countSent(resourcesChanged), tr.countSent(promptsChanged)
+
+	// Replacing swaps the handler and notifies clients
+	s.ReplaceTool("plugin", "A plugin tool, improved", version("v2"))
+	assert.Contains(t, send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"plugin","arguments":{}}}`), "v2")
+	assert.Equal(t, "A plugin tool, improved", s.GetTools()["plugin"].Description)
+	require.Eventually(t, func() bool { return tr.countSent(toolsChanged) == tools+1 }, time.Second, 10*time.Millisecond)
+
+	s.ReplaceResource("/plugin/data", "Plugin data", resource("v2"))
+	assert.Contains(t, send(`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"/plugin/data"}}`), "v2")
+	require.Eventually(t, func() bool { return tr.countSent(resourcesChanged) == resources+1 }, time.Second, 10*time.Millisecond)
+
+	s.ReplacePrompt("plugin", "A plugin prompt, improved", User("Hello again"))
+	assert.Contains(t, send(`{"jsonrpc":"2.0","id":4,"method":"prompts/get","params":{"name":"plugin"}}`), "Hello again")
+	assert.Equal(t, []string{"plugins"}, mcp.TagsFromAnnotations(s.GetPrompts()["plugin"].Annotations), "tags are kept")
+	require.Eventually(t, func() bool { return tr.countSent(promptsChanged) == prompts+1 }, time.Second, 10*time.Millisecond)
+
+	// Replacing something that is not registered changes nothing
+	s.ReplaceTool("missing", "Not registered", version("v1"))
+	s.ReplaceResource("/missing", "Not registered", resource("v1"))
+	s.ReplacePrompt("missing", "Not registered", User("Hello"))
+	assert.NotContains(t, s.GetTools(), "missing")
+	assert.NotContains(t, s.GetResources(), "/missing")
+	assert.NotContains(t, s.GetPrompts(), "missing")
+
+	// Removing unregisters and notifies clients
+	s.RemoveTool("plugin")
+	s.RemoveResource("/plugin/data")
+	s.RemovePrompt("plugin")
+	assert.Contains(t, send(`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"plugin","arguments":{}}}`), "not found")
+	assert.NotContains(t, send(`{"jsonrpc":"2.0","id":6,"method":"tools/list"}`), `"plugin"`)
+	assert.Contains(t, send(`{"jsonrpc":"2.0","id":7,"method":"resources/read","params":{"uri":"/plugin/data"}}`), `"error"`)
+	assert.Contains(t, send(`{"jsonrpc":"2.0","id":8,"method":"prompts/get","params":{"name":"plugin"}}`), `"error"`)
+	require.Eventually(t, func() bool {
+		return tr.countSent(toolsChanged) == tools+2 &&
+			tr.countSent(resourcesChanged) == resources+2 &&
+			tr.countSent(promptsChanged) == prompts+2
+	}, time.Second, 10*time.Millisecond)
+
+	// Clients initializing later no longer see the emptied capabilities
+	capabilities := s.serverCapabilities()
+	assert.NotNil(t, capabilities.Tools)
+	assert.Nil(t, capabilities.Resources)
+	assert.Nil(t, capabilities.Prompts)
+}
+
+func TestReplaceToolWhileServing(t *testing.T) {
+	s := NewServer("registry-test").GetServer()
+	s.SetTransport(&recordingTransport{})
+	s.Tool("plugin", "A plugin tool", func(ctx *Context, args struct{}) (interface{}, error) { return "v0", nil })
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 50; i++ {
+			v := fmt.Sprintf("v%d", i)
+			s.ReplaceTool("plugin", "A plugin tool", func(ctx *Context, args struct{}) (interface{}, error) { return v, nil })
+		}
+	}()
+
+	// Every call finds the tool, whichever version it gets
+	for i := 0; i < 50; i++ {
+		response, err := s.handleMessage([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"plugin","arguments":{}}}`, i)))
+		require.NoError(t, err)
+		assert.NotContains(t, string(response), `"error"`)
+	}
+	wg.Wait()
+}
+
+func TestBatchChanges(t *testing.T) {
+	s := NewServer("registry-test").GetServer()
+	tr := &recordingTransport{}
+	s.SetTransport(tr)
+	handler := func(ctx *Context, args struct{}) (interface{}, error) { return "ok", nil }
+	s.Tool("search_v1", "Search", handler)
+
+	response, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`))
+	require.NoError(t, err)
+	require.NotEmpty(t, response)
+	_, err = s.handleMessage([]byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	require.NoError(t, err)
+
+	const toolsChanged = `"notifications/tools/list_changed"`
+	const resourcesChanged = `"notifications/resources/list_changed"`
+	const promptsChanged = `"notifications/prompts/list_changed"`
+	require.Eventually(t, func() bool { return tr.countSent(toolsChanged) > 0 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	tools, resources := tr.countSent(toolsChanged), tr.countSent(resourcesChanged)
+
+	s.BatchChanges(func(srv Server) {
+		srv.GetServer().RemoveTool("search_v1")
+		srv.Tool("search", "Search", handler)
+		srv.Tool("index", "Index", handler)
+
+		// Batches nest, notifying once the outermost returns
+		srv.GetServer().BatchChanges(func(srv Server) {
+			srv.Resource("/index/stats", "Index statistics", func(ctx *Context, args interface{}) (interface{}, error) { return "ok", nil })
+		})
+		assert.Equal(t, resources, tr.countSent(resourcesChanged))
+	})
+
+	require.Eventually(t, func() bool {
+		return tr.countSent(toolsChanged) == tools+1 && tr.countSent(resourcesChanged) == resources+1
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, tools+1, tr.countSent(toolsChanged), "one notification for the whole batch")
+	assert.Equal(t, resources+1, tr.countSent(resourcesChanged))
+	assert.Zero(t, tr.countSent(promptsChanged), "unchanged lists are not notified")
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/localrivet/gomcp/transport"
+)
+
+var (
+	// errUnknownRequest is returned when a response matches no pending request
+	errUnknownRequest = errors.New("unknown request")
+
+	// errWrongSession is returned when a response arrives from a session other
+	// than the one the request was sent to
+	errWrongSession = errors.New("response from a session the request was not sent to")
+)
+
+// RequestSessionMap records which client session each server-initiated request,
+// such as sampling/createMessage or roots/list, was sent to.
+//
+// On transports that serve several sessions over one shared channel, such as MQTT
+// and NATS, the server uses it to send the request to that session only and to
+// accept the response from that session only, so that clients cannot answer, or
+// accidentally resolve, requests meant for another client. The default
+// MemoryRequestSessionMap keeps the mapping in process memory.
+//
+// Implementations must be safe for concurrent use.
+type RequestSessionMap interface {
+	// Bind records that the request with the given ID was sent to a session.
+	Bind(requestID int, sessionID SessionID)
+
+	// Lookup returns the session a request was sent to.
+	// The boolean is false if the request is not bound to a session.
+	Lookup(requestID int) (SessionID, bool)
+
+	// Unbind forgets a request. Unbinding an unknown request is a no-op.
+	Unbind(requestID int)
+}
+
+// MemoryRequestSessionMap is an in-memory RequestSessionMap.
+type MemoryRequestSessionMap struct {
+	mu       sync.RWMutex
+	sessions map[int]SessionID
+}
+
+// NewMemoryRequestSessionMap creates an empty in-memory request session map.
+func NewMemoryRequestSessionMap() *MemoryRequestSessionMap {
+	return &MemoryRequestSessionMap{
+		sessions: make(map[int]SessionID),
+	}
+}
+
+// Bind records that the request with the given ID was sent to a session.
+func (m *MemoryRequestSessionMap) Bind(requestID int, sessionID SessionID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[requestID] = sessionID
+}
+
+// Lookup returns the session a request was sent to.
+func (m *MemoryRequestSessionMap) Lookup(requestID int) (SessionID, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sessionID, ok := m.sessions[requestID]
+	return sessionID, ok
+}
+
+// Unbind forgets a request.
+func (m *MemoryRequestSessionMap) Unbind(requestID int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, requestID)
+}
+
+// WithRequestSessionMap sets how server-initiated requests are mapped to the
+// sessions they were sent to; the default is a MemoryRequestSessionMap.
+//
+// Example:
+//
+//	server.NewServer("my-server",
+//	    server.WithRequestSessionMap(server.NewMemoryRequestSessionMap()),
+//	)
+func WithRequestSessionMap(sessions RequestSessionMap) Option {
+	return func(s *serverImpl) {
+		if sessions == nil {
+			return
+		}
+		s.requestSessions = sessions
+	}
+}
+
+// sendToSession sends a message to one client session. Transports serving several
+// sessions over a shared channel deliver it to that session only; other transports,
+// or an empty session ID, send it with Send.
+func (s *serverImpl) sendToSession(sessionID SessionID, message []byte) error {
+	if ss, ok := s.transport.(transport.SessionSender); ok && sessionID != "" {
+		return ss.SendToSession(string(sessionID), message)
+	}
+	return s.transport.Send(message)
+}
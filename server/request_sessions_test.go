@@ -0,0 +1,226 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sharedChannelTransport serves several sessions over one channel and records what
+// is sent to each session
+type sharedChannelTransport struct {
+	recordingTransport
+
+	sessionMu   sync.Mutex
+	sessionSent map[string][]string
+}
+
+func (r *sharedChannelTransport) SendToSession(sessionID string, message []byte) error {
+	r.sessionMu.Lock()
+	defer r.sessionMu.Unlock()
+	if r.sessionSent == nil {
+		r.sessionSent = make(map[string][]string)
+	}
+	r.sessionSent[sessionID] = append(r.sessionSent[sessionID], string(message))
+	return nil
+}
+
+// sentTo returns the messages sent to a session
+func (r *sharedChannelTransport) sentTo(sessionID string) []string {
+	r.sessionMu.Lock()
+	defer r.sessionMu.Unlock()
+	return append([]string(nil), r.sessionSent[sessionID]...)
+}
+
+func TestServerRequestsAreRoutedToTheirSession(t *testing.T) {
+	s := NewServer("shared-channel-test").GetServer()
+	tr := &sharedChannelTransport{}
+	s.SetTransport(tr)
+
+	for _, sessionID := range []string{"client-a", "client-b"} {
+		_, err := s.handleSessionMessage(sessionID, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{"sampling":{}},"clientInfo":{"name":"test","version":"1.0"}}}`))
+		require.NoError(t, err)
+	}
+
+	options := DefaultSamplingOptions()
+	options.IgnoreCapability = true
+	options.Timeout = 2 * time.Second
+	options.MaxRetries = 0
+
+	type samplingResult struct {
+		response *SamplingResponse
+		err      error
+	}
+	done := make(chan samplingResult, 1)
+	go func() {
+		response, err := s.RequestSamplingWithSessionAndOptions("client-a", "2025-03-26",
+			[]SamplingMessage{{Role: "user", Content: SamplingMessageContent{Type: "text", Text: "Hi"}}},
+			SamplingModelPreferences{}, "", 100, options)
+		done <- samplingResult{response, err}
+	}()
+
+	// The request goes to its session only
+	require.Eventually(t, func() bool { return len(tr.sentTo("client-a")) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Empty(t, tr.sentTo("client-b"))
+	assert.False(t, tr.sentContaining("sampling/createMessage"))
+
+	var request struct {
+		ID     int64  `json:"id"`
+		Method string `json:"method"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(tr.sentTo("client-a")[0]), &request))
+	assert.Equal(t, "sampling/createMessage", request.Method)
+
+	respond := func(sessionID, text string) {
+		t.Helper()
+		response := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"role":"assistant","content":{"type":"text","text":%q}}}`, request.ID, text)
+		_, err := s.handleSessionMessage(sessionID, []byte(response))
+		require.NoError(t, err)
+	}
+
+	// Another session cannot answer it
+	respond("client-b", "spoofed")
+	select {
+	case result := <-done:
+		t.Fatalf("request resolved by the wrong session: %+v", result)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The session it was sent to can
+	respond("client-a", "hello")
+	select {
+	case result := <-done:
+		require.NoError(t, result.err)
+		assert.Equal(t, "hello", result.response.Content.Text)
+	case <-time.After(time.Second):
+		t.Fatal("expected the sampling request to be resolved")
+	}
+
+	_, bound := s.requestTracker.sessions.Lookup(int(request.ID))
+	assert.False(t, bound)
+}
+
+func TestSamplingFromContextTargetsItsSession(t *testing.T) {
+	s := NewServer("sampling-session-test").GetServer()
+	tr := &sharedChannelTransport{}
+	s.SetTransport(tr)
+
+	s.Tool("ask", "Ask the client's model", func(ctx *Context, args struct {
+		Type      string `json:"type"`
+		MaxTokens int    `json:"maxTokens"`
+	}) (string, error) {
+		content := SamplingMessageContent{Type: args.Type, Text: "Hi", Data: "AAAA", MimeType: "audio/wav"}
+		response, err := ctx.RequestSampling([]SamplingMessage{{Role: "user", Content: content}}, SamplingModelPreferences{}, "", args.MaxTokens)
+		if err != nil {
+			return "", err
+		}
+		return response.Content.Text, nil
+	})
+
+	send := func(sessionID, request string) string {
+		t.Helper()
+		response, err := s.handleSessionMessage(sessionID, []byte(request))
+		require.NoError(t, err)
+		return string(response)
+	}
+	send("client-a", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{"sampling":{}},"clientInfo":{"name":"a","version":"1.0"}}}`)
+	send("client-b", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{"sampling":{}},"clientInfo":{"name":"b","version":"1.0"}}}`)
+	send("client-c", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"c","version":"1.0"}}}`)
+
+	t.Run("routed", func(t *testing.T) {
+		done := make(chan string, 1)
+		go func() {
+			done <- send("client-b", `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"ask","arguments":{"type":"text","maxTokens":100}}}`)
+		}()
+
+		// The request goes to the session that called the tool, not the default one
+		var request struct {
+			ID int64 `json:"id"`
+		}
+		require.Eventually(t, func() bool {
+			for _, message := range tr.sentTo("client-b") {
+				if strings.Contains(message, "sampling/createMessage") {
+					return json.Unmarshal([]byte(message), &request) == nil
+				}
+			}
+			return false
+		}, time.Second, 10*time.Millisecond)
+		assert.False(t, tr.sentContaining("sampling/createMessage"))
+		assert.Empty(t, tr.sentTo("client-a"))
+
+		send("client-b", fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"role":"assistant","content":{"type":"text","text":"hello b"}}}`, request.ID))
+		select {
+		case response := <-done:
+			assert.Contains(t, response, "hello b")
+		case <-time.After(time.Second):
+			t.Fatal("expected the tool call to complete")
+		}
+	})
+
+	t.Run("gated", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			sessionID string
+			arguments string
+			err       string
+		}{
+			{"sampling not declared", "client-c", `{"type":"text","maxTokens":100}`, "client does not support sampling"},
+			{"audio before 2025-03-26", "client-b", `{"type":"audio","maxTokens":100}`, "audio content requires protocol version 2025-03-26"},
+			{"too many tokens", "client-a", `{"type":"text","maxTokens":100000}`, "maxTokens exceeds maximum value (8192)"},
+			{"no tokens", "client-a", `{"type":"text","maxTokens":0}`, "maxTokens must be positive"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				sent := len(tr.sentTo(tt.sessionID))
+				response := send(tt.sessionID, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"ask","arguments":`+tt.arguments+`}}`)
+				assert.Contains(t, response, tt.err)
+
+				// Nothing is sent to a client that cannot answer
+				assert.Len(t, tr.sentTo(tt.sessionID), sent)
+			})
+		}
+	})
+
+	_, err := s.RequestSamplingWithSessionAndOptions("client-z", "", []SamplingMessage{{Role: "user", Content: SamplingMessageContent{Type: "text", Text: "Hi"}}},
+		SamplingModelPreferences{}, "", 100, DefaultSamplingOptions())
+	assert.ErrorContains(t, err, "sampling session not found: client-z")
+}
+
+func TestRequestTrackerSessions(t *testing.T) {
+	sessions := NewMemoryRequestSessionMap()
+	rt := newRequestTracker(sessions)
+
+	rt.addRequest(1, "client-a")
+	sessionID, bound := sessions.Lookup(1)
+	assert.True(t, bound)
+	assert.Equal(t, SessionID("client-a"), sessionID)
+
+	assert.ErrorIs(t, rt.resolveRequest(1, "client-b", json.RawMessage(`{}`)), errWrongSession)
+	assert.ErrorIs(t, rt.resolveRequest(2, "client-a", json.RawMessage(`{}`)), errUnknownRequest)
+	assert.NoError(t, rt.resolveRequest(1, "client-a", json.RawMessage(`{}`)))
+	_, bound = sessions.Lookup(1)
+	assert.False(t, bound)
+
+	// Transports that do not tell sessions apart match any request
+	rt.addRequest(3, "client-a")
+	assert.NoError(t, rt.resolveRequest(3, "", json.RawMessage(`{}`)))
+
+	// Requests to the only peer are not bound
+	rt.addRequest(4, "")
+	_, bound = sessions.Lookup(4)
+	assert.False(t, bound)
+	assert.NoError(t, rt.resolveRequest(4, "client-b", json.RawMessage(`{}`)))
+
+	// Removed requests are unbound
+	rt.addRequest(5, "client-a")
+	rt.removeRequest(5)
+	_, bound = sessions.Lookup(5)
+	assert.False(t, bound)
+	assert.Equal(t, 0, rt.getPendingCount())
+}
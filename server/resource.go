@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/localrivet/gomcp/events"
 	"github.com/localrivet/gomcp/mcp"
+	"github.com/localrivet/gomcp/transport"
 	"github.com/localrivet/gomcp/util/schema"
 	"github.com/localrivet/wilduri"
 )
@@ -60,7 +62,7 @@ type Resource struct {
 // Example:
 //
 //	func(ctx *Context, args *StructType) (interface{}, error)
-func (s *serverImpl) Resource(path, description string, handler interface{}) Server {
+func (s *serverImpl) Resource(path, description string, handler interface{}, annotations ...map[string]interface{}) Server {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -105,6 +107,11 @@ func (s *serverImpl) Resource(path, description string, handler interface{}) Ser
 		IsTemplate:  isTemplate,
 	}
 
+	// Only keep annotations if any were provided
+	if len(annotations) > 0 {
+		resource.Annotations = mergeAnnotations(annotations...)
+	}
+
 	// Store the resource
 	s.resources[path] = resource
 
@@ -215,6 +222,12 @@ func (s *serverImpl) ProcessResourceSubscribe(ctx *Context) (interface{}, error)
 		return nil, fmt.Errorf("uri parameter is required")
 	}
 
+	// Subscriptions to resources hidden from the session succeed like those to
+	// unknown URIs, but are never recorded so no updates reach the session
+	if resource, _, found := s.findResourceAndExtractParams(params.URI); found && !s.visibleTo(ctx.Session, resource.Path) {
+		return map[string]interface{}{}, nil
+	}
+
 	// Get session ID from context
 	sessionID, ok := ctx.Metadata["sessionID"].(string)
 	if !ok {
@@ -286,6 +299,35 @@ func (s *serverImpl) ProcessResourceUnsubscribe(ctx *Context) (interface{}, erro
 	return map[string]interface{}{}, nil
 }
 
+// notifyResourceUpdated sends notifications/resources/updated for a resource to the
+// sessions subscribed to it. Transports that cannot address a single session send
+// it once to all clients if any session is subscribed.
+func (s *serverImpl) notifyResourceUpdated(uri string) {
+	sessions, err := s.sessionManager.ListSessions()
+	if err != nil {
+		s.logger.Error("failed to list sessions for resource update", "uri", uri, "error", err)
+		return
+	}
+
+	_, perSession := s.transport.(transport.SessionSender)
+	params := map[string]interface{}{"uri": uri}
+	for _, session := range sessions {
+		for _, subscribed := range session.ResourceSubscriptions {
+			if subscribed != uri {
+				continue
+			}
+			if !perSession {
+				s.sendNotification("notifications/resources/updated", params)
+				return
+			}
+			if err := s.writeSessionNotification(session, "notifications/resources/updated", params); err != nil {
+				s.logger.Error("failed to send notification", "method", "notifications/resources/updated", "sessionID", session.ID, "error", err)
+			}
+			break
+		}
+	}
+}
+
 // ProcessResourceTemplatesList processes a resource templates list request.
 // This returns a list of all resource templates (resources with path parameters)
 // registered with the server. Supports pagination through an optional cursor parameter.
@@ -295,14 +337,17 @@ func (s *serverImpl) ProcessResourceTemplatesList(ctx *Context) (interface{}, er
 
 	// Get pagination cursor if provided
 	var cursor string
+	var filter *ListFilter
 	if ctx.Request.Params != nil {
 		var params struct {
-			Cursor string `json:"cursor"`
+			Cursor string      `json:"cursor"`
+			Filter *ListFilter `json:"filter,omitempty"`
 		}
 		if err := json.Unmarshal(ctx.Request.Params, &params); err != nil {
 			return nil, fmt.Errorf("invalid params: %w", err)
 		}
 		cursor = params.Cursor
+		filter = params.Filter
 	}
 
 	// For now, we'll use a simple pagination that returns all template resources
@@ -312,12 +357,18 @@ func (s *serverImpl) ProcessResourceTemplatesList(ctx *Context) (interface{}, er
 
 	// Convert resources to the expected format
 	i := 0
-	for path, resource := range s.resources {
+	for _, path := range sortedResourcePaths(s.resources) {
+		resource := s.resources[path]
+
 		// Skip if not a template or if we haven't reached the cursor yet
 		if !resource.IsTemplate || (cursor != "" && path <= cursor) {
 			continue
 		}
 
+		if !filter.Matches(resource.Path, resource.Annotations) || !s.visibleTo(ctx.Session, resource.Path) {
+			continue
+		}
+
 		// Use the full path as the name if no other name is available
 		name := resource.Path
 		if path != "" {
@@ -333,12 +384,15 @@ func (s *serverImpl) ProcessResourceTemplatesList(ctx *Context) (interface{}, er
 		}
 
 		// Add the template to the result
-		templates = append(templates, ResourceTemplateInfo{
+		templateInfo := ResourceTemplateInfo{
 			URITemplate: resource.Path,
 			Name:        name,
 			Description: resource.Description,
 			MimeType:    mimeType,
-		})
+			Annotations: resource.Annotations,
+		}
+		templateInfo.Title, templateInfo.Icons = specDisplay(ctx, resource.Annotations)
+		templates = append(templates, templateInfo)
 
 		i++
 		if i >= maxPageSize {
@@ -398,12 +452,17 @@ func ensureContentsArray(response map[string]interface{}, uri string) map[string
 					if contentMap["text"] == nil {
 						contentMap["text"] = "Empty content"
 					}
-				} else {
-					// If content doesn't exist or is nil, create a default one
+				} else if contentMap["blob"] == nil {
+					// If content doesn't exist or is nil, create one from the text, or a default one
+					// (blob contents carry their data in blob)
+					text, _ := contentMap["text"].(string)
+					if text == "" {
+						text = "Empty content"
+					}
 					contentMap["content"] = []interface{}{
 						map[string]interface{}{
 							"type": "text",
-							"text": "Empty content",
+							"text": text,
 						},
 					}
 					// Ensure text field at contents level
@@ -464,8 +523,8 @@ func ensureContentsArray(response map[string]interface{}, uri string) map[string
 				if contentMap["text"] == nil {
 					contentMap["text"] = "Empty content"
 				}
-			} else {
-				// If content doesn't exist or is nil, create a default one
+			} else if contentMap["blob"] == nil {
+				// If content doesn't exist or is nil, create a default one (blob contents carry their data in blob)
 				contentMap["content"] = []interface{}{
 					map[string]interface{}{
 						"type": "text",
@@ -697,8 +756,13 @@ func (s *serverImpl) ProcessResourceRequest(ctx *Context) (interface{}, error) {
 
 	// Find the resource and extract parameters
 	resource, pathParams, found := s.findResourceAndExtractParams(uri)
-	if !found {
-		return nil, fmt.Errorf("resource not found: %s", uri)
+	if !found || !s.visibleTo(ctx.Session, resource.Path) {
+		return nil, &RemediableError{
+			Code:    -32002,
+			Reason:  mcp.ReasonResourceNotFound,
+			Message: fmt.Sprintf("resource not found: %s", uri),
+			Hint:    "call resources/list and resources/templates/list for the resources the server provides",
+		}
 	}
 
 	// Publish resource access event
@@ -810,14 +874,9 @@ func (s *serverImpl) ProcessResourceRequest(ctx *Context) (interface{}, error) {
 		s.logger.Debug("failed to publish resource accessed event", "error", publishErr)
 	}
 
-	// Use the public FormatResourceResponse function for consistent formatting
-	version := ctx.Version
-	if version == "" {
-		// Default to latest protocol version
-		version = "2025-03-26"
-	}
-
-	return FormatResourceResponse(uri, result, version), nil
+	// Format the result in the canonical shape, with a contents array; it is adapted
+	// to the requesting session's protocol version when sent
+	return FormatResourceResponse(uri, result, canonicalVersion), nil
 }
 
 // ProcessResourceList processes a resource list request.
@@ -830,14 +889,17 @@ func (s *serverImpl) ProcessResourceList(ctx *Context) (interface{}, error) {
 
 	// Get pagination cursor if provided
 	var cursor string
+	var filter *ListFilter
 	if ctx.Request.Params != nil {
 		var params struct {
-			Cursor string `json:"cursor"`
+			Cursor string      `json:"cursor"`
+			Filter *ListFilter `json:"filter,omitempty"`
 		}
 		if err := json.Unmarshal(ctx.Request.Params, &params); err != nil {
 			return nil, fmt.Errorf("invalid params: %w", err)
 		}
 		cursor = params.Cursor
+		filter = params.Filter
 	}
 
 	// For now, we'll use a simple pagination that returns all resources
@@ -847,7 +909,9 @@ func (s *serverImpl) ProcessResourceList(ctx *Context) (interface{}, error) {
 
 	// Convert resources to the expected format
 	i := 0
-	for path, resource := range s.resources {
+	for _, path := range sortedResourcePaths(s.resources) {
+		resource := s.resources[path]
+
 		// Skip template resources - they should only appear in resources/templates/list
 		if resource.IsTemplate {
 			continue
@@ -858,6 +922,10 @@ func (s *serverImpl) ProcessResourceList(ctx *Context) (interface{}, error) {
 			continue
 		}
 
+		if !filter.Matches(resource.Path, resource.Annotations) || !s.visibleTo(ctx.Session, resource.Path) {
+			continue
+		}
+
 		// Use the full path as the name if no other name is available
 		name := resource.Path
 		if path != "" {
@@ -878,7 +946,9 @@ func (s *serverImpl) ProcessResourceList(ctx *Context) (interface{}, error) {
 			Name:        name,
 			Description: resource.Description,
 			MimeType:    mimeType,
+			Annotations: resource.Annotations,
 		}
+		resourceInfo.Title, resourceInfo.Icons = specDisplay(ctx, resource.Annotations)
 
 		resources = append(resources, resourceInfo)
 
@@ -894,6 +964,17 @@ func (s *serverImpl) ProcessResourceList(ctx *Context) (interface{}, error) {
 	return NewResourceListResponse(resources, nextCursor), nil
 }
 
+// sortedResourcePaths returns the registered resource paths in order,
+// so list cursors (the last path returned) are stable across pages.
+func sortedResourcePaths(resources map[string]*Resource) []string {
+	paths := make([]string, 0, len(resources))
+	for path := range resources {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 // findResourceAndExtractParams finds a resource matching the given URI
 // and extracts any path parameters from the URI.
 // Returns the matched resource, extracted parameters, and a boolean indicating success.
@@ -979,6 +1060,61 @@ func extractSchemaFromHandler(handler interface{}) (map[string]interface{}, erro
 	}, nil
 }
 
+// RemoveResource unregisters the resource or resource template registered at path,
+// and notifies clients that the resource list changed. It is safe to call while the
+// server is running.
+//
+// Example:
+//
+//	server.RemoveResource("/users/{id}")
+func (s *serverImpl) RemoveResource(path string) Server {
+	s.mu.Lock()
+	_, exists := s.resources[path]
+	delete(s.resources, path)
+	remaining := len(s.resources) > 0
+	s.mu.Unlock()
+
+	if !exists {
+		s.logger.Error("cannot remove unknown resource", "path", path)
+		return s
+	}
+
+	// Emit resource unregistration event
+	go func() {
+		events.Publish[events.ResourceUnregisteredEvent](s.events, events.TopicResourceUnregistered, events.ResourceUnregisteredEvent{
+			URI:            path,
+			UnregisteredAt: time.Now(),
+		})
+	}()
+
+	// Mark resources as changed and send the notification
+	s.capabilityCache.MarkResourcesRemoved(remaining)
+	s.sendCapabilityNotification("resources")
+
+	s.logger.Debug("resource removed", "path", path)
+	return s
+}
+
+// ReplaceResource replaces the resource registered at path in a single step and
+// notifies clients that the resource list changed. It takes the same parameters as
+// Resource. Replacing a resource that is not registered logs an error and leaves
+// the registry unchanged.
+//
+// Example:
+//
+//	server.ReplaceResource("/users/{id}", "User by ID", getUserV2)
+func (s *serverImpl) ReplaceResource(path, description string, handler interface{}, annotations ...map[string]interface{}) Server {
+	s.mu.RLock()
+	_, exists := s.resources[path]
+	s.mu.RUnlock()
+
+	if !exists {
+		s.logger.Error("cannot replace unknown resource", "path", path)
+		return s
+	}
+	return s.Resource(path, description, handler, annotations...)
+}
+
 // SendResourcesListChangedNotification sends a notification to inform clients that the resource list has changed.
 // This is called when resources are added, removed, or updated, allowing clients to refresh their available resources.
 func (s *serverImpl) SendResourcesListChangedNotification() error {
@@ -1017,3 +1153,9 @@ func (s *serverImpl) SendResourcesListChangedNotification() error {
 	s.logger.Debug("sent resources/list_changed notification")
 	return nil
 }
+
+// SendResourcesListChangedNotificationToSession tells the client of one session that
+// the resource list has changed, without notifying the other clients.
+func (s *serverImpl) SendResourcesListChangedNotificationToSession(sessionID SessionID) error {
+	return s.sendListChangedToSession(sessionID, "notifications/resources/list_changed")
+}
@@ -253,7 +253,9 @@ func formatResourceV20241105(uri string, result interface{}) map[string]interfac
 				result["metadata"] = metadata
 			}
 
-			return result
+			// Format the flattened items as any content array, so that types
+			// 2024-11-05 lacks, such as audio, are converted too
+			return formatResourceV20241105(uri, result)
 		}
 
 		// No content array, create a default one
@@ -1,7 +1,11 @@
 package server
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"net/http"
+
+	"github.com/localrivet/gomcp/mcp"
 )
 
 // Response types for MCP protocol messages
@@ -19,27 +23,26 @@ type ToolInfo struct {
 	Description string                 `json:"description"`
 	InputSchema interface{}            `json:"inputSchema"`
 	Annotations map[string]interface{} `json:"annotations,omitempty"`
+
+	// Title and Icons are only sent to clients of the draft protocol
+	Title string     `json:"title,omitempty"`
+	Icons []mcp.Icon `json:"icons,omitempty"`
 }
 
 // ToolCallResponse represents the response for tools/call requests
 type ToolCallResponse struct {
 	Content []ContentItem `json:"content"`
 	IsError bool          `json:"isError"`
-}
 
-// ContentItem represents a single content item in tool/prompt responses
-type ContentItem struct {
-	Type     string      `json:"type"`
-	Text     string      `json:"text,omitempty"`
-	ImageURL string      `json:"imageUrl,omitempty"`
-	AltText  string      `json:"altText,omitempty"`
-	URL      string      `json:"url,omitempty"`
-	Title    string      `json:"title,omitempty"`
-	MimeType string      `json:"mimeType,omitempty"`
-	Data     interface{} `json:"data,omitempty"`
-	Filename string      `json:"filename,omitempty"`
+	// StructuredContent is the tool's result as a JSON object, only sent to clients
+	// of the draft protocol. Content then holds its JSON as a text fallback.
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
 }
 
+// ContentItem represents a single content item in tool/prompt responses. It is an
+// alias to the shared mcp.ContentItem type.
+type ContentItem = mcp.ContentItem
+
 // PromptListResponse represents the response for prompts/list requests
 type PromptListResponse struct {
 	Prompts    []PromptInfo `json:"prompts"`
@@ -48,9 +51,14 @@ type PromptListResponse struct {
 
 // PromptInfo represents information about a single prompt
 type PromptInfo struct {
-	Name        string           `json:"name"`
-	Description string           `json:"description"`
-	Arguments   []PromptArgument `json:"arguments,omitempty"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Arguments   []PromptArgument       `json:"arguments,omitempty"`
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+
+	// Title and Icons are only sent to clients of the draft protocol
+	Title string     `json:"title,omitempty"`
+	Icons []mcp.Icon `json:"icons,omitempty"`
 }
 
 // PromptGetResponse represents the response for prompts/get requests
@@ -59,30 +67,23 @@ type PromptGetResponse struct {
 	Messages    []PromptMessage `json:"messages"`
 }
 
-// PromptMessage represents a single message in a prompt response
-type PromptMessage struct {
-	Role    string        `json:"role"`
-	Content PromptContent `json:"content"`
-}
+// PromptMessage represents a single message in a prompt response. It is an alias
+// to the shared mcp.PromptMessage type.
+type PromptMessage = mcp.PromptMessage
 
 // ContentType represents the type of content in a prompt
-type ContentType string
+type ContentType = mcp.ContentType
 
 // ContentTypeText is used for plain text content
-const ContentTypeText ContentType = "text"
+const ContentTypeText = mcp.ContentTypeText
 
-// PromptContent represents the content of a prompt message
-type PromptContent struct {
-	Type ContentType `json:"type"`
-	Text string      `json:"text,omitempty"`
-}
+// PromptContent represents the content of a prompt message. It is an alias to the
+// shared mcp.PromptContent type.
+type PromptContent = mcp.PromptContent
 
-// PromptArgument represents an argument for a prompt
-type PromptArgument struct {
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	Required    bool   `json:"required,omitempty"`
-}
+// PromptArgument represents an argument for a prompt. It is an alias to the shared
+// mcp.PromptArgument type.
+type PromptArgument = mcp.PromptArgument
 
 // ResourceListResponse represents the response for resources/list requests
 type ResourceListResponse struct {
@@ -92,10 +93,15 @@ type ResourceListResponse struct {
 
 // ResourceInfo represents information about a single resource
 type ResourceInfo struct {
-	URI         string `json:"uri"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	MimeType    string `json:"mimeType"`
+	URI         string                 `json:"uri"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	MimeType    string                 `json:"mimeType"`
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+
+	// Title and Icons are only sent to clients of the draft protocol
+	Title string     `json:"title,omitempty"`
+	Icons []mcp.Icon `json:"icons,omitempty"`
 }
 
 // ResourceTemplatesListResponse represents the response for resources/templates/list requests
@@ -111,6 +117,10 @@ type ResourceTemplateInfo struct {
 	Description string                 `json:"description"`
 	MimeType    string                 `json:"mimeType,omitempty"`
 	Annotations map[string]interface{} `json:"annotations,omitempty"`
+
+	// Title and Icons are only sent to clients of the draft protocol
+	Title string     `json:"title,omitempty"`
+	Icons []mcp.Icon `json:"icons,omitempty"`
 }
 
 // ResourceReadResponse represents the response for resources/read requests
@@ -118,18 +128,15 @@ type ResourceReadResponse struct {
 	Contents []ResourceContent `json:"contents"`
 }
 
-// ResourceContent represents a single resource content item
-type ResourceContent struct {
-	URI     string        `json:"uri"`
-	Text    string        `json:"text,omitempty"`
-	Content []ContentItem `json:"content,omitempty"`
-}
+// ResourceContent represents a single resource content item. It is an alias to
+// the shared mcp.ResourceContent type.
+type ResourceContent = mcp.ResourceContent
 
 // InitializeResponse represents the response for initialize requests
 type InitializeResponse struct {
 	ProtocolVersion string                 `json:"protocolVersion"`
 	ServerInfo      ServerInfo             `json:"serverInfo"`
-	Capabilities    map[string]interface{} `json:"capabilities"`
+	Capabilities    mcp.ServerCapabilities `json:"capabilities"`
 }
 
 // ServerInfo represents server information in initialize responses
@@ -152,6 +159,98 @@ type RootInfo struct {
 // EmptyResponse represents an empty success response
 type EmptyResponse struct{}
 
+// Conversions between the list entries the server sends and the shared mcp types
+// the client parses them into. Proxies use them to re-serve what they received.
+
+// ToMCP returns the tool as the client sees it
+func (t ToolInfo) ToMCP() mcp.Tool {
+	return mcp.Tool{
+		Name:        t.Name,
+		Description: t.Description,
+		InputSchema: schemaMap(t.InputSchema),
+		Annotations: t.Annotations,
+		Title:       t.Title,
+		Icons:       t.Icons,
+	}
+}
+
+// ToolInfoFromMCP returns the list entry for a tool received from another server
+func ToolInfoFromMCP(tool mcp.Tool) ToolInfo {
+	return ToolInfo{
+		Name:        tool.Name,
+		Description: tool.Description,
+		InputSchema: tool.InputSchema,
+		Annotations: tool.Annotations,
+		Title:       tool.Title,
+		Icons:       tool.Icons,
+	}
+}
+
+// ToMCP returns the resource as the client sees it
+func (r ResourceInfo) ToMCP() mcp.Resource {
+	return mcp.Resource{
+		URI:         r.URI,
+		Name:        r.Name,
+		Description: r.Description,
+		MimeType:    r.MimeType,
+		Annotations: r.Annotations,
+		Title:       r.Title,
+		Icons:       r.Icons,
+	}
+}
+
+// ResourceInfoFromMCP returns the list entry for a resource received from another server
+func ResourceInfoFromMCP(resource mcp.Resource) ResourceInfo {
+	return ResourceInfo{
+		URI:         resource.URI,
+		Name:        resource.Name,
+		Description: resource.Description,
+		MimeType:    resource.MimeType,
+		Annotations: resource.Annotations,
+		Title:       resource.Title,
+		Icons:       resource.Icons,
+	}
+}
+
+// ToMCP returns the prompt as the client sees it
+func (p PromptInfo) ToMCP() mcp.Prompt {
+	return mcp.Prompt{
+		Name:        p.Name,
+		Description: p.Description,
+		Arguments:   p.Arguments,
+		Annotations: p.Annotations,
+		Title:       p.Title,
+		Icons:       p.Icons,
+	}
+}
+
+// PromptInfoFromMCP returns the list entry for a prompt received from another server
+func PromptInfoFromMCP(prompt mcp.Prompt) PromptInfo {
+	return PromptInfo{
+		Name:        prompt.Name,
+		Description: prompt.Description,
+		Arguments:   prompt.Arguments,
+		Annotations: prompt.Annotations,
+		Title:       prompt.Title,
+		Icons:       prompt.Icons,
+	}
+}
+
+// schemaMap converts a JSON schema of any Go form to the generic map form
+func schemaMap(schema interface{}) map[string]interface{} {
+	switch s := schema.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		return s
+	}
+	var m map[string]interface{}
+	if data, err := json.Marshal(schema); err == nil {
+		_ = json.Unmarshal(data, &m)
+	}
+	return m
+}
+
 // Helper functions to create responses
 
 // NewToolListResponse creates a new ToolListResponse
@@ -210,7 +309,7 @@ func NewResourceReadResponse(contents []ResourceContent) *ResourceReadResponse {
 }
 
 // NewInitializeResponse creates a new InitializeResponse
-func NewInitializeResponse(protocolVersion string, serverInfo ServerInfo, capabilities map[string]interface{}) *InitializeResponse {
+func NewInitializeResponse(protocolVersion string, serverInfo ServerInfo, capabilities mcp.ServerCapabilities) *InitializeResponse {
 	return &InitializeResponse{
 		ProtocolVersion: protocolVersion,
 		ServerInfo:      serverInfo,
@@ -284,32 +383,61 @@ func TextContent(text string) ContentItem {
 	}
 }
 
-// ImageContent creates a new image content item.
-// This function creates a properly formatted image content item for inclusion in MCP responses.
+// ImageContent creates a new image content item carrying the image itself.
+// This function creates a properly formatted image content item for inclusion in MCP responses,
+// with the image encoded as base64. Use NewImageContent for an image given by URL.
 //
 // Parameters:
-//   - imageURL: The URL where the image can be accessed
-//   - altText: Accessibility description of the image content
-//   - optMimeType: Optional MIME type of the image (e.g., "image/png")
+//   - data: The raw bytes of the image
+//   - mimeType: The MIME type of the image (e.g., "image/png"); detected from data if empty
 //
 // Returns:
 //   - A ContentItem of type "image" properly formatted for the MCP protocol
-func ImageContent(imageURL string, altText string, optMimeType ...string) ContentItem {
-	content := ContentItem{
-		Type:     "image",
-		ImageURL: imageURL,
-	}
+func ImageContent(data []byte, mimeType string) ContentItem {
+	return mediaContent("image", data, mimeType)
+}
 
-	if altText != "" {
-		content.AltText = altText
-	}
+// AudioContent creates a new audio content item carrying the audio itself.
+// This function creates a properly formatted audio content item for inclusion in MCP responses,
+// with the audio encoded as base64. Clients of protocol version 2024-11-05, which has no
+// audio content, receive a text placeholder instead.
+//
+// Parameters:
+//   - data: The raw bytes of the audio
+//   - mimeType: The MIME type of the audio (e.g., "audio/wav"); detected from data if empty
+//
+// Returns:
+//   - A ContentItem of type "audio" properly formatted for the MCP protocol
+func AudioContent(data []byte, mimeType string) ContentItem {
+	return mediaContent("audio", data, mimeType)
+}
 
-	// Add mime type if provided
-	if len(optMimeType) > 0 && optMimeType[0] != "" {
-		content.MimeType = optMimeType[0]
+// mediaContent creates an image or audio content item with base64-encoded data
+func mediaContent(contentType string, data []byte, mimeType string) ContentItem {
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
 	}
+	return ContentItem{
+		Type:     contentType,
+		Data:     base64.StdEncoding.EncodeToString(data),
+		MimeType: mimeType,
+	}
+}
 
-	return content
+// EmbeddedResourceContent creates a new content item embedding one of the server's resources.
+// The resource is read when the tool result is sent, the same way UserResource embeds one
+// in a prompt; a tool whose result embeds a resource the server cannot read fails.
+//
+// Parameters:
+//   - uri: The URI of the resource to embed
+//
+// Returns:
+//   - A ContentItem of type "resource" properly formatted for the MCP protocol
+func EmbeddedResourceContent(uri string) ContentItem {
+	return ContentItem{
+		Type:     "resource",
+		Resource: &mcp.EmbeddedResource{URI: uri},
+	}
 }
 
 // LinkContent creates a new link content item.
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListEntriesConvertToMCP(t *testing.T) {
+	schema := struct {
+		Type       string                 `json:"type"`
+		Properties map[string]interface{} `json:"properties"`
+	}{Type: "object", Properties: map[string]interface{}{}}
+	tool := ToolInfo{Name: "echo", Description: "Echo the input", InputSchema: schema}
+
+	converted := tool.ToMCP()
+	assert.Equal(t, "echo", converted.Name)
+	assert.Equal(t, "object", converted.InputSchema["type"])
+	assert.Equal(t, converted, ToolInfoFromMCP(converted).ToMCP())
+
+	resource := mcp.Resource{URI: "file:///a.txt", Name: "a", MimeType: "text/plain"}
+	assert.Equal(t, resource, ResourceInfoFromMCP(resource).ToMCP())
+
+	prompt := mcp.Prompt{Name: "greet", Arguments: []mcp.PromptArgument{{Name: "name", Required: true}}}
+	assert.Equal(t, prompt, PromptInfoFromMCP(prompt).ToMCP())
+}
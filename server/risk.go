@@ -0,0 +1,183 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+	"github.com/localrivet/gomcp/mcp"
+)
+
+// RiskAction is what a RiskPolicy does with calls to the tools of a risk level.
+type RiskAction string
+
+const (
+	// RiskAllow runs the calls
+	RiskAllow RiskAction = "allow"
+
+	// RiskApprove runs the calls the server's Approver approves (see WithApprover)
+	RiskApprove RiskAction = "approve"
+
+	// RiskBlock refuses the calls
+	RiskBlock RiskAction = "block"
+)
+
+// RiskPolicy maps the risk levels of tools to what is done with their calls.
+// Levels missing from the policy are allowed; mcp.RiskUnclassified sets the action
+// for tools without a risk level.
+type RiskPolicy map[mcp.RiskLevel]RiskAction
+
+// DevelopmentRiskPolicy allows every call, for development environments.
+var DevelopmentRiskPolicy = RiskPolicy{}
+
+// ProductionRiskPolicy requires approval of destructive calls and blocks
+// irreversible ones, for production environments.
+var ProductionRiskPolicy = RiskPolicy{
+	mcp.RiskDestructive:  RiskApprove,
+	mcp.RiskIrreversible: RiskBlock,
+}
+
+// RiskPolicyFor returns the preset policy of a deployment environment:
+// DevelopmentRiskPolicy for "dev", "development", "local" and "test", and
+// ProductionRiskPolicy for any other environment, so that an unknown environment
+// fails safe.
+func RiskPolicyFor(environment string) RiskPolicy {
+	switch strings.ToLower(environment) {
+	case "dev", "development", "local", "test":
+		return DevelopmentRiskPolicy
+	default:
+		return ProductionRiskPolicy
+	}
+}
+
+// validate checks that the policy names known risk levels and actions
+func (p RiskPolicy) validate() error {
+	for level, action := range p {
+		if !level.Valid() {
+			return fmt.Errorf("unknown risk level %q", level)
+		}
+		switch action {
+		case RiskAllow, RiskApprove, RiskBlock:
+		default:
+			return fmt.Errorf("unknown action %q for risk level %s", action, level)
+		}
+	}
+	return nil
+}
+
+// ApprovalRequest describes a tool call a RiskPolicy requires approval of.
+type ApprovalRequest struct {
+	Tool      string
+	Risk      mcp.RiskLevel
+	Arguments map[string]interface{}
+}
+
+// Approver decides whether a tool call requiring approval may run, for instance by
+// asking an operator or checking a ticket. The call is refused when it returns
+// false or an error.
+type Approver func(ctx *Context, request ApprovalRequest) (bool, error)
+
+// WithRisk returns an annotations map that declares the risk level of a tool. The
+// level is exposed under the "risk" annotation in list responses, along with the
+// matching readOnlyHint or destructiveHint of the specification, and decides what
+// the server's RiskPolicy does with calls to the tool.
+//
+// Example:
+//
+//	server.Tool("drop_table", "Drop a table", handler, server.WithRisk(mcp.RiskIrreversible))
+func WithRisk(level mcp.RiskLevel) map[string]interface{} {
+	annotations := map[string]interface{}{
+		mcp.RiskAnnotation: string(level),
+	}
+	switch level {
+	case mcp.RiskSafe, mcp.RiskReadOnly:
+		annotations["readOnlyHint"] = true
+	case mcp.RiskDestructive, mcp.RiskIrreversible:
+		annotations["destructiveHint"] = true
+	}
+	return annotations
+}
+
+// WithRiskPolicy sets what is done with calls to tools by risk level. Calls are
+// allowed when no policy is set. Refused calls fail with an error result and are
+// published as events.ToolBlockedEvent.
+//
+// Example:
+//
+//	server.NewServer("ops",
+//	    server.WithRiskPolicy(server.RiskPolicyFor(os.Getenv("DEPLOY_ENV"))),
+//	    server.WithApprover(askOnCall),
+//	)
+func WithRiskPolicy(policy RiskPolicy) Option {
+	return func(s *serverImpl) {
+		if err := policy.validate(); err != nil {
+			// A policy that cannot be understood blocks what it does not allow
+			s.logger.Error("invalid risk policy, using the production policy", "error", err)
+			policy = ProductionRiskPolicy
+		}
+		s.riskPolicy = policy
+	}
+}
+
+// WithApprover sets the Approver of the tool calls the RiskPolicy requires approval
+// of. Without an approver, such calls are refused.
+func WithApprover(approver Approver) Option {
+	return func(s *serverImpl) {
+		s.approver = approver
+	}
+}
+
+// errRiskRefused is the error of tool calls refused by the risk policy
+var errRiskRefused = errors.New("refused by risk policy")
+
+// checkRisk applies the risk policy to a call of a tool, returning an error if the
+// call is refused
+func (s *serverImpl) checkRisk(ctx *Context, tool *Tool, args map[string]interface{}) error {
+	if s.riskPolicy == nil {
+		return nil
+	}
+	level := mcp.RiskFromAnnotations(tool.Annotations)
+	action, ok := s.riskPolicy[level]
+	if !ok {
+		action = RiskAllow
+	}
+
+	var reason string
+	switch action {
+	case RiskAllow:
+		return nil
+	case RiskBlock:
+		reason = fmt.Sprintf("%s tools are blocked", level)
+	case RiskApprove:
+		if s.approver == nil {
+			reason = fmt.Sprintf("%s tools require approval and no approver is configured", level)
+			break
+		}
+		approved, err := s.approver(ctx, ApprovalRequest{Tool: tool.Name, Risk: level, Arguments: args})
+		switch {
+		case err != nil:
+			reason = fmt.Sprintf("approval failed: %v", err)
+		case !approved:
+			reason = "approval denied"
+		default:
+			return nil
+		}
+	}
+
+	s.logger.Warn("tool call refused", "tool", tool.Name, "risk", level, "action", action, "reason", reason)
+	event := events.ToolBlockedEvent{
+		Tool:      tool.Name,
+		Risk:      string(level),
+		Action:    string(action),
+		Reason:    reason,
+		BlockedAt: time.Now(),
+	}
+	if ctx.Session != nil {
+		event.SessionID = string(ctx.Session.ID)
+	}
+	go events.Publish[events.ToolBlockedEvent](s.events, events.TopicToolBlocked, event)
+
+	return fmt.Errorf("%w: %s", errRiskRefused, reason)
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiskPolicy(t *testing.T) {
+	newServer := func(options ...Option) *serverImpl {
+		s := NewServer("risk-test", options...).GetServer()
+		s.SetTransport(&recordingTransport{})
+		handler := func(ctx *Context, args struct {
+			Target string `json:"target"`
+		}) (string, error) {
+			return "done", nil
+		}
+		s.Tool("status", "Show status", handler, WithRisk(mcp.RiskReadOnly))
+		s.Tool("restart", "Restart a service", handler, WithRisk(mcp.RiskDestructive))
+		s.Tool("drop", "Drop a database", handler, WithRisk(mcp.RiskIrreversible))
+		s.Tool("legacy", "Delete things", handler, map[string]interface{}{"destructiveHint": true})
+		s.Tool("misc", "Do something", handler)
+		return s
+	}
+	call := func(t *testing.T, s *serverImpl, tool string) (string, bool) {
+		t.Helper()
+		response, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"` + tool + `","arguments":{"target":"db"}}}`))
+		require.NoError(t, err)
+		var decoded struct {
+			Result struct {
+				Content []struct {
+					Text string `json:"text"`
+				} `json:"content"`
+				IsError bool `json:"isError"`
+			} `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(response, &decoded), string(response))
+		require.Len(t, decoded.Result.Content, 1, string(response))
+		return decoded.Result.Content[0].Text, decoded.Result.IsError
+	}
+
+	t.Run("no policy", func(t *testing.T) {
+		s := newServer()
+		for _, tool := range []string{"status", "restart", "drop", "legacy", "misc"} {
+			text, isError := call(t, s, tool)
+			assert.False(t, isError, tool)
+			assert.Equal(t, "done", text)
+		}
+	})
+
+	t.Run("production", func(t *testing.T) {
+		var requests []ApprovalRequest
+		s := newServer(
+			WithRiskPolicy(RiskPolicyFor("production")),
+			WithApprover(func(ctx *Context, request ApprovalRequest) (bool, error) {
+				requests = append(requests, request)
+				return request.Tool == "restart", nil
+			}),
+		)
+
+		_, isError := call(t, s, "status")
+		assert.False(t, isError)
+		_, isError = call(t, s, "misc")
+		assert.False(t, isError, "Expected unclassified tools to be allowed")
+
+		_, isError = call(t, s, "restart")
+		assert.False(t, isError, "Expected the approved call to run")
+
+		text, isError := call(t, s, "legacy")
+		assert.True(t, isError)
+		assert.Contains(t, text, "approval denied", "Expected destructiveHint to classify the tool")
+
+		text, isError = call(t, s, "drop")
+		assert.True(t, isError)
+		assert.Contains(t, text, "irreversible tools are blocked")
+
+		require.Len(t, requests, 2, "Expected blocked tools not to be submitted for approval")
+		assert.Equal(t, ApprovalRequest{Tool: "restart", Risk: mcp.RiskDestructive, Arguments: map[string]interface{}{"target": "db"}}, requests[0])
+	})
+
+	t.Run("approval without approver or failing", func(t *testing.T) {
+		s := newServer(WithRiskPolicy(RiskPolicy{mcp.RiskUnclassified: RiskApprove}))
+		text, isError := call(t, s, "misc")
+		assert.True(t, isError)
+		assert.Contains(t, text, "no approver is configured")
+
+		s = newServer(
+			WithRiskPolicy(RiskPolicy{mcp.RiskReadOnly: RiskApprove}),
+			WithApprover(func(ctx *Context, request ApprovalRequest) (bool, error) {
+				return true, errors.New("operator unreachable")
+			}),
+		)
+		text, isError = call(t, s, "status")
+		assert.True(t, isError)
+		assert.Contains(t, text, "operator unreachable")
+	})
+
+	t.Run("development", func(t *testing.T) {
+		s := newServer(WithRiskPolicy(RiskPolicyFor("dev")))
+		_, isError := call(t, s, "drop")
+		assert.False(t, isError)
+	})
+
+	t.Run("invalid policy fails safe", func(t *testing.T) {
+		s := newServer(WithRiskPolicy(RiskPolicy{"catastrophic": RiskAllow}))
+		_, isError := call(t, s, "drop")
+		assert.True(t, isError)
+	})
+}
+
+func TestRiskFromAnnotations(t *testing.T) {
+	assert.Equal(t, mcp.RiskIrreversible, mcp.RiskFromAnnotations(WithRisk(mcp.RiskIrreversible)))
+	assert.Equal(t, true, WithRisk(mcp.RiskIrreversible)["destructiveHint"])
+	assert.Equal(t, true, WithRisk(mcp.RiskSafe)["readOnlyHint"])
+	assert.Equal(t, mcp.RiskReadOnly, mcp.RiskFromAnnotations(map[string]interface{}{"readOnlyHint": true}))
+	assert.Equal(t, mcp.RiskUnclassified, mcp.RiskFromAnnotations(map[string]interface{}{"risk": "unknown"}))
+	assert.Equal(t, mcp.RiskUnclassified, mcp.RiskFromAnnotations(nil))
+	assert.Equal(t, ProductionRiskPolicy, RiskPolicyFor("qa"), "Expected unknown environments to fail safe")
+}
+
+func TestRiskConfig(t *testing.T) {
+	const config = `name: ops
+risk:
+  environment: ${RISK_TEST_ENV:-production}
+  policies:
+    staging:
+      destructive: allow
+      irreversible: approve
+`
+	t.Setenv("RISK_TEST_ENV", "staging")
+	s, err := NewServerFromConfig(writeConfig(t, config))
+	require.NoError(t, err)
+	assert.Equal(t, RiskPolicy{mcp.RiskDestructive: RiskAllow, mcp.RiskIrreversible: RiskApprove}, s.GetServer().riskPolicy)
+
+	t.Setenv("RISK_TEST_ENV", "production")
+	s, err = NewServerFromConfig(writeConfig(t, config))
+	require.NoError(t, err)
+	assert.Equal(t, ProductionRiskPolicy, s.GetServer().riskPolicy)
+
+	_, err = LoadConfig(writeConfig(t, "name: ops\nrisk:\n  policies:\n    prod:\n      destructive: maybe\n"))
+	assert.ErrorContains(t, err, `unknown action "maybe"`)
+}
@@ -1,94 +1,40 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/localrivet/gomcp/mcp"
 )
 
-// SamplingMessageContent represents the content of a sampling message.
-// It contains the type of content (text, image, etc.) and associated data,
-// allowing for multimodal message exchanges in sampling conversations.
-type SamplingMessageContent struct {
-	Type     string `json:"type"`
-	Text     string `json:"text,omitempty"`
-	Data     string `json:"data,omitempty"`
-	MimeType string `json:"mimeType,omitempty"`
-}
+// SamplingMessageContent represents the content of a sampling message. It is an
+// alias to the shared mcp.SamplingMessageContent type.
+type SamplingMessageContent = mcp.SamplingMessageContent
 
-// IsValidForVersion checks if the content type is valid for the given protocol version.
-// Different protocol versions support different content types, and this method
-// validates whether the current content type is supported in the specified version.
-//
-// Parameters:
-//   - version: The protocol version to check against
-//
-// Returns:
-//   - true if the content type is supported in the specified version, false otherwise
-func (c *SamplingMessageContent) IsValidForVersion(version string) bool {
-	switch version {
-	case "draft", "2025-03-26":
-		// These versions support text, image, and audio content types
-		return c.Type == "text" || c.Type == "image" || c.Type == "audio"
-	case "2024-11-05":
-		// This version only supports text and image content types
-		return c.Type == "text" || c.Type == "image"
-	default:
-		// Unknown version, default to most restrictive
-		return c.Type == "text"
-	}
-}
-
-// SamplingMessage represents a message in a sampling conversation.
-// This struct encapsulates a single message exchange between a user and an assistant,
-// including the role of the speaker and the content of the message.
-type SamplingMessage struct {
-	Role    string                 `json:"role"`
-	Content SamplingMessageContent `json:"content"`
-}
+// SamplingMessage represents a message in a sampling conversation. It is an alias
+// to the shared mcp.SamplingMessage type.
+type SamplingMessage = mcp.SamplingMessage
 
 // SamplingModelHint represents a hint for model selection in sampling requests.
-// Hints provide optional guidance to the client about which AI models might be
-// appropriate for handling a particular sampling request.
-type SamplingModelHint struct {
-	Name string `json:"name"`
-}
+// It is an alias to the shared mcp.SamplingModelHint type.
+type SamplingModelHint = mcp.SamplingModelHint
 
 // SamplingModelPreferences represents the model preferences for a sampling request.
-// These preferences allow the server to communicate desired characteristics for
-// the AI model that will handle the sampling request, such as optimizing for
-// cost, speed, or intelligence.
-type SamplingModelPreferences struct {
-	Hints                []SamplingModelHint `json:"hints,omitempty"`
-	CostPriority         *float64            `json:"costPriority,omitempty"`
-	SpeedPriority        *float64            `json:"speedPriority,omitempty"`
-	IntelligencePriority *float64            `json:"intelligencePriority,omitempty"`
-}
-
-// SamplingCreateMessageParams represents the parameters for a sampling/createMessage request.
-// This struct contains all the information needed to make a complete sampling request
-// to the client, including the conversation history, model preferences, system prompt,
-// and token limits.
-type SamplingCreateMessageParams struct {
-	Messages         []SamplingMessage        `json:"messages"`
-	ModelPreferences SamplingModelPreferences `json:"modelPreferences"`
-	SystemPrompt     string                   `json:"systemPrompt,omitempty"`
-	MaxTokens        int                      `json:"maxTokens,omitempty"`
-}
+// It is an alias to the shared mcp.SamplingModelPreferences type.
+type SamplingModelPreferences = mcp.SamplingModelPreferences
+
+// SamplingCreateMessageParams represents the parameters for a sampling/createMessage
+// request. It is an alias to the shared mcp.SamplingCreateMessageParams type, the
+// type client sampling handlers receive.
+type SamplingCreateMessageParams = mcp.SamplingCreateMessageParams
 
 // SamplingResponse represents the response to a sampling/createMessage request.
-// It contains the AI-generated response content, the role of the responder (typically "assistant"),
-// optional model identification, and information about why the response ended.
-type SamplingResponse struct {
-	Role       string                 `json:"role"`
-	Content    SamplingMessageContent `json:"content"`
-	Model      string                 `json:"model,omitempty"`
-	StopReason string                 `json:"stopReason,omitempty"`
-}
+// It is an alias to the shared mcp.SamplingResponse type.
+type SamplingResponse = mcp.SamplingResponse
 
 // SamplingContentHandler is the interface for all sampling content handlers.
 // This interface defines the contract that all content type implementations must follow,
@@ -270,18 +216,22 @@ func ValidateContentForVersion(content SamplingContentHandler, version string) e
 }
 
 // ProcessSamplingCreateMessage processes a sampling create message request from the client.
-// This is typically a client-side method, so we just return an error. In the MCP protocol,
-// sampling/createMessage is a server->client request, not client->server.
+// In the MCP protocol, sampling/createMessage is a server->client request, so it fails
+// unless the server has a sampling provider set with WithSamplingProvider.
 //
 // Parameters:
 //   - ctx: The request context containing client information and request details
 //
 // Returns:
-//   - Always nil for the result since this method is not meant to be called by clients
-//   - An error explaining that this method is not supported for client requests
+//   - The provider's response, if the server has a sampling provider
+//   - An error explaining that this method is not supported for client requests otherwise
 func (s *serverImpl) ProcessSamplingCreateMessage(ctx *Context) (interface{}, error) {
+	if s.samplingProvider != nil {
+		return s.answerSamplingRequest(ctx)
+	}
+
 	// This is a server->client request, so should not be called directly by clients
-	return nil, fmt.Errorf("method not supported: %s", ctx.Request.Method)
+	return nil, clientCapabilityError(ctx.Request.Method, "sampling")
 }
 
 // RequestSamplingOptions defines options for sampling requests.
@@ -293,6 +243,20 @@ type RequestSamplingOptions struct {
 	RetryInterval    time.Duration // Time to wait between retries
 	IgnoreCapability bool          // Whether to ignore client capability validation
 	ForceSession     bool          // Whether to force using the specified session
+
+	// Retry overrides the server's retry policy for this request. If neither is
+	// set, MaxRetries and RetryInterval are used with the default retry condition.
+	Retry *SamplingRetryPolicy
+
+	// IncludeContext asks the client to add conversation context to the prompt:
+	// mcp.IncludeContextNone (the default), mcp.IncludeContextThisServer or
+	// mcp.IncludeContextAllServers. The client may ignore the request.
+	IncludeContext string
+
+	// Generation parameters, passed through to the client's sampling handler
+	Temperature   *float64               // Sampling temperature, between 0 and 2
+	StopSequences []string               // Sequences that end generation
+	Metadata      map[string]interface{} // Provider-specific metadata
 }
 
 // DefaultSamplingOptions returns the default options for sampling requests.
@@ -331,39 +295,61 @@ type requestTracker struct {
 	mu           sync.RWMutex
 	requests     map[int]chan json.RawMessage
 	timeouts     map[int]*time.Timer // Track timeout timers
+	sentAt       map[int]time.Time   // When each request was added
 	pendingCount int                 // Count of active pending requests
+	sessions     RequestSessionMap   // Sessions requests were sent to
 }
 
-// newRequestTracker creates a new request tracker
-func newRequestTracker() *requestTracker {
+// newRequestTracker creates a new request tracker that records the sessions
+// requests were sent to in sessions, or in memory if sessions is nil
+func newRequestTracker(sessions RequestSessionMap) *requestTracker {
+	if sessions == nil {
+		sessions = NewMemoryRequestSessionMap()
+	}
 	return &requestTracker{
 		requests: make(map[int]chan json.RawMessage),
 		timeouts: make(map[int]*time.Timer),
+		sentAt:   make(map[int]time.Time),
+		sessions: sessions,
 	}
 }
 
-// addRequest adds a new request to track and returns a channel to receive the response
-func (rt *requestTracker) addRequest(id int) chan json.RawMessage {
+// addRequest adds a new request sent to the given session, or to the only peer if
+// sessionID is empty, and returns a channel to receive the response
+func (rt *requestTracker) addRequest(id int, sessionID SessionID) chan json.RawMessage {
 	rt.mu.Lock()
 	defer rt.mu.Unlock()
 
 	// Create a buffered channel to prevent deadlock if response arrives after timeout
 	responseChan := make(chan json.RawMessage, 1)
 	rt.requests[id] = responseChan
+	rt.sentAt[id] = time.Now()
 	rt.pendingCount++
 
+	if sessionID != "" {
+		rt.sessions.Bind(id, sessionID)
+	}
+
 	return responseChan
 }
 
-// resolveRequest resolves a request with its response
-// Returns true if the request was found and resolved
-func (rt *requestTracker) resolveRequest(id int, response json.RawMessage) bool {
+// resolveRequest resolves a request with a response received on the given session.
+// A response from a session other than the one the request was sent to is rejected
+// with errWrongSession and leaves the request pending; an empty sessionID means the
+// transport does not tell sessions apart and matches any request.
+func (rt *requestTracker) resolveRequest(id int, sessionID SessionID, response json.RawMessage) error {
 	rt.mu.Lock()
 	defer rt.mu.Unlock()
 
 	responseChan, exists := rt.requests[id]
 	if !exists {
-		return false
+		return errUnknownRequest
+	}
+
+	if sessionID != "" {
+		if expected, bound := rt.sessions.Lookup(id); bound && expected != sessionID {
+			return errWrongSession
+		}
 	}
 
 	// Cancel any pending timeout for this request
@@ -382,9 +368,11 @@ func (rt *requestTracker) resolveRequest(id int, response json.RawMessage) bool
 
 	// Clean up the request
 	delete(rt.requests, id)
+	delete(rt.sentAt, id)
+	rt.sessions.Unbind(id)
 	rt.pendingCount--
 
-	return true
+	return nil
 }
 
 // removeRequest removes a request from tracking without sending a response
@@ -400,10 +388,35 @@ func (rt *requestTracker) removeRequest(id int) {
 	}
 
 	// Remove the request
+	if _, exists := rt.requests[id]; !exists {
+		return
+	}
 	delete(rt.requests, id)
+	delete(rt.sentAt, id)
+	rt.sessions.Unbind(id)
 	rt.pendingCount--
 }
 
+// removeStale removes requests without a timeout of their own that were added more
+// than maxAge before now, and returns how many were removed
+func (rt *requestTracker) removeStale(maxAge time.Duration, now time.Time) int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	removed := 0
+	for id, sentAt := range rt.sentAt {
+		if _, hasTimer := rt.timeouts[id]; hasTimer || now.Sub(sentAt) <= maxAge {
+			continue
+		}
+		delete(rt.requests, id)
+		delete(rt.sentAt, id)
+		rt.sessions.Unbind(id)
+		rt.pendingCount--
+		removed++
+	}
+	return removed
+}
+
 // setupTimeout creates a timeout for a request
 // When the timeout expires, the request will be automatically cleaned up
 func (rt *requestTracker) setupTimeout(id int, timeout time.Duration) {
@@ -464,37 +477,23 @@ func (s *serverImpl) GetClientCapabilitiesFromContext(ctx *Context) (SamplingCap
 	return session.ClientInfo.SamplingCaps, true
 }
 
-// RequestSamplingFromContext initiates a sampling request based on the context's session information
+// RequestSamplingFromContext sends a sampling request to the client of the session
+// that issued the context's request, checked against the capabilities that client
+// declared. Contexts without a session use the default session.
 func (s *serverImpl) RequestSamplingFromContext(ctx *Context, messages []SamplingMessage, preferences SamplingModelPreferences, systemPrompt string, maxTokens int) (*SamplingResponse, error) {
-	if ctx == nil || ctx.Metadata == nil {
-		// Use default session if no context is provided
-		return s.RequestSampling(messages, preferences, systemPrompt, maxTokens)
-	}
-
-	// Try to get the session ID from the context
-	sessionIDVal, ok := ctx.Metadata["sessionID"]
-	if !ok {
-		// No session ID in context, use default
+	sessionID := ctx.sessionID()
+	if sessionID == "" {
 		return s.RequestSampling(messages, preferences, systemPrompt, maxTokens)
 	}
 
-	// Convert the session ID to string
-	sessionIDStr, ok := sessionIDVal.(string)
-	if !ok {
-		return nil, fmt.Errorf("invalid session ID in context")
-	}
-
-	// Use the context's protocol version
-	protocolVersion := ctx.Version
-
-	return s.RequestSamplingWithSessionAndOptions(SessionID(sessionIDStr), protocolVersion, messages, preferences, systemPrompt, maxTokens, DefaultSamplingOptions())
+	return s.RequestSamplingWithSessionAndOptions(sessionID, ctx.Version, messages, preferences, systemPrompt, maxTokens, DefaultSamplingOptions())
 }
 
 // RequestSamplingWithOptions sends a sampling request with custom options
 func (s *serverImpl) RequestSamplingWithOptions(messages []SamplingMessage, preferences SamplingModelPreferences, systemPrompt string, maxTokens int, options RequestSamplingOptions) (*SamplingResponse, error) {
-	// Get the current protocol version
+	// Use the default session and the version negotiated with its client
 	s.mu.RLock()
-	protocolVersion := s.protocolVersion
+	protocolVersion := s.sessionProtocolVersion(s.defaultSession)
 	sessionID := SessionID("")
 	if s.defaultSession != nil {
 		sessionID = s.defaultSession.ID
@@ -518,54 +517,51 @@ func (s *serverImpl) RequestSamplingWithSessionAndOptions(sessionID SessionID, p
 		options.RetryInterval = 1 * time.Second // Default 1-second retry interval
 	}
 
-	// Validate protocol version for this request
+	// Default to the version negotiated with the session's client
 	if protocolVersion == "" {
-		s.mu.RLock()
-		protocolVersion = s.protocolVersion
-		s.mu.RUnlock()
-	}
-
-	// Validate against protocol and server constraints using the sampling controller
-	if s.samplingController != nil && !options.IgnoreCapability {
-		if err := s.samplingController.ValidateForProtocol(protocolVersion, messages, maxTokens); err != nil {
-			return nil, err
-		}
+		session, _ := s.sessionManager.GetSession(sessionID)
+		protocolVersion = s.sessionProtocolVersion(session)
 	}
 
 	// Get client capabilities for this session
 	clientInfo, found := s.getClientInfoForSession(sessionID)
 	if !found {
-		return nil, fmt.Errorf("client session not found")
+		return nil, fmt.Errorf("sampling session not found: %s", sessionID)
 	}
 
-	// Validate messages against client capabilities if not ignoring capability validation
+	// Fail before sending anything the client cannot answer
 	if !options.IgnoreCapability {
-		for _, msg := range messages {
-			switch msg.Content.Type {
-			case "audio":
-				if !clientInfo.SamplingCaps.AudioSupport {
-					return nil, fmt.Errorf("client does not support audio content")
-				}
-			case "image":
-				if !clientInfo.SamplingCaps.ImageSupport {
-					return nil, fmt.Errorf("client does not support image content")
-				}
-			}
+		if err := validateSamplingForClient(clientInfo, protocolVersion, messages, maxTokens); err != nil {
+			return nil, err
+		}
 
-			// Also validate against protocol version
-			if !msg.Content.IsValidForVersion(protocolVersion) {
-				return nil, fmt.Errorf("content type '%s' not supported in protocol version '%s'",
-					msg.Content.Type, protocolVersion)
+		// Validate against protocol and server constraints, such as the maximum
+		// number of tokens, using the sampling controller
+		if s.samplingController != nil {
+			if err := s.samplingController.ValidateForProtocol(protocolVersion, messages, maxTokens); err != nil {
+				return nil, err
 			}
 		}
 	}
 
+	// Validate the generation parameters for the protocol version
+	if err := mcp.ValidateSamplingParameters(protocolVersion, options.Temperature, options.StopSequences, options.Metadata); err != nil {
+		return nil, fmt.Errorf("invalid sampling parameters: %w", err)
+	}
+	if err := mcp.ValidateIncludeContext(options.IncludeContext); err != nil {
+		return nil, fmt.Errorf("invalid sampling parameters: %w", err)
+	}
+
 	// Create sampling parameters
 	params := SamplingCreateMessageParams{
 		Messages:         messages,
 		ModelPreferences: preferences,
 		SystemPrompt:     systemPrompt,
 		MaxTokens:        maxTokens,
+		IncludeContext:   options.IncludeContext,
+		Temperature:      options.Temperature,
+		StopSequences:    options.StopSequences,
+		Metadata:         options.Metadata,
 	}
 
 	// Marshal the params
@@ -574,6 +570,74 @@ func (s *serverImpl) RequestSamplingWithSessionAndOptions(sessionID SessionID, p
 		return nil, fmt.Errorf("failed to marshal sampling parameters: %w", err)
 	}
 
+	// Answer from the cache or an identical pending request if the sampling
+	// controller allows it, otherwise send the request, retrying transient failures
+	// according to the retry policy
+	response, err := s.samplingController.execute(sessionID, paramsJSON, options.IncludeContext, options.Timeout, func() (*SamplingResponse, error) {
+		response, err := s.sendSamplingRequestWithRetries(sessionID, paramsJSON, options)
+		if err != nil {
+			return nil, err
+		}
+
+		// Validate the response content type
+		if !response.Content.IsValidForVersion(protocolVersion) {
+			return nil, fmt.Errorf("response content type '%s' not supported in protocol version '%s'",
+				response.Content.Type, protocolVersion)
+		}
+		return response, nil
+	})
+
+	if err != nil {
+		// Handle graceful degradation if enabled
+		if errors.Is(err, ErrSamplingTimeout) && s.samplingConfig != nil && s.samplingConfig.GracefulDegradation {
+			s.logger.Info("attempting graceful degradation after timeout",
+				"sessionID", string(sessionID))
+
+			// Generate a fallback response that indicates failure but provides a usable response
+			fallbackResponse := &SamplingResponse{
+				Role: "assistant",
+				Content: SamplingMessageContent{
+					Type: "text",
+					Text: "I apologize, but I was unable to process your request in time. Please try again or rephrase your request.",
+				},
+				StopReason: "timeout",
+			}
+
+			return fallbackResponse, nil
+		}
+
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// sendSamplingRequestWithRetries sends a sampling request, retrying transient
+// failures according to the request's retry policy
+func (s *serverImpl) sendSamplingRequestWithRetries(sessionID SessionID, paramsJSON []byte, options RequestSamplingOptions) (*SamplingResponse, error) {
+	policy := s.samplingRetryPolicy(options)
+
+	for attempt := 0; ; attempt++ {
+		response, err := s.sendSamplingRequest(sessionID, paramsJSON, options.Timeout)
+		if attempt >= policy.MaxRetries || !policy.shouldRetry(response, err) {
+			return response, err
+		}
+
+		delay := policy.delay(attempt + 1)
+		s.logger.Info("retrying sampling request",
+			"sessionID", string(sessionID),
+			"retry", attempt+1,
+			"maxRetries", policy.MaxRetries,
+			"delay", delay.String(),
+			"error", err)
+		time.Sleep(delay)
+	}
+}
+
+// sendSamplingRequest makes a single sampling/createMessage request and waits for
+// the client's answer. It returns ErrSamplingTimeout if none arrives in time and a
+// *SamplingClientError if the client responds with an error.
+func (s *serverImpl) sendSamplingRequest(sessionID SessionID, paramsJSON []byte, timeout time.Duration) (*SamplingResponse, error) {
 	// Create request ID
 	requestID := s.generateRequestID()
 
@@ -588,25 +652,23 @@ func (s *serverImpl) RequestSamplingWithSessionAndOptions(sessionID SessionID, p
 
 	// Create request tracker if not exists
 	if s.requestTracker == nil {
-		s.requestTracker = newRequestTracker()
+		s.requestTracker = newRequestTracker(s.requestSessions)
 	}
 
-	// Register this request
-	responseChan := s.requestTracker.addRequest(int(requestID))
+	// Register this request along with the session it is sent to
+	responseChan := s.requestTracker.addRequest(int(requestID), sessionID)
 
 	// Set up timeout handling using the enhanced request tracker
-	s.requestTracker.setupTimeout(int(requestID), options.Timeout)
+	s.requestTracker.setupTimeout(int(requestID), timeout)
 
 	// Log the request
 	s.logger.Debug("sending sampling request",
 		"id", requestID,
 		"sessionID", string(sessionID),
-		"timeout", options.Timeout.String(),
-		"messageCount", len(messages),
-		"maxTokens", maxTokens)
+		"timeout", timeout.String())
 
-	// Send the request
-	err = s.transport.Send(requestJSON)
+	// Send the request to the session it is for
+	err = s.sendToSession(sessionID, requestJSON)
 	if err != nil {
 		s.requestTracker.removeRequest(int(requestID))
 		return nil, fmt.Errorf("failed to send sampling request: %w", err)
@@ -614,61 +676,16 @@ func (s *serverImpl) RequestSamplingWithSessionAndOptions(sessionID SessionID, p
 
 	// Wait for response with timeout
 	var responseJSON json.RawMessage
-	var timedOut bool
-
 	select {
 	case responseJSON = <-responseChan:
 		// Got a response
-	case <-time.After(options.Timeout):
-		timedOut = true
-	}
-
-	// Handle timeout with improved error handling and retry management
-	if timedOut {
+	case <-time.After(timeout):
 		// The request will be automatically cleaned up by the request tracker
 		s.logger.Warn("timeout waiting for sampling response",
 			"id", requestID,
 			"sessionID", string(sessionID),
-			"timeout", options.Timeout.String(),
-			"retriesRemaining", options.MaxRetries)
-
-		// Check if we should retry
-		if options.MaxRetries > 0 {
-			s.logger.Info("retrying sampling request",
-				"id", requestID,
-				"retriesLeft", options.MaxRetries)
-
-			// Decrement max retries and retry with slightly increased timeout
-			newOptions := options
-			newOptions.MaxRetries--
-			newOptions.Timeout += options.RetryInterval
-
-			// Add a small delay before retrying
-			time.Sleep(options.RetryInterval)
-
-			return s.RequestSamplingWithSessionAndOptions(sessionID, protocolVersion, messages, preferences, systemPrompt, maxTokens, newOptions)
-		}
-
-		// Handle graceful degradation if enabled
-		if s.samplingConfig != nil && s.samplingConfig.GracefulDegradation {
-			s.logger.Info("attempting graceful degradation after timeout",
-				"id", requestID,
-				"sessionID", string(sessionID))
-
-			// Generate a fallback response that indicates failure but provides a usable response
-			fallbackResponse := &SamplingResponse{
-				Role: "assistant",
-				Content: SamplingMessageContent{
-					Type: "text",
-					Text: "I apologize, but I was unable to process your request in time. Please try again or rephrase your request.",
-				},
-				StopReason: "timeout",
-			}
-
-			return fallbackResponse, nil
-		}
-
-		return nil, fmt.Errorf("timeout waiting for sampling response")
+			"timeout", timeout.String())
+		return nil, ErrSamplingTimeout
 	}
 
 	// Parse the response
@@ -695,44 +712,11 @@ func (s *serverImpl) RequestSamplingWithSessionAndOptions(sessionID SessionID, p
 
 	// Check for error
 	if response.Error != nil {
-		// Check if this is a retryable error
-		isRetryable := false
-
-		// Network or temporary errors often have codes in 500+ range
-		if response.Error.Code >= 500 && response.Error.Code < 600 {
-			isRetryable = true
+		return nil, &SamplingClientError{
+			Code:    response.Error.Code,
+			Message: response.Error.Message,
+			Data:    response.Error.Data,
 		}
-
-		// Also look for specific error messages that indicate temporary issues
-		errMsg := response.Error.Message
-		if strings.Contains(errMsg, "timeout") ||
-			strings.Contains(errMsg, "rate limit") ||
-			strings.Contains(errMsg, "temporarily unavailable") ||
-			strings.Contains(errMsg, "try again") {
-			isRetryable = true
-		}
-
-		// Retry if appropriate
-		if isRetryable && options.MaxRetries > 0 {
-			s.logger.Info("retrying after retryable error",
-				"id", requestID,
-				"errorCode", response.Error.Code,
-				"errorMessage", response.Error.Message,
-				"retriesLeft", options.MaxRetries)
-
-			// Decrement max retries and retry with slightly increased timeout
-			newOptions := options
-			newOptions.MaxRetries--
-			newOptions.Timeout += options.RetryInterval
-
-			// Add a small delay before retrying
-			time.Sleep(options.RetryInterval)
-
-			return s.RequestSamplingWithSessionAndOptions(sessionID, protocolVersion, messages, preferences, systemPrompt, maxTokens, newOptions)
-		}
-
-		// Otherwise, return the error
-		return nil, fmt.Errorf("sampling error: %s (code %d)", response.Error.Message, response.Error.Code)
 	}
 
 	// Ensure we have a valid result
@@ -740,12 +724,6 @@ func (s *serverImpl) RequestSamplingWithSessionAndOptions(sessionID SessionID, p
 		return nil, fmt.Errorf("sampling response contains no result")
 	}
 
-	// Validate the response content type
-	if !response.Result.Content.IsValidForVersion(protocolVersion) {
-		return nil, fmt.Errorf("response content type '%s' not supported in protocol version '%s'",
-			response.Result.Content.Type, protocolVersion)
-	}
-
 	return response.Result, nil
 }
 
@@ -794,11 +772,13 @@ type SamplingCapabilities struct {
 
 // ClientInfo represents information about a connected client
 type ClientInfo struct {
-	SamplingSupported bool
-	SamplingCaps      SamplingCapabilities
-	ProtocolVersion   string
-	Env               map[string]string // Environment variables from the client session
-	Roots             []string          // Workspace root paths from the client session
+	SamplingSupported    bool
+	SamplingCaps         SamplingCapabilities
+	ProtocolVersion      string
+	Env                  map[string]string // Environment variables from the client session
+	Roots                []string          // Workspace root paths from the client session
+	RootsSupported       bool              // Whether the client answers roots/list requests
+	ElicitationSupported bool              // Whether the client answers elicitation/create requests
 	// Add other client capabilities here
 }
 
@@ -825,7 +805,8 @@ func (s *serverImpl) getClientInfo() (ClientInfo, bool) {
 	}, true
 }
 
-// getClientInfoForSession returns client info for a specific session
+// getClientInfoForSession returns client info for a specific session, or for the
+// default session if sessionID is empty. It reports false for unknown sessions.
 func (s *serverImpl) getClientInfoForSession(sessionID SessionID) (ClientInfo, bool) {
 	if sessionID == "" {
 		return s.getClientInfo()
@@ -833,12 +814,61 @@ func (s *serverImpl) getClientInfoForSession(sessionID SessionID) (ClientInfo, b
 
 	session, exists := s.sessionManager.GetSession(sessionID)
 	if !exists {
-		return s.getClientInfo()
+		return ClientInfo{}, false
 	}
 
 	return session.ClientInfo, true
 }
 
+// validateSamplingForClient checks that a client declared the sampling capability
+// and can take the content of messages under the protocol version it negotiated
+func validateSamplingForClient(clientInfo ClientInfo, protocolVersion string, messages []SamplingMessage, maxTokens int) error {
+	if !clientInfo.SamplingSupported {
+		return &RemediableError{
+			Reason:  mcp.ReasonUnsupportedCapability,
+			Message: "client does not support sampling",
+			Hint:    "the client must declare the sampling capability when it initializes, for example by registering a sampling handler",
+		}
+	}
+	if maxTokens <= 0 {
+		return fmt.Errorf("maxTokens must be positive, got %d", maxTokens)
+	}
+
+	for i, msg := range messages {
+		switch msg.Content.Type {
+		case "audio":
+			if !supportsAudioContent(protocolVersion) {
+				return &RemediableError{
+					Reason:  mcp.ReasonUnsupportedCapability,
+					Message: fmt.Sprintf("message %d: audio content requires protocol version 2025-03-26 or later, the client negotiated %s", i, protocolVersion),
+					Hint:    "send the audio as text, such as a transcript, to clients of older protocol versions",
+				}
+			}
+			if !clientInfo.SamplingCaps.AudioSupport {
+				return &RemediableError{
+					Reason:  mcp.ReasonUnsupportedCapability,
+					Message: fmt.Sprintf("message %d: client does not support audio content", i),
+				}
+			}
+		case "image":
+			if !clientInfo.SamplingCaps.ImageSupport {
+				return &RemediableError{
+					Reason:  mcp.ReasonUnsupportedCapability,
+					Message: fmt.Sprintf("message %d: client does not support image content", i),
+				}
+			}
+		}
+
+		// Also validate against protocol version
+		if !msg.Content.IsValidForVersion(protocolVersion) {
+			return fmt.Errorf("message %d: content type '%s' not supported in protocol version '%s'",
+				i, msg.Content.Type, protocolVersion)
+		}
+	}
+
+	return nil
+}
+
 // generateRequestID generates a unique request ID
 func (s *serverImpl) generateRequestID() int64 {
 	// Generate a unique request ID using atomic operations for thread safety
@@ -857,6 +887,12 @@ func (s *serverImpl) generateRequestID() int64 {
 
 // HandleJSONRPCResponse processes a JSON-RPC response from the client
 func (s *serverImpl) HandleJSONRPCResponse(responseJSON []byte) error {
+	return s.handleJSONRPCResponse(context.Background(), responseJSON)
+}
+
+// handleJSONRPCResponse processes a JSON-RPC response received on the transport
+// session recorded in ctx, if any, matching it with the request it answers
+func (s *serverImpl) handleJSONRPCResponse(ctx context.Context, responseJSON []byte) error {
 	// Parse the response ID
 	var response struct {
 		JSONRPC string          `json:"jsonrpc"`
@@ -875,8 +911,13 @@ func (s *serverImpl) HandleJSONRPCResponse(responseJSON []byte) error {
 
 	// If we have a request tracker, resolve the request
 	if s.requestTracker != nil {
-		if !s.requestTracker.resolveRequest(id, responseJSON) {
+		sessionID, _ := transportSessionFromContext(ctx)
+		switch err := s.requestTracker.resolveRequest(id, sessionID, responseJSON); {
+		case errors.Is(err, errUnknownRequest):
 			s.logger.Warn("received response for unknown request", "id", id)
+		case errors.Is(err, errWrongSession):
+			s.logger.Warn("ignoring response from a session the request was not sent to",
+				"id", id, "sessionID", string(sessionID))
 		}
 	}
 
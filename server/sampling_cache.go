@@ -0,0 +1,323 @@
+package server
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrSamplingRateLimited is returned when a session has used up its sampling
+// requests for the current minute (SamplingConfig.MaxRequestsPerMinute).
+var ErrSamplingRateLimited = errors.New("sampling rate limit exceeded")
+
+// ErrSamplingBusy is returned when a sampling request waits its whole timeout for
+// one of the SamplingConfig.MaxConcurrentRequests slots.
+var ErrSamplingBusy = errors.New("too many concurrent sampling requests")
+
+// SamplingCache stores sampling responses by the hash of their request.
+// Implement it to share cached responses between server replicas, for example in
+// Redis, and set it with SamplingConfig.WithCache. Keys are hex-encoded SHA-256
+// hashes. Errors are logged and treated as cache misses, so a failing backend
+// only costs round trips to the client.
+type SamplingCache interface {
+	// Get returns the response cached under a key.
+	// The boolean is false if there is none or it has expired.
+	Get(key string) (*SamplingResponse, bool, error)
+
+	// Set caches a response under a key for ttl.
+	Set(key string, response *SamplingResponse, ttl time.Duration) error
+
+	// Delete removes a response. Deleting an unknown key is not an error.
+	Delete(key string) error
+}
+
+// MemorySamplingCache is an in-memory SamplingCache that evicts the least recently
+// used response once it holds its maximum number of responses.
+type MemorySamplingCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // Most recently used at the front
+}
+
+// memorySamplingEntry is a cached response along with its expiry time.
+type memorySamplingEntry struct {
+	key       string
+	response  SamplingResponse
+	expiresAt time.Time
+}
+
+// NewMemorySamplingCache creates an in-memory sampling cache holding at most
+// maxEntries responses. A maxEntries of zero leaves the cache unbounded.
+func NewMemorySamplingCache(maxEntries int) *MemorySamplingCache {
+	return &MemorySamplingCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get returns a copy of the response cached under a key.
+func (m *MemorySamplingCache) Get(key string) (*SamplingResponse, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, exists := m.entries[key]
+	if !exists {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*memorySamplingEntry)
+	if time.Now().After(entry.expiresAt) {
+		m.remove(element)
+		return nil, false, nil
+	}
+
+	m.order.MoveToFront(element)
+	response := entry.response
+	return &response, true, nil
+}
+
+// Set caches a copy of a response, evicting the least recently used response if
+// the cache is full.
+func (m *MemorySamplingCache) Set(key string, response *SamplingResponse, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, exists := m.entries[key]; exists {
+		m.remove(element)
+	}
+	entry := &memorySamplingEntry{key: key, response: *response, expiresAt: time.Now().Add(ttl)}
+	m.entries[key] = m.order.PushFront(entry)
+
+	for m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		m.remove(m.order.Back())
+	}
+	return nil
+}
+
+// Delete removes the response cached under a key.
+func (m *MemorySamplingCache) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, exists := m.entries[key]; exists {
+		m.remove(element)
+	}
+	return nil
+}
+
+// Len returns the number of cached responses, including expired responses that
+// have not been evicted yet.
+func (m *MemorySamplingCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// remove drops an element from the cache. The caller must hold m.mu.
+func (m *MemorySamplingCache) remove(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.entries, element.Value.(*memorySamplingEntry).key)
+}
+
+// SamplingMetrics counts the sampling requests handled by a SamplingController.
+type SamplingMetrics struct {
+	Requests     int64 // Requests made by handlers
+	CacheHits    int64 // Requests answered from the cache
+	CacheMisses  int64 // Cacheable requests that were not in the cache
+	CacheErrors  int64 // Failed reads and writes of the cache backend
+	Deduplicated int64 // Requests that shared the round trip of an identical pending request
+	RoundTrips   int64 // Requests sent to clients, counting retries once
+	Failures     int64 // Round trips that failed after all retries
+	RateLimited  int64 // Requests rejected by the per-minute limit
+	Queued       int64 // Requests that waited for a concurrency slot
+	Rejected     int64 // Requests that timed out waiting for a concurrency slot
+	InFlight     int   // Round trips in progress
+}
+
+// pendingSampling is a request in flight that identical requests wait for
+type pendingSampling struct {
+	done     chan struct{}
+	response *SamplingResponse
+	err      error
+}
+
+// Metrics returns a snapshot of the controller's counters.
+func (sc *SamplingController) Metrics() SamplingMetrics {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	metrics := sc.metrics
+	metrics.InFlight = sc.concurrentCount
+	return metrics
+}
+
+// SamplingMetrics returns the counters of the server's sampling requests,
+// such as cache hits, deduplicated requests and round trips to clients.
+//
+// Example:
+//
+//	metrics := server.SamplingMetrics()
+//	fmt.Printf("%d of %d sampling requests served from cache\n", metrics.CacheHits, metrics.Requests)
+func (s *serverImpl) SamplingMetrics() SamplingMetrics {
+	if s.samplingController == nil {
+		return SamplingMetrics{}
+	}
+	return s.samplingController.Metrics()
+}
+
+// samplingCacheKey returns the key a request is cached and deduplicated under: the
+// hash of its parameters, and of its session unless sessions share responses
+func (sc *SamplingController) samplingCacheKey(sessionID SessionID, paramsJSON []byte) string {
+	hash := sha256.New()
+	if !sc.config.ShareCache {
+		hash.Write([]byte(sessionID))
+	}
+	hash.Write([]byte{0})
+	hash.Write(paramsJSON)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// execute answers a sampling request from the cache or from an identical pending
+// request when the configuration allows it. Otherwise it waits for a concurrency
+// slot and calls send, caching a successful response. Requests that ask the client
+// to include its context are not cached, since the context changes between them.
+// A nil controller calls send directly.
+func (sc *SamplingController) execute(sessionID SessionID, paramsJSON []byte, includeContext string, timeout time.Duration, send func() (*SamplingResponse, error)) (*SamplingResponse, error) {
+	if sc == nil {
+		return send()
+	}
+
+	sc.mu.Lock()
+	sc.metrics.Requests++
+	ttl, deduplicate := sc.config.CacheTTL, sc.config.Deduplicate
+	cacheable := sc.cache != nil && ttl > 0 && (includeContext == "" || includeContext == "none")
+	key := sc.samplingCacheKey(sessionID, paramsJSON)
+	sc.mu.Unlock()
+
+	if cacheable {
+		response, found, err := sc.cache.Get(key)
+		sc.mu.Lock()
+		switch {
+		case err != nil:
+			sc.metrics.CacheErrors++
+			sc.logger.Warn("failed to read sampling cache", "error", err)
+		case found:
+			sc.metrics.CacheHits++
+		default:
+			sc.metrics.CacheMisses++
+		}
+		sc.mu.Unlock()
+		if found {
+			return response, nil
+		}
+	}
+
+	var pending *pendingSampling
+	if deduplicate {
+		sc.mu.Lock()
+		if leader, exists := sc.pending[key]; exists {
+			sc.metrics.Deduplicated++
+			sc.mu.Unlock()
+			<-leader.done
+			if leader.err != nil {
+				return nil, leader.err
+			}
+			response := *leader.response
+			return &response, nil
+		}
+		pending = &pendingSampling{done: make(chan struct{})}
+		sc.pending[key] = pending
+		sc.mu.Unlock()
+	}
+
+	response, err := sc.roundTrip(sessionID, timeout, send)
+	if err == nil && cacheable {
+		if cacheErr := sc.cache.Set(key, response, ttl); cacheErr != nil {
+			sc.mu.Lock()
+			sc.metrics.CacheErrors++
+			sc.mu.Unlock()
+			sc.logger.Warn("failed to write sampling cache", "error", cacheErr)
+		}
+	}
+
+	if pending != nil {
+		sc.mu.Lock()
+		delete(sc.pending, key)
+		sc.mu.Unlock()
+		pending.response, pending.err = response, err
+		close(pending.done)
+	}
+
+	return response, err
+}
+
+// roundTrip sends a request to the client once a concurrency slot is free
+func (sc *SamplingController) roundTrip(sessionID SessionID, timeout time.Duration, send func() (*SamplingResponse, error)) (*SamplingResponse, error) {
+	if err := sc.acquire(sessionID, timeout); err != nil {
+		return nil, err
+	}
+	defer sc.CompleteRequest(sessionID)
+
+	response, err := send()
+
+	sc.mu.Lock()
+	sc.metrics.RoundTrips++
+	if err != nil {
+		sc.metrics.Failures++
+	}
+	sc.mu.Unlock()
+
+	return response, err
+}
+
+// acquire records a request for a session, waiting up to timeout for a concurrency
+// slot. It fails at once if the session has used up its requests for the minute.
+func (sc *SamplingController) acquire(sessionID SessionID, timeout time.Duration) error {
+	var deadline <-chan time.Time
+	queued := false
+	for {
+		sc.mu.Lock()
+		sessionKey := string(sessionID)
+		if sessionKey == "" {
+			sessionKey = "default"
+		}
+		if sc.config.PerClientRateLimit && sc.requestCount[sessionKey] >= sc.config.MaxRequestsPerMinute {
+			sc.metrics.RateLimited++
+			sc.mu.Unlock()
+			return ErrSamplingRateLimited
+		}
+		if sc.config.MaxConcurrentRequests <= 0 || sc.concurrentCount < sc.config.MaxConcurrentRequests {
+			sc.concurrentCount++
+			if sc.config.PerClientRateLimit {
+				sc.requestCount[sessionKey]++
+			}
+			sc.mu.Unlock()
+			return nil
+		}
+
+		// Wait for a request to complete
+		released := sc.released
+		if !queued {
+			queued = true
+			sc.metrics.Queued++
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+		sc.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-deadline:
+			sc.mu.Lock()
+			sc.metrics.Rejected++
+			sc.mu.Unlock()
+			return ErrSamplingBusy
+		}
+	}
+}
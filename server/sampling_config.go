@@ -22,8 +22,9 @@ type SamplingConfig struct {
 	MaxTimeout     time.Duration // Maximum allowed timeout for sampling requests
 
 	// Retry settings
-	DefaultMaxRetries    int           // Default number of retries for failed requests
-	DefaultRetryInterval time.Duration // Default interval between retries
+	DefaultMaxRetries    int                  // Default number of retries for failed requests
+	DefaultRetryInterval time.Duration        // Default interval between retries
+	Retry                *SamplingRetryPolicy // Retry policy for all requests, set with WithRetry
 
 	// Priority settings
 	EnablePrioritization bool // Whether to enable request prioritization
@@ -35,6 +36,13 @@ type SamplingConfig struct {
 	// Error handling
 	GracefulDegradation bool // Whether to enable graceful degradation on errors
 
+	// Caching and deduplication settings
+	CacheTTL        time.Duration // How long responses are cached (0 disables caching)
+	CacheMaxEntries int           // Capacity of the default in-memory cache
+	Cache           SamplingCache // Cache backend, set with WithCache (in-memory if nil)
+	ShareCache      bool          // Whether sessions share cached and pending responses
+	Deduplicate     bool          // Whether identical pending requests share one round trip
+
 	// Protocol-specific settings
 	ProtocolDefaults map[string]*ProtocolSamplingConfig // Protocol-specific settings
 }
@@ -78,6 +86,8 @@ func NewDefaultSamplingConfig() *SamplingConfig {
 
 		GracefulDegradation: true,
 
+		CacheMaxEntries: 1000,
+
 		ProtocolDefaults: map[string]*ProtocolSamplingConfig{
 			"draft": {
 				MaxTokens: 2048,
@@ -119,6 +129,10 @@ type SamplingController struct {
 	concurrentCount int                // Current concurrent requests
 	requestQueue    []*samplingRequest // Prioritized request queue
 	rateLimiterTick *time.Ticker       // Ticker for rate limiting resets
+	released        chan struct{}      // Closed when a request completes
+	cache           SamplingCache      // Cached responses, nil if caching is disabled
+	pending         map[string]*pendingSampling
+	metrics         SamplingMetrics
 	mu              sync.RWMutex
 	logger          *slog.Logger // Logger instance
 }
@@ -153,8 +167,14 @@ func NewSamplingController(config *SamplingConfig, logger *slog.Logger) *Samplin
 	controller := &SamplingController{
 		config:       config,
 		requestCount: make(map[string]int),
+		released:     make(chan struct{}),
+		cache:        config.Cache,
+		pending:      make(map[string]*pendingSampling),
 		logger:       logger,
 	}
+	if controller.cache == nil && config.CacheTTL > 0 {
+		controller.cache = NewMemorySamplingCache(config.CacheMaxEntries)
+	}
 
 	// Start the rate limiter ticker
 	controller.rateLimiterTick = time.NewTicker(time.Minute)
@@ -248,6 +268,35 @@ func (sc *SamplingController) CompleteRequest(sessionID SessionID) {
 	if sc.concurrentCount > 0 {
 		sc.concurrentCount--
 	}
+
+	// Wake requests waiting for a concurrency slot
+	close(sc.released)
+	sc.released = make(chan struct{})
+}
+
+// WithCache caches successful sampling responses for ttl, so that identical
+// requests are answered without a round trip to the client. A nil cache keeps
+// up to CacheMaxEntries responses in memory; pass another SamplingCache to share
+// responses between replicas. Responses are cached per session unless ShareCache
+// is set.
+//
+// Example:
+//
+//	config := server.NewDefaultSamplingConfig().
+//	    WithCache(nil, 10*time.Minute).
+//	    WithDeduplication()
+//	server.NewServer("my-server", server.WithSamplingConfig(config))
+func (c *SamplingConfig) WithCache(cache SamplingCache, ttl time.Duration) *SamplingConfig {
+	c.Cache = cache
+	c.CacheTTL = ttl
+	return c
+}
+
+// WithDeduplication makes identical sampling requests that are pending at the same
+// time share one round trip to the client.
+func (c *SamplingConfig) WithDeduplication() *SamplingConfig {
+	c.Deduplicate = true
+	return c
 }
 
 // GetRequestOptions returns appropriate request options based on configuration.
@@ -390,11 +439,12 @@ func detectSamplingCapabilities(version string) SamplingCapabilities {
 //   - An Option function that configures sampling when applied to a server
 func WithSamplingConfig(config *SamplingConfig) Option {
 	return func(s *serverImpl) {
-		// Update server with the sampling configuration
-		if s.samplingConfig == nil {
-			s.samplingConfig = config
-			s.samplingController = NewSamplingController(config, s.logger)
+		// Replace the default sampling configuration and its controller
+		if s.samplingController != nil {
+			s.samplingController.Stop()
 		}
+		s.samplingConfig = config
+		s.samplingController = NewSamplingController(config, s.logger)
 	}
 }
 
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// ErrSamplingTimeout is returned when the client does not answer a sampling request in time.
+var ErrSamplingTimeout = errors.New("timeout waiting for sampling response")
+
+// defaultSamplingMaxBackoff caps the delay between sampling retries
+const defaultSamplingMaxBackoff = 30 * time.Second
+
+// SamplingClientError is an error response returned by the client to a sampling request.
+type SamplingClientError struct {
+	Code    int
+	Message string
+	Data    string
+}
+
+// Error implements the error interface.
+func (e *SamplingClientError) Error() string {
+	return fmt.Sprintf("sampling error: %s (code %d)", e.Message, e.Code)
+}
+
+// SamplingRetryCondition reports whether a sampling attempt should be retried.
+// err is set when the attempt failed, for example with ErrSamplingTimeout or a
+// *SamplingClientError. Otherwise response holds the client's result, which can
+// also warrant a retry, for example when its stop reason reports a rate limit.
+type SamplingRetryCondition func(response *SamplingResponse, err error) bool
+
+// SamplingRetryPolicy controls how failed sampling requests are retried.
+// Delays grow exponentially from Backoff, are capped at MaxBackoff, and are
+// jittered so that servers do not retry in lockstep.
+type SamplingRetryPolicy struct {
+	MaxRetries int                    // Retries after the first attempt
+	Backoff    time.Duration          // Delay before the first retry
+	MaxBackoff time.Duration          // Maximum delay between retries (default 30s)
+	RetryOn    SamplingRetryCondition // Which failures to retry (default RetryTransientSamplingFailures)
+}
+
+// WithRetry makes the server retry transient sampling failures automatically,
+// so that tool handlers only see an error once all retries are exhausted.
+// A nil retryOn retries the failures matched by RetryTransientSamplingFailures.
+// Individual requests can override the policy with RequestSamplingOptions.Retry.
+//
+// Example:
+//
+//	config := server.NewDefaultSamplingConfig().
+//	    WithRetry(3, 500*time.Millisecond, nil)
+//	server.NewServer("my-server", server.WithSamplingConfig(config))
+func (c *SamplingConfig) WithRetry(maxRetries int, backoff time.Duration, retryOn SamplingRetryCondition) *SamplingConfig {
+	c.Retry = &SamplingRetryPolicy{
+		MaxRetries: maxRetries,
+		Backoff:    backoff,
+		RetryOn:    retryOn,
+	}
+	return c
+}
+
+// RetryTransientSamplingFailures is the default retry condition. It retries
+// timeouts, client errors that indicate a temporary problem (codes 500-599 or
+// messages mentioning timeouts, rate limits or temporary unavailability), and
+// responses whose stop reason reports a rate limit.
+func RetryTransientSamplingFailures(response *SamplingResponse, err error) bool {
+	if err != nil {
+		if errors.Is(err, ErrSamplingTimeout) {
+			return true
+		}
+
+		var clientErr *SamplingClientError
+		if !errors.As(err, &clientErr) {
+			return false
+		}
+		if clientErr.Code >= 500 && clientErr.Code < 600 {
+			return true
+		}
+
+		message := strings.ToLower(clientErr.Message)
+		return strings.Contains(message, "timeout") ||
+			strings.Contains(message, "rate limit") ||
+			strings.Contains(message, "temporarily unavailable") ||
+			strings.Contains(message, "try again")
+	}
+
+	return response != nil && IsRateLimitStopReason(response.StopReason)
+}
+
+// IsRateLimitStopReason reports whether a sampling stop reason means the client's
+// model provider rate limited the request. Spellings such as "rateLimit",
+// "rate_limited" and "rate-limit" are recognized.
+func IsRateLimitStopReason(stopReason string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "", " ", "").Replace(strings.ToLower(stopReason))
+	return normalized == "ratelimit" || normalized == "ratelimited"
+}
+
+// shouldRetry reports whether an attempt's outcome matches the policy's condition
+func (p *SamplingRetryPolicy) shouldRetry(response *SamplingResponse, err error) bool {
+	if p.RetryOn == nil {
+		return RetryTransientSamplingFailures(response, err)
+	}
+	return p.RetryOn(response, err)
+}
+
+// delay returns the jittered wait before the given retry (1 for the first)
+func (p *SamplingRetryPolicy) delay(retry int) time.Duration {
+	if p.Backoff <= 0 {
+		return 0
+	}
+
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultSamplingMaxBackoff
+	}
+
+	backoff := p.Backoff
+	for i := 1; i < retry && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	// Wait between half and the full backoff
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(backoff-half)+1))
+}
+
+// samplingRetryPolicy returns the retry policy for a request: the request's own
+// policy, else the server's configured policy, else one built from the request's
+// MaxRetries and RetryInterval.
+func (s *serverImpl) samplingRetryPolicy(options RequestSamplingOptions) *SamplingRetryPolicy {
+	if options.Retry != nil {
+		return options.Retry
+	}
+	if s.samplingConfig != nil && s.samplingConfig.Retry != nil {
+		return s.samplingConfig.Retry
+	}
+	return &SamplingRetryPolicy{
+		MaxRetries: options.MaxRetries,
+		Backoff:    options.RetryInterval,
+	}
+}
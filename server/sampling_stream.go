@@ -0,0 +1,168 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/localrivet/gomcp/mcp"
+)
+
+// ErrSamplingStreamFull is returned by SamplingStream.Send once the stream has sent
+// the maximum number of chunks the client asked for.
+var ErrSamplingStreamFull = errors.New("sampling stream reached its maximum number of chunks")
+
+// SamplingProvider answers the sampling/createMessage requests clients send to the
+// server, for servers that run a model of their own. stream is non-nil when the
+// client asked for a streamed response and the negotiated protocol version supports
+// streaming; the provider then sends text to it as the model generates it. Either
+// way the provider returns the complete response, which is sent as the result.
+type SamplingProvider func(ctx *Context, params SamplingCreateMessageParams, stream *SamplingStream) (*SamplingResponse, error)
+
+// WithSamplingProvider makes the server answer sampling requests from clients with
+// provider. Without a provider, such requests fail, since sampling is a client
+// capability in MCP.
+//
+// Example:
+//
+//	server.NewServer("llm-gateway", server.WithSamplingProvider(
+//	    func(ctx *server.Context, params server.SamplingCreateMessageParams, stream *server.SamplingStream) (*server.SamplingResponse, error) {
+//	        var text strings.Builder
+//	        for token := range model.Generate(params.Messages) {
+//	            text.WriteString(token)
+//	            if stream != nil {
+//	                if err := stream.Send(token); err != nil {
+//	                    break
+//	                }
+//	            }
+//	        }
+//	        return &server.SamplingResponse{Role: "assistant", Content: server.SamplingMessageContent{Type: "text", Text: text.String()}}, nil
+//	    }))
+func WithSamplingProvider(provider SamplingProvider) Option {
+	return func(s *serverImpl) {
+		s.samplingProvider = provider
+	}
+}
+
+// SamplingStream sends the chunks of a streamed sampling response to the client
+// that requested it, as notifications correlated by the request's ID. It is safe
+// for concurrent use.
+type SamplingStream struct {
+	ctx       *Context
+	chunkSize int
+	maxChunks int
+
+	mu     sync.Mutex
+	chunks int
+}
+
+// Send streams text, split into chunks of at most the chunk size the client asked
+// for. It returns ErrSamplingStreamFull once the client's maximum number of chunks
+// has been sent, after which the provider should stop generating.
+func (st *SamplingStream) Send(text string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for _, piece := range splitSamplingChunk(text, st.chunkSize) {
+		if st.maxChunks > 0 && st.chunks >= st.maxChunks {
+			return ErrSamplingStreamFull
+		}
+
+		params := mcp.SamplingChunkParams{
+			RequestID: st.ctx.Request.ID,
+			Chunk: SamplingResponse{
+				Role:       "assistant",
+				Content:    SamplingMessageContent{Type: "text", Text: piece},
+				ChunkIndex: st.chunks,
+			},
+		}
+		if err := st.ctx.server.writeSessionNotification(st.ctx.Session, mcp.SamplingChunkNotification, params); err != nil {
+			return fmt.Errorf("failed to send sampling chunk: %w", err)
+		}
+		st.chunks++
+	}
+	return nil
+}
+
+// Chunks returns the number of chunks sent so far.
+func (st *SamplingStream) Chunks() int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.chunks
+}
+
+// splitSamplingChunk splits text into pieces of at most size characters, or
+// returns it whole if size is not positive
+func splitSamplingChunk(text string, size int) []string {
+	if text == "" {
+		return nil
+	}
+	runes := []rune(text)
+	if size <= 0 || len(runes) <= size {
+		return []string{text}
+	}
+
+	pieces := make([]string, 0, (len(runes)+size-1)/size)
+	for start := 0; start < len(runes); start += size {
+		end := start + size
+		if end > len(runes) {
+			end = len(runes)
+		}
+		pieces = append(pieces, string(runes[start:end]))
+	}
+	return pieces
+}
+
+// samplingStreamingSupported reports whether sampling responses may be streamed
+// under a protocol version
+func (s *serverImpl) samplingStreamingSupported(version string) bool {
+	if s.samplingConfig == nil {
+		return false
+	}
+	protocolConfig, exists := s.samplingConfig.ProtocolDefaults[version]
+	return exists && protocolConfig.StreamingSupported
+}
+
+// answerSamplingRequest answers a sampling request from a client with the server's
+// sampling provider, streaming the response if the client asked for it
+func (s *serverImpl) answerSamplingRequest(ctx *Context) (interface{}, error) {
+	var params SamplingCreateMessageParams
+	if err := json.Unmarshal(ctx.Request.Params, &params); err != nil {
+		return nil, &InvalidParametersError{Message: fmt.Sprintf("invalid sampling parameters: %v", err)}
+	}
+	if len(params.Messages) == 0 {
+		return nil, &InvalidParametersError{Message: "at least one message is required"}
+	}
+
+	// Clients of versions without streaming get the complete response only
+	var stream *SamplingStream
+	version := ctx.protocolVersion()
+	if params.Streaming != nil && s.samplingStreamingSupported(version) {
+		stream = &SamplingStream{
+			ctx:       ctx,
+			chunkSize: params.Streaming.ChunkSize,
+			maxChunks: params.Streaming.MaxChunks,
+		}
+	}
+
+	response, err := s.samplingProvider(ctx, params, stream)
+	if err != nil {
+		return nil, err
+	}
+	if response == nil {
+		return nil, fmt.Errorf("sampling provider returned no response")
+	}
+	if !response.Content.IsValidForVersion(version) {
+		return nil, fmt.Errorf("response content type '%s' not supported in protocol version '%s'",
+			response.Content.Type, version)
+	}
+
+	// The result closes the stream and tells the client how many chunks to expect
+	result := *response
+	if stream != nil {
+		result.IsComplete = true
+		result.ChunkIndex = stream.Chunks()
+	}
+	return &result, nil
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSamplingProviderStreamsChunks(t *testing.T) {
+	var streamed []bool
+	s := NewServer("sampling-provider-test", WithSamplingProvider(
+		func(ctx *Context, params SamplingCreateMessageParams, stream *SamplingStream) (*SamplingResponse, error) {
+			streamed = append(streamed, stream != nil)
+			if stream != nil {
+				// The text takes four chunks, one more than the client allows
+				assert.ErrorIs(t, stream.Send("one two three"), ErrSamplingStreamFull)
+				assert.Equal(t, 3, stream.Chunks())
+			}
+			return &SamplingResponse{Role: "assistant", Content: SamplingMessageContent{Type: "text", Text: "one two three"}}, nil
+		})).GetServer()
+	tr := &sharedChannelTransport{}
+	s.SetTransport(tr)
+
+	for sessionID, version := range map[string]string{"current": "2025-03-26", "legacy": "2024-11-05"} {
+		_, err := s.handleSessionMessage(sessionID, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":%q,"capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`, version)))
+		require.NoError(t, err)
+	}
+	request := `{"jsonrpc":"2.0","id":7,"method":"sampling/createMessage","params":{"messages":[{"role":"user","content":{"type":"text","text":"count"}}],"maxTokens":10,"streaming":{"chunkSize":4,"maxChunks":3}}}`
+
+	// Chunks go to the requesting session, and the result counts them
+	raw, err := s.handleSessionMessage("current", []byte(request))
+	require.NoError(t, err)
+	var response struct {
+		Result SamplingResponse `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &response))
+	assert.True(t, response.Result.IsComplete)
+	assert.Equal(t, 3, response.Result.ChunkIndex)
+	assert.Equal(t, "one two three", response.Result.Content.Text)
+
+	var chunks []string
+	for _, message := range tr.sentTo("current") {
+		if !strings.Contains(message, mcp.SamplingChunkNotification) {
+			continue
+		}
+		var notification struct {
+			Params mcp.SamplingChunkParams `json:"params"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(message), &notification))
+		assert.EqualValues(t, 7, notification.Params.RequestID)
+		assert.Equal(t, len(chunks), notification.Params.Chunk.ChunkIndex)
+		chunks = append(chunks, notification.Params.Chunk.Content.Text)
+	}
+	assert.Equal(t, []string{"one ", "two ", "thre"}, chunks)
+
+	// Versions without streaming get the complete response only
+	raw, err = s.handleSessionMessage("legacy", []byte(request))
+	require.NoError(t, err)
+	response.Result = SamplingResponse{}
+	require.NoError(t, json.Unmarshal(raw, &response))
+	assert.False(t, response.Result.IsComplete)
+	assert.Equal(t, "one two three", response.Result.Content.Text)
+	for _, message := range tr.sentTo("legacy") {
+		assert.NotContains(t, message, mcp.SamplingChunkNotification)
+	}
+	assert.Equal(t, []bool{true, false}, streamed)
+
+	// Servers without a provider reject the request
+	plain := NewServer("no-provider-test").GetServer()
+	raw, err = plain.handleMessage([]byte(request))
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `"error"`)
+}
@@ -0,0 +1,436 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/localrivet/gomcp/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario scripts a simulated server for demos and tests: its tools answer calls
+// with canned responses chosen by their arguments, optionally after a delay and
+// after sending notifications, instead of running code. It is read from a YAML or
+// JSON file by LoadScenario and registers its tools as a ToolProvider.
+//
+// Example:
+//
+//	name: math
+//	tools:
+//	  - name: divide
+//	    description: Divide two numbers
+//	    inputSchema:
+//	      type: object
+//	      properties:
+//	        a: {type: number}
+//	        b: {type: number}
+//	    responses:
+//	      - match: {b: 0}
+//	        error: division by zero
+//	      - match: {a: 6, b: 3}
+//	        result: {quotient: 2}
+//	      - text: "cannot divide {{a}} by {{b}} in this demo"
+//	  - name: slow_process
+//	    responses:
+//	      - delay: "{{duration}}"
+//	        notify:
+//	          - method: notifications/message
+//	            params: {level: info, message: "processing {{message}}"}
+//	        text: "Completed: {{message}}"
+type Scenario struct {
+	// Name identifies the server to clients
+	Name string `yaml:"name"`
+
+	Tools []ScenarioTool `yaml:"tools"`
+}
+
+// ScenarioTool is a tool of a scenario and its scripted responses.
+type ScenarioTool struct {
+	Name        string                 `yaml:"name"`
+	Description string                 `yaml:"description"`
+	InputSchema map[string]interface{} `yaml:"inputSchema"`
+	Responses   []ScenarioResponse     `yaml:"responses"`
+}
+
+// ScenarioResponse is a scripted answer to a tool call. A call is answered by the
+// first response whose Match and MatchPattern its arguments satisfy and which has
+// not been used up. {{name}} in Text, Error, Delay, Result strings and
+// notification params is replaced with the argument of that name.
+type ScenarioResponse struct {
+	// Match holds argument values the call must have. A response without Match or
+	// MatchPattern answers any call.
+	Match map[string]interface{} `yaml:"match"`
+
+	// MatchPattern holds regular expressions the arguments must match, as text
+	MatchPattern map[string]string `yaml:"matchPattern"`
+
+	// Times is how many calls the response answers before the next matching
+	// response takes over, so that responses can be scripted in sequence. Zero
+	// means any number.
+	Times int `yaml:"times"`
+
+	// Delay is how long to wait before answering, as a Go duration such as "2s"
+	Delay string `yaml:"delay"`
+
+	// Notify lists the notifications sent, in order, before the call is answered
+	Notify []ScenarioNotification `yaml:"notify"`
+
+	// Text answers with a single text item
+	Text string `yaml:"text"`
+
+	// Result answers with a value, as a tool handler returns it
+	Result interface{} `yaml:"result"`
+
+	// Error fails the call with this message
+	Error string `yaml:"error"`
+
+	patterns map[string]*regexp.Regexp
+}
+
+// ScenarioNotification is a notification sent while a scripted call is in
+// progress. notifications/message is sent as a log message with the level and
+// message of its params, notifications/progress as progress of the call, the
+// list_changed notifications as they are, and any other method as a
+// custom notification (see Context.Notify).
+type ScenarioNotification struct {
+	// Delay is how long to wait before sending the notification
+	Delay string `yaml:"delay"`
+
+	Method string                 `yaml:"method"`
+	Params map[string]interface{} `yaml:"params"`
+}
+
+// scenarioArg matches the {{name}} references of scripted values
+var scenarioArg = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// NewServerFromScenario creates a server simulating the scenario in a YAML or JSON
+// file. The options are those of NewServer.
+//
+// Example:
+//
+//	s, err := server.NewServerFromScenario("math.yaml")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	s.AsStdio().Run()
+func NewServerFromScenario(path string, options ...Option) (Server, error) {
+	scenario, err := LoadScenario(path)
+	if err != nil {
+		return nil, err
+	}
+	s := NewServer(scenario.Name, options...)
+	if err := scenario.Register(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadScenario reads a scenario from a YAML or JSON file.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	scenario, err := ParseScenario(data)
+	if err != nil {
+		return nil, fmt.Errorf("scenario %s: %w", path, err)
+	}
+	return scenario, nil
+}
+
+// ParseScenario reads a scenario from YAML or JSON, such as one embedded in a
+// program.
+func ParseScenario(data []byte) (*Scenario, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario: %w", err)
+	}
+	scenario := &Scenario{}
+	if document.Kind != 0 {
+		if err := decodeStrict(&document, scenario); err != nil {
+			return nil, fmt.Errorf("failed to parse scenario: %w", err)
+		}
+	}
+	if err := scenario.validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario: %w", err)
+	}
+	return scenario, nil
+}
+
+// validate checks a scenario and prepares its responses for matching
+func (sc *Scenario) validate() error {
+	if sc.Name == "" {
+		return errors.New("name is required")
+	}
+	names := make(map[string]bool, len(sc.Tools))
+	for i := range sc.Tools {
+		tool := &sc.Tools[i]
+		if tool.Name == "" {
+			return fmt.Errorf("tools[%d]: name is required", i)
+		}
+		if names[tool.Name] {
+			return fmt.Errorf("tool %s is defined twice", tool.Name)
+		}
+		names[tool.Name] = true
+
+		for j := range tool.Responses {
+			if err := tool.Responses[j].prepare(); err != nil {
+				return fmt.Errorf("tool %s: responses[%d]: %w", tool.Name, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+// prepare normalizes a response's values to those of decoded JSON arguments and
+// compiles its patterns
+func (r *ScenarioResponse) prepare() error {
+	if err := checkScenarioDuration(r.Delay); err != nil {
+		return fmt.Errorf("delay: %w", err)
+	}
+	for i := range r.Notify {
+		n := &r.Notify[i]
+		if n.Method == "" {
+			return fmt.Errorf("notify[%d]: method is required", i)
+		}
+		if err := checkScenarioDuration(n.Delay); err != nil {
+			return fmt.Errorf("notify[%d]: delay: %w", i, err)
+		}
+		if n.Params != nil {
+			if err := jsonRoundTrip(&n.Params); err != nil {
+				return fmt.Errorf("notify[%d]: params: %w", i, err)
+			}
+		}
+	}
+
+	// YAML decodes 2 as an int while JSON arguments hold float64s
+	if r.Match != nil {
+		if err := jsonRoundTrip(&r.Match); err != nil {
+			return fmt.Errorf("match: %w", err)
+		}
+	}
+	if r.Result != nil {
+		if err := jsonRoundTrip(&r.Result); err != nil {
+			return fmt.Errorf("result: %w", err)
+		}
+	}
+
+	r.patterns = make(map[string]*regexp.Regexp, len(r.MatchPattern))
+	for name, pattern := range r.MatchPattern {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("matchPattern %s: %w", name, err)
+		}
+		r.patterns[name] = re
+	}
+	return nil
+}
+
+// checkScenarioDuration checks a duration unless it depends on the arguments
+func checkScenarioDuration(value string) error {
+	if value == "" || scenarioArg.MatchString(value) {
+		return nil
+	}
+	_, err := time.ParseDuration(value)
+	return err
+}
+
+// jsonRoundTrip replaces a value with its JSON decoding
+func jsonRoundTrip(value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+// Register adds the scenario's tools to srv. Each server registered keeps its own
+// count of the responses used.
+func (sc *Scenario) Register(srv Server) error {
+	for i := range sc.Tools {
+		tool := &sc.Tools[i]
+		inputSchema := tool.InputSchema
+		if inputSchema == nil {
+			inputSchema = map[string]interface{}{"type": "object"}
+		}
+		script := &scenarioScript{tool: tool, used: make([]int, len(tool.Responses))}
+		srv.GetServer().ToolWithSchema(tool.Name, tool.Description, inputSchema, script.call)
+	}
+	return nil
+}
+
+// scenarioScript answers the calls of a scenario tool
+type scenarioScript struct {
+	tool *ScenarioTool
+
+	mu   sync.Mutex
+	used []int
+}
+
+// call answers a call with the first matching response
+func (s *scenarioScript) call(ctx *Context, args map[string]interface{}) (interface{}, error) {
+	response := s.respond(args)
+	if response == nil {
+		return nil, fmt.Errorf("no scenario response matches the call to %s", s.tool.Name)
+	}
+
+	if err := s.wait(ctx, response.Delay, args); err != nil {
+		return nil, err
+	}
+	for _, n := range response.Notify {
+		if err := s.wait(ctx, n.Delay, args); err != nil {
+			return nil, err
+		}
+		if err := s.notify(ctx, n, args); err != nil {
+			ctx.Logger.Warn("failed to send scenario notification", "tool", s.tool.Name, "method", n.Method, "error", err)
+		}
+	}
+
+	switch {
+	case response.Error != "":
+		return nil, errors.New(expandScenarioText(response.Error, args))
+	case response.Result != nil:
+		return expandScenarioValue(response.Result, args), nil
+	default:
+		return expandScenarioText(response.Text, args), nil
+	}
+}
+
+// respond picks the response answering a call and counts its use
+func (s *scenarioScript) respond(args map[string]interface{}) *ScenarioResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.tool.Responses {
+		response := &s.tool.Responses[i]
+		if !response.matches(args) {
+			continue
+		}
+		if response.Times > 0 && s.used[i] >= response.Times {
+			continue
+		}
+		s.used[i]++
+		return response
+	}
+	return nil
+}
+
+// matches reports whether a call's arguments satisfy the response
+func (r *ScenarioResponse) matches(args map[string]interface{}) bool {
+	for name, want := range r.Match {
+		got, ok := args[name]
+		if !ok || !reflect.DeepEqual(got, want) {
+			return false
+		}
+	}
+	for name, re := range r.patterns {
+		got, ok := args[name]
+		if !ok || !re.MatchString(fmt.Sprint(got)) {
+			return false
+		}
+	}
+	return true
+}
+
+// wait sleeps for a scripted delay, or until the call is cancelled
+func (s *scenarioScript) wait(ctx *Context, delay string, args map[string]interface{}) error {
+	if delay == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(expandScenarioText(delay, args))
+	if err != nil {
+		return fmt.Errorf("invalid delay: %w", err)
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Context().Done():
+		return ctx.Context().Err()
+	}
+}
+
+// notify sends a scripted notification
+func (s *scenarioScript) notify(ctx *Context, n ScenarioNotification, args map[string]interface{}) error {
+	params, _ := expandScenarioValue(n.Params, args).(map[string]interface{})
+
+	switch n.Method {
+	case "notifications/message":
+		level := mcp.LogLevelInfo
+		if value, ok := params["level"].(string); ok {
+			level = mcp.LogLevel(value)
+		}
+		message, _ := params["message"].(string)
+		data := make(map[string]interface{}, len(params))
+		for key, value := range params {
+			if key != "level" && key != "message" {
+				data[key] = value
+			}
+		}
+		return ctx.Log(level, message, data)
+	case "notifications/progress":
+		progress, _ := params["progress"].(float64)
+		message, _ := params["message"].(string)
+		var total *float64
+		if value, ok := params["total"].(float64); ok {
+			total = &value
+		}
+		return ctx.SendProgress(progress, total, message)
+	case "notifications/tools/list_changed", "notifications/resources/list_changed", "notifications/prompts/list_changed":
+		if ctx.server == nil {
+			return errors.New("cannot notify: context has no server")
+		}
+		ctx.server.sendNotification(n.Method, nil)
+		return nil
+	default:
+		return ctx.Notify(n.Method, params)
+	}
+}
+
+// expandScenarioText replaces the {{name}} references of a scripted text
+func expandScenarioText(text string, args map[string]interface{}) string {
+	return scenarioArg.ReplaceAllStringFunc(text, func(reference string) string {
+		name := scenarioArg.FindStringSubmatch(reference)[1]
+		if value, ok := args[name]; ok {
+			return fmt.Sprint(value)
+		}
+		return reference
+	})
+}
+
+// expandScenarioValue replaces the {{name}} references in the strings of a
+// scripted value. A string that is only a reference takes the argument's value,
+// so that numbers and objects keep their type.
+func expandScenarioValue(value interface{}, args map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if match := scenarioArg.FindStringSubmatch(v); match != nil && match[0] == v {
+			if arg, ok := args[match[1]]; ok {
+				return arg
+			}
+		}
+		return expandScenarioText(v, args)
+	case map[string]interface{}:
+		expanded := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			expanded[key] = expandScenarioValue(item, args)
+		}
+		return expanded
+	case []interface{}:
+		expanded := make([]interface{}, len(v))
+		for i, item := range v {
+			expanded[i] = expandScenarioValue(item, args)
+		}
+		return expanded
+	default:
+		return value
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mathScenario = `
+name: math
+tools:
+  - name: divide
+    description: Divide two numbers
+    inputSchema:
+      type: object
+      properties:
+        a: {type: number}
+        b: {type: number}
+    responses:
+      - match: {b: 0}
+        error: division by zero
+      - match: {a: 6, b: 3}
+        times: 1
+        result: {quotient: 2, dividend: "{{a}}"}
+      - text: "cannot divide {{a}} by {{b}} in this demo"
+  - name: slow_echo
+    responses:
+      - matchPattern: {message: "^urgent"}
+        text: "Now: {{message}}"
+      - delay: "{{duration}}"
+        notify:
+          - method: notifications/vendor/step
+            params: {step: "echoing {{message}}"}
+        text: "Completed: {{message}}"
+`
+
+func TestScenario(t *testing.T) {
+	scenario, err := ParseScenario([]byte(mathScenario))
+	require.NoError(t, err)
+
+	s := NewServer(scenario.Name).GetServer()
+	tr := &recordingTransport{}
+	s.SetTransport(tr)
+	require.NoError(t, scenario.Register(s))
+	send := func(message string) string {
+		t.Helper()
+		response, err := s.handleMessage([]byte(message))
+		require.NoError(t, err)
+		return string(response)
+	}
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+
+	list := send(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	assert.Contains(t, list, `"name":"divide"`)
+	assert.Contains(t, list, `"name":"slow_echo"`)
+
+	// Responses are picked by their arguments, in order, until used up
+	call := func(id int, tool, args string) string {
+		return send(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":%q,"arguments":%s}}`, id, tool, args))
+	}
+	quotient := call(3, "divide", `{"a":6,"b":3}`)
+	assert.Contains(t, quotient, `\"quotient\": 2`)
+	assert.Contains(t, quotient, `\"dividend\": 6`, "Expected a reference to keep the argument's type")
+	assert.Contains(t, call(4, "divide", `{"a":6,"b":3}`), "cannot divide 6 by 3 in this demo")
+	failed := call(5, "divide", `{"a":1,"b":0}`)
+	assert.Contains(t, failed, "division by zero")
+	assert.Contains(t, failed, `"isError":true`)
+
+	assert.Contains(t, call(6, "slow_echo", `{"message":"urgent fix"}`), "Now: urgent fix")
+
+	start := time.Now()
+	assert.Contains(t, call(7, "slow_echo", `{"message":"hello","duration":"100ms"}`), "Completed: hello")
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.True(t, tr.sentContaining(`"method":"notifications/vendor/step","params":{"step":"echoing hello"}`), "Expected the scripted notification")
+}
+
+func TestParseScenarioErrors(t *testing.T) {
+	cases := map[string]string{
+		"name: math\ntools:\n  - name: add\n  - name: add\n":                       "defined twice",
+		"name: math\ntools:\n  - name: add\n    responses:\n      - delay: soon\n": "delay",
+		"name: math\ntools:\n  - name: add\n    responses:\n      - sleep: 1s\n":   "field sleep not found",
+		"tools: []\n": "name is required",
+		"name: math\ntools:\n  - name: add\n    responses:\n      - matchPattern: {a: \"(\"}\n": "matchPattern a",
+	}
+	for content, want := range cases {
+		_, err := ParseScenario([]byte(content))
+		if assert.Error(t, err, content) {
+			assert.Contains(t, err.Error(), want)
+		}
+	}
+}
@@ -26,17 +26,20 @@ import (
 	"github.com/localrivet/gomcp/transport/stdio"
 	"github.com/localrivet/gomcp/transport/udp"
 	"github.com/localrivet/gomcp/transport/unix"
+	"github.com/localrivet/gomcp/transport/ws"
 )
 
-// Server represents an MCP server with fluent configuration methods.
-// It provides a builder-style API for configuring all aspects of an MCP server
-// including tools, resources, prompts, and transport options.
-type Server interface {
+// Core is the lifecycle of a running MCP server.
+//
+// Core is deliberately small and stable: it will not grow as features and transports
+// are added, so wrappers, mocks and helpers that only start and stop servers should
+// accept a Core rather than a Server.
+type Core interface {
 	// Run starts the server and blocks until it exits.
 	//
 	// This method initializes the server, starts listening for connections,
-	// and processes incoming requests. It blocks until the server encounters
-	// an error or is explicitly stopped.
+	// and processes incoming requests. It blocks until the server fails to
+	// start or is shut down.
 	//
 	// Example:
 	//  if err := server.Run(); err != nil {
@@ -44,10 +47,23 @@ type Server interface {
 	//  }
 	Run() error
 
+	// Serve starts the server and blocks until ctx is done or the server is shut down.
+	//
+	// When ctx is done, the server is shut down gracefully as with Shutdown.
+	//
+	// Example:
+	//  ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	//  defer stop()
+	//  if err := server.Serve(ctx); err != nil {
+	//      log.Fatalf("Server error: %v", err)
+	//  }
+	Serve(ctx context.Context) error
+
 	// Shutdown gracefully shuts down the server.
 	//
-	// This method stops accepting new connections and gracefully terminates
-	// existing connections. It returns any error encountered during shutdown.
+	// This method stops accepting new requests, waits up to the drain timeout
+	// (see WithDrainTimeout) for in-flight handlers, notifies clients and closes
+	// the transport. It returns any error encountered during shutdown.
 	//
 	// Example:
 	//  if err := server.Shutdown(); err != nil {
@@ -55,6 +71,36 @@ type Server interface {
 	//  }
 	Shutdown() error
 
+	// ShutdownContext gracefully shuts down the server like Shutdown, but stops
+	// waiting for in-flight handlers as soon as ctx is done.
+	//
+	// Example:
+	//  ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	//  defer cancel()
+	//  if err := server.ShutdownContext(ctx); err != nil {
+	//      log.Printf("Server shutdown error: %v", err)
+	//  }
+	ShutdownContext(ctx context.Context) error
+}
+
+// Server represents an MCP server with fluent configuration methods.
+// It provides a builder-style API for configuring all aspects of an MCP server
+// including tools, resources, prompts, and transport options.
+//
+// New transports are selected with Configure rather than with new AsX methods,
+// so the interface does not change when a transport is added. Features beyond
+// registering tools, resources and prompts, such as providers, completions,
+// custom methods and usage reports, are methods of the server GetServer returns,
+// so that they do not grow the interface either.
+type Server interface {
+	Core
+
+	// Configure selects the transport the server communicates over.
+	//
+	// Example:
+	//  server.Configure(server.HTTP(":8080"))
+	Configure(transport Transport) Server
+
 	// Tool registers a tool with the server.
 	//
 	// The name parameter is the unique identifier for the tool. The description
@@ -94,6 +140,9 @@ type Server interface {
 	//   - `path:"name"` for URI template parameters
 	//   - `json:"name"` for JSON body parameters
 	//
+	// Optional annotations, such as WithTags, are included in resources/list and
+	// resources/templates/list responses.
+	//
 	// Example:
 	//  server.Resource("/users/{id}", "Update user name", func(ctx *Context, args struct{
 	//		ID   string `path:"id"`
@@ -112,7 +161,7 @@ type Server interface {
 	//      }
 	//      return user, nil
 	//  })
-	Resource(path, description string, handler interface{}) Server
+	Resource(path, description string, handler interface{}, annotations ...map[string]interface{}) Server
 
 	// Prompt registers a prompt template with the server.
 	//
@@ -273,10 +322,21 @@ type Server interface {
 	// AsWebsocket configures the server to use WebSocket for communication.
 	//
 	// The address parameter specifies the host and port to listen on.
+	// Additional options can be provided to configure TLS, compression,
+	// keepalive pings, message size limits and origin validation.
 	//
 	// Example:
+	//  // Basic configuration
 	//  server.AsWebsocket("localhost:8080")
-	AsWebsocket(address string) Server
+	//
+	//  // With TLS and custom options
+	//  server.AsWebsocket(":8443",
+	//      ws.WithTLS("cert.pem", "key.pem"),
+	//      ws.WithCompression(true),
+	//      ws.WithKeepAlive(30*time.Second, 10*time.Second),
+	//      ws.WithReadLimit(1024*1024),
+	//      ws.WithAllowedOrigins("https://app.example.com"))
+	AsWebsocket(address string, options ...ws.Option) Server
 
 	// AsSSE configures the server to use Server-Sent Events for communication.
 	//
@@ -379,8 +439,8 @@ type Server interface {
 	//	client := client.NewEmbeddedTransport(clientTransport)
 	AsEmbedded(transport *embedded.Transport) Server
 
-	// GetServer returns the underlying server implementation
-	// This is primarily for internal use and testing.
+	// GetServer returns the underlying server implementation, which has the
+	// methods of features beyond the Server interface.
 	GetServer() *serverImpl
 }
 
@@ -403,6 +463,27 @@ type serverImpl struct {
 	// prompts is a map of registered prompt templates keyed by prompt name.
 	prompts map[string]*Prompt
 
+	// completions maps prompt and resource arguments to their completion providers.
+	completions map[completionKey]CompletionHandler
+
+	// methods maps custom JSON-RPC methods to their handlers (see Method).
+	methods map[string]MethodHandler
+
+	// notificationMu protects notificationHandlers and unknownMethods.
+	notificationMu sync.Mutex
+
+	// notificationHandlers maps notification methods to custom handlers (see OnNotification).
+	notificationHandlers map[string]NotificationHandler
+
+	// unknownMethods counts received notifications nobody handles, by method.
+	unknownMethods map[string]int64
+
+	// logLevelMu protects logLevels.
+	logLevelMu sync.RWMutex
+
+	// logLevels holds the minimum log level each session set with logging/setLevel.
+	logLevels map[SessionID]mcp.LogLevel
+
 	// roots is a slice of registered root paths for resource navigation.
 	roots []string
 
@@ -418,12 +499,16 @@ type serverImpl struct {
 	// mu protects concurrent access to server state.
 	mu sync.RWMutex
 
-	// protocolVersion is the negotiated MCP protocol version for this server.
+	// protocolVersion is the MCP protocol version enforced with WithProtocolVersion, if any.
+	// Negotiated versions are stored per session (see sessionProtocolVersion).
 	protocolVersion string
 
 	// requestTracker manages pending requests and matches responses to requests.
 	requestTracker *requestTracker
 
+	// requestSessions records which session each server-initiated request was sent to.
+	requestSessions RequestSessionMap
+
 	// requestCanceller manages cancellable requests and processes cancellation notifications.
 	requestCanceller *RequestCanceller
 
@@ -452,6 +537,9 @@ type serverImpl struct {
 	// samplingController manages sampling requests and applies sampling configuration.
 	samplingController *SamplingController
 
+	// samplingProvider answers sampling requests from clients, set with WithSamplingProvider
+	samplingProvider SamplingProvider
+
 	// initialized indicates whether the client has sent the initialized notification
 	// Only after receiving this notification should the server send feature-specific notifications
 	initialized bool
@@ -463,9 +551,69 @@ type serverImpl struct {
 	// events provides the event system for server lifecycle events
 	events *events.Subject
 
-	// needsRootFetch indicates whether we should fetch workspace roots from the client
-	// after initialization is complete (similar to how we queue capability notifications)
-	needsRootFetch bool
+	// usage aggregates per-tool usage statistics when analytics are enabled (see WithUsageAnalytics)
+	usage *usageTracker
+
+	// adaptiveTimeouts derives per-tool deadlines from observed latencies (see WithAdaptiveTimeouts)
+	adaptiveTimeouts *adaptiveTimeouts
+
+	// riskPolicy decides what is done with calls to tools by risk level (see WithRiskPolicy)
+	riskPolicy RiskPolicy
+
+	// approver approves the tool calls riskPolicy requires approval of (see WithApprover)
+	approver Approver
+
+	// visibilityFilter decides which tools, resources and prompts each session sees (see WithVisibilityFilter)
+	visibilityFilter VisibilityFilter
+
+	// errorReports aggregates repeated tool errors (see WithErrorReportWindow)
+	errorReports errorReporter
+
+	// batchConcurrency bounds how many messages of a batch are processed at once (see WithBatchConcurrency)
+	batchConcurrency int
+
+	// featuresMu protects features
+	featuresMu sync.RWMutex
+
+	// features holds the feature flags set on the server (see WithFeature)
+	features map[string]bool
+
+	// requestPool limits how many requests are handled at once (see WithMaxConcurrentRequests)
+	requestPool *workerPool
+
+	// reconciler re-sends list_changed when clients' views drift (see WithCapabilityReconciliation)
+	reconciler *capabilityReconciler
+
+	// changesMu protects changeBatches and batchedChanges
+	changesMu sync.Mutex
+
+	// changeBatches counts the BatchChanges calls in progress
+	changeBatches int
+
+	// batchedChanges holds the lists changed while a batch is in progress
+	batchedChanges map[string]bool
+
+	// cleaner periodically reclaims expired state (see WithCleanup)
+	cleaner *cleaner
+
+	// drainTimeout bounds how long shutdown waits for in-flight requests (see WithDrainTimeout)
+	drainTimeout time.Duration
+
+	// lifecycleMu protects draining and orders it with inFlight.Add
+	lifecycleMu sync.Mutex
+
+	// draining is set once shutdown begins; new requests are rejected from then on
+	draining bool
+
+	// inFlight counts requests whose handlers are still running
+	inFlight sync.WaitGroup
+
+	// done is closed when shutdown completes, releasing Run and Serve
+	done chan struct{}
+
+	// shutdownOnce ensures the shutdown pipeline runs once; shutdownErr holds its result
+	shutdownOnce sync.Once
+	shutdownErr  error
 }
 
 // CapabilityCache manages the caching and change tracking of server capabilities
@@ -516,6 +664,33 @@ func (c *CapabilityCache) MarkPromptsChanged() {
 	c.hasPrompts = true
 }
 
+// MarkToolsRemoved marks that tools have changed because one was removed;
+// remaining reports whether any tools are still registered
+func (c *CapabilityCache) MarkToolsRemoved(remaining bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.toolsChanged = true
+	c.hasTools = remaining
+}
+
+// MarkResourcesRemoved marks that resources have changed because one was removed;
+// remaining reports whether any resources are still registered
+func (c *CapabilityCache) MarkResourcesRemoved(remaining bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resourcesChanged = true
+	c.hasResources = remaining
+}
+
+// MarkPromptsRemoved marks that prompts have changed because one was removed;
+// remaining reports whether any prompts are still registered
+func (c *CapabilityCache) MarkPromptsRemoved(remaining bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.promptsChanged = true
+	c.hasPrompts = remaining
+}
+
 // QueueNotification adds a notification to be sent after client initialization
 func (c *CapabilityCache) QueueNotification(notification []byte) {
 	c.mu.Lock()
@@ -588,12 +763,11 @@ func (s *serverImpl) GetTransport() transport.Transport {
 //
 // Example:
 //
-//	config := server.NewSamplingConfig().
-//	    WithRateLimit(10).
-//	    WithCacheSize(100)
-//	server.WithSamplingConfig(config)
+//	config := server.NewDefaultSamplingConfig().
+//	    WithCache(nil, 10*time.Minute)
+//	srv.GetServer().WithSamplingConfig(config)
 func (s *serverImpl) WithSamplingConfig(config *SamplingConfig) Server {
-	s.samplingConfig = config
+	WithSamplingConfig(config)(s)
 	return s
 }
 
@@ -635,6 +809,10 @@ func NewServer(name string, options ...Option) Server {
 		tools:                make(map[string]*Tool),
 		resources:            make(map[string]*Resource),
 		prompts:              make(map[string]*Prompt),
+		completions:          make(map[completionKey]CompletionHandler),
+		notificationHandlers: make(map[string]NotificationHandler),
+		unknownMethods:       make(map[string]int64),
+		logLevels:            make(map[SessionID]mcp.LogLevel),
 		roots:                []string{},
 		logger:               slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})),
 		versionDetector:      mcp.NewVersionDetector(),
@@ -643,6 +821,10 @@ func NewServer(name string, options ...Option) Server {
 		capabilityCache:      NewCapabilityCache(),
 		requestCanceller:     NewRequestCanceller(),
 		progressTokenManager: mcp.NewProgressTokenManager(),
+		drainTimeout:         DefaultDrainTimeout,
+		errorReports:         errorReporter{window: DefaultErrorReportWindow},
+		cleaner:              newCleaner(CleanupConfig{}),
+		done:                 make(chan struct{}),
 	}
 
 	// Initialize progress notification handler
@@ -690,6 +872,11 @@ func NewServer(name string, options ...Option) Server {
 			return nil
 		})
 
+	// Expose usage analytics once the event system exists, as registration publishes events
+	if s.usage != nil {
+		s.registerUsageResource()
+	}
+
 	return s
 }
 
@@ -728,6 +915,7 @@ func WithProtocolVersion(version string) Option {
 		s.protocolVersion = version
 		// Update the default session to use this protocol version
 		if s.defaultSession != nil {
+			s.defaultSession.ProtocolVersion = version
 			s.defaultSession.ClientInfo.ProtocolVersion = version
 		}
 	}
@@ -774,11 +962,10 @@ func (s *serverImpl) ProcessInitialize(ctx *Context) (interface{}, error) {
 		return nil, err
 	}
 
-	// Store the validated protocol version without locking
-	s.protocolVersion = protocolVersion
-
-	// Update the transport with the negotiated protocol version
-	if s.transport != nil {
+	// The negotiated version is stored on the client's session. Transports serving
+	// several sessions, such as HTTP, keep no version of their own
+	transportSessionID, isTransportSession := transportSessionFromContext(ctx.ctx)
+	if !isTransportSession && s.transport != nil {
 		s.transport.SetProtocolVersion(protocolVersion)
 	}
 
@@ -819,39 +1006,50 @@ func (s *serverImpl) ProcessInitialize(ctx *Context) (interface{}, error) {
 		}
 	}
 
-	// Add initial roots to server roots
-	if len(initialRoots) > 0 {
+	// Add initial roots to server roots, unless the transport serves several
+	// sessions, whose roots are kept on each session only
+	if len(initialRoots) > 0 && !isTransportSession {
 		s.Root(initialRoots...)
 	}
 
-	// Check if client supports roots capability and mark for fetching via roots/list
-	if clientSupportsRoots(ctx.Request.Params) {
-		s.needsRootFetch = true
-	}
-
-	// Determine sampling capabilities based on protocol version
+	// Determine sampling capabilities based on protocol version. Clients that do
+	// not declare sampling cannot answer sampling/createMessage requests
 	samplingCaps := DetectClientCapabilities(protocolVersion)
+	samplingCaps.Supported = samplingCaps.Supported && clientSupportsSampling(ctx.Request.Params)
 
 	// Update or create client info with session data (include initial roots and will be updated by roots/list)
 	clientInfo := ClientInfo{
-		SamplingSupported: samplingCaps.Supported,
-		SamplingCaps:      samplingCaps,
-		ProtocolVersion:   protocolVersion,
-		Env:               clientEnv,
-		Roots:             initialRoots, // Include initial roots from clientInfo
+		SamplingSupported:    samplingCaps.Supported,
+		SamplingCaps:         samplingCaps,
+		ProtocolVersion:      protocolVersion,
+		Env:                  clientEnv,
+		Roots:                initialRoots, // Include initial roots from clientInfo
+		RootsSupported:       clientSupportsRoots(ctx.Request.Params),
+		ElicitationSupported: clientDeclaresCapability(ctx.Request.Params, "elicitation"),
 	}
 
-	// Create a new session for this client
-	session := s.sessionManager.CreateSession(clientInfo, protocolVersion)
+	// Create a new session for this client, keyed by the transport's session ID if
+	// there is one so that the client's later requests are matched to it
+	var session *ClientSession
+	if isTransportSession {
+		session = s.sessionManager.CreateSessionWithID(transportSessionID, clientInfo, protocolVersion)
+	} else {
+		session = s.sessionManager.CreateSession(clientInfo, protocolVersion)
+	}
 
 	// Store the session ID in the context metadata
 	if ctx.Metadata == nil {
 		ctx.Metadata = make(map[string]interface{})
 	}
 	ctx.Metadata["sessionID"] = string(session.ID)
+	ctx.Session = session
 
-	// For simple implementations that don't track multiple sessions, update the default session without locking
-	s.defaultSession = session
+	// Transports with a single client make it the default session. Sessions of
+	// transports serving several clients are found by their ID instead, so that
+	// one client's initialize does not take over the others' state
+	if !isTransportSession {
+		s.defaultSession = session
+	}
 
 	// Log the session creation
 	s.logger.Info("client connected",
@@ -860,40 +1058,12 @@ func (s *serverImpl) ProcessInitialize(ctx *Context) (interface{}, error) {
 		"samplingSupported", samplingCaps.Supported,
 		"audioSupport", samplingCaps.AudioSupport)
 
+	// Warn about clients that negotiated an older version than the server prefers
+	s.reportVersionDowngrade(session, clientProtocolVersion, ctx.Request.Params)
+
 	// Build server capabilities according to MCP specification
 	// Only declare capability flags, not actual data
-	capabilities := map[string]interface{}{
-		"logging": map[string]interface{}{},
-	}
-
-	// Check capabilities with proper mutex protection
-	s.mu.RLock()
-	hasPrompts := len(s.prompts) > 0
-	hasResources := len(s.resources) > 0
-	hasTools := len(s.tools) > 0
-	s.mu.RUnlock()
-
-	// Add prompts capability if we have any registered
-	if hasPrompts {
-		capabilities["prompts"] = map[string]interface{}{
-			"listChanged": true,
-		}
-	}
-
-	// Add resources capability if we have any registered
-	if hasResources {
-		capabilities["resources"] = map[string]interface{}{
-			"subscribe":   true,
-			"listChanged": true,
-		}
-	}
-
-	// Add tools capability if we have any registered
-	if hasTools {
-		capabilities["tools"] = map[string]interface{}{
-			"listChanged": true,
-		}
-	}
+	capabilities := s.serverCapabilities()
 
 	// Emit client connected event
 	go func() {
@@ -905,7 +1075,7 @@ func (s *serverImpl) ProcessInitialize(ctx *Context) (interface{}, error) {
 				Name:    "Unknown Client",
 				Version: "Unknown",
 			},
-			Capabilities: capabilities,
+			Capabilities: capabilitiesMap(capabilities),
 		})
 	}()
 
@@ -926,45 +1096,113 @@ func (s *serverImpl) ProcessInitialize(ctx *Context) (interface{}, error) {
 	return response, nil
 }
 
+// serverCapabilities returns the capabilities the server declares in its
+// initialize response, based on what has been registered.
+func (s *serverImpl) serverCapabilities() mcp.ServerCapabilities {
+	capabilities := mcp.ServerCapabilities{
+		Logging: &mcp.LoggingCapability{},
+	}
+
+	// Check capabilities with proper mutex protection
+	s.mu.RLock()
+	hasPrompts := len(s.prompts) > 0
+	hasResources := len(s.resources) > 0
+	hasTools := len(s.tools) > 0
+	s.mu.RUnlock()
+
+	if hasPrompts {
+		capabilities.Prompts = &mcp.PromptsCapability{ListChanged: true}
+	}
+	if hasResources {
+		capabilities.Resources = &mcp.ResourcesCapability{Subscribe: true, ListChanged: true}
+	}
+	if hasTools {
+		capabilities.Tools = &mcp.ToolsCapability{ListChanged: true}
+	}
+
+	// Declare completions if any argument has a completion provider
+	if s.hasCompletions() {
+		capabilities.Completions = &mcp.CompletionsCapability{}
+	}
+
+	// Report the feature flags the server runs with and advertise custom methods
+	experimental := map[string]interface{}{}
+	if flags := s.featureFlags(); flags != nil {
+		experimental[FeaturesCapability] = flags
+	}
+	for _, method := range s.customMethods() {
+		experimental[method] = map[string]interface{}{}
+	}
+	if len(experimental) > 0 {
+		capabilities.Experimental = experimental
+	}
+
+	return capabilities
+}
+
+// capabilitiesMap converts capabilities to the generic form events carry them in
+func capabilitiesMap(capabilities mcp.ServerCapabilities) map[string]interface{} {
+	var m map[string]interface{}
+	if data, err := json.Marshal(capabilities); err == nil {
+		_ = json.Unmarshal(data, &m)
+	}
+	return m
+}
+
 // ProcessShutdown processes a shutdown request.
 //
-// This method handles graceful shutdown requests from clients. It returns a success
-// response to the client and initiates server shutdown.
+// A shutdown request means that the client which sent it is leaving. On a
+// transport that serves a single client, such as stdio or the embedded transport,
+// the server has no one left to serve and shuts down gracefully once the response
+// has been sent. On transports shared by several clients, only the session of the
+// client that sent the request is closed, and the server keeps serving the others;
+// stopping it is left to the host program.
 //
 // The ctx parameter contains the shutdown request. The method returns a simple
-// response indicating whether the shutdown was initiated successfully.
+// response acknowledging the request.
 func (s *serverImpl) ProcessShutdown(ctx *Context) (interface{}, error) {
-	// Emit server shutdown event
-	go func() {
-		events.Publish[events.ServerShutdownEvent](s.events, events.TopicServerShutdown, events.ServerShutdownEvent{
-			ServerName:   s.name,
-			ShutdownAt:   time.Now(),
-			GracefulExit: true,
-			Reason:       "client_requested",
-		})
-	}()
+	if sessionID, ok := transportSessionFromContext(ctx.Context()); ok {
+		s.logger.Info("client left, closing its session", "session", sessionID)
+		s.closeSession(sessionID)
+		return NewShutdownResponse(true), nil
+	}
+	if !s.servesSingleClient() {
+		s.logger.Info("client left, the server keeps serving other clients")
+		return NewShutdownResponse(true), nil
+	}
 
-	// TODO: Implement proper shutdown handling
 	go func() {
-		s.logger.Info("shutdown requested, will exit soon")
-
-		// Clean up events system
-		if s.events != nil {
-			events.Complete(s.events)
-		}
+		s.logger.Info("shutdown requested by client, will exit soon")
 
 		// Give time for the response to be sent before actually shutting down
 		time.Sleep(100 * time.Millisecond)
-		// TODO: Implement clean shutdown
+		if err := s.shutdown(context.Background(), shutdownReasonClientRequested); err != nil {
+			s.logger.Error("error during client requested shutdown", "error", err)
+		}
 	}()
 	return NewShutdownResponse(true), nil
 }
 
+// servesSingleClient reports whether the server's transport connects exactly one
+// client, whose shutdown request leaves the server with no one to serve
+func (s *serverImpl) servesSingleClient() bool {
+	s.mu.RLock()
+	t := s.transport
+	s.mu.RUnlock()
+
+	switch t.(type) {
+	case *stdio.Transport, *embedded.Transport:
+		return true
+	}
+	return false
+}
+
 // Run starts the server and blocks until it exits.
 //
 // This method initializes the server's transport, sets up message handling,
-// and begins processing client requests. It blocks until an error occurs or
-// the server is explicitly stopped.
+// and begins processing client requests. It blocks until the server is shut
+// down, either by Shutdown or by a client's shutdown request. Use Serve to
+// tie the server's lifetime to a context instead.
 //
 // Run returns an error if the server fails to start or encounters a fatal error
 // during operation. Common error scenarios include transport initialization failure
@@ -982,6 +1220,11 @@ func (s *serverImpl) ProcessShutdown(ctx *Context) (interface{}, error) {
 //	    log.Fatalf("Server error: %v", err)
 //	}
 func (s *serverImpl) Run() error {
+	return s.Serve(context.Background())
+}
+
+// start initializes and starts the transport with the server's message handler
+func (s *serverImpl) start() error {
 	s.mu.RLock()
 	t := s.transport
 	s.mu.RUnlock()
@@ -992,7 +1235,7 @@ func (s *serverImpl) Run() error {
 
 	// Initialize the request tracker
 	s.mu.Lock()
-	s.requestTracker = newRequestTracker()
+	s.requestTracker = newRequestTracker(s.requestSessions)
 	s.mu.Unlock()
 
 	// Set up transport debug logging
@@ -1003,52 +1246,84 @@ func (s *serverImpl) Run() error {
 	// Set the message handler using the non-exported handleMessage method
 	t.SetMessageHandler(s.handleMessage)
 
+	// Transports serving several sessions report which session each message belongs to
+	if st, ok := t.(transport.SessionTransport); ok {
+		st.SetSessionMessageHandler(s.handleSessionMessage)
+	}
+
+	// Transports ending sessions on their own report them, so their state is dropped
+	if sr, ok := t.(transport.SessionEndReporter); ok {
+		sr.SetSessionEndHandler(func(sessionID string) {
+			s.closeSession(SessionID(sessionID))
+		})
+	}
+
+	// Transports enforcing an access policy report the clients they reject
+	if vr, ok := t.(transport.ViolationReporter); ok {
+		vr.SetViolationHandler(s.reportAccessViolation)
+	}
+
+	// Transports serving deprecated endpoints report the clients still using them
+	if lr, ok := t.(transport.LegacyReporter); ok {
+		lr.SetLegacyClientHandler(s.reportLegacyClient)
+	}
+
 	// Initialize the transport
 	if err := t.Initialize(); err != nil {
 		return fmt.Errorf("failed to initialize transport: %w", err)
 	}
 
+	// Transports gate their risky behaviours on the server's feature flags
+	s.applyFeatures(t)
+
+	// Transports reading requests from a stream stop reading while the request pool is full
+	if ld, ok := t.(transport.LimitedDispatcher); ok && s.requestPool.enabled() {
+		ld.SetRequestLimiter(requestLimiter{s})
+	}
+
 	// Start the transport
 	if err := t.Start(); err != nil {
 		return fmt.Errorf("failed to start transport: %w", err)
 	}
 
-	s.logger.Info("server started", "name", s.name, "transport", fmt.Sprintf("%T", t))
+	if s.cleaner != nil {
+		s.cleaner.start(s)
+	}
 
-	// Block until the transport is done
-	// TODO: Implement proper shutdown handling
-	select {}
+	s.logger.Info("server started", "name", s.name, "transport", fmt.Sprintf("%T", t))
+	return nil
 }
 
-// Shutdown gracefully shuts down the server
-func (s *serverImpl) Shutdown() error {
-	s.logger.Info("shutting down server", "name", s.name)
-
-	// Stop the underlying transport
-	if s.transport != nil {
-		if err := s.transport.Stop(); err != nil {
-			s.logger.Error("error stopping transport", "error", err)
-			return err
-		}
-	}
+// reportAccessViolation logs a client the transport rejected and publishes it as
+// an event
+func (s *serverImpl) reportAccessViolation(violation transport.Violation) {
+	s.logger.Debug("transport rejected client",
+		"kind", violation.Kind,
+		"remoteIP", violation.RemoteIP,
+		"path", violation.Path)
 
-	// Clean up events system
-	if s.events != nil {
-		events.Complete(s.events)
+	if s.events == nil {
+		return
 	}
-
-	s.logger.Info("server shutdown complete", "name", s.name)
-	return nil
+	go events.Publish[events.AccessViolationEvent](s.events, events.TopicAccessViolation, events.AccessViolationEvent{
+		Kind:       string(violation.Kind),
+		RemoteIP:   violation.RemoteIP,
+		Method:     violation.Method,
+		Path:       violation.Path,
+		OccurredAt: violation.OccurredAt,
+	})
 }
 
-// GetServer returns the underlying server implementation
-// This is primarily for internal use and testing.
+// GetServer returns the underlying server implementation, which has the methods
+// of features beyond the Server interface.
 func (s *serverImpl) GetServer() *serverImpl {
 	return s
 }
 
 // SetTransport sets the transport for the server (primarily for testing)
 func (s *serverImpl) SetTransport(t transport.Transport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.transport = t
 }
 
@@ -1063,8 +1338,16 @@ func (s *serverImpl) SetTransport(t transport.Transport) {
 //
 // If the notification cannot be sent, an error is logged but not returned to the caller.
 func (s *serverImpl) sendNotification(method string, params interface{}) {
+	if err := s.writeNotification(method, params); err != nil {
+		s.logger.Error("failed to send notification", "method", method, "error", err)
+	}
+}
+
+// writeNotification sends a notification message to the client and returns any
+// error, for callers that report failures to their own callers.
+func (s *serverImpl) writeNotification(method string, params interface{}) error {
 	if s.transport == nil {
-		return
+		return nil
 	}
 
 	// Create notification using structured type
@@ -1073,19 +1356,38 @@ func (s *serverImpl) sendNotification(method string, params interface{}) {
 	// Convert to JSON
 	message, err := notification.Marshal()
 	if err != nil {
-		s.logger.Error("failed to marshal notification", "error", err)
-		return
+		return fmt.Errorf("failed to marshal notification: %w", err)
 	}
 
 	// Send the notification
-	if err := s.transport.Send(message); err != nil {
-		s.logger.Error("failed to send notification", "error", err)
+	return s.transport.Send(message)
+}
+
+// writeSessionNotification sends a notification message to the client of a session
+// only, so that clients sharing a transport do not see each other's notifications.
+// Notifications for the default session, or a nil session, are sent with Send.
+func (s *serverImpl) writeSessionNotification(session *ClientSession, method string, params interface{}) error {
+	s.mu.RLock()
+	isDefault := session == nil || (s.defaultSession != nil && session.ID == s.defaultSession.ID)
+	s.mu.RUnlock()
+	if isDefault {
+		return s.writeNotification(method, params)
+	}
+	if s.transport == nil {
+		return nil
+	}
+
+	message, err := mcp.NewNotification(method, params).Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
 	}
+	return s.sendToSession(session.ID, message)
 }
 
 // handleInitializedNotification processes the initialized notification from the client
-// and sends any pending notifications that were queued during the initialization phase.
-func (s *serverImpl) handleInitializedNotification() {
+// of a session and sends any pending notifications that were queued during the
+// initialization phase.
+func (s *serverImpl) handleInitializedNotification(session *ClientSession) {
 	// Set initialized with proper mutex protection
 	s.mu.Lock()
 	s.initialized = true
@@ -1094,6 +1396,10 @@ func (s *serverImpl) handleInitializedNotification() {
 	pendingNotifications := s.capabilityCache.GetPendingNotifications()
 	s.capabilityCache.ResetChangeFlags()
 
+	if s.reconciler != nil {
+		s.reconciler.start(s)
+	}
+
 	s.logger.Debug("client initialized, processing pending notifications",
 		"count", len(pendingNotifications))
 
@@ -1103,7 +1409,7 @@ func (s *serverImpl) handleInitializedNotification() {
 	resourceCount := len(s.resources)
 	promptCount := len(s.prompts)
 	serverName := s.name
-	protocolVersion := s.protocolVersion
+	protocolVersion := s.sessionProtocolVersion(session)
 	eventSubject := s.events
 	logger := s.logger
 	s.mu.RUnlock()
@@ -1139,13 +1445,13 @@ func (s *serverImpl) handleInitializedNotification() {
 		}
 	}
 
-	// Fetch workspace roots if needed (for non-stdio transports)
+	// Fetch workspace roots if the session's client supports roots/list
 	// Only fetch roots, don't send initial capability notifications
 	// Capability notifications should only be sent when capabilities actually change
-	if s.needsRootFetch {
+	if session != nil && session.ClientInfo.RootsSupported {
 		go func() {
 			time.Sleep(50 * time.Millisecond) // Small delay for client readiness
-			s.fetchWorkspaceRoots()
+			s.fetchWorkspaceRoots(session)
 		}()
 	}
 }
@@ -1252,7 +1558,7 @@ func (s *serverImpl) ListResources() ([]mcp.Resource, error) {
 			Name:        resourceInfo.Name,
 			Description: resourceInfo.Description,
 			MimeType:    resourceInfo.MimeType,
-			Annotations: nil, // ResourceInfo doesn't have annotations
+			Annotations: resourceInfo.Annotations,
 		}
 
 		resources = append(resources, resource)
@@ -1290,7 +1596,7 @@ func (s *serverImpl) ListPrompts() ([]mcp.Prompt, error) {
 		prompt := mcp.Prompt{
 			Name:        promptInfo.Name,
 			Description: promptInfo.Description,
-			Annotations: nil, // PromptInfo doesn't have annotations
+			Annotations: promptInfo.Annotations,
 		}
 
 		// Convert arguments from PromptArgument to mcp.PromptArgument
@@ -1351,6 +1657,10 @@ func getMapFromInterface(val interface{}) map[string]interface{} {
 // sendCapabilityNotification sends a single notification for a capability that changed
 // This is much simpler than complex debouncing and follows the be-very-stingy-with-locks rule
 func (s *serverImpl) sendCapabilityNotification(capabilityType string) {
+	if s.deferChange(capabilityType) {
+		return
+	}
+
 	// Send the appropriate notification without holding any locks
 	// The individual notification methods handle initialization state and queuing
 	go func() {
@@ -1434,59 +1744,82 @@ func clientSupportsRoots(params interface{}) bool {
 	}
 
 	// Handle both parsed maps and JSON byte slices
-	var paramsMap map[string]interface{}
-
+	var data []byte
 	switch p := params.(type) {
 	case map[string]interface{}:
-		paramsMap = p
-	case json.RawMessage:
-		if err := json.Unmarshal(p, &paramsMap); err != nil {
+		var err error
+		if data, err = json.Marshal(p); err != nil {
 			return false
 		}
+	case json.RawMessage:
+		data = p
 	case []byte:
-		if err := json.Unmarshal(p, &paramsMap); err != nil {
-			return false
-		}
+		data = p
 	default:
 		return false
 	}
 
-	// Look for capabilities.roots in the client initialization
-	capabilities, ok := paramsMap["capabilities"].(map[string]interface{})
-	if !ok {
-		return false
+	// Look for capabilities.roots in the client initialization; the client
+	// supports roots/list if it declares listChanged. Only roots is decoded, so
+	// malformed capabilities the server does not use are tolerated
+	var initParams struct {
+		Capabilities struct {
+			Roots mcp.RootsCapability `json:"roots"`
+		} `json:"capabilities"`
 	}
-
-	roots, ok := capabilities["roots"].(map[string]interface{})
-	if !ok {
+	if err := json.Unmarshal(data, &initParams); err != nil {
 		return false
 	}
+	return initParams.Capabilities.Roots.ListChanged
+}
 
-	// Check if the client supports roots/list (listChanged capability)
-	if listChanged, ok := roots["listChanged"].(bool); ok && listChanged {
-		return true
-	}
+// clientSupportsSampling reports whether a client declared the sampling capability
+// in its initialize parameters
+func clientSupportsSampling(params json.RawMessage) bool {
+	return clientDeclaresCapability(params, "sampling")
+}
 
-	return false
+// clientDeclaresCapability reports whether a client declared a capability in its
+// initialize parameters
+func clientDeclaresCapability(params json.RawMessage, name string) bool {
+	var initParams struct {
+		Capabilities map[string]json.RawMessage `json:"capabilities"`
+	}
+	if err := json.Unmarshal(params, &initParams); err != nil {
+		return false
+	}
+	capability := initParams.Capabilities[name]
+	return len(capability) > 0 && string(capability) != "null" && string(capability) != "false"
 }
 
-// fetchWorkspaceRoots sends a roots/list request to the client to get workspace roots
+// fetchWorkspaceRoots sends a roots/list request to the client of a session to get
+// its workspace roots, or to the client of the default session if session is nil.
 // This follows the MCP protocol where roots/list is a client capability
-func (s *serverImpl) fetchWorkspaceRoots() {
+func (s *serverImpl) fetchWorkspaceRoots(session *ClientSession) {
 	if s.transport == nil {
 		s.logger.Debug("no transport available for roots/list request")
 		return
 	}
 
+	if session == nil {
+		s.mu.RLock()
+		session = s.defaultSession
+		s.mu.RUnlock()
+	}
+	var sessionID SessionID
+	if session != nil {
+		sessionID = session.ID
+	}
+
 	// Generate a unique request ID
-	requestID := int(time.Now().UnixNano())
+	requestID := int(s.generateRequestID())
 
 	// Track the request for response handling
 	if s.requestTracker != nil {
-		responseChan := s.requestTracker.addRequest(requestID)
+		responseChan := s.requestTracker.addRequest(requestID, sessionID)
 
 		// Handle the response in a goroutine
-		go s.handleRootsListResponse(requestID, responseChan)
+		go s.handleRootsListResponse(requestID, session, responseChan)
 	}
 
 	// Create the roots/list request
@@ -1503,8 +1836,8 @@ func (s *serverImpl) fetchWorkspaceRoots() {
 		return
 	}
 
-	// Send the request
-	if err := s.transport.Send(requestBytes); err != nil {
+	// Send the request to the session's client only
+	if err := s.sendToSession(sessionID, requestBytes); err != nil {
 		s.logger.Error("failed to send roots/list request", "error", err)
 		if s.requestTracker != nil {
 			s.requestTracker.removeRequest(requestID)
@@ -1516,8 +1849,8 @@ func (s *serverImpl) fetchWorkspaceRoots() {
 }
 
 // handleRootsListResponse processes the response to a roots/list request
-// and updates the default session with the workspace roots
-func (s *serverImpl) handleRootsListResponse(requestID int, responseChan chan json.RawMessage) {
+// and updates the session the request was sent to with the workspace roots
+func (s *serverImpl) handleRootsListResponse(requestID int, session *ClientSession, responseChan chan json.RawMessage) {
 	// Wait for the response with a timeout
 	timeout := 10 * time.Second
 	timer := time.NewTimer(timeout)
@@ -1562,10 +1895,14 @@ func (s *serverImpl) handleRootsListResponse(requestID int, responseChan chan js
 			rootPaths = append(rootPaths, path)
 		}
 
-		// Update the default session with the workspace roots
-		if s.defaultSession != nil {
-			s.defaultSession.ClientInfo.Roots = rootPaths
+		// Update the session with the workspace roots, writing it back so that
+		// external session stores see the change
+		if session != nil {
+			s.sessionManager.UpdateSession(session.ID, func(cs *ClientSession) {
+				cs.ClientInfo.Roots = rootPaths
+			})
 			s.logger.Debug("updated session with workspace roots",
+				"sessionID", string(session.ID),
 				"count", len(rootPaths),
 				"roots", rootPaths)
 		}
@@ -1,6 +1,9 @@
 package server
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"sync"
 	"time"
 
@@ -55,10 +58,11 @@ func (s *ClientSession) Capabilities() SamplingCapabilities {
 // SessionManager manages client sessions.
 // It provides methods for creating, retrieving, updating, and closing
 // client sessions, ensuring proper lifecycle management and thread safety.
+// Sessions are kept in a SessionStore, in memory by default.
 type SessionManager struct {
-	mu       sync.RWMutex
-	sessions map[SessionID]*ClientSession
-	nextID   int64
+	mu     sync.RWMutex
+	store  SessionStore
+	nextID int64
 }
 
 // NewSessionManager creates a new session manager.
@@ -67,11 +71,27 @@ type SessionManager struct {
 // Returns:
 //   - A new SessionManager instance ready for use
 func NewSessionManager() *SessionManager {
+	return NewSessionManagerWithStore(NewMemorySessionStore(0))
+}
+
+// NewSessionManagerWithStore creates a session manager backed by the given store.
+//
+// Parameters:
+//   - store: The storage backend for sessions
+//
+// Returns:
+//   - A new SessionManager instance using the store
+func NewSessionManagerWithStore(store SessionStore) *SessionManager {
 	return &SessionManager{
-		sessions: make(map[SessionID]*ClientSession),
+		store: store,
 	}
 }
 
+// Store returns the storage backend used by the session manager.
+func (sm *SessionManager) Store() SessionStore {
+	return sm.store
+}
+
 // CreateSession creates a new client session.
 // This method generates a unique session ID, initializes a new session with
 // the provided client information, and adds it to the session manager.
@@ -84,11 +104,28 @@ func NewSessionManager() *SessionManager {
 //   - A new ClientSession instance configured for the client
 func (sm *SessionManager) CreateSession(clientInfo ClientInfo, protocolVersion string) *ClientSession {
 	sm.mu.Lock()
-	defer sm.mu.Unlock()
-
 	// Generate a new session ID
 	sm.nextID++
 	sessionID := SessionID(generateUniqueID(sm.nextID))
+	sm.mu.Unlock()
+
+	return sm.CreateSessionWithID(sessionID, clientInfo, protocolVersion)
+}
+
+// CreateSessionWithID creates a new client session with the given ID, replacing
+// any existing session with that ID. It is used for sessions whose ID is assigned
+// by the transport, such as HTTP sessions identified by Mcp-Session-Id.
+//
+// Parameters:
+//   - sessionID: The identifier for the new session
+//   - clientInfo: Information about the client's capabilities and features
+//   - protocolVersion: The negotiated MCP protocol version for this client
+//
+// Returns:
+//   - A new ClientSession instance configured for the client
+func (sm *SessionManager) CreateSessionWithID(sessionID SessionID, clientInfo ClientInfo, protocolVersion string) *ClientSession {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 
 	// Create a new session
 	session := &ClientSession{
@@ -100,8 +137,8 @@ func (sm *SessionManager) CreateSession(clientInfo ClientInfo, protocolVersion s
 		Metadata:        make(map[string]string),
 	}
 
-	// Store the session
-	sm.sessions[sessionID] = session
+	// Store the session; a failing backend still leaves the caller with a usable session
+	_ = sm.store.Put(session)
 
 	return session
 }
@@ -119,10 +156,25 @@ func (sm *SessionManager) GetSession(id SessionID) (*ClientSession, bool) {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
-	session, exists := sm.sessions[id]
+	session, exists, err := sm.store.Get(id)
+	if err != nil {
+		return nil, false
+	}
 	return session, exists
 }
 
+// ListSessions returns all active sessions.
+//
+// Returns:
+//   - The sessions currently held by the store
+//   - An error if the store could not be read
+func (sm *SessionManager) ListSessions() ([]*ClientSession, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	return sm.store.List()
+}
+
 // UpdateSession updates an existing session.
 // This method applies custom updates to a session while maintaining thread safety,
 // and automatically updates the session's last active timestamp.
@@ -137,8 +189,8 @@ func (sm *SessionManager) UpdateSession(id SessionID, update func(*ClientSession
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	session, exists := sm.sessions[id]
-	if !exists {
+	session, exists, err := sm.store.Get(id)
+	if err != nil || !exists {
 		return false
 	}
 
@@ -148,7 +200,20 @@ func (sm *SessionManager) UpdateSession(id SessionID, update func(*ClientSession
 	// Update the last active time
 	session.LastActive = time.Now()
 
-	return true
+	// Write back so external stores see the change
+	return sm.store.Put(session) == nil
+}
+
+// snapshot returns a copy of a session taken under the manager's lock, which
+// UpdateSession holds while it changes sessions
+func (sm *SessionManager) snapshot(session *ClientSession) *ClientSession {
+	if session == nil {
+		return nil
+	}
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	copied := *session
+	return &copied
 }
 
 // CloseSession removes a session.
@@ -166,12 +231,14 @@ func (sm *SessionManager) CloseSession(id SessionID, eventSystem *events.Subject
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	session, exists := sm.sessions[id]
-	if !exists {
+	session, exists, err := sm.store.Get(id)
+	if err != nil || !exists {
 		return nil, false
 	}
 
-	delete(sm.sessions, id)
+	if err := sm.store.Delete(id); err != nil {
+		return nil, false
+	}
 
 	// Emit client disconnected event if event system is provided
 	if eventSystem != nil && session != nil {
@@ -241,8 +308,8 @@ func (sm *SessionManager) UpdateClientCapabilities(id SessionID, caps SamplingCa
 }
 
 // generateUniqueID creates a unique session identifier.
-// This is a simplified implementation that combines the current timestamp
-// with a sequence number to create reasonably unique identifiers.
+// It combines random bytes with a sequence number so identifiers stay unique
+// across server replicas that share a SessionStore.
 //
 // Parameters:
 //   - id: A sequence number to incorporate into the ID
@@ -250,7 +317,22 @@ func (sm *SessionManager) UpdateClientCapabilities(id SessionID, caps SamplingCa
 // Returns:
 //   - A string containing the unique session identifier
 func generateUniqueID(id int64) string {
-	// In a real implementation, this would generate a secure random ID
-	// For this example, we'll just use a simple string representation
-	return time.Now().Format("20060102150405") + "-" + time.Now().Format("000000000") + "-" + time.Now().Format("000000000000000")
+	var random [12]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		// Fall back to a time-based identifier if the system RNG is unavailable
+		return fmt.Sprintf("%s-%d", time.Now().Format("20060102150405.000000000"), id)
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(random[:]), id)
+}
+
+// closeSession removes a session along with the per-session state the server keeps
+// for it, such as its log level
+func (s *serverImpl) closeSession(id SessionID) bool {
+	_, ok := s.sessionManager.CloseSession(id, s.events)
+
+	s.logLevelMu.Lock()
+	delete(s.logLevels, id)
+	s.logLevelMu.Unlock()
+
+	return ok
 }
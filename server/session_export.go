@@ -0,0 +1,176 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/localrivet/gomcp/transport"
+)
+
+// SessionStateVersion is the version of the SessionState format. Import refuses
+// snapshots of other versions.
+const SessionStateVersion = 1
+
+// SessionState is a serializable snapshot of a client session, produced by Export
+// and restored by Import so that another server, such as the new deployment of a
+// blue/green pair or another replica behind a load balancer, can take the session
+// over without the client initializing again. It marshals with encoding/json.
+type SessionState struct {
+	// Version is the version of the snapshot format, SessionStateVersion
+	Version int `json:"version"`
+
+	// ID identifies the session, such as its Mcp-Session-Id
+	ID SessionID `json:"id"`
+
+	// ProtocolVersion is the protocol version negotiated with the client
+	ProtocolVersion string `json:"protocolVersion"`
+
+	// ClientInfo holds the client's capabilities, roots and environment
+	ClientInfo ClientInfo `json:"clientInfo"`
+
+	// Created is when the session was created
+	Created time.Time `json:"created"`
+
+	// Metadata holds the session metadata, such as the grants an application
+	// keeps per session
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Subscriptions lists the resource URIs the client is subscribed to
+	Subscriptions []string `json:"subscriptions,omitempty"`
+
+	// LogLevel is the minimum level of the log messages the client asked for with
+	// logging/setLevel, if it did. Only the server's ExportSession fills it in.
+	LogLevel mcp.LogLevel `json:"logLevel,omitempty"`
+
+	// ExportedAt is when the snapshot was taken
+	ExportedAt time.Time `json:"exportedAt"`
+}
+
+// Export returns a snapshot of a session.
+//
+// Parameters:
+//   - id: The unique identifier of the session to export
+//
+// Returns:
+//   - The snapshot of the session
+//   - An error if the session does not exist
+func (sm *SessionManager) Export(id SessionID) (*SessionState, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	session, exists, err := sm.store.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session %s: %w", id, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("session not found: %s", id)
+	}
+
+	state := &SessionState{
+		Version:         SessionStateVersion,
+		ID:              session.ID,
+		ProtocolVersion: session.ProtocolVersion,
+		ClientInfo:      session.ClientInfo,
+		Created:         session.Created,
+		Metadata:        make(map[string]string, len(session.Metadata)),
+		Subscriptions:   append([]string(nil), session.ResourceSubscriptions...),
+		ExportedAt:      time.Now(),
+	}
+	for k, v := range session.Metadata {
+		state.Metadata[k] = v
+	}
+	state.ClientInfo.Roots = append([]string(nil), session.ClientInfo.Roots...)
+	if session.ClientInfo.Env != nil {
+		state.ClientInfo.Env = make(map[string]string, len(session.ClientInfo.Env))
+		for k, v := range session.ClientInfo.Env {
+			state.ClientInfo.Env[k] = v
+		}
+	}
+	return state, nil
+}
+
+// Import restores a session from a snapshot taken by Export, replacing any session
+// with the same ID.
+//
+// Parameters:
+//   - state: The snapshot of the session
+//
+// Returns:
+//   - The restored session
+//   - An error if the snapshot is invalid or could not be stored
+func (sm *SessionManager) Import(state *SessionState) (*ClientSession, error) {
+	if state == nil || state.ID == "" {
+		return nil, fmt.Errorf("session state has no session ID")
+	}
+	if state.Version != SessionStateVersion {
+		return nil, fmt.Errorf("unsupported session state version %d, expected %d", state.Version, SessionStateVersion)
+	}
+
+	session := &ClientSession{
+		ID:                    state.ID,
+		ClientInfo:            state.ClientInfo,
+		Created:               state.Created,
+		LastActive:            time.Now(),
+		ProtocolVersion:       state.ProtocolVersion,
+		Metadata:              make(map[string]string, len(state.Metadata)),
+		ResourceSubscriptions: append([]string(nil), state.Subscriptions...),
+	}
+	for k, v := range state.Metadata {
+		session.Metadata[k] = v
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if err := sm.store.Put(session); err != nil {
+		return nil, fmt.Errorf("failed to store session %s: %w", state.ID, err)
+	}
+	return session, nil
+}
+
+// ExportSession returns a snapshot of a session, including the per-session state
+// the server keeps outside of it, such as the log level the client asked for.
+// Hand it to ImportSession on the server taking the session over.
+//
+// Example:
+//
+//	state, err := old.GetServer().ExportSession(id)
+//	data, _ := json.Marshal(state)
+//	// ... on the new server
+//	var state server.SessionState
+//	_ = json.Unmarshal(data, &state)
+//	err = srv.GetServer().ImportSession(&state)
+func (s *serverImpl) ExportSession(id SessionID) (*SessionState, error) {
+	state, err := s.sessionManager.Export(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logLevelMu.RLock()
+	state.LogLevel = s.logLevels[id]
+	s.logLevelMu.RUnlock()
+	return state, nil
+}
+
+// ImportSession restores a session exported by another server, so that its client
+// carries on without initializing again. Transports that track their sessions,
+// such as HTTP with Mcp-Session-Id, are told to accept requests on it.
+func (s *serverImpl) ImportSession(state *SessionState) error {
+	session, err := s.sessionManager.Import(state)
+	if err != nil {
+		return err
+	}
+	if state.LogLevel != "" {
+		s.setSessionLogLevel(session, state.LogLevel)
+	}
+
+	s.mu.RLock()
+	t := s.transport
+	s.mu.RUnlock()
+	if importer, ok := t.(transport.SessionImporter); ok {
+		importer.ImportSession(string(session.ID))
+	}
+
+	s.logger.Info("session imported", "sessionID", session.ID, "protocolVersion", session.ProtocolVersion)
+	return nil
+}
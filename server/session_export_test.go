@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionExport(t *testing.T) {
+	newServer := func() *serverImpl {
+		s := NewServer("export-test").GetServer()
+		s.SetTransport(&sharedChannelTransport{})
+		s.Tool("status", "Show status", func(ctx *Context, args struct{}) (string, error) {
+			return ctx.Session.Metadata["tenant"], nil
+		})
+		return s
+	}
+	send := func(t *testing.T, s *serverImpl, request string) string {
+		t.Helper()
+		response, err := s.handleSessionMessage("blue-1", []byte(request))
+		require.NoError(t, err)
+		return string(response)
+	}
+
+	blue := newServer()
+	send(t, blue, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{"sampling":{}},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	send(t, blue, `{"jsonrpc":"2.0","id":2,"method":"logging/setLevel","params":{"level":"error"}}`)
+	send(t, blue, `{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"file:///notes.md"}}`)
+	require.True(t, blue.sessionManager.UpdateSession("blue-1", func(session *ClientSession) {
+		session.Metadata["tenant"] = "acme"
+		session.ClientInfo.Roots = []string{"/workspace"}
+	}))
+
+	state, err := blue.ExportSession("blue-1")
+	require.NoError(t, err)
+	data, err := json.Marshal(state)
+	require.NoError(t, err)
+
+	// The snapshot is independent of the session it was taken from
+	blue.sessionManager.UpdateSession("blue-1", func(session *ClientSession) {
+		session.Metadata["tenant"] = "changed"
+		session.ClientInfo.Roots[0] = "/changed"
+	})
+
+	var restored SessionState
+	require.NoError(t, json.Unmarshal(data, &restored))
+	green := newServer()
+	require.NoError(t, green.ImportSession(&restored))
+
+	session, ok := green.sessionManager.GetSession("blue-1")
+	require.True(t, ok)
+	assert.Equal(t, "2024-11-05", session.ProtocolVersion)
+	assert.Equal(t, []string{"/workspace"}, session.Roots())
+	assert.Equal(t, []string{"file:///notes.md"}, session.ResourceSubscriptions)
+	assert.True(t, session.ClientInfo.SamplingSupported)
+	assert.Equal(t, mcp.LogLevelError, green.sessionLogLevel(session))
+
+	// The client carries on without initializing again
+	assert.Contains(t, send(t, green, `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"status","arguments":{}}}`), "acme")
+
+	_, err = green.ExportSession("unknown")
+	assert.Error(t, err)
+	restored.Version = SessionStateVersion + 1
+	assert.ErrorContains(t, green.ImportSession(&restored), "unsupported session state version")
+}
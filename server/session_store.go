@@ -0,0 +1,169 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// SessionStore is the storage backend used by the SessionManager.
+//
+// The default MemorySessionStore keeps sessions in process memory. Servers
+// running several replicas behind a load balancer (HTTP, SSE) can provide a
+// shared implementation, for example backed by Redis, so that any replica can
+// resolve a session created by another one. Implementations that serialize
+// sessions should round-trip every exported field of ClientSession; the type
+// marshals cleanly with encoding/json.
+//
+// Implementations must be safe for concurrent use.
+type SessionStore interface {
+	// Get returns the session with the given ID.
+	// The boolean is false if the session does not exist or has expired.
+	Get(id SessionID) (*ClientSession, bool, error)
+
+	// Put creates or replaces a session and refreshes its expiry.
+	Put(session *ClientSession) error
+
+	// Delete removes a session. Deleting an unknown session is not an error.
+	Delete(id SessionID) error
+
+	// List returns all sessions that have not expired.
+	List() ([]*ClientSession, error)
+}
+
+// MemorySessionStore is an in-memory SessionStore with optional TTL-based expiry.
+// Sessions expire when they have not been read or written for the TTL; a zero
+// TTL keeps sessions until they are deleted.
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	sessions map[SessionID]*memorySessionEntry
+}
+
+// memorySessionEntry is a stored session along with its expiry time.
+type memorySessionEntry struct {
+	session   *ClientSession
+	expiresAt time.Time
+}
+
+// NewMemorySessionStore creates an in-memory session store.
+// A ttl of zero disables expiry.
+func NewMemorySessionStore(ttl time.Duration) *MemorySessionStore {
+	return &MemorySessionStore{
+		ttl:      ttl,
+		sessions: make(map[SessionID]*memorySessionEntry),
+	}
+}
+
+// Get returns the session with the given ID and extends its expiry.
+func (m *MemorySessionStore) Get(id SessionID) (*ClientSession, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.sessions[id]
+	if !exists {
+		return nil, false, nil
+	}
+
+	now := time.Now()
+	if m.expired(entry, now) {
+		delete(m.sessions, id)
+		return nil, false, nil
+	}
+
+	m.touch(entry, now)
+	return entry.session, true, nil
+}
+
+// Put creates or replaces a session and refreshes its expiry.
+func (m *MemorySessionStore) Put(session *ClientSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := &memorySessionEntry{session: session}
+	m.touch(entry, time.Now())
+	m.sessions[session.ID] = entry
+	return nil
+}
+
+// Delete removes a session.
+func (m *MemorySessionStore) Delete(id SessionID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, id)
+	return nil
+}
+
+// List returns all sessions that have not expired.
+func (m *MemorySessionStore) List() ([]*ClientSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	sessions := make([]*ClientSession, 0, len(m.sessions))
+	for id, entry := range m.sessions {
+		if m.expired(entry, now) {
+			delete(m.sessions, id)
+			continue
+		}
+		sessions = append(sessions, entry.session)
+	}
+
+	return sessions, nil
+}
+
+// Sweep removes expired sessions and returns how many were removed.
+// Expired sessions are also dropped lazily on access; Sweep only bounds memory
+// for sessions that are never accessed again.
+func (m *MemorySessionStore) Sweep() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	removed := 0
+	for id, entry := range m.sessions {
+		if m.expired(entry, now) {
+			delete(m.sessions, id)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// expired reports whether an entry has passed its expiry time.
+func (m *MemorySessionStore) expired(entry *memorySessionEntry, now time.Time) bool {
+	return m.ttl > 0 && now.After(entry.expiresAt)
+}
+
+// touch pushes an entry's expiry out by the store's TTL.
+func (m *MemorySessionStore) touch(entry *memorySessionEntry, now time.Time) {
+	if m.ttl > 0 {
+		entry.expiresAt = now.Add(m.ttl)
+	}
+}
+
+// WithSessionStore sets the storage backend for client sessions.
+// Use a shared store (e.g. Redis-backed) when several server replicas must see
+// the same sessions; the default is an in-memory store without expiry.
+func WithSessionStore(store SessionStore) Option {
+	return func(s *serverImpl) {
+		if store == nil {
+			return
+		}
+		s.sessionManager = NewSessionManagerWithStore(store)
+
+		// Carry over the default session created before options were applied
+		if s.defaultSession != nil {
+			if err := store.Put(s.defaultSession); err != nil {
+				s.logger.Warn("failed to store default session", "error", err)
+			}
+		}
+	}
+}
+
+// WithSessionTTL expires in-memory sessions that have been idle for longer than ttl.
+// It is a shorthand for WithSessionStore(NewMemorySessionStore(ttl)).
+func WithSessionTTL(ttl time.Duration) Option {
+	return WithSessionStore(NewMemorySessionStore(ttl))
+}
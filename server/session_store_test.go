@@ -0,0 +1,173 @@
+package server
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jsonSessionStore simulates an external backend by storing sessions serialized,
+// so every Get returns a fresh copy the way a Redis-backed store would.
+type jsonSessionStore struct {
+	mu   sync.Mutex
+	data map[SessionID][]byte
+}
+
+func newJSONSessionStore() *jsonSessionStore {
+	return &jsonSessionStore{data: make(map[SessionID][]byte)}
+}
+
+func (j *jsonSessionStore) Get(id SessionID) (*ClientSession, bool, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	raw, exists := j.data[id]
+	if !exists {
+		return nil, false, nil
+	}
+	var session ClientSession
+	if err := json.Unmarshal(raw, &session); err != nil {
+		return nil, false, err
+	}
+	return &session, true, nil
+}
+
+func (j *jsonSessionStore) Put(session *ClientSession) error {
+	raw, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.data[session.ID] = raw
+	return nil
+}
+
+func (j *jsonSessionStore) Delete(id SessionID) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.data, id)
+	return nil
+}
+
+func (j *jsonSessionStore) List() ([]*ClientSession, error) {
+	j.mu.Lock()
+	ids := make([]SessionID, 0, len(j.data))
+	for id := range j.data {
+		ids = append(ids, id)
+	}
+	j.mu.Unlock()
+
+	sessions := make([]*ClientSession, 0, len(ids))
+	for _, id := range ids {
+		if session, ok, err := j.Get(id); err == nil && ok {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func TestMemorySessionStore_Expiry(t *testing.T) {
+	store := NewMemorySessionStore(50 * time.Millisecond)
+
+	require.NoError(t, store.Put(&ClientSession{ID: "idle"}))
+	require.NoError(t, store.Put(&ClientSession{ID: "busy"}))
+
+	// Keep "busy" alive by reading it, let "idle" expire
+	for i := 0; i < 4; i++ {
+		time.Sleep(20 * time.Millisecond)
+		_, ok, err := store.Get("busy")
+		require.NoError(t, err)
+		require.True(t, ok, "accessed session should not expire")
+	}
+
+	_, ok, err := store.Get("idle")
+	require.NoError(t, err)
+	assert.False(t, ok, "idle session should have expired")
+
+	sessions, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, 1, store.Sweep())
+
+	sessions, err = store.List()
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestMemorySessionStore_NoTTL(t *testing.T) {
+	store := NewMemorySessionStore(0)
+	require.NoError(t, store.Put(&ClientSession{ID: "forever"}))
+
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 0, store.Sweep())
+
+	_, ok, err := store.Get("forever")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, store.Delete("forever"))
+	_, ok, _ = store.Get("forever")
+	assert.False(t, ok)
+}
+
+func TestSessionManager_ExternalStore(t *testing.T) {
+	store := newJSONSessionStore()
+	sm := NewSessionManagerWithStore(store)
+
+	session := sm.CreateSession(ClientInfo{ProtocolVersion: "2025-03-26"}, "2025-03-26")
+
+	// A second manager sharing the store (another replica) sees the session
+	replica := NewSessionManagerWithStore(store)
+	shared, ok := replica.GetSession(session.ID)
+	require.True(t, ok)
+	assert.Equal(t, "2025-03-26", shared.ProtocolVersion)
+
+	// Updates are written back to the store
+	require.True(t, replica.UpdateSession(session.ID, func(s *ClientSession) {
+		s.ResourceSubscriptions = append(s.ResourceSubscriptions, "file:///tmp/a")
+	}))
+	updated, ok := sm.GetSession(session.ID)
+	require.True(t, ok)
+	assert.Equal(t, []string{"file:///tmp/a"}, updated.ResourceSubscriptions)
+
+	sessions, err := sm.ListSessions()
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
+
+	_, closed := replica.CloseSession(session.ID, nil)
+	assert.True(t, closed)
+	_, ok = sm.GetSession(session.ID)
+	assert.False(t, ok)
+}
+
+func TestSessionManager_UniqueIDs(t *testing.T) {
+	sm := NewSessionManager()
+	other := NewSessionManager()
+
+	seen := make(map[SessionID]bool)
+	for i := 0; i < 100; i++ {
+		for _, manager := range []*SessionManager{sm, other} {
+			session := manager.CreateSession(ClientInfo{}, "draft")
+			require.False(t, seen[session.ID], "duplicate session ID %s", session.ID)
+			seen[session.ID] = true
+		}
+	}
+}
+
+func TestWithSessionStore_KeepsDefaultSession(t *testing.T) {
+	store := newJSONSessionStore()
+	srv := NewServer("store-test", WithSessionStore(store)).(*serverImpl)
+
+	require.NotNil(t, srv.defaultSession)
+	_, ok, err := store.Get(srv.defaultSession.ID)
+	require.NoError(t, err)
+	assert.True(t, ok, "default session should be present in the configured store")
+	assert.Same(t, store, srv.sessionManager.Store())
+}
@@ -1,12 +1,18 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
 	"testing"
+	"time"
 
+	httpTransport "github.com/localrivet/gomcp/transport/http"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestClientSession_Env(t *testing.T) {
@@ -455,8 +461,8 @@ func TestCompleteSessionFlow(t *testing.T) {
 	assert.True(t, len(env) == 0, "Environment should be empty initially (comes from headers)")
 	assert.True(t, len(roots) == 0, "Roots should be empty initially (comes from roots/list)")
 
-	// Verify that needsRootFetch was set since client supports roots capability
-	assert.True(t, server.needsRootFetch, "Server should be marked to fetch roots via roots/list")
+	// Verify that the session was marked to fetch roots since client supports roots capability
+	assert.True(t, server.defaultSession.ClientInfo.RootsSupported, "Session should be marked to fetch roots via roots/list")
 
 	// Now simulate a tool call request
 	toolCallRequest := map[string]interface{}{
@@ -489,5 +495,57 @@ func TestCompleteSessionFlow(t *testing.T) {
 
 	t.Logf("✅ Complete session flow working: ctx.Session.Env() = %v", toolCtx.Session.Env())
 	t.Logf("✅ Complete session flow working: ctx.Session.Roots() = %v", toolCtx.Session.Roots())
-	t.Logf("✅ Server correctly detected client roots capability and marked needsRootFetch = %v", server.needsRootFetch)
+	t.Logf("✅ Server correctly detected client roots capability and marked RootsSupported = %v", server.defaultSession.ClientInfo.RootsSupported)
+}
+
+// TestHTTPSessionEndClosesSession checks that sessions the HTTP transport ends,
+// by expiry or on the client's DELETE, are dropped from the session manager
+func TestHTTPSessionEndClosesSession(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	s := NewServer("test-server").AsHTTP(address, httpTransport.WithSessionTimeout(200*time.Millisecond)).(*serverImpl)
+	require.NoError(t, s.start())
+	defer s.transport.Stop()
+
+	url := "http://" + address + httpTransport.DefaultMCPEndpoint
+	initialize := func() SessionID {
+		body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}`
+		req, err := http.NewRequest("POST", url, bytes.NewReader([]byte(body)))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		id := SessionID(resp.Header.Get(httpTransport.SessionIDHeader))
+		_, exists := s.sessionManager.GetSession(id)
+		require.True(t, exists, "Expected the server to hold the initialized session")
+		return id
+	}
+	closed := func(id SessionID) func() bool {
+		return func() bool {
+			_, exists := s.sessionManager.GetSession(id)
+			return !exists
+		}
+	}
+
+	// A terminated session is dropped at once
+	terminated := initialize()
+	req, err := http.NewRequest("DELETE", url, nil)
+	require.NoError(t, err)
+	req.Header.Set(httpTransport.SessionIDHeader, string(terminated))
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, closed(terminated)(), "Expected the terminated session to be closed")
+
+	// An idle session is dropped once it expires, without the server sending anything
+	idle := initialize()
+	assert.Eventually(t, closed(idle), 2*time.Second, 20*time.Millisecond, "Expected the idle session to expire")
 }
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+)
+
+// defaultProtocolVersion is used when neither the session nor the server determines a version
+const defaultProtocolVersion = "2025-03-26"
+
+// transportSessionKey is the context key for the session a transport received a message on
+type transportSessionKey struct{}
+
+// withTransportSession returns a context recording the transport session of a message
+func withTransportSession(ctx context.Context, sessionID SessionID) context.Context {
+	return context.WithValue(ctx, transportSessionKey{}, sessionID)
+}
+
+// transportSessionFromContext returns the transport session recorded by withTransportSession
+func transportSessionFromContext(ctx context.Context) (SessionID, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	sessionID, ok := ctx.Value(transportSessionKey{}).(SessionID)
+	return sessionID, ok && sessionID != ""
+}
+
+// sessionForContext returns the session a message belongs to: the transport's session
+// if the client initialized it, otherwise the default session. The session is a
+// snapshot, so that handlers reading it do not race with updates to the session,
+// such as new roots, made while they run.
+func (s *serverImpl) sessionForContext(ctx context.Context) *ClientSession {
+	session := s.defaultSession
+	if sessionID, ok := transportSessionFromContext(ctx); ok {
+		if transportSession, exists := s.sessionManager.GetSession(sessionID); exists {
+			session = transportSession
+		}
+	}
+	return s.sessionManager.snapshot(session)
+}
+
+// sessionProtocolVersion returns the protocol version that governs serialization for
+// a session: the version negotiated with its client, else the version enforced with
+// WithProtocolVersion, else the latest stable version.
+func (s *serverImpl) sessionProtocolVersion(session *ClientSession) string {
+	if session != nil && session.ProtocolVersion != "" {
+		return session.ProtocolVersion
+	}
+	if s.protocolVersion != "" {
+		return s.protocolVersion
+	}
+	return defaultProtocolVersion
+}
+
+// protocolVersion returns the version governing this request's serialization
+func (c *Context) protocolVersion() string {
+	if c.Version != "" {
+		return c.Version
+	}
+	return c.server.sessionProtocolVersion(c.Session)
+}
+
+// supportsBatching reports whether a protocol version allows JSON-RPC batches.
+// Batching was introduced in 2025-03-26.
+func supportsBatching(version string) bool {
+	return version != "2024-11-05"
+}
+
+// supportsAudioContent reports whether a protocol version allows audio content.
+// Audio content was introduced in 2025-03-26.
+func supportsAudioContent(version string) bool {
+	return version != "2024-11-05"
+}
+
+// supportsProgressMessages reports whether a protocol version allows the message
+// field of progress notifications. Progress messages were introduced in 2025-03-26.
+func supportsProgressMessages(version string) bool {
+	return version != "2024-11-05"
+}
+
+// supportsElicitation reports whether a protocol version allows elicitation
+// requests. Elicitation is defined by the draft specification.
+func supportsElicitation(version string) bool {
+	return versionOrder(version) >= versionOrder("draft")
+}
+
+// supportsStructuredContent reports whether a protocol version allows the
+// structuredContent field of tool results. It is defined by the draft specification.
+func supportsStructuredContent(version string) bool {
+	return versionOrder(version) >= versionOrder("draft")
+}
+
+// adaptContentForVersion replaces content a protocol version cannot represent with
+// text, so that older clients still receive a valid result. Audio items become text
+// holding their alternative text, or a reference to the audio otherwise.
+func adaptContentForVersion(content []ContentItem, version string) []ContentItem {
+	if supportsAudioContent(version) {
+		return content
+	}
+
+	adapted := make([]ContentItem, 0, len(content))
+	for _, item := range content {
+		if item.Type != "audio" {
+			adapted = append(adapted, item)
+			continue
+		}
+
+		text := item.AltText
+		if text == "" && item.URL != "" {
+			text = fmt.Sprintf("[audio: %s]", item.URL)
+		} else if text == "" {
+			text = fmt.Sprintf("[audio: %s]", item.MimeType)
+		}
+		adapted = append(adapted, NewTextContent(text))
+	}
+	return adapted
+}
+
+// versionFeatures lists protocol features that depend on the negotiated version,
+// with the version that introduced each
+var versionFeatures = []struct {
+	name  string
+	since string
+}{
+	{"audio_content", "2025-03-26"},
+	{"jsonrpc_batching", "2025-03-26"},
+	{"progress_messages", "2025-03-26"},
+	{"server_instructions", "2025-03-26"},
+	{"elicitation", "draft"},
+	{"structured_content", "draft"},
+}
+
+// versionOrder ranks protocol versions from oldest to newest; unknown versions rank -1
+func versionOrder(version string) int {
+	switch version {
+	case "2024-11-05":
+		return 0
+	case "2025-03-26":
+		return 1
+	case "draft":
+		return 2
+	default:
+		return -1
+	}
+}
+
+// disabledFeatures returns the features available in the preferred version but not
+// in the negotiated one
+func disabledFeatures(negotiated, preferred string) []string {
+	disabled := []string{}
+	for _, feature := range versionFeatures {
+		since := versionOrder(feature.since)
+		if since > versionOrder(negotiated) && since <= versionOrder(preferred) {
+			disabled = append(disabled, feature.name)
+		}
+	}
+	return disabled
+}
+
+// preferredProtocolVersion returns the version the server negotiates when the client
+// does not constrain it
+func (s *serverImpl) preferredProtocolVersion() string {
+	if s.protocolVersion != "" {
+		return s.protocolVersion
+	}
+	return s.versionDetector.DefaultVersion
+}
+
+// reportVersionDowngrade logs a warning and publishes a VersionDowngradedEvent when a
+// session negotiated an older protocol version than the server prefers
+func (s *serverImpl) reportVersionDowngrade(session *ClientSession, requestedVersion string, params json.RawMessage) {
+	preferred := s.preferredProtocolVersion()
+	negotiated := session.ProtocolVersion
+	if versionOrder(negotiated) < 0 || versionOrder(negotiated) >= versionOrder(preferred) {
+		return
+	}
+
+	var initParams struct {
+		ClientInfo events.ClientInfo `json:"clientInfo"`
+	}
+	_ = json.Unmarshal(params, &initParams)
+
+	disabled := disabledFeatures(negotiated, preferred)
+	s.logger.Warn("client negotiated an older protocol version",
+		"sessionID", string(session.ID),
+		"client", initParams.ClientInfo.Name,
+		"negotiatedVersion", negotiated,
+		"preferredVersion", preferred,
+		"disabledFeatures", disabled)
+
+	if s.events == nil {
+		return
+	}
+	go events.Publish[events.VersionDowngradedEvent](s.events, events.TopicVersionDowngraded, events.VersionDowngradedEvent{
+		SessionID:         string(session.ID),
+		ClientInfo:        initParams.ClientInfo,
+		RequestedVersion:  requestedVersion,
+		NegotiatedVersion: negotiated,
+		PreferredVersion:  preferred,
+		DisabledFeatures:  disabled,
+		NegotiatedAt:      time.Now(),
+	})
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func initializeSession(t *testing.T, s *serverImpl, sessionID, version string) {
+	t.Helper()
+	request := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":%q,"capabilities":{},"clientInfo":{"name":"client","version":"1.0"}}}`, version)
+	response, err := s.handleSessionMessage(sessionID, []byte(request))
+	require.NoError(t, err)
+	require.Contains(t, string(response), fmt.Sprintf(`"protocolVersion":%q`, version))
+}
+
+func TestMixedVersionSessions(t *testing.T) {
+	s := NewServer("mixed-versions").GetServer()
+	s.SetTransport(&recordingTransport{})
+
+	s.Tool("version", "Reports the request's protocol version", func(ctx *Context, args struct{}) (interface{}, error) {
+		return ctx.Version, nil
+	})
+	s.Tool("speak", "Returns audio", func(ctx *Context, args struct{}) (interface{}, error) {
+		return []interface{}{
+			map[string]interface{}{"type": "audio", "audioUrl": "https://example.com/hello.wav", "altText": "hello", "mimeType": "audio/wav"},
+		}, nil
+	})
+
+	initializeSession(t, s, "old", "2024-11-05")
+	initializeSession(t, s, "new", "2025-03-26")
+
+	call := func(sessionID, tool string) string {
+		response, err := s.handleSessionMessage(sessionID, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":%q,"arguments":{}}}`, tool)))
+		require.NoError(t, err)
+		return string(response)
+	}
+
+	// Each session keeps the version its client negotiated
+	assert.Contains(t, call("old", "version"), "2024-11-05")
+	assert.Contains(t, call("new", "version"), "2025-03-26")
+
+	// Audio content is downgraded to text for the older session only
+	oldAudio := call("old", "speak")
+	assert.NotContains(t, oldAudio, `"type":"audio"`)
+	assert.Contains(t, oldAudio, `"text":"hello"`)
+	assert.Contains(t, call("new", "speak"), `"type":"audio"`)
+
+	// Batches are rejected for the older session only
+	batch := []byte(`[{"jsonrpc":"2.0","id":3,"method":"ping"}]`)
+	response, err := s.handleSessionMessage("old", batch)
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "-32600")
+
+	response, err = s.handleSessionMessage("new", batch)
+	require.NoError(t, err)
+	assert.NotContains(t, string(response), "error")
+}
+
+func TestAdaptContentForVersion(t *testing.T) {
+	content := []ContentItem{
+		NewTextContent("caption"),
+		{Type: "audio", URL: "https://example.com/a.wav"},
+	}
+
+	assert.Equal(t, content, adaptContentForVersion(content, "2025-03-26"))
+
+	adapted := adaptContentForVersion(content, "2024-11-05")
+	require.Len(t, adapted, 2)
+	assert.Equal(t, "caption", adapted[0].Text)
+	assert.Equal(t, "text", adapted[1].Type)
+	assert.Equal(t, "[audio: https://example.com/a.wav]", adapted[1].Text)
+}
+
+func TestVersionDowngradeEvent(t *testing.T) {
+	s := NewServer("downgrades").GetServer()
+	s.SetTransport(&recordingTransport{})
+
+	downgrades := make(chan events.VersionDowngradedEvent, 2)
+	events.Subscribe[events.VersionDowngradedEvent](s.Events(), events.TopicVersionDowngraded,
+		func(ctx context.Context, event events.VersionDowngradedEvent) error {
+			downgrades <- event
+			return nil
+		})
+
+	initializeSession(t, s, "new", "2025-03-26")
+	initializeSession(t, s, "old", "2024-11-05")
+
+	select {
+	case event := <-downgrades:
+		assert.Equal(t, "old", event.SessionID)
+		assert.Equal(t, "client", event.ClientInfo.Name)
+		assert.Equal(t, "2024-11-05", event.NegotiatedVersion)
+		assert.Equal(t, "2025-03-26", event.PreferredVersion)
+		assert.Contains(t, event.DisabledFeatures, "audio_content")
+		assert.Contains(t, event.DisabledFeatures, "jsonrpc_batching")
+	case <-time.After(time.Second):
+		t.Fatal("expected a version downgrade event for the 2024-11-05 client")
+	}
+
+	// Clients on the preferred version or newer are not reported
+	select {
+	case event := <-downgrades:
+		t.Fatalf("unexpected downgrade event for session %s", event.SessionID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDisabledFeatures(t *testing.T) {
+	assert.Empty(t, disabledFeatures("2025-03-26", "2025-03-26"))
+	assert.Empty(t, disabledFeatures("draft", "2025-03-26"))
+	assert.Equal(t, []string{"elicitation", "structured_content"}, disabledFeatures("2025-03-26", "draft"))
+	assert.Equal(t, append(disabledFeatures("2024-11-05", "2025-03-26"), "elicitation", "structured_content"), disabledFeatures("2024-11-05", "draft"))
+	assert.Len(t, disabledFeatures("2024-11-05", "draft"), len(versionFeatures))
+}
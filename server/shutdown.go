@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+)
+
+// DefaultDrainTimeout is how long shutdown waits for in-flight requests by default.
+const DefaultDrainTimeout = 10 * time.Second
+
+// ErrServerShuttingDown is reported to clients whose requests arrive after shutdown has begun.
+var ErrServerShuttingDown = errors.New("server is shutting down")
+
+// Shutdown reasons reported to clients and in the ServerShutdownEvent
+const (
+	shutdownReasonRequested       = "server_requested"
+	shutdownReasonClientRequested = "client_requested"
+	shutdownReasonContextDone     = "context_done"
+)
+
+// WithDrainTimeout sets how long shutdown waits for in-flight tool, resource and
+// prompt handlers to finish before the transport is closed. Requests still running
+// when the timeout expires are abandoned. Zero waits until the handlers finish or
+// the context passed to ShutdownContext is done. The default is DefaultDrainTimeout.
+//
+// Example:
+//
+//	server.NewServer("my-server", server.WithDrainTimeout(30*time.Second))
+func WithDrainTimeout(timeout time.Duration) Option {
+	return func(s *serverImpl) {
+		s.drainTimeout = timeout
+	}
+}
+
+// Serve starts the server and blocks until ctx is done or the server is shut down.
+//
+// When ctx is done, Serve shuts the server down gracefully, as Shutdown does, and
+// returns the shutdown error if any. This makes it easy to tie the server's lifetime
+// to signals:
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+//	defer stop()
+//	if err := server.Serve(ctx); err != nil {
+//	    log.Fatalf("Server error: %v", err)
+//	}
+func (s *serverImpl) Serve(ctx context.Context) error {
+	if err := s.start(); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return s.shutdown(context.Background(), shutdownReasonContextDone)
+	case <-s.done:
+		return s.shutdownErr
+	}
+}
+
+// Shutdown gracefully shuts down the server, waiting up to the drain timeout for
+// in-flight requests. See ShutdownContext for the steps involved.
+func (s *serverImpl) Shutdown() error {
+	return s.shutdown(context.Background(), shutdownReasonRequested)
+}
+
+// ShutdownContext gracefully shuts down the server. It stops accepting new requests,
+// waits for in-flight handlers until they finish, the drain timeout expires or ctx
+// is done, notifies clients that the server is going away, and then closes the
+// transport. Only the first call performs the shutdown; later calls wait for it and
+// return its result.
+func (s *serverImpl) ShutdownContext(ctx context.Context) error {
+	return s.shutdown(ctx, shutdownReasonRequested)
+}
+
+// shutdown runs the shutdown pipeline once and returns its result
+func (s *serverImpl) shutdown(ctx context.Context, reason string) error {
+	s.shutdownOnce.Do(func() {
+		s.shutdownErr = s.runShutdown(ctx, reason)
+		close(s.done)
+	})
+	return s.shutdownErr
+}
+
+// runShutdown drains requests, notifies clients and releases the server's resources
+func (s *serverImpl) runShutdown(ctx context.Context, reason string) error {
+	s.logger.Info("shutting down server", "name", s.name, "reason", reason)
+
+	// Stop accepting new requests
+	s.lifecycleMu.Lock()
+	s.draining = true
+	s.lifecycleMu.Unlock()
+
+	drained := s.drain(ctx)
+
+	// Tell clients the server is going away
+	s.sendNotification("notifications/message", map[string]interface{}{
+		"level":  "notice",
+		"logger": s.name,
+		"data": map[string]interface{}{
+			"message": ErrServerShuttingDown.Error(),
+			"reason":  reason,
+		},
+	})
+	if s.events != nil {
+		events.Publish[events.ServerShutdownEvent](s.events, events.TopicServerShutdown, events.ServerShutdownEvent{
+			ServerName:   s.name,
+			ShutdownAt:   time.Now(),
+			GracefulExit: drained,
+			Reason:       reason,
+		})
+	}
+
+	// Close the transport and release background resources
+	var err error
+	if s.transport != nil {
+		if stopErr := s.transport.Stop(); stopErr != nil {
+			s.logger.Error("error stopping transport", "error", stopErr)
+			err = fmt.Errorf("failed to stop transport: %w", stopErr)
+		}
+	}
+	if s.samplingController != nil {
+		s.samplingController.Stop()
+	}
+	s.flushToolErrors()
+	if s.events != nil {
+		events.Complete(s.events)
+	}
+
+	s.logger.Info("server shutdown complete", "name", s.name, "drained", drained)
+	return err
+}
+
+// drain waits for in-flight requests and reports whether all of them finished
+func (s *serverImpl) drain(ctx context.Context) bool {
+	if s.drainTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.drainTimeout)
+		defer cancel()
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return true
+	case <-ctx.Done():
+		s.logger.Warn("drain timeout expired, abandoning in-flight requests", "name", s.name)
+		return false
+	}
+}
+
+// beginRequest registers an incoming request with the drain tracker.
+// It returns false once shutdown has begun, in which case the request must be rejected.
+func (s *serverImpl) beginRequest() bool {
+	s.lifecycleMu.Lock()
+	defer s.lifecycleMu.Unlock()
+
+	if s.draining {
+		return false
+	}
+	s.inFlight.Add(1)
+	return true
+}
+
+// endRequest marks a request registered with beginRequest as finished
+func (s *serverImpl) endRequest() {
+	s.inFlight.Done()
+}
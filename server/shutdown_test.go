@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/transport"
+	"github.com/localrivet/gomcp/transport/embedded"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTransport records sent messages and whether it was stopped
+type recordingTransport struct {
+	transport.BaseTransport
+
+	mu      sync.Mutex
+	sent    []string
+	stopped bool
+}
+
+func (r *recordingTransport) Initialize() error        { return nil }
+func (r *recordingTransport) Start() error             { return nil }
+func (r *recordingTransport) Receive() ([]byte, error) { return nil, nil }
+
+func (r *recordingTransport) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopped = true
+	return nil
+}
+
+func (r *recordingTransport) Send(message []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, string(message))
+	return nil
+}
+
+func (r *recordingTransport) isStopped() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stopped
+}
+
+func (r *recordingTransport) sentContaining(substr string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, message := range r.sent {
+		if strings.Contains(message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// newBlockingToolServer returns a server whose "slow" tool signals started and then
+// blocks until release is closed
+func newBlockingToolServer(options ...Option) (*serverImpl, *recordingTransport, chan struct{}, chan struct{}) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	s := NewServer("shutdown-test", options...).GetServer()
+	t := &recordingTransport{}
+	s.SetTransport(t)
+
+	s.Tool("slow", "Blocks until released", func(ctx *Context, args struct{}) (interface{}, error) {
+		started <- struct{}{}
+		<-release
+		return "done", nil
+	})
+	return s, t, started, release
+}
+
+const slowToolCall = `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow","arguments":{}}}`
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	s, tr, started, release := newBlockingToolServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(ctx) }()
+
+	// Start a tool call and wait for its handler to run
+	callResult := make(chan string, 1)
+	go func() {
+		response, _ := s.handleMessage([]byte(slowToolCall))
+		callResult <- string(response)
+	}()
+	<-started
+
+	cancel()
+
+	// New requests are rejected once draining starts
+	require.Eventually(t, func() bool {
+		response, _ := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":2,"method":"ping"}`))
+		return strings.Contains(string(response), "-32000")
+	}, time.Second, 5*time.Millisecond)
+
+	// Notifications are still handled while draining
+	response, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"9"}}`))
+	assert.NoError(t, err)
+	assert.Nil(t, response)
+
+	// The transport stays open until the in-flight call finishes
+	assert.False(t, tr.isStopped())
+	close(release)
+
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve did not return after the in-flight request finished")
+	}
+
+	assert.Contains(t, <-callResult, "done")
+	assert.True(t, tr.isStopped())
+	assert.True(t, tr.sentContaining(`"method":"notifications/message"`), "clients should be notified of the shutdown")
+}
+
+func TestShutdownDrainTimeout(t *testing.T) {
+	s, tr, started, release := newBlockingToolServer(WithDrainTimeout(50 * time.Millisecond))
+	defer close(release)
+
+	go s.handleMessage([]byte(slowToolCall))
+	<-started
+
+	begin := time.Now()
+	require.NoError(t, s.Shutdown())
+	assert.Less(t, time.Since(begin), time.Second, "shutdown should give up after the drain timeout")
+	assert.True(t, tr.isStopped())
+
+	// Later calls return the result of the first shutdown without running it again
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, s.ShutdownContext(ctx))
+}
+
+func TestClientShutdownRequest(t *testing.T) {
+	const shutdownRequest = `{"jsonrpc":"2.0","id":9,"method":"shutdown"}`
+
+	t.Run("shared transport closes the client's session only", func(t *testing.T) {
+		s := NewServer("shutdown-test").GetServer()
+		tr := &recordingTransport{}
+		s.SetTransport(tr)
+		initializeSession(t, s, "leaving", "2025-03-26")
+		initializeSession(t, s, "staying", "2025-03-26")
+
+		response, err := s.handleSessionMessage("leaving", []byte(shutdownRequest))
+		require.NoError(t, err)
+		assert.Contains(t, string(response), `"result"`)
+
+		_, exists := s.sessionManager.GetSession("leaving")
+		assert.False(t, exists, "the leaving client's session should be closed")
+		_, exists = s.sessionManager.GetSession("staying")
+		assert.True(t, exists, "other sessions should be kept")
+
+		// Requests without a session are acknowledged without stopping the server either
+		_, err = s.handleMessage([]byte(shutdownRequest))
+		require.NoError(t, err)
+		time.Sleep(200 * time.Millisecond)
+		assert.False(t, tr.isStopped())
+		response, err = s.handleSessionMessage("staying", []byte(`{"jsonrpc":"2.0","id":10,"method":"ping"}`))
+		require.NoError(t, err)
+		assert.NotContains(t, string(response), "-32000")
+	})
+
+	t.Run("single client transport shuts down", func(t *testing.T) {
+		s := NewServer("shutdown-test").GetServer()
+		serverTransport, _ := embedded.NewTransportPair()
+		s.AsEmbedded(serverTransport)
+
+		_, err := s.handleMessage([]byte(shutdownRequest))
+		require.NoError(t, err)
+		select {
+		case <-s.done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected the server to shut down once its only client left")
+		}
+	})
+}
@@ -0,0 +1,309 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+	"github.com/localrivet/gomcp/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The soak test runs a server under sustained load from clients that come and go,
+// snapshotting goroutines, the heap and the state the server keeps per session and
+// per request, and checks that none of it grows without bound. It runs for a few
+// seconds by default. For a long run set GOMCP_SOAK_DURATION, for example to "2h"
+// (see make soak), and GOMCP_SOAK_PROFILE_DIR to write a goroutine and a heap
+// profile at every snapshot.
+
+// soakTransport stands in for the clients of a multi-session transport: it answers
+// roots/list requests on the session they were sent to and discards everything else
+type soakTransport struct {
+	transport.BaseTransport
+	server *serverImpl
+	sent   atomic.Int64
+}
+
+func (t *soakTransport) Initialize() error        { return nil }
+func (t *soakTransport) Start() error             { return nil }
+func (t *soakTransport) Stop() error              { return nil }
+func (t *soakTransport) Receive() ([]byte, error) { return nil, nil }
+
+func (t *soakTransport) Send(message []byte) error {
+	t.sent.Add(1)
+	return nil
+}
+
+func (t *soakTransport) SendToSession(sessionID string, message []byte) error {
+	t.sent.Add(1)
+	envelope, err := transport.ParseEnvelope(message)
+	if err == nil && envelope.HasMethod && envelope.HasID() {
+		response := fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":{"roots":[{"uri":"file:///soak"}]}}`, envelope.ID)
+		go t.server.handleSessionMessage(sessionID, []byte(response))
+	}
+	return nil
+}
+
+// soakSnapshot is what the server holds at one point of the soak test
+type soakSnapshot struct {
+	Elapsed         time.Duration
+	Goroutines      int
+	HeapAlloc       uint64
+	Sessions        int
+	PendingRequests int
+	BoundRequests   int
+	Cancellations   int
+	ProgressTokens  int
+	RateLimiters    int
+	LogLevels       int
+	UnknownMethods  int
+	PendingEvents   int
+}
+
+// takeSoakSnapshot collects garbage and records what s holds
+func takeSoakSnapshot(t *testing.T, s *serverImpl, elapsed time.Duration) soakSnapshot {
+	t.Helper()
+	runtime.GC()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	sessions, err := s.sessionManager.ListSessions()
+	require.NoError(t, err)
+
+	snapshot := soakSnapshot{
+		Elapsed:        elapsed,
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAlloc:      mem.HeapAlloc,
+		Sessions:       len(sessions),
+		ProgressTokens: s.progressTokenManager.Len(),
+		PendingEvents:  events.Pending(s.events),
+	}
+
+	s.requestTracker.mu.RLock()
+	snapshot.PendingRequests = len(s.requestTracker.requests) + len(s.requestTracker.timeouts)
+	s.requestTracker.mu.RUnlock()
+
+	if bound, ok := s.requestTracker.sessions.(*MemoryRequestSessionMap); ok {
+		bound.mu.RLock()
+		snapshot.BoundRequests = len(bound.sessions)
+		bound.mu.RUnlock()
+	}
+
+	s.requestCanceller.mu.RLock()
+	snapshot.Cancellations = len(s.requestCanceller.cancellations) + len(s.requestCanceller.contexts)
+	s.requestCanceller.mu.RUnlock()
+
+	s.progressNotificationHandler.mu.RLock()
+	snapshot.RateLimiters = len(s.progressNotificationHandler.rateLimiters)
+	s.progressNotificationHandler.mu.RUnlock()
+
+	s.logLevelMu.RLock()
+	snapshot.LogLevels = len(s.logLevels)
+	s.logLevelMu.RUnlock()
+
+	s.notificationMu.Lock()
+	snapshot.UnknownMethods = len(s.unknownMethods)
+	s.notificationMu.Unlock()
+
+	return snapshot
+}
+
+// writeSoakProfiles writes goroutine and heap profiles for snapshot n to dir
+func writeSoakProfiles(t *testing.T, dir string, n int) {
+	t.Helper()
+	for _, name := range []string{"goroutine", "heap"} {
+		f, err := os.Create(filepath.Join(dir, fmt.Sprintf("%s-%03d.pprof", name, n)))
+		require.NoError(t, err)
+		require.NoError(t, pprof.Lookup(name).WriteTo(f, 0))
+		require.NoError(t, f.Close())
+	}
+}
+
+// soakDuration returns how long to run the soak test for
+func soakDuration(t *testing.T) time.Duration {
+	t.Helper()
+	value := os.Getenv("GOMCP_SOAK_DURATION")
+	if value == "" {
+		return 3 * time.Second
+	}
+	duration, err := time.ParseDuration(value)
+	require.NoError(t, err, "invalid GOMCP_SOAK_DURATION")
+	return duration
+}
+
+// runSoakClient plays one client session from initialize to disconnect
+func runSoakClient(t *testing.T, s *serverImpl, sessionID string, n int) {
+	send := func(message string) []byte {
+		response, err := s.handleSessionMessage(sessionID, []byte(message))
+		if err != nil {
+			t.Errorf("session %s: %v", sessionID, err)
+		}
+		return response
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{"roots":{"listChanged":true}},"clientInfo":{"name":"soak","version":"1.0"}}}`)
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	send(`{"jsonrpc":"2.0","id":2,"method":"logging/setLevel","params":{"level":"debug"}}`)
+	send(`{"jsonrpc":"2.0","method":"notifications/roots/list_changed"}`)
+	send(`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+
+	if response := send(`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"count","arguments":{"to":25}}}`); !assert.Contains(t, string(response), "counted") {
+		return
+	}
+
+	// Cancel a call while it waits; the notification may arrive before the call starts
+	// waiting, so keep sending it until the call returns
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		send(`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"wait","arguments":{}}}`)
+	}()
+	for cancelled := false; !cancelled; {
+		send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":5,"reason":"soak"}}`)
+		select {
+		case <-done:
+			cancelled = true
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	// Clients name the methods of unknown notifications, so there is no end to them
+	send(fmt.Sprintf(`{"jsonrpc":"2.0","method":"notifications/soak-%d"}`, n))
+	send(`{"jsonrpc":"2.0","id":6,"method":"ping"}`)
+
+	// Disconnect, as a transport does when the client goes away
+	s.closeSession(SessionID(sessionID))
+}
+
+func TestSoakBoundedGrowth(t *testing.T) {
+	if testing.Short() {
+		t.Skip("soak test skipped in short mode")
+	}
+
+	duration := soakDuration(t)
+	interval := duration / 20
+	if interval < 250*time.Millisecond {
+		interval = 250 * time.Millisecond
+	}
+	profileDir := os.Getenv("GOMCP_SOAK_PROFILE_DIR")
+	if profileDir != "" {
+		require.NoError(t, os.MkdirAll(profileDir, 0o755))
+	}
+
+	const clients = 8
+	discard := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewServer("soak", WithLogger(discard)).GetServer()
+	tr := &soakTransport{server: s}
+	s.SetTransport(tr)
+	s.requestTracker = newRequestTracker(s.requestSessions) // as Serve does
+
+	s.Tool("count", "Reports progress while counting", func(ctx *Context, args struct {
+		To int `json:"to"`
+	}) (interface{}, error) {
+		ctx.CreateProgressToken()
+		total := float64(args.To)
+		for i := 1; i <= args.To; i++ {
+			if err := ctx.SendProgress(float64(i), &total, "counting"); err != nil {
+				return nil, err
+			}
+		}
+		return "counted " + strconv.Itoa(args.To), nil
+	})
+	s.Tool("wait", "Waits until its request is cancelled", func(ctx *Context, args struct{}) (interface{}, error) {
+		select {
+		case <-ctx.RegisterForCancellation():
+			return nil, ErrRequestCancelled
+		case <-time.After(5 * time.Second):
+			return "not cancelled", nil
+		}
+	})
+
+	baseline := takeSoakSnapshot(t, s, 0)
+	start := time.Now()
+	stop := make(chan struct{})
+	var sessionsRun atomic.Int64
+	var wg sync.WaitGroup
+	for c := 0; c < clients; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			// Each client reconnects shortly after disconnecting, which keeps the load
+			// sustained without outrunning the event system's single delivery loop
+			for n := 0; ; n++ {
+				select {
+				case <-stop:
+					return
+				case <-time.After(time.Millisecond):
+				}
+				runSoakClient(t, s, fmt.Sprintf("soak-%d-%d", c, n), int(sessionsRun.Add(1)))
+			}
+		}(c)
+	}
+
+	// Every snapshot taken under load stays within what the clients in flight need
+	var snapshots []soakSnapshot
+	ticker := time.NewTicker(interval)
+	for time.Since(start) < duration {
+		<-ticker.C
+		snapshot := takeSoakSnapshot(t, s, time.Since(start))
+		snapshots = append(snapshots, snapshot)
+		t.Logf("%+v", snapshot)
+		if profileDir != "" {
+			writeSoakProfiles(t, profileDir, len(snapshots))
+		}
+
+		assert.LessOrEqual(t, snapshot.Sessions, clients+1, "sessions")
+		assert.LessOrEqual(t, snapshot.PendingRequests, 4*clients, "pending server requests")
+		assert.LessOrEqual(t, snapshot.BoundRequests, 2*clients, "request sessions")
+		assert.LessOrEqual(t, snapshot.Cancellations, 4*clients, "cancellable requests")
+		assert.LessOrEqual(t, snapshot.ProgressTokens, clients, "progress tokens")
+		assert.LessOrEqual(t, snapshot.RateLimiters, clients, "progress rate limiters")
+		assert.LessOrEqual(t, snapshot.LogLevels, clients, "session log levels")
+		assert.LessOrEqual(t, snapshot.UnknownMethods, maxUnknownMethods+1, "unknown notification methods")
+	}
+	ticker.Stop()
+	close(stop)
+	wg.Wait()
+	require.NotEmpty(t, snapshots)
+	t.Logf("ran %d client sessions, sent %d messages", sessionsRun.Load(), tr.sent.Load())
+
+	// Once the clients are gone, the server is back to holding what it held before
+	var final soakSnapshot
+	settled := assert.Eventually(t, func() bool {
+		final = takeSoakSnapshot(t, s, time.Since(start))
+		return final.Sessions == baseline.Sessions &&
+			final.PendingRequests == 0 &&
+			final.BoundRequests == 0 &&
+			final.Cancellations == 0 &&
+			final.ProgressTokens == 0 &&
+			final.RateLimiters == 0 &&
+			final.LogLevels == 0 &&
+			final.Goroutines <= baseline.Goroutines+10
+	}, 15*time.Second, 100*time.Millisecond)
+	if !settled {
+		t.Errorf("server state did not settle after the load stopped:\nbaseline %+v\nfinal    %+v", baseline, final)
+	}
+
+	// The heap does not keep growing with the number of sessions served
+	var early uint64
+	for _, snapshot := range snapshots[:(len(snapshots)+1)/2] {
+		if snapshot.HeapAlloc > early {
+			early = snapshot.HeapAlloc
+		}
+	}
+	for _, snapshot := range snapshots[(len(snapshots)+1)/2:] {
+		assert.LessOrEqual(t, snapshot.HeapAlloc, 2*early+16<<20,
+			"heap grew from at most %d bytes to %d bytes after %s", early, snapshot.HeapAlloc, snapshot.Elapsed)
+	}
+}
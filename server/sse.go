@@ -1,6 +1,8 @@
 package server
 
 import (
+	"github.com/localrivet/gomcp/events"
+	"github.com/localrivet/gomcp/transport"
 	"github.com/localrivet/gomcp/transport/sse"
 )
 
@@ -27,6 +29,12 @@ import (
 //
 //	// With custom path options
 //	server.AsSSE(":8080", sse.SSE.WithPathPrefix("/api/v1"), sse.SSE.WithEventsPath("/events"))
+//
+//	// Keep the last 500 events for 10 minutes so reconnecting clients can resume
+//	server.AsSSE(":8080", sse.SSE.WithReplayBufferSize(500), sse.SSE.WithReplayRetention(10*time.Minute))
+//
+//	// Serve HTTPS and require client certificates signed by ca.pem
+//	server.AsSSE(":8443", sse.SSE.WithTLS("cert.pem", "key.pem"), sse.SSE.WithMutualTLS("ca.pem"))
 func (s *serverImpl) AsSSE(address string, options ...sse.Option) Server {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -51,3 +59,30 @@ func (s *serverImpl) AsSSE(address string, options ...sse.Option) Server {
 		"message_endpoint", sseTransport.GetFullMessagePath())
 	return s
 }
+
+// reportLegacyClient warns about a client of the deprecated HTTP+SSE endpoints and
+// publishes it as an event, so that their remaining usage can be measured
+func (s *serverImpl) reportLegacyClient(client transport.LegacyClient) {
+	if client.Rejected {
+		s.logger.Warn("legacy HTTP+SSE client turned away, the endpoints are disabled",
+			"remoteIP", client.RemoteIP,
+			"userAgent", client.UserAgent,
+			"path", client.Path)
+	} else {
+		s.logger.Warn("client connected through the deprecated HTTP+SSE endpoints",
+			"remoteIP", client.RemoteIP,
+			"userAgent", client.UserAgent,
+			"path", client.Path)
+	}
+
+	if s.events == nil {
+		return
+	}
+	go events.Publish[events.LegacyClientEvent](s.events, events.TopicLegacyClient, events.LegacyClientEvent{
+		RemoteIP:  client.RemoteIP,
+		UserAgent: client.UserAgent,
+		Path:      client.Path,
+		Rejected:  client.Rejected,
+		SeenAt:    client.SeenAt,
+	})
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/server"
+)
+
+type sleepArgs struct {
+	Millis int `json:"millis"`
+}
+
+func callSleepTool(t *testing.T, s server.Server, id int, millis int) (bool, string) {
+	t.Helper()
+
+	request := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"sleep","arguments":{"millis":%d}}}`, id, millis)
+	responseBytes, err := server.HandleMessage(s.GetServer(), []byte(request))
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+
+	var response struct {
+		Result struct {
+			IsError bool `json:"isError"`
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(responseBytes, &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	text := ""
+	if len(response.Result.Content) > 0 {
+		text = response.Result.Content[0].Text
+	}
+	return response.Result.IsError, text
+}
+
+func TestAdaptiveTimeouts(t *testing.T) {
+	s := server.NewServer("adaptive-server",
+		server.WithAdaptiveTimeouts(2),
+		server.WithAdaptiveTimeoutBounds(30*time.Millisecond, time.Second),
+	)
+
+	handlerSawDeadline := make(chan bool, 1)
+	s.Tool("sleep", "Sleep for a while", func(ctx *server.Context, args sleepArgs) (interface{}, error) {
+		select {
+		case <-time.After(time.Duration(args.Millis) * time.Millisecond):
+			return "done", nil
+		case <-ctx.Done():
+			handlerSawDeadline <- true
+			return nil, fmt.Errorf("interrupted")
+		}
+	})
+
+	// Build up latency history with fast calls
+	for i := 0; i < 25; i++ {
+		if isError, text := callSleepTool(t, s, i+1, 1); isError {
+			t.Fatalf("Warm-up call failed: %s", text)
+		}
+	}
+
+	// A call far beyond the learned p99 (clamped to the 30ms floor) times out
+	isError, text := callSleepTool(t, s, 100, 300)
+	if !isError || !strings.Contains(text, "timed out") {
+		t.Fatalf("Expected timeout error, got isError=%v text=%q", isError, text)
+	}
+
+	select {
+	case <-handlerSawDeadline:
+	case <-time.After(time.Second):
+		t.Error("Handler context should be cancelled when the adaptive deadline passes")
+	}
+
+	// A call within the learned bounds still succeeds
+	if isError, text := callSleepTool(t, s, 101, 5); isError {
+		t.Fatalf("Expected call within deadline to succeed, got %q", text)
+	}
+}
+
+func TestAdaptiveTimeoutsUseCeilingWithoutHistory(t *testing.T) {
+	s := server.NewServer("adaptive-server",
+		server.WithAdaptiveTimeouts(2),
+		server.WithAdaptiveTimeoutBounds(time.Millisecond, 50*time.Millisecond),
+	)
+
+	s.Tool("sleep", "Sleep for a while", func(ctx *server.Context, args sleepArgs) (interface{}, error) {
+		time.Sleep(time.Duration(args.Millis) * time.Millisecond)
+		return "done", nil
+	})
+
+	// With no history the ceiling applies
+	isError, text := callSleepTool(t, s, 1, 200)
+	if !isError || !strings.Contains(text, "timed out after 50ms") {
+		t.Fatalf("Expected ceiling timeout, got isError=%v text=%q", isError, text)
+	}
+}
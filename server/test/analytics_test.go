@@ -0,0 +1,118 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/server"
+)
+
+func callTool(t *testing.T, s server.Server, id int, name string) {
+	t.Helper()
+
+	request := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":%q,"arguments":{}}}`, id, name)
+	if _, err := server.HandleMessage(s.GetServer(), []byte(request)); err != nil {
+		t.Fatalf("Failed to call tool %s: %v", name, err)
+	}
+}
+
+func TestUsageAnalytics(t *testing.T) {
+	s := server.NewServer("analytics-server", server.WithUsageAnalytics(time.Minute, 5))
+
+	s.Tool("fast", "Fast tool", func(ctx *server.Context, args interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	s.Tool("slow", "Slow tool", func(ctx *server.Context, args interface{}) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		return "ok", nil
+	})
+	s.Tool("flaky", "Failing tool", func(ctx *server.Context, args interface{}) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+
+	for i := 0; i < 3; i++ {
+		callTool(t, s, i+1, "fast")
+	}
+	callTool(t, s, 10, "slow")
+	callTool(t, s, 11, "flaky")
+	callTool(t, s, 12, "flaky")
+
+	report := s.GetServer().UsageReport()
+	if len(report.Tools) != 3 {
+		t.Fatalf("Expected 3 tools in report, got %+v", report.Tools)
+	}
+
+	byName := make(map[string]server.ToolUsage)
+	for _, usage := range report.Tools {
+		byName[usage.Name] = usage
+	}
+
+	if byName["fast"].Calls != 3 || byName["fast"].Errors != 0 {
+		t.Errorf("Unexpected stats for fast: %+v", byName["fast"])
+	}
+	if byName["slow"].P95Latency < 20*time.Millisecond {
+		t.Errorf("Expected slow p95 of at least 20ms, got %s", byName["slow"].P95Latency)
+	}
+	if byName["flaky"].ErrorRate != 1 {
+		t.Errorf("Expected flaky error rate 1, got %f", byName["flaky"].ErrorRate)
+	}
+	if byName["fast"].LastCalled.IsZero() || byName["fast"].UniqueSessions != 1 {
+		t.Errorf("Expected last call time and one session for fast: %+v", byName["fast"])
+	}
+
+	// The report is also available as a resource
+	request := fmt.Sprintf(`{"jsonrpc":"2.0","id":20,"method":"resources/read","params":{"uri":%q}}`, server.UsageResourceURI)
+	responseBytes, err := server.HandleMessage(s.GetServer(), []byte(request))
+	if err != nil {
+		t.Fatalf("Failed to read usage resource: %v", err)
+	}
+	if !strings.Contains(string(responseBytes), `\"name\":\"flaky\"`) {
+		t.Errorf("Expected usage resource to contain the report, got %s", responseBytes)
+	}
+
+	// Export writes the report as JSON
+	var buf bytes.Buffer
+	if err := s.GetServer().ExportUsage(&buf); err != nil {
+		t.Fatalf("ExportUsage failed: %v", err)
+	}
+	var exported server.UsageReport
+	if err := json.Unmarshal(buf.Bytes(), &exported); err != nil {
+		t.Fatalf("Failed to parse exported report: %v", err)
+	}
+	if len(exported.Tools) != 3 {
+		t.Errorf("Expected 3 tools in exported report, got %d", len(exported.Tools))
+	}
+
+	s.GetServer().ResetUsage()
+	if tools := s.GetServer().UsageReport().Tools; len(tools) != 0 {
+		t.Errorf("Expected empty report after reset, got %+v", tools)
+	}
+}
+
+func TestUsageAnalyticsDisabled(t *testing.T) {
+	s := server.NewServer("plain-server")
+
+	s.Tool("fast", "Fast tool", func(ctx *server.Context, args interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	callTool(t, s, 1, "fast")
+
+	if tools := s.GetServer().UsageReport().Tools; len(tools) != 0 {
+		t.Errorf("Expected empty report when analytics are disabled, got %+v", tools)
+	}
+
+	resources, err := s.ListResources()
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+	for _, resource := range resources {
+		if resource.URI == server.UsageResourceURI {
+			t.Error("Usage resource should not be registered when analytics are disabled")
+		}
+	}
+}
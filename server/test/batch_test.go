@@ -3,7 +3,10 @@ package test
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/localrivet/gomcp/server"
 )
@@ -483,3 +486,75 @@ func TestBatchContextIsolation(t *testing.T) {
 		}
 	}
 }
+
+// TestBatchConcurrency tests that batch items run concurrently up to the configured
+// limit while responses keep the order of the requests
+func TestBatchConcurrency(t *testing.T) {
+	newServer := func(limit int) (server.Server, *int32) {
+		var running, peak int32
+		srv := server.NewServer("test-batch-concurrency", server.WithBatchConcurrency(limit))
+		srv.Tool("wait", "Wait briefly", func(ctx *server.Context, args struct {
+			Ms int `json:"ms"`
+		}) (string, error) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Duration(args.Ms) * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return fmt.Sprintf("waited %d", args.Ms), nil
+		})
+		return srv, &peak
+	}
+
+	// Later items finish first, yet their responses come last
+	var batch []string
+	for i := 0; i < 6; i++ {
+		batch = append(batch, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"wait","arguments":{"ms":%d}}}`, i, 120-20*i))
+	}
+	batch = append(batch, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":99}}`, `42`)
+	message := []byte("[" + strings.Join(batch, ",") + "]")
+
+	for _, tt := range []struct {
+		limit int
+		peak  int32
+	}{{limit: 1, peak: 1}, {limit: 3, peak: 3}, {limit: 0, peak: 6}} {
+		srv, peak := newServer(tt.limit)
+		responseBytes, err := server.HandleMessage(srv.GetServer(), message)
+		if err != nil {
+			t.Fatalf("HandleMessage returned error: %v", err)
+		}
+
+		var responses []struct {
+			ID     interface{} `json:"id"`
+			Result *struct {
+				Content []struct {
+					Text string `json:"text"`
+				} `json:"content"`
+			} `json:"result"`
+			Error *struct {
+				Code int `json:"code"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(responseBytes, &responses); err != nil {
+			t.Fatalf("Failed to parse batch response: %v", err)
+		}
+		if len(responses) != 7 {
+			t.Fatalf("Expected 6 results and 1 error without the notification, got %s", responseBytes)
+		}
+		for i, response := range responses[:6] {
+			if response.ID != float64(i) || response.Result == nil || response.Result.Content[0].Text != fmt.Sprintf("waited %d", 120-20*i) {
+				t.Errorf("Response %d out of order: %+v", i, response)
+			}
+		}
+		if last := responses[6]; last.ID != nil || last.Error == nil || last.Error.Code != -32600 {
+			t.Errorf("Expected an invalid request error for the non-object item, got %+v", last)
+		}
+		if got := atomic.LoadInt32(peak); got != tt.peak {
+			t.Errorf("With a limit of %d, expected %d items at once, got %d", tt.limit, tt.peak, got)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/localrivet/gomcp/transport"
+)
+
+// handlerTransport is a mock transport that keeps the message handler it is given
+type handlerTransport struct {
+	*MockTransport
+	handler transport.MessageHandler
+}
+
+func (h *handlerTransport) SetMessageHandler(handler transport.MessageHandler) {
+	h.handler = handler
+}
+
+// stubCore is a Server wrapper that only implements the lifecycle
+type stubCore struct {
+	runs int
+}
+
+func (c *stubCore) Run() error                                { c.runs++; return nil }
+func (c *stubCore) Serve(ctx context.Context) error           { return c.Run() }
+func (c *stubCore) Shutdown() error                           { return nil }
+func (c *stubCore) ShutdownContext(ctx context.Context) error { return nil }
+
+func TestConfigureCustomTransport(t *testing.T) {
+	custom := &handlerTransport{MockTransport: NewMockTransport()}
+	s := server.NewServer("configured", server.Configure(server.CustomTransport(custom)))
+	s.Tool("echo", "Echo", func(ctx *server.Context, args struct{}) (interface{}, error) {
+		return "ok", nil
+	})
+
+	if custom.handler == nil {
+		t.Fatal("Expected the server to install its message handler on the custom transport")
+	}
+
+	response, err := custom.handler([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list","params":{}}`))
+	if err != nil {
+		t.Fatalf("Failed to handle message: %v", err)
+	}
+	if !strings.Contains(string(response), `"echo"`) {
+		t.Fatalf("Expected the tool list through the custom transport, got %s", response)
+	}
+
+	// The fluent method switches transports after construction
+	other := &handlerTransport{MockTransport: NewMockTransport()}
+	if s.Configure(server.CustomTransport(other)) != s {
+		t.Fatal("Expected Configure to return the server for chaining")
+	}
+	if other.handler == nil {
+		t.Fatal("Expected Configure to install the message handler on the new transport")
+	}
+}
+
+func TestCoreAcceptsServersAndWrappers(t *testing.T) {
+	runAll := func(cores ...server.Core) {
+		for _, core := range cores {
+			core.Run()
+		}
+	}
+
+	stub := &stubCore{}
+	var s server.Core = server.NewServer("core")
+	if s == nil {
+		t.Fatal("Expected a Server to be usable as a Core")
+	}
+
+	runAll(stub)
+	if stub.runs != 1 {
+		t.Fatalf("Expected the wrapper to run once, got %d", stub.runs)
+	}
+}
@@ -35,9 +35,9 @@ func TestInitializeResponse_Draft(t *testing.T) {
 			expectedVersion: "draft",
 		},
 		{
-			name:            "latest version request in draft context",
+			name:            "latest version request after draft client",
 			clientVersion:   "latest",
-			expectedVersion: "draft", // In draft test context, latest resolves to draft
+			expectedVersion: "2025-03-26", // Each client negotiates its own version; latest is the newest stable one
 		},
 	}
 
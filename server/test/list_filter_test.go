@@ -0,0 +1,199 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/localrivet/gomcp/server"
+)
+
+// listToolNames sends a tools/list request with the given params and returns the tool names and next cursor.
+func listToolNames(t *testing.T, s server.Server, params string) ([]string, string) {
+	t.Helper()
+
+	request := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/list","params":%s}`, params)
+	responseBytes, err := server.HandleMessage(s.GetServer(), []byte(request))
+	if err != nil {
+		t.Fatalf("Failed to process tools/list request: %v", err)
+	}
+
+	var response struct {
+		Result struct {
+			Tools []struct {
+				Name        string                 `json:"name"`
+				Annotations map[string]interface{} `json:"annotations"`
+			} `json:"tools"`
+			NextCursor string `json:"nextCursor"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(responseBytes, &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	names := make([]string, 0, len(response.Result.Tools))
+	for _, tool := range response.Result.Tools {
+		names = append(names, tool.Name)
+	}
+	return names, response.Result.NextCursor
+}
+
+func TestToolListFilter(t *testing.T) {
+	s := server.NewServer("filter-server")
+
+	handler := func(ctx *server.Context, args interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	s.Tool("fs_read", "Read a file", handler, server.WithTags("files", "read-only"))
+	s.Tool("fs_write", "Write a file", handler, server.WithTags("files"))
+	s.Tool("http_get", "Fetch a URL", handler, server.WithTags("network", "read-only"))
+
+	testCases := []struct {
+		name   string
+		params string
+		want   []string
+	}{
+		{"no filter", `{}`, []string{"fs_read", "fs_write", "http_get"}},
+		{"name prefix", `{"filter":{"namePrefix":"fs_"}}`, []string{"fs_read", "fs_write"}},
+		{"single tag", `{"filter":{"tags":["read-only"]}}`, []string{"fs_read", "http_get"}},
+		{"prefix and tag", `{"filter":{"namePrefix":"fs_","tags":["read-only"]}}`, []string{"fs_read"}},
+		{"no match", `{"filter":{"tags":["database"]}}`, []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			names, _ := listToolNames(t, s, tc.params)
+			if fmt.Sprint(names) != fmt.Sprint(tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, names)
+			}
+		})
+	}
+}
+
+func TestToolListPaginationIsStable(t *testing.T) {
+	s := server.NewServer("paging-server")
+
+	handler := func(ctx *server.Context, args interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	for i := 0; i < 120; i++ {
+		s.Tool(fmt.Sprintf("tool_%03d", i), "Numbered tool", handler)
+	}
+
+	seen := make(map[string]bool)
+	cursor := ""
+	for page := 0; page < 10; page++ {
+		params := `{}`
+		if cursor != "" {
+			params = fmt.Sprintf(`{"cursor":%q}`, cursor)
+		}
+
+		names, next := listToolNames(t, s, params)
+		for _, name := range names {
+			if seen[name] {
+				t.Fatalf("Tool %s returned on more than one page", name)
+			}
+			seen[name] = true
+		}
+
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	if len(seen) != 120 {
+		t.Errorf("Expected 120 tools across pages, got %d", len(seen))
+	}
+}
+
+func TestResourceAndPromptTags(t *testing.T) {
+	s := server.NewServer("tag-server")
+
+	resourceHandler := func(ctx *server.Context, args interface{}) (interface{}, error) {
+		return "content", nil
+	}
+
+	s.Resource("/docs/readme", "Project readme", resourceHandler, server.WithTags("docs"))
+	s.Resource("/config/app", "App config", resourceHandler, server.WithTags("config", "read-only"))
+	s.Prompt("review", "Review code", server.User("Review {{code}}"))
+	s.GetServer().TagPrompt("review", "code")
+	s.Prompt("summarize", "Summarize text", server.User("Summarize {{text}}"))
+
+	resources, err := s.ListResources()
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+	for _, resource := range resources {
+		if resource.URI == "/config/app" && fmt.Sprint(mcp.TagsFromAnnotations(resource.Annotations)) != "[config read-only]" {
+			t.Errorf("Expected config resource tags in list, got %v", resource.Annotations)
+		}
+	}
+
+	request := `{"jsonrpc":"2.0","id":1,"method":"resources/list","params":{"filter":{"tags":["docs"]}}}`
+	responseBytes, err := server.HandleMessage(s.GetServer(), []byte(request))
+	if err != nil {
+		t.Fatalf("Failed to process resources/list: %v", err)
+	}
+	var resourceResponse struct {
+		Result struct {
+			Resources []struct {
+				URI string `json:"uri"`
+			} `json:"resources"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(responseBytes, &resourceResponse); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(resourceResponse.Result.Resources) != 1 || resourceResponse.Result.Resources[0].URI != "/docs/readme" {
+		t.Errorf("Expected only /docs/readme, got %+v", resourceResponse.Result.Resources)
+	}
+
+	request = `{"jsonrpc":"2.0","id":2,"method":"prompts/list","params":{"filter":{"tags":["code"]}}}`
+	responseBytes, err = server.HandleMessage(s.GetServer(), []byte(request))
+	if err != nil {
+		t.Fatalf("Failed to process prompts/list: %v", err)
+	}
+	var promptResponse struct {
+		Result struct {
+			Prompts []struct {
+				Name        string                 `json:"name"`
+				Annotations map[string]interface{} `json:"annotations"`
+			} `json:"prompts"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(responseBytes, &promptResponse); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(promptResponse.Result.Prompts) != 1 || promptResponse.Result.Prompts[0].Name != "review" {
+		t.Fatalf("Expected only the review prompt, got %+v", promptResponse.Result.Prompts)
+	}
+	if !mcp.HasAllTags(promptResponse.Result.Prompts[0].Annotations, []string{"code"}) {
+		t.Errorf("Expected review prompt to expose its tags, got %v", promptResponse.Result.Prompts[0].Annotations)
+	}
+}
+
+func TestWithTagsMergesAcrossAnnotationMaps(t *testing.T) {
+	s := server.NewServer("merge-server")
+
+	s.Tool("annotated", "Annotated tool", func(ctx *server.Context, args interface{}) (interface{}, error) {
+		return "ok", nil
+	}, server.WithTags("files"), map[string]interface{}{"readOnlyHint": true}, server.WithTags("search"))
+
+	tools, err := s.ListTools()
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(tools) != 1 {
+		t.Fatalf("Expected 1 tool, got %d", len(tools))
+	}
+	if fmt.Sprint(tools[0].Tags()) != "[files search]" {
+		t.Errorf("Expected merged tags, got %v", tools[0].Tags())
+	}
+	if tools[0].Annotations["readOnlyHint"] != true {
+		t.Errorf("Expected other annotations to be kept, got %v", tools[0].Annotations)
+	}
+}
@@ -2,6 +2,8 @@ package test
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/localrivet/gomcp/server"
@@ -358,3 +360,89 @@ func TestPromptList(t *testing.T) {
 		}
 	}
 }
+
+func TestPromptImageAndResourceContent(t *testing.T) {
+	s := server.NewServer("test-server")
+	s.Resource("/docs/{name}", "A document", func(ctx *server.Context, args interface{}) (interface{}, error) {
+		params, _ := args.(map[string]interface{})
+		return fmt.Sprintf("Contents of %v", params["name"]), nil
+	})
+
+	// A 1x1 PNG
+	const png = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mNkYAAAAAYAAjCB0C8AAAAASUVORK5CYII="
+	s.Prompt("describe", "Describe an image against a document",
+		server.UserImage("{{image}}"),
+		server.AssistantImage("data:image/webp;base64,"+png),
+		server.UserResource("/docs/{{doc}}"),
+		server.User("What does the image show?"),
+	)
+
+	responseBytes, err := server.HandleMessage(s.GetServer(), []byte(`{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"describe","arguments":{"image":"`+png+`","doc":"guide"}}}`))
+	if err != nil {
+		t.Fatalf("Failed to process prompts/get request: %v", err)
+	}
+
+	var response struct {
+		Result struct {
+			Messages []struct {
+				Role    string                 `json:"role"`
+				Content map[string]interface{} `json:"content"`
+			} `json:"messages"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(responseBytes, &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	messages := response.Result.Messages
+	if len(messages) != 4 {
+		t.Fatalf("Expected 4 messages, got %d: %s", len(messages), responseBytes)
+	}
+
+	// Image content carries the data and its detected or declared MIME type, and no text
+	image := messages[0].Content
+	if image["type"] != "image" || image["data"] != png || image["mimeType"] != "image/png" {
+		t.Errorf("Unexpected image content: %v", image)
+	}
+	if _, hasText := image["text"]; hasText {
+		t.Errorf("Expected image content without text, got %v", image)
+	}
+	if messages[1].Role != "assistant" || messages[1].Content["mimeType"] != "image/webp" {
+		t.Errorf("Expected the data URL's MIME type, got %v", messages[1].Content)
+	}
+
+	// Resource content embeds the resource as read
+	if messages[2].Content["type"] != "resource" {
+		t.Fatalf("Expected resource content, got %v", messages[2].Content)
+	}
+	resource, _ := messages[2].Content["resource"].(map[string]interface{})
+	if resource["uri"] != "/docs/guide" || resource["text"] != "Contents of guide" {
+		t.Errorf("Unexpected embedded resource: %v", resource)
+	}
+
+	if messages[3].Content["type"] != "text" || messages[3].Content["text"] != "What does the image show?" {
+		t.Errorf("Unexpected text content: %v", messages[3].Content)
+	}
+
+	// Data that is not an image, and resources that do not exist, are errors
+	for _, params := range []string{
+		`{"name":"describe","arguments":{"image":"aGVsbG8gd29ybGQ=","doc":"guide"}}`,
+		`{"name":"describe","arguments":{"image":"not base64!","doc":"guide"}}`,
+	} {
+		responseBytes, err := server.HandleMessage(s.GetServer(), []byte(`{"jsonrpc":"2.0","id":2,"method":"prompts/get","params":`+params+`}`))
+		if err != nil {
+			t.Fatalf("Failed to process prompts/get request: %v", err)
+		}
+		if !strings.Contains(string(responseBytes), `"error"`) {
+			t.Errorf("Expected an error for %s, got %s", params, responseBytes)
+		}
+	}
+
+	s.Prompt("missing", "Embeds a resource that does not exist", server.UserResource("/nowhere"))
+	responseBytes, err = server.HandleMessage(s.GetServer(), []byte(`{"jsonrpc":"2.0","id":3,"method":"prompts/get","params":{"name":"missing"}}`))
+	if err != nil {
+		t.Fatalf("Failed to process prompts/get request: %v", err)
+	}
+	if !strings.Contains(string(responseBytes), `"error"`) {
+		t.Errorf("Expected an error for a missing resource, got %s", responseBytes)
+	}
+}
@@ -0,0 +1,191 @@
+package test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/localrivet/gomcp/server"
+)
+
+func TestSamplingCache(t *testing.T) {
+	config := server.NewDefaultSamplingConfig().WithCache(nil, time.Minute)
+	s, attempts := newScriptedSamplingServer(t, config, func(n int) string { return samplingOK })
+	impl := s.GetServer()
+
+	for i := 0; i < 3; i++ {
+		response, err := impl.RequestSamplingWithOptions(retryTestMessages, server.SamplingModelPreferences{}, "", 50, retryTestOptions())
+		if err != nil {
+			t.Fatalf("RequestSamplingWithOptions failed: %v", err)
+		}
+		if response.Content.Text != "ok" {
+			t.Errorf("Expected the cached response, got %q", response.Content.Text)
+		}
+	}
+	if got := atomic.LoadInt32(attempts); got != 1 {
+		t.Errorf("Expected identical requests to be answered from the cache, got %d round trips", got)
+	}
+
+	// Other parameters miss the cache
+	if _, err := impl.RequestSamplingWithOptions(retryTestMessages, server.SamplingModelPreferences{}, "", 60, retryTestOptions()); err != nil {
+		t.Fatalf("RequestSamplingWithOptions failed: %v", err)
+	}
+
+	// Requests that include the client's context are never cached
+	options := retryTestOptions()
+	options.IncludeContext = mcp.IncludeContextThisServer
+	for i := 0; i < 2; i++ {
+		if _, err := impl.RequestSamplingWithOptions(retryTestMessages, server.SamplingModelPreferences{}, "", 50, options); err != nil {
+			t.Fatalf("RequestSamplingWithOptions failed: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(attempts); got != 4 {
+		t.Errorf("Expected 4 round trips, got %d", got)
+	}
+
+	metrics := s.GetServer().SamplingMetrics()
+	if metrics.Requests != 6 || metrics.CacheHits != 2 || metrics.CacheMisses != 2 || metrics.RoundTrips != 4 {
+		t.Errorf("Unexpected metrics: %+v", metrics)
+	}
+}
+
+func TestSamplingDeduplication(t *testing.T) {
+	release := make(chan struct{})
+	config := server.NewDefaultSamplingConfig().WithDeduplication()
+	s, attempts := newScriptedSamplingServer(t, config, func(n int) string {
+		<-release
+		return samplingOK
+	})
+	impl := s.GetServer()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := impl.RequestSamplingWithOptions(retryTestMessages, server.SamplingModelPreferences{}, "", 50, retryTestOptions())
+			if err == nil && response.Content.Text != "ok" {
+				err = errors.New("unexpected response " + response.Content.Text)
+			}
+			errs <- err
+		}()
+	}
+
+	// Answer once the followers wait for the first request
+	deadline := time.Now().Add(time.Second)
+	for s.GetServer().SamplingMetrics().Deduplicated < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected identical requests to wait for the pending one, got %+v", s.GetServer().SamplingMetrics())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("RequestSamplingWithOptions failed: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(attempts); got != 1 {
+		t.Errorf("Expected a single round trip, got %d", got)
+	}
+}
+
+func TestSamplingConcurrencyLimits(t *testing.T) {
+	release := make(chan struct{})
+	config := server.NewDefaultSamplingConfig()
+	config.MaxConcurrentRequests = 1
+	config.MaxRequestsPerMinute = 2
+	s, _ := newScriptedSamplingServer(t, config, func(n int) string {
+		if n == 0 {
+			<-release
+		}
+		return samplingOK
+	})
+	impl := s.GetServer()
+
+	done := make(chan error, 1)
+	go func() {
+		options := retryTestOptions()
+		options.Timeout = time.Second
+		_, err := impl.RequestSamplingWithOptions(retryTestMessages, server.SamplingModelPreferences{}, "", 50, options)
+		done <- err
+	}()
+	deadline := time.Now().Add(time.Second)
+	for s.GetServer().SamplingMetrics().InFlight != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the first request to be in flight")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The only slot is taken
+	_, err := impl.RequestSamplingWithOptions(retryTestMessages, server.SamplingModelPreferences{}, "", 60, retryTestOptions())
+	if !errors.Is(err, server.ErrSamplingBusy) {
+		t.Errorf("Expected ErrSamplingBusy, got %v", err)
+	}
+
+	// Waiting requests take the slot once it is released
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	options := retryTestOptions()
+	options.Timeout = time.Second
+	if _, err := impl.RequestSamplingWithOptions(retryTestMessages, server.SamplingModelPreferences{}, "", 70, options); err != nil {
+		t.Errorf("Expected the queued request to succeed, got %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("RequestSamplingWithOptions failed: %v", err)
+	}
+
+	// Both requests of the minute are used up
+	_, err = impl.RequestSamplingWithOptions(retryTestMessages, server.SamplingModelPreferences{}, "", 80, retryTestOptions())
+	if !errors.Is(err, server.ErrSamplingRateLimited) {
+		t.Errorf("Expected ErrSamplingRateLimited, got %v", err)
+	}
+
+	metrics := s.GetServer().SamplingMetrics()
+	if metrics.Queued != 2 || metrics.Rejected != 1 || metrics.RateLimited != 1 || metrics.InFlight != 0 {
+		t.Errorf("Unexpected metrics: %+v", metrics)
+	}
+}
+
+func TestMemorySamplingCache(t *testing.T) {
+	cache := server.NewMemorySamplingCache(2)
+	response := func(text string) *server.SamplingResponse {
+		return &server.SamplingResponse{Role: "assistant", Content: server.SamplingMessageContent{Type: "text", Text: text}}
+	}
+
+	cache.Set("a", response("a"), time.Minute)
+	cache.Set("b", response("b"), time.Minute)
+	if _, found, _ := cache.Get("a"); !found {
+		t.Fatal("Expected a to be cached")
+	}
+
+	// The least recently used response is evicted
+	cache.Set("c", response("c"), time.Minute)
+	if _, found, _ := cache.Get("b"); found {
+		t.Error("Expected b to be evicted")
+	}
+	if got, found, _ := cache.Get("a"); !found || got.Content.Text != "a" {
+		t.Errorf("Expected a to be kept, got %+v", got)
+	}
+
+	// Expired responses are not returned
+	cache.Set("d", response("d"), -time.Second)
+	if _, found, _ := cache.Get("d"); found {
+		t.Error("Expected d to be expired")
+	}
+
+	cache.Delete("a")
+	if cache.Len() != 0 {
+		t.Errorf("Expected an empty cache, got %d responses", cache.Len())
+	}
+}
@@ -0,0 +1,120 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/localrivet/gomcp/server"
+)
+
+func TestSamplingGenerationParameters(t *testing.T) {
+	s := server.NewServer("sampling-params-server")
+	impl := s.GetServer()
+
+	sent := make(chan server.SamplingCreateMessageParams, 1)
+	transport := NewMockTransport()
+	transport.SetSendFunc(func(data []byte) error {
+		var request struct {
+			ID     int64                              `json:"id"`
+			Params server.SamplingCreateMessageParams `json:"params"`
+		}
+		if err := json.Unmarshal(data, &request); err != nil {
+			return err
+		}
+		sent <- request.Params
+
+		// Reply asynchronously like a real client would
+		go impl.HandleJSONRPCResponse([]byte(fmt.Sprintf(
+			`{"jsonrpc":"2.0","id":%d,"result":{"role":"assistant","content":{"type":"text","text":"ok"}}}`, request.ID)))
+		return nil
+	})
+	impl.SetTransport(transport)
+
+	temperature := 0.3
+	options := server.DefaultSamplingOptions()
+	options.IgnoreCapability = true
+	options.Temperature = &temperature
+	options.StopSequences = []string{"\n\n", "END"}
+	options.Metadata = map[string]interface{}{"provider": map[string]interface{}{"topK": float64(40)}}
+
+	messages := []server.SamplingMessage{server.CreateTextSamplingMessage("user", "hello")}
+	if _, err := impl.RequestSamplingWithOptions(messages, server.SamplingModelPreferences{}, "", 50, options); err != nil {
+		t.Fatalf("RequestSamplingWithOptions failed: %v", err)
+	}
+
+	params := <-sent
+	if params.Temperature == nil || *params.Temperature != 0.3 {
+		t.Errorf("Expected temperature 0.3, got %v", params.Temperature)
+	}
+	if len(params.StopSequences) != 2 || params.StopSequences[1] != "END" {
+		t.Errorf("Expected stop sequences to be passed through, got %v", params.StopSequences)
+	}
+	provider, _ := params.Metadata["provider"].(map[string]interface{})
+	if provider["topK"] != float64(40) {
+		t.Errorf("Expected metadata to be passed through untouched, got %v", params.Metadata)
+	}
+
+	// Invalid parameters are rejected before anything is sent
+	invalid := 3.5
+	options.Temperature = &invalid
+	_, err := impl.RequestSamplingWithOptions(messages, server.SamplingModelPreferences{}, "", 50, options)
+	if err == nil || !strings.Contains(err.Error(), "temperature") {
+		t.Errorf("Expected temperature validation error, got %v", err)
+	}
+
+	options.Temperature = nil
+	options.StopSequences = []string{""}
+	if _, err := impl.RequestSamplingWithOptions(messages, server.SamplingModelPreferences{}, "", 50, options); err == nil {
+		t.Error("Expected error for empty stop sequence")
+	}
+}
+
+func TestSamplingIncludeContext(t *testing.T) {
+	s := server.NewServer("sampling-context-server")
+	impl := s.GetServer()
+
+	sent := make(chan []byte, 1)
+	transport := NewMockTransport()
+	transport.SetSendFunc(func(data []byte) error {
+		var request struct {
+			ID int64 `json:"id"`
+		}
+		if err := json.Unmarshal(data, &request); err != nil {
+			return err
+		}
+		sent <- data
+
+		go impl.HandleJSONRPCResponse([]byte(fmt.Sprintf(
+			`{"jsonrpc":"2.0","id":%d,"result":{"role":"assistant","content":{"type":"text","text":"ok"}}}`, request.ID)))
+		return nil
+	})
+	impl.SetTransport(transport)
+
+	messages := []server.SamplingMessage{server.CreateTextSamplingMessage("user", "hello")}
+	options := server.DefaultSamplingOptions()
+	options.IgnoreCapability = true
+
+	// Omitted by default
+	if _, err := impl.RequestSamplingWithOptions(messages, server.SamplingModelPreferences{}, "", 50, options); err != nil {
+		t.Fatalf("RequestSamplingWithOptions failed: %v", err)
+	}
+	if data := <-sent; strings.Contains(string(data), "includeContext") {
+		t.Errorf("Expected includeContext to be omitted, got %s", data)
+	}
+
+	options.IncludeContext = mcp.IncludeContextThisServer
+	if _, err := impl.RequestSamplingWithOptions(messages, server.SamplingModelPreferences{}, "", 50, options); err != nil {
+		t.Fatalf("RequestSamplingWithOptions failed: %v", err)
+	}
+	if data := <-sent; !strings.Contains(string(data), `"includeContext":"thisServer"`) {
+		t.Errorf("Expected includeContext thisServer, got %s", data)
+	}
+
+	options.IncludeContext = "everything"
+	if _, err := impl.RequestSamplingWithOptions(messages, server.SamplingModelPreferences{}, "", 50, options); err == nil {
+		t.Error("Expected error for unknown includeContext value")
+	}
+}
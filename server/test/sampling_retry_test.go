@@ -0,0 +1,162 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/server"
+)
+
+// newScriptedSamplingServer returns a server whose client answers the n-th sampling
+// request (starting at 0) with the result of reply, and a counter of requests sent
+func newScriptedSamplingServer(t *testing.T, config *server.SamplingConfig, reply func(n int) string) (server.Server, *int32) {
+	t.Helper()
+
+	options := []server.Option{}
+	if config != nil {
+		options = append(options, server.WithSamplingConfig(config))
+	}
+	s := server.NewServer("sampling-retry-server", options...)
+	impl := s.GetServer()
+
+	var attempts int32
+	transport := NewMockTransport()
+	transport.SetSendFunc(func(data []byte) error {
+		var request struct {
+			ID int64 `json:"id"`
+		}
+		if err := json.Unmarshal(data, &request); err != nil {
+			return err
+		}
+		n := int(atomic.AddInt32(&attempts, 1)) - 1
+
+		response := reply(n)
+		if response == "" {
+			// Never answer, so the request times out
+			return nil
+		}
+		go impl.HandleJSONRPCResponse([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,%s}`, request.ID, response)))
+		return nil
+	})
+	impl.SetTransport(transport)
+
+	return s, &attempts
+}
+
+const (
+	samplingOK          = `"result":{"role":"assistant","content":{"type":"text","text":"ok"}}`
+	samplingRateLimited = `"error":{"code":-32000,"message":"rate limit exceeded"}`
+	samplingRejected    = `"error":{"code":-1,"message":"User rejected sampling request"}`
+)
+
+func retryTestOptions() server.RequestSamplingOptions {
+	options := server.DefaultSamplingOptions()
+	options.IgnoreCapability = true
+	options.Timeout = 50 * time.Millisecond
+	return options
+}
+
+var retryTestMessages = []server.SamplingMessage{server.CreateTextSamplingMessage("user", "hello")}
+
+func TestSamplingRetryPolicy(t *testing.T) {
+	config := server.NewDefaultSamplingConfig().WithRetry(3, time.Millisecond, nil)
+	config.GracefulDegradation = false
+
+	s, attempts := newScriptedSamplingServer(t, config, func(n int) string {
+		switch n {
+		case 0:
+			return samplingRateLimited
+		case 1:
+			return "" // timeout
+		case 2:
+			return `"result":{"role":"assistant","content":{"type":"text","text":"slow down"},"stopReason":"rate_limited"}`
+		default:
+			return samplingOK
+		}
+	})
+
+	response, err := s.GetServer().RequestSamplingWithOptions(retryTestMessages, server.SamplingModelPreferences{}, "", 50, retryTestOptions())
+	if err != nil {
+		t.Fatalf("Expected transient failures to be retried, got %v", err)
+	}
+	if response.Content.Text != "ok" {
+		t.Errorf("Expected final response, got %q", response.Content.Text)
+	}
+	if got := atomic.LoadInt32(attempts); got != 4 {
+		t.Errorf("Expected 4 attempts, got %d", got)
+	}
+}
+
+func TestSamplingRetryDoesNotRetryPermanentErrors(t *testing.T) {
+	config := server.NewDefaultSamplingConfig().WithRetry(3, time.Millisecond, nil)
+	s, attempts := newScriptedSamplingServer(t, config, func(n int) string {
+		return samplingRejected
+	})
+
+	_, err := s.GetServer().RequestSamplingWithOptions(retryTestMessages, server.SamplingModelPreferences{}, "", 50, retryTestOptions())
+	var clientErr *server.SamplingClientError
+	if !errors.As(err, &clientErr) || clientErr.Code != -1 {
+		t.Fatalf("Expected client error, got %v", err)
+	}
+	if got := atomic.LoadInt32(attempts); got != 1 {
+		t.Errorf("Expected a single attempt, got %d", got)
+	}
+}
+
+func TestSamplingRetryPerRequestOverride(t *testing.T) {
+	config := server.NewDefaultSamplingConfig().WithRetry(3, time.Millisecond, nil)
+	s, attempts := newScriptedSamplingServer(t, config, func(n int) string {
+		if n < 2 {
+			return samplingRejected
+		}
+		return samplingOK
+	})
+
+	// This request retries any client error, but only once
+	options := retryTestOptions()
+	options.Retry = &server.SamplingRetryPolicy{
+		MaxRetries: 1,
+		RetryOn: func(response *server.SamplingResponse, err error) bool {
+			var clientErr *server.SamplingClientError
+			return errors.As(err, &clientErr)
+		},
+	}
+
+	_, err := s.GetServer().RequestSamplingWithOptions(retryTestMessages, server.SamplingModelPreferences{}, "", 50, options)
+	if err == nil {
+		t.Fatal("Expected error after exhausting the per-request retries")
+	}
+	if got := atomic.LoadInt32(attempts); got != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got)
+	}
+}
+
+func TestRetryTransientSamplingFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		response *server.SamplingResponse
+		err      error
+		want     bool
+	}{
+		{"timeout", nil, server.ErrSamplingTimeout, true},
+		{"wrapped timeout", nil, fmt.Errorf("attempt 1: %w", server.ErrSamplingTimeout), true},
+		{"server error code", nil, &server.SamplingClientError{Code: 503, Message: "unavailable"}, true},
+		{"rate limit message", nil, &server.SamplingClientError{Code: -32000, Message: "Rate limit exceeded"}, true},
+		{"rejected", nil, &server.SamplingClientError{Code: -1, Message: "User rejected sampling request"}, false},
+		{"other error", nil, errors.New("failed to send sampling request"), false},
+		{"rate limit stop reason", &server.SamplingResponse{StopReason: "rateLimit"}, nil, true},
+		{"end turn", &server.SamplingResponse{StopReason: "endTurn"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := server.RetryTransientSamplingFailures(tt.response, tt.err); got != tt.want {
+				t.Errorf("RetryTransientSamplingFailures() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/localrivet/gomcp/server"
+)
+
+type convertArgs struct {
+	Amount float64 `json:"amount"`
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+}
+
+func TestToolExamplesAreListed(t *testing.T) {
+	s := server.NewServer("examples-server")
+
+	s.Tool("convert", "Convert between currencies", func(ctx *server.Context, args convertArgs) (interface{}, error) {
+		return args.Amount, nil
+	},
+		server.WithExample(convertArgs{Amount: 10, From: "USD", To: "EUR"}, map[string]interface{}{"amount": 9.2}, "Convert dollars to euros"),
+		server.WithTags("finance"),
+		server.WithExample(convertArgs{Amount: 1, From: "EUR", To: "EUR"}, nil, ""),
+	)
+
+	responseBytes, err := server.HandleMessage(s.GetServer(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	if err != nil {
+		t.Fatalf("Failed to process tools/list request: %v", err)
+	}
+	var response struct {
+		Result struct {
+			Tools []mcp.Tool `json:"tools"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(responseBytes, &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(response.Result.Tools) != 1 {
+		t.Fatalf("Expected 1 tool, got %d", len(response.Result.Tools))
+	}
+	tool := response.Result.Tools[0]
+
+	// Examples from several annotation maps are combined, as tags are
+	examples := tool.Examples()
+	if len(examples) != 2 {
+		t.Fatalf("Expected 2 examples, got %+v", tool.Annotations)
+	}
+	if examples[0].Description != "Convert dollars to euros" {
+		t.Errorf("Expected the example's description, got %q", examples[0].Description)
+	}
+	input, _ := json.Marshal(examples[0].Input)
+	output, _ := json.Marshal(examples[0].Output)
+	if string(input) != `{"amount":10,"from":"USD","to":"EUR"}` || string(output) != `{"amount":9.2}` {
+		t.Errorf("Expected the example's input and output, got %s and %s", input, output)
+	}
+	if examples[1].Output != nil {
+		t.Errorf("Expected no output for the second example, got %v", examples[1].Output)
+	}
+	if len(tool.Tags()) != 1 {
+		t.Errorf("Expected tags to be kept, got %v", tool.Annotations)
+	}
+
+	// The inputs are also the examples of the input schema
+	schemaExamples, _ := json.Marshal(tool.InputSchema["examples"])
+	if string(schemaExamples) != `[{"amount":10,"from":"USD","to":"EUR"},{"amount":1,"from":"EUR","to":"EUR"}]` {
+		t.Errorf("Expected the example inputs in the input schema, got %s", schemaExamples)
+	}
+}
@@ -1,10 +1,12 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -80,12 +82,7 @@ func (s *serverImpl) Tool(name, description string, handler interface{}, annotat
 	}
 
 	// Merge all annotation maps
-	mergedAnnotations := make(map[string]interface{})
-	for _, annotationMap := range annotations {
-		for k, v := range annotationMap {
-			mergedAnnotations[k] = v
-		}
-	}
+	mergedAnnotations := mergeAnnotations(annotations...)
 
 	// Use the internal registerTool method to store the tool
 	s.registerTool(name, description, handlerFunc, schema, mergedAnnotations)
@@ -209,35 +206,174 @@ func (s *serverImpl) validateAndExtractToolHandler(handler interface{}) (interfa
 		// Validate and convert the arguments to the expected type
 		convertedArgs, err := schema.ValidateAndConvertArgs(schemaMap, argsMap, argsType)
 		if err != nil {
-			return nil, fmt.Errorf("argument validation failed: %w", err)
+			return nil, argumentError(err)
 		}
 
 		// Call the original handler using reflection with the converted args
-		results := handlerValue.Call([]reflect.Value{
-			reflect.ValueOf(ctx),
-			reflect.ValueOf(convertedArgs),
-		})
+		return callToolHandler(handlerValue, ctx, convertedArgs)
+	}
 
-		// Extract the results using simple interface{} - Go's dynamic typing
-		var resultValue interface{}
-		var errValue error
+	return wrappedHandler, schemaMap, nil
+}
 
-		// Get first return value (result)
-		if results[0].IsValid() && results[0].CanInterface() {
-			resultValue = results[0].Interface()
+// argumentError reports arguments that could not be bound to a tool's handler,
+// as an InvalidParametersError listing the violations if they broke the schema
+func argumentError(err error) error {
+	var violations schema.ValidationErrors
+	if errors.As(err, &violations) {
+		return &InvalidParametersError{
+			Message:    fmt.Sprintf("invalid arguments: %s", err),
+			Violations: violations,
 		}
+	}
+	return fmt.Errorf("argument validation failed: %w", err)
+}
 
-		// Get second return value (error)
-		if results[1].IsValid() && results[1].CanInterface() {
-			if err := results[1].Interface(); err != nil {
-				errValue = err.(error)
-			}
+// callToolHandler calls a tool handler through reflection and extracts its results
+func callToolHandler(handlerValue reflect.Value, ctx *Context, args interface{}) (interface{}, error) {
+	argsValue := reflect.ValueOf(args)
+	if args == nil {
+		argsValue = reflect.Zero(handlerValue.Type().In(1))
+	}
+	results := handlerValue.Call([]reflect.Value{
+		reflect.ValueOf(ctx),
+		argsValue,
+	})
+
+	// Extract the results using simple interface{} - Go's dynamic typing
+	var resultValue interface{}
+	var errValue error
+
+	// Get first return value (result)
+	if results[0].IsValid() && results[0].CanInterface() {
+		resultValue = results[0].Interface()
+	}
+
+	// Get second return value (error)
+	if results[1].IsValid() && results[1].CanInterface() {
+		if err := results[1].Interface(); err != nil {
+			errValue = err.(error)
 		}
+	}
 
-		return resultValue, errValue
+	return resultValue, errValue
+}
+
+// ToolWithSchema registers a tool with an explicit JSON Schema for its input.
+//
+// Use it when reflection and struct tags cannot express the constraints
+// clients need. The schema may be JSON text (a string, []byte or
+// json.RawMessage) or a map, and must describe an object. It is listed as the
+// tool's inputSchema and arguments are validated against it before the handler,
+// which takes the same form as for Tool or receives a map[string]interface{}, is
+// called.
+//
+// Example:
+//
+//	server.ToolWithSchema("calculate", "Perform a calculation",
+//	    `{"type":"object","properties":{"operation":{"type":"string","enum":["add","subtract"]}}}`,
+//	    func(ctx *Context, args map[string]interface{}) (interface{}, error) {
+//	        return calculate(args)
+//	    })
+func (s *serverImpl) ToolWithSchema(name, description string, inputSchema interface{}, handler interface{}, annotations ...map[string]interface{}) Server {
+	schemaMap, err := parseInputSchema(inputSchema)
+	if err != nil {
+		s.logger.Error("invalid tool schema", "name", name, "error", err)
+		return s
 	}
 
-	return wrappedHandler, schemaMap, nil
+	handlerFunc, err := wrapToolHandlerWithSchema(handler, schemaMap)
+	if err != nil {
+		s.logger.Error("invalid tool handler", "name", name, "error", err)
+		return s
+	}
+
+	s.registerTool(name, description, handlerFunc, schemaMap, mergeAnnotations(annotations...))
+	return s
+}
+
+// parseInputSchema decodes an explicit input schema into a map
+func parseInputSchema(inputSchema interface{}) (map[string]interface{}, error) {
+	var data []byte
+	switch v := inputSchema.(type) {
+	case nil:
+		return nil, errors.New("schema is required")
+	case string:
+		data = []byte(v)
+	case json.RawMessage:
+		data = v
+	case []byte:
+		data = v
+	default:
+		// Round trip maps and structs so that the schema holds plain JSON values
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode schema: %w", err)
+		}
+		data = encoded
+	}
+
+	var schemaMap map[string]interface{}
+	if err := json.Unmarshal(data, &schemaMap); err != nil {
+		return nil, fmt.Errorf("schema must be a JSON object: %w", err)
+	}
+	if schemaType, ok := schemaMap["type"]; ok && schemaType != "object" {
+		return nil, fmt.Errorf("schema must describe an object, got type %v", schemaType)
+	}
+	schemaMap["type"] = "object"
+	return schemaMap, nil
+}
+
+// wrapToolHandlerWithSchema adapts a handler to validate its arguments against an
+// explicit schema
+func wrapToolHandlerWithSchema(handler interface{}, schemaMap map[string]interface{}) (interface{}, error) {
+	if handler == nil {
+		return nil, errors.New("handler cannot be nil")
+	}
+	handlerValue := reflect.ValueOf(handler)
+	handlerType := handlerValue.Type()
+
+	if handlerType.Kind() != reflect.Func || handlerType.NumIn() != 2 || handlerType.NumOut() != 2 {
+		return nil, errors.New("handler must have signature: func(ctx *Context, args ArgsType) (interface{}, error)")
+	}
+	if handlerType.In(0) != reflect.TypeOf((*Context)(nil)) {
+		return nil, errors.New("first parameter must be *Context")
+	}
+	if !handlerType.Out(1).Implements(reflect.TypeOf((*error)(nil)).Elem()) {
+		return nil, errors.New("second return value must be error")
+	}
+
+	argsType := handlerType.In(1)
+	rawArgs := argsType == reflect.TypeOf(map[string]interface{}{}) ||
+		argsType == reflect.TypeOf((*interface{})(nil)).Elem()
+	isStruct := argsType.Kind() == reflect.Struct ||
+		(argsType.Kind() == reflect.Ptr && argsType.Elem().Kind() == reflect.Struct)
+	if !rawArgs && !isStruct {
+		return nil, fmt.Errorf("args parameter must be a struct, *struct or map[string]interface{}, got %s", argsType)
+	}
+
+	return func(ctx *Context, args interface{}) (interface{}, error) {
+		argsMap, ok := args.(map[string]interface{})
+		if !ok {
+			if args != nil {
+				return nil, fmt.Errorf("args must be a map[string]interface{}, got %T", args)
+			}
+			argsMap = map[string]interface{}{}
+		}
+
+		if rawArgs {
+			if err := schema.ValidateArgs(schemaMap, argsMap); err != nil {
+				return nil, argumentError(err)
+			}
+			return callToolHandler(handlerValue, ctx, argsMap)
+		}
+
+		convertedArgs, err := schema.ValidateAndConvertArgs(schemaMap, argsMap, argsType)
+		if err != nil {
+			return nil, argumentError(err)
+		}
+		return callToolHandler(handlerValue, ctx, convertedArgs)
+	}, nil
 }
 
 // registerTool is an internal method that stores a tool in the server's registry.
@@ -252,6 +388,8 @@ func (s *serverImpl) registerTool(name string, description string, handler inter
 		return
 	}
 
+	schema = schemaWithExamples(schema, annotations)
+
 	// Create the tool
 	tool := &Tool{
 		Name:        name,
@@ -284,40 +422,108 @@ func (s *serverImpl) registerTool(name string, description string, handler inter
 	s.logger.Debug("tool registered", "name", name, "description", description)
 }
 
+// RemoveTool unregisters a tool. It is safe to call while the server is running:
+// calls already in progress finish, later calls fail as for an unknown tool, and
+// clients are sent notifications/tools/list_changed.
+//
+// Example:
+//
+//	server.RemoveTool("calculator")
+func (s *serverImpl) RemoveTool(name string) Server {
+	s.mu.Lock()
+	_, exists := s.tools[name]
+	delete(s.tools, name)
+	remaining := len(s.tools) > 0
+	s.mu.Unlock()
+
+	if !exists {
+		s.logger.Error("cannot remove unknown tool", "name", name)
+		return s
+	}
+
+	// Emit tool unregistration event
+	go func() {
+		events.Publish[events.ToolUnregisteredEvent](s.events, events.TopicToolUnregistered, events.ToolUnregisteredEvent{
+			ToolName:       name,
+			UnregisteredAt: time.Now(),
+		})
+	}()
+
+	// Mark tools as changed and send the notification
+	s.capabilityCache.MarkToolsRemoved(remaining)
+	s.sendCapabilityNotification("tools")
+
+	s.logger.Debug("tool removed", "name", name)
+	return s
+}
+
+// ReplaceTool replaces a registered tool's description, handler and annotations
+// in a single step, so that no call finds the tool missing, and notifies clients
+// that the tool list changed. It takes the same parameters as Tool. Replacing a
+// tool that is not registered, or with an invalid handler, logs an error and
+// leaves the registry unchanged.
+//
+// Example:
+//
+//	server.ReplaceTool("calculator", "Perform calculations, now with powers", calculateV2)
+func (s *serverImpl) ReplaceTool(name, description string, handler interface{}, annotations ...map[string]interface{}) Server {
+	s.mu.RLock()
+	_, exists := s.tools[name]
+	s.mu.RUnlock()
+
+	if !exists {
+		s.logger.Error("cannot replace unknown tool", "name", name)
+		return s
+	}
+	return s.Tool(name, description, handler, annotations...)
+}
+
 // ProcessToolList processes a tool list request and returns the list of available tools.
-// It supports pagination through an optional cursor parameter.
+// It supports pagination through an optional cursor parameter and narrowing the
+// list through an optional filter parameter (see ListFilter).
 // The response includes the tools' name, description, and input schema.
 func (s *serverImpl) ProcessToolList(ctx *Context) (interface{}, error) {
-	// Get pagination cursor if provided
+	// Get pagination cursor and filter if provided
 	var cursor string
+	var filter *ListFilter
 	if ctx.Request.Params != nil {
 		var params struct {
-			Cursor string `json:"cursor"`
+			Cursor string      `json:"cursor"`
+			Filter *ListFilter `json:"filter,omitempty"`
 		}
 		if err := json.Unmarshal(ctx.Request.Params, &params); err != nil {
 			return nil, fmt.Errorf("invalid params: %w", err)
 		}
 		cursor = params.Cursor
+		filter = params.Filter
 	}
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	// For now, we'll use a simple pagination that returns all tools
-	// In a real implementation, you'd parse the cursor and limit results
+	// Iterate in name order so the cursor (the last name returned) is stable across pages
+	names := make([]string, 0, len(s.tools))
+	for name := range s.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
 	const maxPageSize = 50
 	var tools = make([]ToolInfo, 0, len(s.tools))
 	var nextCursor string
 
 	// Convert tools to the expected format
-	i := 0
-	for name, tool := range s.tools {
-		// If we have a cursor, skip until we find it
-		// This is a simplistic approach; real cursor would be more sophisticated
+	for _, name := range names {
+		// If we have a cursor, skip until we are past it
 		if cursor != "" && name <= cursor {
 			continue
 		}
 
+		tool := s.tools[name]
+		if !filter.Matches(tool.Name, tool.Annotations) || !s.visibleTo(ctx.Session, tool.Name) {
+			continue
+		}
+
 		// Add the tool to the result
 		toolInfo := ToolInfo{
 			Name:        tool.Name,
@@ -329,11 +535,11 @@ func (s *serverImpl) ProcessToolList(ctx *Context) (interface{}, error) {
 		if len(tool.Annotations) > 0 {
 			toolInfo.Annotations = tool.Annotations
 		}
+		toolInfo.Title, toolInfo.Icons = specDisplay(ctx, tool.Annotations)
 
 		tools = append(tools, toolInfo)
 
-		i++
-		if i >= maxPageSize {
+		if len(tools) >= maxPageSize {
 			// Set cursor for next page
 			nextCursor = name
 			break
@@ -353,8 +559,39 @@ func (s *serverImpl) executeTool(ctx *Context, name string, args map[string]inte
 	tool, exists := s.tools[name]
 	s.mu.RUnlock()
 
-	if !exists {
-		return nil, fmt.Errorf("tool not found: %s", name)
+	// Tools hidden from the session are refused as if they did not exist
+	if !exists || !s.visibleTo(ctx.Session, name) {
+		return nil, &RemediableError{
+			Code:    -32602,
+			Reason:  mcp.ReasonToolNotFound,
+			Message: fmt.Sprintf("tool not found: %s", name),
+			Hint:    "call tools/list for the tools the server provides; the tool may have been removed or hidden",
+		}
+	}
+
+	if err := s.checkStrictArguments(tool, args); err != nil {
+		return nil, err
+	}
+
+	// Refused calls fail like the tool did, so that the model sees why
+	if err := s.checkRisk(ctx, tool, args); err != nil {
+		return nil, fmt.Errorf("tool execution failed: %w", err)
+	}
+
+	startTime := time.Now()
+
+	// Apply the adaptive deadline, if any, so the handler can observe it through ctx
+	var timeoutCh <-chan struct{}
+	timeout := s.toolTimeout(name)
+	if timeout > 0 {
+		parent := ctx.ctx
+		if parent == nil {
+			parent = context.Background()
+		}
+		var cancel context.CancelFunc
+		ctx.ctx, cancel = context.WithTimeout(parent, timeout)
+		defer cancel()
+		timeoutCh = ctx.ctx.Done()
 	}
 
 	// Build raw request using structured type
@@ -369,6 +606,7 @@ func (s *serverImpl) executeTool(ctx *Context, name string, args map[string]inte
 	rawRequest := mcp.NewRequest(requestID, "tools/call", params)
 
 	// Execute the tool handler with cancellation awareness
+	cancelled := ctx.RegisterForCancellation()
 	resultCh := make(chan struct {
 		result interface{}
 		err    error
@@ -391,7 +629,7 @@ func (s *serverImpl) executeTool(ctx *Context, name string, args map[string]inte
 
 		// Check if cancelled after execution but before sending result
 		select {
-		case <-ctx.RegisterForCancellation():
+		case <-cancelled:
 			// Execution completed but was cancelled - don't send result
 			return
 		default:
@@ -408,13 +646,30 @@ func (s *serverImpl) executeTool(ctx *Context, name string, args map[string]inte
 	var finalErr error
 
 	select {
-	case <-ctx.RegisterForCancellation():
+	case <-cancelled:
 		// Request was cancelled during execution
 		finalErr = fmt.Errorf("tool execution cancelled: %s", name)
+	case <-timeoutCh:
+		// Adaptive deadline passed (or the parent context ended) before the handler finished
+		if errors.Is(ctx.ctx.Err(), context.DeadlineExceeded) {
+			finalErr = fmt.Errorf("tool %s timed out after %s", name, timeout)
+			s.reportToolError(name, finalErr)
+		} else {
+			finalErr = fmt.Errorf("tool execution cancelled: %s", name)
+		}
 	case res := <-resultCh:
 		// Execution completed
 		finalResult = res.result
 		finalErr = res.err
+		if finalErr != nil {
+			s.reportToolError(name, finalErr)
+		}
+	}
+
+	latency := time.Since(startTime)
+	s.recordToolUsage(ctx, name, latency, finalErr != nil)
+	if finalErr == nil {
+		s.observeToolLatency(name, latency)
 	}
 
 	// Build raw response using structured types
@@ -460,9 +715,20 @@ func (s *serverImpl) ProcessToolCall(ctx *Context) (interface{}, error) {
 	// Execute the requested tool
 	result, err := s.executeTool(ctx, ctx.Request.ToolName, ctx.Request.ToolArgs)
 	if err != nil {
+		// Arguments that violate the tool's schema are a protocol error
+		var invalidParams *InvalidParametersError
+		if errors.As(err, &invalidParams) {
+			return nil, invalidParams
+		}
+
 		// For tool-specific errors, we still return a valid result but with isError=true
 		if strings.Contains(err.Error(), "tool execution failed:") {
-			return NewToolCallResponse([]ContentItem{NewTextContent(err.Error())}, true), nil
+			text := err.Error()
+			var remediable *RemediableError
+			if errors.As(err, &remediable) && remediable.Hint != "" {
+				text = fmt.Sprintf("%s (hint: %s)", text, remediable.Hint)
+			}
+			return NewToolCallResponse([]ContentItem{NewTextContent(text)}, true), nil
 		}
 		// For other errors (like tool not found), return a protocol error
 		return nil, err
@@ -472,11 +738,19 @@ func (s *serverImpl) ProcessToolCall(ctx *Context) (interface{}, error) {
 	var content []ContentItem
 	var isError bool = false
 
+	// Objects are also sent as structured content, with their JSON as the text
+	var structured interface{}
+
 	// Add appropriate content based on result type
 	switch v := result.(type) {
 	case string:
 		// Simple text result
 		content = []ContentItem{NewTextContent(v)}
+	case ContentItem:
+		// A single content block, such as one made with ImageContent
+		content = []ContentItem{v}
+	case []ContentItem:
+		content = v
 	case map[string]interface{}:
 		// If result is already in the expected format with content field, use it directly
 		if existingContent, ok := v["content"]; ok {
@@ -502,10 +776,15 @@ func (s *serverImpl) ProcessToolCall(ctx *Context) (interface{}, error) {
 				if contentItem.Type == "image" {
 					contentItem.ImageURL = getString(itemMap, "imageUrl")
 					contentItem.AltText = getString(itemMap, "altText")
+					contentItem.Data = itemMap["data"]
+					contentItem.MimeType = getString(itemMap, "mimeType")
 				} else if contentItem.Type == "audio" {
 					contentItem.URL = getString(itemMap, "audioUrl")
 					contentItem.AltText = getString(itemMap, "altText")
+					contentItem.Data = itemMap["data"]
 					contentItem.MimeType = getString(itemMap, "mimeType")
+				} else if contentItem.Type == "resource" {
+					contentItem.Resource = embeddedResourceFromMap(itemMap["resource"])
 				} else if contentItem.Type == "link" {
 					contentItem.URL = getString(itemMap, "url")
 					contentItem.Title = getString(itemMap, "title")
@@ -519,6 +798,7 @@ func (s *serverImpl) ProcessToolCall(ctx *Context) (interface{}, error) {
 			if existingIsError, ok := v["isError"].(bool); ok {
 				isError = existingIsError
 			}
+			structured = v["structuredContent"]
 		} else if imageUrl, ok := v["imageUrl"].(string); ok {
 			// Handle image result
 			altText, _ := v["altText"].(string)
@@ -535,6 +815,7 @@ func (s *serverImpl) ProcessToolCall(ctx *Context) (interface{}, error) {
 			// Otherwise convert the map to JSON and use as text
 			jsonData, _ := json.MarshalIndent(v, "", "  ")
 			content = []ContentItem{NewTextContent(string(jsonData))}
+			structured = v
 		}
 	case []interface{}:
 		// If it's an array of content items, try to use it directly
@@ -558,6 +839,9 @@ func (s *serverImpl) ProcessToolCall(ctx *Context) (interface{}, error) {
 							if altText, hasAlt := contentMap["altText"].(string); hasAlt {
 								contentItem.AltText = altText
 							}
+						} else if data, hasData := contentMap["data"].(string); hasData {
+							contentItem.Data = data
+							contentItem.MimeType, _ = contentMap["mimeType"].(string)
 						} else {
 							continue // Skip invalid image items
 						}
@@ -570,9 +854,18 @@ func (s *serverImpl) ProcessToolCall(ctx *Context) (interface{}, error) {
 							if mimeType, hasMime := contentMap["mimeType"].(string); hasMime {
 								contentItem.MimeType = mimeType
 							}
+						} else if data, hasData := contentMap["data"].(string); hasData {
+							contentItem.Data = data
+							contentItem.MimeType, _ = contentMap["mimeType"].(string)
 						} else {
 							continue // Skip invalid audio items
 						}
+					case "resource":
+						if resource := embeddedResourceFromMap(contentMap["resource"]); resource != nil {
+							contentItem.Resource = resource
+						} else {
+							continue // Skip invalid resource items
+						}
 					case "link":
 						if url, hasUrl := contentMap["url"].(string); hasUrl {
 							contentItem.URL = url
@@ -611,9 +904,82 @@ func (s *serverImpl) ProcessToolCall(ctx *Context) (interface{}, error) {
 		// For other types, convert to JSON
 		jsonData, _ := json.MarshalIndent(v, "", "  ")
 		content = []ContentItem{NewTextContent(string(jsonData))}
+		if isJSONObject(v) {
+			structured = v
+		}
+	}
+
+	// Read the resources the result embeds by URI
+	content, err = s.embedToolResources(ctx, content)
+	if err != nil {
+		return NewToolCallResponse([]ContentItem{NewTextContent(err.Error())}, true), nil
+	}
+
+	// The response is adapted to the requesting session's protocol version when sent
+	response := NewToolCallResponse(content, isError)
+	if structured != nil {
+		response.StructuredContent = structured
+	}
+	return response, nil
+}
+
+// embeddedResourceFromMap converts the resource of a resource content item given
+// as a map, returning nil if it has no URI
+func embeddedResourceFromMap(v interface{}) *mcp.EmbeddedResource {
+	resourceMap, ok := v.(map[string]interface{})
+	if !ok || getString(resourceMap, "uri") == "" {
+		return nil
+	}
+	return &mcp.EmbeddedResource{
+		URI:      getString(resourceMap, "uri"),
+		MimeType: getString(resourceMap, "mimeType"),
+		Text:     getString(resourceMap, "text"),
+		Blob:     getString(resourceMap, "blob"),
+	}
+}
+
+// embedToolResources reads the contents of the resources a tool result embeds by
+// URI only, as EmbeddedResourceContent does
+func (s *serverImpl) embedToolResources(ctx *Context, content []ContentItem) ([]ContentItem, error) {
+	var embedded []ContentItem
+	for i, item := range content {
+		if item.Type != "resource" || item.Resource == nil || item.Resource.Text != "" || item.Resource.Blob != "" {
+			continue
+		}
+		resource, err := s.embedResource(ctx, item.Resource.URI)
+		if err != nil {
+			return nil, err
+		}
+		if embedded == nil {
+			// Copy the content so the handler's slice is left as it was
+			embedded = append([]ContentItem(nil), content...)
+		}
+		embedded[i].Resource = resource
+	}
+	if embedded == nil {
+		return content, nil
 	}
+	return embedded, nil
+}
 
-	return NewToolCallResponse(content, isError), nil
+// isJSONObject reports whether a value is encoded as a JSON object: a struct, a
+// map with string keys, or a non-nil pointer to either
+func isJSONObject(v interface{}) bool {
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return false
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Map:
+		return value.Type().Key().Kind() == reflect.String && !value.IsNil()
+	default:
+		return false
+	}
 }
 
 // SendToolsListChangedNotification sends a notification to inform clients that the tool list has changed.
@@ -654,3 +1020,10 @@ func (s *serverImpl) SendToolsListChangedNotification() error {
 	s.logger.Debug("sent tools/list_changed notification")
 	return nil
 }
+
+// SendToolsListChangedNotificationToSession tells the client of one session that the
+// tool list has changed, without notifying the other clients. Use it when only that
+// session's view changed, such as the tools a visibility filter shows it.
+func (s *serverImpl) SendToolsListChangedNotificationToSession(sessionID SessionID) error {
+	return s.sendListChangedToSession(sessionID, "notifications/tools/list_changed")
+}
@@ -0,0 +1,285 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolArgumentViolations(t *testing.T) {
+	s := NewServer("validation-test").GetServer()
+	s.SetTransport(&recordingTransport{})
+
+	type SearchArgs struct {
+		Query string `json:"query" validate:"required,min=2,max=50"`
+		Limit int    `json:"limit" validate:"min=1,max=100"`
+		Sort  string `json:"sort" validate:"enum=asc|desc"`
+		Email string `json:"email" validate:"format=email"`
+	}
+	s.Tool("search", "Search things", func(ctx *Context, args SearchArgs) (interface{}, error) {
+		return "found", nil
+	})
+
+	call := func(arguments string) map[string]json.RawMessage {
+		t.Helper()
+		response, err := s.handleMessage([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search","arguments":` + arguments + `}}`))
+		require.NoError(t, err)
+		var decoded map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(response, &decoded))
+		return decoded
+	}
+
+	// Valid arguments reach the handler
+	ok := call(`{"query":"go","limit":10,"sort":"asc","email":"jane@example.com"}`)
+	assert.Contains(t, string(ok["result"]), "found")
+	assert.Nil(t, ok["error"])
+
+	// Every violated constraint is reported in a single invalid params error
+	failed := call(`{"query":"g","limit":500,"sort":"random","email":"nope"}`)
+	require.NotNil(t, failed["error"])
+	var rpcErr struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    struct {
+			Message    string `json:"message"`
+			Violations []struct {
+				Field      string `json:"field"`
+				Constraint string `json:"constraint"`
+				Message    string `json:"message"`
+			} `json:"violations"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(failed["error"], &rpcErr))
+	assert.Equal(t, -32602, rpcErr.Code)
+	assert.Equal(t, "Invalid params", rpcErr.Message)
+	assert.Contains(t, rpcErr.Data.Message, "invalid arguments")
+
+	got := map[string]string{}
+	for _, violation := range rpcErr.Data.Violations {
+		got[violation.Field] = violation.Constraint
+		assert.NotEmpty(t, violation.Message)
+	}
+	assert.Equal(t, map[string]string{
+		"email": "format",
+		"limit": "maximum",
+		"query": "minLength",
+		"sort":  "enum",
+	}, got)
+
+	// Missing and mistyped arguments are violations too
+	failed = call(`{"limit":"ten","sort":"asc","email":"jane@example.com"}`)
+	require.NoError(t, json.Unmarshal(failed["error"], &rpcErr))
+	got = map[string]string{}
+	for _, violation := range rpcErr.Data.Violations {
+		got[violation.Field] = violation.Constraint
+	}
+	assert.Equal(t, map[string]string{"limit": "type", "query": "required"}, got)
+}
+
+func TestToolWithSchema(t *testing.T) {
+	s := NewServer("schema-test").GetServer()
+	s.SetTransport(&recordingTransport{})
+
+	const calculateSchema = `{
+		"type": "object",
+		"properties": {
+			"operation": {"type": "string", "enum": ["add", "subtract"]},
+			"a": {"type": "number", "minimum": 0},
+			"b": {"type": "number", "minimum": 0}
+		},
+		"required": ["operation", "a", "b"]
+	}`
+
+	type CalculateArgs struct {
+		Operation string  `json:"operation"`
+		A         float64 `json:"a"`
+		B         float64 `json:"b"`
+	}
+	s.ToolWithSchema("calculate", "Perform a calculation", calculateSchema,
+		func(ctx *Context, args CalculateArgs) (interface{}, error) {
+			if args.Operation == "subtract" {
+				return args.A - args.B, nil
+			}
+			return args.A + args.B, nil
+		})
+	s.ToolWithSchema("echo", "Echo the arguments", map[string]interface{}{
+		"properties": map[string]interface{}{"text": map[string]interface{}{"type": "string", "maxLength": 5}},
+	}, func(ctx *Context, args map[string]interface{}) (interface{}, error) {
+		return args["text"], nil
+	})
+
+	// Invalid schemas and handlers are not registered
+	s.ToolWithSchema("broken", "Not a schema", `[1, 2]`, func(ctx *Context, args CalculateArgs) (interface{}, error) {
+		return nil, nil
+	})
+	s.ToolWithSchema("array", "Not an object", `{"type":"array"}`, func(ctx *Context, args CalculateArgs) (interface{}, error) {
+		return nil, nil
+	})
+	s.ToolWithSchema("untyped", "Bad handler", calculateSchema, func(args CalculateArgs) (interface{}, error) {
+		return nil, nil
+	})
+
+	request := func(message string) map[string]json.RawMessage {
+		t.Helper()
+		response, err := s.handleMessage([]byte(message))
+		require.NoError(t, err)
+		var decoded map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(response, &decoded))
+		return decoded
+	}
+
+	// The explicit schema is listed as given
+	listed := request(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	var list struct {
+		Tools []struct {
+			Name        string                 `json:"name"`
+			InputSchema map[string]interface{} `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(listed["result"], &list))
+	require.Len(t, list.Tools, 2)
+	assert.Equal(t, "calculate", list.Tools[0].Name)
+	var want map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(calculateSchema), &want))
+	assert.Equal(t, want, list.Tools[0].InputSchema)
+	assert.Equal(t, "object", list.Tools[1].InputSchema["type"])
+
+	// Valid arguments are bound to the handler's struct
+	ok := request(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"calculate","arguments":{"operation":"subtract","a":5,"b":2}}}`)
+	assert.Contains(t, string(ok["result"]), `"text":"3"`)
+
+	// Arguments are validated against the explicit schema
+	failed := request(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"calculate","arguments":{"operation":"multiply","a":-1}}}`)
+	require.NotNil(t, failed["error"])
+	assert.Contains(t, string(failed["error"]), `"code":-32602`)
+	assert.Contains(t, string(failed["error"]), `"field":"operation","constraint":"enum"`)
+	assert.Contains(t, string(failed["error"]), `"field":"a","constraint":"minimum"`)
+	assert.Contains(t, string(failed["error"]), `"field":"b","constraint":"required"`)
+
+	// Map handlers receive the validated arguments
+	echoed := request(`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`)
+	assert.Contains(t, string(echoed["result"]), `"text":"hi"`)
+	tooLong := request(`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"echo","arguments":{"text":"too long"}}}`)
+	assert.Contains(t, string(tooLong["error"]), `"constraint":"maxLength"`)
+}
+
+func TestToolStructuredContent(t *testing.T) {
+	s := NewServer("structured-test").GetServer()
+	s.SetTransport(&recordingTransport{})
+
+	type forecast struct {
+		City string  `json:"city"`
+		High float64 `json:"high"`
+	}
+	s.Tool("forecast", "Returns a struct", func(ctx *Context, args struct{}) (interface{}, error) {
+		return &forecast{City: "Oslo", High: 12.5}, nil
+	})
+	s.Tool("stats", "Returns a map", func(ctx *Context, args struct{}) (interface{}, error) {
+		return map[string]interface{}{"count": 3}, nil
+	})
+	s.Tool("custom", "Returns its own content", func(ctx *Context, args struct{}) (interface{}, error) {
+		return map[string]interface{}{
+			"content":           []interface{}{map[string]interface{}{"type": "text", "text": "3 items"}},
+			"structuredContent": map[string]interface{}{"count": 3},
+		}, nil
+	})
+	s.Tool("list", "Returns an array", func(ctx *Context, args struct{}) (interface{}, error) {
+		return []int{1, 2}, nil
+	})
+
+	initializeSession(t, s, "draft", "draft")
+	initializeSession(t, s, "stable", "2025-03-26")
+
+	call := func(sessionID, tool string) ToolCallResponse {
+		t.Helper()
+		raw, err := s.handleSessionMessage(sessionID, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"`+tool+`","arguments":{}}}`))
+		require.NoError(t, err)
+		var response struct {
+			Result ToolCallResponse `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(raw, &response))
+		return response.Result
+	}
+
+	// Objects are sent as structured content along with their JSON as text
+	result := call("draft", "forecast")
+	assert.Equal(t, map[string]interface{}{"city": "Oslo", "high": 12.5}, result.StructuredContent)
+	require.Len(t, result.Content, 1)
+	assert.JSONEq(t, `{"city":"Oslo","high":12.5}`, result.Content[0].Text)
+	assert.Equal(t, map[string]interface{}{"count": float64(3)}, call("draft", "stats").StructuredContent)
+
+	custom := call("draft", "custom")
+	assert.Equal(t, "3 items", custom.Content[0].Text)
+	assert.Equal(t, map[string]interface{}{"count": float64(3)}, custom.StructuredContent)
+
+	// Arrays are not objects, and older versions get the text only
+	assert.Nil(t, call("draft", "list").StructuredContent)
+	stable := call("stable", "forecast")
+	assert.Nil(t, stable.StructuredContent)
+	assert.JSONEq(t, `{"city":"Oslo","high":12.5}`, stable.Content[0].Text)
+}
+
+func TestToolMediaContent(t *testing.T) {
+	s := NewServer("media-test").GetServer()
+	s.SetTransport(&recordingTransport{})
+
+	png := []byte("\x89PNG\r\n\x1a\n")
+	s.Resource("/notes/readme", "Readme", func(ctx *Context, args interface{}) (interface{}, error) {
+		return "read me first", nil
+	})
+	s.Tool("snapshot", "Returns an image", func(ctx *Context, args struct{}) (interface{}, error) {
+		return ImageContent(png, ""), nil
+	})
+	s.Tool("recording", "Returns audio with a caption", func(ctx *Context, args struct{}) (interface{}, error) {
+		return []ContentItem{TextContent("caption"), AudioContent([]byte("RIFF"), "audio/wav")}, nil
+	})
+	s.Tool("readme", "Embeds a resource", func(ctx *Context, args struct{}) (interface{}, error) {
+		return EmbeddedResourceContent("/notes/readme"), nil
+	})
+	s.Tool("missing", "Embeds a resource that does not exist", func(ctx *Context, args struct{}) (interface{}, error) {
+		return EmbeddedResourceContent("/notes/missing"), nil
+	})
+
+	initializeSession(t, s, "stable", "2025-03-26")
+	initializeSession(t, s, "legacy", "2024-11-05")
+
+	call := func(sessionID, tool string) ToolCallResponse {
+		t.Helper()
+		raw, err := s.handleSessionMessage(sessionID, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"`+tool+`","arguments":{}}}`))
+		require.NoError(t, err)
+		var response struct {
+			Result ToolCallResponse `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(raw, &response))
+		return response.Result
+	}
+
+	image := call("stable", "snapshot")
+	require.Len(t, image.Content, 1)
+	assert.Equal(t, "image", image.Content[0].Type)
+	assert.Equal(t, "image/png", image.Content[0].MimeType)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(png), image.Content[0].Data)
+
+	audio := call("stable", "recording")
+	require.Len(t, audio.Content, 2)
+	assert.Equal(t, "audio", audio.Content[1].Type)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("RIFF")), audio.Content[1].Data)
+
+	// 2024-11-05 has no audio content, so the audio becomes a text placeholder
+	legacy := call("legacy", "recording")
+	require.Len(t, legacy.Content, 2)
+	assert.Equal(t, ContentItem{Type: "text", Text: "[audio: audio/wav]"}, legacy.Content[1])
+
+	readme := call("legacy", "readme")
+	require.Len(t, readme.Content, 1)
+	require.NotNil(t, readme.Content[0].Resource)
+	assert.Equal(t, "/notes/readme", readme.Content[0].Resource.URI)
+	assert.Equal(t, "read me first", readme.Content[0].Resource.Text)
+
+	missing := call("stable", "missing")
+	assert.True(t, missing.IsError)
+	assert.Contains(t, missing.Content[0].Text, "cannot embed resource /notes/missing")
+}
@@ -0,0 +1,76 @@
+package server
+
+import "github.com/localrivet/gomcp/mcp"
+
+// Handlers, and the Process methods that call them, build their results against
+// one canonical model: the shapes of the newest protocol version. Resource reads
+// have a contents array, tool results may hold audio and structured content, and
+// progress notifications may carry a message. The functions in this file convert
+// those shapes into the ones of the version each session negotiated, right before
+// they are sent, so that nothing else needs to check the version.
+
+// canonicalVersion is the protocol version whose shapes make up the canonical model
+const canonicalVersion = mcp.VersionDraft
+
+// adaptResultForVersion converts the result of a request from the canonical model
+// into the shape of the given protocol version. Results of methods whose shape does
+// not depend on the version are returned as they are.
+func adaptResultForVersion(method string, result interface{}, version string) interface{} {
+	switch method {
+	case "tools/call":
+		switch v := result.(type) {
+		case ToolCallResponse:
+			return adaptToolResultForVersion(v, version)
+		case *ToolCallResponse:
+			if v != nil {
+				adapted := adaptToolResultForVersion(*v, version)
+				return &adapted
+			}
+		}
+	case "resources/read":
+		if resultMap, ok := result.(map[string]interface{}); ok {
+			return adaptResourceResultForVersion(resultMap, version)
+		}
+	}
+	return result
+}
+
+// adaptToolResultForVersion replaces the content of a tool result that the version
+// cannot represent, and drops its structured content for versions without it
+func adaptToolResultForVersion(response ToolCallResponse, version string) ToolCallResponse {
+	response.Content = adaptContentForVersion(response.Content, version)
+	if !supportsStructuredContent(version) {
+		response.StructuredContent = nil
+	}
+	return response
+}
+
+// adaptResourceResultForVersion converts a resources/read result with a contents
+// array into the content array of 2024-11-05, which also has no audio content
+func adaptResourceResultForVersion(result map[string]interface{}, version string) map[string]interface{} {
+	if version != mcp.Version20241105 {
+		return result
+	}
+	if _, canonical := result["contents"]; !canonical {
+		return result
+	}
+	uri := ""
+	if contents := ensureArray(result["contents"]); len(contents) > 0 {
+		if first, ok := contents[0].(map[string]interface{}); ok {
+			uri, _ = first["uri"].(string)
+		}
+	}
+	return formatResourceV20241105(uri, result)
+}
+
+// adaptProgressForVersion returns a progress notification in the shape of the
+// given protocol version, without the message that 2024-11-05 has no field for.
+// The notification passed in is left unchanged.
+func adaptProgressForVersion(notification *mcp.ProgressNotification, version string) *mcp.ProgressNotification {
+	adapted := *notification
+	adapted.SetProtocolVersion(version)
+	if !supportsProgressMessages(version) {
+		adapted.Params.Message = ""
+	}
+	return &adapted
+}
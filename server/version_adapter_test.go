@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/localrivet/gomcp/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptResultForVersion(t *testing.T) {
+	tool := ToolCallResponse{
+		Content:           []ContentItem{NewTextContent("caption"), AudioContent([]byte("RIFF"), "audio/wav")},
+		StructuredContent: map[string]interface{}{"length": 3},
+	}
+	resource := map[string]interface{}{
+		"contents": []interface{}{map[string]interface{}{"uri": "/notes", "text": "read me"}},
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		result  interface{}
+		version string
+		want    string
+	}{
+		{"tool result for draft", "tools/call", tool, mcp.VersionDraft,
+			`{"content":[{"type":"text","text":"caption"},{"type":"audio","data":"UklGRg==","mimeType":"audio/wav"}],"isError":false,"structuredContent":{"length":3}}`},
+		{"tool result for 2025-03-26", "tools/call", &tool, mcp.Version20250326,
+			`{"content":[{"type":"text","text":"caption"},{"type":"audio","data":"UklGRg==","mimeType":"audio/wav"}],"isError":false}`},
+		{"tool result for 2024-11-05", "tools/call", tool, mcp.Version20241105,
+			`{"content":[{"type":"text","text":"caption"},{"type":"text","text":"[audio: audio/wav]"}],"isError":false}`},
+		{"resource for 2025-03-26", "resources/read", resource, mcp.Version20250326,
+			`{"contents":[{"uri":"/notes","text":"read me"}]}`},
+		{"resource for 2024-11-05", "resources/read", resource, mcp.Version20241105,
+			`{"content":[{"type":"text","text":"read me"}]}`},
+		{"other methods", "prompts/list", resource, mcp.Version20241105,
+			`{"contents":[{"uri":"/notes","text":"read me"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(adaptResultForVersion(tt.method, tt.result, tt.version))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	// The canonical result is left as it was
+	assert.Len(t, tool.Content, 2)
+	assert.NotNil(t, tool.StructuredContent)
+}
+
+func TestVersionAdaptation(t *testing.T) {
+	s := NewServer("adaptation-test").GetServer()
+	tr := &sharedChannelTransport{}
+	s.SetTransport(tr)
+
+	// Handlers are written once, against the shapes of the newest version
+	s.Resource("/notes", "Notes", func(ctx *Context, args interface{}) (interface{}, error) {
+		return map[string]interface{}{
+			"contents": []interface{}{map[string]interface{}{"uri": "/notes", "text": "read me"}},
+		}, nil
+	})
+
+	initializeSession(t, s, "legacy", mcp.Version20241105)
+	initializeSession(t, s, "stable", mcp.Version20250326)
+
+	read := func(sessionID string) string {
+		t.Helper()
+		response, err := s.handleSessionMessage(sessionID, []byte(`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"/notes"}}`))
+		require.NoError(t, err)
+		return string(response)
+	}
+	assert.Contains(t, read("legacy"), `"content":[{"text":"read me","type":"text"}]`)
+	assert.NotContains(t, read("legacy"), `"contents"`)
+	assert.Contains(t, read("stable"), `"contents":[{"content":[{"text":"read me","type":"text"}],"text":"read me","uri":"/notes"}]`)
+
+	// Progress messages reach the sessions whose version has them only
+	for _, sessionID := range []SessionID{"legacy", "stable"} {
+		token := s.CreateProgressToken("job-" + string(sessionID))
+		s.progressTokenManager.BindSession(token, string(sessionID))
+		require.NoError(t, s.SendProgressNotification(token, 1, nil, "halfway"))
+	}
+	progress := func(sessionID SessionID) string {
+		for _, message := range tr.sentTo(string(sessionID)) {
+			if strings.Contains(message, "notifications/progress") {
+				return message
+			}
+		}
+		return ""
+	}
+	require.NotEmpty(t, progress("legacy"))
+	assert.NotContains(t, progress("legacy"), "halfway")
+	assert.Contains(t, progress("stable"), `"message":"halfway"`)
+}
@@ -0,0 +1,30 @@
+package server
+
+// VisibilityFilter decides whether a session sees a tool, resource or prompt. The
+// name is the name of the tool or prompt, or the URI or URI template the resource
+// was registered with. The session is nil for requests outside of a session.
+type VisibilityFilter func(session *ClientSession, name string) bool
+
+// WithVisibilityFilter sets which tools, resources and prompts each session sees,
+// so that a multi-tenant server can expose different capabilities to different
+// clients. Entries the filter rejects are left out of the session's list
+// responses, and calls to them fail as if they did not exist. The filter runs
+// while the server reads its registry, so it must not register or remove
+// entries itself.
+//
+// Example:
+//
+//	server.NewServer("tenants", server.WithVisibilityFilter(
+//	    func(session *server.ClientSession, name string) bool {
+//	        return !strings.HasPrefix(name, "admin_") || isAdmin(session)
+//	    }))
+func WithVisibilityFilter(filter VisibilityFilter) Option {
+	return func(s *serverImpl) {
+		s.visibilityFilter = filter
+	}
+}
+
+// visibleTo reports whether a session sees the tool, resource or prompt of a name
+func (s *serverImpl) visibleTo(session *ClientSession, name string) bool {
+	return s.visibilityFilter == nil || s.visibilityFilter(session, name)
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVisibilityFilter(t *testing.T) {
+	s := NewServer("visibility-test", WithVisibilityFilter(func(session *ClientSession, name string) bool {
+		// Only the admin session sees the admin entries
+		return !strings.Contains(name, "admin") || (session != nil && session.ID == "admin")
+	})).GetServer()
+	s.SetTransport(&sharedChannelTransport{})
+	handler := func(ctx *Context, args struct{}) (string, error) { return "done", nil }
+	s.Tool("status", "Show status", handler)
+	s.Tool("admin_reset", "Reset everything", handler)
+	s.Resource("/admin/users", "Users", func(ctx *Context, args interface{}) (interface{}, error) { return "users", nil })
+	s.Resource("/docs", "Docs", func(ctx *Context, args interface{}) (interface{}, error) { return "docs", nil })
+	s.Prompt("admin_report", "Report", User("Report on {{topic}}"))
+	s.Completion("admin_report", "topic", func(ctx *Context, value string) ([]string, error) {
+		return []string{"payroll"}, nil
+	})
+
+	send := func(t *testing.T, sessionID, request string) string {
+		t.Helper()
+		response, err := s.handleSessionMessage(sessionID, []byte(request))
+		require.NoError(t, err)
+		return string(response)
+	}
+	for _, sessionID := range []string{"admin", "tenant"} {
+		send(t, sessionID, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	}
+
+	t.Run("lists", func(t *testing.T) {
+		tools := send(t, "tenant", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+		assert.Contains(t, tools, `"status"`)
+		assert.NotContains(t, tools, "admin_reset")
+		resources := send(t, "tenant", `{"jsonrpc":"2.0","id":3,"method":"resources/list"}`)
+		assert.Contains(t, resources, "/docs")
+		assert.NotContains(t, resources, "/admin/users")
+		assert.NotContains(t, send(t, "tenant", `{"jsonrpc":"2.0","id":4,"method":"prompts/list"}`), "admin_report")
+
+		assert.Contains(t, send(t, "admin", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`), "admin_reset")
+		assert.Contains(t, send(t, "admin", `{"jsonrpc":"2.0","id":3,"method":"resources/list"}`), "/admin/users")
+		assert.Contains(t, send(t, "admin", `{"jsonrpc":"2.0","id":4,"method":"prompts/list"}`), "admin_report")
+	})
+
+	t.Run("calls", func(t *testing.T) {
+		assert.Contains(t, send(t, "tenant", `{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"admin_reset","arguments":{}}}`), "tool not found")
+		assert.Contains(t, send(t, "tenant", `{"jsonrpc":"2.0","id":6,"method":"resources/read","params":{"uri":"/admin/users"}}`), "resource not found")
+		assert.Contains(t, send(t, "tenant", `{"jsonrpc":"2.0","id":7,"method":"prompts/get","params":{"name":"admin_report","arguments":{"topic":"x"}}}`), "prompt not found")
+
+		assert.Contains(t, send(t, "admin", `{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"admin_reset","arguments":{}}}`), `"done"`)
+		assert.Contains(t, send(t, "admin", `{"jsonrpc":"2.0","id":6,"method":"resources/read","params":{"uri":"/admin/users"}}`), "users")
+	})
+
+	t.Run("completions and subscriptions", func(t *testing.T) {
+		// Hidden entries answer exactly like entries that do not exist
+		complete := func(sessionID, ref string) string {
+			return send(t, sessionID, `{"jsonrpc":"2.0","id":8,"method":"completion/complete","params":{"ref":{"type":"ref/prompt","name":"`+ref+`"},"argument":{"name":"topic","value":"p"}}}`)
+		}
+		assert.Equal(t, complete("tenant", "missing"), complete("tenant", "admin_report"))
+		assert.Contains(t, complete("admin", "admin_report"), "payroll")
+
+		subscribe := func(sessionID, uri string) string {
+			return send(t, sessionID, `{"jsonrpc":"2.0","id":9,"method":"resources/subscribe","params":{"uri":"`+uri+`"}}`)
+		}
+		assert.Equal(t, subscribe("tenant", "/missing"), subscribe("tenant", "/admin/users"))
+		assert.NotContains(t, subscribe("tenant", "/docs"), "error")
+		session, ok := s.sessionManager.GetSession("tenant")
+		require.True(t, ok)
+		assert.Equal(t, []string{"/missing", "/docs"}, session.ResourceSubscriptions)
+		assert.NotContains(t, subscribe("admin", "/admin/users"), "error")
+	})
+}
@@ -14,18 +14,19 @@ import (
 //
 // Parameters:
 //   - address: The listening address for the server (e.g., ":8080" for all interfaces on port 8080)
+//   - options: Optional ws.Option values for TLS, compression, keepalive, message limits and origin checks
 //
 // Returns:
 //   - The server instance for method chaining
 //
 // This transport is particularly useful for web applications requiring real-time
 // updates and interactive communication.
-func (s *serverImpl) AsWebsocket(address string) Server {
+func (s *serverImpl) AsWebsocket(address string, options ...ws.Option) Server {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Create WebSocket transport with the provided address
-	wsTransport := ws.NewTransport(address)
+	// Create WebSocket transport with the provided address and options
+	wsTransport := ws.NewTransport(address, options...)
 
 	// Configure the transport with an empty path prefix by default
 	// Users can set a custom prefix using AsWebsocketWithPaths if needed
@@ -0,0 +1,326 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+)
+
+// DefaultRequestQueueLength is how many requests wait for a worker by default
+// when the number of concurrent requests is limited.
+const DefaultRequestQueueLength = 100
+
+// ErrServerBusy is reported to clients whose requests overflow the request queue.
+var ErrServerBusy = errors.New("server is busy")
+
+// OverflowPolicy decides what happens to a request that arrives while every
+// worker is busy and the request queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowReject answers the request with a "Server busy" error straight away.
+	OverflowReject OverflowPolicy = iota
+
+	// OverflowWait holds the request until there is room in the queue. Transports
+	// reading requests from a stream, such as stdio, stop reading until the server
+	// catches up, responses to the server's own requests included; HTTP requests
+	// wait in their handlers.
+	OverflowWait
+)
+
+// String returns the name used for the policy in events and logs
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowReject:
+		return "reject"
+	case OverflowWait:
+		return "wait"
+	default:
+		return "unknown"
+	}
+}
+
+// WithMaxConcurrentRequests handles requests on a pool of n workers instead of
+// one goroutine each, so that a flood of tool calls cannot exhaust memory.
+// Requests that arrive while all workers are busy wait in a queue, which holds
+// DefaultRequestQueueLength requests and rejects any beyond that unless
+// WithRequestQueue says otherwise. Each request of a batch takes a place of its
+// own. Responses and notifications, including cancellations, bypass the pool.
+// Zero or less removes the limit, which is the default.
+//
+// Transports reading requests from a stream, such as stdio and WebSocket, hand
+// them to the pool as they are read, so that n of them run at once whether or
+// not FeatureParallelStdio is set.
+//
+// The queue's depth is published on events.TopicRequestQueue whenever requests
+// start waiting, the queue empties again, or a request is rejected.
+//
+// Example:
+//
+//	server.NewServer("my-server",
+//	    server.WithMaxConcurrentRequests(16),
+//	    server.WithRequestQueue(256, server.OverflowReject),
+//	)
+func WithMaxConcurrentRequests(n int) Option {
+	return func(s *serverImpl) {
+		if n <= 0 {
+			s.requestPool = nil
+			return
+		}
+		if s.requestPool == nil {
+			s.requestPool = newWorkerPool(DefaultRequestQueueLength, OverflowReject)
+		}
+		s.requestPool.workers = n
+	}
+}
+
+// WithRequestQueue sets how many requests wait for a worker and what happens to
+// requests once the queue is full. A length of zero hands requests only to idle
+// workers. It has no effect unless WithMaxConcurrentRequests is also given, in
+// either order.
+func WithRequestQueue(length int, policy OverflowPolicy) Option {
+	return func(s *serverImpl) {
+		if length < 0 {
+			length = 0
+		}
+		if s.requestPool == nil {
+			s.requestPool = newWorkerPool(length, policy)
+			return
+		}
+		s.requestPool.queueLength = length
+		s.requestPool.policy = policy
+	}
+}
+
+// workerPool runs requests on a fixed number of workers fed by a bounded queue.
+type workerPool struct {
+	workers     int
+	queueLength int
+	policy      OverflowPolicy
+
+	startOnce sync.Once
+	jobs      chan *poolJob
+
+	// slots admits as many requests as there are workers and queue places, so
+	// that a request is only rejected when all of them are taken
+	slots chan struct{}
+
+	active   atomic.Int64
+	rejected atomic.Int64
+
+	// reserved counts the slots taken by transports for requests they are about
+	// to hand over; the next requests submitted use them instead of taking more
+	reserved atomic.Int64
+
+	// backlogged is set while requests are waiting, so that the queue emptying
+	// again is reported once
+	backlogged atomic.Bool
+}
+
+// poolJob is a request waiting for, or running on, a worker
+type poolJob struct {
+	run   func()
+	done  chan struct{}
+	panic interface{}
+
+	// claimed is set by whichever comes first: the worker running the job, or
+	// the submitter giving up on it at shutdown
+	claimed atomic.Bool
+}
+
+// newWorkerPool creates a pool; workers start with the first request
+func newWorkerPool(queueLength int, policy OverflowPolicy) *workerPool {
+	return &workerPool{
+		queueLength: queueLength,
+		policy:      policy,
+	}
+}
+
+// enabled reports whether requests are handled on the pool. Options may have set
+// up the queue without limiting concurrency.
+func (p *workerPool) enabled() bool {
+	return p != nil && p.workers > 0
+}
+
+// start creates the queue and launches the workers
+func (p *workerPool) start(s *serverImpl) {
+	p.startOnce.Do(func() {
+		p.slots = make(chan struct{}, p.workers+p.queueLength)
+		p.jobs = make(chan *poolJob, p.workers+p.queueLength)
+		for i := 0; i < p.workers; i++ {
+			go p.work(s)
+		}
+	})
+}
+
+// work runs queued requests until the server shuts down
+func (p *workerPool) work(s *serverImpl) {
+	for {
+		select {
+		case job := <-p.jobs:
+			if len(p.jobs) == 0 && p.backlogged.CompareAndSwap(true, false) {
+				s.reportRequestQueue(false)
+			}
+			if !job.claimed.CompareAndSwap(false, true) {
+				// Abandoned while queued
+				continue
+			}
+			p.active.Add(1)
+			p.runJob(job)
+			p.active.Add(-1)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// runJob runs a request, handing a panic back to the goroutine that submitted it
+// so that the worker survives and the transport sees the panic as before
+func (p *workerPool) runJob(job *poolJob) {
+	defer close(job.done)
+	defer func() {
+		job.panic = recover()
+	}()
+	job.run()
+}
+
+// reserve takes a slot for a request a transport is about to hand over, waiting
+// for one if the policy is OverflowWait. Under OverflowReject it takes none once
+// the pool is full, so that the request is rejected when submitted.
+func (p *workerPool) reserve(s *serverImpl) {
+	p.start(s)
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		if p.policy == OverflowReject {
+			return
+		}
+		select {
+		case p.slots <- struct{}{}:
+		case <-s.done:
+			return
+		}
+	}
+	p.reserved.Add(1)
+}
+
+// takeReserved claims a slot reserved by a transport, reporting false if none is
+func (p *workerPool) takeReserved() bool {
+	for {
+		n := p.reserved.Load()
+		if n <= 0 {
+			return false
+		}
+		if p.reserved.CompareAndSwap(n, n-1) {
+			return true
+		}
+	}
+}
+
+// unreserve gives back a slot reserved for a message that is not run on the pool
+// itself, such as a batch, whose requests take slots of their own
+func (p *workerPool) unreserve() {
+	if p.takeReserved() {
+		<-p.slots
+	}
+}
+
+// submit runs fn on a worker and waits for it to finish. It returns ErrServerBusy
+// if the queue is full and the policy is OverflowReject, or ctx's error if ctx is
+// done before the request could be queued.
+func (p *workerPool) submit(ctx context.Context, s *serverImpl, fn func()) error {
+	p.start(s)
+	job := &poolJob{run: fn, done: make(chan struct{})}
+
+	if !p.takeReserved() {
+		select {
+		case p.slots <- struct{}{}:
+		default:
+			if p.policy == OverflowReject {
+				p.rejected.Add(1)
+				s.reportRequestQueue(true)
+				return ErrServerBusy
+			}
+			select {
+			case p.slots <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-s.done:
+				return ErrServerShuttingDown
+			}
+		}
+	}
+	defer func() { <-p.slots }()
+
+	// Holding a slot guarantees room in the queue
+	p.jobs <- job
+
+	// Report the backlog if the request has to wait for a worker
+	if int(p.active.Load())+len(p.jobs) > p.workers {
+		p.backlogged.Store(true)
+		s.reportRequestQueue(false)
+	}
+
+	select {
+	case <-job.done:
+	case <-s.done:
+		// Workers stop at shutdown; a request still queued will not run
+		if job.claimed.CompareAndSwap(false, true) {
+			return ErrServerShuttingDown
+		}
+		<-job.done
+	}
+	if job.panic != nil {
+		panic(job.panic)
+	}
+	return nil
+}
+
+// handlePooled handles a request on the request pool and returns its response,
+// or a "Server busy" error response if the pool cannot take it
+func (s *serverImpl) handlePooled(ctx context.Context, id interface{}, message []byte) ([]byte, error) {
+	var response []byte
+	var err error
+	submitErr := s.requestPool.submit(ctx, s, func() {
+		response, err = handleMessageContext(ctx, s, message)
+	})
+	if submitErr != nil {
+		s.logger.Warn("request not handled by the request pool", "error", submitErr)
+		return createErrorResponse(id, -32000, "Server busy", submitErr.Error()), nil
+	}
+	return response, err
+}
+
+// requestLimiter makes transports that read requests from a stream wait for room
+// in the request pool before handing a request over
+type requestLimiter struct {
+	s *serverImpl
+}
+
+// Wait reserves a slot in the request pool for the next request
+func (l requestLimiter) Wait() {
+	l.s.requestPool.reserve(l.s)
+}
+
+// reportRequestQueue publishes the request queue's state
+func (s *serverImpl) reportRequestQueue(rejected bool) {
+	p := s.requestPool
+	if !p.enabled() || s.events == nil {
+		return
+	}
+	evt := events.RequestQueueEvent{
+		Depth:      len(p.jobs),
+		Capacity:   p.queueLength,
+		Active:     int(p.active.Load()),
+		Workers:    p.workers,
+		Rejected:   p.rejected.Load(),
+		Overflowed: rejected,
+		Policy:     p.policy.String(),
+		ObservedAt: time.Now(),
+	}
+	go events.Publish[events.RequestQueueEvent](s.events, events.TopicRequestQueue, evt)
+}
@@ -0,0 +1,221 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/localrivet/gomcp/events"
+	"github.com/localrivet/gomcp/transport/stdio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	s := NewServer("pool-test", WithMaxConcurrentRequests(2)).GetServer()
+	s.SetTransport(&recordingTransport{})
+
+	var running, peak atomic.Int64
+	s.Tool("work", "Takes a while", func(ctx *Context, args struct{}) (interface{}, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		return "done", nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			response, err := s.handleMessage([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"work","arguments":{}}}`, id)))
+			assert.NoError(t, err)
+			assert.Contains(t, string(response), "done")
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(2), peak.Load(), "at most two requests should run at once")
+}
+
+func TestMaxConcurrentRequestsCountsBatchItems(t *testing.T) {
+	s := NewServer("pool-test", WithMaxConcurrentRequests(2)).GetServer()
+	s.SetTransport(&recordingTransport{})
+	initializeSession(t, s, "batch", "2025-03-26")
+
+	var running, peak atomic.Int64
+	s.Tool("work", "Takes a while", func(ctx *Context, args struct{}) (interface{}, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		return "done", nil
+	})
+
+	calls := make([]string, 6)
+	for i := range calls {
+		calls[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"work","arguments":{}}}`, i+1)
+	}
+	response, err := s.handleSessionMessage("batch", []byte("["+strings.Join(calls, ",")+"]"))
+	require.NoError(t, err)
+	assert.Equal(t, 6, strings.Count(string(response), "done"))
+	assert.Equal(t, int64(2), peak.Load(), "the batch's requests should share the two workers")
+}
+
+func TestRequestPoolStopsStdioReader(t *testing.T) {
+	s, _, started, release := newBlockingToolServer(
+		WithMaxConcurrentRequests(2),
+		WithRequestQueue(0, OverflowWait),
+	)
+	in, feed := io.Pipe()
+	defer feed.Close()
+	tr := stdio.NewTransportWithIO(in, io.Discard)
+	tr.DisableProcessMonitoring()
+	s.SetTransport(tr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Serve(ctx)
+
+	call := func(id int) []byte {
+		return []byte(strings.Replace(slowToolCall, `"id":1`, fmt.Sprintf(`"id":%d`, id), 1) + "\n")
+	}
+
+	// Both workers are used although stdio handles requests one at a time
+	for id := 1; id <= 2; id++ {
+		_, err := feed.Write(call(id))
+		require.NoError(t, err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected two requests to run at once")
+		}
+	}
+
+	// The third request is read but waits for a worker, and the reader with it
+	_, err := feed.Write(call(3))
+	require.NoError(t, err)
+	wrote := make(chan struct{})
+	go func() {
+		feed.Write(call(4))
+		close(wrote)
+	}()
+	select {
+	case <-wrote:
+		t.Fatal("Expected the reader to stop while the pool is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected the waiting requests to run")
+		}
+	}
+	select {
+	case <-wrote:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the reader to read on")
+	}
+}
+
+func TestRequestQueueOverflowReject(t *testing.T) {
+	s, _, started, release := newBlockingToolServer(
+		WithMaxConcurrentRequests(1),
+		WithRequestQueue(0, OverflowReject),
+	)
+	queueEvents := make(chan events.RequestQueueEvent, 10)
+	events.Subscribe[events.RequestQueueEvent](s.Events(), events.TopicRequestQueue,
+		func(ctx context.Context, evt events.RequestQueueEvent) error {
+			queueEvents <- evt
+			return nil
+		})
+
+	first := make(chan string, 1)
+	go func() {
+		response, _ := s.handleMessage([]byte(slowToolCall))
+		first <- string(response)
+	}()
+	<-started
+
+	// The only worker is busy and there is no room to wait
+	response, err := s.handleMessage([]byte(strings.Replace(slowToolCall, `"id":1`, `"id":2`, 1)))
+	require.NoError(t, err)
+	assert.Contains(t, string(response), `"id":2`)
+	assert.Contains(t, string(response), "Server busy")
+
+	select {
+	case evt := <-queueEvents:
+		assert.True(t, evt.Overflowed)
+		assert.Equal(t, int64(1), evt.Rejected)
+		assert.Equal(t, 1, evt.Active)
+		assert.Equal(t, "reject", evt.Policy)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a request queue event for the rejection")
+	}
+
+	close(release)
+	assert.Contains(t, <-first, "done")
+
+	// Notifications bypass the pool
+	response, err = s.handleMessage([]byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":99}}`))
+	assert.NoError(t, err)
+	assert.Nil(t, response)
+}
+
+func TestRequestQueueOverflowWait(t *testing.T) {
+	s, _, started, release := newBlockingToolServer(
+		WithRequestQueue(0, OverflowWait),
+		WithMaxConcurrentRequests(1),
+	)
+
+	responses := make(chan string, 2)
+	for i := 1; i <= 2; i++ {
+		go func(id int) {
+			response, _ := s.handleMessage([]byte(strings.Replace(slowToolCall, `"id":1`, fmt.Sprintf(`"id":%d`, id), 1)))
+			responses <- string(response)
+		}(i)
+	}
+
+	// Only one call runs; the other waits instead of being rejected
+	<-started
+	select {
+	case <-started:
+		t.Fatal("Expected the second call to wait for the worker")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-started
+	for i := 0; i < 2; i++ {
+		assert.Contains(t, <-responses, "done")
+	}
+}
+
+func TestRequestQueueWithoutWorkersIsUnlimited(t *testing.T) {
+	s, _, started, release := newBlockingToolServer(WithRequestQueue(0, OverflowReject))
+	close(release)
+	response, err := s.handleMessage([]byte(slowToolCall))
+	<-started
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "done")
+}
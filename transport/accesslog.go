@@ -0,0 +1,198 @@
+package transport
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccessLogFormat selects how access log entries are written.
+type AccessLogFormat int
+
+const (
+	// AccessLogCombined writes entries in the Combined Log Format of Apache and
+	// nginx, which log analysis and SIEM tools read.
+	AccessLogCombined AccessLogFormat = iota
+
+	// AccessLogStructured writes entries as slog records.
+	AccessLogStructured
+)
+
+// DefaultAccessLogExcludePaths lists the health check paths that are not logged
+// unless AccessLogConfig.ExcludePaths says otherwise.
+var DefaultAccessLogExcludePaths = []string{"/health", "/healthz", "/livez", "/readyz"}
+
+// AccessLogConfig configures the access log of an HTTP-based server transport.
+type AccessLogConfig struct {
+	// Format selects the entry format; the zero value is AccessLogCombined
+	Format AccessLogFormat
+
+	// Output receives combined log entries. It defaults to os.Stdout.
+	Output io.Writer
+
+	// Logger receives structured entries. It defaults to the transport's logger.
+	Logger *slog.Logger
+
+	// SampleRate is the fraction of successful requests that are logged, between
+	// 0 and 1. Requests answered with an error status are always logged. 0, the
+	// default, logs every request.
+	SampleRate float64
+
+	// ExcludePaths lists paths that are never logged, such as health checks
+	// polled by load balancers. nil uses DefaultAccessLogExcludePaths; an empty
+	// slice logs every path.
+	ExcludePaths []string
+}
+
+// AccessLog wraps an HTTP handler so that every request it serves is logged once
+// answered. Streams are logged when they close.
+func AccessLog(handler http.Handler, config AccessLogConfig) http.Handler {
+	excluded := make(map[string]bool)
+	excludePaths := config.ExcludePaths
+	if excludePaths == nil {
+		excludePaths = DefaultAccessLogExcludePaths
+	}
+	for _, path := range excludePaths {
+		excluded[path] = true
+	}
+	output := config.Output
+	if output == nil {
+		output = os.Stdout
+	}
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	var outputMu sync.Mutex
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if excluded[r.URL.Path] {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		recorder := &responseRecorder{ResponseWriter: w}
+		handler.ServeHTTP(recorder, r)
+
+		status := recorder.statusCode()
+		if config.SampleRate > 0 && config.SampleRate < 1 && status < http.StatusBadRequest && rand.Float64() >= config.SampleRate {
+			return
+		}
+
+		if config.Format == AccessLogStructured {
+			logger.Info("http request",
+				"remote", remoteHost(r),
+				"method", r.Method,
+				"path", r.URL.RequestURI(),
+				"proto", r.Proto,
+				"status", status,
+				"bytes", recorder.bytes,
+				"duration", time.Since(start),
+				"user_agent", r.UserAgent(),
+				"session", r.Header.Get("Mcp-Session-Id"))
+			return
+		}
+
+		outputMu.Lock()
+		defer outputMu.Unlock()
+		fmt.Fprintf(output, "%s - %s [%s] %q %d %d %q %q\n",
+			remoteHost(r),
+			accessLogUser(r),
+			start.Format("02/Jan/2006:15:04:05 -0700"),
+			r.Method+" "+r.URL.RequestURI()+" "+r.Proto,
+			status,
+			recorder.bytes,
+			orDash(r.Referer()),
+			orDash(r.UserAgent()))
+	})
+}
+
+// remoteHost returns the client's address without the port
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// accessLogUser returns the basic auth user of a request, or "-"
+func accessLogUser(r *http.Request) string {
+	if user, _, ok := r.BasicAuth(); ok && user != "" {
+		return strings.ReplaceAll(user, " ", "_")
+	}
+	return "-"
+}
+
+// orDash returns "-" for an empty field, as the combined format expects
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// responseRecorder records the status and size of a response. It passes flushes
+// and hijacks through, so SSE streams and WebSocket upgrades keep working.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader records the status code
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the number of bytes written
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(data)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush sends buffered data to the client
+func (r *responseRecorder) Flush() {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Hijack takes over the connection, as a WebSocket upgrade does
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil && r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// statusCode returns the recorded status; a handler that wrote nothing answered 200
+func (r *responseRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
@@ -0,0 +1,78 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAccessLogCombined(t *testing.T) {
+	var out bytes.Buffer
+	handler := AccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("hello"))
+	}), AccessLogConfig{Output: &out})
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp?x=1", nil)
+	req.RemoteAddr = "192.0.2.7:51234"
+	req.SetBasicAuth("alice", "secret")
+	req.Header.Set("User-Agent", "mcp-client/1.0")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	pattern := `^192\.0\.2\.7 - alice \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "POST /mcp\?x=1 HTTP/1\.1" 202 5 "-" "mcp-client/1\.0"\n$`
+	if !regexp.MustCompile(pattern).MatchString(out.String()) {
+		t.Errorf("Unexpected log entry %q", out.String())
+	}
+}
+
+func TestAccessLogStructured(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&out, nil))
+	handler := AccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Streams flush through the recorder
+		w.(http.Flusher).Flush()
+		w.Write([]byte("data: {}\n\n"))
+	}), AccessLogConfig{Format: AccessLogStructured, Logger: logger})
+
+	req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	req.Header.Set("Mcp-Session-Id", "session-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to parse log entry %q: %v", out.String(), err)
+	}
+	if entry["method"] != "GET" || entry["path"] != "/mcp" || entry["status"] != float64(200) ||
+		entry["bytes"] != float64(10) || entry["session"] != "session-1" {
+		t.Errorf("Unexpected log entry %v", entry)
+	}
+}
+
+func TestAccessLogExclusionAndSampling(t *testing.T) {
+	var out bytes.Buffer
+	handler := AccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+		}
+	}), AccessLogConfig{Output: &out, SampleRate: 1e-9})
+
+	// Health checks are never logged, and successful requests are almost never
+	// logged at this rate
+	for _, path := range []string{"/healthz", "/mcp", "/mcp"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected no log entries, got %q", out.String())
+	}
+
+	// Errors are always logged
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if !strings.Contains(out.String(), `"GET /missing HTTP/1.1" 404`) {
+		t.Errorf("Expected the error to be logged, got %q", out.String())
+	}
+}
@@ -6,7 +6,6 @@
 package embedded
 
 import (
-	"context"
 	"errors"
 	"sync"
 	"time"
@@ -235,14 +234,20 @@ func (t *Transport) Send(message []byte) error {
 	msgCopy := make([]byte, len(message))
 	copy(msgCopy, message)
 
-	// Send with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
-	defer cancel()
+	// Most sends find room in the channel; only a full channel needs a timer
+	select {
+	case t.serverToClient <- msgCopy:
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(t.timeout)
+	defer timer.Stop()
 
 	select {
 	case t.serverToClient <- msgCopy:
 		return nil
-	case <-ctx.Done():
+	case <-timer.C:
 		return errors.New("send timeout")
 	case <-t.done:
 		return errors.New("transport stopped")
@@ -351,3 +351,22 @@ func TestGetChannelStats(t *testing.T) {
 		}
 	}
 }
+
+func BenchmarkSend(b *testing.B) {
+	server, client := NewTransportPair()
+	server.Initialize()
+	client.Initialize()
+	server.Start()
+	client.Start()
+	defer server.Stop()
+
+	message := []byte(`{"jsonrpc":"2.0","method":"notifications/progress","params":{"progressToken":1,"progress":50}}`)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := server.Send(message); err != nil {
+			b.Fatal(err)
+		}
+		<-client.GetResponseChannel()
+	}
+}
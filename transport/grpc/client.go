@@ -21,7 +21,10 @@ func (t *Transport) startClient() error {
 	defer cancel()
 
 	// Create dial options
-	opts := t.getClientOptions()
+	opts, err := t.getClientOptions()
+	if err != nil {
+		return err
+	}
 
 	conn, err := grpc.DialContext(ctx, t.address, opts...)
 	if err != nil {
@@ -34,8 +37,8 @@ func (t *Transport) startClient() error {
 
 	// Initialize the session
 	_, err = client.Initialize(ctx, &pb.InitializeRequest{
-		ClientId:      "client-1", // Should be configurable
-		ClientVersion: "1.0.0",    // Should come from client config
+		ClientId:      t.clientID,
+		ClientVersion: "1.0.0", // Should come from client config
 	})
 	if err != nil {
 		return fmt.Errorf("failed to initialize session: %w", err)
@@ -48,7 +51,9 @@ func (t *Transport) startClient() error {
 	}
 
 	// Start goroutine to receive messages from server
+	t.workers.Add(2)
 	go func() {
+		defer t.workers.Done()
 		for {
 			message, err := stream.Recv()
 			if err != nil {
@@ -83,7 +88,7 @@ func (t *Transport) startClient() error {
 				continue
 			}
 
-			t.GetLogger().Info("Client received message", "content", string(content))
+			t.GetLogger().Debug("Client received message", "content", string(content))
 
 			// Route message to appropriate handler
 			t.routeMessage(content)
@@ -92,6 +97,7 @@ func (t *Transport) startClient() error {
 
 	// Start goroutine to send messages to server
 	go func() {
+		defer t.workers.Done()
 		for {
 			select {
 			case <-t.ctx.Done():
@@ -28,6 +28,7 @@ const (
 	DefaultKeepAliveTime     = 10 * time.Second
 	DefaultKeepAliveTimeout  = 3 * time.Second
 	DefaultBufferSize        = 100
+	DefaultClientID          = "gomcp-client"
 )
 
 // Transport errors.
@@ -85,6 +86,21 @@ func WithBufferSize(size int) Option {
 	}
 }
 
+// WithReflection registers the gRPC server reflection service (server mode only),
+// so clients and tools such as grpcurl can discover the MCP service.
+func WithReflection() Option {
+	return func(t *Transport) {
+		t.reflection = true
+	}
+}
+
+// WithClientID sets the client ID sent when a session is initialized (client mode only).
+func WithClientID(id string) Option {
+	return func(t *Transport) {
+		t.clientID = id
+	}
+}
+
 // WithKeepAliveParams sets the keepalive parameters.
 func WithKeepAliveParams(time, timeout time.Duration) Option {
 	return func(t *Transport) {
@@ -105,6 +121,7 @@ func WithKeepAliveParams(time, timeout time.Duration) Option {
 type Transport struct {
 	// Base transport functionality
 	transport.BaseTransport
+	transport.Dispatcher
 
 	// Core transport options
 	isServer bool
@@ -123,6 +140,8 @@ type Transport struct {
 	keepAliveTime     time.Duration
 	keepAliveTimeout  time.Duration
 	bufferSize        int
+	reflection        bool
+	clientID          string
 
 	// Runtime state
 	server     *grpc.Server
@@ -132,6 +151,10 @@ type Transport struct {
 	runningMu  sync.Mutex
 	closeOnce  sync.Once
 
+	// workers tracks the goroutines that send on the channels, which Stop waits
+	// for before closing them
+	workers sync.WaitGroup
+
 	// Channels for messaging
 	sendCh chan []byte
 	recvCh chan []byte
@@ -140,6 +163,10 @@ type Transport struct {
 	// Request/response matching for client mode
 	pendingRequests map[interface{}]chan []byte
 	pendingMu       sync.RWMutex
+
+	// Client streams in server mode, by session ID
+	streams   map[string]*serverStream
+	streamsMu sync.Mutex
 }
 
 // NewTransport creates a new gRPC transport.
@@ -158,6 +185,8 @@ func NewTransport(address string, isServer bool, options ...Option) *Transport {
 		keepAliveTime:     DefaultKeepAliveTime,
 		keepAliveTimeout:  DefaultKeepAliveTimeout,
 		bufferSize:        DefaultBufferSize,
+		clientID:          DefaultClientID,
+		streams:           make(map[string]*serverStream),
 	}
 
 	// Apply configuration options
@@ -252,6 +281,10 @@ func (t *Transport) Stop() error {
 		}
 	}
 
+	// Wait for the goroutines that send on the channels, which return once the
+	// context is cancelled and the connection closed
+	t.workers.Wait()
+
 	// Reset state
 	t.running = false
 
@@ -268,12 +301,20 @@ func (t *Transport) Stop() error {
 // Send sends a message through the transport.
 //
 // In client mode, this sends a message to the server.
-// In server mode, this sends a message to the connected client.
+// In server mode, this sends a message to every connected client.
 //
 // This method returns an error if the transport is not running
 // or if the message cannot be sent.
 func (t *Transport) Send(message []byte) error {
 	t.runningMu.Lock()
+	if t.isServer {
+		running := t.running
+		t.runningMu.Unlock()
+		if !running {
+			return ErrNotRunning
+		}
+		return t.broadcast(message)
+	}
 	defer t.runningMu.Unlock()
 
 	if !t.running {
@@ -321,8 +362,9 @@ func (t *Transport) SendWithContext(ctx context.Context, message []byte) ([]byte
 	}
 
 	requestID := requestMap["id"]
-	if requestID == nil {
-		// This is a notification (no response expected)
+	if _, isRequest := requestMap["method"]; requestID == nil || !isRequest {
+		// This is a notification, or the response to a server request, such as
+		// roots/list (no response expected)
 		if err := t.Send(message); err != nil {
 			return nil, err
 		}
@@ -387,7 +429,7 @@ func (t *Transport) routeMessage(message []byte) {
 		t.pendingMu.RUnlock()
 
 		if exists {
-			t.GetLogger().Info("Routing response to pending request", "id", id)
+			t.GetLogger().Debug("Routing response to pending request", "id", id)
 			select {
 			case responseCh <- message:
 			case <-t.ctx.Done():
@@ -406,7 +448,7 @@ func (t *Transport) routeMessage(message []byte) {
 		}
 	} else {
 		// This is a notification or request - put in recvCh for Receive() method
-		t.GetLogger().Info("Routing notification/request to recvCh")
+		t.GetLogger().Debug("Routing notification/request to recvCh")
 		select {
 		case t.recvCh <- message:
 		case <-t.ctx.Done():
@@ -450,7 +492,10 @@ func (t *Transport) getServerOptions() []grpc.ServerOption {
 }
 
 // getClientOptions returns the gRPC client dial options.
-func (t *Transport) getClientOptions() []grpc.DialOption {
+//
+// Unlike the server, a client never falls back to an insecure connection when its
+// TLS credentials cannot be loaded.
+func (t *Transport) getClientOptions() ([]grpc.DialOption, error) {
 	opts := []grpc.DialOption{
 		grpc.WithDefaultCallOptions(
 			grpc.MaxCallRecvMsgSize(t.maxMessageSize),
@@ -467,15 +512,12 @@ func (t *Transport) getClientOptions() []grpc.DialOption {
 	if t.useTLS {
 		creds, err := t.getClientTLSCredentials()
 		if err != nil {
-			// Log the error but continue with insecure credentials
-			t.GetLogger().Warn("Failed to load TLS credentials, continuing with insecure connection", "error", err)
-			opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
-		} else {
-			opts = append(opts, grpc.WithTransportCredentials(creds))
+			return nil, fmt.Errorf("failed to load TLS credentials: %w", err)
 		}
+		opts = append(opts, grpc.WithTransportCredentials(creds))
 	} else {
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
-	return opts
+	return opts, nil
 }
@@ -0,0 +1,66 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	pb "github.com/localrivet/gomcp/transport/grpc/proto/gen"
+	"google.golang.org/grpc"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+)
+
+// ServiceName is the fully qualified name of the MCP gRPC service.
+var ServiceName = pb.MCP_ServiceDesc.ServiceName
+
+// ListServices asks the server at address for the services it exposes, using the
+// gRPC server reflection protocol. The server must have been started with
+// WithReflection.
+//
+// The options configure the connection the same way as for NewTransport, so TLS,
+// message size and connection timeout settings apply.
+func ListServices(ctx context.Context, address string, options ...Option) ([]string, error) {
+	t := NewTransport(address, false, options...)
+
+	opts, err := t.getClientOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.connectionTimeout)
+	defer cancel()
+
+	conn, err := grpc.NewClient(address, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer conn.Close()
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reflection stream: %w", err)
+	}
+	defer stream.CloseSend()
+
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send reflection request: %w", err)
+	}
+
+	response, err := stream.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	if errResponse := response.GetErrorResponse(); errResponse != nil {
+		return nil, fmt.Errorf("failed to list services: %s", errResponse.GetErrorMessage())
+	}
+
+	var services []string
+	for _, service := range response.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	sort.Strings(services)
+	return services, nil
+}
@@ -2,14 +2,19 @@ package grpc
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"strings"
 	"time"
 
+	"github.com/localrivet/gomcp/transport"
 	pb "github.com/localrivet/gomcp/transport/grpc/proto/gen"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
 // mcpServer implements the MCP gRPC server.
@@ -47,10 +52,16 @@ func (t *Transport) startGRPCServer() error {
 
 	// Register service
 	pb.RegisterMCPServer(t.server, &mcpServer{transport: t})
+	if t.reflection {
+		reflection.Register(t.server)
+	}
 
 	// Start server in a goroutine
+	server := t.server
+	t.workers.Add(1)
 	go func() {
-		if err := t.server.Serve(lis); err != nil {
+		defer t.workers.Done()
+		if err := server.Serve(lis); err != nil {
 			select {
 			case t.errCh <- fmt.Errorf("failed to serve: %w", err):
 			case <-t.ctx.Done():
@@ -81,31 +92,37 @@ func (s *mcpServer) Initialize(ctx context.Context, req *pb.InitializeRequest) (
 //
 // This method acts as a message pipe, passing JSON-RPC messages between
 // the client and the MCP server's message handler, just like other transports.
+// Each stream is a session of its own: the messages read from it are handled as
+// messages of that session, and responses are written back to the same stream.
 func (s *mcpServer) StreamMessages(stream pb.MCP_StreamMessagesServer) error {
-	// Create done channel for this stream
-	done := make(chan struct{})
-	defer close(done)
+	t := s.transport
+
+	// Register the stream under a session of its own
+	sessionID := generateSessionID()
+	client := &serverStream{
+		out:  make(chan []byte, t.bufferSize),
+		done: make(chan struct{}),
+	}
+	t.streamsMu.Lock()
+	t.streams[sessionID] = client
+	t.streamsMu.Unlock()
+	defer func() {
+		t.streamsMu.Lock()
+		delete(t.streams, sessionID)
+		t.streamsMu.Unlock()
+		close(client.done)
+	}()
 
 	// Start a goroutine to send outgoing messages to the client
 	go func() {
-		defer func() {
-			// Recover from any panic, particularly send on closed channel
-			if r := recover(); r != nil {
-				s.transport.GetLogger().Warn("Sender goroutine recovered from panic", "error", r)
-			}
-		}()
-
 		for {
 			select {
-			case <-s.transport.ctx.Done():
+			case <-t.ctx.Done():
 				return
-			case message, ok := <-s.transport.sendCh:
-				if !ok {
-					// Channel closed, exit gracefully
-					return
-				}
-
-				s.transport.GetLogger().Info("Sending message to client", "content", string(message))
+			case <-client.done:
+				return
+			case message := <-client.out:
+				t.GetLogger().Debug("Sending message to client", "content", string(message))
 
 				// Convert the JSON-RPC message to gRPC format
 				protoMsg := &pb.MCPMessage{
@@ -119,60 +136,135 @@ func (s *mcpServer) StreamMessages(stream pb.MCP_StreamMessagesServer) error {
 				// Send the message to the client
 				if err := stream.Send(protoMsg); err != nil {
 					// Log error but don't crash
-					s.transport.GetLogger().Warn("Failed to send message to client", "error", err)
+					t.GetLogger().Warn("Failed to send message to client", "error", err)
 					return
 				}
-
-				s.transport.GetLogger().Info("Successfully sent message to client")
 			}
 		}
 	}()
 
-	// Receive messages from the client and put them in recvCh
-	// The server will process them via transport.Receive() and send responses via transport.Send()
-	for {
-		protoMsg, err := stream.Recv()
-		if err != nil {
-			if err == io.EOF {
-				// Stream closed by client - normal termination
-				return nil
+	// Receive messages from the client in a goroutine, so that the stream ends
+	// when the transport stops even though the client keeps it open
+	recvErr := make(chan error, 1)
+	go func() {
+		var requests transport.RequestQueue
+		for {
+			protoMsg, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+
+			// Extract the JSON-RPC message content
+			var message []byte
+			switch content := protoMsg.Content.(type) {
+			case *pb.MCPMessage_TextContent:
+				message = []byte(content.TextContent)
+			case *pb.MCPMessage_BinaryContent:
+				message = content.BinaryContent
+			default:
+				// Skip unknown content types
+				t.GetLogger().Warn("Unknown message content type", "type", fmt.Sprintf("%T", content))
+				continue
 			}
-			return fmt.Errorf("failed to receive message: %w", err)
+
+			t.GetLogger().Debug("Received message from client", "content", string(message))
+
+			t.Dispatch(&requests, message, func() { t.dispatch(client, sessionID, message) })
 		}
+	}()
 
-		// Extract the JSON-RPC message content
-		var message []byte
-		switch content := protoMsg.Content.(type) {
-		case *pb.MCPMessage_TextContent:
-			message = []byte(content.TextContent)
-		case *pb.MCPMessage_BinaryContent:
-			message = content.BinaryContent
-		default:
-			// Skip unknown content types
-			s.transport.GetLogger().Warn("Unknown message content type", "type", fmt.Sprintf("%T", content))
-			continue
+	select {
+	case <-t.ctx.Done():
+		return nil
+	case err := <-recvErr:
+		if err == io.EOF {
+			// Stream closed by client - normal termination
+			return nil
 		}
+		return fmt.Errorf("failed to receive message: %w", err)
+	}
+}
 
-		s.transport.GetLogger().Info("Received message from client", "content", string(message))
+// serverStream is the stream of a client connected to the server, with the
+// messages queued for it.
+type serverStream struct {
+	out  chan []byte
+	done chan struct{}
+}
 
-		// Process the message directly using the transport's handler (like stdio transport does)
-		if response, err := s.transport.HandleMessage(message); err == nil && response != nil {
-			s.transport.GetLogger().Info("Generated response", "content", string(response))
+// send queues a message for the stream's client.
+func (s *serverStream) send(ctx context.Context, message []byte) error {
+	select {
+	case s.out <- message:
+		return nil
+	case <-s.done:
+		return errors.New("client stream closed")
+	case <-ctx.Done():
+		return fmt.Errorf("send canceled: %w", ctx.Err())
+	}
+}
 
-			// Send the response back via sendCh
-			select {
-			case s.transport.sendCh <- response:
-				// Response queued for sending
-			case <-s.transport.ctx.Done():
-				return fmt.Errorf("send canceled: %w", s.transport.ctx.Err())
-			default:
-				// Channel is full, log warning
-				s.transport.GetLogger().Warn("Send channel full, dropping response")
-			}
-		} else if err != nil {
-			s.transport.GetLogger().Warn("Message handler error", "error", err)
+// dispatch handles a message of a stream's session and queues the response for
+// that stream.
+func (t *Transport) dispatch(client *serverStream, sessionID string, message []byte) {
+	response, err := t.HandleSessionMessage(sessionID, message)
+	if err != nil {
+		t.GetLogger().Warn("Message handler error", "error", err)
+		return
+	}
+	if response != nil {
+		if err := client.send(t.ctx, response); err != nil {
+			t.GetLogger().Warn("Failed to queue response", "error", err)
+		}
+	}
+}
+
+// SendToSession sends a message to the stream with the given session ID, the ID
+// the transport passes to the session message handler for every message read
+// from that stream. Each stream is its own session, so messages for one client
+// are not seen by the others. Sending to a stream that has closed fails.
+func (t *Transport) SendToSession(sessionID string, message []byte) error {
+	if !t.isServer {
+		return t.Send(message)
+	}
+
+	t.streamsMu.Lock()
+	client, exists := t.streams[sessionID]
+	t.streamsMu.Unlock()
+	if !exists {
+		return fmt.Errorf("no gRPC stream for session %s", sessionID)
+	}
+	return client.send(t.ctx, message)
+}
+
+// broadcast sends a message to every connected client (server mode only).
+func (t *Transport) broadcast(message []byte) error {
+	t.streamsMu.Lock()
+	clients := make([]*serverStream, 0, len(t.streams))
+	for _, client := range t.streams {
+		clients = append(clients, client)
+	}
+	t.streamsMu.Unlock()
+
+	var lastErr error
+	for _, client := range clients {
+		if err := client.send(t.ctx, message); err != nil {
+			// Note the error but continue sending to the other clients
+			lastErr = err
 		}
 	}
+	return lastErr
+}
+
+// generateSessionID returns a random session ID for a new stream.
+func generateSessionID() string {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		// Fall back to a timestamp-based ID if crypto/rand fails
+		return fmt.Sprintf("session-%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(bytes)
 }
 
 // StreamEvents implements server-to-client event streaming.
@@ -0,0 +1,339 @@
+package transport
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccessPolicy restricts which clients may reach a network server transport and
+// how hard they may use it. A zero value allows everything.
+type AccessPolicy struct {
+	// Allow lists the addresses, as IPs or CIDR ranges, that may connect. When
+	// empty, every address not denied may connect.
+	Allow []string
+
+	// Deny lists the addresses, as IPs or CIDR ranges, that may not connect. Deny
+	// takes precedence over Allow.
+	Deny []string
+
+	// RequestsPerSecond limits the sustained request rate of each client IP. 0
+	// disables rate limiting.
+	RequestsPerSecond float64
+
+	// Burst is how many requests a client IP may send at once before the rate
+	// limit applies. 0 allows a burst of one second's worth of requests.
+	Burst int
+
+	// MaxConnectionsPerIP limits the connections a single client IP holds open at
+	// once; further connections are closed as soon as they are accepted. 0
+	// disables the limit.
+	MaxConnectionsPerIP int
+}
+
+// ViolationKind tells which rule of an AccessPolicy a client broke.
+type ViolationKind string
+
+// Violation kinds.
+const (
+	// ViolationDenied is a request from an address the policy does not allow,
+	// answered with 403 Forbidden
+	ViolationDenied ViolationKind = "denied"
+
+	// ViolationRateLimited is a request over the client's rate limit, answered
+	// with 429 Too Many Requests
+	ViolationRateLimited ViolationKind = "rate_limited"
+
+	// ViolationConnectionLimit is a connection over the per-IP connection limit,
+	// closed without a response
+	ViolationConnectionLimit ViolationKind = "connection_limit"
+)
+
+// Violation describes a request or connection an AccessPolicy rejected.
+type Violation struct {
+	Kind       ViolationKind
+	RemoteIP   string
+	Method     string // Empty for connection violations
+	Path       string // Empty for connection violations
+	OccurredAt time.Time
+}
+
+// ViolationHandler is called for every request or connection an AccessPolicy rejects.
+type ViolationHandler func(Violation)
+
+// ViolationReporter is implemented by transports that enforce an AccessPolicy and
+// report the clients they reject, so that the server can publish them as events.
+type ViolationReporter interface {
+	// SetViolationHandler sets the handler called for every rejection
+	SetViolationHandler(handler ViolationHandler)
+}
+
+// Guard enforces an AccessPolicy on an HTTP server.
+type Guard struct {
+	allow      []netip.Prefix
+	deny       []netip.Prefix
+	rate       float64
+	burst      float64
+	maxConns   int
+	onViolated ViolationHandler
+
+	mu        sync.Mutex
+	buckets   map[netip.Addr]*tokenBucket
+	conns     map[netip.Addr]int
+	lastSweep time.Time
+}
+
+// tokenBucket holds the tokens a client IP has left for requests
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewGuard creates a guard enforcing the policy, calling onViolation, which may be
+// nil, for every rejection. It fails if an allow or deny entry is not an IP or
+// CIDR range.
+func NewGuard(policy AccessPolicy, onViolation ViolationHandler) (*Guard, error) {
+	allow, err := parsePrefixes(policy.Allow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allow list: %w", err)
+	}
+	deny, err := parsePrefixes(policy.Deny)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deny list: %w", err)
+	}
+
+	burst := float64(policy.Burst)
+	if burst <= 0 {
+		burst = math.Max(1, math.Ceil(policy.RequestsPerSecond))
+	}
+
+	return &Guard{
+		allow:      allow,
+		deny:       deny,
+		rate:       policy.RequestsPerSecond,
+		burst:      burst,
+		maxConns:   policy.MaxConnectionsPerIP,
+		onViolated: onViolation,
+		buckets:    make(map[netip.Addr]*tokenBucket),
+		conns:      make(map[netip.Addr]int),
+		lastSweep:  time.Now(),
+	}, nil
+}
+
+// parsePrefixes parses IPs and CIDR ranges; an IP is a range of one address
+func parsePrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, err
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// Allowed reports whether the policy's allow and deny lists admit an address.
+func (g *Guard) Allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range g.deny {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	if len(g.allow) == 0 {
+		return true
+	}
+	for _, prefix := range g.allow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler wraps an HTTP handler, answering requests from addresses the policy
+// does not allow with 403 Forbidden and requests over the rate limit with 429 Too
+// Many Requests.
+func (g *Guard) Handler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addr, err := remoteAddr(r.RemoteAddr)
+		if err != nil {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if !g.Allowed(addr) {
+			g.report(ViolationDenied, addr, r)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if wait, ok := g.take(addr); !ok {
+			g.report(ViolationRateLimited, addr, r)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Listener wraps a listener, closing connections over the per-IP connection
+// limit as soon as they are accepted. It returns the listener unchanged when the
+// policy sets no connection limit.
+func (g *Guard) Listener(listener net.Listener) net.Listener {
+	if g.maxConns <= 0 {
+		return listener
+	}
+	return &guardListener{Listener: listener, guard: g}
+}
+
+// take takes a token from an address's bucket, or returns how long until one is
+// available
+func (g *Guard) take(addr netip.Addr) (time.Duration, bool) {
+	if g.rate <= 0 {
+		return 0, true
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	g.sweep(now)
+
+	bucket, ok := g.buckets[addr]
+	if !ok {
+		bucket = &tokenBucket{tokens: g.burst, last: now}
+		g.buckets[addr] = bucket
+	}
+	bucket.tokens = math.Min(g.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*g.rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return time.Duration((1 - bucket.tokens) / g.rate * float64(time.Second)), false
+	}
+	bucket.tokens--
+	return 0, true
+}
+
+// sweep drops the buckets of clients idle long enough to have refilled, so the
+// map does not grow with every address ever seen; callers hold g.mu
+func (g *Guard) sweep(now time.Time) {
+	if now.Sub(g.lastSweep) < time.Minute {
+		return
+	}
+	g.lastSweep = now
+	refill := time.Duration(g.burst / g.rate * float64(time.Second))
+	for addr, bucket := range g.buckets {
+		if now.Sub(bucket.last) > refill {
+			delete(g.buckets, addr)
+		}
+	}
+}
+
+// report passes a violation to the handler
+func (g *Guard) report(kind ViolationKind, addr netip.Addr, r *http.Request) {
+	if g.onViolated == nil {
+		return
+	}
+	violation := Violation{Kind: kind, RemoteIP: addr.String(), OccurredAt: time.Now()}
+	if r != nil {
+		violation.Method = r.Method
+		violation.Path = r.URL.Path
+	}
+	g.onViolated(violation)
+}
+
+// remoteAddr parses the IP of a "host:port" remote address
+func remoteAddr(address string) (netip.Addr, error) {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	return addrPort.Addr().Unmap(), nil
+}
+
+// guardListener enforces the per-IP connection limit of a guard
+type guardListener struct {
+	net.Listener
+	guard *Guard
+}
+
+// Accept accepts the next connection within its IP's limit
+func (l *guardListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		addr, err := remoteAddr(conn.RemoteAddr().String())
+		if err != nil {
+			return conn, nil
+		}
+
+		g := l.guard
+		g.mu.Lock()
+		admitted := g.conns[addr] < g.maxConns
+		if admitted {
+			g.conns[addr]++
+		}
+		g.mu.Unlock()
+
+		if !admitted {
+			conn.Close()
+			g.report(ViolationConnectionLimit, addr, nil)
+			continue
+		}
+		return &guardConn{Conn: conn, release: func() {
+			g.mu.Lock()
+			if g.conns[addr]--; g.conns[addr] <= 0 {
+				delete(g.conns, addr)
+			}
+			g.mu.Unlock()
+		}}, nil
+	}
+}
+
+// guardConn frees its IP's connection slot when closed
+type guardConn struct {
+	net.Conn
+	releaseOnce sync.Once
+	release     func()
+}
+
+// Close closes the connection and frees its slot
+func (c *guardConn) Close() error {
+	err := c.Conn.Close()
+	c.releaseOnce.Do(c.release)
+	return err
+}
+
+// GuardServer applies an access policy to the handler and listener of an HTTP
+// server. A nil policy leaves both unchanged, as does an invalid one, for which
+// the error is returned.
+func GuardServer(policy *AccessPolicy, onViolation ViolationHandler, handler http.Handler, listener net.Listener) (http.Handler, net.Listener, error) {
+	if policy == nil {
+		return handler, listener, nil
+	}
+	guard, err := NewGuard(*policy, onViolation)
+	if err != nil {
+		return handler, listener, err
+	}
+	return guard.Handler(handler), guard.Listener(listener), nil
+}
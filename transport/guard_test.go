@@ -0,0 +1,150 @@
+package transport
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+// guardedRequest serves a request from the given remote address through the guard
+func guardedRequest(handler http.Handler, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGuardAllowDeny(t *testing.T) {
+	var violations []Violation
+	guard, err := NewGuard(AccessPolicy{
+		Allow: []string{"10.0.0.0/8", "2001:db8::/32", "192.0.2.1"},
+		Deny:  []string{"10.6.0.0/16"},
+	}, func(v Violation) { violations = append(violations, v) })
+	if err != nil {
+		t.Fatalf("NewGuard failed: %v", err)
+	}
+	handler := guard.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for addr, want := range map[string]int{
+		"10.1.2.3:5000":          http.StatusOK,
+		"[::ffff:10.1.2.3]:5000": http.StatusOK,
+		"[2001:db8::1]:5000":     http.StatusOK,
+		"192.0.2.1:5000":         http.StatusOK,
+		"192.0.2.2:5000":         http.StatusForbidden,
+		"10.6.0.1:5000":          http.StatusForbidden,
+	} {
+		if got := guardedRequest(handler, addr).Code; got != want {
+			t.Errorf("%s: expected %d, got %d", addr, want, got)
+		}
+	}
+
+	if len(violations) != 2 || violations[0].Kind != ViolationDenied || violations[0].Path != "/mcp" {
+		t.Errorf("Expected two denied violations, got %+v", violations)
+	}
+	if !guard.Allowed(netip.MustParseAddr("10.255.0.1")) {
+		t.Error("Expected 10.255.0.1 to be allowed")
+	}
+}
+
+func TestGuardRateLimit(t *testing.T) {
+	var violations []Violation
+	guard, err := NewGuard(AccessPolicy{RequestsPerSecond: 1, Burst: 3},
+		func(v Violation) { violations = append(violations, v) })
+	if err != nil {
+		t.Fatalf("NewGuard failed: %v", err)
+	}
+	handler := guard.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for i := 0; i < 3; i++ {
+		if rec := guardedRequest(handler, "192.0.2.1:5000"); rec.Code != http.StatusOK {
+			t.Fatalf("Request %d within the burst got %d", i, rec.Code)
+		}
+	}
+	rec := guardedRequest(handler, "192.0.2.1:5000")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected 429 with Retry-After: 1, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	// Limits are per IP
+	if rec := guardedRequest(handler, "192.0.2.2:5000"); rec.Code != http.StatusOK {
+		t.Errorf("Expected another IP to be served, got %d", rec.Code)
+	}
+	if len(violations) != 1 || violations[0].Kind != ViolationRateLimited || violations[0].RemoteIP != "192.0.2.1" {
+		t.Errorf("Expected one rate limit violation, got %+v", violations)
+	}
+}
+
+func TestGuardConnectionLimit(t *testing.T) {
+	violations := make(chan Violation, 1)
+	guard, err := NewGuard(AccessPolicy{MaxConnectionsPerIP: 1},
+		func(v Violation) { violations <- v })
+	if err != nil {
+		t.Fatalf("NewGuard failed: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	listener = guard.Listener(listener)
+	defer listener.Close()
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	first, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer first.Close()
+	held := <-accepted
+
+	// A second connection from the same IP is closed straight away
+	second, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer second.Close()
+	second.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := second.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("Expected the connection to be closed, got %v", err)
+	}
+	if v := <-violations; v.Kind != ViolationConnectionLimit || v.RemoteIP != "127.0.0.1" {
+		t.Errorf("Unexpected violation %+v", v)
+	}
+
+	// Closing the first connection frees the slot
+	held.Close()
+	third, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer third.Close()
+	select {
+	case conn := <-accepted:
+		conn.Close()
+	case <-time.After(2 * time.Second):
+		t.Error("Expected the connection to be accepted once the slot was freed")
+	}
+}
+
+func TestGuardInvalidPolicy(t *testing.T) {
+	if _, err := NewGuard(AccessPolicy{Allow: []string{"10.0.0.0/33"}}, nil); err == nil {
+		t.Error("Expected an invalid CIDR range to be rejected")
+	}
+	if _, err := NewGuard(AccessPolicy{Deny: []string{"example.com"}}, nil); err == nil {
+		t.Error("Expected a host name to be rejected")
+	}
+}
@@ -9,12 +9,15 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/tls"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -63,12 +66,179 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithSessions returns an option that enables or disables Mcp-Session-Id session
+// management. Sessions are enabled by default.
+func WithSessions(enabled bool) Option {
+	return func(t *Transport) {
+		t.enableSessions = enabled
+	}
+}
+
+// WithSessionTimeout returns an option that sets how long a session may go
+// without requests before it expires. Sessions with an open event stream do not
+// expire. A timeout of zero keeps sessions until the client terminates them.
+func WithSessionTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		t.sessionTimeout = timeout
+	}
+}
+
+// WithSessionIDGenerator returns an option that sets the function used to create
+// session IDs on initialize. Deployments behind a load balancer can use it to embed
+// a replica identifier in the ID, so that requests for a session can be routed back
+// to the replica holding it. Generated IDs must only contain visible ASCII characters.
+func WithSessionIDGenerator(generator func() string) Option {
+	return func(t *Transport) {
+		t.sessionIDGenerator = generator
+	}
+}
+
+// WithTLS returns an option that makes a server serve HTTPS with the given PEM
+// certificate and key files
+func WithTLS(certFile, keyFile string) Option {
+	return func(t *Transport) {
+		t.tlsCertFile = certFile
+		t.tlsKeyFile = keyFile
+	}
+}
+
+// WithMutualTLS returns an option that makes a TLS server require client
+// certificates signed by one of the CAs in caFile. It requires WithTLS.
+func WithMutualTLS(caFile string) Option {
+	return func(t *Transport) {
+		t.tlsClientCAFile = caFile
+	}
+}
+
+// WithRootCAs returns an option that makes a client verify the server's
+// certificate against the CAs in caFile instead of the system pool
+func WithRootCAs(caFile string) Option {
+	return func(t *Transport) {
+		t.tlsRootCAFile = caFile
+	}
+}
+
+// WithClientCertificate returns an option that makes a client present the given
+// certificate to servers requiring mutual TLS
+func WithClientCertificate(certFile, keyFile string) Option {
+	return func(t *Transport) {
+		t.tlsClientCertFile = certFile
+		t.tlsClientKeyFile = keyFile
+	}
+}
+
+// WithTLSConfig returns an option that sets the complete TLS configuration.
+// Servers serve HTTPS with it and clients use it for their connections.
+// It takes precedence over the file-based TLS options.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(t *Transport) {
+		t.tlsConfig = config
+	}
+}
+
+// WithReadHeaderTimeout returns an option that sets how long a server waits for a
+// request's headers, which guards against slowloris clients. 0 disables the limit.
+func WithReadHeaderTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		t.limits.ReadHeaderTimeout = timeout
+	}
+}
+
+// WithReadTimeout returns an option that sets how long a server waits for a whole
+// request, including its body. 0 disables the limit.
+func WithReadTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		t.limits.ReadTimeout = timeout
+	}
+}
+
+// WithWriteTimeout returns an option that sets how long a server may take to write
+// a response, including the time a tool call runs. SSE streams are exempt. 0, the
+// default, disables the limit.
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		t.limits.WriteTimeout = timeout
+	}
+}
+
+// WithIdleTimeout returns an option that sets how long a server keeps an idle
+// keep-alive connection open. 0 disables the limit.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		t.limits.IdleTimeout = timeout
+	}
+}
+
+// WithMaxHeaderBytes returns an option that limits the size of request headers a
+// server accepts. 0 uses the net/http default of 1 MB.
+func WithMaxHeaderBytes(size int) Option {
+	return func(t *Transport) {
+		t.limits.MaxHeaderBytes = size
+	}
+}
+
+// WithMaxConnections returns an option that limits how many connections a server
+// holds open at once; further connections wait for one to close. 0, the default,
+// disables the limit.
+func WithMaxConnections(n int) Option {
+	return func(t *Transport) {
+		t.limits.MaxConnections = n
+	}
+}
+
+// WithAccessLog returns an option that logs every request the server answers,
+// in the combined log format or as structured records. Health check paths are
+// excluded by default.
+func WithAccessLog(config transport.AccessLogConfig) Option {
+	return func(t *Transport) {
+		t.accessLog = &config
+	}
+}
+
+// WithAccessPolicy returns an option that restricts which client IPs may reach
+// the server and how many requests and connections each may make. Rejected
+// requests get 403 Forbidden or 429 Too Many Requests, and are reported to the
+// violation handler.
+func WithAccessPolicy(policy transport.AccessPolicy) Option {
+	return func(t *Transport) {
+		t.accessPolicy = &policy
+	}
+}
+
+// WithServerLimits returns an option that sets all of a server's timeouts and
+// limits at once, replacing transport.DefaultServerLimits.
+func WithServerLimits(limits transport.ServerLimits) Option {
+	return func(t *Transport) {
+		t.limits = limits
+	}
+}
+
 // DefaultShutdownTimeout is the default timeout for graceful shutdown
 const DefaultShutdownTimeout = 10 * time.Second
 
 // DefaultMCPEndpoint is the default MCP endpoint path
 const DefaultMCPEndpoint = "/mcp"
 
+// SessionIDHeader is the HTTP header carrying the session ID (2025-03-26)
+const SessionIDHeader = "Mcp-Session-Id"
+
+// LastEventIDHeader is the HTTP header of a POST response carrying the ID of the
+// last event the server had queued on the session's event stream when it
+// answered. Clients reading the stream deliver the events up to it before the
+// response, so that the notifications sent while a request was handled, such as
+// its progress, arrive before its result.
+const LastEventIDHeader = "Mcp-Last-Event-Id"
+
+// DefaultSessionTimeout is how long a session may go without requests before it expires
+const DefaultSessionTimeout = 5 * time.Minute
+
+// sessionEventBuffer is how many messages are queued for a session's event
+// stream; messages for a session whose queue is full are dropped
+const sessionEventBuffer = 100
+
+// streamKeepAlive is how often an idle event stream gets a keep-alive comment
+const streamKeepAlive = 15 * time.Second
+
 // Transport implements the transport.Transport interface for Streamable HTTP
 type Transport struct {
 	transport.BaseTransport
@@ -81,9 +251,31 @@ type Transport struct {
 	mcpEndpoint string // MCP endpoint path
 
 	// Session management (2025-03-26)
-	sessions       map[string]*SessionInfo // Map session ID to session info
-	sessionsMu     sync.Mutex
-	enableSessions bool // Whether to use session management
+	sessions          map[string]*SessionInfo // Map session ID to session info
+	sessionsMu        sync.Mutex
+	enableSessions    bool                        // Whether to use session management
+	sessionTimeout    time.Duration               // Idle time after which sessions expire, 0 for never
+	sessionEndHandler transport.SessionEndHandler // Called for every session that ends
+
+	sessionIDGenerator func() string // Optional custom session ID generator
+	done               chan struct{} // Closed by Stop, ending the event streams
+	stopOnce           sync.Once
+
+	limits    transport.ServerLimits     // Server timeouts and connection limit
+	accessLog *transport.AccessLogConfig // Access log, nil when disabled
+
+	// Client restrictions, nil when disabled, and the handler of rejected clients
+	accessPolicy     *transport.AccessPolicy
+	violationHandler transport.ViolationHandler
+
+	// TLS configuration, from files or set directly
+	tlsConfig         *tls.Config
+	tlsCertFile       string // Server certificate
+	tlsKeyFile        string
+	tlsClientCAFile   string // CA for verifying client certificates (mutual TLS)
+	tlsRootCAFile     string // CA for verifying the server certificate
+	tlsClientCertFile string // Client certificate for mutual TLS
+	tlsClientKeyFile  string
 
 	// For client mode
 	url       string
@@ -98,6 +290,45 @@ type SessionInfo struct {
 	CreatedAt time.Time
 	LastSeen  time.Time
 	ClientID  string
+
+	// events queues the messages for the session's event stream, numbered from 1
+	// in the order they were queued; streaming reports whether a client has the
+	// stream open, and closed is closed when the session ends
+	events    chan sessionEvent
+	lastEvent uint64
+	streaming bool
+	closed    chan struct{}
+}
+
+// sessionEvent is a message queued for a session's event stream
+type sessionEvent struct {
+	id   uint64
+	data []byte
+}
+
+// newSessionInfo returns a session without queued messages
+func newSessionInfo(sessionID, clientID string) *SessionInfo {
+	now := time.Now()
+	return &SessionInfo{
+		ID:        sessionID,
+		CreatedAt: now,
+		LastSeen:  now,
+		ClientID:  clientID,
+		events:    make(chan sessionEvent, sessionEventBuffer),
+		closed:    make(chan struct{}),
+	}
+}
+
+// queue adds a message to the session's event stream, dropping it if the queue
+// is full; the caller holds sessionsMu
+func (s *SessionInfo) queue(message []byte) bool {
+	select {
+	case s.events <- sessionEvent{id: s.lastEvent + 1, data: message}:
+		s.lastEvent++
+		return true
+	default:
+		return false
+	}
 }
 
 // HTTPHeaderFunc is a function type for generating dynamic headers
@@ -113,6 +344,9 @@ func NewTransport(addr string, options ...Option) *Transport {
 		pathPrefix:     "", // Empty by default
 		mcpEndpoint:    DefaultMCPEndpoint,
 		enableSessions: true, // Enable sessions by default for 2025-03-26
+		sessionTimeout: DefaultSessionTimeout,
+		limits:         transport.DefaultServerLimits(),
+		done:           make(chan struct{}),
 	}
 
 	// Apply options
@@ -173,21 +407,154 @@ func (t *Transport) startServer() error {
 	// Register the MCP endpoint
 	mux.HandleFunc(t.GetFullMCPEndpoint(), t.handleMCPRequest)
 
-	t.server = &http.Server{
-		Addr:    t.addr,
-		Handler: mux,
+	tlsConfig, err := t.serverTLSConfig()
+	if err != nil {
+		return err
+	}
+
+	listener, err := transport.Listen(t.addr, t.limits)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", t.addr, err)
 	}
 
+	handler, listener, err := transport.GuardServer(t.accessPolicy, t.reportViolation, mux, listener)
+	if err != nil {
+		listener.Close()
+		return fmt.Errorf("invalid access policy: %w", err)
+	}
+
+	t.server = transport.NewHTTPServer(t.addr, t.accessLogHandler(handler), t.limits)
+	t.server.TLSConfig = tlsConfig
+
 	// Start the server in a goroutine
 	go func() {
-		if err := t.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if tlsConfig != nil {
+			// Certificates come from the TLS config
+			err = t.server.ServeTLS(listener, "", "")
+		} else {
+			err = t.server.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
 			t.GetLogger().Error("HTTP server error", "error", err)
 		}
 	}()
 
+	if t.enableSessions && t.sessionTimeout > 0 {
+		go t.expireSessions()
+	}
+
 	return nil
 }
 
+// expireSessions ends idle sessions until the transport stops
+func (t *Transport) expireSessions() {
+	ticker := time.NewTicker(t.sessionTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.expireIdleSessions(time.Now())
+		case <-t.done:
+			return
+		}
+	}
+}
+
+// expireIdleSessions ends the sessions without an open event stream that have
+// had no requests for longer than the session timeout
+func (t *Transport) expireIdleSessions(now time.Time) {
+	var expired []string
+	t.sessionsMu.Lock()
+	for sessionID, session := range t.sessions {
+		if !session.streaming && now.Sub(session.LastSeen) > t.sessionTimeout {
+			delete(t.sessions, sessionID)
+			close(session.closed)
+			expired = append(expired, sessionID)
+		}
+	}
+	t.sessionsMu.Unlock()
+
+	for _, sessionID := range expired {
+		t.GetLogger().Debug("Session expired", "sessionID", sessionID)
+		t.endSession(sessionID)
+	}
+}
+
+// SetSessionEndHandler sets the handler called for every session that the client
+// terminates or that expires.
+func (t *Transport) SetSessionEndHandler(handler transport.SessionEndHandler) {
+	t.sessionEndHandler = handler
+}
+
+// endSession passes an ended session to the session end handler, if any
+func (t *Transport) endSession(sessionID string) {
+	if t.sessionEndHandler != nil {
+		t.sessionEndHandler(sessionID)
+	}
+}
+
+// SetViolationHandler sets the handler called for every client the access policy
+// rejects.
+func (t *Transport) SetViolationHandler(handler transport.ViolationHandler) {
+	t.violationHandler = handler
+}
+
+// reportViolation passes a rejected client to the violation handler, if any
+func (t *Transport) reportViolation(violation transport.Violation) {
+	if t.violationHandler != nil {
+		t.violationHandler(violation)
+	}
+}
+
+// accessLogHandler wraps the server's handler with the access log, if enabled
+func (t *Transport) accessLogHandler(handler http.Handler) http.Handler {
+	if t.accessLog == nil {
+		return handler
+	}
+	config := *t.accessLog
+	if config.Logger == nil {
+		config.Logger = t.GetLogger()
+	}
+	return transport.AccessLog(handler, config)
+}
+
+// serverTLSConfig returns the server's TLS configuration, or nil to serve plain HTTP
+func (t *Transport) serverTLSConfig() (*tls.Config, error) {
+	if t.tlsConfig != nil {
+		return t.tlsConfig, nil
+	}
+	if t.tlsCertFile == "" && t.tlsKeyFile == "" {
+		if t.tlsClientCAFile != "" {
+			return nil, errors.New("mutual TLS requires a server certificate, use WithTLS")
+		}
+		return nil, nil
+	}
+
+	config, err := transport.LoadServerTLSConfig(t.tlsCertFile, t.tlsKeyFile, t.tlsClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	return config, nil
+}
+
+// clientTLSConfig returns the client's TLS configuration, or nil to use the defaults
+func (t *Transport) clientTLSConfig() (*tls.Config, error) {
+	if t.tlsConfig != nil {
+		return t.tlsConfig, nil
+	}
+	if t.tlsRootCAFile == "" && t.tlsClientCertFile == "" && t.tlsClientKeyFile == "" {
+		return nil, nil
+	}
+
+	config, err := transport.LoadClientTLSConfig(t.tlsRootCAFile, t.tlsClientCertFile, t.tlsClientKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	return config, nil
+}
+
 // startClient starts the HTTP client
 func (t *Transport) startClient() error {
 	// Parse the server URL
@@ -203,6 +570,15 @@ func (t *Transport) startClient() error {
 	}
 	t.url = strings.TrimSuffix(t.url, "/") + t.GetFullMCPEndpoint()
 
+	// Apply the client TLS configuration, if any
+	tlsConfig, err := t.clientTLSConfig()
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		t.client = transport.HTTPClientWithTLS(t.client, tlsConfig)
+	}
+
 	return nil
 }
 
@@ -217,6 +593,9 @@ func (t *Transport) Stop() error {
 	}
 
 	if t.server != nil {
+		// Event streams stay open until told to end, which Shutdown would wait for
+		t.stopOnce.Do(func() { close(t.done) })
+
 		ctx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
 		defer cancel()
 		return t.server.Shutdown(ctx)
@@ -249,7 +628,7 @@ func (t *Transport) sendClientRequest(message []byte) error {
 
 	// Add session ID if available
 	if sessionID := t.sessionID.Load(); sessionID != nil {
-		req.Header.Set("MCP-Session-ID", *sessionID)
+		req.Header.Set(SessionIDHeader, *sessionID)
 	}
 
 	// Add custom headers
@@ -265,10 +644,16 @@ func (t *Transport) sendClientRequest(message []byte) error {
 	defer resp.Body.Close()
 
 	// Check for session ID in response
-	if sessionID := resp.Header.Get("MCP-Session-ID"); sessionID != "" {
+	if sessionID := resp.Header.Get(SessionIDHeader); sessionID != "" {
 		t.sessionID.Store(&sessionID)
 	}
 
+	// The server no longer knows our session; a new one must be initialized
+	if resp.StatusCode == http.StatusNotFound && req.Header.Get(SessionIDHeader) != "" {
+		t.sessionID.Store(nil)
+		return fmt.Errorf("session %s not found on server", req.Header.Get(SessionIDHeader))
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("POST request returned status code %d", resp.StatusCode)
 	}
@@ -278,34 +663,38 @@ func (t *Transport) sendClientRequest(message []byte) error {
 
 // sendServerMessage sends a message from server to clients via SSE streams
 func (t *Transport) sendServerMessage(message []byte) error {
-	// For server mode, we need to send the message via SSE streams
-	// This would typically involve maintaining active SSE connections
-	// and sending the message as SSE events to connected clients
-
-	// For now, we'll store the message to be sent when clients connect
-	// In a full implementation, this would maintain active SSE connections
 	t.sessionsMu.Lock()
 	defer t.sessionsMu.Unlock()
 
-	// Send to all active sessions (simplified implementation)
-	// In production, this would send via established SSE streams
 	for sessionID, session := range t.sessions {
-		// Check if session is still active (within last 5 minutes)
-		if time.Since(session.LastSeen) > 5*time.Minute {
-			delete(t.sessions, sessionID)
-			continue
+		if !session.queue(message) {
+			// Queue full, message dropped (could be a slow client)
+			t.GetLogger().Warn("Event stream queue full, dropping message", "sessionID", sessionID)
 		}
-
-		// In a full implementation, this would write to the SSE stream
-		// associated with this session. For now, we'll log it.
-		t.GetLogger().Info("Would send SSE message to session",
-			"sessionID", sessionID,
-			"messageSize", len(message))
 	}
 
-	// TODO: Implement actual SSE message sending to active streams
-	// This requires maintaining a map of active SSE connections
+	return nil
+}
+
+// SendToSession sends a message to the event stream of the session with the given
+// ID, the ID the transport passes to the session message handler for POSTs
+// carrying it in the Mcp-Session-Id header. Messages are queued until the client
+// opens the stream with a GET request. Sending to a session that has ended fails.
+func (t *Transport) SendToSession(sessionID string, message []byte) error {
+	if t.isClient {
+		return t.Send(message)
+	}
 
+	t.sessionsMu.Lock()
+	defer t.sessionsMu.Unlock()
+	session, exists := t.sessions[sessionID]
+	if !exists {
+		return fmt.Errorf("no HTTP session %s", sessionID)
+	}
+	if !session.queue(message) {
+		// Queue full, message dropped, as sendServerMessage does for slow clients
+		t.GetLogger().Warn("Event stream queue full, dropping message", "sessionID", sessionID)
+	}
 	return nil
 }
 
@@ -324,73 +713,68 @@ func (t *Transport) handleMCPRequest(w http.ResponseWriter, r *http.Request) {
 	case http.MethodDelete:
 		t.handleSessionTermination(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		transport.WriteJSONRPCError(w, http.StatusMethodNotAllowed, transport.JSONRPCServerError, "Method not allowed")
 	}
 }
 
 // handleClientMessage handles POST requests from clients
 func (t *Transport) handleClientMessage(w http.ResponseWriter, r *http.Request) {
-	// Validate Content-Type
-	contentType := r.Header.Get("Content-Type")
-	if !strings.Contains(strings.ToLower(contentType), "application/json") {
-		http.Error(w, "Content-Type must be application/json", http.StatusBadRequest)
+	// Validate Content-Type and Accept; responses are always JSON
+	if !transport.IsJSONContentType(r.Header.Get("Content-Type")) {
+		transport.WriteJSONRPCError(w, http.StatusUnsupportedMediaType, transport.JSONRPCInvalidRequest,
+			"Content-Type must be application/json")
+		return
+	}
+	if !transport.Accepts(r.Header.Get("Accept"), "application/json") {
+		transport.WriteJSONRPCError(w, http.StatusNotAcceptable, transport.JSONRPCServerError,
+			"Accept header must include application/json")
 		return
 	}
 
 	// Read request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		transport.WriteJSONRPCError(w, http.StatusBadRequest, transport.JSONRPCInvalidRequest, "Failed to read request body")
 		return
 	}
 	defer r.Body.Close()
 
-	// Handle session management
-	sessionID := r.Header.Get("MCP-Session-ID")
+	if !json.Valid(body) {
+		transport.WriteJSONRPCError(w, http.StatusBadRequest, transport.JSONRPCParseError, "Parse error: request body is not valid JSON")
+		return
+	}
+
+	// Handle session management: initialize starts a session, every
+	// other request must carry the ID that was handed out
+	var sessionID string
 	if t.enableSessions {
-		if sessionID == "" {
-			// Create new session
-			sessionID = t.generateSessionID()
-			w.Header().Set("MCP-Session-ID", sessionID)
-
-			t.sessionsMu.Lock()
-			t.sessions[sessionID] = &SessionInfo{
-				ID:        sessionID,
-				CreatedAt: time.Now(),
-				LastSeen:  time.Now(),
-				ClientID:  r.RemoteAddr,
-			}
-			t.sessionsMu.Unlock()
+		if isInitializeRequest(body) {
+			sessionID = t.createSession(r.RemoteAddr)
+			w.Header().Set(SessionIDHeader, sessionID)
 		} else {
-			// Update existing session
-			t.sessionsMu.Lock()
-			if session, exists := t.sessions[sessionID]; exists {
-				session.LastSeen = time.Now()
-				w.Header().Set("MCP-Session-ID", sessionID)
-			} else {
-				// Session not found, create new one
-				sessionID = t.generateSessionID()
-				w.Header().Set("MCP-Session-ID", sessionID)
-				t.sessions[sessionID] = &SessionInfo{
-					ID:        sessionID,
-					CreatedAt: time.Now(),
-					LastSeen:  time.Now(),
-					ClientID:  r.RemoteAddr,
-				}
+			if !t.validateSession(w, r) {
+				return
 			}
-			t.sessionsMu.Unlock()
+			sessionID = r.Header.Get(SessionIDHeader)
 		}
 	}
 
-	// Handle the message
-	response, err := t.HandleMessage(body)
+	// Handle the message on its session
+	response, err := t.HandleSessionMessage(sessionID, body)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Message handling failed: %v", err), http.StatusInternalServerError)
+		transport.WriteJSONRPCError(w, http.StatusInternalServerError, transport.JSONRPCInternalError,
+			fmt.Sprintf("Message handling failed: %v", err))
 		return
 	}
 
 	// Set response headers
 	w.Header().Set("Content-Type", "application/json")
+	t.sessionsMu.Lock()
+	if session, exists := t.sessions[sessionID]; exists && session.lastEvent > 0 {
+		w.Header().Set(LastEventIDHeader, strconv.FormatUint(session.lastEvent, 10))
+	}
+	t.sessionsMu.Unlock()
 
 	// Send JSON response
 	if _, err := w.Write(response); err != nil {
@@ -398,59 +782,85 @@ func (t *Transport) handleClientMessage(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// handleSSEStream handles GET requests for SSE streams
+// handleSSEStream handles GET requests for SSE streams, on which the messages
+// sent to a session reach its client. A session has one stream at a time.
 func (t *Transport) handleSSEStream(w http.ResponseWriter, r *http.Request) {
 	// Check Accept header for text/event-stream
 	accept := r.Header.Get("Accept")
 	if !strings.Contains(accept, "text/event-stream") {
-		http.Error(w, "text/event-stream not accepted", http.StatusNotAcceptable)
+		transport.WriteJSONRPCError(w, http.StatusNotAcceptable, transport.JSONRPCServerError,
+			"Accept header must include text/event-stream")
 		return
 	}
 
-	// Set SSE headers
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-
-	// Handle session management
-	sessionID := r.Header.Get("MCP-Session-ID")
-	if t.enableSessions && sessionID != "" {
-		t.sessionsMu.Lock()
-		if session, exists := t.sessions[sessionID]; exists {
-			session.LastSeen = time.Now()
-			w.Header().Set("MCP-Session-ID", sessionID)
-		} else {
-			// Session not found
-			http.Error(w, "Session not found", http.StatusNotFound)
-			t.sessionsMu.Unlock()
-			return
-		}
-		t.sessionsMu.Unlock()
+	// Messages are sent to sessions, so there is no stream without one
+	if !t.enableSessions {
+		w.Header().Set("Allow", "POST")
+		transport.WriteJSONRPCError(w, http.StatusMethodNotAllowed, transport.JSONRPCServerError, "Session management is disabled")
+		return
+	}
+	if !t.validateSession(w, r) {
+		return
 	}
 
 	// Start SSE stream
 	flusher, ok := w.(http.Flusher)
 	if !ok {
-		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		transport.WriteJSONRPCError(w, http.StatusInternalServerError, transport.JSONRPCInternalError, "Streaming not supported")
+		return
+	}
+
+	t.sessionsMu.Lock()
+	session, exists := t.sessions[r.Header.Get(SessionIDHeader)]
+	busy := exists && session.streaming
+	if exists && !busy {
+		session.streaming = true
+	}
+	t.sessionsMu.Unlock()
+	if !exists {
+		transport.WriteJSONRPCError(w, http.StatusNotFound, transport.JSONRPCSessionNotFound, "Session not found")
+		return
+	}
+	if busy {
+		transport.WriteJSONRPCError(w, http.StatusConflict, transport.JSONRPCServerError, "The session already has an event stream")
 		return
 	}
+	defer func() {
+		t.sessionsMu.Lock()
+		session.streaming = false
+		session.LastSeen = time.Now()
+		t.sessionsMu.Unlock()
+	}()
+
+	// Set SSE headers
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
 
-	// Keep the connection alive and handle server-initiated messages
-	// For now, this is a placeholder - in a real implementation,
-	// this would listen for messages to send to the client
 	ctx := r.Context()
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		default:
-			// This is where server-initiated messages would be sent
-			// For the basic implementation, we just keep the connection open
-			time.Sleep(1 * time.Second)
-
-			// Send a heartbeat event to keep connection alive
-			fmt.Fprintf(w, "data: {\"type\":\"heartbeat\",\"timestamp\":\"%s\"}\n\n", time.Now().Format(time.RFC3339))
+		case <-t.done:
+			return
+		case <-session.closed:
+			return
+		case event := <-session.events:
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: message\ndata: %s\n\n", event.id, event.data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
 			flusher.Flush()
 		}
 	}
@@ -458,19 +868,32 @@ func (t *Transport) handleSSEStream(w http.ResponseWriter, r *http.Request) {
 
 // handleSessionTermination handles DELETE requests for session termination
 func (t *Transport) handleSessionTermination(w http.ResponseWriter, r *http.Request) {
-	sessionID := r.Header.Get("MCP-Session-ID")
+	if !t.enableSessions {
+		w.Header().Set("Allow", "GET, POST")
+		transport.WriteJSONRPCError(w, http.StatusMethodNotAllowed, transport.JSONRPCServerError, "Session management is disabled")
+		return
+	}
+
+	sessionID := r.Header.Get(SessionIDHeader)
 	if sessionID == "" {
-		http.Error(w, "Missing MCP-Session-ID header", http.StatusBadRequest)
+		transport.WriteJSONRPCError(w, http.StatusBadRequest, transport.JSONRPCInvalidRequest, "Missing "+SessionIDHeader+" header")
 		return
 	}
 
 	t.sessionsMu.Lock()
-	_, exists := t.sessions[sessionID]
+	session, exists := t.sessions[sessionID]
 	if exists {
 		delete(t.sessions, sessionID)
+		close(session.closed)
 	}
 	t.sessionsMu.Unlock()
 
+	if !exists {
+		transport.WriteJSONRPCError(w, http.StatusNotFound, transport.JSONRPCSessionNotFound, "Session not found")
+		return
+	}
+	t.endSession(sessionID)
+
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(`{"status":"session_terminated"}`))
 }
@@ -482,7 +905,10 @@ func (t *Transport) terminateSession(sessionID string) error {
 		return fmt.Errorf("failed to create DELETE request: %w", err)
 	}
 
-	req.Header.Set("MCP-Session-ID", sessionID)
+	req.Header.Set(SessionIDHeader, sessionID)
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
 
 	resp, err := t.client.Do(req)
 	if err != nil {
@@ -490,16 +916,103 @@ func (t *Transport) terminateSession(sessionID string) error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	t.sessionID.Store(nil)
+
+	// 405 means the server does not allow clients to terminate sessions,
+	// 404 means the session is already gone
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound, http.StatusMethodNotAllowed:
+		return nil
+	default:
 		return fmt.Errorf("DELETE request returned status code %d", resp.StatusCode)
 	}
+}
 
-	t.sessionID.Store(nil)
-	return nil
+// createSession registers a new session and returns its ID
+func (t *Transport) createSession(clientID string) string {
+	sessionID := t.generateSessionID()
+
+	t.sessionsMu.Lock()
+	t.sessions[sessionID] = newSessionInfo(sessionID, clientID)
+	t.sessionsMu.Unlock()
+
+	return sessionID
+}
+
+// ImportSession accepts requests on a session created by another server, such as
+// a session handed over during a blue/green deployment.
+func (t *Transport) ImportSession(sessionID string) {
+	t.sessionsMu.Lock()
+	defer t.sessionsMu.Unlock()
+	if _, exists := t.sessions[sessionID]; !exists {
+		t.sessions[sessionID] = newSessionInfo(sessionID, "")
+	}
+}
+
+// validateSession checks the session header of a request, writing a 400 response
+// if it is missing and a 404 response if the session is unknown or terminated.
+// It returns true if the request may proceed.
+func (t *Transport) validateSession(w http.ResponseWriter, r *http.Request) bool {
+	sessionID := r.Header.Get(SessionIDHeader)
+	if sessionID == "" {
+		transport.WriteJSONRPCError(w, http.StatusBadRequest, transport.JSONRPCInvalidRequest, "Missing "+SessionIDHeader+" header")
+		return false
+	}
+
+	t.sessionsMu.Lock()
+	session, exists := t.sessions[sessionID]
+	if exists {
+		session.LastSeen = time.Now()
+	}
+	t.sessionsMu.Unlock()
+
+	if !exists {
+		transport.WriteJSONRPCError(w, http.StatusNotFound, transport.JSONRPCSessionNotFound, "Session not found")
+		return false
+	}
+
+	w.Header().Set(SessionIDHeader, sessionID)
+	return true
+}
+
+// isInitializeRequest reports whether a message (or any message of a batch) is an
+// initialize request
+func isInitializeRequest(body []byte) bool {
+	var single struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(body, &single); err == nil {
+		return single.Method == "initialize"
+	}
+
+	var batch []struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return false
+	}
+	for _, msg := range batch {
+		if msg.Method == "initialize" {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionID returns the session ID assigned by the server, or an empty string
+// if the client has no active session
+func (t *Transport) SessionID() string {
+	if sessionID := t.sessionID.Load(); sessionID != nil {
+		return *sessionID
+	}
+	return ""
 }
 
 // generateSessionID generates a random session ID
 func (t *Transport) generateSessionID() string {
+	if t.sessionIDGenerator != nil {
+		return t.sessionIDGenerator()
+	}
 	bytes := make([]byte, 16)
 	rand.Read(bytes)
 	return hex.EncodeToString(bytes)
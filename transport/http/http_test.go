@@ -2,6 +2,7 @@ package http
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -131,7 +132,7 @@ func TestServerMode(t *testing.T) {
 	// Test POST request to MCP endpoint
 	requestBody := map[string]interface{}{
 		"jsonrpc": "2.0",
-		"method":  "test",
+		"method":  "initialize",
 		"id":      1,
 	}
 	reqBytes, _ := json.Marshal(requestBody)
@@ -176,9 +177,9 @@ func TestSessionPersistence(t *testing.T) {
 		return []byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`), nil
 	})
 
-	// First request - creates session
-	reqBytes := []byte(`{"jsonrpc":"2.0","method":"test","id":1}`)
-	req1 := httptest.NewRequest("POST", tr.GetFullMCPEndpoint(), bytes.NewReader(reqBytes))
+	// First request - initialize creates session
+	initBytes := []byte(`{"jsonrpc":"2.0","method":"initialize","id":1}`)
+	req1 := httptest.NewRequest("POST", tr.GetFullMCPEndpoint(), bytes.NewReader(initBytes))
 	req1.Header.Set("Content-Type", "application/json")
 
 	w1 := httptest.NewRecorder()
@@ -190,6 +191,7 @@ func TestSessionPersistence(t *testing.T) {
 	}
 
 	// Second request - uses existing session
+	reqBytes := []byte(`{"jsonrpc":"2.0","method":"test","id":2}`)
 	req2 := httptest.NewRequest("POST", tr.GetFullMCPEndpoint(), bytes.NewReader(reqBytes))
 	req2.Header.Set("Content-Type", "application/json")
 	req2.Header.Set("MCP-Session-ID", sessionID)
@@ -217,19 +219,106 @@ func TestSessionPersistence(t *testing.T) {
 	}
 }
 
+func TestSessionMessageHandler(t *testing.T) {
+	tr := NewTransport("127.0.0.1:0")
+	tr.isClient = false
+
+	var received []string
+	tr.SetSessionMessageHandler(func(sessionID string, message []byte) ([]byte, error) {
+		received = append(received, sessionID)
+		return []byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`), nil
+	})
+
+	post := func(body, sessionID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", tr.GetFullMCPEndpoint(), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(SessionIDHeader, sessionID)
+		}
+		w := httptest.NewRecorder()
+		tr.handleMCPRequest(w, req)
+		return w
+	}
+
+	sessionID := post(`{"jsonrpc":"2.0","method":"initialize","id":1}`, "").Header().Get(SessionIDHeader)
+	post(`{"jsonrpc":"2.0","method":"ping","id":2}`, sessionID)
+
+	if len(received) != 2 || received[0] != sessionID || received[1] != sessionID {
+		t.Errorf("Expected both messages on session %s, got %v", sessionID, received)
+	}
+}
+
+func TestSessionEventStream(t *testing.T) {
+	tr := NewTransport("127.0.0.1:0")
+	tr.isClient = false
+
+	// Requests send a notification to their session before answering
+	tr.SetSessionMessageHandler(func(sessionID string, message []byte) ([]byte, error) {
+		if err := tr.SendToSession(sessionID, []byte(`{"jsonrpc":"2.0","method":"notifications/progress"}`)); err != nil {
+			return nil, err
+		}
+		return []byte(`{"jsonrpc":"2.0","id":1,"result":{}}`), nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(tr.handleMCPRequest))
+	defer server.Close()
+	defer tr.Stop()
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","method":"initialize","id":1}`))
+	if err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	resp.Body.Close()
+	sessionID := resp.Header.Get(SessionIDHeader)
+	if got := resp.Header.Get(LastEventIDHeader); got != "1" {
+		t.Errorf("Expected the response to follow event 1, got %q", got)
+	}
+
+	// The notification was queued until the stream opened
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(SessionIDHeader, sessionID)
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open the event stream: %v", err)
+	}
+	defer stream.Body.Close()
+	if stream.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the event stream to open, got status %d", stream.StatusCode)
+	}
+
+	want := "id: 1\nevent: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n"
+	event := make([]byte, len(want))
+	if _, err := io.ReadFull(stream.Body, event); err != nil {
+		t.Fatalf("Failed to read the event: %v", err)
+	}
+	if string(event) != want {
+		t.Errorf("Expected event %q, got %q", want, event)
+	}
+
+	// A session has one stream at a time
+	second, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to request a second stream: %v", err)
+	}
+	second.Body.Close()
+	if second.StatusCode != http.StatusConflict {
+		t.Errorf("Expected a second stream to be refused, got status %d", second.StatusCode)
+	}
+}
+
 func TestSessionTermination(t *testing.T) {
 	tr := NewTransport("127.0.0.1:0")
 	tr.isClient = false
 
+	var ended string
+	tr.SetSessionEndHandler(func(sessionID string) {
+		ended = sessionID
+	})
+
 	// Create a session first
 	sessionID := tr.generateSessionID()
 	tr.sessionsMu.Lock()
-	tr.sessions[sessionID] = &SessionInfo{
-		ID:        sessionID,
-		CreatedAt: time.Now(),
-		LastSeen:  time.Now(),
-		ClientID:  "test",
-	}
+	tr.sessions[sessionID] = newSessionInfo(sessionID, "test")
 	tr.sessionsMu.Unlock()
 
 	// Test DELETE request
@@ -251,6 +340,172 @@ func TestSessionTermination(t *testing.T) {
 	if exists {
 		t.Error("Session should have been deleted")
 	}
+	if ended != sessionID {
+		t.Errorf("Expected the session end handler to get %q, got %q", sessionID, ended)
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	tr := NewTransport("127.0.0.1:0", WithSessionTimeout(time.Minute))
+	var ended []string
+	tr.SetSessionEndHandler(func(sessionID string) {
+		ended = append(ended, sessionID)
+	})
+
+	now := time.Now()
+	idle := newSessionInfo("idle", "test")
+	idle.LastSeen = now.Add(-2 * time.Minute)
+	streaming := newSessionInfo("streaming", "test")
+	streaming.LastSeen = now.Add(-2 * time.Minute)
+	streaming.streaming = true
+	tr.sessionsMu.Lock()
+	tr.sessions["idle"] = idle
+	tr.sessions["streaming"] = streaming
+	tr.sessions["active"] = newSessionInfo("active", "test")
+	tr.sessionsMu.Unlock()
+
+	tr.expireIdleSessions(now)
+
+	if len(ended) != 1 || ended[0] != "idle" {
+		t.Fatalf("Expected only the idle session to end, got %v", ended)
+	}
+	select {
+	case <-idle.closed:
+	default:
+		t.Error("Expected the expired session to be closed")
+	}
+	tr.sessionsMu.Lock()
+	remaining := len(tr.sessions)
+	tr.sessionsMu.Unlock()
+	if remaining != 2 {
+		t.Errorf("Expected the streaming and active sessions to remain, got %d sessions", remaining)
+	}
+}
+
+func TestSessionValidation(t *testing.T) {
+	tr := NewTransport("127.0.0.1:0")
+	tr.isClient = false
+
+	tr.SetMessageHandler(func(message []byte) ([]byte, error) {
+		return []byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`), nil
+	})
+
+	post := func(body, sessionID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", tr.GetFullMCPEndpoint(), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(SessionIDHeader, sessionID)
+		}
+		w := httptest.NewRecorder()
+		tr.handleMCPRequest(w, req)
+		return w
+	}
+
+	// Requests before initialize are rejected
+	if w := post(`{"jsonrpc":"2.0","method":"tools/list","id":1}`, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without session ID, got %d", w.Code)
+	}
+
+	// Unknown sessions are rejected
+	if w := post(`{"jsonrpc":"2.0","method":"tools/list","id":1}`, "unknown"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown session, got %d", w.Code)
+	}
+
+	// Imported sessions are accepted without initialize
+	tr.ImportSession("imported")
+	if w := post(`{"jsonrpc":"2.0","method":"tools/list","id":1}`, "imported"); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for an imported session, got %d", w.Code)
+	}
+
+	// A batch containing initialize starts a session
+	w := post(`[{"jsonrpc":"2.0","method":"initialize","id":1}]`, "")
+	sessionID := w.Header().Get(SessionIDHeader)
+	if w.Code != http.StatusOK || sessionID == "" {
+		t.Fatalf("Expected initialize to assign a session, got status %d", w.Code)
+	}
+
+	if w := post(`{"jsonrpc":"2.0","method":"tools/list","id":2}`, sessionID); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 with valid session, got %d", w.Code)
+	}
+
+	// Terminated sessions are rejected
+	del := httptest.NewRequest("DELETE", tr.GetFullMCPEndpoint(), nil)
+	del.Header.Set(SessionIDHeader, sessionID)
+	dw := httptest.NewRecorder()
+	tr.handleMCPRequest(dw, del)
+	if dw.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for DELETE, got %d", dw.Code)
+	}
+
+	if w := post(`{"jsonrpc":"2.0","method":"tools/list","id":3}`, sessionID); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after termination, got %d", w.Code)
+	}
+
+	dw = httptest.NewRecorder()
+	tr.handleMCPRequest(dw, del)
+	if dw.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for second DELETE, got %d", dw.Code)
+	}
+}
+
+func TestSessionOptions(t *testing.T) {
+	tr := NewTransport("127.0.0.1:0", WithSessionIDGenerator(func() string {
+		return "replica-1.abc"
+	}))
+	tr.SetMessageHandler(func(message []byte) ([]byte, error) {
+		return []byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`), nil
+	})
+
+	req := httptest.NewRequest("POST", tr.GetFullMCPEndpoint(), strings.NewReader(`{"jsonrpc":"2.0","method":"initialize","id":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	tr.handleMCPRequest(w, req)
+	if got := w.Header().Get(SessionIDHeader); got != "replica-1.abc" {
+		t.Errorf("Expected generated session ID, got %q", got)
+	}
+
+	// Without sessions, requests need no session header
+	plain := NewTransport("127.0.0.1:0", WithSessions(false))
+	plain.SetMessageHandler(func(message []byte) ([]byte, error) {
+		return []byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`), nil
+	})
+	req = httptest.NewRequest("POST", plain.GetFullMCPEndpoint(), strings.NewReader(`{"jsonrpc":"2.0","method":"tools/list","id":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	plain.handleMCPRequest(w, req)
+	if w.Code != http.StatusOK || w.Header().Get(SessionIDHeader) != "" {
+		t.Errorf("Expected sessionless request to succeed, got status %d", w.Code)
+	}
+}
+
+func TestClientSessionExpired(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(SessionIDHeader) == "stale" {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set(SessionIDHeader, "fresh")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+	}))
+	defer server.Close()
+
+	tr := NewClientTransport(server.URL)
+	stale := "stale"
+	tr.sessionID.Store(&stale)
+
+	if err := tr.Send([]byte(`{"jsonrpc":"2.0","method":"tools/list","id":1}`)); err == nil {
+		t.Fatal("Expected error for expired session")
+	}
+	if tr.SessionID() != "" {
+		t.Errorf("Expected session ID to be cleared, got %q", tr.SessionID())
+	}
+
+	if err := tr.Send([]byte(`{"jsonrpc":"2.0","method":"initialize","id":2}`)); err != nil {
+		t.Fatalf("Failed to re-initialize: %v", err)
+	}
+	if tr.SessionID() != "fresh" {
+		t.Errorf("Expected new session ID, got %q", tr.SessionID())
+	}
 }
 
 func TestClientMode(t *testing.T) {
@@ -310,8 +565,8 @@ func TestInvalidContentType(t *testing.T) {
 	w := httptest.NewRecorder()
 	tr.handleMCPRequest(w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("Expected status 400, got %d", w.Code)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected status 415, got %d", w.Code)
 	}
 
 	if !strings.Contains(w.Body.String(), "Content-Type must be application/json") {
@@ -332,6 +587,78 @@ func TestMethodNotAllowed(t *testing.T) {
 	}
 }
 
+func TestJSONRPCErrorResponses(t *testing.T) {
+	tr := NewTransport("127.0.0.1:0")
+	tr.SetMessageHandler(func(message []byte) ([]byte, error) {
+		return message, nil
+	})
+
+	tests := []struct {
+		name        string
+		method      string
+		body        string
+		contentType string
+		accept      string
+		session     string
+		status      int
+		code        int
+	}{
+		{"malformed JSON", "POST", `{"jsonrpc":`, "application/json", "", "", http.StatusBadRequest, transport.JSONRPCParseError},
+		{"wrong method", "PUT", "", "", "", "", http.StatusMethodNotAllowed, transport.JSONRPCServerError},
+		{"wrong content type", "POST", `{}`, "text/plain", "", "", http.StatusUnsupportedMediaType, transport.JSONRPCInvalidRequest},
+		{"unsupported accept", "POST", `{}`, "application/json", "text/html", "", http.StatusNotAcceptable, transport.JSONRPCServerError},
+		{"missing session", "POST", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`, "application/json", "application/json, text/event-stream", "", http.StatusBadRequest, transport.JSONRPCInvalidRequest},
+		{"unknown session", "POST", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`, "application/json", "", "unknown", http.StatusNotFound, transport.JSONRPCSessionNotFound},
+		{"stream not accepted", "GET", "", "", "application/json", "", http.StatusNotAcceptable, transport.JSONRPCServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tr.GetFullMCPEndpoint(), strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			if tt.session != "" {
+				req.Header.Set(SessionIDHeader, tt.session)
+			}
+			w := httptest.NewRecorder()
+			tr.handleMCPRequest(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Expected a JSON response, got Content-Type %q", ct)
+			}
+
+			var response struct {
+				JSONRPC string          `json:"jsonrpc"`
+				ID      json.RawMessage `json:"id"`
+				Error   struct {
+					Code    int    `json:"code"`
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Expected a JSON-RPC error body, got %q", w.Body.String())
+			}
+			if response.JSONRPC != "2.0" || string(response.ID) != "null" || response.Error.Code != tt.code || response.Error.Message == "" {
+				t.Errorf("Unexpected error response %s", w.Body.String())
+			}
+		})
+	}
+
+	req := httptest.NewRequest("PUT", tr.GetFullMCPEndpoint(), nil)
+	w := httptest.NewRecorder()
+	tr.handleMCPRequest(w, req)
+	if allow := w.Header().Get("Allow"); allow != "GET, POST, DELETE" {
+		t.Errorf("Expected the Allow header to list the supported methods, got %q", allow)
+	}
+}
+
 func TestReceiveNotSupported(t *testing.T) {
 	tr := NewTransport("localhost:8080")
 	tr.SetClientMode(true)
@@ -402,3 +729,33 @@ func TestTransportInterface(t *testing.T) {
 		t.Errorf("Expected protocol version %s, got %s", version, tr.GetProtocolVersion())
 	}
 }
+
+func TestTLSOptions(t *testing.T) {
+	// Mutual TLS needs a server certificate
+	tr := NewServerTransport("127.0.0.1:0", WithMutualTLS("ca.pem"))
+	if err := tr.Start(); err == nil || !strings.Contains(err.Error(), "server certificate") {
+		t.Errorf("Expected error for mutual TLS without certificate, got %v", err)
+	}
+
+	// Certificate files are loaded on start
+	tr = NewServerTransport("127.0.0.1:0", WithTLS("missing.pem", "missing-key.pem"))
+	if err := tr.Start(); err == nil || !strings.Contains(err.Error(), "failed to configure TLS") {
+		t.Errorf("Expected error for missing certificate files, got %v", err)
+	}
+
+	client := NewClientTransport("https://127.0.0.1:8443", WithRootCAs("missing-ca.pem"))
+	if err := client.Start(); err == nil || !strings.Contains(err.Error(), "failed to configure TLS") {
+		t.Errorf("Expected error for missing CA file, got %v", err)
+	}
+
+	// A TLS config is applied to the client's connections
+	config := &tls.Config{ServerName: "mcp.example.com"}
+	client = NewClientTransport("https://127.0.0.1:8443", WithTLSConfig(config))
+	if err := client.Start(); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	roundTripper, ok := client.client.Transport.(*http.Transport)
+	if !ok || roundTripper.TLSClientConfig != config {
+		t.Error("Expected the client to use the TLS config")
+	}
+}
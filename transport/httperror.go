@@ -0,0 +1,71 @@
+package transport
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// JSON-RPC error codes HTTP-based transports answer malformed requests with,
+// before a message reaches the server.
+const (
+	// JSONRPCParseError is the code for a body that is not valid JSON
+	JSONRPCParseError = -32700
+
+	// JSONRPCInvalidRequest is the code for a request the transport cannot
+	// accept, such as one with the wrong Content-Type or without a session
+	JSONRPCInvalidRequest = -32600
+
+	// JSONRPCInternalError is the code for a message the server failed to handle
+	JSONRPCInternalError = -32603
+
+	// JSONRPCServerError is the code for HTTP-level errors, such as a method the
+	// endpoint does not support or an Accept header it cannot satisfy
+	JSONRPCServerError = -32000
+
+	// JSONRPCSessionNotFound is the code for a request on an unknown or
+	// terminated session, which clients answer by initializing a new session
+	JSONRPCSessionNotFound = -32001
+)
+
+// WriteJSONRPCError answers an HTTP request with a JSON-RPC error response, so
+// that clients can parse the error like any other, instead of plain text.
+func WriteJSONRPCError(w http.ResponseWriter, status, code int, message string) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      nil,
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// IsJSONContentType reports whether a Content-Type header denotes JSON.
+func IsJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
+
+// Accepts reports whether an Accept header admits the given media type. A
+// missing header accepts everything.
+func Accepts(accept, mediaType string) bool {
+	if strings.TrimSpace(accept) == "" {
+		return true
+	}
+	category, _, _ := strings.Cut(mediaType, "/")
+	for _, entry := range strings.Split(accept, ",") {
+		accepted, _, _ := strings.Cut(entry, ";")
+		switch strings.ToLower(strings.TrimSpace(accepted)) {
+		case mediaType, category + "/*", "*/*":
+			return true
+		}
+	}
+	return false
+}
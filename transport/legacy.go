@@ -0,0 +1,43 @@
+package transport
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// LegacyClient describes a client that used the deprecated HTTP+SSE endpoints of
+// the 2024-11-05 protocol, such as /sse, instead of the unified MCP endpoint.
+type LegacyClient struct {
+	RemoteIP  string
+	UserAgent string
+	Path      string
+	Rejected  bool // Whether the endpoints are disabled and the client was turned away
+	SeenAt    time.Time
+}
+
+// LegacyClientHandler is called for every client of the deprecated endpoints.
+type LegacyClientHandler func(LegacyClient)
+
+// LegacyReporter is implemented by transports that serve the deprecated HTTP+SSE
+// endpoints and report the clients that use them, so that the server can warn
+// about them and operators can tell when the endpoints can be retired.
+type LegacyReporter interface {
+	// SetLegacyClientHandler sets the handler called for every legacy client
+	SetLegacyClientHandler(handler LegacyClientHandler)
+}
+
+// NewLegacyClient describes the client of a request to a deprecated endpoint.
+func NewLegacyClient(r *http.Request, rejected bool) LegacyClient {
+	remoteIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remoteIP = host
+	}
+	return LegacyClient{
+		RemoteIP:  remoteIP,
+		UserAgent: r.UserAgent(),
+		Path:      r.URL.Path,
+		Rejected:  rejected,
+		SeenAt:    time.Now(),
+	}
+}
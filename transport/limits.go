@@ -0,0 +1,138 @@
+package transport
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServerLimits holds the timeouts and limits of an HTTP-based server transport.
+// A zero value disables the corresponding limit.
+type ServerLimits struct {
+	// ReadHeaderTimeout bounds reading a request's headers, which guards against
+	// slowloris clients that open connections and trickle headers
+	ReadHeaderTimeout time.Duration
+
+	// ReadTimeout bounds reading a whole request, including its body
+	ReadTimeout time.Duration
+
+	// WriteTimeout bounds writing a response. It does not apply to SSE streams,
+	// which stay open for as long as the client listens.
+	WriteTimeout time.Duration
+
+	// IdleTimeout bounds how long a keep-alive connection waits for its next request
+	IdleTimeout time.Duration
+
+	// MaxHeaderBytes limits the size of request headers
+	MaxHeaderBytes int
+
+	// MaxConnections limits the number of connections open at once. Further
+	// connections wait until one closes.
+	MaxConnections int
+}
+
+// DefaultServerLimits returns the limits HTTP-based server transports use unless
+// configured otherwise. Writes are not bounded by default, because responses to
+// tool calls are only written once the tool finishes, which may take a while.
+func DefaultServerLimits() ServerLimits {
+	return ServerLimits{
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		MaxHeaderBytes:    1 << 20,
+	}
+}
+
+// NewHTTPServer creates an HTTP server applying the limits. The connection limit
+// is applied by Listen.
+func NewHTTPServer(addr string, handler http.Handler, limits ServerLimits) *http.Server {
+	if limits.WriteTimeout > 0 {
+		handler = limitWrites(handler, limits.WriteTimeout)
+	}
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: limits.ReadHeaderTimeout,
+		ReadTimeout:       limits.ReadTimeout,
+		IdleTimeout:       limits.IdleTimeout,
+		MaxHeaderBytes:    limits.MaxHeaderBytes,
+	}
+}
+
+// Listen listens on a TCP address, holding at most limits.MaxConnections
+// connections open at once.
+func Listen(addr string, limits ServerLimits) (net.Listener, error) {
+	if addr == "" {
+		addr = ":http"
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if limits.MaxConnections > 0 {
+		listener = &limitListener{
+			Listener: listener,
+			slots:    make(chan struct{}, limits.MaxConnections),
+			done:     make(chan struct{}),
+		}
+	}
+	return listener, nil
+}
+
+// limitWrites sets a write deadline on every response except SSE streams.
+// http.Server.WriteTimeout cannot be used, since it would cut streams off.
+func limitWrites(handler http.Handler, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !(r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/event-stream")) {
+			// Not every ResponseWriter supports deadlines; those simply go unbounded
+			_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// limitListener is a listener that accepts at most cap(slots) connections at once
+type limitListener struct {
+	net.Listener
+	slots     chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Accept waits for a free slot, then accepts a connection
+func (l *limitListener) Accept() (net.Conn, error) {
+	select {
+	case l.slots <- struct{}{}:
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		<-l.slots
+		return nil, err
+	}
+	return &limitConn{Conn: conn, release: func() { <-l.slots }}, nil
+}
+
+// Close closes the listener, waking up an Accept waiting for a slot
+func (l *limitListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return l.Listener.Close()
+}
+
+// limitConn frees its listener slot when closed
+type limitConn struct {
+	net.Conn
+	releaseOnce sync.Once
+	release     func()
+}
+
+// Close closes the connection and frees its slot
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.releaseOnce.Do(c.release)
+	return err
+}
@@ -0,0 +1,118 @@
+package transport
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveWithLimits serves handler on a local port with the given limits
+func serveWithLimits(t *testing.T, handler http.Handler, limits ServerLimits) string {
+	t.Helper()
+
+	listener, err := Listen("127.0.0.1:0", limits)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	server := NewHTTPServer(listener.Addr().String(), handler, limits)
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+	return listener.Addr().String()
+}
+
+func TestServerLimitsReadHeaderTimeout(t *testing.T) {
+	addr := serveWithLimits(t, http.NotFoundHandler(), ServerLimits{ReadHeaderTimeout: 100 * time.Millisecond})
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	// A slowloris client sends part of its headers and stalls
+	fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: localhost\r\n")
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.ReadAll(conn); err != nil {
+		t.Fatalf("Expected the server to close the stalled connection, got %v", err)
+	}
+}
+
+func TestServerLimitsWriteTimeoutSparesStreams(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stream" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for i := 0; i < 3; i++ {
+				fmt.Fprintf(w, "data: %d\n\n", i)
+				w.(http.Flusher).Flush()
+				time.Sleep(100 * time.Millisecond)
+			}
+			return
+		}
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("late"))
+	})
+	addr := serveWithLimits(t, handler, ServerLimits{WriteTimeout: 50 * time.Millisecond})
+
+	// A slow regular response is cut off
+	if resp, err := http.Get("http://" + addr + "/slow"); err == nil {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err == nil && string(body) == "late" {
+			t.Error("Expected the write timeout to cut off the slow response")
+		}
+	}
+
+	// An SSE stream outlives the write timeout
+	req, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/stream", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	events := 0
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "data:") {
+			events++
+		}
+	}
+	if events != 3 {
+		t.Errorf("Expected all 3 events on the stream, got %d (%v)", events, scanner.Err())
+	}
+}
+
+func TestServerLimitsMaxConnections(t *testing.T) {
+	addr := serveWithLimits(t, http.NotFoundHandler(), ServerLimits{MaxConnections: 1})
+
+	first, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+
+	// The second connection is not served until the first closes
+	second, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer second.Close()
+	fmt.Fprint(second, "GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")
+
+	second.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	if _, err := second.Read(make([]byte, 1)); err == nil {
+		t.Fatal("Expected the second connection to wait for a free slot")
+	}
+
+	first.Close()
+	second.SetReadDeadline(time.Now().Add(2 * time.Second))
+	status, err := bufio.NewReader(second).ReadString('\n')
+	if err != nil || !strings.Contains(status, "404") {
+		t.Errorf("Expected the second connection to be served once the first closed, got %q (%v)", status, err)
+	}
+}
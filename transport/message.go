@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// maxPooledBuffer is the largest buffer returned to the pool; larger ones, grown
+// by an occasional huge message, are left to the garbage collector rather than
+// pinning their memory
+const maxPooledBuffer = 1 << 20
+
+// bufferPool holds the buffers transports read messages into
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// GetBuffer returns an empty buffer from a pool shared by transports. Return it
+// with PutBuffer once nothing refers to its contents.
+func GetBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// PutBuffer returns a buffer obtained from GetBuffer to the pool.
+func PutBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// Envelope holds the fields that tell what kind of JSON-RPC message a message
+// is. ParseEnvelope decodes them without allocating the params, result or error,
+// which is much cheaper than decoding the whole message into a map.
+type Envelope struct {
+	JSONRPC   string
+	ID        json.RawMessage // nil when the message has no ID; "null" for a null ID
+	HasMethod bool
+	HasResult bool
+	HasError  bool
+}
+
+// presence records whether a field occurs in a message, skipping its value
+type presence bool
+
+// UnmarshalJSON marks the field as present
+func (p *presence) UnmarshalJSON([]byte) error {
+	*p = true
+	return nil
+}
+
+// ParseEnvelope decodes the envelope of a single JSON-RPC message. It fails for
+// anything but a JSON object, including batches.
+func ParseEnvelope(message []byte) (Envelope, error) {
+	var fields struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Method  presence        `json:"method"`
+		Result  presence        `json:"result"`
+		Error   presence        `json:"error"`
+	}
+	if err := json.Unmarshal(message, &fields); err != nil {
+		return Envelope{}, err
+	}
+	return Envelope{
+		JSONRPC:   fields.JSONRPC,
+		ID:        fields.ID,
+		HasMethod: bool(fields.Method),
+		HasResult: bool(fields.Result),
+		HasError:  bool(fields.Error),
+	}, nil
+}
+
+// HasID reports whether the message has an ID, even a null one.
+func (e Envelope) HasID() bool {
+	return e.ID != nil
+}
+
+// IsRequest reports whether a message is a single JSON-RPC request, one with both
+// a method and an ID.
+func IsRequest(message []byte) bool {
+	envelope, err := ParseEnvelope(message)
+	return err == nil && envelope.HasMethod && envelope.HasID()
+}
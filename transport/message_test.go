@@ -0,0 +1,82 @@
+package transport
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseEnvelope(t *testing.T) {
+	tests := []struct {
+		message                        string
+		id                             string
+		hasMethod, hasResult, hasError bool
+	}{
+		{`{"jsonrpc":"2.0","id":1,"method":"ping","params":{"large":[1,2,3]}}`, "1", true, false, false},
+		{`{"jsonrpc":"2.0","id":"a","result":{"tools":[]}}`, `"a"`, false, true, false},
+		{`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"Parse error"}}`, "null", false, false, true},
+		{`{"jsonrpc":"2.0","method":"notifications/initialized"}`, "", true, false, false},
+	}
+	for _, tt := range tests {
+		envelope, err := ParseEnvelope([]byte(tt.message))
+		if err != nil {
+			t.Fatalf("ParseEnvelope(%s) failed: %v", tt.message, err)
+		}
+		if envelope.JSONRPC != "2.0" || string(envelope.ID) != tt.id || envelope.HasID() != (tt.id != "") ||
+			envelope.HasMethod != tt.hasMethod || envelope.HasResult != tt.hasResult || envelope.HasError != tt.hasError {
+			t.Errorf("ParseEnvelope(%s) = %+v", tt.message, envelope)
+		}
+	}
+
+	if _, err := ParseEnvelope([]byte(`[{"jsonrpc":"2.0","id":1,"method":"ping"}]`)); err == nil {
+		t.Error("Expected a batch to be rejected")
+	}
+}
+
+func TestIsRequest(t *testing.T) {
+	tests := map[string]bool{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call"}`:                                true,
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}`: false,
+		`{"jsonrpc":"2.0","id":1,"result":{}}`:                                          false,
+		`[{"jsonrpc":"2.0","id":1,"method":"ping"}]`:                                    false,
+	}
+	for message, want := range tests {
+		if got := IsRequest([]byte(message)); got != want {
+			t.Errorf("IsRequest(%s) = %v, want %v", message, got, want)
+		}
+	}
+}
+
+func TestBufferPool(t *testing.T) {
+	buf := GetBuffer()
+	buf.WriteString("message")
+	PutBuffer(buf)
+
+	if buf := GetBuffer(); buf.Len() != 0 {
+		t.Errorf("Expected an empty buffer from the pool, got %q", buf.String())
+	}
+}
+
+// benchmarkMessage is a tools/call request with typical arguments
+var benchmarkMessage = []byte(`{"jsonrpc":"2.0","id":42,"method":"tools/call","params":{"name":"search",` +
+	`"arguments":{"query":"quarterly revenue by region","limit":25,"filters":{"year":2024,"tags":["finance","sales"]}}}}`)
+
+func BenchmarkParseEnvelope(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseEnvelope(benchmarkMessage); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParseMap decodes a message into a map, as transports did before
+// ParseEnvelope, for comparison
+func BenchmarkParseMap(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var msg map[string]interface{}
+		if err := json.Unmarshal(benchmarkMessage, &msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -32,6 +32,7 @@ const DefaultClientTopic = "responses"
 // Transport implements the transport.Transport interface for MQTT
 type Transport struct {
 	transport.BaseTransport
+	transport.Dispatcher
 	brokerURL    string
 	clientID     string
 	client       paho.Client
@@ -48,6 +49,13 @@ type Transport struct {
 	subs         map[string]byte
 	done         chan struct{}
 	handler      transport.MessageHandler
+
+	// sessionHandler receives server-side messages along with the ID of the
+	// client whose topic they arrived on
+	sessionHandler transport.SessionMessageHandler
+
+	// requests runs a server's requests one at a time unless they run concurrently
+	requests transport.RequestQueue
 }
 
 // TLSConfig holds TLS configuration for MQTT connections
@@ -206,6 +214,25 @@ func (t *Transport) Send(message []byte) error {
 	return nil
 }
 
+// SendToSession publishes a message on the topic of one client, identified by the
+// client ID it publishes its own messages with. Client transports have a single
+// session and send the message to the server.
+func (t *Transport) SendToSession(sessionID string, message []byte) error {
+	if !t.isServer || sessionID == "" {
+		return t.Send(message)
+	}
+	if !t.connected {
+		return errors.New("not connected to MQTT broker")
+	}
+
+	token := t.client.Publish(t.getClientTopic(sessionID), t.qos, false, message)
+	if token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+
+	return nil
+}
+
 // Receive is not implemented for MQTT as it uses callbacks
 func (t *Transport) Receive() ([]byte, error) {
 	return nil, errors.New("not implemented: MQTT transport uses subscription callbacks")
@@ -223,32 +250,57 @@ func (t *Transport) Receive() ([]byte, error) {
 
 // messageHandler processes incoming MQTT messages
 func (t *Transport) messageHandler(client paho.Client, msg paho.Message) {
+	if t.handler == nil && t.sessionHandler == nil {
+		return
+	}
 
-	if handler := t.handler; handler != nil {
-		response, err := handler(msg.Payload())
-		if err != nil {
-			slog.Error("message handler error", "error", err)
-		} else if response != nil && t.isServer {
-			// Extract client ID from the topic to route response securely
-			clientID := extractClientIDFromTopic(msg.Topic(), t.topicPrefix, t.serverTopic)
-
-			if clientID != "" {
-				// Send response to client-specific topic using client ID
-				responseTopic := t.getClientTopic(clientID)
-
-				token := t.client.Publish(responseTopic, t.qos, false, response)
-				token.Wait()
-			} else {
-				// Fallback to broadcast if no client ID found
-				responseTopic := t.getClientTopic("all")
-
-				token := t.client.Publish(responseTopic, t.qos, false, response)
-				token.Wait()
-			}
+	// Extract client ID from the topic to route the message and response securely
+	var clientID string
+	if t.isServer {
+		clientID = extractClientIDFromTopic(msg.Topic(), t.topicPrefix, t.serverTopic)
+	}
+
+	// A server reads on while requests run, handling other messages in the order
+	// they arrive
+	if t.isServer {
+		payload := msg.Payload()
+		t.Dispatch(&t.requests, payload, func() { t.process(clientID, payload) })
+	} else {
+		t.process(clientID, msg.Payload())
+	}
+}
+
+// process handles a message and publishes the response, if any
+func (t *Transport) process(clientID string, payload []byte) {
+	response, err := t.dispatch(clientID, payload)
+	if err != nil {
+		slog.Error("message handler error", "error", err)
+	} else if response != nil && t.isServer {
+		if clientID != "" {
+			// Send response to client-specific topic using client ID
+			responseTopic := t.getClientTopic(clientID)
+
+			token := t.client.Publish(responseTopic, t.qos, false, response)
+			token.Wait()
+		} else {
+			// Fallback to broadcast if no client ID found
+			responseTopic := t.getClientTopic("all")
+
+			token := t.client.Publish(responseTopic, t.qos, false, response)
+			token.Wait()
 		}
 	}
 }
 
+// dispatch passes a message received on a client's topic to the session handler,
+// using the client ID as the session ID, and other messages to the message handler
+func (t *Transport) dispatch(clientID string, message []byte) ([]byte, error) {
+	if handler := t.sessionHandler; handler != nil && clientID != "" {
+		return handler(clientID, message)
+	}
+	return t.HandleMessage(message)
+}
+
 // subscribe subscribes to an MQTT topic
 func (t *Transport) subscribe(topic string, qos byte) error {
 	token := t.client.Subscribe(topic, qos, t.messageHandler)
@@ -330,6 +382,11 @@ func (t *Transport) SetMessageHandler(handler transport.MessageHandler) {
 	t.handler = handler
 }
 
+// SetSessionMessageHandler sets the handler for messages received on a client's topic
+func (t *Transport) SetSessionMessageHandler(handler transport.SessionMessageHandler) {
+	t.sessionHandler = handler
+}
+
 // HandleMessage processes an incoming message using the registered handler
 func (t *Transport) HandleMessage(message []byte) ([]byte, error) {
 	handler := t.handler
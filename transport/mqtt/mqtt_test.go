@@ -59,5 +59,36 @@ func TestTopicFormatting(t *testing.T) {
 	assert.Equal(t, "custom/responses/client1", trans.getClientTopic("client1"))
 }
 
+func TestSessionDispatch(t *testing.T) {
+	trans := NewTransport("tcp://localhost:1883", true)
+	trans.SetMessageHandler(func(message []byte) ([]byte, error) {
+		return []byte("shared"), nil
+	})
+
+	// Without a session handler every message goes to the message handler
+	response, err := trans.dispatch("client1", []byte("{}"))
+	assert.NoError(t, err)
+	assert.Equal(t, "shared", string(response))
+
+	var sessionID string
+	trans.SetSessionMessageHandler(func(id string, message []byte) ([]byte, error) {
+		sessionID = id
+		return []byte("session"), nil
+	})
+
+	// Messages on a client's topic are passed with the client ID as session ID
+	response, err = trans.dispatch("client1", []byte("{}"))
+	assert.NoError(t, err)
+	assert.Equal(t, "session", string(response))
+	assert.Equal(t, "client1", sessionID)
+
+	response, err = trans.dispatch("", []byte("{}"))
+	assert.NoError(t, err)
+	assert.Equal(t, "shared", string(response))
+
+	// Sending to a session requires a broker connection
+	assert.Error(t, trans.SendToSession("client1", []byte("{}")))
+}
+
 // Note: Integration tests requiring an actual MQTT broker would be in separate files
 // and typically skipped unless explicitly enabled
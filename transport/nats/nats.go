@@ -31,6 +31,7 @@ const DefaultClientSubject = "responses"
 // Transport implements the transport.Transport interface for NATS
 type Transport struct {
 	transport.BaseTransport
+	transport.Dispatcher
 	serverURL     string
 	clientID      string
 	conn          *nats.Conn
@@ -48,6 +49,13 @@ type Transport struct {
 	connMu        sync.RWMutex
 	done          chan struct{}
 	handler       transport.MessageHandler
+
+	// sessionHandler receives server-side messages along with the ID of the
+	// client whose subject they arrived on
+	sessionHandler transport.SessionMessageHandler
+
+	// requests runs a server's requests one at a time unless they run concurrently
+	requests transport.RequestQueue
 }
 
 // TLSConfig holds TLS configuration for NATS connections
@@ -169,6 +177,11 @@ func (t *Transport) Start() error {
 		if err := t.subscribe(requestSubject); err != nil {
 			return err
 		}
+	} else {
+		// Client subscribes to its own subject for responses and server requests
+		if err := t.subscribe(t.getClientSubject(t.clientID)); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -218,12 +231,31 @@ func (t *Transport) Send(message []byte) error {
 	if t.isServer {
 		subject = t.getClientSubject("all") // Broadcast to all clients
 	} else {
-		subject = t.getServerSubject("") // Send to server
+		subject = t.getServerSubject(t.clientID) // Send to server with client ID in subject
 	}
 
 	return t.conn.Publish(subject, message)
 }
 
+// SendToSession publishes a message on the subject of one client, identified by
+// the client ID it publishes its own messages with. Client transports have a
+// single session and send the message to the server.
+func (t *Transport) SendToSession(sessionID string, message []byte) error {
+	if !t.isServer || sessionID == "" {
+		return t.Send(message)
+	}
+
+	t.connMu.RLock()
+	connected := t.connected
+	t.connMu.RUnlock()
+
+	if !connected {
+		return errors.New("not connected to NATS server")
+	}
+
+	return t.conn.Publish(t.getClientSubject(sessionID), message)
+}
+
 // Receive is not implemented for NATS as it uses callbacks
 func (t *Transport) Receive() ([]byte, error) {
 	return nil, errors.New("not implemented: NATS transport uses subscription callbacks")
@@ -257,9 +289,19 @@ func (t *Transport) messageHandler(msg *nats.Msg) {
 		}
 	}
 
-	// Process the message
-	if t.handler != nil {
-		response, err := t.HandleMessage(msg.Data)
+	// A server reads on while requests run, handling other messages in the order
+	// they arrive
+	if t.isServer {
+		t.Dispatch(&t.requests, msg.Data, func() { t.process(msg, clientID) })
+	} else {
+		t.process(msg, clientID)
+	}
+}
+
+// process handles a message and publishes the response, if any
+func (t *Transport) process(msg *nats.Msg, clientID string) {
+	if t.handler != nil || t.sessionHandler != nil {
+		response, err := t.dispatch(clientID, msg.Data)
 		if err != nil {
 			// Could log the error here
 			return
@@ -285,6 +327,18 @@ func (t *Transport) messageHandler(msg *nats.Msg) {
 	}
 }
 
+// dispatch passes a message received on a client's subject to the session handler,
+// using the client ID as the session ID, and other messages to the message handler
+func (t *Transport) dispatch(clientID string, message []byte) ([]byte, error) {
+	if handler := t.sessionHandler; handler != nil && clientID != "" {
+		return handler(clientID, message)
+	}
+	if handler := t.handler; handler != nil {
+		return handler(message)
+	}
+	return nil, errors.New("no message handler set")
+}
+
 // subscribe subscribes to a subject
 func (t *Transport) subscribe(subject string) error {
 	t.subsMu.Lock()
@@ -312,6 +366,11 @@ func (t *Transport) SetMessageHandler(handler transport.MessageHandler) {
 	t.handler = handler
 }
 
+// SetSessionMessageHandler sets the handler for messages received on a client's subject
+func (t *Transport) SetSessionMessageHandler(handler transport.SessionMessageHandler) {
+	t.sessionHandler = handler
+}
+
 // WithClientID sets the client ID for the NATS transport
 func WithClientID(clientID string) NATSOption {
 	return func(t *Transport) {
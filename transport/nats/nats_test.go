@@ -73,6 +73,35 @@ func TestTopicFormatting(t *testing.T) {
 	assert.Equal(t, "mcp.responses.>", transport.getClientSubject("all"))
 }
 
+func TestSessionDispatch(t *testing.T) {
+	transport := NewTransport("nats://localhost:4222", true)
+
+	_, err := transport.dispatch("client1", []byte("{}"))
+	assert.Error(t, err)
+
+	transport.SetMessageHandler(func(message []byte) ([]byte, error) {
+		return []byte("shared"), nil
+	})
+	var sessionID string
+	transport.SetSessionMessageHandler(func(id string, message []byte) ([]byte, error) {
+		sessionID = id
+		return []byte("session"), nil
+	})
+
+	// Messages on a client's subject are passed with the client ID as session ID
+	response, err := transport.dispatch("client1", []byte("{}"))
+	assert.NoError(t, err)
+	assert.Equal(t, "session", string(response))
+	assert.Equal(t, "client1", sessionID)
+
+	response, err = transport.dispatch("", []byte("{}"))
+	assert.NoError(t, err)
+	assert.Equal(t, "shared", string(response))
+
+	// Sending to a session requires a server connection
+	assert.Error(t, transport.SendToSession("client1", []byte("{}")))
+}
+
 func TestNATSEndToEnd(t *testing.T) {
 	// Skip in normal test runs since it requires a running NATS server
 	t.Skip("NATS E2E test requires a running NATS server - enable manually")
@@ -0,0 +1,76 @@
+package transport
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// ConnectionPool holds the connection pooling settings of an HTTP-based client
+// transport. A zero value disables the corresponding limit.
+type ConnectionPool struct {
+	// MaxIdleConns limits the idle connections kept open across all hosts
+	MaxIdleConns int
+
+	// MaxIdleConnsPerHost limits the idle connections kept open to each host.
+	// net/http keeps only 2 by default, so a client sending many concurrent
+	// requests to one server keeps opening and closing connections.
+	MaxIdleConnsPerHost int
+
+	// MaxConnsPerHost limits the connections open to each host, idle or not.
+	// Further requests wait for a connection to become free.
+	MaxConnsPerHost int
+
+	// IdleConnTimeout bounds how long an idle connection stays in the pool
+	IdleConnTimeout time.Duration
+
+	// KeepAlive sets the interval of TCP keep-alive probes on open connections.
+	// A negative value disables them.
+	KeepAlive time.Duration
+
+	// DialTimeout bounds establishing a TCP connection
+	DialTimeout time.Duration
+
+	// TLSHandshakeTimeout bounds the TLS handshake of a new connection
+	TLSHandshakeTimeout time.Duration
+
+	// HTTP2 negotiates HTTP/2 with servers that support it, which multiplexes
+	// requests over a single connection
+	HTTP2 bool
+}
+
+// DefaultConnectionPool returns the pooling settings HTTP-based client transports
+// use unless configured otherwise. Unlike the net/http defaults, they keep enough
+// idle connections to each host for a busy client to reuse them.
+func DefaultConnectionPool() ConnectionPool {
+	return ConnectionPool{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 32,
+		IdleConnTimeout:     90 * time.Second,
+		KeepAlive:           30 * time.Second,
+		DialTimeout:         30 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+		HTTP2:               true,
+	}
+}
+
+// NewHTTPTransport creates an HTTP round tripper pooling connections with the
+// given settings. Proxies are taken from the environment, as with
+// http.DefaultTransport.
+func NewHTTPTransport(pool ConnectionPool) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   pool.DialTimeout,
+		KeepAlive: pool.KeepAlive,
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     pool.HTTP2,
+		MaxIdleConns:          pool.MaxIdleConns,
+		MaxIdleConnsPerHost:   pool.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       pool.MaxConnsPerHost,
+		IdleConnTimeout:       pool.IdleConnTimeout,
+		TLSHandshakeTimeout:   pool.TLSHandshakeTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+}
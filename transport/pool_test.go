@@ -0,0 +1,63 @@
+package transport
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConnectionPoolReusesConnections(t *testing.T) {
+	var opened atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			opened.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := &http.Client{Transport: NewHTTPTransport(DefaultConnectionPool())}
+
+	// Two bursts of concurrent requests; the second reuses the first's connections
+	for burst := 0; burst < 2; burst++ {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := client.Get(server.URL)
+				if err != nil {
+					t.Errorf("Request failed: %v", err)
+					return
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}()
+		}
+		wg.Wait()
+	}
+
+	if n := opened.Load(); n > 10 {
+		t.Errorf("Expected at most 10 connections, got %d", n)
+	}
+}
+
+func TestConnectionPoolMaxConnsPerHost(t *testing.T) {
+	pool := DefaultConnectionPool()
+	pool.MaxConnsPerHost = 4
+	pool.HTTP2 = false
+
+	roundTripper := NewHTTPTransport(pool)
+	if roundTripper.MaxConnsPerHost != 4 || roundTripper.MaxIdleConnsPerHost != 32 || roundTripper.ForceAttemptHTTP2 {
+		t.Errorf("Unexpected transport settings %+v", roundTripper)
+	}
+}
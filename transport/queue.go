@@ -0,0 +1,116 @@
+package transport
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// RequestLimiter is set on transports by servers that handle a bounded number of
+// requests at once, such as with a pool of workers.
+type RequestLimiter interface {
+	// Wait blocks until the server has room for another request, and holds that
+	// room for the next request handed to it.
+	Wait()
+}
+
+// LimitedDispatcher is implemented by transports that read requests from a
+// stream and can wait on a server's RequestLimiter. Once a limiter is set, they
+// hand requests to the server concurrently, waiting on the limiter before each
+// one, so that they stop reading while the server is full.
+type LimitedDispatcher interface {
+	// SetRequestLimiter sets the limiter waited on before each request; nil
+	// removes it
+	SetRequestLimiter(limiter RequestLimiter)
+}
+
+// Dispatcher decides how a transport that reads messages from a stream hands
+// them to its handler. Single notifications and responses are handled before the
+// reader reads on. Requests, batches and anything unparsable run on the
+// connection's RequestQueue, or concurrently if that is enabled or a limiter is
+// set. Transports embed it to implement ConcurrentDispatcher and
+// LimitedDispatcher; the zero value handles requests one at a time.
+type Dispatcher struct {
+	concurrent atomic.Bool
+	limiter    atomic.Pointer[RequestLimiter]
+}
+
+// SetConcurrentDispatch sets whether requests run in goroutines of their own, so
+// that a slow one does not hold up the requests read after it. It may be called
+// while the transport is running.
+func (d *Dispatcher) SetConcurrentDispatch(enabled bool) {
+	d.concurrent.Store(enabled)
+}
+
+// SetRequestLimiter sets the limiter waited on before each request. It may be
+// called while the transport is running.
+func (d *Dispatcher) SetRequestLimiter(limiter RequestLimiter) {
+	if limiter == nil {
+		d.limiter.Store(nil)
+		return
+	}
+	d.limiter.Store(&limiter)
+}
+
+// Dispatch handles a message read from a connection by calling handle, queueing
+// requests on queue unless they run concurrently.
+func (d *Dispatcher) Dispatch(queue *RequestQueue, message []byte, handle func()) {
+	if envelope, err := ParseEnvelope(message); err == nil && !(envelope.HasMethod && envelope.HasID()) {
+		handle()
+		return
+	}
+	if limiter := d.limiter.Load(); limiter != nil {
+		(*limiter).Wait()
+		go handle()
+		return
+	}
+	if d.concurrent.Load() {
+		go handle()
+		return
+	}
+	queue.Run(handle)
+}
+
+// RequestQueue runs the requests read from a connection one at a time, in the
+// order they were read, on a goroutine of its own. The reader queues requests
+// and carries on reading, so that notifications and responses are handled while
+// a request runs: a cancellation reaches the request it cancels, and the answer
+// to a server request, such as sampling or elicitation, reaches the request
+// waiting for it. The zero value is ready to use.
+type RequestQueue struct {
+	mu      sync.Mutex
+	pending []func()
+	running bool
+}
+
+// Run queues fn behind the functions already queued and returns without waiting
+// for it to run.
+func (q *RequestQueue) Run(fn func()) {
+	q.mu.Lock()
+	q.pending = append(q.pending, fn)
+	if q.running {
+		q.mu.Unlock()
+		return
+	}
+	q.running = true
+	q.mu.Unlock()
+
+	go q.drain()
+}
+
+// drain runs queued functions until the queue is empty
+func (q *RequestQueue) drain() {
+	for {
+		q.mu.Lock()
+		if len(q.pending) == 0 {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		fn := q.pending[0]
+		q.pending[0] = nil
+		q.pending = q.pending[1:]
+		q.mu.Unlock()
+
+		fn()
+	}
+}
@@ -0,0 +1,93 @@
+package transport
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDispatcher(t *testing.T) {
+	var dispatcher Dispatcher
+	var queue RequestQueue
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func() {
+		return func() {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		}
+	}
+
+	// Hold the queue up until the notification and the response have been handled
+	release := make(chan struct{})
+	queue.Run(func() { <-release })
+	dispatcher.Dispatch(&queue, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call"}`), record("request"))
+	dispatcher.Dispatch(&queue, []byte(`[{"jsonrpc":"2.0","id":2,"method":"ping"}]`), record("batch"))
+	dispatcher.Dispatch(&queue, []byte(`{"jsonrpc":"2.0","method":"notifications/cancelled"}`), record("notification"))
+	dispatcher.Dispatch(&queue, []byte(`{"jsonrpc":"2.0","id":3,"result":{}}`), record("response"))
+
+	done := make(chan struct{})
+	queue.Run(func() { close(done) })
+	close(release)
+	<-done
+
+	want := []string{"notification", "response", "request", "batch"}
+	if len(order) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, order)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, order)
+		}
+	}
+}
+
+// slotLimiter admits as many requests as it has slots
+type slotLimiter chan struct{}
+
+func (l slotLimiter) Wait() { l <- struct{}{} }
+
+func TestDispatcherWaitsOnLimiter(t *testing.T) {
+	var dispatcher Dispatcher
+	var queue RequestQueue
+	limiter := make(slotLimiter, 2)
+	dispatcher.SetRequestLimiter(limiter)
+
+	// Requests run concurrently while the limiter has room
+	running := make(chan struct{}, 3)
+	finish := make(chan struct{})
+	request := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call"}`)
+	handle := func() {
+		running <- struct{}{}
+		<-finish
+		<-limiter
+	}
+	dispatcher.Dispatch(&queue, request, handle)
+	dispatcher.Dispatch(&queue, request, handle)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-running:
+		case <-time.After(time.Second):
+			t.Fatal("Expected both requests to run at once")
+		}
+	}
+
+	// The reader waits once the limiter is full
+	dispatched := make(chan struct{})
+	go func() {
+		dispatcher.Dispatch(&queue, request, handle)
+		close(dispatched)
+	}()
+	select {
+	case <-dispatched:
+		t.Fatal("Expected the third request to wait for room")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(finish)
+	select {
+	case <-dispatched:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the third request once there was room")
+	}
+}
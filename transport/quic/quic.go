@@ -0,0 +1,519 @@
+// Package quic provides an experimental QUIC implementation of the MCP transport.
+//
+// Every request travels on a QUIC stream of its own: the client opens a
+// bidirectional stream, writes the request and closes its side, and the server
+// answers on the same stream. Streams are multiplexed on one connection without
+// head-of-line blocking, so a lost packet only delays the message it belongs to,
+// which suits lossy networks better than the UDP transport while keeping delivery
+// reliable. Server-initiated messages travel on unidirectional streams.
+//
+// QUIC always runs over TLS 1.3. A server without a certificate generates a
+// self-signed one, which clients only accept with WithInsecureSkipVerify.
+package quic
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/localrivet/gomcp/transport"
+	quicgo "github.com/quic-go/quic-go"
+)
+
+// Default configuration values.
+const (
+	DefaultALPN           = "mcp"
+	DefaultMaxMessageSize = 16 << 20
+	DefaultConnectTimeout = 10 * time.Second
+	DefaultIdleTimeout    = 60 * time.Second
+	DefaultKeepAlive      = 15 * time.Second
+)
+
+// Transport errors.
+var (
+	ErrNotRunning      = errors.New("transport not running")
+	ErrNotServerMode   = errors.New("not supported in server mode")
+	ErrMessageTooLarge = errors.New("message too large")
+)
+
+// Error codes connections are closed and streams are canceled with
+const (
+	closeCode  quicgo.ApplicationErrorCode = 0
+	cancelCode quicgo.StreamErrorCode      = 0
+)
+
+// Option represents a configuration option for the QUIC transport.
+type Option func(*Transport)
+
+// WithTLSConfig sets the TLS configuration. Servers must present a certificate;
+// clients use it to verify the server. transport.LoadServerTLSConfig and
+// transport.LoadClientTLSConfig build one from PEM files.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(t *Transport) {
+		t.tlsConfig = config
+	}
+}
+
+// WithInsecureSkipVerify makes a client accept any server certificate, such as
+// the self-signed one of a server started without TLS configuration. Use it for
+// development only.
+func WithInsecureSkipVerify() Option {
+	return func(t *Transport) {
+		t.insecureSkipVerify = true
+	}
+}
+
+// WithALPN sets the application protocol negotiated during the TLS handshake.
+// Client and server must agree on it.
+func WithALPN(protocol string) Option {
+	return func(t *Transport) {
+		t.alpn = protocol
+	}
+}
+
+// WithMaxMessageSize sets the largest message the transport reads.
+func WithMaxMessageSize(size int64) Option {
+	return func(t *Transport) {
+		if size > 0 {
+			t.maxMessageSize = size
+		}
+	}
+}
+
+// WithConnectTimeout sets the timeout for establishing a client connection.
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		if timeout > 0 {
+			t.connectTimeout = timeout
+		}
+	}
+}
+
+// WithIdleTimeout sets how long a connection may go without network activity
+// before it is closed.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		if timeout > 0 {
+			t.idleTimeout = timeout
+		}
+	}
+}
+
+// WithKeepAlive sets how often an idle connection sends a keep-alive packet. A
+// period of 0 disables keep-alives.
+func WithKeepAlive(period time.Duration) Option {
+	return func(t *Transport) {
+		t.keepAlive = period
+	}
+}
+
+// Transport implements the transport.Transport interface for QUIC.
+//
+// In server mode it accepts connections, each of which is a session; in client
+// mode it connects to a server and matches responses with SendWithContext.
+type Transport struct {
+	transport.BaseTransport
+
+	addr               string
+	isServer           bool
+	tlsConfig          *tls.Config
+	insecureSkipVerify bool
+	alpn               string
+	maxMessageSize     int64
+	connectTimeout     time.Duration
+	idleTimeout        time.Duration
+	keepAlive          time.Duration
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	runMu   sync.Mutex
+	running bool
+
+	// Server mode: the listener and the connected sessions
+	listener    *quicgo.Listener
+	sessions    map[string]quicgo.Connection
+	sessionsMu  sync.Mutex
+	nextSession atomic.Int64
+
+	// Client mode: the connection and the notification handler
+	conn           quicgo.Connection
+	notifyHandler  func(method string, message []byte)
+	notifyHandlerM sync.RWMutex
+}
+
+// NewTransport creates a new QUIC transport.
+//
+// In server mode addr is the UDP address to listen on (e.g., ":4433"); in client
+// mode it is the server's address (e.g., "localhost:4433").
+func NewTransport(addr string, isServer bool, options ...Option) *Transport {
+	t := &Transport{
+		addr:           addr,
+		isServer:       isServer,
+		alpn:           DefaultALPN,
+		maxMessageSize: DefaultMaxMessageSize,
+		connectTimeout: DefaultConnectTimeout,
+		idleTimeout:    DefaultIdleTimeout,
+		keepAlive:      DefaultKeepAlive,
+		sessions:       make(map[string]quicgo.Connection),
+	}
+
+	for _, option := range options {
+		option(t)
+	}
+
+	return t
+}
+
+// Initialize prepares the TLS configuration.
+func (t *Transport) Initialize() error {
+	if t.isServer && (t.tlsConfig == nil || (len(t.tlsConfig.Certificates) == 0 && t.tlsConfig.GetCertificate == nil)) {
+		certificate, err := selfSignedCertificate()
+		if err != nil {
+			return fmt.Errorf("failed to generate a self-signed certificate: %w", err)
+		}
+		config := &tls.Config{}
+		if t.tlsConfig != nil {
+			config = t.tlsConfig.Clone()
+		}
+		config.Certificates = []tls.Certificate{certificate}
+		t.tlsConfig = config
+		t.GetLogger().Warn("QUIC server has no TLS certificate, using a self-signed one")
+	}
+	return nil
+}
+
+// quicTLSConfig returns the TLS configuration with the negotiated protocol set
+func (t *Transport) quicTLSConfig() *tls.Config {
+	config := &tls.Config{}
+	if t.tlsConfig != nil {
+		config = t.tlsConfig.Clone()
+	}
+	config.NextProtos = []string{t.alpn}
+	if t.insecureSkipVerify {
+		config.InsecureSkipVerify = true
+	}
+	return config
+}
+
+// quicConfig returns the QUIC connection configuration
+func (t *Transport) quicConfig() *quicgo.Config {
+	return &quicgo.Config{
+		MaxIdleTimeout:  t.idleTimeout,
+		KeepAlivePeriod: t.keepAlive,
+	}
+}
+
+// Start listens for connections (server mode) or connects to the server (client mode).
+func (t *Transport) Start() error {
+	t.runMu.Lock()
+	defer t.runMu.Unlock()
+
+	if t.running {
+		return nil
+	}
+	t.ctx, t.cancel = context.WithCancel(context.Background())
+
+	if t.isServer {
+		listener, err := quicgo.ListenAddr(t.addr, t.quicTLSConfig(), t.quicConfig())
+		if err != nil {
+			t.cancel()
+			return fmt.Errorf("failed to listen on %s: %w", t.addr, err)
+		}
+		t.listener = listener
+		t.wg.Add(1)
+		go t.accept()
+	} else {
+		ctx, cancel := context.WithTimeout(t.ctx, t.connectTimeout)
+		conn, err := quicgo.DialAddr(ctx, t.addr, t.quicTLSConfig(), t.quicConfig())
+		cancel()
+		if err != nil {
+			t.cancel()
+			return fmt.Errorf("failed to connect to %s: %w", t.addr, err)
+		}
+		t.conn = conn
+		t.wg.Add(1)
+		go t.receiveNotifications(conn)
+	}
+
+	t.running = true
+	return nil
+}
+
+// Stop closes the connections and, in server mode, the listener.
+func (t *Transport) Stop() error {
+	t.runMu.Lock()
+	if !t.running {
+		t.runMu.Unlock()
+		return nil
+	}
+	t.running = false
+	t.cancel()
+	t.runMu.Unlock()
+
+	var err error
+	if t.isServer {
+		err = t.listener.Close()
+		t.sessionsMu.Lock()
+		for _, conn := range t.sessions {
+			conn.CloseWithError(closeCode, "server stopped")
+		}
+		t.sessionsMu.Unlock()
+	} else {
+		err = t.conn.CloseWithError(closeCode, "client disconnected")
+	}
+	t.wg.Wait()
+	return err
+}
+
+// Addr returns the address the server listens on, which tells the port chosen
+// for ":0" (server mode only).
+func (t *Transport) Addr() string {
+	if t.listener == nil {
+		return t.addr
+	}
+	return t.listener.Addr().String()
+}
+
+// Send sends a message without waiting for a response.
+//
+// In server mode the message, typically a notification, goes to every connected
+// client. In client mode it goes to the server.
+func (t *Transport) Send(message []byte) error {
+	if !t.isRunning() {
+		return ErrNotRunning
+	}
+
+	if !t.isServer {
+		_, err := t.exchange(t.ctx, message, false)
+		return err
+	}
+
+	t.sessionsMu.Lock()
+	conns := make([]quicgo.Connection, 0, len(t.sessions))
+	for _, conn := range t.sessions {
+		conns = append(conns, conn)
+	}
+	t.sessionsMu.Unlock()
+
+	var errs []error
+	for _, conn := range conns {
+		if err := t.push(conn, message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Receive is not supported; incoming messages are delivered to the message
+// handler (server mode) or the notification handler (client mode).
+func (t *Transport) Receive() ([]byte, error) {
+	return nil, errors.New("not implemented: QUIC transport delivers messages to handlers")
+}
+
+// SendWithContext sends a request on a stream of its own and waits for the
+// response (client mode only). Notifications and responses return immediately
+// with an empty result.
+func (t *Transport) SendWithContext(ctx context.Context, message []byte) ([]byte, error) {
+	if t.isServer {
+		return nil, fmt.Errorf("SendWithContext: %w", ErrNotServerMode)
+	}
+	if !t.isRunning() {
+		return nil, ErrNotRunning
+	}
+
+	var envelope struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid JSON message: %w", err)
+	}
+	return t.exchange(ctx, message, len(envelope.ID) > 0 && envelope.Method != "")
+}
+
+// SetNotificationHandler sets the handler of server-initiated messages (client
+// mode only). The handler receives the method and the whole JSON-RPC message.
+func (t *Transport) SetNotificationHandler(handler func(method string, message []byte)) {
+	t.notifyHandlerM.Lock()
+	defer t.notifyHandlerM.Unlock()
+	t.notifyHandler = handler
+}
+
+// isRunning reports whether the transport has been started and not stopped
+func (t *Transport) isRunning() bool {
+	t.runMu.Lock()
+	defer t.runMu.Unlock()
+	return t.running
+}
+
+// exchange writes a message on a new stream and, if a response is expected, reads it
+func (t *Transport) exchange(ctx context.Context, message []byte, expectResponse bool) ([]byte, error) {
+	stream, err := t.conn.OpenStreamSync(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open QUIC stream: %w", err)
+	}
+
+	// Unblock reads and writes when the caller gives up
+	stop := context.AfterFunc(ctx, func() {
+		stream.CancelRead(cancelCode)
+		stream.CancelWrite(cancelCode)
+	})
+	defer stop()
+
+	if _, err := stream.Write(message); err != nil {
+		return nil, t.streamError(ctx, "write", err)
+	}
+	if err := stream.Close(); err != nil {
+		return nil, t.streamError(ctx, "write", err)
+	}
+	if !expectResponse {
+		stream.CancelRead(cancelCode)
+		return []byte{}, nil
+	}
+
+	response, err := t.readMessage(stream)
+	if err != nil {
+		return nil, t.streamError(ctx, "read", err)
+	}
+	return response, nil
+}
+
+// streamError prefers the context's error when the caller canceled the exchange
+func (t *Transport) streamError(ctx context.Context, op string, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("failed to %s QUIC stream: %w", op, err)
+}
+
+// readMessage reads a stream to its end, bounded by the maximum message size
+func (t *Transport) readMessage(stream io.Reader) ([]byte, error) {
+	message, err := io.ReadAll(io.LimitReader(stream, t.maxMessageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(message)) > t.maxMessageSize {
+		return nil, ErrMessageTooLarge
+	}
+	return message, nil
+}
+
+// push sends a server-initiated message on a unidirectional stream
+func (t *Transport) push(conn quicgo.Connection, message []byte) error {
+	stream, err := conn.OpenUniStreamSync(t.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open QUIC stream: %w", err)
+	}
+	if _, err := stream.Write(message); err != nil {
+		return fmt.Errorf("failed to write QUIC stream: %w", err)
+	}
+	return stream.Close()
+}
+
+// accept accepts connections until the listener closes (server mode)
+func (t *Transport) accept() {
+	defer t.wg.Done()
+
+	for {
+		conn, err := t.listener.Accept(t.ctx)
+		if err != nil {
+			if t.ctx.Err() == nil {
+				t.GetLogger().Error("failed to accept QUIC connection", "error", err)
+			}
+			return
+		}
+
+		sessionID := fmt.Sprintf("quic-%d", t.nextSession.Add(1))
+		t.sessionsMu.Lock()
+		t.sessions[sessionID] = conn
+		t.sessionsMu.Unlock()
+
+		t.wg.Add(1)
+		go t.serveConnection(sessionID, conn)
+	}
+}
+
+// serveConnection serves the streams of a connection until it closes (server mode)
+func (t *Transport) serveConnection(sessionID string, conn quicgo.Connection) {
+	defer t.wg.Done()
+	defer func() {
+		t.sessionsMu.Lock()
+		delete(t.sessions, sessionID)
+		t.sessionsMu.Unlock()
+	}()
+
+	t.GetLogger().Debug("QUIC session opened", "session", sessionID, "remote", conn.RemoteAddr().String())
+	for {
+		stream, err := conn.AcceptStream(t.ctx)
+		if err != nil {
+			t.GetLogger().Debug("QUIC session closed", "session", sessionID, "error", err)
+			return
+		}
+		t.wg.Add(1)
+		go t.serveStream(sessionID, stream)
+	}
+}
+
+// serveStream handles the message of a stream and writes the response to it
+func (t *Transport) serveStream(sessionID string, stream quicgo.Stream) {
+	defer t.wg.Done()
+	defer stream.Close()
+
+	message, err := t.readMessage(stream)
+	if err != nil {
+		t.GetLogger().Warn("failed to read QUIC stream", "session", sessionID, "error", err)
+		stream.CancelWrite(cancelCode)
+		return
+	}
+
+	response, err := t.HandleSessionMessage(sessionID, message)
+	if err != nil {
+		t.GetLogger().Error("failed to handle QUIC message", "session", sessionID, "error", err)
+	}
+	if response != nil {
+		if _, err := stream.Write(response); err != nil {
+			t.GetLogger().Debug("failed to write QUIC response", "session", sessionID, "error", err)
+		}
+	}
+}
+
+// receiveNotifications delivers the server's unidirectional streams to the
+// notification handler (client mode)
+func (t *Transport) receiveNotifications(conn quicgo.Connection) {
+	defer t.wg.Done()
+
+	for {
+		stream, err := conn.AcceptUniStream(t.ctx)
+		if err != nil {
+			return
+		}
+
+		message, err := t.readMessage(stream)
+		if err != nil {
+			t.GetLogger().Warn("failed to read QUIC notification", "error", err)
+			continue
+		}
+
+		var envelope struct {
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal(message, &envelope); err != nil {
+			t.GetLogger().Warn("ignoring invalid QUIC message", "error", err)
+			continue
+		}
+
+		t.notifyHandlerM.RLock()
+		handler := t.notifyHandler
+		t.notifyHandlerM.RUnlock()
+		if handler != nil {
+			handler(envelope.Method, message)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package quic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echo answers a request with its session and ID
+func echo(sessionID string, message []byte) ([]byte, error) {
+	var request struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil || len(request.ID) == 0 {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%q}`, request.ID, sessionID)), nil
+}
+
+// startTransport initializes and starts a transport, stopping it on cleanup
+func startTransport(t *testing.T, tr *Transport) *Transport {
+	t.Helper()
+	require.NoError(t, tr.Initialize())
+	require.NoError(t, tr.Start())
+	t.Cleanup(func() { tr.Stop() })
+	return tr
+}
+
+// startServer starts a server with a self-signed certificate on a free port
+func startServer(t *testing.T, handler func(string, []byte) ([]byte, error)) *Transport {
+	t.Helper()
+	server := NewTransport("127.0.0.1:0", true)
+	server.SetSessionMessageHandler(handler)
+	return startTransport(t, server)
+}
+
+func TestRequestResponse(t *testing.T) {
+	server := startServer(t, echo)
+	client := startTransport(t, NewTransport(server.Addr(), false, WithInsecureSkipVerify()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	response, err := client.SendWithContext(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":"quic-1"}`, string(response))
+
+	// Concurrent requests are multiplexed on the connection and share its session
+	var wg sync.WaitGroup
+	for i := 2; i < 12; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			response, err := client.SendWithContext(ctx, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"ping"}`, id)))
+			if assert.NoError(t, err) {
+				assert.JSONEq(t, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"quic-1"}`, id), string(response))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Notifications get no response
+	response, err = client.SendWithContext(ctx, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	require.NoError(t, err)
+	assert.Empty(t, response)
+
+	// A second connection is a session of its own
+	other := startTransport(t, NewTransport(server.Addr(), false, WithInsecureSkipVerify()))
+	response, err = other.SendWithContext(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":"quic-2"}`, string(response))
+}
+
+func TestServerNotifications(t *testing.T) {
+	server := startServer(t, echo)
+	client := startTransport(t, NewTransport(server.Addr(), false, WithInsecureSkipVerify()))
+	notifications := make(chan string, 1)
+	client.SetNotificationHandler(func(method string, message []byte) {
+		notifications <- method
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The server learns of the connection once the client opens a stream
+	_, err := client.SendWithContext(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	require.NoError(t, err)
+
+	require.NoError(t, server.Send([]byte(`{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`)))
+	select {
+	case method := <-notifications:
+		assert.Equal(t, "notifications/tools/list_changed", method)
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the notification")
+	}
+}
+
+func TestRequestCanceled(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	server := startServer(t, func(sessionID string, message []byte) ([]byte, error) {
+		<-release
+		return echo(sessionID, message)
+	})
+	client := startTransport(t, NewTransport(server.Addr(), false, WithInsecureSkipVerify()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	_, err := client.SendWithContext(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"slow"}`))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestUntrustedCertificate(t *testing.T) {
+	server := startServer(t, echo)
+
+	err := NewTransport(server.Addr(), false, WithConnectTimeout(2*time.Second)).Start()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "certificate")
+}
+
+func TestMessageTooLarge(t *testing.T) {
+	server := startServer(t, echo)
+	client := startTransport(t, NewTransport(server.Addr(), false, WithInsecureSkipVerify(), WithMaxMessageSize(16)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.SendWithContext(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	assert.ErrorIs(t, err, ErrMessageTooLarge)
+}
@@ -0,0 +1,165 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// Error is an error reply from the Redis server.
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// dialConfig holds what is needed to open an authenticated connection
+type dialConfig struct {
+	address   string
+	username  string
+	password  string
+	tlsConfig *tls.Config
+}
+
+// conn is a connection speaking RESP, the Redis serialization protocol.
+// Commands are serialized by a mutex, so a connection that is not subscribed can be
+// shared.
+type conn struct {
+	netConn net.Conn
+	reader  *bufio.Reader
+	mu      sync.Mutex
+}
+
+// dial opens a connection and authenticates it
+func dial(ctx context.Context, config dialConfig) (*conn, error) {
+	var netConn net.Conn
+	var err error
+	if config.tlsConfig != nil {
+		dialer := &tls.Dialer{Config: config.tlsConfig}
+		netConn, err = dialer.DialContext(ctx, "tcp", config.address)
+	} else {
+		var dialer net.Dialer
+		netConn, err = dialer.DialContext(ctx, "tcp", config.address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &conn{netConn: netConn, reader: bufio.NewReader(netConn)}
+
+	if config.password != "" {
+		args := []string{"AUTH", config.password}
+		if config.username != "" {
+			args = []string{"AUTH", config.username, config.password}
+		}
+		if _, err := c.do(args...); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// do sends a command and reads its reply
+func (c *conn) do(args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.write(args...); err != nil {
+		return nil, err
+	}
+	reply, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+	if replyErr, ok := reply.(Error); ok {
+		return nil, replyErr
+	}
+	return reply, nil
+}
+
+// write sends a command as an array of bulk strings
+func (c *conn) write(args ...string) error {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+	_, err := c.netConn.Write(buf)
+	return err
+}
+
+// read reads a reply: a string, Error, int64, []byte (nil for a null bulk
+// string) or []interface{}
+func (c *conn) read() (interface{}, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return string(line[1:]), nil
+	case '-':
+		return Error(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(string(line[1:]), 10, 64)
+	case '$':
+		size, err := strconv.Atoi(string(line[1:]))
+		if err != nil || size < 0 {
+			return []byte(nil), err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(string(line[1:]))
+		if err != nil || count < 0 {
+			return []interface{}(nil), err
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected reply type %q", line[0])
+	}
+}
+
+// readLine reads a CRLF terminated line without the terminator
+func (c *conn) readLine() ([]byte, error) {
+	line, err := c.reader.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, errors.New("malformed reply line")
+	}
+	return line[:len(line)-2], nil
+}
+
+// Close closes the connection
+func (c *conn) Close() error {
+	return c.netConn.Close()
+}
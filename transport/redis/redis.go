@@ -0,0 +1,569 @@
+// Package redis provides a Redis pub/sub implementation of the MCP transport.
+//
+// Clients publish requests to a request channel of their own, and the server,
+// subscribed to every request channel with a pattern, publishes each response to
+// the matching response channel. Server notifications go to a notification channel
+// that every client subscribes to:
+//
+//	<prefix>:requests:<clientID>   client to server
+//	<prefix>:responses:<clientID>  server to one client
+//	<prefix>:notifications         server to every client
+//
+// Redis pub/sub delivers messages at most once to the subscribers connected when
+// they are published, so the transport suits deployments that already run Redis
+// and want a lightweight broker rather than durable queues.
+//
+// The package speaks the Redis protocol itself and needs no Redis client library.
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/localrivet/gomcp/transport"
+)
+
+// Default configuration values.
+const (
+	DefaultChannelPrefix  = "mcp"
+	DefaultPort           = "6379"
+	DefaultConnectTimeout = 10 * time.Second
+	DefaultReconnectWait  = 2 * time.Second
+)
+
+// Transport errors.
+var (
+	ErrNotRunning    = errors.New("transport not running")
+	ErrNotServerMode = errors.New("not supported in server mode")
+)
+
+// Option represents a configuration option for the Redis transport.
+type Option func(*Transport)
+
+// WithClientID sets the client ID, which names the client's request and response
+// channels and is the session the server sees. By default a unique ID is generated.
+func WithClientID(clientID string) Option {
+	return func(t *Transport) {
+		t.clientID = clientID
+	}
+}
+
+// WithChannelPrefix sets the prefix of the transport's channels.
+func WithChannelPrefix(prefix string) Option {
+	return func(t *Transport) {
+		t.channelPrefix = prefix
+	}
+}
+
+// WithCredentials sets the username and password for Redis ACL authentication.
+func WithCredentials(username, password string) Option {
+	return func(t *Transport) {
+		t.username = username
+		t.password = password
+	}
+}
+
+// WithPassword sets the password for servers protected by requirepass.
+func WithPassword(password string) Option {
+	return func(t *Transport) {
+		t.password = password
+	}
+}
+
+// WithTLSConfig connects to Redis over TLS with the given configuration.
+// transport.LoadClientTLSConfig builds one from PEM files. "rediss://" addresses
+// use TLS with the default configuration.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(t *Transport) {
+		t.tlsConfig = config
+	}
+}
+
+// WithConnectTimeout sets the timeout for connecting to Redis.
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		if timeout > 0 {
+			t.connectTimeout = timeout
+		}
+	}
+}
+
+// WithReconnectWait sets how long the transport waits before reconnecting after
+// losing its subscription connection.
+func WithReconnectWait(wait time.Duration) Option {
+	return func(t *Transport) {
+		if wait > 0 {
+			t.reconnectWait = wait
+		}
+	}
+}
+
+// Transport implements the transport.Transport interface for Redis pub/sub.
+//
+// In server mode it serves the requests of every client; in client mode it
+// publishes requests and matches responses with SendWithContext.
+type Transport struct {
+	transport.BaseTransport
+
+	address        string
+	isServer       bool
+	clientID       string
+	channelPrefix  string
+	username       string
+	password       string
+	tlsConfig      *tls.Config
+	connectTimeout time.Duration
+	reconnectWait  time.Duration
+
+	publisher *conn
+	pubMu     sync.Mutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	runMu     sync.Mutex
+	running   bool
+
+	// The subscription connection, replaced on reconnect
+	subscriber *conn
+	subMu      sync.Mutex
+
+	// Client mode: pending requests and the notification handler
+	pending        map[string]chan []byte
+	pendingMu      sync.Mutex
+	notifyHandler  func(method string, message []byte)
+	notifyHandlerM sync.RWMutex
+}
+
+// NewTransport creates a new Redis transport.
+//
+// The address is "host:port", "redis://[user:password@]host:port" or
+// "rediss://..." for TLS. Credentials in the address apply unless set with an option.
+func NewTransport(address string, isServer bool, options ...Option) *Transport {
+	t := &Transport{
+		isServer:       isServer,
+		channelPrefix:  DefaultChannelPrefix,
+		connectTimeout: DefaultConnectTimeout,
+		reconnectWait:  DefaultReconnectWait,
+	}
+	t.parseAddress(address)
+
+	for _, option := range options {
+		option(t)
+	}
+
+	if t.clientID == "" && !isServer {
+		t.clientID = fmt.Sprintf("mcp-client-%d", time.Now().UnixNano())
+	}
+
+	return t
+}
+
+// parseAddress sets the host, port, credentials and TLS from a Redis URL
+func (t *Transport) parseAddress(address string) {
+	t.address = address
+	if !strings.Contains(address, "://") {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			t.address = net.JoinHostPort(address, DefaultPort)
+		}
+		return
+	}
+
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return
+	}
+	host := parsed.Host
+	if parsed.Port() == "" {
+		host = net.JoinHostPort(parsed.Hostname(), DefaultPort)
+	}
+	t.address = host
+
+	if parsed.User != nil {
+		t.username = parsed.User.Username()
+		t.password, _ = parsed.User.Password()
+		if t.password == "" {
+			// redis://:password@host carries only a password
+			t.password, t.username = t.username, ""
+		}
+	}
+	if parsed.Scheme == "rediss" {
+		t.tlsConfig = &tls.Config{ServerName: parsed.Hostname()}
+	}
+}
+
+// ClientID returns the client's ID (client mode only).
+func (t *Transport) ClientID() string {
+	return t.clientID
+}
+
+// Initialize connects the publishing connection.
+func (t *Transport) Initialize() error {
+	publisher, err := t.dial()
+	if err != nil {
+		return fmt.Errorf("failed to connect to Redis at %s: %w", t.address, err)
+	}
+	t.publisher = publisher
+	t.pending = make(map[string]chan []byte)
+	return nil
+}
+
+// dial opens an authenticated connection, bounded by the connect timeout
+func (t *Transport) dial() (*conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), t.connectTimeout)
+	defer cancel()
+	return dial(ctx, dialConfig{
+		address:   t.address,
+		username:  t.username,
+		password:  t.password,
+		tlsConfig: t.tlsConfig,
+	})
+}
+
+// Start subscribes to the transport's channels. It returns once the subscription
+// is confirmed, so responses to requests sent afterwards are not missed.
+func (t *Transport) Start() error {
+	t.runMu.Lock()
+	defer t.runMu.Unlock()
+
+	if t.publisher == nil {
+		return errors.New("transport not initialized")
+	}
+	if t.running {
+		return nil
+	}
+
+	subscriber, err := t.subscribe()
+	if err != nil {
+		return err
+	}
+
+	t.ctx, t.cancel = context.WithCancel(context.Background())
+	t.subscriber = subscriber
+	t.running = true
+	t.wg.Add(1)
+	go t.listen(subscriber)
+	return nil
+}
+
+// Stop unsubscribes and closes the connections.
+func (t *Transport) Stop() error {
+	t.runMu.Lock()
+	if !t.running {
+		t.runMu.Unlock()
+		return nil
+	}
+	t.running = false
+	t.cancel()
+	t.runMu.Unlock()
+
+	t.subMu.Lock()
+	subscriber := t.subscriber
+	t.subMu.Unlock()
+
+	var errs []error
+	if err := subscriber.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		errs = append(errs, fmt.Errorf("failed to close Redis subscription: %w", err))
+	}
+	t.wg.Wait()
+	t.pubMu.Lock()
+	publisher := t.publisher
+	t.pubMu.Unlock()
+	if err := publisher.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close Redis connection: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// Send publishes a message without waiting for a response.
+//
+// In server mode the message, typically a notification, goes to every client. In
+// client mode it goes to the server.
+func (t *Transport) Send(message []byte) error {
+	if t.isServer {
+		return t.publish(t.notificationChannel(), message)
+	}
+	return t.publish(t.requestChannel(t.clientID), message)
+}
+
+// Receive is not supported; incoming messages are delivered to the message
+// handler (server mode) or the notification handler (client mode).
+func (t *Transport) Receive() ([]byte, error) {
+	return nil, errors.New("not implemented: Redis transport delivers messages to handlers")
+}
+
+// SendWithContext publishes a request and waits for its response (client mode only).
+// Notifications and responses return immediately with an empty result.
+func (t *Transport) SendWithContext(ctx context.Context, message []byte) ([]byte, error) {
+	if t.isServer {
+		return nil, fmt.Errorf("SendWithContext: %w", ErrNotServerMode)
+	}
+
+	var envelope struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid JSON message: %w", err)
+	}
+	if len(envelope.ID) == 0 || envelope.Method == "" {
+		return []byte{}, t.publish(t.requestChannel(t.clientID), message)
+	}
+
+	key := string(envelope.ID)
+	responseCh := make(chan []byte, 1)
+	t.pendingMu.Lock()
+	t.pending[key] = responseCh
+	t.pendingMu.Unlock()
+	defer func() {
+		t.pendingMu.Lock()
+		delete(t.pending, key)
+		t.pendingMu.Unlock()
+	}()
+
+	if err := t.publish(t.requestChannel(t.clientID), message); err != nil {
+		return nil, err
+	}
+
+	select {
+	case response := <-responseCh:
+		return response, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-t.ctx.Done():
+		return nil, fmt.Errorf("transport stopped: %w", t.ctx.Err())
+	}
+}
+
+// SetNotificationHandler sets the handler of server-initiated messages (client
+// mode only). The handler receives the method and the whole JSON-RPC message.
+func (t *Transport) SetNotificationHandler(handler func(method string, message []byte)) {
+	t.notifyHandlerM.Lock()
+	defer t.notifyHandlerM.Unlock()
+	t.notifyHandler = handler
+}
+
+// requestChannel returns the channel a client publishes requests to
+func (t *Transport) requestChannel(clientID string) string {
+	return t.channelPrefix + ":requests:" + clientID
+}
+
+// responseChannel returns the channel the server publishes a client's responses to
+func (t *Transport) responseChannel(clientID string) string {
+	return t.channelPrefix + ":responses:" + clientID
+}
+
+// notificationChannel returns the channel of messages for every client
+func (t *Transport) notificationChannel() string {
+	return t.channelPrefix + ":notifications"
+}
+
+// publish publishes a message to a channel
+func (t *Transport) publish(channel string, message []byte) error {
+	t.runMu.Lock()
+	running := t.running
+	t.runMu.Unlock()
+	if !running {
+		return ErrNotRunning
+	}
+
+	t.pubMu.Lock()
+	publisher := t.publisher
+	t.pubMu.Unlock()
+
+	_, err := publisher.do("PUBLISH", channel, string(message))
+	var replyErr Error
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection was lost; reconnect once and retry
+		if publisher, err = t.reconnectPublisher(publisher); err == nil {
+			_, err = publisher.do("PUBLISH", channel, string(message))
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", channel, err)
+	}
+	return nil
+}
+
+// reconnectPublisher replaces a failed publishing connection, unless a concurrent
+// publish has already replaced it
+func (t *Transport) reconnectPublisher(failed *conn) (*conn, error) {
+	t.pubMu.Lock()
+	defer t.pubMu.Unlock()
+
+	if t.publisher != failed {
+		return t.publisher, nil
+	}
+	publisher, err := t.dial()
+	if err != nil {
+		return nil, err
+	}
+	failed.Close()
+	t.publisher = publisher
+	return publisher, nil
+}
+
+// subscribe opens a connection subscribed to the transport's channels, and waits
+// for the subscriptions to be confirmed
+func (t *Transport) subscribe() (*conn, error) {
+	subscriber, err := t.dial()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis at %s: %w", t.address, err)
+	}
+
+	command := []string{"SUBSCRIBE", t.responseChannel(t.clientID), t.notificationChannel()}
+	if t.isServer {
+		command = []string{"PSUBSCRIBE", escapePattern(t.requestChannel("")) + "*"}
+	}
+	if err := subscriber.write(command...); err != nil {
+		subscriber.Close()
+		return nil, fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	for i := 1; i < len(command); i++ {
+		reply, err := subscriber.read()
+		if err == nil {
+			if replyErr, ok := reply.(Error); ok {
+				err = replyErr
+			}
+		}
+		if err != nil {
+			subscriber.Close()
+			return nil, fmt.Errorf("failed to subscribe: %w", err)
+		}
+	}
+	return subscriber, nil
+}
+
+// listen reads published messages, resubscribing after the connection drops,
+// until the transport stops
+func (t *Transport) listen(subscriber *conn) {
+	defer t.wg.Done()
+
+	for {
+		reply, err := subscriber.read()
+		if err == nil {
+			t.dispatch(reply)
+			continue
+		}
+
+		subscriber.Close()
+		if t.ctx.Err() != nil {
+			return
+		}
+		t.GetLogger().Warn("lost Redis subscription, reconnecting", "address", t.address, "error", err)
+
+		for {
+			select {
+			case <-time.After(t.reconnectWait):
+			case <-t.ctx.Done():
+				return
+			}
+			if subscriber, err = t.subscribe(); err == nil {
+				break
+			}
+			t.GetLogger().Warn("failed to resubscribe to Redis", "address", t.address, "error", err)
+		}
+
+		t.subMu.Lock()
+		t.subscriber = subscriber
+		t.subMu.Unlock()
+		// Stop may have closed the previous connection while we reconnected
+		if t.ctx.Err() != nil {
+			subscriber.Close()
+			return
+		}
+	}
+}
+
+// dispatch handles a push message of the subscription connection
+func (t *Transport) dispatch(reply interface{}) {
+	items, ok := reply.([]interface{})
+	if !ok || len(items) < 3 {
+		return
+	}
+	kind, _ := items[0].([]byte)
+
+	switch string(kind) {
+	case "message":
+		channel, _ := items[1].([]byte)
+		payload, _ := items[2].([]byte)
+		t.deliver(string(channel), payload)
+	case "pmessage":
+		if len(items) < 4 {
+			return
+		}
+		channel, _ := items[2].([]byte)
+		payload, _ := items[3].([]byte)
+		clientID := strings.TrimPrefix(string(channel), t.requestChannel(""))
+		t.wg.Add(1)
+		go t.serve(clientID, payload)
+	}
+}
+
+// serve handles a request and publishes its response (server mode)
+func (t *Transport) serve(clientID string, message []byte) {
+	defer t.wg.Done()
+
+	response, err := t.HandleSessionMessage(clientID, message)
+	if err != nil {
+		t.GetLogger().Error("failed to handle Redis message", "client", clientID, "error", err)
+	}
+	if response == nil {
+		return
+	}
+	if err := t.publish(t.responseChannel(clientID), response); err != nil && t.ctx.Err() == nil {
+		t.GetLogger().Error("failed to publish Redis response", "client", clientID, "error", err)
+	}
+}
+
+// deliver passes a message to the waiting request or the notification handler
+// (client mode)
+func (t *Transport) deliver(channel string, message []byte) {
+	var envelope struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		t.GetLogger().Warn("ignoring invalid Redis message", "channel", channel, "error", err)
+		return
+	}
+
+	if envelope.Method == "" {
+		t.pendingMu.Lock()
+		responseCh, ok := t.pending[string(envelope.ID)]
+		delete(t.pending, string(envelope.ID))
+		t.pendingMu.Unlock()
+		if ok {
+			responseCh <- message
+		}
+		return
+	}
+
+	t.notifyHandlerM.RLock()
+	handler := t.notifyHandler
+	t.notifyHandlerM.RUnlock()
+	if handler != nil {
+		handler(envelope.Method, message)
+	}
+}
+
+// escapePattern escapes the glob characters of a channel name for PSUBSCRIBE
+func escapePattern(channel string) string {
+	var b strings.Builder
+	for _, r := range channel {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
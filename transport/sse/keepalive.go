@@ -0,0 +1,145 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultKeepAliveInterval is the default interval between keepalive comments on
+// idle server streams
+const DefaultKeepAliveInterval = 15 * time.Second
+
+// DefaultReconnectDelay is the default time a client waits before reconnecting,
+// unless the server sends a retry hint
+const DefaultReconnectDelay = 5 * time.Second
+
+// ErrHeartbeatTimeout is returned when a client stream receives nothing, not even
+// a keepalive comment, within the heartbeat timeout
+var ErrHeartbeatTimeout = errors.New("SSE stream missed its heartbeat")
+
+// WithKeepAlive returns an option that sets how often a server writes a
+// ": keepalive" comment to each stream, so that proxies and load balancers with
+// idle timeouts, such as nginx or AWS ALB, keep the connection open. An interval
+// of 0 disables keepalives.
+func (Options) WithKeepAlive(interval time.Duration) Option {
+	return func(t *Transport) {
+		t.keepAliveInterval = interval
+	}
+}
+
+// WithRetryHint returns an option that makes a server send a retry field when a
+// stream opens, telling clients how long to wait before reconnecting. A hint of 0
+// sends none.
+func (Options) WithRetryHint(retry time.Duration) Option {
+	return func(t *Transport) {
+		t.retryHint = retry
+	}
+}
+
+// WithHeartbeatTimeout returns an option that makes a client drop and reconnect
+// a stream that receives nothing, including keepalive comments, for the given
+// time. Set it to a few keepalive intervals of the server. A timeout of 0, the
+// default, never drops idle streams.
+func (Options) WithHeartbeatTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		t.heartbeatTimeout = timeout
+	}
+}
+
+// writeRetryHint sends the retry hint at the start of a stream, if one is set
+func (t *Transport) writeRetryHint(w http.ResponseWriter) error {
+	if t.retryHint <= 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", t.retryHint.Milliseconds()); err != nil {
+		return err
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// keepAlive returns a channel that fires when a stream is due a keepalive
+// comment, and a function releasing it. The channel is nil when keepalives are
+// disabled, so selecting on it blocks forever.
+func (t *Transport) keepAlive() (<-chan time.Time, func()) {
+	if t.keepAliveInterval <= 0 {
+		return nil, func() {}
+	}
+	ticker := time.NewTicker(t.keepAliveInterval)
+	return ticker.C, ticker.Stop
+}
+
+// writeKeepAlive writes a keepalive comment, which clients ignore
+func (t *Transport) writeKeepAlive(w http.ResponseWriter) error {
+	if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+		t.GetLogger().Debug("Failed to send keepalive", "error", err)
+		return err
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// heartbeat cancels a client stream that stays silent for longer than the
+// heartbeat timeout
+type heartbeat struct {
+	timer   *time.Timer
+	timeout time.Duration
+	expired atomic.Bool
+}
+
+// watchHeartbeat starts watching a stream, canceling it through cancel when it
+// goes silent. It returns nil when no heartbeat timeout is set.
+func (t *Transport) watchHeartbeat(cancel context.CancelFunc) *heartbeat {
+	if t.heartbeatTimeout <= 0 {
+		return nil
+	}
+	h := &heartbeat{timeout: t.heartbeatTimeout}
+	h.timer = time.AfterFunc(h.timeout, func() {
+		h.expired.Store(true)
+		cancel()
+	})
+	return h
+}
+
+// beat records that the stream received data
+func (h *heartbeat) beat() {
+	if h != nil {
+		h.timer.Reset(h.timeout)
+	}
+}
+
+// stop stops watching the stream
+func (h *heartbeat) stop() {
+	if h != nil {
+		h.timer.Stop()
+	}
+}
+
+// streamError returns ErrHeartbeatTimeout for a read failing because the stream
+// went silent, and err otherwise
+func (h *heartbeat) streamError(err error) error {
+	if h != nil && h.expired.Load() {
+		return ErrHeartbeatTimeout
+	}
+	return err
+}
+
+// applyRetryHint stores a retry field received from the server as the delay
+// before the next reconnect
+func (t *Transport) applyRetryHint(value []byte) {
+	ms, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil || ms < 0 {
+		return
+	}
+	t.reconnectDelay.Store(int64(time.Duration(ms) * time.Millisecond))
+	t.GetLogger().Debug("Server set reconnect delay", "delay_ms", ms)
+}
@@ -0,0 +1,79 @@
+package sse
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/localrivet/gomcp/transport"
+)
+
+// MigrationGuide documents the move from the HTTP+SSE transport to Streamable HTTP.
+const MigrationGuide = "https://modelcontextprotocol.io/specification/2025-03-26/basic/transports#backwards-compatibility"
+
+// MigrationHint is the JSON body a server answers requests to the disabled legacy
+// endpoints with, telling clients where the MCP endpoint has moved.
+type MigrationHint struct {
+	Error           string `json:"error"`
+	Endpoint        string `json:"endpoint"`        // URL of the unified MCP endpoint
+	Transport       string `json:"transport"`       // Transport to use instead
+	ProtocolVersion string `json:"protocolVersion"` // First protocol version of that transport
+	Documentation   string `json:"documentation"`
+}
+
+// WithoutLegacyEndpoints returns an option that stops a server from serving the
+// HTTP+SSE endpoints of the 2024-11-05 protocol. Requests to the legacy events
+// path are answered with 410 Gone and a MigrationHint instead of a stream, and
+// the legacy client is still reported (see SetLegacyClientHandler). The unified
+// MCP endpoint, which also receives the POSTs of legacy clients, is not affected.
+func (Options) WithoutLegacyEndpoints() Option {
+	return func(t *Transport) {
+		t.legacyDisabled = true
+	}
+}
+
+// SetLegacyClientHandler sets the handler called for every client of the legacy
+// endpoints, whether they are served or disabled.
+func (t *Transport) SetLegacyClientHandler(handler transport.LegacyClientHandler) {
+	t.legacyHandler = handler
+}
+
+// handleLegacyRequest serves the legacy events path: a stream with endpoint
+// discovery, marked as deprecated, or a migration hint once it is disabled
+func (t *Transport) handleLegacyRequest(w http.ResponseWriter, r *http.Request) {
+	if t.legacyHandler != nil {
+		t.legacyHandler(transport.NewLegacyClient(r, t.legacyDisabled))
+	}
+
+	endpoint := t.endpointURL(r)
+	if t.legacyDisabled {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", endpoint))
+		w.WriteHeader(http.StatusGone)
+		_ = json.NewEncoder(w).Encode(MigrationHint{
+			Error:           "the HTTP+SSE transport of protocol version 2024-11-05 is no longer served",
+			Endpoint:        endpoint,
+			Transport:       "streamable-http",
+			ProtocolVersion: "2025-03-26",
+			Documentation:   MigrationGuide,
+		})
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", endpoint))
+	t.handleLegacySSEConnection(w, r)
+}
+
+// endpointURL returns the URL of the unified MCP endpoint as reached by a request
+func (t *Transport) endpointURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, r.Host, t.GetFullMCPPath())
+}
@@ -0,0 +1,110 @@
+package sse
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/localrivet/gomcp/transport"
+)
+
+// WithReadHeaderTimeout returns an option that sets how long a server waits for a
+// request's headers, which guards against slowloris clients. 0 disables the limit.
+func (Options) WithReadHeaderTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		t.limits.ReadHeaderTimeout = timeout
+	}
+}
+
+// WithReadTimeout returns an option that sets how long a server waits for a whole
+// request, including its body. 0 disables the limit.
+func (Options) WithReadTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		t.limits.ReadTimeout = timeout
+	}
+}
+
+// WithWriteTimeout returns an option that sets how long a server may take to write
+// a response, including the time a tool call runs. SSE streams are exempt. 0, the
+// default, disables the limit.
+func (Options) WithWriteTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		t.limits.WriteTimeout = timeout
+	}
+}
+
+// WithIdleTimeout returns an option that sets how long a server keeps an idle
+// keep-alive connection open. 0 disables the limit.
+func (Options) WithIdleTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		t.limits.IdleTimeout = timeout
+	}
+}
+
+// WithMaxHeaderBytes returns an option that limits the size of request headers a
+// server accepts. 0 uses the net/http default of 1 MB.
+func (Options) WithMaxHeaderBytes(size int) Option {
+	return func(t *Transport) {
+		t.limits.MaxHeaderBytes = size
+	}
+}
+
+// WithMaxConnections returns an option that limits how many connections a server
+// holds open at once; further connections wait for one to close. 0, the default,
+// disables the limit.
+func (Options) WithMaxConnections(n int) Option {
+	return func(t *Transport) {
+		t.limits.MaxConnections = n
+	}
+}
+
+// WithAccessLog returns an option that logs every request the server answers,
+// in the combined log format or as structured records. Streams are logged when
+// they close, and health check paths are excluded by default.
+func (Options) WithAccessLog(config transport.AccessLogConfig) Option {
+	return func(t *Transport) {
+		t.accessLog = &config
+	}
+}
+
+// WithAccessPolicy returns an option that restricts which client IPs may reach
+// the server and how many requests and connections each may make. Rejected
+// requests get 403 Forbidden or 429 Too Many Requests, and are reported to the
+// violation handler.
+func (Options) WithAccessPolicy(policy transport.AccessPolicy) Option {
+	return func(t *Transport) {
+		t.accessPolicy = &policy
+	}
+}
+
+// WithServerLimits returns an option that sets all of a server's timeouts and
+// limits at once, replacing transport.DefaultServerLimits.
+func (Options) WithServerLimits(limits transport.ServerLimits) Option {
+	return func(t *Transport) {
+		t.limits = limits
+	}
+}
+
+// SetViolationHandler sets the handler called for every client the access policy
+// rejects.
+func (t *Transport) SetViolationHandler(handler transport.ViolationHandler) {
+	t.violationHandler = handler
+}
+
+// reportViolation passes a rejected client to the violation handler, if any
+func (t *Transport) reportViolation(violation transport.Violation) {
+	if t.violationHandler != nil {
+		t.violationHandler(violation)
+	}
+}
+
+// accessLogHandler wraps the server's handler with the access log, if enabled
+func (t *Transport) accessLogHandler(handler http.Handler) http.Handler {
+	if t.accessLog == nil {
+		return handler
+	}
+	config := *t.accessLog
+	if config.Logger == nil {
+		config.Logger = t.GetLogger()
+	}
+	return transport.AccessLog(handler, config)
+}
@@ -0,0 +1,150 @@
+package sse
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultReplayBufferSize is the default number of events kept per session for resumption
+const DefaultReplayBufferSize = 100
+
+// DefaultReplayRetention is the default time events are kept for resumption
+const DefaultReplayRetention = 5 * time.Minute
+
+// WithReplayBufferSize returns an option that sets how many events are kept per
+// session so that a client reconnecting with Last-Event-ID receives the messages
+// it missed. A size of 0 disables resumption.
+func (Options) WithReplayBufferSize(size int) Option {
+	return func(t *Transport) {
+		t.replaySize = size
+	}
+}
+
+// WithReplayRetention returns an option that sets how long events are kept for
+// resumption. A retention of 0 keeps events until the buffer is full.
+func (Options) WithReplayRetention(retention time.Duration) Option {
+	return func(t *Transport) {
+		t.replayRetention = retention
+	}
+}
+
+// sseEvent is a server message with the event ID it is delivered under
+type sseEvent struct {
+	id   int64
+	data []byte
+	at   time.Time
+}
+
+// replayBuffer is a bounded, time-limited log of the events sent on a stream
+type replayBuffer struct {
+	events []sseEvent
+}
+
+// append adds an event, dropping the oldest one if the buffer is full
+func (b *replayBuffer) append(ev sseEvent, size int) {
+	if len(b.events) >= size {
+		copy(b.events, b.events[len(b.events)-size+1:])
+		b.events = b.events[:size-1]
+	}
+	b.events = append(b.events, ev)
+}
+
+// since returns the events after lastID that are still within the retention period
+func (b *replayBuffer) since(lastID int64, retention time.Duration) []sseEvent {
+	cutoff := time.Time{}
+	if retention > 0 {
+		cutoff = time.Now().Add(-retention)
+	}
+
+	var missed []sseEvent
+	for _, ev := range b.events {
+		if ev.id > lastID && ev.at.After(cutoff) {
+			missed = append(missed, ev)
+		}
+	}
+	return missed
+}
+
+// replayState holds the per-session replay buffers of a server transport.
+// Streams without a session share the buffer stored under the empty key.
+type replayState struct {
+	mu      sync.Mutex
+	buffers map[string]*replayBuffer
+}
+
+// newEvent assigns the next event ID to a message and records it in every
+// replay buffer, so it can be replayed to streams that miss it
+func (t *Transport) newEvent(message []byte) sseEvent {
+	ev := sseEvent{id: t.nextEventIDValue(), data: message, at: time.Now()}
+	if t.replaySize <= 0 {
+		return ev
+	}
+
+	t.replay.mu.Lock()
+	for _, buffer := range t.replay.buffers {
+		buffer.append(ev, t.replaySize)
+	}
+	t.replay.mu.Unlock()
+
+	return ev
+}
+
+// newSessionEvent assigns the next event ID to a message meant for one session's
+// stream and records it in that session's replay buffer only. Streams without a
+// session share a buffer, so their messages are not recorded at all.
+func (t *Transport) newSessionEvent(sessionID string, message []byte) sseEvent {
+	ev := sseEvent{id: t.nextEventIDValue(), data: message, at: time.Now()}
+	if t.replaySize <= 0 || sessionID == "" {
+		return ev
+	}
+
+	t.replay.mu.Lock()
+	if buffer, exists := t.replay.buffers[sessionID]; exists {
+		buffer.append(ev, t.replaySize)
+	}
+	t.replay.mu.Unlock()
+
+	return ev
+}
+
+// trackReplay starts buffering events for a session's stream
+func (t *Transport) trackReplay(sessionID string) {
+	if t.replaySize <= 0 {
+		return
+	}
+
+	t.replay.mu.Lock()
+	if t.replay.buffers == nil {
+		t.replay.buffers = make(map[string]*replayBuffer)
+	}
+	if _, exists := t.replay.buffers[sessionID]; !exists {
+		t.replay.buffers[sessionID] = &replayBuffer{}
+	}
+	t.replay.mu.Unlock()
+}
+
+// forgetReplay discards the replay buffer of a terminated session
+func (t *Transport) forgetReplay(sessionID string) {
+	t.replay.mu.Lock()
+	delete(t.replay.buffers, sessionID)
+	t.replay.mu.Unlock()
+}
+
+// missedEvents returns the buffered events of a session after the given Last-Event-ID.
+// An unparseable ID yields no events.
+func (t *Transport) missedEvents(sessionID, lastEventID string) []sseEvent {
+	lastID, err := strconv.ParseInt(lastEventID, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	t.replay.mu.Lock()
+	defer t.replay.mu.Unlock()
+
+	buffer, exists := t.replay.buffers[sessionID]
+	if !exists {
+		return nil
+	}
+	return buffer.since(lastID, t.replayRetention)
+}
@@ -13,6 +13,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/tls"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -67,6 +68,12 @@ func (Options) WithMessagePath(path string) Option {
 	}
 }
 
+// StreamIDHeader carries the ID the server assigns to each SSE stream. Clients send
+// it back with their POSTs, so that the server handles them as a session of their
+// own and sends the messages meant for that session, such as progress
+// notifications, to that stream only.
+const StreamIDHeader = "Mcp-Stream-Id"
+
 // DefaultShutdownTimeout is the default timeout for graceful shutdown
 const DefaultShutdownTimeout = 10 * time.Second
 
@@ -87,18 +94,49 @@ type Transport struct {
 	isClient bool
 
 	// For server mode
-	clients     map[string]chan []byte // Map client ID to message channel
+	clients     map[string]chan sseEvent // Map client ID to event channel
+	streams     map[string]string        // Map client ID to the Mcp-Session-Id its stream was opened with
 	clientsMu   sync.Mutex
 	pathPrefix  string // Optional prefix for endpoint paths (e.g., "/api")
 	mcpEndpoint string // Unified MCP endpoint path
 	eventsPath  string // Legacy events path for 2024-11-05 compatibility
 
+	// Legacy endpoints: whether they are disabled, and the handler of their clients
+	legacyDisabled bool
+	legacyHandler  transport.LegacyClientHandler
+
 	// Session management (2025-03-26/draft)
 	sessions       map[string]*SessionInfo // Map session ID to session info
 	sessionsMu     sync.Mutex
 	nextEventID    int64 // For SSE event IDs
 	enableSessions bool  // Whether to use session management
 
+	// Resumability: events kept per session for replay after Last-Event-ID
+	replay          replayState
+	replaySize      int
+	replayRetention time.Duration
+
+	// Server timeouts and connection limit, and the access log (nil when disabled)
+	limits    transport.ServerLimits
+	accessLog *transport.AccessLogConfig
+
+	// Client restrictions, nil when disabled, and the handler of rejected clients
+	accessPolicy     *transport.AccessPolicy
+	violationHandler transport.ViolationHandler
+
+	// Idle stream handling: keepalive comments and the retry hint sent to clients
+	keepAliveInterval time.Duration
+	retryHint         time.Duration
+
+	// TLS configuration, from files or set directly
+	tlsConfig         *tls.Config
+	tlsCertFile       string // Server certificate
+	tlsKeyFile        string
+	tlsClientCAFile   string // CA for verifying client certificates (mutual TLS)
+	tlsRootCAFile     string // CA for verifying the server certificate
+	tlsClientCertFile string // Client certificate for mutual TLS
+	tlsClientKeyFile  string
+
 	// For client mode
 	url       string
 	client    *http.Client
@@ -107,6 +145,12 @@ type Transport struct {
 	doneCh    chan struct{}
 	connected atomic.Bool
 	mcpURL    atomic.Pointer[string] // Complete URL for the MCP endpoint
+
+	lastEventID atomic.Pointer[string] // ID of the last event received, sent as Last-Event-ID on reconnect
+	streamID    atomic.Pointer[string] // Stream ID assigned by the server, sent with every POST
+
+	heartbeatTimeout time.Duration // Reconnect streams silent for this long
+	reconnectDelay   atomic.Int64  // Delay before reconnecting, updated by retry hints
 }
 
 // SessionInfo holds information about an active session
@@ -133,9 +177,15 @@ func NewTransport(addr string) *Transport {
 		t.readCh = make(chan []byte, 100)
 		t.errCh = make(chan error, 1)
 		t.doneCh = make(chan struct{})
+		t.reconnectDelay.Store(int64(DefaultReconnectDelay))
 	} else {
-		t.clients = make(map[string]chan []byte)
+		t.clients = make(map[string]chan sseEvent)
+		t.streams = make(map[string]string)
 		t.sessions = make(map[string]*SessionInfo)
+		t.replaySize = DefaultReplayBufferSize
+		t.replayRetention = DefaultReplayRetention
+		t.keepAliveInterval = DefaultKeepAliveInterval
+		t.limits = transport.DefaultServerLimits()
 		t.enableSessions = true // Enable session management by default for 2025-03-26/draft
 		// Set default unified endpoint
 		t.mcpEndpoint = DefaultMCPEndpoint
@@ -218,6 +268,14 @@ func (t *Transport) Initialize() error {
 // Start starts the transport
 func (t *Transport) Start() error {
 	if t.isClient {
+		tlsConfig, err := t.clientTLSConfig()
+		if err != nil {
+			return err
+		}
+		if tlsConfig != nil {
+			t.client = transport.HTTPClientWithTLS(t.client, tlsConfig)
+		}
+
 		// Start the client connection
 		go t.startClientConnection()
 		return nil
@@ -230,22 +288,38 @@ func (t *Transport) Start() error {
 	mux.HandleFunc(t.GetFullMCPPath(), t.handleMCPRequest)
 
 	// For backward compatibility with 2024-11-05, also register the legacy SSE endpoint
-	// This endpoint only handles GET requests for SSE connection with endpoint discovery
-	mux.HandleFunc(t.GetFullEventsPath(), func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodGet {
-			t.handleLegacySSEConnection(w, r)
-		} else {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
+	// This endpoint only handles GET requests for SSE connection with endpoint discovery,
+	// or answers with a migration hint once legacy endpoints are disabled
+	mux.HandleFunc(t.GetFullEventsPath(), t.handleLegacyRequest)
+
+	tlsConfig, err := t.serverTLSConfig()
+	if err != nil {
+		return err
+	}
+
+	listener, err := transport.Listen(t.addr, t.limits)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", t.addr, err)
+	}
 
-	t.server = &http.Server{
-		Addr:    t.addr,
-		Handler: mux,
+	handler, listener, err := transport.GuardServer(t.accessPolicy, t.reportViolation, mux, listener)
+	if err != nil {
+		listener.Close()
+		return fmt.Errorf("invalid access policy: %w", err)
 	}
 
+	t.server = transport.NewHTTPServer(t.addr, t.accessLogHandler(handler), t.limits)
+	t.server.TLSConfig = tlsConfig
+
 	go func() {
-		if err := t.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if tlsConfig != nil {
+			// Certificates come from the TLS config
+			err = t.server.ServeTLS(listener, "", "")
+		} else {
+			err = t.server.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
 			// Log error
 			slog.Default().Error("SSE server error", "error", err)
 		}
@@ -271,7 +345,8 @@ func (t *Transport) Stop() error {
 	for _, clientCh := range t.clients {
 		close(clientCh)
 	}
-	t.clients = make(map[string]chan []byte)
+	t.clients = make(map[string]chan sseEvent)
+	t.streams = make(map[string]string)
 	t.clientsMu.Unlock()
 
 	// Shutdown the server
@@ -296,6 +371,9 @@ func (t *Transport) Send(message []byte) error {
 		}
 
 		req.Header.Set("Content-Type", "application/json")
+		if streamID := t.StreamID(); streamID != "" {
+			req.Header.Set(StreamIDHeader, streamID)
+		}
 
 		resp, err := t.client.Do(req)
 		if err != nil {
@@ -310,10 +388,13 @@ func (t *Transport) Send(message []byte) error {
 		return nil
 	}
 
-	// Server mode - send to all connected SSE clients
+	// Server mode - assign an event ID and buffer the message for resumption
+	ev := t.newEvent(message)
+
+	// Send to all connected SSE clients
 	t.clientsMu.Lock()
 	// Create a copy of the clients map to avoid holding the lock during channel operations
-	clientChannels := make([]chan []byte, 0, len(t.clients))
+	clientChannels := make([]chan sseEvent, 0, len(t.clients))
 	for _, clientCh := range t.clients {
 		clientChannels = append(clientChannels, clientCh)
 	}
@@ -322,7 +403,7 @@ func (t *Transport) Send(message []byte) error {
 	// Send to all clients without holding the mutex
 	for _, clientCh := range clientChannels {
 		select {
-		case clientCh <- message:
+		case clientCh <- ev:
 			// Message sent successfully
 		default:
 			// Client channel full, message dropped (could be a slow client)
@@ -333,6 +414,33 @@ func (t *Transport) Send(message []byte) error {
 	return nil
 }
 
+// SendToSession sends a message to the SSE stream with the given ID, the ID the
+// transport passes to the session message handler for POSTs carrying it in the
+// Mcp-Stream-Id header. Messages for a stream that is not connected are sent to
+// all streams, as Send does.
+func (t *Transport) SendToSession(sessionID string, message []byte) error {
+	if t.isClient {
+		return t.Send(message)
+	}
+
+	t.clientsMu.Lock()
+	clientCh, exists := t.clients[sessionID]
+	replaySession := t.streams[sessionID]
+	t.clientsMu.Unlock()
+	if !exists {
+		return t.Send(message)
+	}
+
+	// Only the stream's own session may replay the message
+	ev := t.newSessionEvent(replaySession, message)
+	select {
+	case clientCh <- ev:
+	default:
+		// Client channel full, message dropped, as Send does for slow clients
+	}
+	return nil
+}
+
 // Receive receives a message (client mode only)
 func (t *Transport) Receive() ([]byte, error) {
 	if !t.isClient {
@@ -355,9 +463,10 @@ func (t *Transport) Receive() ([]byte, error) {
 	}
 }
 
-// generateClientID creates a unique client ID
+// generateClientID creates a unique client ID. Client IDs address streams in the
+// Mcp-Stream-Id header, so they are as hard to guess as session IDs.
 func (t *Transport) generateClientID() string {
-	return fmt.Sprintf("client-%d", time.Now().UnixNano())
+	return "client-" + t.generateSessionID()
 }
 
 // generateSessionID creates a cryptographically secure session ID
@@ -373,8 +482,12 @@ func (t *Transport) generateSessionID() string {
 
 // getNextEventID returns the next SSE event ID
 func (t *Transport) getNextEventID() string {
-	eventID := atomic.AddInt64(&t.nextEventID, 1)
-	return fmt.Sprintf("%d", eventID)
+	return fmt.Sprintf("%d", t.nextEventIDValue())
+}
+
+// nextEventIDValue returns the next SSE event ID as a number
+func (t *Transport) nextEventIDValue() int64 {
+	return atomic.AddInt64(&t.nextEventID, 1)
 }
 
 // validateAcceptHeader validates the Accept header according to MCP spec
@@ -418,7 +531,8 @@ func (t *Transport) handleMCPRequest(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		// Validate Accept header for GET requests (SSE) - required for SSE
 		if !t.validateAcceptHeader(r, "text/event-stream") {
-			http.Error(w, "Accept header must include text/event-stream", http.StatusBadRequest)
+			transport.WriteJSONRPCError(w, http.StatusNotAcceptable, transport.JSONRPCServerError,
+				"Accept header must include text/event-stream")
 			return
 		}
 		// Handle SSE stream establishment
@@ -431,7 +545,8 @@ func (t *Transport) handleMCPRequest(w http.ResponseWriter, r *http.Request) {
 		hasValidContentType := strings.Contains(strings.ToLower(r.Header.Get("Content-Type")), "application/json")
 
 		if !hasValidAccept && !hasValidContentType {
-			http.Error(w, "Either Accept header must include application/json/text/event-stream or Content-Type must be application/json", http.StatusBadRequest)
+			transport.WriteJSONRPCError(w, http.StatusNotAcceptable, transport.JSONRPCServerError,
+				"Either Accept header must include application/json/text/event-stream or Content-Type must be application/json")
 			return
 		}
 		// Handle client message submission
@@ -442,20 +557,22 @@ func (t *Transport) handleMCPRequest(w http.ResponseWriter, r *http.Request) {
 		t.handleSessionTermination(w, r)
 
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		transport.WriteJSONRPCError(w, http.StatusMethodNotAllowed, transport.JSONRPCServerError, "Method not allowed")
 	}
 }
 
 // handleSessionTermination handles DELETE requests for explicit session termination
 func (t *Transport) handleSessionTermination(w http.ResponseWriter, r *http.Request) {
 	if !t.enableSessions {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		w.Header().Set("Allow", "GET, POST")
+		transport.WriteJSONRPCError(w, http.StatusMethodNotAllowed, transport.JSONRPCServerError, "Session management is disabled")
 		return
 	}
 
 	sessionID := r.Header.Get("Mcp-Session-Id")
 	if sessionID == "" {
-		http.Error(w, "Mcp-Session-Id header required", http.StatusBadRequest)
+		transport.WriteJSONRPCError(w, http.StatusBadRequest, transport.JSONRPCInvalidRequest, "Mcp-Session-Id header required")
 		return
 	}
 
@@ -468,10 +585,12 @@ func (t *Transport) handleSessionTermination(w http.ResponseWriter, r *http.Requ
 	t.sessionsMu.Unlock()
 
 	if !exists {
-		http.Error(w, "Session not found", http.StatusNotFound)
+		transport.WriteJSONRPCError(w, http.StatusNotFound, transport.JSONRPCSessionNotFound, "Session not found")
 		return
 	}
 
+	t.forgetReplay(sessionID)
+
 	w.WriteHeader(http.StatusOK)
 	t.GetLogger().Debug("Session terminated", "session_id", sessionID)
 }
@@ -490,7 +609,7 @@ func (t *Transport) handleSSEConnection(w http.ResponseWriter, r *http.Request)
 			session, exists := t.sessions[sessionID]
 			if !exists {
 				t.sessionsMu.Unlock()
-				http.Error(w, "Session not found", http.StatusNotFound)
+				transport.WriteJSONRPCError(w, http.StatusNotFound, transport.JSONRPCSessionNotFound, "Session not found")
 				return
 			}
 			session.LastSeen = time.Now()
@@ -518,6 +637,11 @@ func (t *Transport) handleSSEConnection(w http.ResponseWriter, r *http.Request)
 		w.Header().Set("Mcp-Session-Id", sessionID)
 	}
 
+	// Generate a unique client ID, which the client sends back with its POSTs
+	clientID := t.generateClientID()
+	w.Header().Set(StreamIDHeader, clientID)
+	t.GetLogger().Debug("Generated client ID", "client_id", clientID)
+
 	t.GetLogger().Debug("Set SSE headers")
 
 	// Flush headers immediately to complete the HTTP response
@@ -526,16 +650,17 @@ func (t *Transport) handleSSEConnection(w http.ResponseWriter, r *http.Request)
 	}
 	t.GetLogger().Debug("Flushed SSE headers")
 
-	// Generate a unique client ID
-	clientID := t.generateClientID()
-	t.GetLogger().Debug("Generated client ID", "client_id", clientID)
-
 	// Create a channel for this client
-	clientCh := make(chan []byte, 10)
+	clientCh := make(chan sseEvent, 10)
+
+	// Start buffering events for this stream before registering it, so nothing
+	// sent from now on can be missed on a later resume
+	t.trackReplay(sessionID)
 
 	// Register the client
 	t.clientsMu.Lock()
 	t.clients[clientID] = clientCh
+	t.streams[clientID] = sessionID
 	t.clientsMu.Unlock()
 	t.GetLogger().Debug("Registered client", "client_id", clientID)
 
@@ -550,11 +675,16 @@ func (t *Transport) handleSSEConnection(w http.ResponseWriter, r *http.Request)
 		// Only close the channel if the client is still in our map (not already cleaned up)
 		if ch, exists := t.clients[clientID]; exists {
 			delete(t.clients, clientID)
+			delete(t.streams, clientID)
 			close(ch)
 		}
 		t.clientsMu.Unlock()
 	}()
 
+	if err := t.writeRetryHint(w); err != nil {
+		return
+	}
+
 	// For unified MCP endpoint (2025-03-26/draft), we don't send endpoint events
 	// The client already knows the endpoint from the URL they connected to
 	// This is different from the legacy 2024-11-05 behavior
@@ -578,28 +708,46 @@ func (t *Transport) handleSSEConnection(w http.ResponseWriter, r *http.Request)
 	}
 	// For draft and 2025-03-26, we don't send endpoint events - unified endpoint pattern
 
+	// Replay the messages a resuming client missed while disconnected
+	var lastSent int64
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		missed := t.missedEvents(sessionID, lastEventID)
+		for _, ev := range missed {
+			if err := t.writeEvent(w, ev); err != nil {
+				return
+			}
+			lastSent = ev.id
+		}
+		t.GetLogger().Debug("Replayed missed events", "last_event_id", lastEventID, "count", len(missed))
+	}
+
+	// Keep idle streams alive through proxies
+	keepAlive, stopKeepAlive := t.keepAlive()
+	defer stopKeepAlive()
+
 	// Listen for messages and send them to the client
 	for {
 		select {
-		case msg, ok := <-clientCh:
+		case ev, ok := <-clientCh:
 			if !ok {
 				// Channel closed, client disconnected
 				return
 			}
 
+			// Skip events already delivered by the replay
+			if ev.id <= lastSent {
+				continue
+			}
+
 			// Send message as SSE event with ID for resumability
-			eventID := t.getNextEventID()
-			event := fmt.Sprintf("id: %s\nevent: message\ndata: %s\n\n", eventID, string(msg))
-			if _, err := fmt.Fprint(w, event); err != nil {
-				t.GetLogger().Debug("Failed to send SSE message", "error", err, "event_id", eventID)
+			if err := t.writeEvent(w, ev); err != nil {
 				return
 			}
 
-			// Flush to ensure the message is sent immediately
-			if flusher, ok := w.(http.Flusher); ok {
-				flusher.Flush()
+		case <-keepAlive:
+			if err := t.writeKeepAlive(w); err != nil {
+				return
 			}
-			t.GetLogger().Debug("Sent SSE message", "event_id", eventID)
 
 		case <-r.Context().Done():
 			// Client disconnected
@@ -633,7 +781,7 @@ func (t *Transport) handleLegacySSEConnection(w http.ResponseWriter, r *http.Req
 	t.GetLogger().Debug("Generated client ID", "client_id", clientID)
 
 	// Create a channel for this client
-	clientCh := make(chan []byte, 10)
+	clientCh := make(chan sseEvent, 10)
 
 	// Register the client
 	t.clientsMu.Lock()
@@ -658,6 +806,10 @@ func (t *Transport) handleLegacySSEConnection(w http.ResponseWriter, r *http.Req
 		t.clientsMu.Unlock()
 	}()
 
+	if err := t.writeRetryHint(w); err != nil {
+		return
+	}
+
 	// Always send endpoint discovery event for legacy SSE endpoint
 	eventID := t.getNextEventID()
 	endpointEvent := fmt.Sprintf("id: %s\nevent: endpoint\ndata: %s\n\n", eventID, mcpURL)
@@ -672,26 +824,27 @@ func (t *Transport) handleLegacySSEConnection(w http.ResponseWriter, r *http.Req
 	}
 	t.GetLogger().Debug("Sent endpoint discovery event", "endpoint", mcpURL, "event_id", eventID)
 
+	// Keep idle streams alive through proxies
+	keepAlive, stopKeepAlive := t.keepAlive()
+	defer stopKeepAlive()
+
 	// Listen for messages and send them to the client
 	for {
 		select {
-		case msg, ok := <-clientCh:
+		case ev, ok := <-clientCh:
 			if !ok {
 				// Channel closed, client disconnected
 				return
 			}
 
 			// Send message as SSE event with ID
-			eventID := t.getNextEventID()
-			event := fmt.Sprintf("id: %s\nevent: message\ndata: %s\n\n", eventID, string(msg))
-			if _, err := fmt.Fprint(w, event); err != nil {
-				t.GetLogger().Debug("Failed to send SSE message", "error", err, "event_id", eventID)
+			if err := t.writeEvent(w, ev); err != nil {
 				return
 			}
 
-			// Flush to ensure the message is sent immediately
-			if flusher, ok := w.(http.Flusher); ok {
-				flusher.Flush()
+		case <-keepAlive:
+			if err := t.writeKeepAlive(w); err != nil {
+				return
 			}
 
 		case <-r.Context().Done():
@@ -701,12 +854,28 @@ func (t *Transport) handleLegacySSEConnection(w http.ResponseWriter, r *http.Req
 	}
 }
 
+// writeEvent writes a message as an SSE event and flushes it to the client
+func (t *Transport) writeEvent(w http.ResponseWriter, ev sseEvent) error {
+	event := fmt.Sprintf("id: %d\nevent: message\ndata: %s\n\n", ev.id, string(ev.data))
+	if _, err := fmt.Fprint(w, event); err != nil {
+		t.GetLogger().Debug("Failed to send SSE message", "error", err, "event_id", ev.id)
+		return err
+	}
+
+	// Flush to ensure the message is sent immediately
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	t.GetLogger().Debug("Sent SSE message", "event_id", ev.id)
+	return nil
+}
+
 // handleClientMessage handles POST requests for client message submission
 func (t *Transport) handleClientMessage(w http.ResponseWriter, r *http.Request) {
 	// Validate content type
-	contentType := r.Header.Get("Content-Type")
-	if !strings.Contains(strings.ToLower(contentType), "application/json") {
-		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+	if !transport.IsJSONContentType(r.Header.Get("Content-Type")) {
+		transport.WriteJSONRPCError(w, http.StatusUnsupportedMediaType, transport.JSONRPCInvalidRequest,
+			"Content-Type must be application/json")
 		return
 	}
 
@@ -722,7 +891,7 @@ func (t *Transport) handleClientMessage(w http.ResponseWriter, r *http.Request)
 			session, exists := t.sessions[sessionID]
 			if !exists {
 				t.sessionsMu.Unlock()
-				http.Error(w, "Session not found", http.StatusNotFound)
+				transport.WriteJSONRPCError(w, http.StatusNotFound, transport.JSONRPCSessionNotFound, "Session not found")
 				return
 			}
 			session.LastSeen = time.Now()
@@ -733,7 +902,7 @@ func (t *Transport) handleClientMessage(w http.ResponseWriter, r *http.Request)
 	// Read message
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		transport.WriteJSONRPCError(w, http.StatusBadRequest, transport.JSONRPCInvalidRequest, "Failed to read request body")
 		return
 	}
 	defer r.Body.Close()
@@ -741,16 +910,17 @@ func (t *Transport) handleClientMessage(w http.ResponseWriter, r *http.Request)
 	// Validate JSON format before processing
 	var jsonCheck interface{}
 	if err := json.Unmarshal(body, &jsonCheck); err != nil {
-		http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+		transport.WriteJSONRPCError(w, http.StatusBadRequest, transport.JSONRPCParseError, "Parse error: request body is not valid JSON")
 		return
 	}
 
 	// Check if this is a notification (no "id" field) - should return 202 Accepted
 	if t.isNotificationRequest(body) {
 		// For notifications, process and return appropriate status based on protocol version
-		_, err := t.HandleMessage(body)
+		_, _, err := t.handleClientBody(r, body)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Error processing notification: %v", err), http.StatusInternalServerError)
+			transport.WriteJSONRPCError(w, http.StatusInternalServerError, transport.JSONRPCInternalError,
+				fmt.Sprintf("Error processing notification: %v", err))
 			return
 		}
 
@@ -770,12 +940,21 @@ func (t *Transport) handleClientMessage(w http.ResponseWriter, r *http.Request)
 	// not go through the SSE broadcasting system which is for server-initiated messages
 
 	// Process message directly and synchronously for POST requests
-	response, err := t.HandleMessage(body)
+	response, onStream, err := t.handleClientBody(r, body)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error processing message: %v", err), http.StatusInternalServerError)
+		transport.WriteJSONRPCError(w, http.StatusInternalServerError, transport.JSONRPCInternalError,
+			fmt.Sprintf("Error processing message: %v", err))
 		return
 	}
 
+	// The server keeps the version negotiated on a stream with the stream's session;
+	// the transport adopts it too, since it shapes how the transport responds
+	if onStream {
+		if version := negotiatedVersion(body, response); version != "" {
+			t.SetProtocolVersion(version)
+		}
+	}
+
 	// Handle session creation for initialize responses
 	if t.enableSessions && (t.GetProtocolVersion() == "2025-03-26" || t.GetProtocolVersion() == "draft") {
 		// Check if this is an initialize response by looking at the request
@@ -819,6 +998,43 @@ func (t *Transport) handleClientMessage(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// handleClientBody passes a POSTed message to the server. Messages naming a
+// connected stream in the Mcp-Stream-Id header are handled as messages of that
+// stream's session, which onStream reports.
+func (t *Transport) handleClientBody(r *http.Request, body []byte) (response []byte, onStream bool, err error) {
+	if streamID := r.Header.Get(StreamIDHeader); streamID != "" {
+		t.clientsMu.Lock()
+		_, connected := t.clients[streamID]
+		t.clientsMu.Unlock()
+		if connected {
+			response, err = t.HandleSessionMessage(streamID, body)
+			return response, true, err
+		}
+	}
+	response, err = t.HandleMessage(body)
+	return response, false, err
+}
+
+// negotiatedVersion returns the protocol version of a successful initialize
+// response, or "" if the request was not an initialize request
+func negotiatedVersion(request, response []byte) string {
+	var req struct {
+		Method string `json:"method"`
+	}
+	if json.Unmarshal(request, &req) != nil || req.Method != "initialize" {
+		return ""
+	}
+	var resp struct {
+		Result struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		} `json:"result"`
+	}
+	if json.Unmarshal(response, &resp) != nil {
+		return ""
+	}
+	return resp.Result.ProtocolVersion
+}
+
 // startClientConnection establishes and maintains the SSE connection
 func (t *Transport) startClientConnection() {
 	for {
@@ -835,9 +1051,9 @@ func (t *Transport) startClientConnection() {
 					// Error channel full, discard
 				}
 
-				// Wait before reconnecting
+				// Wait before reconnecting, as long as the server asked if it sent a retry hint
 				select {
-				case <-time.After(5 * time.Second):
+				case <-time.After(time.Duration(t.reconnectDelay.Load())):
 					// Try again
 				case <-t.doneCh:
 					return
@@ -886,6 +1102,11 @@ func (t *Transport) connectToSSE() error {
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
 
+	// Resume after the last event received on a previous connection
+	if lastEventID := t.lastEventID.Load(); lastEventID != nil {
+		req.Header.Set("Last-Event-ID", *lastEventID)
+	}
+
 	// Context that can be canceled when Stop is called
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -903,6 +1124,10 @@ func (t *Transport) connectToSSE() error {
 
 	req = req.WithContext(ctx)
 
+	// Drop the stream if it goes silent, so the loop in startClientConnection reconnects
+	heartbeat := t.watchHeartbeat(cancel)
+	defer heartbeat.stop()
+
 	if debugHandler := t.GetDebugHandler(); debugHandler != nil {
 		debugHandler("Sending MCP connection request...")
 	}
@@ -942,7 +1167,9 @@ func (t *Transport) connectToSSE() error {
 
 	t.GetLogger().Debug("MCP SSE connection established, parsing events")
 
-	// Store the MCP URL for POST requests
+	// Store the stream ID and the MCP URL for POST requests
+	streamID := resp.Header.Get(StreamIDHeader)
+	t.streamID.Store(&streamID)
 	t.mcpURL.Store(&mcpURL)
 	t.connected.Store(true)
 	t.GetLogger().Debug("MCP endpoint set", "endpoint", mcpURL)
@@ -951,6 +1178,7 @@ func (t *Transport) connectToSSE() error {
 	reader := bufio.NewReader(resp.Body)
 	var buf bytes.Buffer
 	var eventType string
+	var eventID string
 
 	for {
 		line, err := reader.ReadBytes('\n')
@@ -960,8 +1188,9 @@ func (t *Transport) connectToSSE() error {
 				break
 			}
 			t.GetLogger().Debug("Error reading SSE stream", "error", err)
-			return err
+			return heartbeat.streamError(err)
 		}
+		heartbeat.beat()
 
 		line = bytes.TrimSpace(line)
 		t.GetLogger().Debug("SSE line received", "line", string(line))
@@ -978,6 +1207,18 @@ func (t *Transport) connectToSSE() error {
 			continue
 		}
 
+		// Handle the reconnect delay hint
+		if bytes.HasPrefix(line, []byte("retry:")) {
+			t.applyRetryHint(bytes.TrimSpace(bytes.TrimPrefix(line, []byte("retry:"))))
+			continue
+		}
+
+		// Handle event ID
+		if bytes.HasPrefix(line, []byte("id:")) {
+			eventID = string(bytes.TrimSpace(bytes.TrimPrefix(line, []byte("id:"))))
+			continue
+		}
+
 		// Handle data lines
 		if bytes.HasPrefix(line, []byte("data:")) {
 			// Extract the data
@@ -990,6 +1231,13 @@ func (t *Transport) connectToSSE() error {
 			msg := buf.Bytes()
 			t.GetLogger().Debug("Complete event received", "data", string(msg), "type", eventType)
 
+			// Remember the event ID so a reconnect can resume after it
+			if eventID != "" {
+				id := eventID
+				t.lastEventID.Store(&id)
+				eventID = ""
+			}
+
 			// Handle different event types
 			if eventType == "endpoint" {
 				// Legacy 2024-11-05 behavior: server sends endpoint URL
@@ -1078,6 +1326,10 @@ func (t *Transport) connectToLegacySSE() error {
 
 	req = req.WithContext(ctx)
 
+	// Drop the stream if it goes silent, so the loop in startClientConnection reconnects
+	heartbeat := t.watchHeartbeat(cancel)
+	defer heartbeat.stop()
+
 	if debugHandler := t.GetDebugHandler(); debugHandler != nil {
 		debugHandler("Sending legacy SSE connection request...")
 	}
@@ -1118,8 +1370,9 @@ func (t *Transport) connectToLegacySSE() error {
 				break
 			}
 			t.GetLogger().Debug("Error reading legacy SSE stream", "error", err)
-			return err
+			return heartbeat.streamError(err)
 		}
+		heartbeat.beat()
 
 		line = bytes.TrimSpace(line)
 		t.GetLogger().Debug("Legacy SSE line received", "line", string(line))
@@ -1136,6 +1389,12 @@ func (t *Transport) connectToLegacySSE() error {
 			continue
 		}
 
+		// Handle the reconnect delay hint
+		if bytes.HasPrefix(line, []byte("retry:")) {
+			t.applyRetryHint(bytes.TrimSpace(bytes.TrimPrefix(line, []byte("retry:"))))
+			continue
+		}
+
 		// Handle data lines
 		if bytes.HasPrefix(line, []byte("data:")) {
 			// Extract the data
@@ -1216,3 +1475,13 @@ func (t *Transport) GetStoredMCPURL() string {
 	}
 	return *mcpURL
 }
+
+// StreamID returns the ID the server assigned to the current SSE stream, which is
+// sent in the Mcp-Stream-Id header of POST requests (client mode only)
+func (t *Transport) StreamID() string {
+	streamID := t.streamID.Load()
+	if streamID == nil {
+		return ""
+	}
+	return *streamID
+}
@@ -1,14 +1,19 @@
 package sse
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/localrivet/gomcp/transport"
 )
 
 func getRandomPort() string {
@@ -200,3 +205,367 @@ func TestServerReceiveError(t *testing.T) {
 		t.Error("Expected Receive to fail in server mode, but it succeeded")
 	}
 }
+
+// readEvent reads one SSE event from the stream and returns its ID and data
+func readEvent(t *testing.T, reader *bufio.Reader) (string, string) {
+	t.Helper()
+
+	var id, data string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read SSE stream: %v", err)
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "id:"):
+			id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "data:"):
+			data = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		case line == "" && data != "":
+			return id, data
+		}
+	}
+}
+
+// openStream opens an SSE stream, optionally resuming after lastEventID
+func openStream(t *testing.T, url, lastEventID string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	return resp, bufio.NewReader(resp.Body)
+}
+
+// waitForClients waits until the transport has the given number of streams registered
+func waitForClients(t *testing.T, tr *Transport, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		tr.clientsMu.Lock()
+		count := len(tr.clients)
+		tr.clientsMu.Unlock()
+		if count == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d SSE clients", n)
+}
+
+func TestLastEventIDReplay(t *testing.T) {
+	tr := NewTransport(":0")
+	server := httptest.NewServer(http.HandlerFunc(tr.handleMCPRequest))
+	defer server.Close()
+
+	resp, reader := openStream(t, server.URL, "")
+	waitForClients(t, tr, 1)
+
+	tr.Send([]byte(`{"seq":1}`))
+	firstID, data := readEvent(t, reader)
+	if data != `{"seq":1}` {
+		t.Fatalf("Expected first message, got %s", data)
+	}
+
+	// Messages sent while the client is away are buffered
+	resp.Body.Close()
+	waitForClients(t, tr, 0)
+	tr.Send([]byte(`{"seq":2}`))
+	tr.Send([]byte(`{"seq":3}`))
+
+	resp, reader = openStream(t, server.URL, firstID)
+	defer resp.Body.Close()
+
+	for _, expected := range []string{`{"seq":2}`, `{"seq":3}`} {
+		if _, data := readEvent(t, reader); data != expected {
+			t.Fatalf("Expected replayed message %s, got %s", expected, data)
+		}
+	}
+
+	// Live delivery continues after the replay
+	waitForClients(t, tr, 1)
+	tr.Send([]byte(`{"seq":4}`))
+	if _, data := readEvent(t, reader); data != `{"seq":4}` {
+		t.Fatalf("Expected live message after replay, got %s", data)
+	}
+}
+
+func TestReplayBufferLimits(t *testing.T) {
+	buffer := &replayBuffer{}
+	now := time.Now()
+	for i := int64(1); i <= 5; i++ {
+		buffer.append(sseEvent{id: i, at: now}, 3)
+	}
+
+	missed := buffer.since(0, 0)
+	if len(missed) != 3 || missed[0].id != 3 {
+		t.Fatalf("Expected the 3 most recent events, got %+v", missed)
+	}
+
+	buffer.append(sseEvent{id: 6, at: now.Add(-time.Hour)}, 3)
+	if missed := buffer.since(4, time.Minute); len(missed) != 1 || missed[0].id != 5 {
+		t.Errorf("Expected expired events to be skipped, got %+v", missed)
+	}
+
+	// Resumption can be disabled
+	tr := NewTransport(":0")
+	SSE.WithReplayBufferSize(0)(tr)
+	tr.trackReplay("")
+	tr.Send([]byte("lost"))
+	if events := tr.missedEvents("", "0"); len(events) != 0 {
+		t.Errorf("Expected no buffered events when replay is disabled, got %d", len(events))
+	}
+}
+
+func TestTLSOptions(t *testing.T) {
+	server := NewTransport(":0")
+	SSE.WithMutualTLS("ca.pem")(server)
+	if err := server.Start(); err == nil || !strings.Contains(err.Error(), "server certificate") {
+		t.Errorf("Expected error for mutual TLS without certificate, got %v", err)
+	}
+
+	server = NewTransport(":0")
+	SSE.WithTLS("missing.pem", "missing-key.pem")(server)
+	if err := server.Start(); err == nil || !strings.Contains(err.Error(), "failed to configure TLS") {
+		t.Errorf("Expected error for missing certificate files, got %v", err)
+	}
+
+	client := NewTransport("https://localhost:9999")
+	SSE.WithClientCertificate("missing.pem", "missing-key.pem")(client)
+	if err := client.Start(); err == nil || !strings.Contains(err.Error(), "failed to configure TLS") {
+		t.Errorf("Expected error for missing client certificate, got %v", err)
+	}
+}
+
+func TestKeepAliveAndRetryHint(t *testing.T) {
+	tr := NewTransport(":0")
+	SSE.WithKeepAlive(20 * time.Millisecond)(tr)
+	SSE.WithRetryHint(1500 * time.Millisecond)(tr)
+	server := httptest.NewServer(http.HandlerFunc(tr.handleMCPRequest))
+	defer server.Close()
+
+	resp, reader := openStream(t, server.URL, "")
+	defer resp.Body.Close()
+
+	line, err := reader.ReadString('\n')
+	if err != nil || strings.TrimSpace(line) != "retry: 1500" {
+		t.Fatalf("Expected the stream to open with a retry hint, got %q (%v)", line, err)
+	}
+
+	// An idle stream receives keepalive comments
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read SSE stream: %v", err)
+		}
+		if strings.TrimSpace(line) == ": keepalive" {
+			return
+		}
+	}
+	t.Fatal("Expected a keepalive comment on the idle stream")
+}
+
+func TestHeartbeatTimeoutReconnects(t *testing.T) {
+	var mu sync.Mutex
+	connections := 0
+
+	// A stream that goes silent after the retry hint, as behind a proxy that
+	// dropped it without closing the connection
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		connections++
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "retry: 10\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	tr := NewTransport(server.URL)
+	SSE.WithHeartbeatTimeout(100 * time.Millisecond)(tr)
+	if err := tr.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer tr.Stop()
+
+	select {
+	case err := <-tr.errCh:
+		if err != ErrHeartbeatTimeout {
+			t.Fatalf("Expected ErrHeartbeatTimeout, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the silent stream to be dropped")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		reconnected := connections >= 2
+		mu.Unlock()
+		if reconnected {
+			if delay := time.Duration(tr.reconnectDelay.Load()); delay != 10*time.Millisecond {
+				t.Errorf("Expected the retry hint to set the reconnect delay, got %v", delay)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Expected the client to reconnect after the missed heartbeat")
+}
+
+func TestStreamsAreSessions(t *testing.T) {
+	tr := NewTransport(":0")
+	var mu sync.Mutex
+	var handledOn []string
+	tr.SetMessageHandler(func(message []byte) ([]byte, error) {
+		mu.Lock()
+		handledOn = append(handledOn, "")
+		mu.Unlock()
+		return []byte(`{"jsonrpc":"2.0","id":1,"result":{}}`), nil
+	})
+	tr.SetSessionMessageHandler(func(sessionID string, message []byte) ([]byte, error) {
+		mu.Lock()
+		handledOn = append(handledOn, sessionID)
+		mu.Unlock()
+		return []byte(`{"jsonrpc":"2.0","id":1,"result":{}}`), nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(tr.handleMCPRequest))
+	defer server.Close()
+
+	respA, readerA := openStream(t, server.URL, "")
+	defer respA.Body.Close()
+	respB, readerB := openStream(t, server.URL, "")
+	defer respB.Body.Close()
+	waitForClients(t, tr, 2)
+
+	streamA, streamB := respA.Header.Get(StreamIDHeader), respB.Header.Get(StreamIDHeader)
+	if streamA == "" || streamA == streamB {
+		t.Fatalf("Expected distinct stream IDs, got %q and %q", streamA, streamB)
+	}
+
+	// POSTs naming a connected stream are handled as messages of its session
+	for _, streamID := range []string{streamA, "", "unknown"} {
+		req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		if streamID != "" {
+			req.Header.Set(StreamIDHeader, streamID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to post: %v", err)
+		}
+		resp.Body.Close()
+	}
+	mu.Lock()
+	if fmt.Sprint(handledOn) != fmt.Sprint([]string{streamA, "", ""}) {
+		t.Errorf("Expected the first POST handled on stream %s only, got %q", streamA, handledOn)
+	}
+	mu.Unlock()
+
+	// Messages for a session reach its stream only
+	if err := tr.SendToSession(streamA, []byte(`{"to":"a"}`)); err != nil {
+		t.Fatalf("SendToSession failed: %v", err)
+	}
+	if _, data := readEvent(t, readerA); data != `{"to":"a"}` {
+		t.Fatalf("Expected the message on stream A, got %s", data)
+	}
+	tr.Send([]byte(`{"to":"all"}`))
+	if _, data := readEvent(t, readerB); data != `{"to":"all"}` {
+		t.Errorf("Expected stream B to skip the message for A, got %s", data)
+	}
+	if _, data := readEvent(t, readerA); data != `{"to":"all"}` {
+		t.Errorf("Expected the broadcast on stream A, got %s", data)
+	}
+
+	// Messages for a stream that is gone go to every stream
+	if err := tr.SendToSession("unknown", []byte(`{"to":"unknown"}`)); err != nil {
+		t.Fatalf("SendToSession failed: %v", err)
+	}
+	for _, reader := range []*bufio.Reader{readerA, readerB} {
+		if _, data := readEvent(t, reader); data != `{"to":"unknown"}` {
+			t.Errorf("Expected the message on every stream, got %s", data)
+		}
+	}
+}
+
+func TestLegacyEndpoints(t *testing.T) {
+	var mu sync.Mutex
+	var clients []transport.LegacyClient
+	newServer := func(options ...Option) *httptest.Server {
+		tr := NewTransport(":0")
+		for _, option := range options {
+			option(tr)
+		}
+		tr.SetLegacyClientHandler(func(client transport.LegacyClient) {
+			mu.Lock()
+			defer mu.Unlock()
+			clients = append(clients, client)
+		})
+		return httptest.NewServer(http.HandlerFunc(tr.handleLegacyRequest))
+	}
+
+	// Served legacy streams are marked as deprecated and point to their successor
+	server := newServer()
+	req, _ := http.NewRequest(http.MethodGet, server.URL+DefaultEventsPath, nil)
+	req.Header.Set("User-Agent", "old-client/0.1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open legacy stream: %v", err)
+	}
+	line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+	resp.Body.Close()
+	server.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Deprecation") != "true" {
+		t.Errorf("Expected a deprecated stream, got %d %v", resp.StatusCode, resp.Header)
+	}
+	if !strings.Contains(resp.Header.Get("Link"), DefaultMCPEndpoint+`>; rel="successor-version"`) {
+		t.Errorf("Expected a link to the MCP endpoint, got %q", resp.Header.Get("Link"))
+	}
+	if !strings.HasPrefix(line, "id: ") {
+		t.Errorf("Expected the endpoint event, got %q", line)
+	}
+
+	// Disabled endpoints answer with a migration hint
+	server = newServer(SSE.WithoutLegacyEndpoints())
+	defer server.Close()
+	resp, err = http.Get(server.URL + DefaultEventsPath)
+	if err != nil {
+		t.Fatalf("Failed to request legacy endpoint: %v", err)
+	}
+	defer resp.Body.Close()
+	var hint MigrationHint
+	if err := json.NewDecoder(resp.Body).Decode(&hint); err != nil {
+		t.Fatalf("Failed to decode migration hint: %v", err)
+	}
+	if resp.StatusCode != http.StatusGone || hint.Endpoint != server.URL+DefaultMCPEndpoint || hint.ProtocolVersion != "2025-03-26" {
+		t.Errorf("Expected 410 with a migration hint, got %d %+v", resp.StatusCode, hint)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(clients) != 2 {
+		t.Fatalf("Expected both legacy clients to be reported, got %+v", clients)
+	}
+	if clients[0].Rejected || clients[0].UserAgent != "old-client/0.1" || clients[0].RemoteIP != "127.0.0.1" || clients[0].Path != DefaultEventsPath {
+		t.Errorf("Unexpected served client %+v", clients[0])
+	}
+	if !clients[1].Rejected {
+		t.Errorf("Expected the second client to be reported as rejected, got %+v", clients[1])
+	}
+}
@@ -0,0 +1,87 @@
+package sse
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	"github.com/localrivet/gomcp/transport"
+)
+
+// WithTLS returns an option that makes a server serve HTTPS with the given PEM
+// certificate and key files
+func (Options) WithTLS(certFile, keyFile string) Option {
+	return func(t *Transport) {
+		t.tlsCertFile = certFile
+		t.tlsKeyFile = keyFile
+	}
+}
+
+// WithMutualTLS returns an option that makes a TLS server require client
+// certificates signed by one of the CAs in caFile. It requires WithTLS.
+func (Options) WithMutualTLS(caFile string) Option {
+	return func(t *Transport) {
+		t.tlsClientCAFile = caFile
+	}
+}
+
+// WithRootCAs returns an option that makes a client verify the server's
+// certificate against the CAs in caFile instead of the system pool
+func (Options) WithRootCAs(caFile string) Option {
+	return func(t *Transport) {
+		t.tlsRootCAFile = caFile
+	}
+}
+
+// WithClientCertificate returns an option that makes a client present the given
+// certificate to servers requiring mutual TLS
+func (Options) WithClientCertificate(certFile, keyFile string) Option {
+	return func(t *Transport) {
+		t.tlsClientCertFile = certFile
+		t.tlsClientKeyFile = keyFile
+	}
+}
+
+// WithTLSConfig returns an option that sets the complete TLS configuration.
+// Servers serve HTTPS with it and clients use it for their connections.
+// It takes precedence over the file-based TLS options.
+func (Options) WithTLSConfig(config *tls.Config) Option {
+	return func(t *Transport) {
+		t.tlsConfig = config
+	}
+}
+
+// serverTLSConfig returns the server's TLS configuration, or nil to serve plain HTTP
+func (t *Transport) serverTLSConfig() (*tls.Config, error) {
+	if t.tlsConfig != nil {
+		return t.tlsConfig, nil
+	}
+	if t.tlsCertFile == "" && t.tlsKeyFile == "" {
+		if t.tlsClientCAFile != "" {
+			return nil, errors.New("mutual TLS requires a server certificate, use WithTLS")
+		}
+		return nil, nil
+	}
+
+	config, err := transport.LoadServerTLSConfig(t.tlsCertFile, t.tlsKeyFile, t.tlsClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	return config, nil
+}
+
+// clientTLSConfig returns the client's TLS configuration, or nil to use the defaults
+func (t *Transport) clientTLSConfig() (*tls.Config, error) {
+	if t.tlsConfig != nil {
+		return t.tlsConfig, nil
+	}
+	if t.tlsRootCAFile == "" && t.tlsClientCertFile == "" && t.tlsClientKeyFile == "" {
+		return nil, nil
+	}
+
+	config, err := transport.LoadClientTLSConfig(t.tlsRootCAFile, t.tlsClientCertFile, t.tlsClientKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	return config, nil
+}
@@ -6,12 +6,12 @@ package stdio
 
 import (
 	"bufio"
-	"encoding/json"
+	"bytes"
 	"errors"
 	"io"
 	"log/slog"
 	"os"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/localrivet/gomcp/transport"
@@ -21,26 +21,14 @@ import (
 // isValidJSONRPC checks if a message appears to be a valid JSON-RPC message.
 // This provides anti-fragile behavior by filtering out log messages and other noise.
 func isValidJSONRPC(data []byte) bool {
-	// Quick check: must be valid JSON
-	var raw map[string]interface{}
-	if err := json.Unmarshal(data, &raw); err != nil {
-		return false
-	}
-
-	// Must have jsonrpc field with value "2.0"
-	jsonrpc, ok := raw["jsonrpc"]
-	if !ok {
-		return false
-	}
-	if jsonrpcStr, ok := jsonrpc.(string); !ok || jsonrpcStr != "2.0" {
+	// Only the envelope is decoded; params and results are skipped
+	envelope, err := transport.ParseEnvelope(data)
+	if err != nil || envelope.JSONRPC != "2.0" {
 		return false
 	}
 
 	// Must be one of: request (has method + id), response (has id + result/error), or notification (has method, no id)
-	_, hasMethod := raw["method"]
-	_, hasID := raw["id"]
-	_, hasResult := raw["result"]
-	_, hasError := raw["error"]
+	hasMethod, hasID := envelope.HasMethod, envelope.HasID()
 
 	// Request: method + id
 	if hasMethod && hasID {
@@ -48,7 +36,7 @@ func isValidJSONRPC(data []byte) bool {
 	}
 
 	// Response: id + (result or error)
-	if hasID && (hasResult || hasError) {
+	if hasID && (envelope.HasResult || envelope.HasError) {
 		return true
 	}
 
@@ -63,13 +51,16 @@ func isValidJSONRPC(data []byte) bool {
 // Transport implements the transport.Transport interface for Standard I/O.
 type Transport struct {
 	transport.BaseTransport
+	transport.Dispatcher
 	reader         *bufio.Reader
 	writer         *bufio.Writer
+	writeMu        sync.Mutex // keeps concurrent messages from interleaving
 	done           chan struct{}
 	readEOF        bool
 	newline        bool // Whether to append a newline to each message
 	processMonitor *util.ProcessMonitor
 	logger         *slog.Logger
+	requests       transport.RequestQueue
 }
 
 // NewTransport creates a new Standard I/O transport.
@@ -164,6 +155,9 @@ func (t *Transport) Stop() error {
 
 // Send sends a message over stdout.
 func (t *Transport) Send(message []byte) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
 	// Write the message to stdout
 	_, err := t.writer.Write(message)
 	if err != nil {
@@ -198,9 +192,11 @@ func (t *Transport) readLoop() {
 		case <-t.done:
 			return
 		default:
-			// Read a line from stdin
-			line, err := t.reader.ReadString('\n')
+			// Read a line from stdin into a pooled buffer
+			buf := transport.GetBuffer()
+			err := t.readLine(buf)
 			if err != nil {
+				transport.PutBuffer(buf)
 				if err == io.EOF {
 					// EOF doesn't mean we should exit - the parent process might send more input later
 					// Just sleep a bit to avoid tight loop
@@ -233,44 +229,76 @@ func (t *Transport) readLoop() {
 			t.readEOF = false
 
 			// Trim newline character(s)
-			line = strings.TrimRight(line, "\r\n")
+			line := bytes.TrimRight(buf.Bytes(), "\r\n")
 
 			// Skip empty lines
-			if line == "" {
+			if len(line) == 0 {
+				transport.PutBuffer(buf)
 				continue
 			}
 
 			// Anti-fragile filtering: only process valid JSON-RPC messages
-			if !isValidJSONRPC([]byte(line)) {
+			if !isValidJSONRPC(line) {
 				// Log filtered message if debug enabled
 				if debugHandler := t.GetDebugHandler(); debugHandler != nil {
-					if len(line) > 100 {
-						debugHandler("stdio transport filtered non-JSON-RPC: " + line[:100] + "...")
-					} else {
-						debugHandler("stdio transport filtered non-JSON-RPC: " + line)
-					}
+					debugHandler("stdio transport filtered non-JSON-RPC: " + preview(line))
 				}
+				transport.PutBuffer(buf)
 				continue
 			}
 
 			// Log received message if debug enabled
 			if debugHandler := t.GetDebugHandler(); debugHandler != nil {
-				if len(line) > 100 {
-					debugHandler("stdio transport received: " + line[:100] + "...")
-				} else {
-					debugHandler("stdio transport received: " + line)
-				}
+				debugHandler("stdio transport received: " + preview(line))
 			}
 
-			// Process the message with the handler
-			if response, err := t.HandleMessage([]byte(line)); err == nil && response != nil {
-				if err := t.Send(response); err != nil {
-					// Log error but continue processing
-					if debugHandler := t.GetDebugHandler(); debugHandler != nil {
-						debugHandler("stdio transport: failed to send response: " + err.Error())
-					}
-				}
+			// The handler may keep the message, so it gets a copy of its own and the
+			// buffer goes back to the pool
+			message := make([]byte, len(line))
+			copy(message, line)
+			transport.PutBuffer(buf)
+
+			// Process the message with the handler, reading on while requests run
+			t.Dispatch(&t.requests, message, func() { t.dispatch(message) })
+		}
+	}
+}
+
+// dispatch passes a message to the handler and sends its response, if any
+func (t *Transport) dispatch(message []byte) {
+	if response, err := t.HandleMessage(message); err == nil && response != nil {
+		if err := t.Send(response); err != nil {
+			// Log error but continue processing
+			if debugHandler := t.GetDebugHandler(); debugHandler != nil {
+				debugHandler("stdio transport: failed to send response: " + err.Error())
 			}
 		}
 	}
 }
+
+// readLine reads the next line, including its newline, into buf. It returns
+// io.EOF only if nothing was read.
+func (t *Transport) readLine(buf *bytes.Buffer) error {
+	for {
+		chunk, err := t.reader.ReadSlice('\n')
+		buf.Write(chunk)
+		switch {
+		case err == bufio.ErrBufferFull:
+			// The line is longer than the reader's buffer; keep reading
+			continue
+		case err == io.EOF && buf.Len() > 0:
+			// A final line without a newline
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+// preview returns the start of a message for debug output
+func preview(message []byte) string {
+	if len(message) > 100 {
+		return string(message[:100]) + "..."
+	}
+	return string(message)
+}
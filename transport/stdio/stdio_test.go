@@ -436,3 +436,144 @@ func TestTransportInterface(t *testing.T) {
 	// Ensure our Transport implements the transport.Transport interface
 	var _ transport.Transport = &Transport{}
 }
+
+func TestReadLineLongerThanBuffer(t *testing.T) {
+	// A message larger than the reader's 4 KB buffer arrives in several chunks
+	message := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"data":"` + strings.Repeat("x", 10000) + `"}}`
+	tr := NewTransportWithIO(strings.NewReader(message+"\n"+`{"jsonrpc":"2.0","method":"done"}`), io.Discard)
+	tr.DisableProcessMonitoring()
+
+	buf := transport.GetBuffer()
+	defer transport.PutBuffer(buf)
+	if err := tr.readLine(buf); err != nil {
+		t.Fatalf("readLine failed: %v", err)
+	}
+	if got := strings.TrimSuffix(buf.String(), "\n"); got != message {
+		t.Errorf("Expected the whole message, got %d bytes", len(got))
+	}
+
+	// A final line without a newline is still read
+	buf.Reset()
+	if err := tr.readLine(buf); err != nil || buf.String() != `{"jsonrpc":"2.0","method":"done"}` {
+		t.Errorf("Expected the final line, got %q, %v", buf.String(), err)
+	}
+	buf.Reset()
+	if err := tr.readLine(buf); err != io.EOF {
+		t.Errorf("Expected EOF, got %v", err)
+	}
+}
+
+func BenchmarkReadLoop(b *testing.B) {
+	message := []byte(`{"jsonrpc":"2.0","id":42,"method":"tools/call","params":{"name":"search","arguments":{"query":"quarterly revenue","limit":25}}}` + "\n")
+	reader, writer := io.Pipe()
+	tr := NewTransportWithIO(reader, io.Discard)
+	tr.DisableProcessMonitoring()
+
+	received := make(chan struct{}, 1024)
+	tr.SetMessageHandler(func(message []byte) ([]byte, error) {
+		received <- struct{}{}
+		return nil, nil
+	})
+	tr.Start()
+	defer tr.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	go func() {
+		for i := 0; i < b.N; i++ {
+			writer.Write(message)
+		}
+	}()
+	for i := 0; i < b.N; i++ {
+		<-received
+	}
+	b.StopTimer()
+	writer.Close()
+}
+
+func TestConcurrentDispatch(t *testing.T) {
+	in, feed := io.Pipe()
+	defer feed.Close()
+	tr := NewTransportWithIO(in, io.Discard)
+	tr.DisableProcessMonitoring()
+	tr.SetConcurrentDispatch(true)
+
+	// The first request only completes once the second one has been handled,
+	// which sequential dispatch would never get to
+	release := make(chan struct{})
+	handled := make(chan string, 2)
+	tr.SetMessageHandler(func(message []byte) ([]byte, error) {
+		if strings.Contains(string(message), `"id":1`) {
+			select {
+			case <-release:
+			case <-time.After(2 * time.Second):
+			}
+		} else {
+			close(release)
+		}
+		handled <- string(message)
+		return nil, nil
+	})
+
+	if err := tr.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer tr.Stop()
+	go feed.Write([]byte(`{"jsonrpc":"2.0","method":"slow","id":1}` + "\n" + `{"jsonrpc":"2.0","method":"fast","id":2}` + "\n"))
+
+	for _, want := range []string{`"id":2`, `"id":1`} {
+		select {
+		case message := <-handled:
+			if !strings.Contains(message, want) {
+				t.Errorf("Expected message with %s to complete next, got %s", want, message)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for the message with %s", want)
+		}
+	}
+}
+
+func TestSerialDispatchReadsWhileRequestsRun(t *testing.T) {
+	in, feed := io.Pipe()
+	defer feed.Close()
+	tr := NewTransportWithIO(in, io.Discard)
+	tr.DisableProcessMonitoring()
+
+	// The first request waits for the response that follows it, as a tool asking
+	// the client for sampling or elicitation does; the second request must still
+	// run after the first
+	answered := make(chan struct{})
+	handled := make(chan string, 3)
+	tr.SetMessageHandler(func(message []byte) ([]byte, error) {
+		switch {
+		case strings.Contains(string(message), `"method":"slow"`):
+			select {
+			case <-answered:
+			case <-time.After(2 * time.Second):
+			}
+		case strings.Contains(string(message), `"result"`):
+			close(answered)
+		}
+		handled <- string(message)
+		return nil, nil
+	})
+
+	if err := tr.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer tr.Stop()
+	go feed.Write([]byte(`{"jsonrpc":"2.0","method":"slow","id":1}` + "\n" +
+		`{"jsonrpc":"2.0","method":"next","id":2}` + "\n" +
+		`{"jsonrpc":"2.0","id":"server-1","result":{}}` + "\n"))
+
+	for _, want := range []string{`"id":"server-1"`, `"id":1`, `"id":2`} {
+		select {
+		case message := <-handled:
+			if !strings.Contains(message, want) {
+				t.Errorf("Expected message with %s to complete next, got %s", want, message)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for the message with %s", want)
+		}
+	}
+}
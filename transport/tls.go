@@ -0,0 +1,100 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// LoadServerTLSConfig builds a server TLS configuration from PEM certificate and key files.
+// If clientCAFile is not empty, mutual TLS is enabled: clients must present a
+// certificate signed by one of the CAs in that file.
+func LoadServerTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("TLS requires both a certificate and a key file")
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate key pair: %w", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if clientCAFile != "" {
+		pool, err := loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
+
+// LoadClientTLSConfig builds a client TLS configuration. If rootCAFile is not
+// empty, server certificates are verified against the CAs in that file instead of
+// the system pool. If certFile and keyFile are set, the client presents that
+// certificate to servers requiring mutual TLS.
+func LoadClientTLSConfig(rootCAFile, certFile, keyFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if rootCAFile != "" {
+		pool, err := loadCertPool(rootCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate key pair: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
+
+// HTTPClientWithTLS returns a copy of client whose connections use the given TLS
+// configuration. The client's other settings, such as its timeout, are kept.
+func HTTPClientWithTLS(client *http.Client, config *tls.Config) *http.Client {
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	base, ok := client.Transport.(*http.Transport)
+	if !ok || base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+
+	roundTripper := base.Clone()
+	roundTripper.TLSClientConfig = config
+
+	withTLS := *client
+	withTLS.Transport = roundTripper
+	return &withTLS
+}
+
+// loadCertPool reads the PEM encoded CA certificates in caFile.
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	caBytes, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caBytes) {
+		return nil, fmt.Errorf("failed to add CA certificate to pool")
+	}
+	return pool, nil
+}
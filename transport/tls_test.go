@@ -0,0 +1,159 @@
+package transport
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testPKI holds the PEM files of a test CA and the certificates it issued
+type testPKI struct {
+	caFile         string
+	serverCertFile string
+	serverKeyFile  string
+	clientCertFile string
+	clientKeyFile  string
+}
+
+// newTestPKI creates a CA with a server certificate for 127.0.0.1 and a client certificate
+func newTestPKI(t *testing.T) testPKI {
+	t.Helper()
+	dir := t.TempDir()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate CA key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("Failed to create CA certificate: %v", err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	writePEM := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	issue := func(name string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("Failed to generate key: %v", err)
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatalf("Failed to create certificate: %v", err)
+		}
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatalf("Failed to marshal key: %v", err)
+		}
+		return writePEM(name+".pem", "CERTIFICATE", der), writePEM(name+"-key.pem", "EC PRIVATE KEY", keyDER)
+	}
+
+	pki := testPKI{caFile: writePEM("ca.pem", "CERTIFICATE", caDER)}
+	pki.serverCertFile, pki.serverKeyFile = issue("server", 2, x509.ExtKeyUsageServerAuth)
+	pki.clientCertFile, pki.clientKeyFile = issue("client", 3, x509.ExtKeyUsageClientAuth)
+	return pki
+}
+
+func TestMutualTLS(t *testing.T) {
+	pki := newTestPKI(t)
+
+	serverConfig, err := LoadServerTLSConfig(pki.serverCertFile, pki.serverKeyFile, pki.caFile)
+	if err != nil {
+		t.Fatalf("LoadServerTLSConfig failed: %v", err)
+	}
+	if serverConfig.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("Expected client certificates to be required, got %v", serverConfig.ClientAuth)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = serverConfig
+	server.StartTLS()
+	defer server.Close()
+
+	// A client with the CA and a certificate is accepted
+	clientConfig, err := LoadClientTLSConfig(pki.caFile, pki.clientCertFile, pki.clientKeyFile)
+	if err != nil {
+		t.Fatalf("LoadClientTLSConfig failed: %v", err)
+	}
+	client := HTTPClientWithTLS(&http.Client{Timeout: 5 * time.Second}, clientConfig)
+	if client.Timeout != 5*time.Second {
+		t.Errorf("Expected the client timeout to be kept, got %v", client.Timeout)
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected mutual TLS request to succeed, got %v", err)
+	}
+	resp.Body.Close()
+
+	// A client trusting the CA but without a certificate is rejected
+	caOnly, err := LoadClientTLSConfig(pki.caFile, "", "")
+	if err != nil {
+		t.Fatalf("LoadClientTLSConfig failed: %v", err)
+	}
+	if resp, err := HTTPClientWithTLS(nil, caOnly).Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Error("Expected request without client certificate to fail")
+	}
+
+	// A client not trusting the CA rejects the server
+	if resp, err := HTTPClientWithTLS(nil, &tls.Config{}).Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Error("Expected request without the custom CA to fail")
+	}
+}
+
+func TestLoadTLSConfigErrors(t *testing.T) {
+	if _, err := LoadServerTLSConfig("", "", ""); err == nil {
+		t.Error("Expected error without certificate files")
+	}
+	if _, err := LoadServerTLSConfig("missing.pem", "missing-key.pem", ""); err == nil {
+		t.Error("Expected error for missing certificate files")
+	}
+	if _, err := LoadClientTLSConfig("missing-ca.pem", "", ""); err == nil {
+		t.Error("Expected error for missing CA file")
+	}
+
+	config, err := LoadClientTLSConfig("", "", "")
+	if err != nil || config.RootCAs != nil || len(config.Certificates) != 0 {
+		t.Errorf("Expected default client config, got %+v, %v", config, err)
+	}
+}
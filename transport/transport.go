@@ -7,11 +7,18 @@ import (
 	"errors"
 	"log/slog"
 	"os"
+	"sync"
 )
 
 // MessageHandler represents a function that handles incoming messages
 type MessageHandler func(message []byte) ([]byte, error)
 
+// SessionMessageHandler represents a function that handles a message received on a client session
+type SessionMessageHandler func(sessionID string, message []byte) ([]byte, error)
+
+// SessionEndHandler represents a function called when a transport ends a client session
+type SessionEndHandler func(sessionID string)
+
 // DebugHandler represents a function that receives debug messages from the transport
 type DebugHandler func(message string)
 
@@ -51,10 +58,65 @@ type Transport interface {
 	GetProtocolVersion() string
 }
 
+// SessionTransport is implemented by transports that serve several client sessions
+// at once and can tell which session a message arrived on, such as HTTP with
+// Mcp-Session-Id. Messages received on a session are passed to the session
+// message handler instead of the message handler, so that the server can keep
+// per-session state such as the negotiated protocol version.
+type SessionTransport interface {
+	// SetSessionMessageHandler sets the handler for messages received on a session
+	SetSessionMessageHandler(handler SessionMessageHandler)
+}
+
+// SessionEndReporter is implemented by session transports that end sessions on
+// their own, when the client terminates them or they expire, so that the server
+// can drop its state for them.
+type SessionEndReporter interface {
+	// SetSessionEndHandler sets the handler called for every session that ends
+	SetSessionEndHandler(handler SessionEndHandler)
+}
+
+// SessionImporter is implemented by transports that track the sessions they hand
+// out and refuse requests on unknown ones, such as HTTP with Mcp-Session-Id. A
+// server that imports a session exported by another server tells the transport to
+// accept requests on it.
+type SessionImporter interface {
+	// ImportSession makes the transport accept requests on the session with the
+	// given ID, the same ID the transport passes to the session message handler
+	ImportSession(sessionID string)
+}
+
+// ConcurrentDispatcher is implemented by transports that read messages from a
+// stream, such as stdio or a WebSocket connection, and can handle requests
+// concurrently instead of one after the other. Responses are then written as
+// they complete, possibly out of order, which JSON-RPC allows since they carry
+// the ID of their request. Notifications and responses are handled as they are
+// read whether or not requests run concurrently; see RequestQueue.
+type ConcurrentDispatcher interface {
+	// SetConcurrentDispatch sets whether requests are handled concurrently. It
+	// may be called while the transport is running.
+	SetConcurrentDispatch(enabled bool)
+}
+
+// SessionSender is implemented by transports that serve several client sessions
+// over one shared channel, such as MQTT topics or NATS subjects, and can address a
+// single session on it. The server sends its own requests, such as sampling and
+// roots/list, to the session they are for instead of broadcasting them with Send.
+type SessionSender interface {
+	// SendToSession sends a message to the client session with the given ID, the
+	// same ID the transport passes to the session message handler
+	SendToSession(sessionID string, message []byte) error
+}
+
 // BaseTransport provides common transport functionality
 type BaseTransport struct {
-	handler         MessageHandler
-	debugHandler    DebugHandler
+	handler        MessageHandler
+	sessionHandler SessionMessageHandler
+	debugHandler   DebugHandler
+
+	// mu guards the logger, which is created on first use, and protocolVersion,
+	// which requests set while streams read it
+	mu              sync.RWMutex
 	logger          *slog.Logger
 	protocolVersion string
 }
@@ -64,6 +126,11 @@ func (t *BaseTransport) SetMessageHandler(handler MessageHandler) {
 	t.handler = handler
 }
 
+// SetSessionMessageHandler sets the handler for messages received on a client session
+func (t *BaseTransport) SetSessionMessageHandler(handler SessionMessageHandler) {
+	t.sessionHandler = handler
+}
+
 // SetDebugHandler sets the debug handler
 func (t *BaseTransport) SetDebugHandler(handler DebugHandler) {
 	t.debugHandler = handler
@@ -76,11 +143,22 @@ func (t *BaseTransport) GetDebugHandler() DebugHandler {
 
 // SetLogger sets the structured logger
 func (t *BaseTransport) SetLogger(logger *slog.Logger) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.logger = logger
 }
 
 // GetLogger returns the current logger, creating a default one if none is set
 func (t *BaseTransport) GetLogger() *slog.Logger {
+	t.mu.RLock()
+	logger := t.logger
+	t.mu.RUnlock()
+	if logger != nil {
+		return logger
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	if t.logger == nil {
 		// Create a default logger that outputs to stderr with INFO level
 		t.logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
@@ -92,11 +170,15 @@ func (t *BaseTransport) GetLogger() *slog.Logger {
 
 // SetProtocolVersion sets the negotiated MCP protocol version
 func (t *BaseTransport) SetProtocolVersion(version string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.protocolVersion = version
 }
 
 // GetProtocolVersion returns the current protocol version
 func (t *BaseTransport) GetProtocolVersion() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
 	return t.protocolVersion
 }
 
@@ -107,3 +189,13 @@ func (t *BaseTransport) HandleMessage(message []byte) ([]byte, error) {
 	}
 	return t.handler(message)
 }
+
+// HandleSessionMessage handles a message received on the given client session.
+// It falls back to the message handler when no session handler is set or the
+// session ID is empty.
+func (t *BaseTransport) HandleSessionMessage(sessionID string, message []byte) ([]byte, error) {
+	if t.sessionHandler == nil || sessionID == "" {
+		return t.HandleMessage(message)
+	}
+	return t.sessionHandler(sessionID, message)
+}
@@ -7,6 +7,8 @@ package unix
 
 import (
 	"bufio"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -34,9 +36,11 @@ const DefaultSocketPermissions = 0600
 // It supports both server and client modes for local inter-process communication.
 type Transport struct {
 	transport.BaseTransport
+	transport.Dispatcher
 	socketPath       string
 	listener         net.Listener
-	conns            map[net.Conn]bool
+	conns            map[net.Conn]string // session ID of each connection
+	sessions         map[string]net.Conn // connection of each session ID
 	connsMu          sync.Mutex
 	isClient         bool
 	permissions      os.FileMode
@@ -96,7 +100,8 @@ func NewTransport(socketPath string, options ...UnixSocketOption) *Transport {
 
 	t := &Transport{
 		socketPath:       socketPath,
-		conns:            make(map[net.Conn]bool),
+		conns:            make(map[net.Conn]string),
+		sessions:         make(map[string]net.Conn),
 		isClient:         isClient,
 		permissions:      DefaultSocketPermissions,
 		socketBufferSize: 4096,
@@ -213,28 +218,33 @@ func (t *Transport) acceptConnections() {
 			continue
 		}
 
-		// Register the connection
+		// Register the connection under a session of its own
+		sessionID := generateSessionID()
 		t.connsMu.Lock()
-		t.conns[conn] = true
+		t.conns[conn] = sessionID
+		t.sessions[sessionID] = conn
 		t.connsMu.Unlock()
 
 		// Handle the connection in a goroutine
-		go t.handleServerConnection(conn)
+		go t.handleServerConnection(conn, sessionID)
 	}
 }
 
 // handleServerConnection processes messages from a client connection.
 // This is an internal function used in server mode to handle communication
-// with each connected client in its own goroutine.
-func (t *Transport) handleServerConnection(conn net.Conn) {
+// with each connected client in its own goroutine, as messages of the
+// connection's session.
+func (t *Transport) handleServerConnection(conn net.Conn, sessionID string) {
 	defer func() {
 		conn.Close()
 		t.connsMu.Lock()
 		delete(t.conns, conn)
+		delete(t.sessions, sessionID)
 		t.connsMu.Unlock()
 	}()
 
 	reader := bufio.NewReaderSize(conn, t.socketBufferSize)
+	var requests transport.RequestQueue
 
 	for {
 		// Read message length (JSON-RPC messages are newline-delimited)
@@ -250,32 +260,47 @@ func (t *Transport) handleServerConnection(conn net.Conn) {
 		// Remove trailing newline
 		message = message[:len(message)-1]
 
-		// Process the message
-		response, err := t.HandleMessage(message)
-		if err != nil {
-			// Log error
-			t.GetLogger().Error("Unix Socket Transport: Error handling message", "error", err)
-			// Try to send error response if possible
-			errorResp := createErrorResponse(message, err)
-			if errorResp != nil {
-				if _, err := conn.Write(append(errorResp, '\n')); err != nil {
-					t.GetLogger().Error("Unix Socket Transport: Error writing error response", "error", err)
-				}
+		// Process the message, reading on while requests run
+		t.Dispatch(&requests, message, func() { t.dispatch(conn, sessionID, message) })
+	}
+}
+
+// dispatch handles a message of a connection's session and writes the response
+// back to that connection, closing it when the write fails.
+func (t *Transport) dispatch(conn net.Conn, sessionID string, message []byte) {
+	response, err := t.HandleSessionMessage(sessionID, message)
+	if err != nil {
+		// Log error
+		t.GetLogger().Error("Unix Socket Transport: Error handling message", "error", err)
+		// Try to send error response if possible
+		errorResp := createErrorResponse(message, err)
+		if errorResp != nil {
+			if _, err := conn.Write(append(errorResp, '\n')); err != nil {
+				t.GetLogger().Error("Unix Socket Transport: Error writing error response", "error", err)
 			}
-			continue
 		}
+		return
+	}
 
-		if response != nil {
-			// Send response back to the client
-			_, err = conn.Write(append(response, '\n'))
-			if err != nil {
-				t.GetLogger().Error("Unix Socket Transport: Error writing response", "error", err)
-				return
-			}
+	if response != nil {
+		// Send response back to the client
+		if _, err := conn.Write(append(response, '\n')); err != nil {
+			t.GetLogger().Error("Unix Socket Transport: Error writing response", "error", err)
+			conn.Close()
 		}
 	}
 }
 
+// generateSessionID returns a random session ID for a new connection.
+func generateSessionID() string {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		// Fall back to a timestamp-based ID if crypto/rand fails
+		return fmt.Sprintf("session-%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(bytes)
+}
+
 // createErrorResponse creates a JSON-RPC error response for error situations.
 // This helper function constructs a properly formatted JSON-RPC error response
 // based on the original request and error that occurred.
@@ -334,7 +359,8 @@ func (t *Transport) Stop() error {
 		for conn := range t.conns {
 			conn.Close()
 		}
-		t.conns = make(map[net.Conn]bool)
+		t.conns = make(map[net.Conn]string)
+		t.sessions = make(map[string]net.Conn)
 		t.connsMu.Unlock()
 
 		// Remove the socket file
@@ -370,7 +396,7 @@ func (t *Transport) Send(message []byte) error {
 	var lastErr error
 	message = append(message, '\n')
 
-	for conn := range t.conns {
+	for conn, sessionID := range t.conns {
 		_, err := conn.Write(message)
 		if err != nil {
 			// Note the error but continue trying to send to other clients
@@ -378,12 +404,33 @@ func (t *Transport) Send(message []byte) error {
 			// Remove failed connection
 			conn.Close()
 			delete(t.conns, conn)
+			delete(t.sessions, sessionID)
 		}
 	}
 
 	return lastErr
 }
 
+// SendToSession sends a message to the connection with the given session ID, the
+// ID the transport passes to the session message handler for every message read
+// from that connection. Each connection is its own session, so messages for one
+// client are not seen by the others. Sending to a connection that has closed
+// fails.
+func (t *Transport) SendToSession(sessionID string, message []byte) error {
+	if t.isClient {
+		return t.Send(message)
+	}
+
+	t.connsMu.Lock()
+	conn, exists := t.sessions[sessionID]
+	t.connsMu.Unlock()
+	if !exists {
+		return fmt.Errorf("no Unix socket connection for session %s", sessionID)
+	}
+	_, err := conn.Write(append(message, '\n'))
+	return err
+}
+
 // Receive receives a message (client mode only).
 // This method is used in client mode to receive responses from the server.
 // In server mode, this method returns an error as server-side message handling
@@ -0,0 +1,215 @@
+package ws
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsflate"
+	"github.com/gobwas/ws/wsutil"
+)
+
+// ErrMessageTooLarge is returned when a message exceeds the configured read or write limit
+var ErrMessageTooLarge = errors.New("websocket message too large")
+
+// wsConn wraps an upgraded connection with the transport's per-connection settings.
+// Writes are serialized so that responses, broadcasts, pongs and keepalive pings
+// never interleave on the wire.
+type wsConn struct {
+	net.Conn
+
+	src        io.Reader
+	state      ws.State
+	compressed bool
+	readLimit  int64
+	writeLimit int64
+
+	// readTimeout bounds how long the peer may stay silent when keepalive is enabled
+	readTimeout time.Duration
+
+	writeMu sync.Mutex
+}
+
+// newWSConn wraps conn. br holds any data buffered during the handshake and may be nil.
+func newWSConn(conn net.Conn, br *bufio.Reader, state ws.State, compressed bool, t *Transport) *wsConn {
+	c := &wsConn{
+		Conn:       conn,
+		src:        conn,
+		state:      state,
+		compressed: compressed,
+		readLimit:  t.readLimit,
+		writeLimit: t.writeLimit,
+	}
+	if br != nil && br.Buffered() > 0 {
+		c.src = io.MultiReader(br, conn)
+	}
+	if compressed {
+		c.state |= ws.StateExtended
+	}
+	if t.pingInterval > 0 {
+		c.readTimeout = t.pingInterval + t.pongTimeout
+	}
+	return c
+}
+
+// readMessage reads the next text or binary message, answering control frames
+// on the way and decompressing the payload if compression was negotiated.
+func (c *wsConn) readMessage() ([]byte, ws.OpCode, error) {
+	var msgState wsflate.MessageState
+	rd := &wsutil.Reader{
+		Source:         c.src,
+		State:          c.state,
+		CheckUTF8:      !c.compressed,
+		MaxFrameSize:   c.readLimit,
+		OnIntermediate: c.handleControl,
+	}
+	if c.compressed {
+		rd.Extensions = []wsutil.RecvExtension{&msgState}
+	}
+
+	for {
+		if c.readTimeout > 0 {
+			if err := c.SetReadDeadline(time.Now().Add(c.readTimeout)); err != nil {
+				return nil, 0, err
+			}
+		}
+
+		hdr, err := rd.NextFrame()
+		if err != nil {
+			if errors.Is(err, wsutil.ErrFrameTooLarge) {
+				return nil, 0, fmt.Errorf("%w: frame exceeds %d bytes", ErrMessageTooLarge, c.readLimit)
+			}
+			return nil, 0, err
+		}
+
+		if hdr.OpCode.IsControl() {
+			if err := c.handleControl(hdr, rd); err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+
+		if hdr.OpCode&(ws.OpText|ws.OpBinary) == 0 {
+			if err := rd.Discard(); err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+
+		var payload io.Reader = rd
+		if msgState.IsCompressed() {
+			payload = wsflate.NewReader(rd, func(r io.Reader) wsflate.Decompressor {
+				return flate.NewReader(r)
+			})
+		}
+
+		// Limit the decoded size, which also guards against compression bombs
+		if c.readLimit > 0 {
+			payload = io.LimitReader(payload, c.readLimit+1)
+		}
+
+		data, err := io.ReadAll(payload)
+		if err != nil {
+			return nil, 0, err
+		}
+		if c.readLimit > 0 && int64(len(data)) > c.readLimit {
+			return nil, 0, fmt.Errorf("%w: message exceeds %d bytes", ErrMessageTooLarge, c.readLimit)
+		}
+
+		return data, hdr.OpCode, nil
+	}
+}
+
+// handleControl answers ping and close frames. Replies are built in memory and
+// written under the write lock so they cannot split a concurrent message.
+func (c *wsConn) handleControl(hdr ws.Header, src io.Reader) error {
+	var reply bytes.Buffer
+	err := wsutil.ControlHandler{
+		Src:                 src,
+		Dst:                 &reply,
+		State:               c.state &^ ws.StateExtended,
+		DisableSrcCiphering: true,
+	}.Handle(hdr)
+
+	if reply.Len() > 0 {
+		c.writeMu.Lock()
+		_, writeErr := c.Write(reply.Bytes())
+		c.writeMu.Unlock()
+		if err == nil {
+			err = writeErr
+		}
+	}
+
+	return err
+}
+
+// writeMessage writes a complete message, compressing it if compression was negotiated.
+func (c *wsConn) writeMessage(op ws.OpCode, payload []byte) error {
+	if c.writeLimit > 0 && int64(len(payload)) > c.writeLimit {
+		return fmt.Errorf("%w: %d bytes exceeds limit of %d", ErrMessageTooLarge, len(payload), c.writeLimit)
+	}
+
+	if !c.compressed {
+		c.writeMu.Lock()
+		defer c.writeMu.Unlock()
+		return wsutil.WriteMessage(c.Conn, c.state, op, payload)
+	}
+
+	var buf bytes.Buffer
+	var msgState wsflate.MessageState
+	msgState.SetCompressed(true)
+
+	wr := wsutil.NewWriter(&buf, c.state, op)
+	wr.SetExtensions(&msgState)
+
+	fw := wsflate.NewWriter(wr, func(w io.Writer) wsflate.Compressor {
+		f, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return f
+	})
+	if _, err := fw.Write(payload); err != nil {
+		return err
+	}
+	// Flush ends the deflate stream with the sync tail that permessage-deflate strips
+	if err := fw.Flush(); err != nil {
+		return err
+	}
+	if err := wr.Flush(); err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.Write(buf.Bytes())
+	return err
+}
+
+// ping sends a keepalive ping. The peer's pong extends the read deadline.
+func (c *wsConn) ping() error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return wsutil.WriteMessage(c.Conn, c.state&^ws.StateExtended, ws.OpPing, nil)
+}
+
+// keepAlive pings the peer every interval until done is closed or a ping fails.
+func (c *wsConn) keepAlive(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := c.ping(); err != nil {
+				return
+			}
+		}
+	}
+}
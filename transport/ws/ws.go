@@ -5,17 +5,23 @@
 package ws
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"log/slog"
-	"net"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/gobwas/httphead"
 	"github.com/gobwas/ws"
-	"github.com/gobwas/ws/wsutil"
+	"github.com/gobwas/ws/wsflate"
 	"github.com/localrivet/gomcp/transport"
 )
 
@@ -25,19 +31,142 @@ const DefaultShutdownTimeout = 10 * time.Second
 // DefaultWSPath is the default endpoint path for WebSocket connections
 const DefaultWSPath = "/ws"
 
+// DefaultPongTimeout is how long a peer may take to answer a keepalive ping
+const DefaultPongTimeout = 10 * time.Second
+
+// Option represents a configuration option for the WebSocket transport.
+type Option func(*Transport)
+
+// WithTLSConfig sets the TLS configuration. Servers serve HTTPS with it, which
+// requires certificates in the config or from WithTLS; clients use it to dial
+// wss:// addresses, for example to trust a private CA or present a client certificate.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(t *Transport) {
+		t.tlsConfig = config
+	}
+}
+
+// WithTLS makes a server serve TLS with the given certificate and key files.
+func WithTLS(certFile, keyFile string) Option {
+	return func(t *Transport) {
+		t.tlsCertFile = certFile
+		t.tlsKeyFile = keyFile
+	}
+}
+
+// WithCompression enables permessage-deflate compression (RFC 7692).
+// Compression is only used on connections where both peers negotiate it.
+func WithCompression(enabled bool) Option {
+	return func(t *Transport) {
+		t.compression = enabled
+	}
+}
+
+// WithKeepAlive pings the peer every interval and closes the connection if
+// nothing, including the pong, is received within interval plus timeout.
+// A timeout of zero uses DefaultPongTimeout.
+func WithKeepAlive(interval, timeout time.Duration) Option {
+	return func(t *Transport) {
+		if interval > 0 {
+			t.pingInterval = interval
+		}
+		if timeout > 0 {
+			t.pongTimeout = timeout
+		}
+	}
+}
+
+// WithReadLimit sets the maximum size in bytes of a received message.
+// Connections sending larger messages are closed.
+func WithReadLimit(limit int64) Option {
+	return func(t *Transport) {
+		if limit > 0 {
+			t.readLimit = limit
+		}
+	}
+}
+
+// WithWriteLimit sets the maximum size in bytes of a sent message.
+// Sending a larger message fails with ErrMessageTooLarge.
+func WithWriteLimit(limit int64) Option {
+	return func(t *Transport) {
+		if limit > 0 {
+			t.writeLimit = limit
+		}
+	}
+}
+
+// WithOriginCheck sets a callback that validates the Origin header of incoming
+// connections. Requests it rejects get 403 Forbidden before the upgrade.
+func WithOriginCheck(check func(origin string) bool) Option {
+	return func(t *Transport) {
+		t.checkOrigin = check
+	}
+}
+
+// WithAllowedOrigins only accepts connections from the given origins, compared
+// case-insensitively. Requests without an Origin header come from non-browser
+// clients and are accepted.
+func WithAllowedOrigins(origins ...string) Option {
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[strings.ToLower(origin)] = true
+	}
+
+	return WithOriginCheck(func(origin string) bool {
+		return origin == "" || allowed[strings.ToLower(origin)]
+	})
+}
+
+// WithAccessLog logs every request the server answers, in the combined log
+// format or as structured records. Upgrades are logged with status 101 once the
+// handshake completes.
+func WithAccessLog(config transport.AccessLogConfig) Option {
+	return func(t *Transport) {
+		t.accessLog = &config
+	}
+}
+
+// WithAccessPolicy restricts which client IPs may reach the server and how many
+// requests and connections each may make. Rejected upgrade requests get 403
+// Forbidden or 429 Too Many Requests, and are reported to the violation handler.
+func WithAccessPolicy(policy transport.AccessPolicy) Option {
+	return func(t *Transport) {
+		t.accessPolicy = &policy
+	}
+}
+
 // Transport implements the transport.Transport interface for WebSocket
 type Transport struct {
 	transport.BaseTransport
+	transport.Dispatcher
 	addr       string
 	server     *http.Server
-	conns      map[net.Conn]bool
+	conns      map[*wsConn]string // session ID of each connection
+	sessions   map[string]*wsConn // connection of each session ID
 	connsMu    sync.Mutex
 	isClient   bool
 	pathPrefix string // Optional prefix for endpoint path (e.g., "/mcp")
 	wsPath     string // Endpoint path for WebSocket connections
 
+	// Connection options
+	tlsConfig    *tls.Config
+	tlsCertFile  string
+	tlsKeyFile   string
+	compression  bool
+	pingInterval time.Duration
+	pongTimeout  time.Duration
+	readLimit    int64
+	writeLimit   int64
+	checkOrigin  func(origin string) bool
+	accessLog    *transport.AccessLogConfig
+
+	// Client restrictions, nil when disabled, and the handler of rejected clients
+	accessPolicy     *transport.AccessPolicy
+	violationHandler transport.ViolationHandler
+
 	// For client mode
-	clientConn net.Conn
+	clientConn *wsConn
 	clientMu   sync.Mutex
 	readCh     chan []byte
 	errCh      chan error
@@ -45,16 +174,22 @@ type Transport struct {
 }
 
 // NewTransport creates a new WebSocket transport
-func NewTransport(addr string) *Transport {
+func NewTransport(addr string, options ...Option) *Transport {
 	// Determine if we're in client or server mode based on the address
 	isClient := strings.HasPrefix(addr, "ws://") || strings.HasPrefix(addr, "wss://")
 
 	t := &Transport{
-		addr:       addr,
-		conns:      make(map[net.Conn]bool),
-		isClient:   isClient,
-		pathPrefix: "", // Empty by default
-		wsPath:     DefaultWSPath,
+		addr:        addr,
+		conns:       make(map[*wsConn]string),
+		sessions:    make(map[string]*wsConn),
+		isClient:    isClient,
+		pathPrefix:  "", // Empty by default
+		wsPath:      DefaultWSPath,
+		pongTimeout: DefaultPongTimeout,
+	}
+
+	for _, option := range options {
+		option(t)
 	}
 
 	if isClient {
@@ -116,17 +251,35 @@ func (t *Transport) Initialize() error {
 			wsURL = strings.TrimSuffix(wsURL, "/") + DefaultWSPath
 		}
 
-		conn, _, _, err := ws.Dial(ctx, wsURL)
+		dialer := ws.Dialer{TLSConfig: t.tlsConfig}
+		if t.compression {
+			dialer.Extensions = []httphead.Option{wsflate.DefaultParameters.Option()}
+		}
+
+		conn, br, hs, err := dialer.Dial(ctx, wsURL)
 		if err != nil {
 			return err
 		}
 
+		compressed := false
+		for _, ext := range hs.Extensions {
+			if bytes.Equal(ext.Name, wsflate.ExtensionNameBytes) {
+				compressed = true
+			}
+		}
+
+		wc := newWSConn(conn, br, ws.StateClientSide, compressed, t)
+
 		t.clientMu.Lock()
-		t.clientConn = conn
+		t.clientConn = wc
 		t.clientMu.Unlock()
 
 		// Start reading messages
 		go t.readClientMessages()
+
+		if t.pingInterval > 0 {
+			go wc.keepAlive(t.pingInterval, t.doneCh)
+		}
 	}
 	return nil
 }
@@ -144,13 +297,43 @@ func (t *Transport) Start() error {
 	// Register WebSocket handler at the configured path
 	mux.HandleFunc(t.GetFullWSPath(), t.handleWebSocketRequest)
 
+	useTLS := t.tlsCertFile != "" || t.tlsConfig != nil
+	if useTLS && t.tlsCertFile == "" && len(t.tlsConfig.Certificates) == 0 && t.tlsConfig.GetCertificate == nil {
+		return fmt.Errorf("websocket TLS configuration has no certificate")
+	}
+
+	listener, err := transport.Listen(t.addr, transport.ServerLimits{})
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", t.addr, err)
+	}
+
+	handler, listener, err := transport.GuardServer(t.accessPolicy, t.reportViolation, mux, listener)
+	if err != nil {
+		listener.Close()
+		return fmt.Errorf("invalid access policy: %w", err)
+	}
+	if t.accessLog != nil {
+		config := *t.accessLog
+		if config.Logger == nil {
+			config.Logger = t.GetLogger()
+		}
+		handler = transport.AccessLog(handler, config)
+	}
+
 	t.server = &http.Server{
-		Addr:    t.addr,
-		Handler: mux,
+		Addr:      t.addr,
+		Handler:   handler,
+		TLSConfig: t.tlsConfig,
 	}
 
 	go func() {
-		if err := t.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if useTLS {
+			err = t.server.ServeTLS(listener, t.tlsCertFile, t.tlsKeyFile)
+		} else {
+			err = t.server.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
 			// Log error
 			slog.Default().Error("WebSocket server error", "error", err)
 		}
@@ -182,7 +365,8 @@ func (t *Transport) Stop() error {
 	for conn := range t.conns {
 		conn.Close()
 	}
-	t.conns = make(map[net.Conn]bool)
+	t.conns = make(map[*wsConn]string)
+	t.sessions = make(map[string]*wsConn)
 	t.connsMu.Unlock()
 
 	// Shutdown the server
@@ -200,7 +384,7 @@ func (t *Transport) Send(message []byte) error {
 			return errors.New("not connected to server")
 		}
 
-		return wsutil.WriteClientMessage(t.clientConn, ws.OpText, message)
+		return t.clientConn.writeMessage(ws.OpText, message)
 	}
 
 	// Server mode - send to all clients
@@ -208,19 +392,39 @@ func (t *Transport) Send(message []byte) error {
 	defer t.connsMu.Unlock()
 
 	var lastErr error
-	for conn := range t.conns {
-		if err := wsutil.WriteServerMessage(conn, ws.OpText, message); err != nil {
+	for conn, sessionID := range t.conns {
+		if err := conn.writeMessage(ws.OpText, message); err != nil {
 			// Note the error but continue trying to send to other clients
 			lastErr = err
 			// Remove failed connection
 			conn.Close()
 			delete(t.conns, conn)
+			delete(t.sessions, sessionID)
 		}
 	}
 
 	return lastErr
 }
 
+// SendToSession sends a message to the connection with the given session ID, the
+// ID the transport passes to the session message handler for every message read
+// from that connection. Each connection is its own session, so messages for one
+// client are not seen by the others. Sending to a connection that has closed
+// fails.
+func (t *Transport) SendToSession(sessionID string, message []byte) error {
+	if t.isClient {
+		return t.Send(message)
+	}
+
+	t.connsMu.Lock()
+	conn, exists := t.sessions[sessionID]
+	t.connsMu.Unlock()
+	if !exists {
+		return fmt.Errorf("no WebSocket connection for session %s", sessionID)
+	}
+	return conn.writeMessage(ws.OpText, message)
+}
+
 // Receive receives a message (client mode only)
 func (t *Transport) Receive() ([]byte, error) {
 	if !t.isClient {
@@ -237,60 +441,117 @@ func (t *Transport) Receive() ([]byte, error) {
 	}
 }
 
+// SetViolationHandler sets the handler called for every client the access policy
+// rejects.
+func (t *Transport) SetViolationHandler(handler transport.ViolationHandler) {
+	t.violationHandler = handler
+}
+
+// reportViolation passes a rejected client to the violation handler, if any
+func (t *Transport) reportViolation(violation transport.Violation) {
+	if t.violationHandler != nil {
+		t.violationHandler(violation)
+	}
+}
+
 // handleWebSocketRequest handles incoming WebSocket connection requests
 func (t *Transport) handleWebSocketRequest(w http.ResponseWriter, r *http.Request) {
-	// Upgrade the HTTP connection to WebSocket
-	conn, _, _, err := ws.UpgradeHTTP(r, w)
+	// Reject cross-origin requests before upgrading
+	if t.checkOrigin != nil && !t.checkOrigin(r.Header.Get("Origin")) {
+		http.Error(w, "Origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	// Upgrade the HTTP connection to WebSocket, negotiating compression if enabled
+	var upgrader ws.HTTPUpgrader
+	var deflate *wsflate.Extension
+	if t.compression {
+		deflate = &wsflate.Extension{Parameters: wsflate.DefaultParameters}
+		upgrader.Negotiate = deflate.Negotiate
+	}
+
+	netConn, rw, _, err := upgrader.Upgrade(r, w)
 	if err != nil {
 		return
 	}
 
-	// Register the connection
+	var br *bufio.Reader
+	if rw != nil {
+		br = rw.Reader
+	}
+
+	compressed := false
+	if deflate != nil {
+		_, compressed = deflate.Accepted()
+	}
+
+	conn := newWSConn(netConn, br, ws.StateServerSide, compressed, t)
+
+	// Register the connection under a session of its own
+	sessionID := generateSessionID()
 	t.connsMu.Lock()
-	t.conns[conn] = true
+	t.conns[conn] = sessionID
+	t.sessions[sessionID] = conn
 	t.connsMu.Unlock()
 
 	// Handle incoming messages in a goroutine
-	go t.handleServerConnection(conn)
+	go t.handleServerConnection(conn, sessionID)
 }
 
-// handleServerConnection processes messages from a client connection
-func (t *Transport) handleServerConnection(conn net.Conn) {
+// handleServerConnection processes messages from a client connection, handling
+// them as messages of the connection's session
+func (t *Transport) handleServerConnection(conn *wsConn, sessionID string) {
+	var requests transport.RequestQueue
+	done := make(chan struct{})
 	defer func() {
+		close(done)
 		conn.Close()
 		t.connsMu.Lock()
 		delete(t.conns, conn)
+		delete(t.sessions, sessionID)
 		t.connsMu.Unlock()
 	}()
 
+	if t.pingInterval > 0 {
+		go conn.keepAlive(t.pingInterval, done)
+	}
+
 	for {
-		msg, op, err := wsutil.ReadClientData(conn)
+		msg, op, err := conn.readMessage()
 		if err != nil {
-			// Connection closed or error
+			// Connection closed, limit exceeded or keepalive expired
+			if errors.Is(err, ErrMessageTooLarge) {
+				slog.Default().Warn("closing WebSocket connection", "error", err)
+			}
 			return
 		}
 
-		if op == ws.OpClose {
-			return
+		if op == ws.OpText || op == ws.OpBinary {
+			t.Dispatch(&requests, msg, func() { t.dispatch(conn, sessionID, msg) })
 		}
+	}
+}
 
-		if op == ws.OpText || op == ws.OpBinary {
-			// Process the message
-			response, err := t.HandleMessage(msg)
-			if err != nil {
-				// Log error
-				continue
-			}
+// dispatch handles a message of a connection's session and writes the response
+// back to that connection, closing it when the write fails
+func (t *Transport) dispatch(conn *wsConn, sessionID string, msg []byte) {
+	response, err := t.HandleSessionMessage(sessionID, msg)
+	if err != nil || response == nil {
+		return
+	}
+	if err := conn.writeMessage(ws.OpText, response); err != nil {
+		conn.Close()
+	}
+}
 
-			if response != nil {
-				// Send response back to this specific client
-				if err := wsutil.WriteServerMessage(conn, ws.OpText, response); err != nil {
-					// Log error
-					return
-				}
-			}
-		}
+// generateSessionID returns a random session ID for a new connection
+func generateSessionID() string {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		// Fall back to a timestamp-based ID if crypto/rand fails
+		return fmt.Sprintf("session-%d", time.Now().UnixNano())
 	}
+	return hex.EncodeToString(bytes)
 }
 
 // readClientMessages continuously reads messages from the server in client mode
@@ -318,17 +579,12 @@ func (t *Transport) readClientMessages() {
 				return
 			}
 
-			msg, op, err := wsutil.ReadServerData(conn)
+			msg, op, err := conn.readMessage()
 			if err != nil {
 				t.errCh <- err
 				return
 			}
 
-			if op == ws.OpClose {
-				t.errCh <- errors.New("connection closed by server")
-				return
-			}
-
 			if op == ws.OpText || op == ws.OpBinary {
 				select {
 				case t.readCh <- msg:
@@ -2,6 +2,7 @@ package ws
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -134,3 +135,210 @@ func TestEchoHandler(t *testing.T) {
 		t.Fatalf("Failed to stop transport: %v", err)
 	}
 }
+
+// newOptionsTestServer starts a server transport with the given options behind an
+// httptest server, echoing every message, and returns the server and its ws:// URL.
+func newOptionsTestServer(t *testing.T, options ...Option) (*Transport, string) {
+	t.Helper()
+
+	serverTransport := NewTransport(":0", options...)
+	serverTransport.SetMessageHandler(func(message []byte) ([]byte, error) {
+		return message, nil
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(serverTransport.handleWebSocketRequest))
+	t.Cleanup(server.Close)
+
+	return serverTransport, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// receiveWithTimeout waits for the next message received by a client transport
+func receiveWithTimeout(t *testing.T, transport *Transport) ([]byte, error) {
+	t.Helper()
+
+	type result struct {
+		msg []byte
+		err error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		msg, err := transport.Receive()
+		resultCh <- result{msg, err}
+	}()
+
+	select {
+	case r := <-resultCh:
+		return r.msg, r.err
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for message")
+		return nil, nil
+	}
+}
+
+func TestOriginCheck(t *testing.T) {
+	_, wsURL := newOptionsTestServer(t, WithAllowedOrigins("https://app.example.com"))
+
+	dial := func(origin string) error {
+		dialer := ws.Dialer{Header: ws.HandshakeHeaderHTTP(http.Header{"Origin": {origin}})}
+		conn, _, _, err := dialer.Dial(context.Background(), wsURL)
+		if err == nil {
+			conn.Close()
+		}
+		return err
+	}
+
+	if err := dial("https://APP.example.com"); err != nil {
+		t.Errorf("Expected allowed origin to connect, got %v", err)
+	}
+
+	err := dial("https://evil.example.com")
+	var statusErr ws.StatusError
+	if !errors.As(err, &statusErr) || int(statusErr) != http.StatusForbidden {
+		t.Errorf("Expected 403 for disallowed origin, got %v", err)
+	}
+
+	// Non-browser clients send no Origin header
+	conn, _, _, err := ws.Dial(context.Background(), wsURL)
+	if err != nil {
+		t.Fatalf("Expected connection without Origin to succeed, got %v", err)
+	}
+	conn.Close()
+}
+
+func TestCompression(t *testing.T) {
+	serverTransport, wsURL := newOptionsTestServer(t, WithCompression(true))
+
+	client := NewTransport(wsURL, WithCompression(true))
+	if err := client.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize client: %v", err)
+	}
+	defer client.Stop()
+
+	if !client.clientConn.compressed {
+		t.Fatal("Expected compression to be negotiated")
+	}
+
+	message := []byte(strings.Repeat(`{"jsonrpc":"2.0","method":"ping"}`, 50))
+	if err := client.Send(message); err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+
+	resp, err := receiveWithTimeout(t, client)
+	if err != nil {
+		t.Fatalf("Failed to receive message: %v", err)
+	}
+	if string(resp) != string(message) {
+		t.Errorf("Expected echoed message of %d bytes, got %d bytes", len(message), len(resp))
+	}
+
+	serverTransport.connsMu.Lock()
+	for conn := range serverTransport.conns {
+		if !conn.compressed {
+			t.Error("Expected server connection to be compressed")
+		}
+	}
+	serverTransport.connsMu.Unlock()
+}
+
+func TestReadAndWriteLimits(t *testing.T) {
+	_, wsURL := newOptionsTestServer(t, WithReadLimit(64))
+
+	client := NewTransport(wsURL, WithWriteLimit(128))
+	if err := client.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize client: %v", err)
+	}
+	defer client.Stop()
+
+	if err := client.Send(make([]byte, 256)); !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("Expected ErrMessageTooLarge from write limit, got %v", err)
+	}
+
+	// Within the client's write limit but above the server's read limit
+	if err := client.Send([]byte(strings.Repeat("x", 100))); err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+
+	if _, err := receiveWithTimeout(t, client); err == nil {
+		t.Error("Expected server to close the connection after exceeding its read limit")
+	}
+}
+
+func TestKeepAlive(t *testing.T) {
+	serverTransport, wsURL := newOptionsTestServer(t, WithKeepAlive(20*time.Millisecond, 20*time.Millisecond))
+
+	// A client transport answers pings and stays connected
+	client := NewTransport(wsURL)
+	if err := client.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize client: %v", err)
+	}
+	defer client.Stop()
+
+	// A raw connection that never reads does not answer pings
+	silent, _, _, err := ws.Dial(context.Background(), wsURL)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer silent.Close()
+
+	time.Sleep(200 * time.Millisecond)
+
+	serverTransport.connsMu.Lock()
+	remaining := len(serverTransport.conns)
+	serverTransport.connsMu.Unlock()
+
+	if remaining != 1 {
+		t.Errorf("Expected only the responsive connection to remain, got %d connections", remaining)
+	}
+
+	if err := client.Send([]byte("still alive")); err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	if resp, err := receiveWithTimeout(t, client); err != nil || string(resp) != "still alive" {
+		t.Errorf("Expected echo on kept-alive connection, got %q, %v", resp, err)
+	}
+}
+
+func TestConnectionSessions(t *testing.T) {
+	serverTransport, wsURL := newOptionsTestServer(t)
+	serverTransport.SetSessionMessageHandler(func(sessionID string, message []byte) ([]byte, error) {
+		return []byte(sessionID), nil
+	})
+
+	// Each connection is handled as a session of its own
+	var clients []*Transport
+	var sessionIDs []string
+	for i := 0; i < 2; i++ {
+		client := NewTransport(wsURL)
+		if err := client.Initialize(); err != nil {
+			t.Fatalf("Failed to initialize client: %v", err)
+		}
+		defer client.Stop()
+
+		if err := client.Send([]byte("who am I")); err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+		sessionID, err := receiveWithTimeout(t, client)
+		if err != nil || len(sessionID) == 0 {
+			t.Fatalf("Expected a session ID, got %q, %v", sessionID, err)
+		}
+		clients = append(clients, client)
+		sessionIDs = append(sessionIDs, string(sessionID))
+	}
+	if sessionIDs[0] == sessionIDs[1] {
+		t.Fatalf("Expected distinct sessions, both got %s", sessionIDs[0])
+	}
+
+	// Messages sent to a session reach its connection only
+	for i, sessionID := range sessionIDs {
+		if err := serverTransport.SendToSession(sessionID, []byte("for "+sessionID)); err != nil {
+			t.Fatalf("Failed to send to session: %v", err)
+		}
+		if resp, err := receiveWithTimeout(t, clients[i]); err != nil || string(resp) != "for "+sessionID {
+			t.Errorf("Expected the message for %s, got %q, %v", sessionID, resp, err)
+		}
+	}
+
+	if err := serverTransport.SendToSession("closed", []byte("lost")); err == nil {
+		t.Error("Expected sending to an unknown session to fail")
+	}
+}
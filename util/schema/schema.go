@@ -2,9 +2,15 @@
 package schema
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
 	"reflect"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -15,7 +21,7 @@ import (
 
 // PropertyDetail represents a JSON Schema property definition.
 type PropertyDetail struct {
-	Type        string          `json:"type"`
+	Type        string          `json:"type,omitempty"`
 	Description string          `json:"description,omitempty"`
 	Enum        []interface{}   `json:"enum,omitempty"`
 	Format      string          `json:"format,omitempty"`
@@ -24,6 +30,8 @@ type PropertyDetail struct {
 	MinLength   *int            `json:"minLength,omitempty"`
 	MaxLength   *int            `json:"maxLength,omitempty"`
 	Pattern     string          `json:"pattern,omitempty"`
+	MinItems    *int            `json:"minItems,omitempty"`
+	MaxItems    *int            `json:"maxItems,omitempty"`
 	Default     interface{}     `json:"default,omitempty"`
 	Items       *PropertyDetail `json:"items,omitempty"`
 }
@@ -80,6 +88,9 @@ func goTypeToJSONType(kind reflect.Kind) string {
 		return "array"
 	case reflect.Map, reflect.Struct:
 		return "object"
+	case reflect.Interface:
+		// Any JSON value
+		return ""
 	default:
 		return "string"
 	}
@@ -109,6 +120,106 @@ func parseIntTag(tagValue string) *int {
 	return &val
 }
 
+// timeType is described as a date-time string rather than an object, matching
+// how arguments are decoded into it
+var timeType = reflect.TypeOf(time.Time{})
+
+// fieldConstraints are the constraints declared on a struct field
+type fieldConstraints struct {
+	required             bool
+	min, max             string
+	minLength, maxLength string
+	minItems, maxItems   string
+	enum                 []string
+	pattern              string
+	format               string
+}
+
+// parseValidateTag splits a validate or jsonschema tag such as
+// `validate:"required,min=1,max=10,enum=a|b|c"` into its rules. Because a regular
+// expression may itself contain commas, pattern must be the last rule.
+func parseValidateTag(tag string) map[string]string {
+	rules := make(map[string]string)
+	for tag != "" {
+		if strings.HasPrefix(tag, "pattern=") {
+			rules["pattern"] = strings.TrimPrefix(tag, "pattern=")
+			break
+		}
+		rule := tag
+		if i := strings.Index(tag, ","); i >= 0 {
+			rule, tag = tag[:i], tag[i+1:]
+		} else {
+			tag = ""
+		}
+		key, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if key != "" {
+			rules[key] = value
+		}
+	}
+	return rules
+}
+
+// constraintsOf collects a field's constraints from its individual tags
+// (min:"1", enum:"a,b"), its jsonschema tag and its validate tag, in that order of
+// precedence.
+//
+// A jsonschema tag uses JSON Schema keywords, as in
+// `jsonschema:"enum=add|subtract,minimum=0"`. In a validate tag, min and max bound
+// the length of strings and the number of items in slices. In both, enum values
+// are separated by "|".
+func constraintsOf(field reflect.StructField, kind reflect.Kind) fieldConstraints {
+	keywords := parseValidateTag(field.Tag.Get("jsonschema"))
+	rules := parseValidateTag(field.Tag.Get("validate"))
+	pick := func(tag, keyword, rule string) string {
+		if value := field.Tag.Get(tag); value != "" {
+			return value
+		}
+		if value := keywords[keyword]; value != "" {
+			return value
+		}
+		return rules[rule]
+	}
+
+	c := fieldConstraints{
+		pattern: pick("pattern", "pattern", "pattern"),
+		format:  pick("format", "format", "format"),
+	}
+	_, requiredKeyword := keywords["required"]
+	_, requiredRule := rules["required"]
+	c.required = requiredKeyword || requiredRule || field.Tag.Get("required") == "true"
+
+	switch {
+	case kind == reflect.String:
+		c.minLength = pick("minLength", "minLength", "min")
+		c.maxLength = pick("maxLength", "maxLength", "max")
+		if c.minLength == "" {
+			c.minLength = rules["minLength"]
+		}
+		if c.maxLength == "" {
+			c.maxLength = rules["maxLength"]
+		}
+	case kind == reflect.Slice || kind == reflect.Array:
+		c.minItems = pick("minItems", "minItems", "min")
+		c.maxItems = pick("maxItems", "maxItems", "max")
+	case kind >= reflect.Int && kind <= reflect.Float64:
+		c.min = pick("min", "minimum", "min")
+		c.max = pick("max", "maximum", "max")
+	}
+
+	if enumTag := field.Tag.Get("enum"); enumTag != "" {
+		c.enum = strings.Split(enumTag, ",")
+	} else if enumKeyword := keywords["enum"]; enumKeyword != "" {
+		c.enum = strings.Split(enumKeyword, "|")
+	} else if enumRule := rules["enum"]; enumRule != "" {
+		c.enum = strings.Split(enumRule, "|")
+	}
+	for i, value := range c.enum {
+		c.enum[i] = strings.TrimSpace(value)
+	}
+
+	return c
+}
+
 // FromStruct generates a ToolInputSchema from struct tags.
 // It examines the struct fields and their tags to create a schema that describes
 // the expected input format for an MCP tool.
@@ -130,6 +241,9 @@ func FromStruct(v interface{}) ToolInputSchema {
 		}
 
 		descTag := field.Tag.Get("description")
+		if descTag == "" {
+			descTag = parseValidateTag(field.Tag.Get("jsonschema"))["description"]
+		}
 		jsonTag := field.Tag.Get("json")
 		var name string
 
@@ -152,24 +266,29 @@ func FromStruct(v interface{}) ToolInputSchema {
 			name = strings.ToLower(field.Name)
 		}
 
-		// Check for required tag
-		if field.Tag.Get("required") == "true" && !trackFields[name] {
-			requiredFields = append(requiredFields, name)
-			trackFields[name] = true
-		}
-
 		// Determine the schema type
 		fieldType := field.Type
 		if field.Type.Kind() == reflect.Ptr {
 			fieldType = fieldType.Elem()
 		}
 		schemaType := goTypeToJSONType(fieldType.Kind())
+		constraints := constraintsOf(field, fieldType.Kind())
+
+		// Check for required tag
+		if constraints.required && !trackFields[name] {
+			requiredFields = append(requiredFields, name)
+			trackFields[name] = true
+		}
 
 		// Create property definition
 		propDetail := PropertyDetail{
 			Type:        schemaType,
 			Description: descTag,
 		}
+		if fieldType == timeType {
+			propDetail.Type = "string"
+			propDetail.Format = "date-time"
+		}
 
 		// Handle array/slice types - generate items schema
 		if fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array {
@@ -189,58 +308,47 @@ func FromStruct(v interface{}) ToolInputSchema {
 			propDetail.Items = itemsDetail
 		}
 
-		// Process enum tag
-		enumTag := field.Tag.Get("enum")
-		if enumTag != "" {
-			enumValuesStr := strings.Split(enumTag, ",")
-			enumValues := make([]interface{}, len(enumValuesStr))
-			for i, v := range enumValuesStr {
-				// Trim whitespace and store as interface{}
-				enumValues[i] = strings.TrimSpace(v)
+		// Process enum values, which are numbers for numeric fields
+		if len(constraints.enum) > 0 {
+			enumValues := make([]interface{}, len(constraints.enum))
+			for i, v := range constraints.enum {
+				enumValues[i] = v
+				if schemaType == "integer" || schemaType == "number" {
+					if num, err := strconv.ParseFloat(v, 64); err == nil {
+						enumValues[i] = num
+					}
+				}
 			}
 			propDetail.Enum = enumValues
 		}
 
-		// Process format tag for string
-		formatTag := field.Tag.Get("format")
-		if formatTag != "" {
-			propDetail.Format = formatTag
+		// Process format for string
+		if constraints.format != "" {
+			propDetail.Format = constraints.format
 		}
 
-		// Process pattern tag for string
-		patternTag := field.Tag.Get("pattern")
-		if patternTag != "" {
-			propDetail.Pattern = patternTag
+		// Process pattern for string
+		if constraints.pattern != "" {
+			propDetail.Pattern = constraints.pattern
 		}
 
 		// Process min/max for numeric types
-		if schemaType == "integer" || schemaType == "number" {
-			minTag := field.Tag.Get("min")
-			if minTag != "" {
-				propDetail.Minimum = parseNumericTag(minTag)
-			}
-
-			maxTag := field.Tag.Get("max")
-			if maxTag != "" {
-				propDetail.Maximum = parseNumericTag(maxTag)
-			}
-		}
+		propDetail.Minimum = parseNumericTag(constraints.min)
+		propDetail.Maximum = parseNumericTag(constraints.max)
 
 		// Process minLength/maxLength for string
-		if schemaType == "string" {
-			minLengthTag := field.Tag.Get("minLength")
-			if minLengthTag != "" {
-				propDetail.MinLength = parseIntTag(minLengthTag)
-			}
+		propDetail.MinLength = parseIntTag(constraints.minLength)
+		propDetail.MaxLength = parseIntTag(constraints.maxLength)
 
-			maxLengthTag := field.Tag.Get("maxLength")
-			if maxLengthTag != "" {
-				propDetail.MaxLength = parseIntTag(maxLengthTag)
-			}
-		}
+		// Process minItems/maxItems for arrays
+		propDetail.MinItems = parseIntTag(constraints.minItems)
+		propDetail.MaxItems = parseIntTag(constraints.maxItems)
 
 		// Process default value
 		defaultTag := field.Tag.Get("default")
+		if defaultTag == "" {
+			defaultTag = parseValidateTag(field.Tag.Get("jsonschema"))["default"]
+		}
 		if defaultTag != "" {
 			// Convert default to the appropriate type based on schema type
 			switch schemaType {
@@ -274,9 +382,36 @@ func FromStruct(v interface{}) ToolInputSchema {
 	return schema
 }
 
+// FieldError describes one violated constraint.
+type FieldError struct {
+	// Field is the name of the offending field, using its JSON name. Entries of
+	// arrays and maps are written as "items[2].name" or "labels[env]".
+	Field string `json:"field"`
+
+	// Constraint is the schema keyword that was violated, such as "required",
+	// "minimum", "pattern" or "type".
+	Constraint string `json:"constraint"`
+
+	// Message is a human-readable description of the violation.
+	Message string `json:"message"`
+}
+
+// ValidationErrors lists every constraint a value violated.
+type ValidationErrors []FieldError
+
+// Error joins the messages of all violations.
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fe := range e {
+		messages[i] = fe.Message
+	}
+	return fmt.Sprintf("validation failed: %s", strings.Join(messages, "; "))
+}
+
 // Validator provides validation for struct fields.
 type Validator struct {
-	errors []string
+	errors     []string
+	violations ValidationErrors
 }
 
 // NewValidator creates a new validator.
@@ -286,16 +421,22 @@ func NewValidator() *Validator {
 	}
 }
 
+// add records a violated constraint
+func (v *Validator) add(field, constraint, message string) {
+	v.errors = append(v.errors, message)
+	v.violations = append(v.violations, FieldError{Field: field, Constraint: constraint, Message: message})
+}
+
 // Required validates that a field is not nil or empty.
 func (v *Validator) Required(fieldName string, value interface{}) *Validator {
 	if value == nil {
-		v.errors = append(v.errors, fmt.Sprintf("Field '%s' is required but was not provided", fieldName))
+		v.add(fieldName, "required", fmt.Sprintf("Field '%s' is required but was not provided", fieldName))
 		return v
 	}
 
 	// Check for empty string
 	if strVal, ok := value.(string); ok && strVal == "" {
-		v.errors = append(v.errors, fmt.Sprintf("Field '%s' is required but was empty", fieldName))
+		v.add(fieldName, "required", fmt.Sprintf("Field '%s' is required but was empty", fieldName))
 	}
 
 	return v
@@ -330,12 +471,12 @@ func (v *Validator) Min(fieldName string, value interface{}, min float64) *Valid
 	case float64:
 		numValue = val
 	default:
-		v.errors = append(v.errors, fmt.Sprintf("Field '%s' must be a number for min validation", fieldName))
+		v.add(fieldName, "type", fmt.Sprintf("Field '%s' must be a number for min validation", fieldName))
 		return v
 	}
 
 	if numValue < min {
-		v.errors = append(v.errors, fmt.Sprintf("Field '%s' must be at least %v", fieldName, min))
+		v.add(fieldName, "minimum", fmt.Sprintf("Field '%s' must be at least %v", fieldName, min))
 	}
 
 	return v
@@ -370,12 +511,12 @@ func (v *Validator) Max(fieldName string, value interface{}, max float64) *Valid
 	case float64:
 		numValue = val
 	default:
-		v.errors = append(v.errors, fmt.Sprintf("Field '%s' must be a number for max validation", fieldName))
+		v.add(fieldName, "type", fmt.Sprintf("Field '%s' must be a number for max validation", fieldName))
 		return v
 	}
 
 	if numValue > max {
-		v.errors = append(v.errors, fmt.Sprintf("Field '%s' must be at most %v", fieldName, max))
+		v.add(fieldName, "maximum", fmt.Sprintf("Field '%s' must be at most %v", fieldName, max))
 	}
 
 	return v
@@ -384,7 +525,7 @@ func (v *Validator) Max(fieldName string, value interface{}, max float64) *Valid
 // MinLength validates a string has a minimum length.
 func (v *Validator) MinLength(fieldName string, value string, minLength int) *Validator {
 	if len(value) < minLength {
-		v.errors = append(v.errors, fmt.Sprintf("Field '%s' must be at least %d characters long", fieldName, minLength))
+		v.add(fieldName, "minLength", fmt.Sprintf("Field '%s' must be at least %d characters long", fieldName, minLength))
 	}
 	return v
 }
@@ -392,7 +533,7 @@ func (v *Validator) MinLength(fieldName string, value string, minLength int) *Va
 // MaxLength validates a string has a maximum length.
 func (v *Validator) MaxLength(fieldName string, value string, maxLength int) *Validator {
 	if len(value) > maxLength {
-		v.errors = append(v.errors, fmt.Sprintf("Field '%s' must be at most %d characters long", fieldName, maxLength))
+		v.add(fieldName, "maxLength", fmt.Sprintf("Field '%s' must be at most %d characters long", fieldName, maxLength))
 	}
 	return v
 }
@@ -408,34 +549,90 @@ func (v *Validator) Enum(fieldName string, value string, allowedValues []string)
 	}
 
 	if !found {
-		v.errors = append(v.errors, fmt.Sprintf("Field '%s' must be one of: %s", fieldName, strings.Join(allowedValues, ", ")))
+		v.add(fieldName, "enum", fmt.Sprintf("Field '%s' must be one of: %s", fieldName, strings.Join(allowedValues, ", ")))
 	}
 	return v
 }
 
-// Format validates a string matches a format.
+// numericEnum validates a number is one of a set of allowed values.
+func (v *Validator) numericEnum(fieldName string, value float64, allowedValues []string) {
+	for _, allowed := range allowedValues {
+		if num, err := strconv.ParseFloat(allowed, 64); err == nil && num == value {
+			return
+		}
+	}
+	v.add(fieldName, "enum", fmt.Sprintf("Field '%s' must be one of: %s", fieldName, strings.Join(allowedValues, ", ")))
+}
+
+// Pattern validates a string matches a regular expression.
+func (v *Validator) Pattern(fieldName string, value string, pattern string) *Validator {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		v.add(fieldName, "pattern", fmt.Sprintf("Invalid pattern '%s' for field '%s'", pattern, fieldName))
+		return v
+	}
+	if !re.MatchString(value) {
+		v.add(fieldName, "pattern", fmt.Sprintf("Field '%s' does not match pattern '%s'", fieldName, pattern))
+	}
+	return v
+}
+
+// uuidPattern matches the canonical textual form of a UUID
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// hostnamePattern matches an RFC 1123 host name
+var hostnamePattern = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+
+// Format validates a string matches a format. The supported formats are
+// email, uri, date-time, date, time, uuid, ipv4, ipv6 and hostname.
 func (v *Validator) Format(fieldName string, value string, format string) *Validator {
+	var valid bool
+	var description string
 	switch format {
 	case "email":
-		if !strings.Contains(value, "@") {
-			v.errors = append(v.errors, fmt.Sprintf("Field '%s' must be a valid email address", fieldName))
-		}
+		addr, err := mail.ParseAddress(value)
+		valid, description = err == nil && addr.Address == value, "a valid email address"
 	case "uri":
-		if !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
-			v.errors = append(v.errors, fmt.Sprintf("Field '%s' must be a valid URI", fieldName))
-		}
+		u, err := url.Parse(value)
+		valid, description = err == nil && u.Scheme != "", "a valid URI"
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		valid, description = err == nil, "a valid RFC 3339 date-time"
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		valid, description = err == nil, "a valid date (YYYY-MM-DD)"
+	case "time":
+		_, err := time.Parse("15:04:05Z07:00", value)
+		if err != nil {
+			_, err = time.Parse(time.TimeOnly, value)
+		}
+		valid, description = err == nil, "a valid time"
+	case "uuid":
+		valid, description = uuidPattern.MatchString(value), "a valid UUID"
+	case "ipv4":
+		ip, err := netip.ParseAddr(value)
+		valid, description = err == nil && ip.Is4(), "a valid IPv4 address"
+	case "ipv6":
+		ip, err := netip.ParseAddr(value)
+		valid, description = err == nil && ip.Is6(), "a valid IPv6 address"
+	case "hostname":
+		valid, description = len(value) <= 253 && hostnamePattern.MatchString(value), "a valid hostname"
 	default:
-		v.errors = append(v.errors, fmt.Sprintf("Unsupported format '%s' for field '%s'", format, fieldName))
+		v.add(fieldName, "format", fmt.Sprintf("Unsupported format '%s' for field '%s'", format, fieldName))
+		return v
+	}
+	if !valid {
+		v.add(fieldName, "format", fmt.Sprintf("Field '%s' must be %s", fieldName, description))
 	}
 	return v
 }
 
 // Error returns validation errors or nil if none.
 func (v *Validator) Error() error {
-	if len(v.errors) == 0 {
+	if len(v.violations) == 0 {
 		return nil
 	}
-	return fmt.Errorf("validation failed: %s", strings.Join(v.errors, "; "))
+	return v.violations
 }
 
 // HasErrors returns true if there are validation errors.
@@ -448,6 +645,11 @@ func (v *Validator) Errors() []string {
 	return v.errors
 }
 
+// Violations returns all violated constraints.
+func (v *Validator) Violations() ValidationErrors {
+	return v.violations
+}
+
 // ValidateStruct validates a struct against its schema definition tags.
 func ValidateStruct(data interface{}) error {
 	val := reflect.ValueOf(data)
@@ -477,10 +679,21 @@ func ValidateStruct(data interface{}) error {
 			fieldName = strings.Split(jsonTag, ",")[0]
 		}
 
+		// Get actual value for pointer types
+		fieldValue := value
+		if value.Kind() == reflect.Ptr && !value.IsNil() {
+			fieldValue = value.Elem()
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		constraints := constraintsOf(field, fieldType.Kind())
+
 		// Required validation
-		if field.Tag.Get("required") == "true" {
+		if constraints.required {
 			if isZeroValue(value) {
-				v.errors = append(v.errors, fmt.Sprintf("Field '%s' is required but was not provided", fieldName))
+				v.add(fieldName, "required", fmt.Sprintf("Field '%s' is required but was not provided", fieldName))
 				continue
 			}
 		}
@@ -490,32 +703,23 @@ func ValidateStruct(data interface{}) error {
 			continue
 		}
 
-		// Get actual value for pointer types
-		fieldValue := value
-		if value.Kind() == reflect.Ptr && !value.IsNil() {
-			fieldValue = value.Elem()
-		}
-
 		// Numeric validations for int or float types
 		if fieldValue.Kind() >= reflect.Int && fieldValue.Kind() <= reflect.Float64 {
+			numVal := getNumericValue(fieldValue)
+
 			// Min validation
-			if minStr := field.Tag.Get("min"); minStr != "" {
-				if min, err := strconv.ParseFloat(minStr, 64); err == nil {
-					numVal := getNumericValue(fieldValue)
-					if numVal < min {
-						v.errors = append(v.errors, fmt.Sprintf("Field '%s' must be at least %v", fieldName, min))
-					}
-				}
+			if min := parseNumericTag(constraints.min); min != nil && numVal < *min {
+				v.add(fieldName, "minimum", fmt.Sprintf("Field '%s' must be at least %v", fieldName, *min))
 			}
 
 			// Max validation
-			if maxStr := field.Tag.Get("max"); maxStr != "" {
-				if max, err := strconv.ParseFloat(maxStr, 64); err == nil {
-					numVal := getNumericValue(fieldValue)
-					if numVal > max {
-						v.errors = append(v.errors, fmt.Sprintf("Field '%s' must be at most %v", fieldName, max))
-					}
-				}
+			if max := parseNumericTag(constraints.max); max != nil && numVal > *max {
+				v.add(fieldName, "maximum", fmt.Sprintf("Field '%s' must be at most %v", fieldName, *max))
+			}
+
+			// Enum validation
+			if len(constraints.enum) > 0 {
+				v.numericEnum(fieldName, numVal, constraints.enum)
 			}
 		}
 
@@ -524,46 +728,38 @@ func ValidateStruct(data interface{}) error {
 			strVal := fieldValue.String()
 
 			// MinLength validation
-			if minLenStr := field.Tag.Get("minLength"); minLenStr != "" {
-				if minLen, err := strconv.Atoi(minLenStr); err == nil {
-					if len(strVal) < minLen {
-						v.errors = append(v.errors, fmt.Sprintf("Field '%s' must be at least %d characters long", fieldName, minLen))
-					}
-				}
+			if minLen := parseIntTag(constraints.minLength); minLen != nil {
+				v.MinLength(fieldName, strVal, *minLen)
 			}
 
 			// MaxLength validation
-			if maxLenStr := field.Tag.Get("maxLength"); maxLenStr != "" {
-				if maxLen, err := strconv.Atoi(maxLenStr); err == nil {
-					if len(strVal) > maxLen {
-						v.errors = append(v.errors, fmt.Sprintf("Field '%s' must be at most %d characters long", fieldName, maxLen))
-					}
-				}
+			if maxLen := parseIntTag(constraints.maxLength); maxLen != nil {
+				v.MaxLength(fieldName, strVal, *maxLen)
 			}
 
 			// Enum validation
-			if enumStr := field.Tag.Get("enum"); enumStr != "" {
-				allowedValues := strings.Split(enumStr, ",")
-				for i, val := range allowedValues {
-					allowedValues[i] = strings.TrimSpace(val)
-				}
-
-				found := false
-				for _, val := range allowedValues {
-					if strVal == val {
-						found = true
-						break
-					}
-				}
+			if len(constraints.enum) > 0 {
+				v.Enum(fieldName, strVal, constraints.enum)
+			}
 
-				if !found {
-					v.errors = append(v.errors, fmt.Sprintf("Field '%s' must be one of: %s", fieldName, strings.Join(allowedValues, ", ")))
-				}
+			// Pattern validation
+			if constraints.pattern != "" {
+				v.Pattern(fieldName, strVal, constraints.pattern)
 			}
 
 			// Format validation
-			if format := field.Tag.Get("format"); format != "" {
-				v.Format(fieldName, strVal, format)
+			if constraints.format != "" {
+				v.Format(fieldName, strVal, constraints.format)
+			}
+		}
+
+		// Length validations for slices and arrays
+		if fieldValue.Kind() == reflect.Slice || fieldValue.Kind() == reflect.Array {
+			if minItems := parseIntTag(constraints.minItems); minItems != nil && fieldValue.Len() < *minItems {
+				v.add(fieldName, "minItems", fmt.Sprintf("Field '%s' must have at least %d items", fieldName, *minItems))
+			}
+			if maxItems := parseIntTag(constraints.maxItems); maxItems != nil && fieldValue.Len() > *maxItems {
+				v.add(fieldName, "maxItems", fmt.Sprintf("Field '%s' must have at most %d items", fieldName, *maxItems))
 			}
 		}
 	}
@@ -685,7 +881,7 @@ func (v *Validator) Map(fieldName string, value map[string]interface{}, propsSch
 
 		// Validate entry type if specified
 		if expectedType != "" && !ValidateType(val, expectedType) {
-			v.errors = append(v.errors, fmt.Sprintf("Entry with key '%s' in map '%s' must be of type '%s'",
+			v.add(fmt.Sprintf("%s[%s]", fieldName, key), "type", fmt.Sprintf("Entry with key '%s' in map '%s' must be of type '%s'",
 				key, fieldName, expectedType))
 		}
 
@@ -702,12 +898,8 @@ func (v *Validator) Map(fieldName string, value map[string]interface{}, propsSch
 							propVal, exists := objVal[propName]
 							if !exists {
 								// Check if required
-								if requiredProps, ok := additionalPropsSchema["required"].([]string); ok {
-									for _, req := range requiredProps {
-										if req == propName {
-											nestedValidator.Required(propName, nil)
-										}
-									}
+								if slices.Contains(requiredFields(additionalPropsSchema["required"]), propName) {
+									nestedValidator.Required(propName, nil)
 								}
 								continue
 							}
@@ -719,9 +911,9 @@ func (v *Validator) Map(fieldName string, value map[string]interface{}, propsSch
 
 				// Add nested validation errors to parent validator
 				if nestedValidator.HasErrors() {
-					for _, err := range nestedValidator.Errors() {
-						v.errors = append(v.errors, fmt.Sprintf("In map entry '%s' of field '%s': %s",
-							key, fieldName, err))
+					for _, violation := range nestedValidator.Violations() {
+						v.add(fmt.Sprintf("%s[%s].%s", fieldName, key, violation.Field), violation.Constraint,
+							fmt.Sprintf("In map entry '%s' of field '%s': %s", key, fieldName, violation.Message))
 					}
 				}
 			}
@@ -738,11 +930,11 @@ func (v *Validator) Map(fieldName string, value map[string]interface{}, propsSch
 func (v *Validator) Array(fieldName string, array []interface{}, arraySchema map[string]interface{}) *Validator {
 	// Validate array length
 	if minItems, ok := arraySchema["minItems"].(float64); ok && float64(len(array)) < minItems {
-		v.errors = append(v.errors, fmt.Sprintf("Field '%s' must have at least %.0f items", fieldName, minItems))
+		v.add(fieldName, "minItems", fmt.Sprintf("Field '%s' must have at least %.0f items", fieldName, minItems))
 	}
 
 	if maxItems, ok := arraySchema["maxItems"].(float64); ok && float64(len(array)) > maxItems {
-		v.errors = append(v.errors, fmt.Sprintf("Field '%s' must have at most %.0f items", fieldName, maxItems))
+		v.add(fieldName, "maxItems", fmt.Sprintf("Field '%s' must have at most %.0f items", fieldName, maxItems))
 	}
 
 	// Validate items against items schema
@@ -758,7 +950,7 @@ func (v *Validator) Array(fieldName string, array []interface{}, arraySchema map
 	for i, item := range array {
 		// Validate item type if specified
 		if expectedType != "" && !ValidateType(item, expectedType) {
-			v.errors = append(v.errors, fmt.Sprintf("Item at index %d in array '%s' must be of type '%s'",
+			v.add(fmt.Sprintf("%s[%d]", fieldName, i), "type", fmt.Sprintf("Item at index %d in array '%s' must be of type '%s'",
 				i, fieldName, expectedType))
 		}
 
@@ -775,12 +967,8 @@ func (v *Validator) Array(fieldName string, array []interface{}, arraySchema map
 							propVal, exists := objItem[propName]
 							if !exists {
 								// Check if required
-								if requiredProps, ok := itemsSchema["required"].([]string); ok {
-									for _, req := range requiredProps {
-										if req == propName {
-											nestedValidator.Required(propName, nil)
-										}
-									}
+								if slices.Contains(requiredFields(itemsSchema["required"]), propName) {
+									nestedValidator.Required(propName, nil)
 								}
 								continue
 							}
@@ -792,9 +980,9 @@ func (v *Validator) Array(fieldName string, array []interface{}, arraySchema map
 
 				// Add nested validation errors to parent validator
 				if nestedValidator.HasErrors() {
-					for _, err := range nestedValidator.Errors() {
-						v.errors = append(v.errors, fmt.Sprintf("In item %d of array '%s': %s",
-							i, fieldName, err))
+					for _, violation := range nestedValidator.Violations() {
+						v.add(fmt.Sprintf("%s[%d].%s", fieldName, i, violation.Field), violation.Constraint,
+							fmt.Sprintf("In item %d of array '%s': %s", i, fieldName, violation.Message))
 					}
 				}
 			}
@@ -862,7 +1050,7 @@ func ValidateType(value interface{}, expectedType string) bool {
 func ValidateValueAgainstSchema(v *Validator, fieldName string, value interface{}, schema map[string]interface{}) {
 	// Validate type
 	if typeName, ok := schema["type"].(string); ok && !ValidateType(value, typeName) {
-		v.errors = append(v.errors, fmt.Sprintf("Field '%s' must be of type '%s'", fieldName, typeName))
+		v.add(fieldName, "type", fmt.Sprintf("Field '%s' must be of type '%s'", fieldName, typeName))
 		return
 	}
 
@@ -893,19 +1081,10 @@ func ValidateConstraints(v *Validator, fieldName string, value interface{}, sche
 	switch typeName {
 	case "number", "integer":
 		// Convert to float64 for numeric validation
-		var numValue float64
-		switch value := value.(type) {
-		case float64:
-			numValue = value
-		case float32:
-			numValue = float64(value)
-		case int:
-			numValue = float64(value)
-		case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-			numValue = reflect.ValueOf(value).Float()
-		default:
+		if !ValidateType(value, "number") {
 			return // Skip non-numeric values
 		}
+		numValue := getNumericValue(reflect.ValueOf(value))
 
 		// Validate minimum
 		if min, ok := schema["minimum"].(float64); ok {
@@ -917,6 +1096,11 @@ func ValidateConstraints(v *Validator, fieldName string, value interface{}, sche
 			v.Max(fieldName, numValue, max)
 		}
 
+		// Validate enum
+		if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+			v.numericEnum(fieldName, numValue, enumStrings(enum))
+		}
+
 	case "string":
 		strValue, ok := value.(string)
 		if !ok {
@@ -935,10 +1119,7 @@ func ValidateConstraints(v *Validator, fieldName string, value interface{}, sche
 
 		// Validate pattern
 		if pattern, ok := schema["pattern"].(string); ok && pattern != "" {
-			matched, _ := regexp.MatchString(pattern, strValue)
-			if !matched {
-				v.errors = append(v.errors, fmt.Sprintf("Field '%s' does not match pattern '%s'", fieldName, pattern))
-			}
+			v.Pattern(fieldName, strValue, pattern)
 		}
 
 		// Validate format
@@ -948,16 +1129,20 @@ func ValidateConstraints(v *Validator, fieldName string, value interface{}, sche
 
 		// Validate enum
 		if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
-			// Convert enum values to strings
-			enumStrs := make([]string, len(enum))
-			for i, e := range enum {
-				enumStrs[i] = fmt.Sprintf("%v", e)
-			}
-			v.Enum(fieldName, strValue, enumStrs)
+			v.Enum(fieldName, strValue, enumStrings(enum))
 		}
 	}
 }
 
+// enumStrings formats a schema's enum values as strings
+func enumStrings(enum []interface{}) []string {
+	enumStrs := make([]string, len(enum))
+	for i, e := range enum {
+		enumStrs[i] = fmt.Sprintf("%v", e)
+	}
+	return enumStrs
+}
+
 // Enhanced HandleArgs to support more complex validations
 func HandleArgsWithSchema[T any](arguments any, schemaMap map[string]interface{}) (*T, error) {
 	var args T
@@ -973,40 +1158,8 @@ func HandleArgsWithSchema[T any](arguments any, schemaMap map[string]interface{}
 	}
 
 	// Validate against schema before decoding
-	validator := NewValidator()
-
-	// Get properties map from schema
-	properties, hasProps := schemaMap["properties"].(map[string]interface{})
-
-	// Get required fields list
-	var requiredFields []string
-	if required, ok := schemaMap["required"].([]string); ok {
-		requiredFields = required
-	}
-
-	// Validate required fields
-	for _, field := range requiredFields {
-		fieldValue, exists := argsMap[field]
-		validator.Required(field, fieldValue)
-		if !exists {
-			validator.Required(fmt.Sprintf("missing_required_%s", field), nil)
-		}
-	}
-
-	// Validate each field against schema
-	if hasProps {
-		for fieldName, propSchema := range properties {
-			if fieldValue, exists := argsMap[fieldName]; exists {
-				if propMap, ok := propSchema.(map[string]interface{}); ok {
-					ValidateValueAgainstSchema(validator, fieldName, fieldValue, propMap)
-				}
-			}
-		}
-	}
-
-	// Check for validation errors
-	if validator.HasErrors() {
-		return nil, fmt.Errorf("validation failed: %v", validator.Errors())
+	if err := ValidateArgs(schemaMap, argsMap); err != nil {
+		return nil, err
 	}
 
 	// Use mapstructure to decode the map into the struct
@@ -1032,6 +1185,95 @@ func HandleArgsWithSchema[T any](arguments any, schemaMap map[string]interface{}
 	return &args, nil
 }
 
+// ValidateArgs validates arguments against an object schema, such as one made by
+// Generator.GenerateSchema, and returns ValidationErrors listing every violated
+// constraint, or nil. Properties may be given as PropertyDetail values or as
+// decoded JSON.
+func ValidateArgs(schemaMap map[string]interface{}, args map[string]interface{}) error {
+	validator := NewValidator()
+
+	// Validate required fields
+	required := requiredFields(schemaMap["required"])
+	for _, field := range required {
+		validator.Required(field, args[field])
+	}
+
+	// Validate each field against schema, skipping nulls for optional fields
+	for fieldName, propMap := range propertySchemas(schemaMap["properties"]) {
+		fieldValue, exists := args[fieldName]
+		if !exists || (fieldValue == nil && !slices.Contains(required, fieldName)) {
+			continue
+		}
+		if fieldValue != nil {
+			ValidateValueAgainstSchema(validator, fieldName, fieldValue, propMap)
+		}
+	}
+
+	// Report violations in a stable order
+	violations := validator.Violations()
+	if len(violations) == 0 {
+		return nil
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Field < violations[j].Field
+	})
+	return violations
+}
+
+// requiredFields reads a schema's required list, which is a []string when
+// generated and a []interface{} when decoded from JSON
+func requiredFields(value interface{}) []string {
+	switch required := value.(type) {
+	case []string:
+		return required
+	case []interface{}:
+		fields := make([]string, 0, len(required))
+		for _, field := range required {
+			if name, ok := field.(string); ok {
+				fields = append(fields, name)
+			}
+		}
+		return fields
+	default:
+		return nil
+	}
+}
+
+// propertySchemas reads a schema's properties as decoded JSON, converting
+// PropertyDetail values so that their constraints are validated too
+func propertySchemas(value interface{}) map[string]map[string]interface{} {
+	schemas := make(map[string]map[string]interface{})
+	switch properties := value.(type) {
+	case map[string]interface{}:
+		for name, prop := range properties {
+			switch prop := prop.(type) {
+			case map[string]interface{}:
+				schemas[name] = prop
+			case PropertyDetail:
+				schemas[name] = prop.toMap()
+			}
+		}
+	case map[string]PropertyDetail:
+		for name, prop := range properties {
+			schemas[name] = prop.toMap()
+		}
+	}
+	return schemas
+}
+
+// toMap returns the property as decoded JSON, with numbers as float64
+func (p PropertyDetail) toMap() map[string]interface{} {
+	var m map[string]interface{}
+	data, err := json.Marshal(p)
+	if err == nil {
+		err = json.Unmarshal(data, &m)
+	}
+	if err != nil {
+		return map[string]interface{}{"type": p.Type}
+	}
+	return m
+}
+
 // ValidateAndConvertArgs validates arguments against a schema and converts
 // them to the appropriate type based on reflection target type.
 // This is a more general version than HandleArgsWithSchema that works with any target Go type.
@@ -1065,41 +1307,9 @@ func ValidateAndConvertArgs(schemaMap map[string]interface{}, args map[string]in
 		// Create a new instance of the target struct (always start with a pointer)
 		target := reflect.New(structType)
 
-		// Validate against schema before decoding
-		validator := NewValidator()
-
-		// Get properties map from schema
-		properties, hasProps := schemaMap["properties"].(map[string]interface{})
-
-		// Get required fields list
-		var requiredFields []string
-		if required, ok := schemaMap["required"].([]string); ok {
-			requiredFields = required
-		}
-
-		// Validate required fields
-		for _, field := range requiredFields {
-			fieldValue, exists := args[field]
-			validator.Required(field, fieldValue)
-			if !exists {
-				validator.Required(fmt.Sprintf("missing_required_%s", field), nil)
-			}
-		}
-
-		// Validate each field against schema
-		if hasProps {
-			for fieldName, propSchema := range properties {
-				if fieldValue, exists := args[fieldName]; exists {
-					if propMap, ok := propSchema.(map[string]interface{}); ok {
-						ValidateValueAgainstSchema(validator, fieldName, fieldValue, propMap)
-					}
-				}
-			}
-		}
-
-		// Check for validation errors
-		if validator.HasErrors() {
-			return nil, fmt.Errorf("validation failed: %v", validator.Errors())
+		// Validate against schema before decoding, reporting every violation
+		if err := ValidateArgs(schemaMap, args); err != nil {
+			return nil, err
 		}
 
 		// Use mapstructure to decode the map into the struct
@@ -701,3 +701,203 @@ func TestArraySchemaValidation(t *testing.T) {
 		t.Errorf("Expected numbers items type to be 'integer', got '%s'", numbersSchema.Items.Type)
 	}
 }
+
+func TestValidateTag(t *testing.T) {
+	type Args struct {
+		Name   string   `json:"name" validate:"required,min=3,max=10"`
+		Count  int      `json:"count" validate:"min=1,max=5"`
+		Level  int      `json:"level" validate:"enum=1|2|3"`
+		Mode   string   `json:"mode" validate:"enum=fast|slow"`
+		Email  string   `json:"email" validate:"format=email"`
+		IDs    []string `json:"ids" validate:"min=1,max=2"`
+		Code   string   `json:"code" validate:"pattern=^[A-Z]{2,3}$"`
+		Note   *string  `json:"note,omitempty" validate:"max=5" maxLength:"4"`
+		Option *int     `json:"option,omitempty" validate:"required"`
+	}
+
+	schema := FromStruct(Args{})
+
+	name := schema.Properties["name"]
+	if name.MinLength == nil || *name.MinLength != 3 || name.MaxLength == nil || *name.MaxLength != 10 {
+		t.Errorf("Expected name length bounds 3..10, got %v..%v", name.MinLength, name.MaxLength)
+	}
+	count := schema.Properties["count"]
+	if count.Minimum == nil || *count.Minimum != 1 || count.Maximum == nil || *count.Maximum != 5 {
+		t.Errorf("Expected count bounds 1..5, got %v..%v", count.Minimum, count.Maximum)
+	}
+	if level := schema.Properties["level"]; len(level.Enum) != 3 || level.Enum[0] != 1.0 {
+		t.Errorf("Expected numeric enum for level, got %v", level.Enum)
+	}
+	if mode := schema.Properties["mode"]; len(mode.Enum) != 2 || mode.Enum[1] != "slow" {
+		t.Errorf("Expected enum fast|slow for mode, got %v", mode.Enum)
+	}
+	ids := schema.Properties["ids"]
+	if ids.MinItems == nil || *ids.MinItems != 1 || ids.MaxItems == nil || *ids.MaxItems != 2 {
+		t.Errorf("Expected ids item bounds 1..2, got %v..%v", ids.MinItems, ids.MaxItems)
+	}
+	if code := schema.Properties["code"]; code.Pattern != "^[A-Z]{2,3}$" {
+		t.Errorf("Expected pattern with commas to be kept whole, got %q", code.Pattern)
+	}
+	if note := schema.Properties["note"]; note.MaxLength == nil || *note.MaxLength != 4 {
+		t.Errorf("Expected maxLength tag to take precedence, got %v", note.MaxLength)
+	}
+	requiredOption := false
+	for _, req := range schema.Required {
+		if req == "option" {
+			requiredOption = true
+		}
+	}
+	if !requiredOption {
+		t.Error("Expected 'option' to be required by its validate tag")
+	}
+
+	err := ValidateStruct(Args{
+		Name:  "ab",
+		Count: 9,
+		Level: 4,
+		Mode:  "medium",
+		Email: "not-an-email",
+		IDs:   []string{"a", "b", "c"},
+		Code:  "abc",
+	})
+	violations, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("Expected ValidationErrors, got %T: %v", err, err)
+	}
+	constraints := map[string]string{}
+	for _, violation := range violations {
+		constraints[violation.Field] = violation.Constraint
+	}
+	want := map[string]string{
+		"name":   "minLength",
+		"count":  "maximum",
+		"level":  "enum",
+		"mode":   "enum",
+		"email":  "format",
+		"ids":    "maxItems",
+		"code":   "pattern",
+		"option": "required",
+	}
+	for field, constraint := range want {
+		if constraints[field] != constraint {
+			t.Errorf("Expected %s to violate %s, got %q", field, constraint, constraints[field])
+		}
+	}
+}
+
+func TestValidateArgs(t *testing.T) {
+	type Args struct {
+		Query string    `json:"query" validate:"min=2"`
+		Limit int       `json:"limit" validate:"min=1,max=100"`
+		Sort  string    `json:"sort" validate:"enum=asc|desc"`
+		Since *string   `json:"since,omitempty" validate:"format=date-time"`
+		Tags  *[]string `json:"tags,omitempty" validate:"max=2"`
+	}
+
+	schemaMap, err := NewGenerator().GenerateSchema(Args{})
+	if err != nil {
+		t.Fatalf("GenerateSchema failed: %v", err)
+	}
+
+	valid := map[string]interface{}{"query": "go", "limit": float64(10), "sort": "asc", "since": nil}
+	if err := ValidateArgs(schemaMap, valid); err != nil {
+		t.Errorf("Expected valid arguments, got %v", err)
+	}
+
+	err = ValidateArgs(schemaMap, map[string]interface{}{
+		"query": "g",
+		"limit": float64(500),
+		"since": "yesterday",
+		"tags":  []interface{}{"a", "b", "c"},
+	})
+	violations, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("Expected ValidationErrors, got %T: %v", err, err)
+	}
+	got := make([]string, len(violations))
+	for i, violation := range violations {
+		got[i] = violation.Field + ":" + violation.Constraint
+	}
+	want := []string{"limit:maximum", "query:minLength", "since:format", "sort:required", "tags:maxItems"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected violations %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected violation %d to be %s, got %s", i, want[i], got[i])
+		}
+	}
+
+	// Types are checked against the schema too
+	err = ValidateArgs(schemaMap, map[string]interface{}{"query": "go", "limit": "ten", "sort": "asc"})
+	if violations, ok := err.(ValidationErrors); !ok || len(violations) != 1 || violations[0].Constraint != "type" {
+		t.Errorf("Expected a single type violation, got %v", err)
+	}
+}
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		valid  string
+		bad    string
+	}{
+		{"email", "jane@example.com", "jane@"},
+		{"uri", "mailto:jane@example.com", "/relative/path"},
+		{"date-time", "2024-05-01T10:00:00Z", "2024-05-01 10:00"},
+		{"date", "2024-05-01", "01/05/2024"},
+		{"time", "10:00:00", "10am"},
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000", "123e4567"},
+		{"ipv4", "192.168.0.1", "::1"},
+		{"ipv6", "::1", "192.168.0.1"},
+		{"hostname", "api.example.com", "-bad-.example.com"},
+	}
+	for _, tt := range tests {
+		if err := NewValidator().Format("f", tt.valid, tt.format).Error(); err != nil {
+			t.Errorf("Expected %q to be a valid %s, got %v", tt.valid, tt.format, err)
+		}
+		if err := NewValidator().Format("f", tt.bad, tt.format).Error(); err == nil {
+			t.Errorf("Expected %q to be an invalid %s", tt.bad, tt.format)
+		}
+	}
+}
+
+func TestJSONSchemaTag(t *testing.T) {
+	type Args struct {
+		Operation string  `json:"operation" jsonschema:"enum=add|subtract,description=The operation,default=add"`
+		A         float64 `json:"a" jsonschema:"minimum=0,maximum=10" validate:"min=5"`
+		Name      *string `json:"name,omitempty" jsonschema:"required,minLength=2,pattern=^[a-z]+(,[a-z]+)*$"`
+		Scale     int     `json:"scale" jsonschema:"minimum=1" min:"2"`
+	}
+
+	schema := FromStruct(Args{})
+
+	operation := schema.Properties["operation"]
+	if len(operation.Enum) != 2 || operation.Enum[0] != "add" || operation.Enum[1] != "subtract" {
+		t.Errorf("Expected enum add|subtract, got %v", operation.Enum)
+	}
+	if operation.Description != "The operation" || operation.Default != "add" {
+		t.Errorf("Expected description and default from the jsonschema tag, got %q and %v", operation.Description, operation.Default)
+	}
+	a := schema.Properties["a"]
+	if a.Minimum == nil || *a.Minimum != 0 || a.Maximum == nil || *a.Maximum != 10 {
+		t.Errorf("Expected jsonschema bounds to take precedence over validate, got %v..%v", a.Minimum, a.Maximum)
+	}
+	name := schema.Properties["name"]
+	if name.MinLength == nil || *name.MinLength != 2 || name.Pattern != "^[a-z]+(,[a-z]+)*$" {
+		t.Errorf("Expected minLength 2 and the whole pattern, got %v and %q", name.MinLength, name.Pattern)
+	}
+	if scale := schema.Properties["scale"]; scale.Minimum == nil || *scale.Minimum != 2 {
+		t.Errorf("Expected the min tag to take precedence, got %v", scale.Minimum)
+	}
+	required := false
+	for _, req := range schema.Required {
+		required = required || req == "name"
+	}
+	if !required {
+		t.Error("Expected 'name' to be required by its jsonschema tag")
+	}
+
+	if err := ValidateStruct(Args{Operation: "multiply", A: -1, Scale: 2}); err == nil {
+		t.Error("Expected jsonschema constraints to be validated")
+	}
+}